@@ -0,0 +1,64 @@
+// accept.go
+package openapi2mcp
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// acceptArgument is the reserved tool argument selecting the response media type of operations documenting several.
+const acceptArgument = "__accept"
+
+// defaultAccept is the Accept header of operations that document no response content types.
+const defaultAccept = "application/json, application/vnd.api+json"
+
+// responseContentTypes returns the media types of the operation's documented responses: those of the
+// success responses in status code order, then those of the default response, without duplicates.
+func responseContentTypes(op OpenAPIOperation) []string {
+	if op.Responses == nil {
+		return nil
+	}
+	var types []string
+	add := func(code string) {
+		ref := op.Responses.Value(code)
+		if ref == nil || ref.Value == nil {
+			return
+		}
+		for _, mediaType := range slices.Sorted(maps.Keys(ref.Value.Content)) {
+			if !slices.Contains(types, mediaType) {
+				types = append(types, mediaType)
+			}
+		}
+	}
+	for _, code := range slices.Sorted(maps.Keys(op.Responses.Map())) {
+		if strings.HasPrefix(code, "2") {
+			add(code)
+		}
+	}
+	add("default")
+	return types
+}
+
+// acceptHeader returns the Accept header for the documented response media types.
+func acceptHeader(types []string) string {
+	if len(types) == 0 {
+		return defaultAccept
+	}
+	return strings.Join(types, ", ")
+}
+
+// acceptArgumentSchema describes the __accept argument of tools whose operation documents several response media types.
+func acceptArgumentSchema(types []string) *jsonschema.Schema {
+	enum := make([]any, len(types))
+	for i, t := range types {
+		enum[i] = t
+	}
+	return &jsonschema.Schema{
+		Type:        "string",
+		Enum:        enum,
+		Description: "Optional response media type to request (sent as the Accept header), e.g. \"" + types[len(types)-1] + "\"; by default, any documented type is accepted.",
+	}
+}
@@ -0,0 +1,69 @@
+package openapi2mcp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestToolHandler_Accept(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /reports/{id}:
+    parameters: [{name: id, in: path, required: true, schema: {type: string}}]
+    get:
+      operationId: getReport
+      responses:
+        "200":
+          description: ok
+          content:
+            text/csv: {schema: {type: string}}
+            application/json: {schema: {type: object}}
+        "202": {description: accepted, content: {application/pdf: {schema: {type: string, format: binary}}}}
+        default: {description: error, content: {application/problem+json: {schema: {type: object}}}}
+  /status:
+    get: {operationId: getStatus, responses: {"204": {description: empty}}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ops := map[string]OpenAPIOperation{}
+	for _, op := range ExtractOpenAPIOperations(doc) {
+		ops[op.OperationID] = op
+	}
+
+	types := responseContentTypes(ops["getReport"])
+	if got := strings.Join(types, ", "); got != "application/json, text/csv, application/pdf, application/problem+json" {
+		t.Errorf("responseContentTypes() = %s", got)
+	}
+
+	var accept string
+	opts := &ToolGenOptions{RequestHandler: func(req *http.Request) (*http.Response, error) {
+		accept = req.Header.Get("Accept")
+		return &http.Response{StatusCode: 200, Header: http.Header{"Content-Type": {"text/csv"}}, Body: io.NopCloser(strings.NewReader("a,b\n1,2\n")), Request: req}, nil
+	}}
+	call := func(name string, args map[string]any) {
+		handler := toolHandler(name, ops[name], doc, jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+		if _, _, err := handler(context.Background(), nil, args); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	call("getReport", map[string]any{"id": "1"})
+	if accept != "application/json, text/csv, application/pdf, application/problem+json" {
+		t.Errorf("expected the documented types, got %q", accept)
+	}
+	call("getReport", map[string]any{"id": "1", "__accept": "text/csv"})
+	if accept != "text/csv" {
+		t.Errorf("expected the requested type, got %q", accept)
+	}
+	call("getStatus", map[string]any{})
+	if accept != defaultAccept {
+		t.Errorf("expected the default for undocumented types, got %q", accept)
+	}
+}
@@ -0,0 +1,193 @@
+// arazzo.go
+package openapi2mcp
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"go.yaml.in/yaml/v3"
+)
+
+// arazzoDocument is the part of an Arazzo document (https://spec.openapis.org/arazzo/latest.html) that is served.
+type arazzoDocument struct {
+	Arazzo     string           `yaml:"arazzo"`
+	Workflows  []arazzoWorkflow `yaml:"workflows"`
+	Components struct {
+		Inputs         map[string]map[string]any  `yaml:"inputs"`
+		Parameters     map[string]arazzoParameter `yaml:"parameters"`
+		SuccessActions map[string]arazzoAction    `yaml:"successActions"`
+		FailureActions map[string]arazzoAction    `yaml:"failureActions"`
+	} `yaml:"components"`
+}
+
+type arazzoWorkflow struct {
+	WorkflowID     string            `yaml:"workflowId"`
+	Summary        string            `yaml:"summary"`
+	Description    string            `yaml:"description"`
+	Inputs         map[string]any    `yaml:"inputs"`
+	Steps          []arazzoStep      `yaml:"steps"`
+	SuccessActions []arazzoAction    `yaml:"successActions"`
+	FailureActions []arazzoAction    `yaml:"failureActions"`
+	Outputs        map[string]string `yaml:"outputs"`
+}
+
+type arazzoStep struct {
+	StepID          string              `yaml:"stepId"`
+	OperationID     string              `yaml:"operationId"`
+	OperationPath   string              `yaml:"operationPath"`
+	WorkflowID      string              `yaml:"workflowId"`
+	Parameters      []arazzoParameter   `yaml:"parameters"`
+	RequestBody     *arazzoRequestBody  `yaml:"requestBody"`
+	SuccessCriteria []WorkflowCriterion `yaml:"successCriteria"`
+	OnSuccess       []arazzoAction      `yaml:"onSuccess"`
+	OnFailure       []arazzoAction      `yaml:"onFailure"`
+	Outputs         map[string]string   `yaml:"outputs"`
+}
+
+type arazzoParameter struct {
+	Name      string `yaml:"name"`
+	In        string `yaml:"in"`
+	Value     any    `yaml:"value"`
+	Reference string `yaml:"reference"` // $components.parameters.<name>, with Value overriding its value
+}
+
+type arazzoRequestBody struct {
+	ContentType string `yaml:"contentType"`
+	Payload     any    `yaml:"payload"`
+}
+
+type arazzoAction struct {
+	Type       string              `yaml:"type"`
+	StepID     string              `yaml:"stepId"`
+	WorkflowID string              `yaml:"workflowId"`
+	RetryAfter float64             `yaml:"retryAfter"`
+	RetryLimit int                 `yaml:"retryLimit"`
+	Criteria   []WorkflowCriterion `yaml:"criteria"`
+	Reference  string              `yaml:"reference"` // $components.successActions.<name> or $components.failureActions.<name>
+}
+
+// LoadArazzoWorkflows reads the workflows of an Arazzo document describing sequences of calls of doc's operations,
+// so that they can be served as tools like those of LoadWorkflows. Steps name operations by operationId, optionally
+// qualified by the source description ("$sourceDescriptions.petstore.createPet"), or by operationPath
+// ("{$sourceDescriptions.petstore.url}#/paths/~1pets/post"); their parameters and request body payload become the
+// operation tool's arguments. Steps running other workflows and actions going to other workflows are not supported;
+// such workflows are skipped when registered.
+//
+// Example usage for LoadArazzoWorkflows:
+//
+//	workflows, err := openapi2mcp.LoadArazzoWorkflows("onboarding.arazzo.yaml", doc)
+//	if err != nil { log.Fatal(err) }
+//	opts.Workflows = append(opts.Workflows, workflows...)
+func LoadArazzoWorkflows(path string, doc *openapi3.T) ([]Workflow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var arazzo arazzoDocument
+	if err := yaml.Unmarshal(data, &arazzo); err != nil {
+		return nil, fmt.Errorf("invalid Arazzo document %s: %w", path, err)
+	}
+	if !strings.HasPrefix(arazzo.Arazzo, "1.") {
+		return nil, fmt.Errorf("%s is not an Arazzo 1.x document", path)
+	}
+
+	var workflows []Workflow
+	for _, aw := range arazzo.Workflows {
+		w := Workflow{
+			Name:           aw.WorkflowID,
+			Description:    strings.TrimSpace(strings.Join([]string{aw.Summary, aw.Description}, "\n\n")),
+			Inputs:         arazzo.inputs(aw.Inputs),
+			Outputs:        aw.Outputs,
+			SuccessActions: arazzo.actions(aw.SuccessActions, arazzo.Components.SuccessActions),
+			FailureActions: arazzo.actions(aw.FailureActions, arazzo.Components.FailureActions),
+		}
+		for _, as := range aw.Steps {
+			step := WorkflowStep{
+				ID:              as.StepID,
+				Operation:       arazzoOperationID(as, doc),
+				Arguments:       make(map[string]any),
+				Outputs:         as.Outputs,
+				SuccessCriteria: as.SuccessCriteria,
+				OnSuccess:       arazzo.actions(as.OnSuccess, arazzo.Components.SuccessActions),
+				OnFailure:       arazzo.actions(as.OnFailure, arazzo.Components.FailureActions),
+			}
+			for _, p := range as.Parameters {
+				if name, ok := strings.CutPrefix(p.Reference, "$components.parameters."); ok {
+					value := p.Value
+					p = arazzo.Components.Parameters[name]
+					if value != nil {
+						p.Value = value
+					}
+				}
+				step.Arguments[escapeParameterName(p.Name)] = p.Value
+			}
+			if as.RequestBody != nil && as.RequestBody.Payload != nil {
+				step.Arguments["requestBody"] = as.RequestBody.Payload
+			}
+			w.Steps = append(w.Steps, step)
+		}
+		workflows = append(workflows, w)
+	}
+	return workflows, nil
+}
+
+// inputs returns the JSON schema of workflow inputs, resolving a reference to the document's components.
+func (d *arazzoDocument) inputs(schema map[string]any) map[string]any {
+	if ref, ok := schema["$ref"].(string); ok {
+		if name, ok := strings.CutPrefix(ref, "#/components/inputs/"); ok {
+			return d.Components.Inputs[name]
+		}
+	}
+	return schema
+}
+
+// actions converts Arazzo success or failure actions, resolving references to the reusable ones. Actions going to
+// other workflows keep an unsupported type, so that their workflow is skipped.
+func (d *arazzoDocument) actions(actions []arazzoAction, reusable map[string]arazzoAction) []WorkflowAction {
+	var converted []WorkflowAction
+	for _, a := range actions {
+		if a.Reference != "" {
+			name := a.Reference[strings.LastIndex(a.Reference, ".")+1:]
+			a = reusable[name]
+		}
+		action := WorkflowAction{Type: a.Type, StepID: a.StepID, RetryAfter: a.RetryAfter, RetryLimit: a.RetryLimit, Criteria: a.Criteria}
+		if a.WorkflowID != "" {
+			action.Type = a.Type + " to workflow " + a.WorkflowID
+		}
+		converted = append(converted, action)
+	}
+	return converted
+}
+
+// arazzoOperationID returns the operationId of the operation an Arazzo step calls, or "" if the step runs another
+// workflow.
+func arazzoOperationID(step arazzoStep, doc *openapi3.T) string {
+	if rest, ok := strings.CutPrefix(step.OperationID, "$sourceDescriptions."); ok {
+		_, id, _ := strings.Cut(rest, ".")
+		return id
+	}
+	if step.OperationID != "" {
+		return step.OperationID
+	}
+	_, pointer, ok := strings.Cut(step.OperationPath, "#")
+	if !ok || doc == nil || doc.Paths == nil {
+		return step.OperationPath
+	}
+	// #/paths/~1pets~1{petId}/get
+	parts := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	if len(parts) != 3 || parts[0] != "paths" {
+		return step.OperationPath
+	}
+	path := strings.NewReplacer("~1", "/", "~0", "~").Replace(parts[1])
+	method := strings.ToUpper(parts[2])
+	item := doc.Paths.Value(path)
+	if item == nil || item.GetOperation(method) == nil {
+		return step.OperationPath
+	}
+	if id := item.GetOperation(method).OperationID; id != "" {
+		return id
+	}
+	return fmt.Sprintf("%s_%s", method, path) // as named by ExtractOpenAPIOperations
+}
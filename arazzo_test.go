@@ -0,0 +1,144 @@
+package openapi2mcp
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const arazzoSpec = `openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /reports:
+    post:
+      operationId: createReport
+      parameters: [{name: format, in: query, schema: {type: string}}]
+      responses: {"202": {description: accepted}}
+  /reports/{reportId}:
+    parameters: [{name: reportId, in: path, required: true, schema: {type: string}}]
+    get:
+      responses: {"200": {description: ok}}
+`
+
+const arazzoDocumentYAML = `arazzo: 1.0.1
+info: {title: Reports, version: 1.0.0}
+sourceDescriptions:
+  - {name: reports, url: ./reports.yaml, type: openapi}
+workflows:
+  - workflowId: build_report
+    summary: Create a report and wait until it is ready.
+    inputs: {$ref: "#/components/inputs/report"}
+    steps:
+      - stepId: create
+        operationId: $sourceDescriptions.reports.createReport
+        parameters: [{name: format, in: query, value: $inputs.format}]
+        successCriteria: [{condition: $statusCode == 202}]
+        outputs: {id: $response.body#/id}
+      - stepId: poll
+        operationPath: "{$sourceDescriptions.reports.url}#/paths/~1reports~1{reportId}/get"
+        parameters: [{reference: $components.parameters.reportId}]
+        successCriteria:
+          - condition: $statusCode == 200
+          - {context: $response.body#/status, condition: ^done$, type: regex}
+        onFailure: [{reference: $components.failureActions.pollAgain}]
+        outputs: {url: $response.body#/url}
+    outputs:
+      url: $steps.poll.outputs.url
+  - workflowId: nested
+    steps:
+      - {stepId: run, workflowId: build_report}
+components:
+  inputs:
+    report: {type: object, properties: {format: {type: string}}}
+  parameters:
+    reportId: {name: reportId, in: path, value: $steps.create.outputs.id}
+  failureActions:
+    pollAgain: {name: pollAgain, type: retry, retryAfter: 0, retryLimit: 3, criteria: [{condition: $statusCode == 200}]}
+`
+
+func TestLoadArazzoWorkflows(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(arazzoSpec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "reports.arazzo.yaml")
+	if err := os.WriteFile(path, []byte(arazzoDocumentYAML), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	workflows, err := LoadArazzoWorkflows(path, doc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var mu sync.Mutex
+	var sent []string
+	polls := 0
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	toolNames := RegisterOpenAPITools(server, ExtractOpenAPIOperations(doc), doc, &ToolGenOptions{
+		Workflows: workflows,
+		BaseURL:   "http://example.com",
+		RequestHandler: func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			sent = append(sent, req.Method+" "+req.URL.RequestURI())
+			if req.Method == http.MethodPost {
+				return fakeResponse(202, "application/json", `{"id": "r1"}`)(req)
+			}
+			if polls++; polls < 3 {
+				return fakeResponse(200, "application/json", `{"status": "running"}`)(req)
+			}
+			return fakeResponse(200, "application/json", `{"status": "done", "url": "https://example.com/r1.pdf"}`)(req)
+		},
+	})
+	joined := strings.Join(toolNames, ",")
+	if !strings.Contains(joined, "build_report") || strings.Contains(joined, "nested") {
+		t.Errorf("expected build_report but not the workflow running another one, got %v", toolNames)
+	}
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(context.Background(), serverTransport, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil).Connect(context.Background(), clientTransport, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cs.Close()
+	res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "build_report", Arguments: map[string]any{"format": "pdf"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(t, res); res.IsError || !strings.Contains(text, `"url": "https://example.com/r1.pdf"`) {
+		t.Errorf("expected the report URL, got: %s", text)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	want := "POST /reports?format=pdf,GET /reports/r1,GET /reports/r1,GET /reports/r1"
+	if got := strings.Join(sent, ","); got != want {
+		t.Errorf("expected requests %s, got %s", want, got)
+	}
+}
+
+func TestWorkflowState_Condition(t *testing.T) {
+	state := &workflowState{inputs: map[string]any{"limit": 10.0}}
+	response := &linkContext{statusCode: 201, body: map[string]any{"status": "active", "count": 3.0}}
+	for cond, want := range map[string]bool{
+		"$statusCode == 201":                                        true,
+		"$statusCode >= 200 && $statusCode < 300":                   true,
+		"$response.body#/status == 'active'":                        true,
+		"$response.body#/count > $inputs.limit":                     false,
+		"$response.body#/count > $inputs.limit || $statusCode != 1": true,
+		"$response.body#/missing":                                   false,
+		"$response.body#/status":                                    true,
+	} {
+		if got := state.condition(cond, response); got != want {
+			t.Errorf("condition %q: expected %v, got %v", cond, want, got)
+		}
+	}
+}
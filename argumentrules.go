@@ -0,0 +1,188 @@
+// argumentrules.go
+package openapi2mcp
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// ArgumentRules constrain argument values beyond what the schemas say, for restrictions specific to a deployment,
+// e.g. that agents only deploy to staging or only address IDs of their tenant. Calls breaking a rule are rejected
+// before they reach the API, with all broken rules and the values that would be accepted.
+type ArgumentRules struct {
+	Rules []ArgumentRule `json:"rules" yaml:"rules"`
+}
+
+// ArgumentRule constrains the value of one argument of the operations matching Operations (all if empty). Argument
+// names the argument, with "." selecting fields of object arguments (e.g. "requestBody.region"); its value, as a
+// string (objects and arrays as JSON), must match one of the Allow patterns, none of the Deny patterns, and the
+// Pattern regular expression, if set. Allow and Deny entries are path.Match patterns ("staging", "tenant-42-*").
+// Rules skip calls leaving the argument out, unless Required is set.
+type ArgumentRule struct {
+	Operations []string `json:"operations,omitempty" yaml:"operations,omitempty"`
+	Argument   string   `json:"argument" yaml:"argument"`
+	Allow      []string `json:"allow,omitempty" yaml:"allow,omitempty"`
+	Deny       []string `json:"deny,omitempty" yaml:"deny,omitempty"`
+	Pattern    string   `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	Required   bool     `json:"required,omitempty" yaml:"required,omitempty"`
+	Message    string   `json:"message,omitempty" yaml:"message,omitempty"` // shown to the model when the rule is broken
+}
+
+// LoadArgumentRules reads and validates argument rules from a YAML or JSON file:
+//
+//	rules:
+//	  - operations: [deploy*]
+//	    argument: environment
+//	    allow: [staging]
+//	    message: Agents may only deploy to staging
+//	  - argument: customerId
+//	    pattern: ^acme-
+//	    message: Only customers of the acme tenant can be accessed
+//
+// Example usage for LoadArgumentRules:
+//
+//	rules, err := openapi2mcp.LoadArgumentRules("argument-rules.yaml")
+//	if err != nil { log.Fatal(err) }
+//	opts.ArgumentRules = rules
+func LoadArgumentRules(file string) (*ArgumentRules, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var rules ArgumentRules
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("invalid argument rules %s: %w", file, err)
+	}
+	if err := rules.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid argument rules %s: %w", file, err)
+	}
+	return &rules, nil
+}
+
+// Validate checks that every rule names an argument and constrains it with valid patterns.
+func (r *ArgumentRules) Validate() error {
+	for i, rule := range r.Rules {
+		if rule.Argument == "" {
+			return fmt.Errorf("rule %d: argument is missing", i+1)
+		}
+		if len(rule.Allow) == 0 && len(rule.Deny) == 0 && rule.Pattern == "" && !rule.Required {
+			return fmt.Errorf("rule %d: set allow, deny, pattern, or required", i+1)
+		}
+		for _, pattern := range append(append(append([]string(nil), rule.Operations...), rule.Allow...), rule.Deny...) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("rule %d: invalid pattern %q", i+1, pattern)
+			}
+		}
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return fmt.Errorf("rule %d: invalid regular expression %q: %v", i+1, rule.Pattern, err)
+		}
+	}
+	return nil
+}
+
+// forOperation returns the rules applying to calls of the operation.
+func (r *ArgumentRules) forOperation(op OpenAPIOperation) []ArgumentRule {
+	if r == nil {
+		return nil
+	}
+	var rules []ArgumentRule
+	for _, rule := range r.Rules {
+		if len(rule.Operations) == 0 || matchAny(rule.Operations, op.OperationID) {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// checkArgumentRules returns an error for each of the rules the arguments break.
+func checkArgumentRules(rules []ArgumentRule, args map[string]any) []FieldError {
+	var errs []FieldError
+	for _, rule := range rules {
+		value, ok := argumentString(args, rule.Argument)
+		if !ok {
+			if rule.Required {
+				errs = append(errs, FieldError{Field: rule.Argument, Message: ruleMessage(rule, "is required"), Hint: strings.TrimPrefix(rule.expectation(), "required")})
+			}
+			continue
+		}
+		var broken string
+		switch {
+		case len(rule.Allow) > 0 && !matchAny(rule.Allow, value):
+			broken = fmt.Sprintf("%q is not allowed", value)
+		case matchAny(rule.Deny, value):
+			broken = fmt.Sprintf("%q is denied", value)
+		case rule.Pattern != "" && !regexp.MustCompile(rule.Pattern).MatchString(value):
+			broken = fmt.Sprintf("%q does not match %s", value, rule.Pattern)
+		default:
+			continue
+		}
+		errs = append(errs, FieldError{Field: rule.Argument, Message: ruleMessage(rule, broken), Hint: rule.expectation()})
+	}
+	return errs
+}
+
+// ruleMessage returns the rule's message, or else what is wrong with the argument.
+func ruleMessage(rule ArgumentRule, broken string) string {
+	if rule.Message != "" {
+		return rule.Message
+	}
+	return broken
+}
+
+// expectation tells the model which values the rule accepts.
+func (rule ArgumentRule) expectation() string {
+	var parts []string
+	if len(rule.Allow) > 0 {
+		parts = append(parts, "allowed values: "+strings.Join(rule.Allow, ", "))
+	}
+	if len(rule.Deny) > 0 {
+		parts = append(parts, "denied values: "+strings.Join(rule.Deny, ", "))
+	}
+	if rule.Pattern != "" {
+		parts = append(parts, "values must match "+rule.Pattern)
+	}
+	if len(parts) == 0 {
+		return "required"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// describeArgumentRules tells the model up front which argument values the server accepts.
+func describeArgumentRules(rules []ArgumentRule) string {
+	if len(rules) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\n\nARGUMENT RULES: This server only accepts calls where:")
+	for _, rule := range rules {
+		values := rule.expectation()
+		if rule.Required && values != "required" {
+			values += " (required)"
+		}
+		fmt.Fprintf(&sb, "\n- %s: %s", rule.Argument, values)
+		if rule.Message != "" {
+			fmt.Fprintf(&sb, " (%s)", strings.TrimSuffix(rule.Message, "."))
+		}
+	}
+	return sb.String()
+}
+
+// argumentRulesText explains a call that was rejected for breaking argument rules.
+func argumentRulesText(errs []FieldError, operationID, callID string) string {
+	var sb strings.Builder
+	sb.WriteString("Arguments not permitted: the server's argument rules reject this call.\n")
+	for _, e := range errs {
+		fmt.Fprintf(&sb, "• %s: %s", e.Field, e.Message)
+		if e.Hint != "" {
+			fmt.Fprintf(&sb, " (%s)", e.Hint)
+		}
+		sb.WriteString("\n")
+	}
+	fmt.Fprintf(&sb, "Correct these arguments and retry, or ask the user if the call needs other values.\nOperation: %s\nCall ID: %s", operationID, callID)
+	return sb.String()
+}
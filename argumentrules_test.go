@@ -0,0 +1,99 @@
+package openapi2mcp
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestLoadArgumentRules(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "rules.yaml")
+	os.WriteFile(valid, []byte("rules:\n  - operations: [deploy*]\n    argument: environment\n    allow: [staging]\n"), 0o600)
+	rules, err := LoadArgumentRules(valid)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rules.Rules) != 1 || rules.Rules[0].Allow[0] != "staging" {
+		t.Errorf("unexpected rules: %+v", rules)
+	}
+
+	for name, content := range map[string]string{
+		"no-argument.yaml":   "rules:\n  - allow: [staging]\n",
+		"no-condition.yaml":  "rules:\n  - argument: environment\n",
+		"bad-pattern.yaml":   "rules:\n  - argument: environment\n    allow: ['[']\n",
+		"bad-regexp.yaml":    "rules:\n  - argument: environment\n    pattern: '('\n",
+		"bad-operation.yaml": "rules:\n  - argument: environment\n    operations: ['[']\n    required: true\n",
+	} {
+		file := filepath.Join(dir, name)
+		os.WriteFile(file, []byte(content), 0o600)
+		if _, err := LoadArgumentRules(file); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestToolHandler_ArgumentRules(t *testing.T) {
+	var sent int
+	opts := &ToolGenOptions{
+		ErrorFormat: ErrorFormatBoth,
+		ArgumentRules: &ArgumentRules{Rules: []ArgumentRule{
+			{Operations: []string{"deploy*"}, Argument: "environment", Allow: []string{"staging"}, Message: "Agents may only deploy to staging"},
+			{Argument: "requestBody.customerId", Pattern: "^acme-", Required: true},
+			{Operations: []string{"other"}, Argument: "environment", Deny: []string{"*"}},
+		}},
+		RequestHandler: func(req *http.Request) (*http.Response, error) {
+			sent++
+			return fakeResponse(200, "application/json", `{}`)(req)
+		},
+	}
+	op := OpenAPIOperation{OperationID: "deployRelease", Path: "/deploy", Method: "get"}
+	handler := toolHandler("deployRelease", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, newServerRuntime())
+
+	res, _, err := handler(context.Background(), nil, map[string]any{"environment": "production", "requestBody": map[string]any{"customerId": "globex-1"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(t, res)
+	if toolErr, _ := res.StructuredContent.(map[string]any)["error"].(*ToolError); toolErr == nil || toolErr.Code != "argument_not_permitted" || len(toolErr.FieldErrors) != 2 {
+		t.Errorf("expected an argument_not_permitted error with both arguments, got %+v", res.StructuredContent)
+	}
+	for _, want := range []string{
+		"• environment: Agents may only deploy to staging (allowed values: staging)",
+		`• requestBody.customerId: "globex-1" does not match ^acme- (values must match ^acme-)`,
+	} {
+		if !res.IsError || !strings.Contains(text, want) {
+			t.Errorf("expected %q in the error, got: %s", want, text)
+		}
+	}
+
+	res, _, _ = handler(context.Background(), nil, map[string]any{"environment": "staging"})
+	if text := resultText(t, res); !strings.Contains(text, "requestBody.customerId: is required") {
+		t.Errorf("expected the required argument to be reported, got: %s", text)
+	}
+	if sent != 0 {
+		t.Fatalf("expected no requests for rejected calls, got %d", sent)
+	}
+
+	res, _, _ = handler(context.Background(), nil, map[string]any{"environment": "staging", "requestBody": map[string]any{"customerId": "acme-7"}})
+	if res.IsError || sent != 1 {
+		t.Errorf("expected the permitted call to be sent, got: %s", resultText(t, res))
+	}
+}
+
+func TestDescribeArgumentRules(t *testing.T) {
+	desc := describeArgumentRules([]ArgumentRule{
+		{Argument: "environment", Allow: []string{"staging", "dev-*"}},
+		{Argument: "tenant", Required: true},
+	})
+	for _, want := range []string{"- environment: allowed values: staging, dev-*", "- tenant: required"} {
+		if !strings.Contains(desc, want) {
+			t.Errorf("expected %q in the description, got %q", want, desc)
+		}
+	}
+}
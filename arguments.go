@@ -0,0 +1,301 @@
+// arguments.go
+package openapi2mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// toolArguments describes the arguments of a registered tool, for checking calls before they reach its handler.
+type toolArguments struct {
+	op      OpenAPIOperation
+	schema  *jsonschema.Schema
+	presets map[string]ArgumentPreset // filled in before the server validates the arguments
+}
+
+// missingArgument is a required argument, or a required field within one, that a call left out.
+type missingArgument struct {
+	path   string             // argument name, followed by the field names within it, e.g. "requestBody.customer.email"
+	schema *jsonschema.Schema // schema of the missing value
+}
+
+// misnamedArgument is an argument a call sent under a name the tool doesn't have, though it closely matches one.
+type misnamedArgument struct {
+	sent      string // name the call used, e.g. "filter[created_at]"
+	want      string // argument it stands for, e.g. "filter_created_at_"
+	duplicate bool   // whether the call also sent want itself
+}
+
+// resolveLocalRef returns the subschema a local reference such as "#/properties/requestBody/properties/owner"
+// points to within root, as emitted for repeated component schemas; other schemas are returned as they are.
+func resolveLocalRef(root, s *jsonschema.Schema) *jsonschema.Schema {
+	if s == nil || !strings.HasPrefix(s.Ref, "#/") {
+		return s
+	}
+	cur := root
+	parts := strings.Split(strings.TrimPrefix(s.Ref, "#/"), "/")
+	for len(parts) > 0 && cur != nil {
+		key := parts[0]
+		if key == "items" {
+			cur, parts = cur.Items, parts[1:]
+			continue
+		}
+		if len(parts) < 2 {
+			return nil
+		}
+		arg := parts[1]
+		parts = parts[2:]
+		switch key {
+		case "properties":
+			cur = cur.Properties[jsonPointerUnescaper.Replace(arg)]
+		case "allOf", "oneOf", "anyOf":
+			list := map[string][]*jsonschema.Schema{"allOf": cur.AllOf, "oneOf": cur.OneOf, "anyOf": cur.AnyOf}[key]
+			n, err := strconv.Atoi(arg)
+			if err != nil || n < 0 || n >= len(list) {
+				return nil
+			}
+			cur = list[n]
+		default:
+			return nil
+		}
+	}
+	return cur
+}
+
+// findMissingArguments lists the required properties of s, and of the objects nested in its present properties
+// (including those required by its allOf subschemas), that value lacks. Paths are prefixed with prefix.
+func findMissingArguments(root, s *jsonschema.Schema, value map[string]any, prefix string) []missingArgument {
+	s = resolveLocalRef(root, s)
+	if s == nil {
+		return nil
+	}
+	join := func(name string) string {
+		if prefix == "" {
+			return name
+		}
+		return prefix + "." + name
+	}
+
+	var missing []missingArgument
+	for _, name := range s.Required {
+		if _, ok := value[name]; !ok {
+			missing = append(missing, missingArgument{path: join(name), schema: resolveLocalRef(root, s.Properties[name])})
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(s.Properties)) {
+		if nested, ok := value[name].(map[string]any); ok {
+			missing = append(missing, findMissingArguments(root, s.Properties[name], nested, join(name))...)
+		}
+	}
+	for _, sub := range s.AllOf {
+		missing = append(missing, findMissingArguments(root, sub, value, prefix)...)
+	}
+	return missing
+}
+
+// expectedType names the JSON type a schema expects, e.g. "string" or "integer|null".
+func expectedType(s *jsonschema.Schema) string {
+	switch {
+	case s == nil:
+		return "any"
+	case s.Type != "":
+		return s.Type
+	case len(s.Types) > 0:
+		return strings.Join(s.Types, "|")
+	case s.Properties != nil:
+		return "object"
+	}
+	return "any"
+}
+
+// argumentNameKey reduces an argument name to its lowercase letters and digits, so that near misses such as
+// "Filter[createdAt]" and "filter_created_at_" compare equal.
+func argumentNameKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+// findMisnamedArguments lists the arguments of a call that aren't properties of the input schema but closely
+// match one: a parameter's unescaped name (e.g. "filter[created_at]" for "filter_created_at_"), or a name that
+// differs only in case and punctuation. Arguments also sent under their correct name are marked as duplicates.
+func findMisnamedArguments(schema *jsonschema.Schema, params openapi3.Parameters, args map[string]any) []misnamedArgument {
+	unescaped := make(map[string]string) // original parameter name -> escaped name
+	for escaped, original := range buildParameterNameMapping(params) {
+		unescaped[original] = escaped
+	}
+	byKey := make(map[string]string, len(schema.Properties))
+	for name := range schema.Properties {
+		byKey[argumentNameKey(name)] = name
+	}
+
+	var misnamed []misnamedArgument
+	for _, sent := range slices.Sorted(maps.Keys(args)) {
+		if _, ok := schema.Properties[sent]; ok {
+			continue
+		}
+		want, ok := unescaped[sent]
+		if !ok {
+			if want, ok = byKey[argumentNameKey(sent)]; !ok {
+				continue
+			}
+		}
+		_, duplicate := args[want]
+		misnamed = append(misnamed, misnamedArgument{sent: sent, want: want, duplicate: duplicate})
+	}
+	return misnamed
+}
+
+// missingArgumentsExample builds arguments holding example values of just the missing ones, nested as in the call,
+// e.g. {"requestBody": {"customer": {"email": "user@example.com"}}}.
+func missingArgumentsExample(missing []missingArgument, gens ExampleGenerators) map[string]any {
+	example := map[string]any{}
+	for _, m := range missing {
+		parts := strings.Split(m.path, ".")
+		obj := example
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := obj[part].(map[string]any)
+			if !ok {
+				next = map[string]any{}
+				obj[part] = next
+			}
+			obj = next
+		}
+		obj[parts[len(parts)-1]] = generateExampleValueFromSchema(m.schema, gens)
+	}
+	return example
+}
+
+// argumentErrorsResult builds the error result of a call lacking required arguments or using near misses of
+// argument names. Unlike the server's validation error, it names nested requestBody fields, shows an example of
+// only what is missing, and gives the exact name of each misnamed argument.
+func argumentErrorsResult(op OpenAPIOperation, inputSchema *jsonschema.Schema, missing []missingArgument, misnamed []misnamedArgument, opts *ToolGenOptions) *mcp.CallToolResult {
+	var gens ExampleGenerators
+	var format string
+	if opts != nil {
+		gens, format = opts.ExampleGenerators, opts.ErrorFormat
+	}
+
+	var response strings.Builder
+	code, message := "missing_arguments", fmt.Sprintf("%d required argument(s) missing", len(missing))
+	if len(missing) > 0 {
+		response.WriteString("MISSING ARGUMENTS: The call lacks required arguments.\n\n")
+	} else {
+		code, message = "misnamed_arguments", fmt.Sprintf("%d argument(s) misnamed", len(misnamed))
+		response.WriteString("MISNAMED ARGUMENTS: The call uses argument names the tool doesn't have.\n\n")
+	}
+	response.WriteString(fmt.Sprintf("OPERATION: %s", op.OperationID))
+	if op.Summary != "" {
+		response.WriteString(fmt.Sprintf(" - %s", op.Summary))
+	}
+	response.WriteString("\n")
+
+	fieldErrors := make([]FieldError, 0, len(missing)+len(misnamed))
+	if len(misnamed) > 0 {
+		response.WriteString("\nMISNAMED:\n")
+	}
+	for _, m := range misnamed {
+		hint := "use " + m.want
+		if m.duplicate {
+			response.WriteString(fmt.Sprintf("• %s duplicates %s; send it only once, as %s\n", m.sent, m.want, m.want))
+			hint = "remove it and keep " + m.want
+		} else {
+			response.WriteString(fmt.Sprintf("• %s: did you mean %s?\n", m.sent, m.want))
+		}
+		fieldErrors = append(fieldErrors, FieldError{Field: m.sent, Message: "unknown argument", Hint: hint})
+	}
+
+	if len(missing) > 0 {
+		response.WriteString("\nMISSING:\n")
+	}
+	for _, m := range missing {
+		typ := expectedType(m.schema)
+		response.WriteString(fmt.Sprintf("• %s (%s)", m.path, typ))
+		if m.schema != nil && m.schema.Description != "" {
+			response.WriteString(": " + m.schema.Description)
+		}
+		response.WriteString("\n")
+		fieldErrors = append(fieldErrors, FieldError{
+			Field:   m.path,
+			Message: fmt.Sprintf("required %s is missing", typ),
+			Hint:    fieldHint(*inputSchema, m.path),
+		})
+	}
+
+	if len(missing) > 0 {
+		exampleJSON, _ := json.MarshalIndent(missingArgumentsExample(missing, gens), "", "  ")
+		response.WriteString(fmt.Sprintf("\nADD TO YOUR ARGUMENTS, e.g.:\n%s\n", exampleJSON))
+	}
+	response.WriteString("\nKeep the other arguments you sent as they are and retry with these corrected.")
+
+	toolErr := &ToolError{
+		Code:        code,
+		Message:     message,
+		FieldErrors: fieldErrors,
+		Operation:   op.OperationID,
+		DocsURL:     operationDocsURL(op),
+	}
+	return toolErrorResult(response.String(), toolErr, format)
+}
+
+// toolArgumentsMiddleware returns MCP receiving middleware for calls to the given tools, by name. If
+// opts.CoerceArguments is set, it converts stringly-typed arguments before the server validates them. Calls the
+// server rejects for lacking required arguments, or for near misses of argument names, get a tool error
+// listing them instead of a protocol error.
+func toolArgumentsMiddleware(tools map[string]toolArguments, opts *ToolGenOptions) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			call, ok := req.(*mcp.CallToolRequest)
+			if !ok || call.Params == nil {
+				return next(ctx, method, req)
+			}
+			tool, ok := tools[call.Params.Name]
+			if !ok {
+				return next(ctx, method, req)
+			}
+			if len(tool.presets) > 0 {
+				var args map[string]any
+				if json.Unmarshal(call.Params.Arguments, &args) == nil && args[presetArgument] != nil {
+					expanded, ok := applyPreset(tool.presets, args)
+					if !ok {
+						return unknownPresetResult(tool.op, tool.presets, args[presetArgument], "", opts.ErrorFormat), nil
+					}
+					if data, err := json.Marshal(expanded); err == nil {
+						call.Params.Arguments = data
+					}
+				}
+			}
+			if opts != nil && opts.CoerceArguments {
+				call.Params.Arguments = coerceArguments(tool.schema, call.Params.Arguments)
+			}
+
+			result, err := next(ctx, method, req)
+			if err == nil {
+				return result, nil
+			}
+			var args map[string]any
+			if len(call.Params.Arguments) > 0 && json.Unmarshal(call.Params.Arguments, &args) != nil {
+				return result, err
+			}
+			missing := findMissingArguments(tool.schema, tool.schema, args, "")
+			misnamed := findMisnamedArguments(tool.schema, tool.op.Parameters, args)
+			if len(missing) == 0 && len(misnamed) == 0 {
+				return result, err
+			}
+			return argumentErrorsResult(tool.op, tool.schema, missing, misnamed, opts), nil
+		}
+	}
+}
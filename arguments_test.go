@@ -0,0 +1,141 @@
+package openapi2mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestFindMissingArguments(t *testing.T) {
+	address := &jsonschema.Schema{Type: "object", Required: []string{"city"}, Properties: map[string]*jsonschema.Schema{"city": {Type: "string"}}}
+	schema := &jsonschema.Schema{
+		Type:     "object",
+		Required: []string{"id", "requestBody"},
+		Properties: map[string]*jsonschema.Schema{
+			"id": {Type: "integer"},
+			"requestBody": {
+				Type:     "object",
+				Required: []string{"customer"},
+				Properties: map[string]*jsonschema.Schema{
+					"customer": {
+						Type:     "object",
+						Required: []string{"email", "name"},
+						Properties: map[string]*jsonschema.Schema{
+							"email":   {Type: "string", Format: "email"},
+							"name":    {Type: "string"},
+							"billing": address,
+							"address": {Ref: "#/properties/requestBody/properties/customer/properties/billing"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	args := map[string]any{"requestBody": map[string]any{"customer": map[string]any{"name": "Ann", "address": map[string]any{}}}}
+	var paths []string
+	for _, m := range findMissingArguments(schema, schema, args, "") {
+		paths = append(paths, m.path+" ("+expectedType(m.schema)+")")
+	}
+	want := "id (integer), requestBody.customer.email (string), requestBody.customer.address.city (string)"
+	if got := strings.Join(paths, ", "); got != want {
+		t.Errorf("findMissingArguments() = %s, want %s", got, want)
+	}
+
+	example := missingArgumentsExample(findMissingArguments(schema, schema, args, ""), nil)
+	customer := example["requestBody"].(map[string]any)["customer"].(map[string]any)
+	if customer["email"] != "user@example.com" || customer["name"] != nil || example["id"] == nil {
+		t.Errorf("expected an example of only the missing arguments, got %v", example)
+	}
+}
+
+func TestRegisterOpenAPITools_MissingArguments(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+servers: [{url: "https://api.example.com"}]
+paths:
+  /orders:
+    post:
+      operationId: createOrder
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [customer]
+              properties:
+                customer:
+                  type: object
+                  required: [email]
+                  properties:
+                    email: {type: string, format: email, description: Customer e-mail}
+                    name: {type: string}
+                note: {type: string}
+      responses: {"201": {description: created}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	RegisterOpenAPITools(server, ExtractOpenAPIOperations(doc), doc, &ToolGenOptions{
+		RequestHandler: fakeResponse(201, "application/json", `{}`),
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(context.Background(), serverTransport, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil).Connect(context.Background(), clientTransport, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cs.Close()
+
+	res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "createOrder", Arguments: map[string]any{"requestBody": map[string]any{"customer": map[string]any{"name": "Ann"}}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := res.Content[0].(*mcp.TextContent).Text
+	if !res.IsError || !strings.Contains(text, "• requestBody.customer.email (string): Customer e-mail") {
+		t.Fatalf("expected the nested field to be reported, got %s", text)
+	}
+	if !strings.Contains(text, `"email": "user@example.com"`) || strings.Contains(text, `"note"`) || strings.Contains(text, `"name"`) {
+		t.Errorf("expected an example of only the missing field, got %s", text)
+	}
+}
+
+func TestFindMisnamedArguments(t *testing.T) {
+	params := openapi3.Parameters{
+		{Value: &openapi3.Parameter{Name: "filter[created_at]", In: "query"}},
+		{Value: &openapi3.Parameter{Name: "pageSize", In: "query"}},
+	}
+	schema := &jsonschema.Schema{Type: "object", Properties: map[string]*jsonschema.Schema{
+		"filter_created_at_": {Type: "string"},
+		"pageSize":           {Type: "integer"},
+		"status":             {Type: "string"},
+	}}
+	args := map[string]any{"filter[created_at]": "2024-01-01", "page_size": 10, "Status": "open", "status": "open", "other": 1}
+
+	var got []string
+	for _, m := range findMisnamedArguments(schema, params, args) {
+		got = append(got, fmt.Sprintf("%s→%s %v", m.sent, m.want, m.duplicate))
+	}
+	want := "Status→status true, filter[created_at]→filter_created_at_ false, page_size→pageSize false"
+	if strings.Join(got, ", ") != want {
+		t.Errorf("findMisnamedArguments() = %s, want %s", strings.Join(got, ", "), want)
+	}
+
+	res := argumentErrorsResult(OpenAPIOperation{OperationID: "listItems"}, schema, nil, findMisnamedArguments(schema, params, args), nil)
+	text := res.Content[0].(*mcp.TextContent).Text
+	for _, want := range []string{"MISNAMED ARGUMENTS", "• filter[created_at]: did you mean filter_created_at_?", "• Status duplicates status; send it only once, as status"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in %s", want, text)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// async.go
+package openapi2mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Defaults of AsyncPolling.
+const (
+	DefaultAsyncPollInterval = 2 * time.Second
+	DefaultAsyncPollMaxWait  = 30 * time.Second
+)
+
+// AsyncPolling configures how tools wait for operations the API accepted for asynchronous processing
+// (202 Accepted with a status URL). The status URL is polled with GET until it answers with another status.
+type AsyncPolling struct {
+	Interval time.Duration // delay between polls unless the API sends Retry-After; 0 means DefaultAsyncPollInterval
+	MaxWait  time.Duration // polling gives up after this time and returns the pending result; 0 means DefaultAsyncPollMaxWait
+}
+
+// asyncPending is the structured content of the result of an accepted operation that is still running.
+type asyncPending struct {
+	StatusURL  string    `json:"status_url"`
+	RetryAfter int       `json:"retry_after,omitempty"` // Seconds to wait before checking the status, from Retry-After
+	NextCall   *nextCall `json:"next_call,omitempty"`   // Tool call checking the status, if a GET operation matches the status URL
+}
+
+// nextCall is a ready-made tool call suggested to the model.
+type nextCall struct {
+	Tool      string         `json:"tool"`
+	Arguments map[string]any `json:"arguments"`
+}
+
+// asyncStatusURL returns the URL at which the status of an accepted operation can be checked: the Location,
+// Operation-Location, or Content-Location header, or a statusUrl/status_url field of a JSON body.
+// Relative URLs are resolved against the request URL. Returns nil if the response has none.
+func asyncStatusURL(resp *http.Response, body []byte) *url.URL {
+	var ref string
+	for _, name := range []string{"Location", "Operation-Location", "Content-Location"} {
+		if ref = resp.Header.Get(name); ref != "" {
+			break
+		}
+	}
+	if ref == "" {
+		var fields map[string]any
+		if json.Unmarshal(body, &fields) == nil {
+			for _, name := range []string{"statusUrl", "status_url", "statusURL"} {
+				if s, ok := fields[name].(string); ok && s != "" {
+					ref = s
+					break
+				}
+			}
+		}
+	}
+	if ref == "" {
+		return nil
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return nil
+	}
+	if resp.Request != nil && resp.Request.URL != nil {
+		u = resp.Request.URL.ResolveReference(u)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil
+	}
+	return u
+}
+
+// pollStatus polls statusURL with GET until it answers with a status other than 202, and returns that response
+// with an unread body. Returns nil if the operation is still pending after polling.MaxWait. Headers of the
+// original request, including credentials, are only sent if the status URL is on the same host.
+func pollStatus(ctx context.Context, requestHandler func(*http.Request) (*http.Response, error), orig *http.Request, statusURL *url.URL, retryAfter int, polling *AsyncPolling) (*http.Response, error) {
+	interval := DefaultAsyncPollInterval
+	if polling.Interval > 0 {
+		interval = polling.Interval
+	}
+	maxWait := DefaultAsyncPollMaxWait
+	if polling.MaxWait > 0 {
+		maxWait = polling.MaxWait
+	}
+	deadline := time.Now().Add(maxWait)
+
+	for {
+		wait := interval
+		if retryAfter > 0 {
+			wait = time.Duration(retryAfter) * time.Second
+		}
+		if time.Now().Add(wait).After(deadline) {
+			return nil, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, statusURL.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		if strings.EqualFold(statusURL.Host, orig.URL.Host) {
+			req.Header = orig.Header.Clone()
+			req.Header.Del("Content-Type")
+		} else {
+			req.Header.Set("Accept", orig.Header.Get("Accept"))
+		}
+		resp, err := requestHandler(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusAccepted {
+			return resp, nil
+		}
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+		resp.Body.Close()
+		retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+	}
+}
+
+// statusOperationCall suggests the call of a GET operation tool whose path matches statusURL, relative to
+// one of baseURLs, with the path and query parameters taken from the URL. Returns nil if none matches.
+func statusOperationCall(statusURL *url.URL, baseURLs []string, registry *OperationRegistry) *nextCall {
+	var best *nextCall
+	bestLiterals := -1
+	for _, base := range baseURLs {
+		b, err := url.Parse(base)
+		if err != nil || !strings.EqualFold(b.Host, statusURL.Host) {
+			continue
+		}
+		rel, ok := strings.CutPrefix(statusURL.EscapedPath(), strings.TrimSuffix(b.EscapedPath(), "/"))
+		if !ok {
+			continue
+		}
+		for _, name := range registry.ToolNames() {
+			op, _ := registry.Operation(name)
+			if !strings.EqualFold(op.Method, http.MethodGet) {
+				continue
+			}
+			args, literals, ok := matchPathTemplate(op.Path, rel)
+			if !ok || literals <= bestLiterals {
+				continue
+			}
+			for _, p := range op.Parameters {
+				if p != nil && p.Value != nil && p.Value.In == "query" && statusURL.Query().Has(p.Value.Name) {
+					args[escapeParameterName(p.Value.Name)] = statusURL.Query().Get(p.Value.Name)
+				}
+			}
+			best, bestLiterals = &nextCall{Tool: name, Arguments: args}, literals
+		}
+	}
+	return best
+}
+
+// matchPathTemplate matches path against an OpenAPI path template such as "/jobs/{id}". It returns the values
+// of the template's parameters and the number of literal segments, so that the most specific template can win.
+func matchPathTemplate(template, path string) (map[string]any, int, bool) {
+	tsegs := strings.Split(strings.Trim(template, "/"), "/")
+	psegs := strings.Split(strings.Trim(path, "/"), "/")
+	if len(tsegs) != len(psegs) {
+		return nil, 0, false
+	}
+	args := map[string]any{}
+	literals := 0
+	for i, tseg := range tsegs {
+		if name, ok := strings.CutPrefix(tseg, "{"); ok && strings.HasSuffix(name, "}") {
+			value, err := url.PathUnescape(psegs[i])
+			if err != nil || value == "" {
+				return nil, 0, false
+			}
+			args[escapeParameterName(strings.TrimSuffix(name, "}"))] = value
+		} else if tseg == psegs[i] {
+			literals++
+		} else {
+			return nil, 0, false
+		}
+	}
+	return args, literals, true
+}
+
+// pendingResult renders an accepted operation that is still running, telling the model where and when to check
+// its status, with a ready-made tool call if one matches.
+func pendingResult(method, fullURL string, status int, callID, headers string, body []byte, pending asyncPending) *mcp.CallToolResult {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "HTTP %s %s\nStatus: %d\nCall ID: %s\n%s", method, fullURL, status, callID, headers)
+	fmt.Fprintf(&sb, "ACCEPTED: The API is processing the request asynchronously; it has not completed yet.\nStatus URL: %s\n", pending.StatusURL)
+	if pending.RetryAfter > 0 {
+		fmt.Fprintf(&sb, "Check again in %d seconds.\n", pending.RetryAfter)
+	}
+	if pending.NextCall != nil {
+		argsJSON, _ := json.Marshal(pending.NextCall.Arguments)
+		fmt.Fprintf(&sb, "NEXT STEP: call %s with %s to check whether it has completed.\n", pending.NextCall.Tool, argsJSON)
+	}
+	if len(body) > 0 {
+		sb.WriteString("Response:\n")
+		sb.Write(body)
+	}
+	return &mcp.CallToolResult{
+		Content:           []mcp.Content{&mcp.TextContent{Text: strings.TrimSuffix(sb.String(), "\n")}},
+		StructuredContent: map[string]any{"pending": pending},
+	}
+}
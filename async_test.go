@@ -0,0 +1,125 @@
+package openapi2mcp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestToolHandler_AsyncAccepted(t *testing.T) {
+	createJob := OpenAPIOperation{OperationID: "createJob", Path: "/jobs", Method: "post"}
+	getJob := OpenAPIOperation{OperationID: "getJob", Path: "/jobs/{id}", Method: "get", Parameters: openapi3.Parameters{
+		{Value: &openapi3.Parameter{Name: "id", In: "path", Required: true}},
+		{Value: &openapi3.Parameter{Name: "verbose", In: "query"}},
+	}}
+	rt := newServerRuntime()
+	rt.ops.add("getJob", getJob, nil)
+	rt.ops.add("getJobs", OpenAPIOperation{OperationID: "getJobs", Path: "/{collection}/{id}", Method: "get"}, nil)
+
+	var polls int
+	retryAfter := "5"
+	opts := &ToolGenOptions{RequestHandler: func(req *http.Request) (*http.Response, error) {
+		resp := &http.Response{StatusCode: http.StatusAccepted, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{"state":"queued"}`)), Request: req}
+		resp.Header.Set("Content-Type", "application/json")
+		if req.Method == http.MethodPost {
+			resp.Header.Set("Location", "/api/jobs/42?verbose=true")
+			resp.Header.Set("Retry-After", retryAfter)
+			return resp, nil
+		}
+		if polls++; polls < 3 {
+			return resp, nil
+		}
+		resp.StatusCode = http.StatusOK
+		resp.Body = io.NopCloser(strings.NewReader(`{"state":"done"}`))
+		return resp, nil
+	}}
+	call := func() string {
+		handler := toolHandler("createJob", createJob, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com/api"}, opts, rt)
+		res, _, err := handler(context.Background(), nil, map[string]any{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res.IsError {
+			t.Fatalf("unexpected error result: %s", resultText(t, res))
+		}
+		return resultText(t, res)
+	}
+
+	text := call()
+	for _, want := range []string{
+		"Status: 202",
+		"Status URL: http://example.com/api/jobs/42?verbose=true",
+		"Check again in 5 seconds.",
+		`NEXT STEP: call getJob with {"id":"42","verbose":"true"} to check whether it has completed.`,
+		`Response:` + "\n" + `{"state":"queued"}`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected pending result to contain %q, got: %s", want, text)
+		}
+	}
+
+	// Polling waits for the final status
+	retryAfter = ""
+	opts.AsyncPolling = &AsyncPolling{Interval: time.Millisecond, MaxWait: time.Minute}
+	if text := call(); polls != 3 || !strings.Contains(text, "Status: 200") || !strings.Contains(text, `{"state":"done"}`) {
+		t.Errorf("expected the final status after 3 polls, got %d polls: %s", polls, text)
+	}
+
+	// A Retry-After beyond MaxWait returns the pending result right away
+	polls = 0
+	retryAfter = "5"
+	opts.AsyncPolling.MaxWait = time.Second
+	if text := call(); polls != 0 || !strings.Contains(text, "Status: 202") {
+		t.Errorf("expected no polls, got %d: %s", polls, text)
+	}
+}
+
+func TestToolHandler_AsyncPollingSendsSessionCookies(t *testing.T) {
+	createJob := OpenAPIOperation{OperationID: "createJob", Path: "/jobs", Method: "post"}
+	var polls, rejected int
+	opts := &ToolGenOptions{SessionCookies: true, AsyncPolling: &AsyncPolling{Interval: time.Millisecond, MaxWait: time.Minute}, RequestHandler: func(req *http.Request) (*http.Response, error) {
+		resp := &http.Response{StatusCode: http.StatusAccepted, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{"state":"queued"}`)), Request: req}
+		resp.Header.Set("Content-Type", "application/json")
+		if req.Method == http.MethodPost {
+			resp.Header.Set("Location", "/api/jobs/42")
+			resp.Header.Set("Set-Cookie", "session=abc; Path=/")
+			return resp, nil
+		}
+		if cookie, err := req.Cookie("session"); err != nil || cookie.Value != "abc" {
+			rejected++
+			resp.StatusCode = http.StatusUnauthorized
+			resp.Body = io.NopCloser(strings.NewReader(`{"error":"no session"}`))
+			return resp, nil
+		}
+		if polls++; polls < 2 {
+			return resp, nil
+		}
+		resp.StatusCode = http.StatusOK
+		resp.Body = io.NopCloser(strings.NewReader(`{"state":"done"}`))
+		return resp, nil
+	}}
+	handler := toolHandler("createJob", createJob, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com/api"}, opts, newServerRuntime())
+	res, _, err := handler(context.Background(), nil, map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(t, res); rejected > 0 || polls != 2 || !strings.Contains(text, "Status: 200") || !strings.Contains(text, `{"state":"done"}`) {
+		t.Errorf("expected the polls to send the session cookie, got %d polls, %d rejected: %s", polls, rejected, text)
+	}
+}
+
+func TestMatchPathTemplate(t *testing.T) {
+	args, literals, ok := matchPathTemplate("/jobs/{id}/status", "/jobs/a%2Fb/status")
+	if !ok || literals != 2 || args["id"] != "a/b" {
+		t.Errorf("matchPathTemplate() = %v, %d, %v", args, literals, ok)
+	}
+	if _, _, ok := matchPathTemplate("/jobs/{id}", "/tasks/1"); ok {
+		t.Error("expected no match for a different literal segment")
+	}
+}
@@ -0,0 +1,38 @@
+// baseurl.go
+package openapi2mcp
+
+import (
+	"slices"
+	"strings"
+)
+
+// BaseURLOverride sends the requests of some operations to another base URL than the rest of the document,
+// e.g. the operations below /admin to an internal host. If both Tag and PathPrefix are set, operations
+// must match both.
+type BaseURLOverride struct {
+	Tag        string // operations having this tag
+	PathPrefix string // operations whose path is this prefix or lies below it, matching whole segments ("/admin")
+	URL        string // base URL of the matching operations
+}
+
+// matches reports whether the override applies to the operation.
+func (o BaseURLOverride) matches(op OpenAPIOperation) bool {
+	if o.Tag == "" && o.PathPrefix == "" {
+		return false
+	}
+	if o.Tag != "" && !slices.Contains(op.Tags, o.Tag) {
+		return false
+	}
+	prefix := strings.TrimSuffix(o.PathPrefix, "/")
+	return prefix == "" || op.Path == prefix || strings.HasPrefix(op.Path, prefix+"/")
+}
+
+// operationBaseURLs returns the base URLs of the operation: that of the first matching override, else baseURLs.
+func operationBaseURLs(overrides []BaseURLOverride, op OpenAPIOperation, baseURLs []string) []string {
+	for _, o := range overrides {
+		if o.URL != "" && o.matches(op) {
+			return []string{o.URL}
+		}
+	}
+	return baseURLs
+}
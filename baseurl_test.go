@@ -0,0 +1,81 @@
+package openapi2mcp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestOperationBaseURLs(t *testing.T) {
+	overrides := []BaseURLOverride{
+		{Tag: "billing", PathPrefix: "/admin", URL: "https://billing-admin.internal"},
+		{PathPrefix: "/admin/", URL: "http://admin.internal"},
+		{Tag: "billing", URL: "https://billing.example.com"},
+		{URL: "https://ignored.example.com"},
+	}
+	defaults := []string{"https://api.example.com"}
+	tests := []struct {
+		op   OpenAPIOperation
+		want string
+	}{
+		{OpenAPIOperation{Path: "/admin/users", Tags: []string{"billing"}}, "https://billing-admin.internal"},
+		{OpenAPIOperation{Path: "/admin"}, "http://admin.internal"},
+		{OpenAPIOperation{Path: "/administrators"}, "https://api.example.com"},
+		{OpenAPIOperation{Path: "/invoices", Tags: []string{"billing"}}, "https://billing.example.com"},
+		{OpenAPIOperation{Path: "/pets"}, "https://api.example.com"},
+	}
+	for _, tt := range tests {
+		if got := operationBaseURLs(overrides, tt.op, defaults); strings.Join(got, ",") != tt.want {
+			t.Errorf("operationBaseURLs(%s %v) = %v, want %s", tt.op.Path, tt.op.Tags, got, tt.want)
+		}
+	}
+}
+
+func TestRegisterOpenAPITools_BaseURLOverrides(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+servers: [{url: "https://api.example.com/v1"}]
+paths:
+  /pets:
+    get: {operationId: listPets, responses: {"200": {description: ok}}}
+  /admin/users:
+    get: {operationId: listUsers, responses: {"200": {description: ok}}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var requested string
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	RegisterOpenAPITools(server, ExtractOpenAPIOperations(doc), doc, &ToolGenOptions{
+		BaseURLOverrides: []BaseURLOverride{{PathPrefix: "/admin", URL: "http://10.0.0.5:8080/internal"}},
+		RequestHandler: func(req *http.Request) (*http.Response, error) {
+			requested = req.URL.String()
+			return &http.Response{StatusCode: 200, Header: http.Header{"Content-Type": {"application/json"}}, Body: io.NopCloser(strings.NewReader(`{}`)), Request: req}, nil
+		},
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(context.Background(), serverTransport, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil).Connect(context.Background(), clientTransport, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cs.Close()
+
+	// The override's host is configured by the operator, so the host policy allows it
+	for name, want := range map[string]string{"listPets": "https://api.example.com/v1/pets", "listUsers": "http://10.0.0.5:8080/internal/admin/users"} {
+		res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: map[string]any{}})
+		if err != nil || res.IsError {
+			t.Fatalf("%s: unexpected error: %v %v", name, err, res)
+		}
+		if requested != want {
+			t.Errorf("%s: requested %s, want %s", name, requested, want)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+// bench.go
+package openapi2mcp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// LoadTestOptions configures RunLoadTest.
+type LoadTestOptions struct {
+	Calls          int             // Total number of tool calls (default 1000)
+	Concurrency    int             // Number of concurrent client sessions (default GOMAXPROCS)
+	ResponseBody   []byte          // Body returned by the stub upstream (default: a small JSON object)
+	ResponseDelay  time.Duration   // Simulated upstream latency per request
+	ToolGenOptions *ToolGenOptions // Options used to register the tools; RequestHandler is replaced by the stub
+}
+
+// LoadTestReport summarizes a RunLoadTest run.
+type LoadTestReport struct {
+	Tools         int           `json:"tools"`
+	Calls         int           `json:"calls"`
+	Errors        int           `json:"errors"`
+	Duration      time.Duration `json:"duration"`
+	Throughput    float64       `json:"calls_per_second"`
+	Mean          time.Duration `json:"mean"`
+	P50           time.Duration `json:"p50"`
+	P90           time.Duration `json:"p90"`
+	P99           time.Duration `json:"p99"`
+	Max           time.Duration `json:"max"`
+	AllocsPerCall float64       `json:"allocs_per_call"`
+	BytesPerCall  float64       `json:"bytes_per_call"`
+}
+
+// String renders the report as a human-readable summary.
+func (r *LoadTestReport) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Tools:       %d\n", r.Tools))
+	sb.WriteString(fmt.Sprintf("Calls:       %d (%d errors)\n", r.Calls, r.Errors))
+	sb.WriteString(fmt.Sprintf("Duration:    %s\n", r.Duration.Round(time.Millisecond)))
+	sb.WriteString(fmt.Sprintf("Throughput:  %.1f calls/s\n", r.Throughput))
+	sb.WriteString(fmt.Sprintf("Latency:     mean %s · p50 %s · p90 %s · p99 %s · max %s\n",
+		r.Mean.Round(time.Microsecond), r.P50.Round(time.Microsecond), r.P90.Round(time.Microsecond),
+		r.P99.Round(time.Microsecond), r.Max.Round(time.Microsecond)))
+	sb.WriteString(fmt.Sprintf("Allocations: %.0f allocs/call · %s/call\n", r.AllocsPerCall, formatBytes(int(r.BytesPerCall))))
+	return sb.String()
+}
+
+// RunLoadTest registers the operations as tools on an in-process MCP server whose upstream is a stub,
+// then replays synthetic tool calls (with example arguments generated from each tool's input schema)
+// through in-memory client sessions, measuring throughput, latency distribution, and allocations.
+// Allocation counts include the in-process client and the stub upstream.
+//
+// Example usage for RunLoadTest:
+//
+//	report, err := openapi2mcp.RunLoadTest(ctx, doc, ops, &openapi2mcp.LoadTestOptions{Calls: 5000})
+//	if err != nil { log.Fatal(err) }
+//	fmt.Print(report)
+func RunLoadTest(ctx context.Context, doc *openapi3.T, ops []OpenAPIOperation, opts *LoadTestOptions) (*LoadTestReport, error) {
+	if opts == nil {
+		opts = &LoadTestOptions{}
+	}
+	calls := opts.Calls
+	if calls <= 0 {
+		calls = 1000
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+	responseBody := opts.ResponseBody
+	if responseBody == nil {
+		responseBody = []byte(`{"id": 1, "name": "example", "status": "ok"}`)
+	}
+
+	toolOpts := ToolGenOptions{}
+	if opts.ToolGenOptions != nil {
+		toolOpts = *opts.ToolGenOptions
+	}
+	toolOpts.DryRun = false
+	toolOpts.Mock = false
+	toolOpts.SpecDrift = nil
+	toolOpts.RequestHandler = func(req *http.Request) (*http.Response, error) {
+		if req.Body != nil {
+			io.Copy(io.Discard, req.Body)
+		}
+		if opts.ResponseDelay > 0 {
+			time.Sleep(opts.ResponseDelay)
+		}
+		return &http.Response{
+			StatusCode:    http.StatusOK,
+			Status:        "200 OK",
+			Header:        http.Header{"Content-Type": []string{"application/json"}},
+			ContentLength: int64(len(responseBody)),
+			Body:          io.NopCloser(bytes.NewReader(responseBody)),
+			Request:       req,
+		}, nil
+	}
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "openapi-mcp-bench", Version: "bench"}, nil)
+	RegisterOpenAPITools(server, ops, doc, &toolOpts)
+	client := mcp.NewClient(&mcp.Implementation{Name: "openapi-mcp-bench-client", Version: "bench"}, nil)
+
+	sessions := make([]*mcp.ClientSession, concurrency)
+	for i := range sessions {
+		serverTransport, clientTransport := mcp.NewInMemoryTransports()
+		if _, err := server.Connect(ctx, serverTransport, nil); err != nil {
+			return nil, err
+		}
+		cs, err := client.Connect(ctx, clientTransport, nil)
+		if err != nil {
+			return nil, err
+		}
+		defer cs.Close()
+		sessions[i] = cs
+	}
+
+	// Build the synthetic calls from the tool list as clients see it
+	listed, err := sessions[0].ListTools(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	var params []*mcp.CallToolParams
+	for _, tool := range listed.Tools {
+		if isMetaTool(tool.Name, &toolOpts) {
+			continue
+		}
+		args := generateExampleArguments(tool.InputSchema, toolOpts.ExampleGenerators)
+		args["__confirmed"] = true
+		params = append(params, &mcp.CallToolParams{Name: tool.Name, Arguments: args})
+	}
+	if len(params) == 0 {
+		return nil, fmt.Errorf("no operations to call")
+	}
+
+	latencies := make([]time.Duration, calls)
+	var next, errorCount atomic.Int64
+	var wg sync.WaitGroup
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+
+	for _, cs := range sessions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1)) - 1
+				if i >= calls || ctx.Err() != nil {
+					return
+				}
+				callStart := time.Now()
+				res, err := cs.CallTool(ctx, params[i%len(params)])
+				latencies[i] = time.Since(callStart)
+				if err != nil || res.IsError {
+					errorCount.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	report := &LoadTestReport{
+		Tools:         len(params),
+		Calls:         calls,
+		Errors:        int(errorCount.Load()),
+		Duration:      elapsed,
+		Throughput:    float64(calls) / elapsed.Seconds(),
+		AllocsPerCall: float64(after.Mallocs-before.Mallocs) / float64(calls),
+		BytesPerCall:  float64(after.TotalAlloc-before.TotalAlloc) / float64(calls),
+	}
+
+	var total time.Duration
+	for _, l := range latencies {
+		total += l
+	}
+	slices.Sort(latencies)
+	report.Mean = total / time.Duration(calls)
+	report.P50 = percentile(latencies, 0.50)
+	report.P90 = percentile(latencies, 0.90)
+	report.P99 = percentile(latencies, 0.99)
+	report.Max = latencies[len(latencies)-1]
+	return report, nil
+}
+
+// percentile returns the p-th percentile of the sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	idx := int(float64(len(sorted)-1) * p)
+	return sorted[idx]
+}
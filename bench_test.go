@@ -0,0 +1,44 @@
+package openapi2mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRunLoadTest(t *testing.T) {
+	doc := largeOpenAPIDoc(3)
+	ops := ExtractOpenAPIOperations(doc)
+	report, err := RunLoadTest(context.Background(), doc, ops, &LoadTestOptions{Calls: 30, Concurrency: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Tools != 3 || report.Calls != 30 || report.Errors != 0 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	if report.P50 <= 0 || report.Max < report.P99 || report.AllocsPerCall <= 0 {
+		t.Errorf("expected latency and allocation stats, got %+v", report)
+	}
+	if !strings.Contains(report.String(), "Calls:       30 (0 errors)") {
+		t.Errorf("unexpected summary:\n%s", report)
+	}
+}
+
+func TestGenerateExampleArguments(t *testing.T) {
+	doc := largeOpenAPIDoc(1)
+	op := ExtractOpenAPIOperations(doc)[0]
+	op.RequestBody.Value.Required = true
+	schema := BuildInputSchema(op.Parameters, op.RequestBody)
+
+	args := generateExampleArguments(&schema, nil)
+	if args["id"] != "example_string" {
+		t.Errorf("expected required path parameter, got %v", args)
+	}
+	body, ok := args["requestBody"].(map[string]any)
+	if !ok || body["name"] != "example_string" {
+		t.Errorf("expected request body with required name, got %v", args["requestBody"])
+	}
+	if _, ok := args["expand__"]; ok {
+		t.Errorf("expected optional parameters to be omitted, got %v", args)
+	}
+}
@@ -0,0 +1,265 @@
+// callback.go
+package openapi2mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// maxStoredCallbacks caps the number of received callbacks kept by a CallbackReceiver.
+	maxStoredCallbacks = 1000
+	// maxCallbackBodyBytes caps the stored body of a received callback.
+	maxCallbackBodyBytes = 64 << 10
+	// maxAwaitCallbackTimeout caps how long await_callback blocks.
+	maxAwaitCallbackTimeout = 5 * time.Minute
+)
+
+// ReceivedCallback is a callback (webhook) request received by a CallbackReceiver.
+type ReceivedCallback struct {
+	ID          int               `json:"id"`
+	OperationID string            `json:"operation_id,omitempty"`
+	Name        string            `json:"name,omitempty"`
+	Method      string            `json:"method"`
+	Path        string            `json:"path"`
+	Query       string            `json:"query,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Body        string            `json:"body,omitempty"`
+	ReceivedAt  time.Time         `json:"received_at"`
+
+	delivered bool // returned by await_callback already
+}
+
+// CallbackReceiver receives the callbacks that APIs send for operations with OpenAPI callbacks (or webhooks),
+// so that agents can wait for and inspect them with the await_callback and list_received_callbacks tools.
+// Serve it on an address the API can reach; requests to /{operationId}/{callbackName} are attributed to that
+// operation's callback, and requests to any other path are recorded as well.
+//
+//	receiver := openapi2mcp.NewCallbackReceiver("https://hooks.example.com")
+//	go http.ListenAndServe(":9090", receiver)
+//	opts.CallbackReceiver = receiver
+type CallbackReceiver struct {
+	baseURL string
+
+	mu       sync.Mutex
+	nextID   int
+	received []*ReceivedCallback
+	changed  chan struct{} // closed and replaced whenever a callback arrives
+}
+
+// NewCallbackReceiver creates a receiver whose public base URL (as reachable by the API) is baseURL.
+func NewCallbackReceiver(baseURL string) *CallbackReceiver {
+	return &CallbackReceiver{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		changed: make(chan struct{}),
+	}
+}
+
+// CallbackURL returns the URL to which the API should send the named callback of an operation.
+func (r *CallbackReceiver) CallbackURL(operationID, name string) string {
+	return r.baseURL + "/" + escapePathParameter(operationID) + "/" + escapePathParameter(name)
+}
+
+// ServeHTTP records the request and answers 204 No Content.
+func (r *CallbackReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(io.LimitReader(req.Body, maxCallbackBodyBytes))
+	cb := &ReceivedCallback{
+		Method:     req.Method,
+		Path:       req.URL.Path,
+		Query:      req.URL.RawQuery,
+		Headers:    redactHeaders(req.Header, nil),
+		Body:       string(body),
+		ReceivedAt: time.Now(),
+	}
+	if parts := strings.SplitN(strings.TrimPrefix(req.URL.Path, "/"), "/", 3); len(parts) >= 2 {
+		cb.OperationID, cb.Name = parts[0], parts[1]
+	}
+
+	r.mu.Lock()
+	r.nextID++
+	cb.ID = r.nextID
+	r.received = append(r.received, cb)
+	if len(r.received) > maxStoredCallbacks {
+		r.received = r.received[len(r.received)-maxStoredCallbacks:]
+	}
+	close(r.changed)
+	r.changed = make(chan struct{})
+	r.mu.Unlock()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Received returns the stored callbacks matching operationID and name (empty matches any), oldest first.
+func (r *CallbackReceiver) Received(operationID, name string) []ReceivedCallback {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []ReceivedCallback
+	for _, cb := range r.received {
+		if cb.matches(operationID, name) {
+			out = append(out, *cb)
+		}
+	}
+	return out
+}
+
+// Await returns the oldest matching callback not returned by Await before, waiting until one arrives
+// or ctx is done. Callbacks that arrived before Await was called are returned too, so there's no race
+// between triggering an operation and waiting for its callback.
+func (r *CallbackReceiver) Await(ctx context.Context, operationID, name string) (ReceivedCallback, error) {
+	for {
+		r.mu.Lock()
+		for _, cb := range r.received {
+			if !cb.delivered && cb.matches(operationID, name) {
+				cb.delivered = true
+				r.mu.Unlock()
+				return *cb, nil
+			}
+		}
+		changed := r.changed
+		r.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ReceivedCallback{}, ctx.Err()
+		}
+	}
+}
+
+func (cb *ReceivedCallback) matches(operationID, name string) bool {
+	return (operationID == "" || cb.OperationID == operationID) && (name == "" || cb.Name == name)
+}
+
+// describeCallbacks explains where the operation's callbacks are sent and how to direct them to the receiver.
+func describeCallbacks(op OpenAPIOperation, receiver *CallbackReceiver) string {
+	if len(op.Callbacks) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\n\nCALLBACKS: This operation notifies asynchronously via callbacks.")
+	for _, name := range slices.Sorted(maps.Keys(op.Callbacks)) {
+		ref := op.Callbacks[name]
+		if ref == nil || ref.Value == nil {
+			continue
+		}
+		for _, expr := range slices.Sorted(maps.Keys(ref.Value.Map())) {
+			sb.WriteString(fmt.Sprintf("\n• %s: sent to %s", name, expr))
+			if receiver != nil {
+				if arg := callbackURLArgument(expr); arg != "" {
+					sb.WriteString(fmt.Sprintf(" — set %s to %q", arg, receiver.CallbackURL(op.OperationID, name)))
+				}
+			}
+		}
+	}
+	if receiver != nil {
+		sb.WriteString(fmt.Sprintf("\nAfter calling this tool, use await_callback with {\"operation_id\": %q} to wait for the callback.", op.OperationID))
+	}
+	return sb.String()
+}
+
+// callbackURLArgument maps a callback URL runtime expression like "{$request.body#/callbackUrl}" to the
+// tool argument holding the URL, e.g. "requestBody.callbackUrl". Returns "" if the URL isn't taken from the request.
+func callbackURLArgument(expr string) string {
+	expr = strings.TrimSuffix(strings.TrimPrefix(expr, "{"), "}")
+	switch {
+	case strings.HasPrefix(expr, "$request.body#/"):
+		return "requestBody." + strings.ReplaceAll(strings.TrimPrefix(expr, "$request.body#/"), "/", ".")
+	case strings.HasPrefix(expr, "$request.query."):
+		return "query parameter " + escapeParameterName(strings.TrimPrefix(expr, "$request.query."))
+	case strings.HasPrefix(expr, "$request.header."):
+		return "header parameter " + escapeParameterName(strings.TrimPrefix(expr, "$request.header."))
+	case strings.HasPrefix(expr, "$request.path."):
+		return "path parameter " + escapeParameterName(strings.TrimPrefix(expr, "$request.path."))
+	}
+	return ""
+}
+
+// registerCallbackTools adds the await_callback and list_received_callbacks tools and the
+// callbacks://received resource for receiver.
+func registerCallbackTools(server *mcp.Server, receiver *CallbackReceiver, opts *ToolGenOptions) {
+	var annotations *mcp.ToolAnnotations
+	if opts.Version != "" {
+		annotations = &mcp.ToolAnnotations{Title: "OpenAPI " + opts.Version}
+	}
+	filterProps := map[string]*jsonschema.Schema{
+		"operation_id": {Type: "string", Description: "Only match callbacks of this operation (tool name's operationId)."},
+		"name":         {Type: "string", Description: "Only match callbacks with this callback name."},
+	}
+
+	awaitProps := maps.Clone(filterProps)
+	awaitProps["timeout_seconds"] = &jsonschema.Schema{Type: "integer", Description: "How long to wait (default 30, max 300)."}
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "await_callback",
+		Description: "Wait for the next callback (webhook) the API sends to the local receiver, and return it: method, path, headers, and body. Callbacks received since the last await_callback are returned immediately, oldest first.",
+		InputSchema: &jsonschema.Schema{Type: "object", Properties: awaitProps},
+		Annotations: annotations,
+	}, func(ctx context.Context, _ *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+		timeout := 30 * time.Second
+		if v, ok := args["timeout_seconds"].(float64); ok && v > 0 {
+			timeout = min(time.Duration(v)*time.Second, maxAwaitCallbackTimeout)
+		}
+		operationID, _ := args["operation_id"].(string)
+		name, _ := args["name"].(string)
+
+		awaitCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		cb, err := receiver.Await(awaitCtx, operationID, name)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("No callback received within %s. The API may not have sent it yet: call await_callback again, or check list_received_callbacks.", timeout)}},
+				IsError: true,
+			}, nil, nil
+		}
+		text, _ := json.MarshalIndent(cb, "", "  ")
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: string(text)}}}, nil, nil
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "list_received_callbacks",
+		Description: "List the callbacks (webhooks) received by the local receiver so far, oldest first.",
+		InputSchema: &jsonschema.Schema{Type: "object", Properties: filterProps},
+		Annotations: annotations,
+	}, func(_ context.Context, _ *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+		operationID, _ := args["operation_id"].(string)
+		name, _ := args["name"].(string)
+		text, _ := json.MarshalIndent(receivedOrEmpty(receiver.Received(operationID, name)), "", "  ")
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: string(text)}}}, nil, nil
+	})
+
+	resource := &mcp.Resource{
+		URI:         "callbacks://received",
+		Name:        "Received Callbacks",
+		Description: "Callbacks (webhooks) received by the local receiver so far, oldest first",
+		MIMEType:    "application/json",
+	}
+	server.AddResource(resource, func(context.Context, *mcp.ServerRequest[*mcp.ReadResourceParams]) (*mcp.ReadResourceResult, error) {
+		text, _ := json.MarshalIndent(receivedOrEmpty(receiver.Received("", "")), "", "  ")
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{URI: resource.URI, MIMEType: "application/json", Text: string(text)}},
+		}, nil
+	})
+}
+
+// receivedOrEmpty returns callbacks, or an empty (non-nil) slice so that it marshals as [].
+func receivedOrEmpty(callbacks []ReceivedCallback) []ReceivedCallback {
+	if callbacks == nil {
+		return []ReceivedCallback{}
+	}
+	return callbacks
+}
+
+// hasCallbacks reports whether any of the operations defines callbacks.
+func hasCallbacks(ops []OpenAPIOperation) bool {
+	return slices.ContainsFunc(ops, func(op OpenAPIOperation) bool { return len(op.Callbacks) > 0 })
+}
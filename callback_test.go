@@ -0,0 +1,105 @@
+package openapi2mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func callbackOperation() OpenAPIOperation {
+	callback := openapi3.NewCallback(openapi3.WithCallback("{$request.body#/callbackUrl}", &openapi3.PathItem{
+		Post: &openapi3.Operation{Responses: openapi3.NewResponses()},
+	}))
+	return OpenAPIOperation{
+		OperationID: "createSubscription",
+		Path:        "/subscriptions",
+		Method:      "post",
+		Callbacks:   openapi3.Callbacks{"onEvent": &openapi3.CallbackRef{Value: callback}},
+	}
+}
+
+func TestCallbackReceiver_Await(t *testing.T) {
+	receiver := NewCallbackReceiver("https://hooks.example.com/")
+	if got := receiver.CallbackURL("createSubscription", "onEvent"); got != "https://hooks.example.com/createSubscription/onEvent" {
+		t.Errorf("unexpected callback URL %q", got)
+	}
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+
+	// A callback arriving before Await is still returned
+	resp, err := http.Post(srv.URL+"/createSubscription/onEvent", "application/json", strings.NewReader(`{"event":"first"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", resp.StatusCode)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	cb, err := receiver.Await(ctx, "createSubscription", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cb.Name != "onEvent" || cb.Body != `{"event":"first"}` {
+		t.Errorf("unexpected callback %+v", cb)
+	}
+
+	// The next Await blocks until another callback arrives
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		resp, err := http.Post(srv.URL+"/createSubscription/onEvent", "application/json", strings.NewReader(`{"event":"second"}`))
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+	if cb, err = receiver.Await(ctx, "createSubscription", "onEvent"); err != nil || cb.Body != `{"event":"second"}` {
+		t.Errorf("expected second callback, got %+v, %v", cb, err)
+	}
+
+	short, cancelShort := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelShort()
+	if _, err := receiver.Await(short, "", ""); err == nil {
+		t.Error("expected timeout with no pending callbacks")
+	}
+	if got := receiver.Received("", ""); len(got) != 2 {
+		t.Errorf("expected 2 received callbacks, got %d", len(got))
+	}
+	if got := receiver.Received("otherOperation", ""); len(got) != 0 {
+		t.Errorf("expected no callbacks for other operation, got %d", len(got))
+	}
+}
+
+func TestRegisterOpenAPITools_Callbacks(t *testing.T) {
+	ops := []OpenAPIOperation{callbackOperation()}
+
+	snapshot, err := ToolsSnapshot(context.Background(), minimalOpenAPIDoc(), ops, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := string(snapshot); !strings.Contains(text, "CALLBACKS:") || strings.Contains(text, "await_callback") {
+		t.Errorf("expected callbacks described without await tools, got:\n%s", text)
+	}
+
+	receiver := NewCallbackReceiver("https://hooks.example.com")
+	snapshot, err = ToolsSnapshot(context.Background(), minimalOpenAPIDoc(), ops, &ToolGenOptions{CallbackReceiver: receiver})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := string(snapshot)
+	for _, want := range []string{
+		`"name": "await_callback"`,
+		`"name": "list_received_callbacks"`,
+		`set requestBody.callbackUrl to \"https://hooks.example.com/createSubscription/onEvent\"`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected snapshot to contain %s, got:\n%s", want, text)
+		}
+	}
+}
@@ -0,0 +1,371 @@
+// callgroup.go
+package openapi2mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxGroupCalls caps the calls recorded per call group; later calls of the group are counted only.
+const maxGroupCalls = 100
+
+// callGroup is the state-changing calls an MCP session made since begin_group, with how to undo them.
+type callGroup struct {
+	ID      string        `json:"id"`
+	Name    string        `json:"name,omitempty"`
+	Begun   time.Time     `json:"begun"`
+	Calls   []groupedCall `json:"calls"`
+	Dropped int           `json:"dropped_calls,omitempty"` // calls beyond maxGroupCalls, not recorded
+	session string
+}
+
+// groupedCall is a successful state-changing call recorded in a call group.
+type groupedCall struct {
+	Tool         string            `json:"tool"`
+	Method       string            `json:"method"`
+	URL          string            `json:"url"`
+	Status       int               `json:"status"`
+	Compensation *compensatingCall `json:"compensation,omitempty"`
+	Note         string            `json:"note,omitempty"` // why there is no compensating call
+}
+
+// compensatingCall is a call undoing a grouped call, e.g. the DELETE of a resource a POST created.
+type compensatingCall struct {
+	Tool      string         `json:"tool"`
+	Arguments map[string]any `json:"arguments"`
+	Source    string         `json:"source"` // the link or convention the call was derived from
+}
+
+// callGroups holds the open call group of each MCP session. It is safe for concurrent use.
+type callGroups struct {
+	mu     sync.Mutex
+	groups map[string]*callGroup // by session
+}
+
+// newCallGroups creates a store without open groups.
+func newCallGroups() *callGroups {
+	return &callGroups{groups: make(map[string]*callGroup)}
+}
+
+// begin opens a call group for the session, evicting the oldest open group of another session beyond maxSessions.
+func (g *callGroups) begin(session, name string) (*callGroup, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if open, ok := g.groups[session]; ok {
+		return nil, fmt.Errorf("group %s is still open; commit or abort it first", open.ID)
+	}
+	if len(g.groups) >= maxSessions {
+		oldest := slices.MinFunc(slices.Collect(maps.Values(g.groups)), func(a, b *callGroup) int { return a.Begun.Compare(b.Begun) })
+		delete(g.groups, oldest.session)
+	}
+	group := &callGroup{ID: newRandomID(), Name: name, Begun: time.Now(), Calls: []groupedCall{}, session: session}
+	g.groups[session] = group
+	return group, nil
+}
+
+// record adds a call to the session's open group, if any.
+func (g *callGroups) record(session string, call groupedCall) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	group, ok := g.groups[session]
+	if !ok {
+		return
+	}
+	if len(group.Calls) >= maxGroupCalls {
+		group.Dropped++
+		return
+	}
+	group.Calls = append(group.Calls, call)
+}
+
+// open returns a copy of the session's open group.
+func (g *callGroups) open(session string) (callGroup, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	group, ok := g.groups[session]
+	if !ok {
+		return callGroup{}, fmt.Errorf("no call group is open; start one with begin_group")
+	}
+	copied := *group
+	copied.Calls = slices.Clone(group.Calls)
+	return copied, nil
+}
+
+// end closes the session's open group and returns it.
+func (g *callGroups) end(session string) (*callGroup, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	group, ok := g.groups[session]
+	if !ok {
+		return nil, fmt.Errorf("no call group is open; start one with begin_group")
+	}
+	delete(g.groups, session)
+	return group, nil
+}
+
+// compensate derives the call undoing a successful state-changing call from the links of its response to a DELETE
+// operation or, for a POST, from the DELETE operation on the path below it ("POST /pets" → "DELETE /pets/{id}"),
+// taking the new resource's ID from the response body or its Location header. Returns a note instead if there is none.
+func compensate(op OpenAPIOperation, doc *openapi3.T, c *linkContext, ops *OperationRegistry) (*compensatingCall, string) {
+	method := strings.ToUpper(op.Method)
+	if method != "POST" {
+		return nil, fmt.Sprintf("%s calls can't be undone automatically; restore the previous state yourself if needed", method)
+	}
+
+	// A link of the response to a DELETE operation
+	links := responseLinksFor(op, c.statusCode)
+	for _, linkName := range slices.Sorted(maps.Keys(links)) {
+		ref := links[linkName]
+		if ref == nil || ref.Value == nil {
+			continue
+		}
+		tool, target := operationTool(ops, linkTargetOperationID(ref.Value, doc))
+		if tool == "" || !strings.EqualFold(target.Method, "delete") {
+			continue
+		}
+		args := make(map[string]any)
+		complete := true
+		for param, value := range ref.Value.Parameters {
+			v, _, ok := c.evaluate(value)
+			if in, name, found := strings.Cut(param, "."); found && slices.Contains(parameterLocations, in) {
+				param = name
+			}
+			args[escapeParameterName(param)] = v
+			complete = complete && ok
+		}
+		if complete {
+			return &compensatingCall{Tool: tool, Arguments: args, Source: "link " + linkName}, ""
+		}
+	}
+
+	// By convention, the DELETE operation on the path of the created resource
+	for _, tool := range ops.ToolNames() {
+		target, _ := ops.Operation(tool)
+		parent, last := path.Split(target.Path)
+		if !strings.EqualFold(target.Method, "delete") || strings.TrimSuffix(parent, "/") != strings.TrimSuffix(op.Path, "/") ||
+			!strings.HasPrefix(last, "{") || !strings.HasSuffix(last, "}") {
+			continue
+		}
+		param := strings.Trim(last, "{}")
+		id, ok := createdID(c, param)
+		if !ok {
+			return nil, fmt.Sprintf("%s may undo this call, but the created resource's %s is unknown", tool, param)
+		}
+		args := map[string]any{escapeParameterName(param): id}
+		// Parameters of the parent path carry over, e.g. userId of /users/{userId}/pets
+		for _, ref := range target.Parameters {
+			if ref != nil && ref.Value != nil && ref.Value.In == "path" && ref.Value.Name != param {
+				name := escapeParameterName(ref.Value.Name)
+				if v, ok := c.args[name]; ok {
+					args[name] = v
+				}
+			}
+		}
+		return &compensatingCall{Tool: tool, Arguments: args, Source: fmt.Sprintf("convention: DELETE %s undoes POST %s", target.Path, op.Path)}, ""
+	}
+	return nil, "no DELETE operation is known to undo this call"
+}
+
+// createdID returns the ID of a resource created by a POST: the param or "id" field of the response body, or the
+// last segment of its Location header.
+func createdID(c *linkContext, param string) (any, bool) {
+	if body, ok := c.body.(map[string]any); ok {
+		for _, field := range []string{param, "id"} {
+			if v, ok := body[field]; ok && v != nil {
+				return v, true
+			}
+		}
+	}
+	if location := c.header.Get("Location"); location != "" {
+		if u, err := url.Parse(location); err == nil {
+			if id := path.Base(strings.TrimSuffix(u.Path, "/")); id != "" && id != "." && id != "/" {
+				return id, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// operationTool returns the name and operation of the tool of the operation with operationID, or "" if there is none.
+func operationTool(ops *OperationRegistry, operationID string) (string, OpenAPIOperation) {
+	if operationID == "" {
+		return "", OpenAPIOperation{}
+	}
+	for _, tool := range ops.ToolNames() {
+		if op, _ := ops.Operation(tool); op.OperationID == operationID {
+			return tool, op
+		}
+	}
+	return "", OpenAPIOperation{}
+}
+
+// rollbackOutcome is the result of a compensating call executed by abort_group.
+type rollbackOutcome struct {
+	Tool      string         `json:"tool"`
+	Arguments map[string]any `json:"arguments"`
+	Succeeded bool           `json:"succeeded"`
+	Result    string         `json:"result,omitempty"`
+}
+
+// rollback executes the compensating calls of the group, most recent first, on behalf of req, the abort_group request.
+// Calls needing confirmation are only made if the rollback was confirmed.
+func rollback(ctx context.Context, req *mcp.CallToolRequest, group *callGroup, ops *OperationRegistry, confirmed bool) []rollbackOutcome {
+	outcomes := []rollbackOutcome{}
+	for _, call := range slices.Backward(group.Calls) {
+		if call.Compensation == nil {
+			continue
+		}
+		args := maps.Clone(call.Compensation.Arguments)
+		if confirmed {
+			args["__confirmed"] = true
+		}
+		outcome := rollbackOutcome{Tool: call.Compensation.Tool, Arguments: call.Compensation.Arguments}
+		res, err := ops.callAs(ctx, req, call.Compensation.Tool, args)
+		switch {
+		case err != nil:
+			outcome.Result = err.Error()
+		case res != nil:
+			outcome.Succeeded = !res.IsError
+			if len(res.Content) > 0 {
+				if text, ok := res.Content[0].(*mcp.TextContent); ok {
+					outcome.Result = text.Text
+				}
+			}
+		}
+		outcomes = append(outcomes, outcome)
+	}
+	return outcomes
+}
+
+// compensationSteps describes how to undo the calls of the group, most recent first.
+func compensationSteps(group *callGroup) []string {
+	var steps []string
+	for _, call := range slices.Backward(group.Calls) {
+		if call.Compensation == nil {
+			steps = append(steps, fmt.Sprintf("• %s %s: %s", call.Method, call.URL, call.Note))
+			continue
+		}
+		argsJSON, _ := json.Marshal(call.Compensation.Arguments)
+		steps = append(steps, fmt.Sprintf("• %s %s: call %s with %s (%s)", call.Method, call.URL, call.Compensation.Tool, argsJSON, call.Compensation.Source))
+	}
+	return steps
+}
+
+// registerCallGroupTools adds the begin_group, commit_group, and abort_group tools. Compensating calls are only
+// executed on abort_group if execute is true, after confirming them as a whole if any of them needs confirmation.
+func registerCallGroupTools(server *mcp.Server, groups *callGroups, ops *OperationRegistry, execute bool, opts *ToolGenOptions) {
+	var annotations *mcp.ToolAnnotations
+	if opts != nil && opts.Version != "" {
+		annotations = &mcp.ToolAnnotations{Title: "OpenAPI " + opts.Version}
+	}
+	sessionOf := func(req *mcp.CallToolRequest) string {
+		if req == nil {
+			return sessionCorrelationID(nil)
+		}
+		return sessionCorrelationID(req.Session)
+	}
+	result := func(v any, isError bool) *mcp.CallToolResult {
+		text, ok := v.(string)
+		if !ok {
+			data, _ := json.MarshalIndent(v, "", "  ")
+			text = string(data)
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}, IsError: isError}
+	}
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "begin_group",
+		Description: "Start a group of related API calls, e.g. the steps of a multi-step change. State-changing calls made " +
+			"until commit_group or abort_group are recorded with how to undo them, so that a partial change can be " +
+			"cleaned up if a later step fails.",
+		InputSchema: &jsonschema.Schema{Type: "object", Properties: map[string]*jsonschema.Schema{
+			"name": {Type: "string", Description: "What the group of calls does, e.g. \"onboard customer\"."},
+		}},
+		Annotations: annotations,
+	}, func(_ context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+		name, _ := args["name"].(string)
+		group, err := groups.begin(sessionOf(req), name)
+		if err != nil {
+			return result("Cannot begin a group: "+err.Error(), true), nil, nil
+		}
+		return result(group, false), nil, nil
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "commit_group",
+		Description: "End the open group of calls because all steps succeeded, and list the calls it recorded.",
+		InputSchema: &jsonschema.Schema{Type: "object"},
+		Annotations: annotations,
+	}, func(_ context.Context, req *mcp.CallToolRequest, _ map[string]any) (*mcp.CallToolResult, any, error) {
+		group, err := groups.end(sessionOf(req))
+		if err != nil {
+			return result("Cannot commit the group: "+err.Error(), true), nil, nil
+		}
+		return result(group, false), nil, nil
+	})
+
+	abortDesc := "End the open group of calls because a step failed, and list the compensating calls undoing the calls it recorded, most recent first. Make these calls to clean up the partial change."
+	if execute {
+		abortDesc = "End the open group of calls because a step failed, and undo the calls it recorded with compensating calls, most recent first, if rollback is true; otherwise list them. Compensating calls needing confirmation are listed for approval first."
+	}
+	abortProps := map[string]*jsonschema.Schema{}
+	if execute {
+		abortProps["rollback"] = &jsonschema.Schema{Type: "boolean", Description: "Execute the compensating calls instead of listing them."}
+	}
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "abort_group",
+		Description: abortDesc,
+		InputSchema: &jsonschema.Schema{Type: "object", Properties: abortProps},
+		Annotations: annotations,
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+		_, confirmed := args["__confirmed"]
+		doRollback := execute && args["rollback"] == true
+
+		// Compensating calls needing confirmation are confirmed as a whole, listing them, while the group stays open
+		if doRollback && !confirmed {
+			group, err := groups.open(sessionOf(req))
+			if err != nil {
+				return result("Cannot abort the group: "+err.Error(), true), nil, nil
+			}
+			if slices.ContainsFunc(group.Calls, func(call groupedCall) bool {
+				if call.Compensation == nil {
+					return false
+				}
+				op, _ := ops.Operation(call.Compensation.Tool)
+				return confirmationRequired(opts, op, ops.Doc())
+			}) {
+				text := fmt.Sprintf("⚠️  CONFIRMATION REQUIRED\n\nAction: abort_group\nRoll back group %s, most recent first:\n%s\n\nTo confirm, retry the call with {\"__confirmed\": true} added to your arguments.", group.ID, strings.Join(compensationSteps(&group), "\n"))
+				return result(text, false), nil, nil
+			}
+		}
+
+		group, err := groups.end(sessionOf(req))
+		if err != nil {
+			return result("Cannot abort the group: "+err.Error(), true), nil, nil
+		}
+		if doRollback {
+			return result(map[string]any{"group": group, "rollback": rollback(ctx, req, group, ops, confirmed)}, false), nil, nil
+		}
+		steps := compensationSteps(group)
+		if len(steps) == 0 {
+			return result(fmt.Sprintf("Group %s aborted. It recorded no state-changing calls, so there is nothing to undo.", group.ID), false), nil, nil
+		}
+		text := fmt.Sprintf("Group %s aborted. To undo its calls, most recent first:\n%s", group.ID, strings.Join(steps, "\n"))
+		if group.Dropped > 0 {
+			text += fmt.Sprintf("\n%d earlier calls of the group were not recorded; check their effects yourself.", group.Dropped)
+		}
+		return result(text, false), nil, nil
+	})
+}
@@ -0,0 +1,146 @@
+package openapi2mcp
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const callGroupSpec = `openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /pets:
+    post:
+      operationId: createPet
+      responses: {"201": {description: created}}
+  /pets/{petId}:
+    parameters: [{name: petId, in: path, required: true, schema: {type: integer}}]
+    put:
+      operationId: updatePet
+      responses: {"200": {description: ok}}
+    delete:
+      operationId: deletePet
+      responses: {"204": {description: deleted}}
+  /owners:
+    post:
+      operationId: createOwner
+      responses:
+        "201":
+          description: created
+          links:
+            Remove: {operationId: removeOwner, parameters: {name: $response.body#/name}}
+  /owners/remove:
+    delete:
+      operationId: removeOwner
+      parameters: [{name: name, in: query, schema: {type: string}}]
+      responses: {"204": {description: deleted}}
+`
+
+func TestRegisterOpenAPITools_CallGroups(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(callGroupSpec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var mu sync.Mutex
+	var sent []string
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	RegisterOpenAPITools(server, ExtractOpenAPIOperations(doc), doc, &ToolGenOptions{
+		CallGroups:              true,
+		GroupRollback:           true,
+		ConfirmDangerousActions: true,
+		BaseURL:                 "http://example.com",
+		RequestHandler: func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			sent = append(sent, req.Method+" "+req.URL.RequestURI())
+			mu.Unlock()
+			switch req.URL.Path {
+			case "/pets":
+				return fakeResponse(201, "application/json", `{"id": 7}`)(req)
+			case "/owners":
+				return fakeResponse(201, "application/json", `{"name": "Ann"}`)(req)
+			}
+			return fakeResponse(200, "application/json", `{}`)(req)
+		},
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(context.Background(), serverTransport, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil).Connect(context.Background(), clientTransport, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cs.Close()
+	call := func(name string, args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: args})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return res
+	}
+
+	// Calls outside a group aren't recorded
+	call("createPet", map[string]any{"__confirmed": true})
+	if res := call("abort_group", nil); !res.IsError {
+		t.Errorf("expected an error without an open group, got: %s", resultText(t, res))
+	}
+
+	call("begin_group", map[string]any{"name": "onboarding"})
+	if res := call("begin_group", nil); !res.IsError {
+		t.Errorf("expected an error for a second open group, got: %s", resultText(t, res))
+	}
+	call("createPet", map[string]any{"__confirmed": true})
+	call("createOwner", map[string]any{"__confirmed": true})
+	call("updatePet", map[string]any{"petId": 7, "__confirmed": true})
+	text := resultText(t, call("abort_group", nil))
+	for _, want := range []string{`call deletePet with {"petId":7}`, `call removeOwner with {"name":"Ann"} (link Remove)`, "PUT calls can't be undone"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in the compensating calls, got: %s", want, text)
+		}
+	}
+	if strings.Index(text, "removeOwner") > strings.Index(text, "deletePet") {
+		t.Errorf("expected the most recent call to be undone first, got: %s", text)
+	}
+
+	// With rollback, the compensating calls are confirmed, then made
+	call("begin_group", nil)
+	call("createPet", map[string]any{"__confirmed": true})
+	mu.Lock()
+	sentBefore := len(sent)
+	mu.Unlock()
+	text = resultText(t, call("abort_group", map[string]any{"rollback": true}))
+	if !strings.Contains(text, "CONFIRMATION REQUIRED") || !strings.Contains(text, `call deletePet with {"petId":7}`) {
+		t.Errorf("expected the compensating calls to be confirmed first, got: %s", text)
+	}
+	mu.Lock()
+	if len(sent) != sentBefore {
+		t.Errorf("expected no call before the rollback is confirmed, got %q", sent[sentBefore:])
+	}
+	mu.Unlock()
+	text = resultText(t, call("abort_group", map[string]any{"rollback": true, "__confirmed": true}))
+	if !strings.Contains(text, `"succeeded": true`) {
+		t.Errorf("expected the rollback to succeed, got: %s", text)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if last := sent[len(sent)-1]; last != "DELETE /pets/7" {
+		t.Errorf("expected the created pet to be deleted, got %q", last)
+	}
+}
+
+func TestCreatedID(t *testing.T) {
+	c := &linkContext{header: http.Header{"Location": {"/pets/42"}}}
+	if id, ok := createdID(c, "petId"); !ok || id != "42" {
+		t.Errorf("expected the ID from the Location header, got %v", id)
+	}
+	c.body = map[string]any{"petId": 7.0, "id": 8.0}
+	if id, ok := createdID(c, "petId"); !ok || id != 7.0 {
+		t.Errorf("expected the ID from the body field named like the parameter, got %v", id)
+	}
+}
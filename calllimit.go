@@ -0,0 +1,145 @@
+// calllimit.go
+package openapi2mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ConcurrencyLimit limits how many calls of some operations run at once, e.g. one deployRelease at a time, so that
+// side-effectful calls don't race. Operations match by OperationID or Tag (both if both are set), and all operations
+// matching a limit share its slots. Calls beyond the limit wait up to Wait for a free slot, or are rejected with a
+// "busy" error at once if Wait is 0.
+type ConcurrencyLimit struct {
+	OperationID string        // the operation with this operationId
+	Tag         string        // operations having this tag
+	Max         int           // calls running at once
+	Wait        time.Duration // how long extra calls queue for a slot; 0 rejects them at once
+}
+
+// matches reports whether the limit applies to the operation.
+func (l ConcurrencyLimit) matches(op OpenAPIOperation) bool {
+	if l.Max <= 0 || l.OperationID == "" && l.Tag == "" {
+		return false
+	}
+	if l.OperationID != "" && l.OperationID != op.OperationID {
+		return false
+	}
+	return l.Tag == "" || slices.Contains(op.Tags, l.Tag)
+}
+
+// operationConcurrencyLimits returns the indexes of the limits applying to the operation.
+func operationConcurrencyLimits(opts *ToolGenOptions, op OpenAPIOperation) []int {
+	if opts == nil {
+		return nil
+	}
+	var indexes []int
+	for i, l := range opts.ConcurrencyLimits {
+		if l.matches(op) {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// describeConcurrencyLimit tells the model how many calls of the operation may run at once, so that it makes them
+// one after another instead of in parallel.
+func describeConcurrencyLimit(opts *ToolGenOptions, op OpenAPIOperation) string {
+	indexes := operationConcurrencyLimits(opts, op)
+	if len(indexes) == 0 {
+		return ""
+	}
+	limit := opts.ConcurrencyLimits[indexes[0]]
+	for _, i := range indexes[1:] {
+		if opts.ConcurrencyLimits[i].Max < limit.Max {
+			limit = opts.ConcurrencyLimits[i]
+		}
+	}
+	calls := "calls"
+	if limit.Max == 1 {
+		calls = "call"
+	}
+	desc := fmt.Sprintf("\n\nCONCURRENCY: At most %d %s of this operation", limit.Max, calls)
+	if limit.Tag != "" {
+		desc += fmt.Sprintf(" and the other operations tagged %q", limit.Tag)
+	}
+	return desc + " may run at once; wait for running calls to complete instead of starting more in parallel."
+}
+
+// callSlots holds the free slots of each ConcurrencyLimit, by its index in ToolGenOptions.ConcurrencyLimits.
+// It is safe for concurrent use.
+type callSlots struct {
+	mu    sync.Mutex
+	slots map[int]chan struct{}
+}
+
+// newCallSlots creates the slots of no limits yet; those of a limit are created when first needed.
+func newCallSlots() *callSlots {
+	return &callSlots{slots: make(map[int]chan struct{})}
+}
+
+// semaphore returns the slots of the limit with the index.
+func (s *callSlots) semaphore(index int, limit ConcurrencyLimit) chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sem, ok := s.slots[index]
+	if !ok {
+		sem = make(chan struct{}, limit.Max)
+		s.slots[index] = sem
+	}
+	return sem
+}
+
+// acquire takes a slot of each of the limits with the indexes, in order, waiting for them as long as the limits
+// allow. It returns a function releasing the slots taken, or else the limit that is busy, or ctx's error.
+func (s *callSlots) acquire(ctx context.Context, limits []ConcurrencyLimit, indexes []int) (release func(), busy *ConcurrencyLimit, err error) {
+	var taken []chan struct{}
+	release = func() {
+		for _, sem := range taken {
+			<-sem
+		}
+	}
+	for _, i := range indexes {
+		sem := s.semaphore(i, limits[i])
+		select {
+		case sem <- struct{}{}:
+			taken = append(taken, sem)
+			continue
+		default:
+		}
+		if limits[i].Wait <= 0 {
+			release()
+			return nil, &limits[i], nil
+		}
+		timer := time.NewTimer(limits[i].Wait)
+		select {
+		case sem <- struct{}{}:
+			timer.Stop()
+			taken = append(taken, sem)
+		case <-timer.C:
+			release()
+			return nil, &limits[i], nil
+		case <-ctx.Done():
+			timer.Stop()
+			release()
+			return nil, nil, ctx.Err()
+		}
+	}
+	return release, nil, nil
+}
+
+// busyText explains a call that was rejected because the operation's concurrency limit is reached.
+func busyText(limit *ConcurrencyLimit, operationID, callID string) string {
+	scope := "this operation"
+	if limit.Tag != "" {
+		scope = fmt.Sprintf("the operations tagged %q", limit.Tag)
+	}
+	running := fmt.Sprintf("%d calls of %s are", limit.Max, scope)
+	if limit.Max == 1 {
+		running = "a call of " + scope + " is"
+	}
+	return fmt.Sprintf("Operation busy: %s already running, the most allowed at once.\nWait for running calls to complete, then retry this call; don't start it in parallel again.\nOperation: %s\nCall ID: %s", running, operationID, callID)
+}
@@ -0,0 +1,71 @@
+package openapi2mcp
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestToolHandler_ConcurrencyLimit(t *testing.T) {
+	started, release := make(chan struct{}, 2), make(chan struct{})
+	deploy := OpenAPIOperation{OperationID: "deployRelease", Path: "/deploy", Method: "post", Tags: []string{"deploy"}}
+	rollback := OpenAPIOperation{OperationID: "rollbackRelease", Path: "/rollback", Method: "post", Tags: []string{"deploy"}}
+	opts := &ToolGenOptions{
+		ConcurrencyLimits: []ConcurrencyLimit{{Tag: "deploy", Max: 1}},
+		RequestHandler: func(req *http.Request) (*http.Response, error) {
+			started <- struct{}{}
+			<-release
+			return fakeResponse(200, "application/json", `{}`)(req)
+		},
+	}
+	rt := newServerRuntime()
+	deployHandler := toolHandler("deployRelease", deploy, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, rt)
+	rollbackHandler := toolHandler("rollbackRelease", rollback, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, rt)
+
+	done := make(chan error)
+	go func() {
+		_, _, err := deployHandler(context.Background(), nil, map[string]any{"__confirmed": true})
+		done <- err
+	}()
+	<-started
+
+	// Operations sharing the limit are rejected while the call runs
+	res, _, err := rollbackHandler(context.Background(), nil, map[string]any{"__confirmed": true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(t, res); !res.IsError || !strings.Contains(text, "Operation busy") || !strings.Contains(text, `tagged "deploy"`) {
+		t.Errorf("expected a busy error, got: %s", text)
+	}
+
+	// Queued calls run once the slot is free
+	opts.ConcurrencyLimits[0].Wait = time.Minute
+	go func() {
+		_, _, err := rollbackHandler(context.Background(), nil, map[string]any{"__confirmed": true})
+		done <- err
+	}()
+	close(release)
+	for range 2 {
+		if err := <-done; err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(started) != 1 {
+		t.Errorf("expected the queued call to reach the API, got %d pending starts", len(started))
+	}
+}
+
+func TestDescribeConcurrencyLimit(t *testing.T) {
+	op := OpenAPIOperation{OperationID: "deployRelease", Tags: []string{"deploy"}}
+	opts := &ToolGenOptions{ConcurrencyLimits: []ConcurrencyLimit{{Tag: "deploy", Max: 3}, {OperationID: "deployRelease", Max: 1}, {OperationID: "other", Max: 1}}}
+	if desc := describeConcurrencyLimit(opts, op); !strings.Contains(desc, "At most 1 call of this operation may run at once") {
+		t.Errorf("expected the tightest limit to be described, got %q", desc)
+	}
+	if desc := describeConcurrencyLimit(opts, OpenAPIOperation{OperationID: "listReleases"}); desc != "" {
+		t.Errorf("expected no description for unlimited operations, got %q", desc)
+	}
+}
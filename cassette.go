@@ -0,0 +1,338 @@
+// cassette.go
+package openapi2mcp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// credentialQueryParams lists lower-cased query parameter names that are removed from recorded URLs besides those
+// of the spec's apiKey schemes.
+var credentialQueryParams = []string{"api_key", "apikey", "api-key", "access_token", "client_secret"}
+
+// Cassette is a recorded sequence of upstream HTTP interactions, stored as JSON.
+type Cassette struct {
+	Interactions []CassetteInteraction `json:"interactions"`
+	Redaction    *CassetteRedaction    `json:"redaction,omitempty"` // how requests were redacted; nil in cassettes recorded without
+}
+
+// CassetteRedaction describes how recorded requests were redacted. On replay, it is applied to the requests again,
+// so that they match the recorded ones.
+type CassetteRedaction struct {
+	QueryParams []string `json:"query_params,omitempty"` // query parameters removed from URLs, besides credentialQueryParams
+	JSONPaths   []string `json:"json_paths,omitempty"`   // JSON body paths redacted, besides credential-like keys
+}
+
+// request returns the URL and body of a request as recorded: without credential query parameters, and with
+// credential-like keys and the JSON paths redacted from JSON and form bodies. A nil redaction only sorts the query.
+func (c *CassetteRedaction) request(u *url.URL, contentType string, body []byte) (string, string) {
+	if c == nil {
+		return canonicalURL(u), string(body)
+	}
+	redacted := *u
+	query := redacted.Query()
+	for name := range query {
+		if slices.Contains(credentialQueryParams, strings.ToLower(name)) || slices.ContainsFunc(c.QueryParams, func(p string) bool { return strings.EqualFold(p, name) }) {
+			query.Del(name)
+		}
+	}
+	redacted.RawQuery = query.Encode()
+
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		if form, err := url.ParseQuery(string(body)); err == nil {
+			for name := range form {
+				if isSensitiveKey(name) {
+					form.Set(name, redactedValue)
+				}
+			}
+			return canonicalURL(&redacted), form.Encode()
+		}
+	}
+	if len(body) > 0 {
+		body = (&RedactionRules{JSONPaths: c.JSONPaths}).redactBody(body)
+	}
+	return canonicalURL(&redacted), string(body)
+}
+
+// apiKeyQueryParams returns the names of the query parameters of the spec's apiKey schemes.
+func apiKeyQueryParams(doc *openapi3.T) []string {
+	var names []string
+	if doc != nil && doc.Components != nil {
+		for _, ref := range doc.Components.SecuritySchemes {
+			if ref != nil && ref.Value != nil && ref.Value.Type == "apiKey" && ref.Value.In == "query" {
+				names = append(names, ref.Value.Name)
+			}
+		}
+	}
+	return names
+}
+
+// CassetteInteraction is a single recorded request/response pair.
+type CassetteInteraction struct {
+	Request    CassetteRequest  `json:"request"`
+	Response   CassetteResponse `json:"response"`
+	RecordedAt time.Time        `json:"recorded_at"`
+}
+
+// CassetteRequest is a recorded upstream request. Credential headers, query parameters, and body fields are redacted.
+type CassetteRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+}
+
+// CassetteResponse is a recorded upstream response.
+// BodyEncoding is "base64" for bodies that are not valid UTF-8.
+type CassetteResponse struct {
+	Status       int               `json:"status"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	Body         string            `json:"body,omitempty"`
+	BodyEncoding string            `json:"body_encoding,omitempty"`
+}
+
+// key identifies requests that are replayed with the same recorded responses:
+// the method, the URL (query parameters are sorted on encoding), and the body.
+func (r CassetteRequest) key() string {
+	return r.Method + " " + r.URL + "\n" + r.Body
+}
+
+// CassetteRecorder wraps a request handler and records every upstream interaction to a cassette file.
+// The file is rewritten after each interaction, so a partial session is never lost.
+type CassetteRecorder struct {
+	mu       sync.Mutex
+	path     string
+	next     func(req *http.Request) (*http.Response, error)
+	rules    *RedactionRules
+	cassette Cassette
+}
+
+// NewCassetteRecorder creates a recorder writing to path. If next is nil, http.DefaultClient is used.
+// Authorization, Cookie, the headers listed in rules (which may be nil), credential query parameters, and
+// credential-like keys and the JSON paths of rules in request bodies are redacted in the cassette. Set it as
+// opts.Cassette rather than using RequestHandler, so that requests go through the client enforcing the host and
+// redirect policies, and the credential headers and apiKey query parameters of the spec are redacted as well.
+//
+//	rec := openapi2mcp.NewCassetteRecorder("cassette.json", nil, nil)
+//	opts.Cassette = rec
+func NewCassetteRecorder(path string, next func(req *http.Request) (*http.Response, error), rules *RedactionRules) *CassetteRecorder {
+	if next == nil {
+		next = defaultRequestHandler
+	}
+	redaction := &CassetteRedaction{}
+	if rules != nil {
+		redaction.JSONPaths = rules.JSONPaths
+	}
+	return &CassetteRecorder{path: path, next: next, rules: rules, cassette: Cassette{Redaction: redaction}}
+}
+
+// RequestHandler performs the request with the wrapped handler and records the interaction.
+func (r *CassetteRecorder) RequestHandler(req *http.Request) (*http.Response, error) {
+	return r.record(r.next, nil, req)
+}
+
+// handler returns a request handler sending requests with next and recording them, redacting the credential
+// headers and apiKey query parameters of doc and opts as well.
+func (r *CassetteRecorder) handler(next func(req *http.Request) (*http.Response, error), doc *openapi3.T, opts *ToolGenOptions) func(req *http.Request) (*http.Response, error) {
+	credentials := credentialHeaders(doc, opts)
+	r.mu.Lock()
+	for _, name := range apiKeyQueryParams(doc) {
+		if !slices.Contains(r.cassette.Redaction.QueryParams, name) {
+			r.cassette.Redaction.QueryParams = append(r.cassette.Redaction.QueryParams, name)
+		}
+	}
+	r.mu.Unlock()
+	return func(req *http.Request) (*http.Response, error) {
+		return r.record(next, credentials, req)
+	}
+}
+
+// record performs the request with next and records the interaction, also redacting the credential headers.
+func (r *CassetteRecorder) record(next func(req *http.Request) (*http.Response, error), credentials []string, req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		reqBody, _ = io.ReadAll(req.Body)
+		req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := next(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	r.mu.Lock()
+	recordedURL, recordedBody := r.cassette.Redaction.request(req.URL, req.Header.Get("Content-Type"), reqBody)
+	r.mu.Unlock()
+	interaction := CassetteInteraction{
+		Request: CassetteRequest{
+			Method:  req.Method,
+			URL:     recordedURL,
+			Headers: recordedHeaders(req.Header, r.rules, credentials),
+			Body:    recordedBody,
+		},
+		Response: CassetteResponse{
+			Status:  resp.StatusCode,
+			Headers: recordedHeaders(resp.Header, r.rules, credentials),
+		},
+		RecordedAt: time.Now().UTC(),
+	}
+	if utf8.Valid(respBody) {
+		interaction.Response.Body = string(respBody)
+	} else {
+		interaction.Response.Body = base64.StdEncoding.EncodeToString(respBody)
+		interaction.Response.BodyEncoding = "base64"
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	if err := r.save(); err != nil {
+		return nil, fmt.Errorf("failed to write cassette %s: %w", r.path, err)
+	}
+	return resp, nil
+}
+
+// recordedHeaders returns the headers as recorded, with credentials, the headers of rules, and the credential
+// headers redacted.
+func recordedHeaders(header http.Header, rules *RedactionRules, credentials []string) map[string]string {
+	out := redactHeaders(header, rules)
+	for name := range out {
+		if slices.ContainsFunc(credentials, func(c string) bool { return c != "" && strings.EqualFold(c, name) }) {
+			out[name] = redactedValue
+		}
+	}
+	return out
+}
+
+// save writes the cassette to a temporary file and renames it into place.
+func (r *CassetteRecorder) save() error {
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), r.path)
+}
+
+// CassetteReplayer serves recorded responses instead of calling the upstream API.
+type CassetteReplayer struct {
+	redaction    *CassetteRedaction // applied to requests before matching them with the recorded ones
+	mu           sync.Mutex
+	interactions map[string][]CassetteInteraction
+	played       map[string]int
+}
+
+// LoadCassette reads a cassette file for replay.
+//
+//	rep, err := openapi2mcp.LoadCassette("cassette.json")
+//	if err != nil { log.Fatal(err) }
+//	opts.RequestHandler = rep.RequestHandler
+func LoadCassette(path string) (*CassetteReplayer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("invalid cassette %s: %w", path, err)
+	}
+	r := &CassetteReplayer{
+		redaction:    cassette.Redaction,
+		interactions: make(map[string][]CassetteInteraction),
+		played:       make(map[string]int),
+	}
+	for _, i := range cassette.Interactions {
+		key := i.Request.key()
+		r.interactions[key] = append(r.interactions[key], i)
+	}
+	return r, nil
+}
+
+// RequestHandler returns the recorded response for a request with the same method, URL, and body.
+// Repeated requests get the recorded responses in order; once exhausted, the last one is repeated.
+// Requests that were never recorded fail with an error.
+func (r *CassetteReplayer) RequestHandler(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		reqBody, _ = io.ReadAll(req.Body)
+		req.Body.Close()
+	}
+	recordedURL, recordedBody := r.redaction.request(req.URL, req.Header.Get("Content-Type"), reqBody)
+	key := CassetteRequest{Method: req.Method, URL: recordedURL, Body: recordedBody}.key()
+
+	r.mu.Lock()
+	recorded := r.interactions[key]
+	n := r.played[key]
+	if n < len(recorded) {
+		r.played[key] = n + 1
+	}
+	r.mu.Unlock()
+
+	if len(recorded) == 0 {
+		return nil, fmt.Errorf("no recorded interaction for %s %s in cassette; re-record the cassette with --record to include this request", req.Method, recordedURL)
+	}
+	interaction := recorded[min(n, len(recorded)-1)]
+
+	body := []byte(interaction.Response.Body)
+	if interaction.Response.BodyEncoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(interaction.Response.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 body in cassette for %s %s: %w", req.Method, req.URL, err)
+		}
+		body = decoded
+	}
+	header := make(http.Header, len(interaction.Response.Headers))
+	for name, value := range interaction.Response.Headers {
+		header.Set(name, value)
+	}
+	header.Del("Content-Length")
+
+	return &http.Response{
+		StatusCode:    interaction.Response.Status,
+		Status:        fmt.Sprintf("%d %s", interaction.Response.Status, http.StatusText(interaction.Response.Status)),
+		Header:        header,
+		ContentLength: int64(len(body)),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		Request:       req,
+	}, nil
+}
+
+// canonicalURL returns the URL with query parameters in sorted order.
+func canonicalURL(u *url.URL) string {
+	sorted := *u
+	sorted.RawQuery = sorted.Query().Encode()
+	return strings.TrimSuffix(sorted.String(), "?")
+}
@@ -0,0 +1,162 @@
+package openapi2mcp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestCassette_RecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	calls := 0
+	upstream := func(req *http.Request) (*http.Response, error) {
+		calls++
+		return fakeResponse(200, "application/json", `{"call":`+strconv.Itoa(calls)+`}`)(req)
+	}
+	rec := NewCassetteRecorder(path, upstream, nil)
+
+	op := OpenAPIOperation{OperationID: "getPet", Path: "/pet", Method: "get"}
+	call := func(handler func(*http.Request) (*http.Response, error)) (string, error) {
+		tool := toolHandler("getPet", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://api.example.com"}, &ToolGenOptions{RequestHandler: handler}, nil)
+		res, _, err := tool(context.Background(), nil, map[string]any{})
+		if err != nil {
+			return "", err
+		}
+		return resultText(t, res), nil
+	}
+
+	for range 2 {
+		if _, err := call(rec.RequestHandler); err != nil {
+			t.Fatalf("unexpected error recording: %v", err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected cassette to be written: %v", err)
+	}
+	if !strings.Contains(string(data), `"url": "http://api.example.com/pet"`) {
+		t.Errorf("expected recorded URL in cassette, got: %s", data)
+	}
+
+	rep, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("failed to load cassette: %v", err)
+	}
+	// Recorded responses are replayed in order, then the last one repeats
+	for _, want := range []string{`{"call":1}`, `{"call":2}`, `{"call":2}`} {
+		text, err := call(rep.RequestHandler)
+		if err != nil {
+			t.Fatalf("unexpected error replaying: %v", err)
+		}
+		if !strings.Contains(text, want) {
+			t.Errorf("expected replayed body %s, got: %s", want, text)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("expected replay not to call upstream, got %d upstream calls", calls)
+	}
+}
+
+func TestCassette_RedactsAndMatches(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	binary := string([]byte{0xff, 0x00, 0xfe})
+	rec := NewCassetteRecorder(path, fakeResponse(200, "application/octet-stream", binary), &RedactionRules{Headers: []string{"X-Api-Key"}})
+
+	req, _ := http.NewRequest("POST", "http://api.example.com/upload?b=2&a=1", strings.NewReader(`{"name":"Rex"}`))
+	req.Header.Set("Authorization", "Bearer secret")
+	req.Header.Set("X-Api-Key", "secret")
+	if _, err := rec.RequestHandler(req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("expected credentials to be redacted, got: %s", data)
+	}
+
+	rep, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("failed to load cassette: %v", err)
+	}
+	// Query parameter order doesn't matter
+	req, _ = http.NewRequest("POST", "http://api.example.com/upload?a=1&b=2", strings.NewReader(`{"name":"Rex"}`))
+	resp, err := rep.RequestHandler(req)
+	if err != nil {
+		t.Fatalf("expected recorded interaction to match: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != binary {
+		t.Errorf("expected binary body to round-trip, got %q", body)
+	}
+
+	// A different body was never recorded
+	req, _ = http.NewRequest("POST", "http://api.example.com/upload?a=1&b=2", strings.NewReader(`{"name":"Max"}`))
+	if _, err := rep.RequestHandler(req); err == nil || !strings.Contains(err.Error(), "no recorded interaction") {
+		t.Errorf("expected error for unrecorded request, got %v", err)
+	}
+}
+
+func TestCassette_RedactsSpecCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	rec := NewCassetteRecorder(path, nil, &RedactionRules{JSONPaths: []string{"$.card.number"}})
+	doc := minimalOpenAPIDoc()
+	doc.Components = &openapi3.Components{SecuritySchemes: openapi3.SecuritySchemes{
+		"header": &openapi3.SecuritySchemeRef{Value: &openapi3.SecurityScheme{Type: "apiKey", In: "header", Name: "X-Secret-Key"}},
+		"query":  &openapi3.SecuritySchemeRef{Value: &openapi3.SecurityScheme{Type: "apiKey", In: "query", Name: "sig"}},
+	}}
+	handler := rec.handler(fakeResponse(200, "application/json", `{}`), doc, &ToolGenOptions{APIKeyHeader: "X-Fallback-Key"})
+
+	send := func(target, contentType, body string) {
+		req, _ := http.NewRequest("POST", target, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("X-Secret-Key", "secret-header")
+		req.Header.Set("X-Fallback-Key", "secret-fallback")
+		if _, err := handler(req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	send("http://api.example.com/pay?sig=secret-sig&page=2", "application/json", `{"card":{"number":"secret-card"},"amount":5}`)
+	send("http://auth.example.com/token", "application/x-www-form-urlencoded", "grant_type=client_credentials&client_secret=secret-client")
+
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "secret-") {
+		t.Errorf("expected credentials to be redacted, got: %s", data)
+	}
+	if !strings.Contains(string(data), "page=2") || !strings.Contains(string(data), "grant_type=client_credentials") {
+		t.Errorf("expected other parameters to be kept, got: %s", data)
+	}
+
+	// Replayed requests are redacted the same way, so that they match
+	rep, err := LoadCassette(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest("POST", "http://api.example.com/pay?page=2&sig=other-sig", strings.NewReader(`{"amount":5,"card":{"number":"other-card"}}`))
+	req.Header.Set("Content-Type", "application/json")
+	if _, err := rep.RequestHandler(req); err != nil {
+		t.Errorf("expected the redacted request to match: %v", err)
+	}
+}
+
+func TestCassette_RecordsThroughHostGuard(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	doc := minimalOpenAPIDoc()
+	rt := newServerRuntime()
+	rt.hosts = newHostGuard(&HostPolicy{}, doc, nil, []string{"https://api.example.com"})
+	send := upstreamRequestHandler(rt, doc, &ToolGenOptions{Cassette: NewCassetteRecorder(path, nil, nil)})
+
+	req, _ := http.NewRequest("GET", "http://169.254.169.254/latest/meta-data/", nil)
+	if _, err := send(req); err == nil {
+		t.Fatal("expected the host guard to reject the request while recording")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected nothing to be recorded, got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+// charset.go
+package openapi2mcp
+
+import (
+	"bytes"
+	"mime"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// decodeCharset transcodes a response body to UTF-8 from the charset declared in contentType, or announced by a
+// UTF-16 byte order mark, for legacy APIs sending e.g. ISO-8859-1 or Shift_JIS. It returns the body and its content
+// type, now declaring charset=utf-8. Bodies already in UTF-8, with an unknown charset, or of an image, audio, video,
+// font, or model type are returned unchanged.
+func decodeCharset(contentType string, body []byte) ([]byte, string) {
+	if len(body) == 0 {
+		return body, contentType
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil && contentType != "" || binaryMediaType(mediaType) {
+		return body, contentType
+	}
+
+	var enc encoding.Encoding
+	switch label := params["charset"]; {
+	case label != "":
+		if enc, err = htmlindex.Get(label); err != nil {
+			return body, contentType
+		}
+	case bytes.HasPrefix(body, []byte{0xFF, 0xFE}), bytes.HasPrefix(body, []byte{0xFE, 0xFF}):
+		enc = unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM)
+	default:
+		return body, contentType
+	}
+	if enc == unicode.UTF8 {
+		return body, contentType
+	}
+
+	// A byte order mark wins over the declared charset, as in browsers
+	decoded, _, err := transform.Bytes(unicode.BOMOverride(enc.NewDecoder()), body)
+	if err != nil {
+		return body, contentType
+	}
+	if mediaType == "" {
+		mediaType = "text/plain"
+	}
+	if params == nil {
+		params = map[string]string{}
+	}
+	params["charset"] = "utf-8"
+	return decoded, mime.FormatMediaType(mediaType, params)
+}
@@ -0,0 +1,46 @@
+package openapi2mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestDecodeCharset(t *testing.T) {
+	tests := []struct {
+		name, contentType, body string
+		want, wantType          string
+	}{
+		{"latin-1", "text/plain; charset=ISO-8859-1", "caf\xe9", "café", "text/plain; charset=utf-8"},
+		{"windows-1252", "text/plain; charset=windows-1252", "\x80 5", "€ 5", "text/plain; charset=utf-8"},
+		{"shift_jis json", "application/json; charset=Shift_JIS", "{\"name\": \"\x93\xfa\x96\x7b\"}", `{"name": "日本"}`, "application/json; charset=utf-8"},
+		{"utf-16 bom", "", "\xff\xfeO\x00K\x00", "OK", "text/plain; charset=utf-8"},
+		{"utf-8", "text/plain; charset=utf-8", "café", "café", "text/plain; charset=utf-8"},
+		{"no charset", "text/plain", "caf\xe9", "caf\xe9", "text/plain"},
+		{"unknown charset", "text/plain; charset=x-unknown", "caf\xe9", "caf\xe9", "text/plain; charset=x-unknown"},
+		{"image", "image/svg+xml; charset=iso-8859-1", "<svg/>", "<svg/>", "image/svg+xml; charset=iso-8859-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, contentType := decodeCharset(tt.contentType, []byte(tt.body))
+			if string(body) != tt.want || contentType != tt.wantType {
+				t.Errorf("expected %q as %q, got %q as %q", tt.want, tt.wantType, body, contentType)
+			}
+		})
+	}
+}
+
+func TestToolHandler_LegacyCharset(t *testing.T) {
+	op := OpenAPIOperation{OperationID: "getCity", Path: "/city", Method: "get"}
+	opts := &ToolGenOptions{RequestHandler: fakeResponse(200, "application/json; charset=iso-8859-1", "{\"name\": \"M\xfcnchen\"}")}
+	handler := toolHandler("getCity", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+	res, _, err := handler(context.Background(), nil, map[string]any{"__filter": "name"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(t, res); !strings.Contains(text, `"München"`) || strings.Contains(text, "MISMATCH") {
+		t.Errorf("expected the body transcoded to UTF-8, got: %s", text)
+	}
+}
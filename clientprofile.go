@@ -0,0 +1,187 @@
+// clientprofile.go
+package openapi2mcp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Values of ToolGenOptions.ClientProfile.
+const (
+	ClientProfileClaude  = "claude"  // Claude Desktop, Claude Code, and the Claude API
+	ClientProfileCursor  = "cursor"  // Cursor
+	ClientProfileGeneric = "generic" // unknown clients: the constraints of the most limited common clients
+)
+
+// clientProfile describes the constraints of an MCP client the tools are adapted to.
+type clientProfile struct {
+	maxToolNameLength    int  // longer tool names are shortened, keeping them unique; 0 means no limit
+	maxDescriptionLength int  // longer tool descriptions are shortened; 0 means no limit
+	inlineSchemas        bool // if true, input schemas contain no $ref, even with CompactSchemas
+	flattenAllOf         bool // if true, allOf object schemas are merged into their parent
+	nativeConfirmation   bool // if true, the client asks the user before calling tools, guided by their annotations
+}
+
+// clientProfiles are the known client profiles by name.
+var clientProfiles = map[string]clientProfile{
+	// Tool names must match ^[a-zA-Z0-9_-]{1,64}$; the apps ask before calling tools that aren't read-only
+	ClientProfileClaude: {maxToolNameLength: 64, nativeConfirmation: true},
+	// Server and tool name together may not exceed 60 characters; $ref and allOf schemas are sent to models unresolved
+	ClientProfileCursor:  {maxToolNameLength: 60, maxDescriptionLength: 2048, inlineSchemas: true, flattenAllOf: true, nativeConfirmation: true},
+	ClientProfileGeneric: {maxToolNameLength: 64, maxDescriptionLength: 1024, inlineSchemas: true, flattenAllOf: true},
+}
+
+// ClientProfiles returns the names of the known client profiles, sorted.
+func ClientProfiles() []string {
+	return slices.Sorted(maps.Keys(clientProfiles))
+}
+
+// withClientProfile returns a copy of opts adapted to its ClientProfile, or opts if it has none: tool names are
+// sanitized and shortened, schemas inlined and flattened, and the __confirmed round trip is left to clients asking
+// the user themselves.
+func withClientProfile(opts *ToolGenOptions) *ToolGenOptions {
+	if opts == nil {
+		return nil
+	}
+	profile, ok := clientProfiles[opts.ClientProfile]
+	if !ok {
+		return opts
+	}
+	adapted := *opts
+	nameFormat := opts.NameFormat
+	adapted.NameFormat = func(name string) string {
+		if nameFormat != nil {
+			name = nameFormat(name)
+		}
+		return profileToolName(name, profile.maxToolNameLength)
+	}
+	if profile.inlineSchemas {
+		adapted.CompactSchemas = false
+	}
+	if profile.flattenAllOf {
+		postProcess := opts.PostProcessSchema
+		adapted.PostProcessSchema = func(toolName string, schema jsonschema.Schema) jsonschema.Schema {
+			if postProcess != nil {
+				schema = postProcess(toolName, schema)
+			}
+			return *flattenAllOf(&schema, map[*jsonschema.Schema]*jsonschema.Schema{})
+		}
+	}
+	if profile.nativeConfirmation {
+		adapted.ConfirmDangerousActions = false
+	}
+	if opts.MaxDescriptionLength <= 0 {
+		adapted.MaxDescriptionLength = profile.maxDescriptionLength
+	}
+	return &adapted
+}
+
+// profileToolName replaces characters other than letters, digits, _ and - in name, and shortens it to maxLength
+// (if positive) with a suffix derived from the full name, so that shortened names stay unique.
+func profileToolName(name string, maxLength int) string {
+	sanitized := []byte(name)
+	for i, c := range sanitized {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-') {
+			sanitized[i] = '_'
+		}
+	}
+	if maxLength <= 0 || len(sanitized) <= maxLength {
+		return string(sanitized)
+	}
+	sum := sha256.Sum256([]byte(name))
+	suffix := "_" + hex.EncodeToString(sum[:4])
+	return string(sanitized[:max(maxLength-len(suffix), 0)]) + suffix
+}
+
+// profileAnnotations sets the hints clients asking the user before tool calls rely on, from the HTTP method of the tool.
+func profileAnnotations(annotations *mcp.ToolAnnotations, method string) {
+	switch method = strings.ToUpper(method); method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		annotations.ReadOnlyHint = true
+	default:
+		destructive := method == http.MethodPut || method == http.MethodPatch || method == http.MethodDelete
+		annotations.DestructiveHint = &destructive
+		annotations.IdempotentHint = idempotentMethod(method)
+	}
+}
+
+// flattenAllOf returns s with the object schemas of its allOf lists merged into their parent, recursively.
+// Subschemas that aren't plain objects (with keywords other than type, properties, required, and descriptions) stay
+// in allOf. s is copied, so that schemas shared between tools are never modified.
+func flattenAllOf(s *jsonschema.Schema, seen map[*jsonschema.Schema]*jsonschema.Schema) *jsonschema.Schema {
+	if s == nil {
+		return nil
+	}
+	if out, ok := seen[s]; ok {
+		return out
+	}
+	out := shallowCopy(s)
+	seen[s] = out
+	out.Items = flattenAllOf(s.Items, seen)
+	if s.Properties != nil {
+		out.Properties = make(map[string]*jsonschema.Schema, len(s.Properties))
+		for name, prop := range s.Properties {
+			out.Properties[name] = flattenAllOf(prop, seen)
+		}
+	}
+	out.OneOf, out.AnyOf = flattenList(s.OneOf, seen), flattenList(s.AnyOf, seen)
+
+	var rest []*jsonschema.Schema
+	for _, sub := range flattenList(s.AllOf, seen) {
+		if !plainObjectSchema(sub) || out.Type != "" && out.Type != "object" {
+			rest = append(rest, sub)
+			continue
+		}
+		out.Type = "object"
+		if len(sub.Properties) > 0 && out.Properties == nil {
+			out.Properties = make(map[string]*jsonschema.Schema, len(sub.Properties))
+		}
+		for name, prop := range sub.Properties {
+			if _, ok := out.Properties[name]; !ok {
+				out.Properties[name] = prop
+			}
+		}
+		for _, name := range sub.Required {
+			if !slices.Contains(out.Required, name) {
+				out.Required = append(slices.Clip(out.Required), name)
+			}
+		}
+		if out.Description == "" {
+			out.Description = sub.Description
+		}
+	}
+	out.AllOf = rest
+	return out
+}
+
+// flattenList flattens each schema of list, returning nil for an empty list.
+func flattenList(list []*jsonschema.Schema, seen map[*jsonschema.Schema]*jsonschema.Schema) []*jsonschema.Schema {
+	if len(list) == 0 {
+		return nil
+	}
+	out := make([]*jsonschema.Schema, len(list))
+	for i, sub := range list {
+		out[i] = flattenAllOf(sub, seen)
+	}
+	return out
+}
+
+// plainObjectSchema reports whether s only has the keywords flattenAllOf merges: type object, properties, required,
+// title, and description.
+func plainObjectSchema(s *jsonschema.Schema) bool {
+	if s == nil || s.Type != "" && s.Type != "object" {
+		return false
+	}
+	rest := shallowCopy(s)
+	rest.Type, rest.Properties, rest.Required, rest.Title, rest.Description = "", nil, nil, "", ""
+	data, err := json.Marshal(rest)
+	return err == nil && (string(data) == "{}" || string(data) == "true")
+}
@@ -0,0 +1,123 @@
+package openapi2mcp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestProfileToolName(t *testing.T) {
+	long := strings.Repeat("listAllTheThings", 5)
+	short := profileToolName(long, 64)
+	if len(short) != 64 || !strings.HasPrefix(short, "listAllTheThings") {
+		t.Errorf("expected a 64 character name, got %q", short)
+	}
+	if other := profileToolName(long+"X", 64); other == short {
+		t.Errorf("expected shortened names to stay unique, got %q twice", short)
+	}
+	if got := profileToolName("pets.list v2", 64); got != "pets_list_v2" {
+		t.Errorf("expected unsupported characters to be replaced, got %q", got)
+	}
+}
+
+func TestFlattenAllOf(t *testing.T) {
+	shared := &jsonschema.Schema{Type: "object", Properties: map[string]*jsonschema.Schema{"id": {Type: "string"}}, Required: []string{"id"}}
+	s := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"pet": {AllOf: []*jsonschema.Schema{
+				shared,
+				{Properties: map[string]*jsonschema.Schema{"name": {Type: "string"}}, Required: []string{"name"}},
+				{Not: &jsonschema.Schema{Required: []string{"owner"}}},
+			}},
+		},
+	}
+	out := flattenAllOf(s, map[*jsonschema.Schema]*jsonschema.Schema{})
+
+	pet := out.Properties["pet"]
+	if pet.Type != "object" || pet.Properties["id"] == nil || pet.Properties["name"] == nil {
+		t.Errorf("expected the object subschemas to be merged, got %+v", pet)
+	}
+	if strings.Join(pet.Required, ",") != "id,name" {
+		t.Errorf("expected the required fields to be merged, got %v", pet.Required)
+	}
+	if len(pet.AllOf) != 1 || pet.AllOf[0].Not == nil {
+		t.Errorf("expected the subschema that can't be merged to stay in allOf, got %v", pet.AllOf)
+	}
+	if len(s.Properties["pet"].AllOf) != 3 || len(shared.Properties) != 1 {
+		t.Error("expected the original schemas to be unchanged")
+	}
+}
+
+func TestGenerateToolSummaries_ClientProfile(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /pets/{id}:
+    delete:
+      operationId: pets.delete
+      description: "` + strings.Repeat("Deletes the pet. ", 100) + `"
+      parameters: [{name: id, in: path, required: true, schema: {type: string}}]
+      responses: {"204": {description: deleted}}
+    put:
+      operationId: pets.update
+      parameters: [{name: id, in: path, required: true, schema: {type: string}}]
+      requestBody:
+        content:
+          application/json:
+            schema:
+              allOf:
+                - {$ref: '#/components/schemas/Named'}
+                - {type: object, properties: {tag: {$ref: '#/components/schemas/Named'}}}
+      responses: {"200": {description: updated}}
+components:
+  schemas:
+    Named: {type: object, properties: {name: {type: string}}, required: [name]}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ops := ExtractOpenAPIOperations(doc)
+
+	summaries := GenerateToolSummaries(ops, doc, &ToolGenOptions{ClientProfile: ClientProfileGeneric, CompactSchemas: true})
+	byName := map[string]ToolSummary{}
+	for _, s := range summaries {
+		byName[s.Name] = s
+	}
+	del, ok := byName["pets_delete"]
+	if !ok {
+		t.Fatalf("expected sanitized tool names, got %v", summaries)
+	}
+	if len(del.Description) > 1024 || !strings.Contains(del.Description, "(Description shortened: call describe_tool") {
+		t.Errorf("expected the description to be shortened to 1024 bytes, got %d bytes", len(del.Description))
+	}
+	body := byName["pets_update"].InputSchema.Properties["requestBody"]
+	if body == nil || len(body.AllOf) != 0 || body.Properties["name"] == nil || body.Properties["tag"] == nil {
+		t.Errorf("expected allOf to be flattened, got %+v", body)
+	}
+	if body != nil && body.Properties["tag"] != nil && body.Properties["tag"].Ref != "" {
+		t.Errorf("expected no $ref with the generic profile, got %q", body.Properties["tag"].Ref)
+	}
+
+	// Without a profile, names and schemas are kept
+	for _, s := range GenerateToolSummaries(ops, doc, &ToolGenOptions{}) {
+		if s.Name != "pets.delete" && s.Name != "pets.update" {
+			t.Errorf("expected the operationId as name, got %q", s.Name)
+		}
+	}
+}
+
+func TestWithClientProfile_NativeConfirmation(t *testing.T) {
+	opts := &ToolGenOptions{ClientProfile: ClientProfileClaude, ConfirmDangerousActions: true, CompactSchemas: true}
+	adapted := withClientProfile(opts)
+	if adapted.ConfirmDangerousActions || !opts.ConfirmDangerousActions {
+		t.Error("expected the __confirmed round trip to be left to the client, without changing the options")
+	}
+	if !adapted.CompactSchemas {
+		t.Error("expected $ref to be kept for clients supporting it")
+	}
+	if got := withClientProfile(&ToolGenOptions{ClientProfile: ClientProfileGeneric, ConfirmDangerousActions: true}); !got.ConfirmDangerousActions {
+		t.Error("expected confirmations to be asked for by clients without their own")
+	}
+}
@@ -0,0 +1,36 @@
+// bench.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	openapi2mcp "github.com/evcc-io/openapi-mcp"
+)
+
+// handleBenchCommand runs the load-test harness for the spec at specPath and prints the report.
+// The tools are registered with the same options as in server mode, but all calls go to a stub upstream.
+func handleBenchCommand(flags *cliFlags, specPath string) {
+	doc, err := openapi2mcp.LoadOpenAPISpec(specPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not load OpenAPI spec: %v\n", err)
+		os.Exit(1)
+	}
+	ops, err := serverOperations(doc)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	report, err := openapi2mcp.RunLoadTest(context.Background(), doc, ops, &openapi2mcp.LoadTestOptions{
+		Calls:          flags.benchCalls,
+		Concurrency:    flags.benchConcurrency,
+		ToolGenOptions: toolGenOptions(flags, doc),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Load test failed: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Print(report)
+}
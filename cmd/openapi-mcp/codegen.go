@@ -0,0 +1,78 @@
+// codegen.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime/debug"
+	"slices"
+
+	openapi2mcp "github.com/evcc-io/openapi-mcp"
+)
+
+// handleCodegenCommand generates a standalone Go module serving the spec at args[0] with the tools compiled in.
+// Flags may also follow the spec path, as in "codegen api.yaml -o ./out".
+func handleCodegenCommand(flags *cliFlags, args []string) {
+	fs := flag.NewFlagSet("codegen", flag.ExitOnError)
+	fs.StringVar(&flags.codegenOutput, "o", flags.codegenOutput, "Output directory of the generated module")
+	fs.StringVar(&flags.codegenOutput, "output", flags.codegenOutput, "Output directory of the generated module")
+	fs.StringVar(&flags.codegenModule, "module", flags.codegenModule, "Module path of the generated module")
+	if err := fs.Parse(args[1:]); err != nil || fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "Error: usage: openapi-mcp codegen <openapi-spec-path> -o <output-dir> [--module <module-path>]")
+		os.Exit(1)
+	}
+	if flags.codegenOutput == "" {
+		fmt.Fprintln(os.Stderr, "Error: missing required -o <output-dir> argument for codegen.")
+		os.Exit(1)
+	}
+
+	doc, err := openapi2mcp.LoadOpenAPISpec(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not load OpenAPI spec: %v\n", err)
+		os.Exit(1)
+	}
+	ops, err := serverOperations(doc)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	files, err := openapi2mcp.GenerateGoServer(doc, ops, &openapi2mcp.CodegenOptions{
+		ModulePath:              flags.codegenModule,
+		LibraryVersion:          libraryVersion(),
+		TagFilter:               flags.tagFlags,
+		ConfirmDangerousActions: !flags.noConfirmDangerous,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Code generation failed: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.MkdirAll(flags.codegenOutput, 0o755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		if err := os.WriteFile(filepath.Join(flags.codegenOutput, name), files[name], 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Generated a server for %d operations into %s. Build with:\n  cd %s && go mod tidy && go build\n", len(ops), flags.codegenOutput, flags.codegenOutput)
+}
+
+// libraryVersion returns the released version of openapi-mcp this binary was built from, or "" for development
+// builds, whose (pseudo-)versions can't be required by other modules.
+func libraryVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok || info.Main.Path != "github.com/evcc-io/openapi-mcp" || !releaseVersion.MatchString(info.Main.Version) {
+		return ""
+	}
+	return info.Main.Version
+}
+
+// releaseVersion matches release tags like v1.2.3, but not pseudo-versions like v0.0.0-20260101000000-0123456789ab.
+var releaseVersion = regexp.MustCompile(`^v\d+\.\d+\.\d+$`)
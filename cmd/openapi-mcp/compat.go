@@ -0,0 +1,24 @@
+// compat.go
+package main
+
+import (
+	"fmt"
+	"os"
+
+	openapi2mcp "github.com/evcc-io/openapi-mcp"
+)
+
+// handleCompatCommand prints the features of the spec at specPath that openapi-mcp cannot fully handle, per operation.
+func handleCompatCommand(specPath string) {
+	doc, err := openapi2mcp.LoadOpenAPISpec(specPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not load OpenAPI spec: %v\n", err)
+		os.Exit(1)
+	}
+	ops, err := serverOperations(doc)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Print(openapi2mcp.CheckCompatibility(doc, ops))
+}
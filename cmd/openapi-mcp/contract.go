@@ -0,0 +1,38 @@
+// contract.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	openapi2mcp "github.com/evcc-io/openapi-mcp"
+)
+
+// handleContractCommand calls operations of the spec at specPath against the live API and prints the drift report.
+// It exits with status 1 if any operation drifted from the spec or failed.
+func handleContractCommand(flags *cliFlags, specPath string) {
+	doc, err := openapi2mcp.LoadOpenAPISpec(specPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not load OpenAPI spec: %v\n", err)
+		os.Exit(1)
+	}
+	ops, err := serverOperations(doc)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	report, err := openapi2mcp.RunContractTest(context.Background(), doc, ops, &openapi2mcp.ContractTestOptions{
+		Operations:     flags.contractOps,
+		ToolGenOptions: toolGenOptions(flags, doc),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Contract test failed: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Print(report)
+	if !report.OK() {
+		os.Exit(1)
+	}
+}
@@ -17,53 +17,106 @@ import (
 
 // handleDocMode handles the --doc mode, generating Markdown documentation for all tools.
 func handleDocMode(flags *cliFlags, ops []openapi2mcp.OpenAPIOperation, doc *openapi3.T) {
+	checkDocFormat(flags)
+	summaries, err := docSummaries(flags, ops, doc)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := writeMarkdownDocFromSummaries(flags.docFile, summaries, doc); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing Markdown doc: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote Markdown documentation to %s\n", flags.docFile)
+	os.Exit(0)
+}
+
+// handleMountDocMode handles the --doc mode with --mount, generating one Markdown document with a section
+// per mounted spec, linked from a table of contents.
+func handleMountDocMode(flags *cliFlags) {
+	checkDocFormat(flags)
+	var sections []mountDocSection
+	for _, m := range flags.mounts {
+		doc, err := openapi2mcp.LoadOpenAPISpec(m.SpecPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Could not load OpenAPI spec for mount %s: %v\n", m.BasePath, err)
+			os.Exit(1)
+		}
+		ops, err := serverOperations(doc)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		specFlags := *flags
+		if flags.prefixTools {
+			specFlags.toolPrefix = toolNamePrefix(m.BasePath)
+		}
+		summaries, err := docSummaries(&specFlags, ops, doc)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		sections = append(sections, mountDocSection{basePath: "/" + strings.Trim(m.BasePath, "/"), doc: doc, summaries: summaries})
+	}
+	if err := writeMountsMarkdownDoc(flags.docFile, sections); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing Markdown doc: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote Markdown documentation of %d mounts to %s\n", len(sections), flags.docFile)
+	os.Exit(0)
+}
+
+// checkDocFormat exits unless --doc-format is one that can be written.
+func checkDocFormat(flags *cliFlags) {
+	switch flags.docFormat {
+	case "markdown":
+	case "html":
+		fmt.Fprintf(os.Stderr, "HTML documentation output is not yet implemented.\n")
+		os.Exit(1)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown doc format: %s\n", flags.docFormat)
+		os.Exit(1)
+	}
+}
+
+// docSummaries builds the summaries documented for ops, passed through --post-hook-cmd if set.
+func docSummaries(flags *cliFlags, ops []openapi2mcp.OpenAPIOperation, doc *openapi3.T) ([]map[string]any, error) {
 	toolSummaries := make([]map[string]any, 0, len(ops))
 	for _, op := range ops {
-		name := op.OperationID
-		if flags.toolNameFormat != "" {
-			name = formatToolName(flags.toolNameFormat, name)
-		}
+		name := flags.toolPrefix + formatToolName(flags.toolNameFormat, op.OperationID)
 		desc := op.Description
 		if desc == "" {
 			desc = op.Summary
 		}
 		inputSchema := openapi2mcp.BuildInputSchema(op.Parameters, op.RequestBody)
-		toolSummaries = append(toolSummaries, map[string]any{
+		summary := map[string]any{
 			"name":        name,
 			"description": desc,
 			"tags":        op.Tags,
 			"inputSchema": openapi2mcp.SchemaToMap(inputSchema),
-		})
+		}
+		if samples := openapi2mcp.OperationCodeSamples(op, doc); len(samples) > 0 {
+			summary["codeSamples"] = samples
+		}
+		if op.ExternalDocs != nil && op.ExternalDocs.URL != "" {
+			summary["externalDocs"] = map[string]any{"url": op.ExternalDocs.URL, "description": op.ExternalDocs.Description}
+		}
+		toolSummaries = append(toolSummaries, summary)
 	}
 	jsonBytes, _ := json.MarshalIndent(toolSummaries, "", "  ")
 	if flags.postHookCmd != "" {
 		out, err := processWithPostHook(jsonBytes, flags.postHookCmd)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error running post-hook-cmd: %v\n", err)
-			os.Exit(1)
+			return nil, fmt.Errorf("running post-hook-cmd: %w", err)
 		}
 		jsonBytes = out
 	}
-	if flags.docFormat == "markdown" {
-		// Parse the possibly post-processed JSON back to []map[string]any
-		var processed []map[string]any
-		if err := json.Unmarshal(jsonBytes, &processed); err != nil {
-			fmt.Fprintf(os.Stderr, "Error parsing post-processed JSON: %v\n", err)
-			os.Exit(1)
-		}
-		if err := writeMarkdownDocFromSummaries(flags.docFile, processed, doc); err != nil {
-			fmt.Fprintf(os.Stderr, "Error writing Markdown doc: %v\n", err)
-			os.Exit(1)
-		}
-		fmt.Fprintf(os.Stderr, "Wrote Markdown documentation to %s\n", flags.docFile)
-		os.Exit(0)
-	} else if flags.docFormat == "html" {
-		fmt.Fprintf(os.Stderr, "HTML documentation output is not yet implemented.\n")
-		os.Exit(1)
-	} else {
-		fmt.Fprintf(os.Stderr, "Unknown doc format: %s\n", flags.docFormat)
-		os.Exit(1)
+	// Parse the possibly post-processed JSON back to []map[string]any
+	var processed []map[string]any
+	if err := json.Unmarshal(jsonBytes, &processed); err != nil {
+		return nil, fmt.Errorf("parsing post-processed JSON: %w", err)
 	}
+	return processed, nil
 }
 
 // writeMarkdownDocFromSummaries writes Markdown documentation from a []map[string]any (post-processed summaries).
@@ -75,21 +128,95 @@ func writeMarkdownDocFromSummaries(path string, summaries []map[string]any, doc
 	defer f.Close()
 
 	f.WriteString("# MCP Tools Documentation\n\n")
-	if doc.Info != nil {
-		f.WriteString(fmt.Sprintf("**API Title:** %s\n\n", doc.Info.Title))
-		f.WriteString(fmt.Sprintf("**Version:** %s\n\n", doc.Info.Version))
-		if doc.Info.Description != "" {
-			f.WriteString(doc.Info.Description + "\n\n")
+	writeAPIInfo(f, doc)
+	writeToolsMarkdown(f, summaries, "##", nil)
+	return nil
+}
+
+// mountDocSection is the documentation of one mounted spec.
+type mountDocSection struct {
+	basePath  string
+	doc       *openapi3.T
+	summaries []map[string]any
+}
+
+// anchor returns the anchor of the section's heading, or of one of its tools if tool is non-empty.
+func (s mountDocSection) anchor(tool string) string {
+	id := "mount" + s.basePath
+	if tool != "" {
+		id += "-" + tool
+	}
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		if r >= 'A' && r <= 'Z' {
+			return r - 'A' + 'a'
+		}
+		return '-'
+	}, id)
+}
+
+// writeMountsMarkdownDoc writes the documentation of several mounted specs to one Markdown file: a table of
+// contents linking to each mount's section and tools, then a section per mount, linking back to the contents.
+func writeMountsMarkdownDoc(path string, sections []mountDocSection) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	f.WriteString("# MCP Tools Documentation\n\n")
+	f.WriteString("<a id=\"contents\"></a>\n\n")
+	f.WriteString("| Mount | API | Tools |\n|-------|-----|-------|\n")
+	for _, s := range sections {
+		title := s.basePath
+		if s.doc.Info != nil && s.doc.Info.Title != "" {
+			title = s.doc.Info.Title
+		}
+		var tools []string
+		for _, m := range s.summaries {
+			name, _ := m["name"].(string)
+			tools = append(tools, fmt.Sprintf("[%s](#%s)", name, s.anchor(name)))
 		}
+		f.WriteString(fmt.Sprintf("| [`%s`](#%s) | %s | %s |\n", s.basePath, s.anchor(""), title, strings.Join(tools, ", ")))
+	}
+	f.WriteString("\n")
+
+	for _, s := range sections {
+		f.WriteString(fmt.Sprintf("<a id=\"%s\"></a>\n\n## Mount `%s`\n\n", s.anchor(""), s.basePath))
+		f.WriteString(fmt.Sprintf("**Endpoint:** `%s` ([back to contents](#contents))\n\n", s.basePath))
+		writeAPIInfo(f, s.doc)
+		writeToolsMarkdown(f, s.summaries, "###", s.anchor)
 	}
+	return nil
+}
 
+// writeAPIInfo writes the title, version, and description of the API.
+func writeAPIInfo(w io.StringWriter, doc *openapi3.T) {
+	if doc.Info == nil {
+		return
+	}
+	w.WriteString(fmt.Sprintf("**API Title:** %s\n\n", doc.Info.Title))
+	w.WriteString(fmt.Sprintf("**Version:** %s\n\n", doc.Info.Version))
+	if doc.Info.Description != "" {
+		w.WriteString(doc.Info.Description + "\n\n")
+	}
+}
+
+// writeToolsMarkdown writes a section per tool with the given heading level, e.g. "##". If anchor is non-nil,
+// each section is preceded by the anchor it returns for the tool's name.
+func writeToolsMarkdown(f io.StringWriter, summaries []map[string]any, heading string, anchor func(tool string) string) {
 	for _, m := range summaries {
 		name, _ := m["name"].(string)
 		desc, _ := m["description"].(string)
 		tags, _ := m["tags"].([]any)
 		inputSchema, _ := m["inputSchema"].(map[string]any)
 
-		f.WriteString(fmt.Sprintf("## %s\n\n", name))
+		if anchor != nil {
+			f.WriteString(fmt.Sprintf("<a id=\"%s\"></a>\n\n", anchor(name)))
+		}
+		f.WriteString(fmt.Sprintf("%s %s\n\n", heading, name))
 		if desc != "" {
 			f.WriteString(desc + "\n\n")
 		}
@@ -103,6 +230,16 @@ func writeMarkdownDocFromSummaries(path string, summaries []map[string]any, doc
 			f.WriteString(fmt.Sprintf("**Tags:** %s\n\n", strings.Join(tagStrs, ", ")))
 		}
 
+		// Documentation page of the operation
+		if docs, _ := m["externalDocs"].(map[string]any); docs != nil {
+			url, _ := docs["url"].(string)
+			docsDesc, _ := docs["description"].(string)
+			if docsDesc == "" {
+				docsDesc = url
+			}
+			f.WriteString(fmt.Sprintf("**Documentation:** [%s](%s)\n\n", docsDesc, url))
+		}
+
 		// Arguments
 		props, _ := inputSchema["properties"].(map[string]any)
 		propsOrder := slices.Sorted(maps.Keys(props))
@@ -148,8 +285,21 @@ func writeMarkdownDocFromSummaries(path string, summaries []map[string]any, doc
 			f.WriteString("**Example call:**\n\n")
 			f.WriteString("```json\n" + fmt.Sprintf("call %s %s\n", name, string(exampleJSON)) + "```\n\n")
 		}
+
+		// Code samples from x-codeSamples, which often show the payload structure better than the schema
+		samples, _ := m["codeSamples"].([]any)
+		for _, s := range samples {
+			sample, _ := s.(map[string]any)
+			lang, _ := sample["lang"].(string)
+			label, _ := sample["label"].(string)
+			source, _ := sample["source"].(string)
+			if label == "" {
+				label = lang
+			}
+			f.WriteString(fmt.Sprintf("**Code sample (%s):**\n\n", label))
+			f.WriteString("```" + strings.ToLower(lang) + "\n" + strings.TrimRight(source, "\n") + "\n```\n\n")
+		}
 	}
-	return nil
 }
 
 // processWithPostHook pipes JSON through an external command and returns the output.
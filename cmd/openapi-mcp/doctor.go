@@ -0,0 +1,20 @@
+// doctor.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	openapi2mcp "github.com/evcc-io/openapi-mcp"
+)
+
+// handleDoctorCommand runs the preflight checks for the spec at specPath and prints each problem with a fix.
+// It exits with status 1 if any check failed; warnings don't change the exit status.
+func handleDoctorCommand(flags *cliFlags, specPath string) {
+	report := openapi2mcp.DiagnoseSpec(context.Background(), specPath, &openapi2mcp.DoctorOptions{BaseURL: flags.baseURL})
+	fmt.Print(report)
+	if !report.OK() {
+		os.Exit(1)
+	}
+}
@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
+
+	openapi2mcp "github.com/evcc-io/openapi-mcp"
 )
 
 // cliFlags holds all parsed CLI flags and arguments.
@@ -18,6 +21,8 @@ type cliFlags struct {
 	excludeDescRegex   string
 	dryRun             bool
 	summary            bool
+	tokens             bool // Print the estimated tokens of the tools
+	tokenBudget        int  // Context budget the tools should fit in (tokens); warn beyond it
 	toolNameFormat     string
 	diffFile           string
 	tagFlags           multiFlag
@@ -30,6 +35,104 @@ type cliFlags struct {
 	functionListFile   string     // Path to file listing functions to include (for filter command)
 	logFile            string     // Path to file for logging MCP requests and responses
 	noLogTruncation    bool       // Disable truncation in human-readable MCP logs
+	logMaxSizeMB       int        // Rotate the log file once it exceeds this size (MB)
+	logMaxBackups      int        // Number of rotated log files to keep
+	redactHeaders      multiFlag  // Additional header names to redact in logs
+	redactJSONPaths    multiFlag  // JSON body paths to redact in logs
+	requestIDHeader    string     // Header used to send the per-call correlation ID upstream
+	telemetry          bool       // Append latency/outcome telemetry to results and expose server_stats
+	httpAddr           string     // Serve MCP over streamable HTTP on this address instead of stdio
+	warmMounts         bool       // Load all --mount specs at startup instead of on first request
+	prefixTools        bool       // Prefix tool names with the mount's base path or the spec's file name
+	toolPrefix         string     // Tool name prefix of the spec being served, derived for --prefix-tools
+	compactSchemas     bool       // Emit repeated component schemas within a tool as $ref
+	clientProfile      string     // Adapt tool names, schemas, and descriptions to a known client
+	overridesFile      string     // YAML/JSON file with tool titles, descriptions, and examples by operationId
+	presetsFile        string     // YAML/JSON file with named argument presets by operationId
+	maxDescription     int        // Shorten longer tool descriptions (bytes), offloading the rest to describe_tool
+	maxResponseSizeMB  int        // Truncate upstream response bodies beyond this size (MB)
+	maxRequestSizeMB   int        // Reject tool calls whose request body exceeds this size (MB)
+	maxArgumentSizeKB  int        // Reject tool calls with an argument exceeding this size (KB)
+	mock               bool       // Synthesize responses from the spec instead of calling the API
+	recordFile         string     // Record upstream interactions to this cassette file
+	replayFile         string     // Serve upstream responses from this cassette file
+	live               bool       // Let validate call safe GET operations against the real API
+	liveOps            multiFlag  // operationIds called by validate --live
+	baseURL            string     // Base URL of the API, overriding OPENAPI_BASE_URL and the spec's servers
+	contractOps        multiFlag  // operationIds called by the contract command
+	callbackAddr       string     // Listen address of the local callback (webhook) receiver
+	callbackURL        string     // Public base URL of the callback receiver, as reachable by the API
+	benchCalls         int        // Number of tool calls made by the bench command
+	codegenOutput      string     // Output directory of the codegen command
+	codegenModule      string     // Module path of the module generated by the codegen command
+	manifestURL        string     // Public URL of the streamable HTTP endpoint listed by the manifest command
+	benchConcurrency   int        // Number of concurrent client sessions used by the bench command
+	trimConfigFile     string     // YAML/JSON file with per-operation response trimming rules
+	apiKeyHeader       string     // Header sending API_KEY to operations without a matching security scheme
+	allowHosts         multiFlag  // Additional hosts tool calls may send requests to
+	forwardHeaders     multiFlag  // Incoming HTTP request headers forwarded to upstream requests
+	policyFile         string     // YAML/JSON file with rules authorizing tool calls
+	argumentRulesFile  string     // YAML/JSON file with rules constraining argument values
+	responseHeaders    multiFlag  // Response headers included in results, optionally prefixed with "operationId:"
+	allowPrivateNets   bool       // Let spec hosts resolve to private, loopback, and link-local addresses
+	maxRedirects       int        // Maximum number of redirects followed per tool call
+	noFollowRedirects  bool       // Return 3xx responses with their Location instead of following them
+	operationTimeouts  multiFlag  // Timeouts of single operations, as "operationId:duration"
+	maxConcurrent      multiFlag  // Calls running at once by operation or tag, as "operationId:n" or "tag:name:n"
+	operationCosts     multiFlag  // Costs of single operations, as "operationId:level" or "operationId:level:reason"
+	confirmCost        string     // Cost level from which calls need confirmation
+	htmlToMarkdown     bool       // Convert HTML responses to Markdown
+	guardResponses     bool       // Mark response bodies as untrusted data and flag instruction-like text
+	stripHTML          bool       // Strip scripts, comments, and tags from HTML and JSON responses (with guardResponses)
+	noRateLimitPacing  bool       // Don't delay calls nearing an API's rate limit
+	rateLimitWarning   float64    // Warn in results below this percentage of a host's rate limit
+	reproCommand       string     // Append an equivalent curl or httpie command to results
+	responseLimits     multiFlag  // Response sizes shown by transport, as "stdio:256" (KB)
+	uploadDir          string     // Directory binary request bodies may be uploaded from
+	maxUploadSizeMB    int        // Reject requestBodyFile uploads larger than this size (MB)
+	graphQL            bool       // Give GraphQL endpoints query/operationName/variables arguments
+	codeSamples        multiFlag  // Languages of the x-codeSamples shown in tool descriptions
+	timeZone           string     // IANA time zone of the date helpers
+	relativeDates      bool       // Accept relative values such as "yesterday" in date parameters
+	coerceArgs         bool       // Convert stringly-typed arguments such as "123" before validation
+	autoIfMatch        bool       // Send the current ETag or version of resources updates leave it out for
+	sessionCookies     bool       // Keep the cookies the API sets per MCP session
+	coalesceRequests   bool       // Share the response of identical GET calls in flight within a session
+	scheduleCalls      bool       // Add tools queuing tool calls for later
+	callGroups         bool       // Add tools grouping calls and suggesting compensating calls on abort
+	groupRollback      bool       // Let abort_group execute the compensating calls
+	workflowsFile      string     // YAML/JSON file with workflows served as composite tools
+	readOnly           bool       // Start with non-GET calls blocked
+	adminAddr          string     // Address of the admin endpoint toggling read-only mode
+	arazzoFiles        multiFlag  // Arazzo documents whose workflows are served as composite tools
+	csrfHeader         string     // Header sending the CSRF token with state-changing calls
+	csrfCookie         string     // Cookie the CSRF token is read from
+	csrfEndpoint       string     // Path or URL answering a GET with the CSRF token
+	csrfField          string     // JSON field of the CSRF endpoint's response holding the token
+	baseURLFor         multiFlag  // Base URLs of operations by path prefix or tag, as "/admin=URL" or "tag:admin=URL"
+	mountBaseURLs      multiFlag  // Base URLs of --mount specs, as "/base=URL"
+	sandboxURL         string     // Base URL of the sandbox API some calls are routed to
+	sandboxOps         multiFlag  // operationIds always routed to the sandbox
+	sandboxPercent     float64    // Share of sessions routed to the sandbox, in percent
+	sandboxSession     string     // Incoming HTTP header routing a session to the sandbox if true
+	sandboxHeaders     multiFlag  // Headers of sandboxed requests, as "Name=Value"
+	specDriftURL       string     // URL of the API's own spec, compared with the loaded one
+	oauthLogin         string     // Interactive OAuth2 flow signing the user in: authorization_code or device_code
+	oauthAuthURL       string     // Authorization endpoint of the authorization code flow
+	oauthDeviceURL     string     // Device authorization endpoint of the device flow
+	oauthRedirectAddr  string     // Address of the redirect listener of the authorization code flow
+	oauthTokenFile     string     // File persisting the signed in user's token
+	stateDir           string     // Directory persisting sessions, raw results, scheduled calls, and audit records
+
+	timeout         time.Duration // Timeout of each tool call's upstream request
+	asyncWait       time.Duration // How long to poll the status URL of 202 Accepted responses
+	concurrencyWait time.Duration // How long calls wait for a slot of --max-concurrent
+	specDrift       time.Duration // How often the loaded spec is compared with the API's own
+
+	retryAttempts        int    // Attempts per call for transient failures; 0 or 1 disables retries
+	idempotencyKeyHeader string // Header sending a unique key with POST/PATCH calls, making them retryable
+
+	readOnlySwitch *openapi2mcp.ReadOnlySwitch // Shared by all servers and the admin endpoint; see readOnlySwitch
 }
 
 type mountFlag struct {
@@ -70,16 +173,112 @@ func parseFlags() *cliFlags {
 	flag.BoolVar(&flags.dryRun, "dry-run", false, "Print the generated MCP tool schemas and exit (do not start the server)")
 	flag.Var(&flags.tagFlags, "tag", "Only include tools with the given OpenAPI tag (repeatable)")
 	flag.StringVar(&flags.toolNameFormat, "tool-name-format", "", "Format tool names: lower, upper, snake, camel")
-	flag.BoolVar(&flags.summary, "summary", false, "Print a summary of the generated tools (count, tags, etc)")
+	flag.BoolVar(&flags.summary, "summary", false, "Print a JSON summary of the generated tools for CI; with --diff, exit 2 on breaking changes")
+	flag.BoolVar(&flags.tokens, "tokens", false, "Print the estimated tokens of the tool list, per tool and tag, as JSON; exit 2 if over --token-budget")
+	flag.IntVar(&flags.tokenBudget, "token-budget", 0, "Context budget the tool list should fit in (tokens); warn at startup if it doesn't")
 	flag.StringVar(&flags.diffFile, "diff", "", "Compare the generated output to a previous run (file path)")
 	flag.StringVar(&flags.docFile, "doc", "", "Write Markdown/HTML documentation for all tools to this file (implies no server)")
 	flag.StringVar(&flags.docFormat, "doc-format", "markdown", "Documentation format: markdown (default) or html")
 	flag.StringVar(&flags.postHookCmd, "post-hook-cmd", "", "Command to post-process the generated tool schema JSON (used in --dry-run or --doc mode)")
 	flag.BoolVar(&flags.noConfirmDangerous, "no-confirm-dangerous", false, "Disable confirmation prompt for dangerous (PUT/POST/DELETE) actions in tool descriptions")
 	flag.Var(&flags.mounts, "mount", "Mount an OpenAPI spec at a base path: /base:path/to/spec.yaml (repeatable, can be used multiple times)")
+	flag.StringVar(&flags.httpAddr, "http", "", "Serve MCP over streamable HTTP on this address (e.g. :8080) instead of stdio")
+	flag.BoolVar(&flags.warmMounts, "warm-mounts", false, "Load all --mount specs at startup instead of on the first request to their base path")
+	flag.BoolVar(&flags.prefixTools, "prefix-tools", false, "Prefix tool names with the --mount base path or the spec's file name, e.g. github_listRepos, to keep them unique across specs")
 	flag.StringVar(&flags.functionListFile, "function-list-file", "", "File with list of function (operationId) names to include (one per line, for filter command)")
 	flag.StringVar(&flags.logFile, "log-file", "", "File path to log all MCP requests and responses for debugging")
 	flag.BoolVar(&flags.noLogTruncation, "no-log-truncation", false, "Disable truncation of long values in human-readable MCP logs")
+	flag.IntVar(&flags.logMaxSizeMB, "log-max-size", 10, "Rotate the --log-file once it exceeds this size in MB (0 disables rotation)")
+	flag.IntVar(&flags.logMaxBackups, "log-max-backups", 3, "Number of rotated --log-file backups to keep")
+	flag.Var(&flags.redactHeaders, "redact-header", "Header name to redact in logs, in addition to Authorization/Cookie (repeatable)")
+	flag.StringVar(&flags.requestIDHeader, "request-id-header", "", "Send each tool call's correlation ID upstream in this header (e.g. X-Request-ID)")
+	flag.BoolVar(&flags.telemetry, "telemetry", false, "Append a latency/outcome line to each tool result and expose the server_stats tool")
+	flag.BoolVar(&flags.mock, "mock", false, "Never call the real API: synthesize responses from the spec's response examples and schemas")
+	flag.StringVar(&flags.recordFile, "record", "", "Record upstream request/response pairs to this cassette file (JSON)")
+	flag.StringVar(&flags.replayFile, "replay", "", "Never call the real API: serve responses recorded with --record from this cassette file")
+	flag.BoolVar(&flags.live, "live", false, "Let validate also call safe GET operations against the real API with example arguments")
+	flag.Var(&flags.liveOps, "live-op", "operationId of a GET operation called by validate --live (repeatable, default: up to 5 GET operations)")
+	flag.StringVar(&flags.apiKeyHeader, "api-key-header", "", "Header sending API_KEY to operations without a matching security scheme (overrides API_KEY_HEADER env)")
+	flag.Var(&flags.responseHeaders, "response-header", "Response header to include in results, e.g. Location or X-RateLimit-*; prefix with operationId: for one operation (repeatable)")
+	flag.StringVar(&flags.policyFile, "policy", "", "YAML/JSON file with rules allowing or denying tool calls by operation, method, path, arguments, or session")
+	flag.StringVar(&flags.argumentRulesFile, "argument-rules", "", "YAML/JSON file with rules constraining argument values, e.g. environment must be staging, enforced before calls reach the API")
+	flag.Var(&flags.forwardHeaders, "forward-header", "Header of incoming MCP HTTP requests to forward to the API, e.g. Authorization (repeatable, --http only)")
+	flag.Var(&flags.allowHosts, "allow-host", "Additional host tool calls may send requests to, e.g. api.example.com or *.example.com (repeatable)")
+	flag.BoolVar(&flags.allowPrivateNets, "allow-private-networks", false, "Allow requests to private, loopback, and link-local addresses (disables SSRF address checks)")
+	flag.DurationVar(&flags.timeout, "timeout", 0, "Timeout of each tool call's API request, e.g. 30s (default: none)")
+	flag.DurationVar(&flags.asyncWait, "async-wait", 0, "Poll the status URL of 202 Accepted responses for up to this long, e.g. 1m (default: return them as pending)")
+	flag.Var(&flags.operationTimeouts, "operation-timeout", "Timeout of one operation's API request as operationId:duration, e.g. createReport:5m (repeatable)")
+	flag.Var(&flags.maxConcurrent, "max-concurrent", "Calls of an operation, or of the operations with a tag, running at once: operationId:n or tag:name:n, e.g. deployRelease:1 (repeatable)")
+	flag.Var(&flags.operationCosts, "operation-cost", "Cost or blast radius of an operation as operationId:level[:reason], level low, medium, high, or critical, e.g. chargeCard:high (repeatable, overrides x-mcp-cost)")
+	flag.StringVar(&flags.confirmCost, "confirm-cost", "", "Require confirmation before calls of operations costing this level or more: low, medium, high, or critical")
+	flag.DurationVar(&flags.concurrencyWait, "concurrency-wait", 0, "Let calls beyond --max-concurrent wait this long for a slot, e.g. 2m (default: reject them as busy at once)")
+	flag.IntVar(&flags.retryAttempts, "retry", 0, "Attempts per tool call when the API fails transiently (connection errors, 408, 425, 429, 500, 502-504); idempotent methods only (default: no retries)")
+	flag.StringVar(&flags.idempotencyKeyHeader, "idempotency-key-header", "", "Header sending a unique key with POST and PATCH calls, e.g. Idempotency-Key, so that --retry also retries them")
+	flag.IntVar(&flags.maxRedirects, "max-redirects", 0, "Maximum number of redirects followed per tool call (default: 10)")
+	flag.BoolVar(&flags.noFollowRedirects, "no-follow-redirects", false, "Don't follow redirects: return the 3xx status and its Location to the model")
+	flag.StringVar(&flags.baseURL, "base-url", "", "Base URL of the API, overriding OPENAPI_BASE_URL and the spec's servers")
+	flag.Var(&flags.baseURLFor, "base-url-for", "Base URL of the operations below a path prefix or with a tag: /admin=URL or tag:admin=URL (repeatable, first match wins)")
+	flag.Var(&flags.mountBaseURLs, "mount-base-url", "Base URL of the spec mounted at a base path: /base=URL (repeatable, overrides --base-url)")
+	flag.StringVar(&flags.sandboxURL, "sandbox-url", "", "Base URL of a sandbox API, e.g. a test mode, that the calls selected by the other --sandbox-* flags go to")
+	flag.Var(&flags.sandboxOps, "sandbox-op", "operationId (glob) whose calls always go to --sandbox-url (repeatable)")
+	flag.Float64Var(&flags.sandboxPercent, "sandbox-percent", 0, "Percentage of sessions whose calls go to --sandbox-url, as a canary")
+	flag.StringVar(&flags.sandboxSession, "sandbox-session-header", "", "Incoming HTTP header sending a session's calls to --sandbox-url if true, or to production if false")
+	flag.Var(&flags.sandboxHeaders, "sandbox-header", "Header of requests sent to --sandbox-url, e.g. Authorization=Bearer sk_test_... (repeatable)")
+	flag.DurationVar(&flags.specDrift, "spec-drift", 0, "Compare the spec at startup and then this often with the one the API serves, e.g. 1h, and warn when they diverge")
+	flag.StringVar(&flags.oauthLogin, "oauth-login", "", "Sign the user in for oauth2 operations with the authorization_code or device_code flow, using CLIENT_ID and CLIENT_SECRET (optional)")
+	flag.StringVar(&flags.oauthAuthURL, "oauth-authorization-url", "", "Authorization endpoint of --oauth-login=authorization_code (default: the spec's authorizationUrl)")
+	flag.StringVar(&flags.oauthDeviceURL, "oauth-device-url", "", "Device authorization endpoint of --oauth-login=device_code, e.g. https://auth.example.com/oauth/device/code")
+	flag.StringVar(&flags.oauthRedirectAddr, "oauth-redirect-addr", "", "Address of the local redirect listener of --oauth-login=authorization_code (default: 127.0.0.1:8085, redirect URI http://127.0.0.1:8085/callback)")
+	flag.StringVar(&flags.oauthTokenFile, "oauth-token-file", "", "File keeping the token of --oauth-login across restarts, e.g. oauth-token.json (written with mode 0600)")
+	flag.StringVar(&flags.stateDir, "state-dir", "", "Directory keeping session cookies, raw results, scheduled calls, and audit records across restarts; share it between replicas")
+	flag.StringVar(&flags.specDriftURL, "spec-drift-url", "", "URL of the API's own spec, or path resolved against the base URL, compared by --spec-drift (default: /openapi.json)")
+	flag.Var(&flags.contractOps, "contract-op", "operationId called by the contract command, of any method (repeatable, default: all GET operations)")
+	flag.StringVar(&flags.callbackAddr, "callback-addr", "", "Receive OpenAPI callbacks on this address (e.g. :9090) and add the await_callback tool")
+	flag.StringVar(&flags.callbackURL, "callback-url", "", "Public base URL of the callback receiver as reachable by the API (default: http://localhost<callback-addr>)")
+	flag.StringVar(&flags.codegenOutput, "o", "", "Output directory of the codegen command")
+	flag.StringVar(&flags.codegenOutput, "output", "", "Output directory of the codegen command")
+	flag.StringVar(&flags.codegenModule, "module", "", "Module path of the module generated by the codegen command (default: derived from the API title)")
+	flag.StringVar(&flags.manifestURL, "manifest-url", "", "Public URL of the streamable HTTP endpoint listed by the manifest command")
+	flag.IntVar(&flags.benchCalls, "bench-calls", 1000, "Number of synthetic tool calls made by the bench command")
+	flag.IntVar(&flags.benchConcurrency, "bench-concurrency", 0, "Number of concurrent client sessions used by the bench command (default: number of CPUs)")
+	flag.IntVar(&flags.maxResponseSizeMB, "max-response-size", 0, "Truncate upstream response bodies larger than this size in MB (default: 128)")
+	flag.IntVar(&flags.maxRequestSizeMB, "max-request-size", 0, "Reject tool calls whose serialized request body is larger than this size in MB (default: 10)")
+	flag.IntVar(&flags.maxArgumentSizeKB, "max-argument-size", 0, "Reject tool calls with an argument other than requestBody larger than this size in KB (default: 64)")
+	flag.StringVar(&flags.uploadDir, "upload-dir", "", "Let binary uploads (e.g. application/octet-stream bodies) send files from this directory via requestBodyFile")
+	flag.IntVar(&flags.maxUploadSizeMB, "max-upload-size", 0, "Reject requestBodyFile uploads larger than this size in MB (default: 1024)")
+	flag.BoolVar(&flags.graphQL, "graphql", false, "Give POST /graphql operations query, operationName, and variables arguments and list GraphQL errors readably")
+	flag.Var(&flags.codeSamples, "code-sample", "Show the operations' x-codeSamples in this language (e.g. curl) in tool descriptions (repeatable)")
+	flag.StringVar(&flags.timeZone, "timezone", "", "IANA time zone of the timestamp://current resource and the convert_time tool, e.g. Europe/Berlin (default: local)")
+	flag.BoolVar(&flags.relativeDates, "relative-dates", false, "Let date parameters take relative values such as yesterday, -7d, or last_month_start, resolved in --timezone")
+	flag.BoolVar(&flags.coerceArgs, "coerce-args", false, "Convert stringly-typed arguments before validation, e.g. \"123\" to 123 for integer and \"true\" to true for boolean parameters, and trim whitespace")
+	flag.BoolVar(&flags.autoIfMatch, "auto-if-match", false, "Fetch the current ETag or version for PUT/PATCH calls documenting If-Match or a version field when the agent leaves it out")
+	flag.BoolVar(&flags.sessionCookies, "session-cookies", false, "Keep the cookies the API sets per MCP session and send them with the session's later calls")
+	flag.BoolVar(&flags.coalesceRequests, "coalesce-requests", false, "Let identical GET calls of an MCP session share the upstream request of one still in flight")
+	flag.BoolVar(&flags.scheduleCalls, "schedule-calls", false, "Add schedule_call, list_scheduled, and cancel_scheduled tools queuing tool calls to run after a delay or at a time")
+	flag.BoolVar(&flags.callGroups, "call-groups", false, "Add begin_group, commit_group, and abort_group tools recording state-changing calls and suggesting how to undo them on abort")
+	flag.BoolVar(&flags.groupRollback, "group-rollback", false, "Let abort_group execute the calls undoing a group's calls (implies --call-groups)")
+	flag.StringVar(&flags.workflowsFile, "workflows", "", "YAML/JSON file with workflows, each served as one tool calling several operations in order and passing data between them")
+	flag.BoolVar(&flags.readOnly, "read-only", false, "Block calls of all operations other than GET, HEAD, OPTIONS, and TRACE with a read_only error (toggle at runtime via --admin-addr)")
+	flag.StringVar(&flags.adminAddr, "admin-addr", "", "Serve the admin endpoint /read-only on this address, e.g. 127.0.0.1:9091: GET shows read-only mode, POST ?enabled=true|false toggles it")
+	flag.Var(&flags.arazzoFiles, "arazzo", "Arazzo document describing workflows of the spec's operations, each served as one tool (repeatable)")
+	flag.StringVar(&flags.csrfHeader, "csrf-header", "", "Header sending a CSRF token with POST, PUT, PATCH, and DELETE calls, e.g. X-CSRF-Token (overrides the spec's x-mcp-csrf)")
+	flag.StringVar(&flags.csrfCookie, "csrf-cookie", "", "Cookie the CSRF token is read from, e.g. csrftoken (with --csrf-header)")
+	flag.StringVar(&flags.csrfEndpoint, "csrf-endpoint", "", "Path below the base URL, or URL, answering a GET with the CSRF token (with --csrf-header)")
+	flag.StringVar(&flags.csrfField, "csrf-field", "", "JSON field of the --csrf-endpoint response holding the token (default: its --csrf-header response header)")
+	flag.Var(&flags.responseLimits, "response-limit", "Show at most this many KB of response bodies over a transport, e.g. stdio:256 or http:1024; the rest stays available as a resource (repeatable)")
+	flag.BoolVar(&flags.guardResponses, "guard-responses", false, "Wrap response bodies in delimited data blocks and flag text that looks like instructions to the model")
+	flag.BoolVar(&flags.stripHTML, "strip-html", false, "Strip scripts, styles, comments, and tags from HTML and JSON responses (implies --guard-responses)")
+	flag.BoolVar(&flags.htmlToMarkdown, "html-to-markdown", false, "Show HTML responses as Markdown of their readable content (raw HTML stays available as a resource)")
+	flag.BoolVar(&flags.noRateLimitPacing, "no-rate-limit-pacing", false, "Don't delay calls when an API host's rate limit is nearly used up")
+	flag.Float64Var(&flags.rateLimitWarning, "rate-limit-warning", 0, "Warn in results when an API host's remaining rate limit quota is at or below this percentage of its limit, e.g. 10")
+	flag.StringVar(&flags.reproCommand, "repro-command", "", "Append an equivalent command of each request to results: curl or httpie (credentials as env variables)")
+	flag.StringVar(&flags.overridesFile, "description-overrides", "", "YAML/JSON file replacing tool titles, descriptions, argument descriptions, and examples by operationId")
+	flag.StringVar(&flags.presetsFile, "presets", "", "YAML/JSON file with named argument presets by operationId, selected with the __preset argument")
+	flag.IntVar(&flags.maxDescription, "max-description-length", 0, "Shorten tool descriptions longer than this many bytes; the complete ones stay available via describe_tool")
+	flag.StringVar(&flags.trimConfigFile, "trim-config", "", "YAML/JSON file with per-operation response trimming rules (drop paths, max array/string lengths)")
+	flag.BoolVar(&flags.compactSchemas, "compact-schemas", false, "Emit component schemas used more than once within a tool once and reference them via $ref")
+	flag.StringVar(&flags.clientProfile, "client-profile", "", "Fit tool names, schemas, descriptions, and confirmations to a client: claude, cursor, or generic")
+	flag.Var(&flags.redactJSONPaths, "redact-json-path", "JSON body path to redact in logs, e.g. $.card.number (repeatable)")
 	flag.Parse()
 	flags.args = flag.Args()
 	if flags.extended {
@@ -97,18 +296,40 @@ Usage:
   openapi-mcp [flags] filter <openapi-spec-path>
   openapi-mcp [flags] validate <openapi-spec-path>
   openapi-mcp [flags] lint <openapi-spec-path>
-  openapi-mcp [flags] <openapi-spec-path>
+  openapi-mcp [flags] bench <openapi-spec-path>
+  openapi-mcp [flags] contract <openapi-spec-path>
+  openapi-mcp [flags] compat <openapi-spec-path>
+  openapi-mcp [flags] doctor <openapi-spec-path>
+  openapi-mcp [flags] codegen <openapi-spec-path> -o <output-dir>
+  openapi-mcp [flags] manifest <openapi-spec-path>
+  openapi-mcp [flags] <openapi-spec-path>       Serve the API as MCP tools over stdio (or HTTP with --http)
+  openapi-mcp --http=:8080 --mount /base:spec.yaml ...  Serve several specs over HTTP at base paths
 
 Commands:
   filter <openapi-spec-path>    Output a filtered list of operations as JSON, applying --tag, --include-desc-regex, --exclude-desc-regex, and --function-list-file (no server)
   validate <openapi-spec-path>  Validate the OpenAPI spec and report actionable errors (with --http: starts validation API server)
   lint <openapi-spec-path>      Perform detailed OpenAPI linting with comprehensive suggestions (with --http: starts linting API server)
+  bench <openapi-spec-path>     Replay synthetic tool calls against an in-process server with a stub upstream and report throughput, latency, and allocations
+  contract <openapi-spec-path>  Call operations against the live API and report responses that drift from the documented status codes and schemas
+  compat <openapi-spec-path>    List the features of each operation that openapi-mcp cannot fully handle: media types, parameter styles, oneOf bodies, callbacks, and security schemes
+  doctor <openapi-spec-path>    Check for misconfigurations before serving: $ref problems, self-test errors, missing auth env vars, unreachable servers, and clock skew
+  manifest <openapi-spec-path>  Print a server manifest for MCP registries: name, version, transports, auth requirements, and tools (JSON)
+  codegen <openapi-spec-path>   Generate a standalone Go module serving the spec's tools, compiled in without runtime spec parsing (-o, --module)
 
 Examples:
 
   Validation & Linting:
     openapi-mcp validate api.yaml                 # Check for critical issues
     openapi-mcp lint api.yaml                     # Comprehensive linting
+    openapi-mcp --base-url=https://staging.example.com contract api.yaml  # Report spec drift of the live API
+    openapi-mcp compat api.yaml                   # What openapi-mcp can't fully handle
+    openapi-mcp doctor api.yaml                   # Preflight checks with fixes
+
+  Publishing:
+    openapi-mcp --manifest-url=https://mcp.example.com/petstore manifest api.yaml > server.json  # Registry manifest
+
+  Code Generation:
+    openapi-mcp codegen api.yaml -o ./petstore-mcp  # Generate a Go module with the tools compiled in
 
   Filtering & Documentation:
     openapi-mcp filter --tag=admin api.yaml              # Only admin operations
@@ -121,6 +342,16 @@ Examples:
   Advanced Configuration:
     openapi-mcp --include-desc-regex="user.*" api.yaml      # Filter by description
     openapi-mcp --no-confirm-dangerous api.yaml             # Skip confirmations
+    openapi-mcp --trim-config=trim.yaml api.yaml            # Prune noisy response fields
+    openapi-mcp --allow-host=uploads.example.com api.yaml   # Allow requests to another host
+    openapi-mcp --base-url-for=/admin=http://admin.internal api.yaml # Send /admin paths elsewhere
+    openapi-mcp --policy=policy.yaml api.yaml               # Authorize tool calls
+    openapi-mcp --no-follow-redirects api.yaml              # Return redirects to the model
+    openapi-mcp --timeout=30s --operation-timeout=createReport:5m api.yaml # Limit call durations
+    openapi-mcp --retry=3 --idempotency-key-header=Idempotency-Key api.yaml # Retry transient failures
+    openapi-mcp --response-header=Location --response-header='X-RateLimit-*' api.yaml # Show response headers
+    openapi-mcp --http=:8080 --forward-header=Authorization api.yaml # Per-client credentials
+    openapi-mcp --repro-command=curl api.yaml               # Show each request as a curl command
 
 Flags:
   --extended           Enable extended (human-friendly) output (default: minimal/agent)
@@ -131,13 +362,108 @@ Flags:
   --doc-format         Documentation format: markdown (default) or html
   --post-hook-cmd      Command to post-process the generated tool schema JSON
   --no-confirm-dangerous Disable confirmation for dangerous actions
-  --summary            Print a summary for CI
+  --summary            Print a JSON summary of the tools for CI (exit 2 on breaking changes with --diff)
+  --tokens             Print the estimated tokens of the tool list, per tool and tag, as JSON (exit 2 if over --token-budget)
+  --token-budget       Context budget the tool list should fit in (tokens); warn at startup if it doesn't
   --tag                Only include tools with the given tag
-  --diff               Compare generated tools with a reference file
-  --mount /base:path/to/spec.yaml  Mount an OpenAPI spec at a base path (repeatable, can be used multiple times)
+  --diff               Compare generated tools with a previous --dry-run output
+  --http               Serve MCP over streamable HTTP on this address (e.g. :8080) instead of stdio
+  --mount /base:path/to/spec.yaml  Mount an OpenAPI spec at a base path (repeatable, requires --http); specs load on first request
+  --warm-mounts        Load all --mount specs at startup instead of on first request
+  --prefix-tools       Prefix tool names with the --mount base path or the spec's file name, e.g. github_listRepos
   --function-list-file   File with list of function (operationId) names to include (one per line, for filter command)
-  --log-file           File path to log all MCP requests and responses for debugging
+  --log-file           File path to log all MCP requests and responses (and upstream HTTP traffic) as JSONL
+  --log-max-size       Rotate the log file once it exceeds this size in MB (default: 10, 0 disables rotation)
+  --log-max-backups    Number of rotated log files to keep (default: 3)
   --no-log-truncation  Disable truncation of long values in human-readable MCP logs
+  --redact-header      Header name to redact in logs, in addition to Authorization/Cookie (repeatable)
+  --redact-json-path   JSON body path to redact in logs, e.g. $.card.number (repeatable)
+  --telemetry          Append a latency/outcome line to each tool result and expose the server_stats tool
+  --request-id-header  Send each tool call's correlation ID upstream in this header (e.g. X-Request-ID)
+  --mock               Never call the real API: synthesize responses from the spec's response examples and schemas
+  --record             Record upstream request/response pairs to this cassette file (JSON)
+  --replay             Never call the real API: serve responses recorded with --record from this cassette file
+  --live               Let validate also call safe GET operations against the real API with example arguments
+  --live-op            operationId of a GET operation called by validate --live (repeatable, default: up to 5 GET operations)
+  --api-key-header     Header sending API_KEY to operations without a matching security scheme (overrides API_KEY_HEADER env)
+  --response-header    Response header to include in results, e.g. Location or X-RateLimit-*; prefix with operationId: for one operation (repeatable)
+  --policy             YAML/JSON file with rules allowing or denying tool calls by operation, method, path, arguments, or session
+  --argument-rules     YAML/JSON file with rules constraining argument values, e.g. environment must be staging, enforced before calls reach the API
+  --forward-header     Header of incoming MCP HTTP requests to forward to the API, e.g. Authorization (repeatable, --http only)
+  --allow-host         Additional host tool calls may send requests to, e.g. api.example.com or *.example.com (repeatable)
+  --allow-private-networks Allow requests to private, loopback, and link-local addresses (disables SSRF address checks)
+  --timeout            Timeout of each tool call's API request, e.g. 30s (default: none)
+  --operation-timeout  Timeout of one operation's API request as operationId:duration, e.g. createReport:5m (repeatable)
+  --max-concurrent     Calls of an operation, or of the operations with a tag, running at once: operationId:n or tag:name:n, e.g. deployRelease:1 (repeatable)
+  --operation-cost     Cost or blast radius of an operation as operationId:level[:reason], level low, medium, high, or critical, e.g. chargeCard:high (repeatable, overrides x-mcp-cost)
+  --confirm-cost       Require confirmation before calls of operations costing this level or more: low, medium, high, or critical
+  --concurrency-wait   Let calls beyond --max-concurrent wait this long for a slot, e.g. 2m (default: reject them as busy at once)
+  --retry              Attempts per tool call when the API fails transiently (connection errors, 408, 425, 429, 500, 502-504); idempotent methods only (default: no retries)
+  --idempotency-key-header Header sending a unique key with POST and PATCH calls, e.g. Idempotency-Key, so that --retry also retries them
+  --async-wait         Poll the status URL of 202 Accepted responses for up to this long, e.g. 1m (default: return them as pending)
+  --max-redirects      Maximum number of redirects followed per tool call (default: 10)
+  --no-follow-redirects Don't follow redirects: return the 3xx status and its Location to the model
+  --base-url           Base URL of the API, overriding OPENAPI_BASE_URL and the spec's servers
+  --base-url-for       Base URL of the operations below a path prefix or with a tag: /admin=URL or tag:admin=URL (repeatable, first match wins)
+  --mount-base-url     Base URL of the spec mounted at a base path: /base=URL (repeatable, overrides --base-url)
+  --sandbox-url        Base URL of a sandbox API, e.g. a test mode, that the calls selected by the other --sandbox-* flags go to
+  --sandbox-op         operationId (glob) whose calls always go to --sandbox-url (repeatable)
+  --sandbox-percent    Percentage of sessions whose calls go to --sandbox-url, as a canary
+  --sandbox-session-header Incoming HTTP header sending a session's calls to --sandbox-url if true, or to production if false
+  --sandbox-header     Header of requests sent to --sandbox-url, e.g. Authorization=Bearer sk_test_... (repeatable)
+  --spec-drift         Compare the spec at startup and then this often with the one the API serves, e.g. 1h, and warn when they diverge
+  --oauth-login        Sign the user in for oauth2 operations with the authorization_code or device_code flow, using CLIENT_ID and CLIENT_SECRET (optional)
+  --oauth-authorization-url Authorization endpoint of --oauth-login=authorization_code (default: the spec's authorizationUrl)
+  --oauth-device-url   Device authorization endpoint of --oauth-login=device_code, e.g. https://auth.example.com/oauth/device/code
+  --oauth-redirect-addr Address of the local redirect listener of --oauth-login=authorization_code (default: 127.0.0.1:8085, redirect URI http://127.0.0.1:8085/callback)
+  --oauth-token-file   File keeping the token of --oauth-login across restarts, e.g. oauth-token.json (written with mode 0600)
+  --state-dir          Directory keeping session cookies, raw results, scheduled calls, and audit records across restarts; share it between replicas
+  --spec-drift-url     URL of the API's own spec, or path resolved against the base URL, compared by --spec-drift (default: /openapi.json)
+  --contract-op        operationId called by the contract command, of any method (repeatable, default: all GET operations)
+  --callback-addr      Receive OpenAPI callbacks on this address (e.g. :9090) and add the await_callback tool
+  --callback-url       Public base URL of the callback receiver as reachable by the API (default: http://localhost<callback-addr>)
+  --manifest-url       Public URL of the streamable HTTP endpoint listed by the manifest command
+  -o, --output         Output directory of the codegen command
+  --module             Module path of the module generated by the codegen command (default: derived from the API title)
+  --bench-calls        Number of synthetic tool calls made by the bench command (default: 1000)
+  --bench-concurrency  Number of concurrent client sessions used by the bench command (default: number of CPUs)
+  --max-response-size  Truncate upstream response bodies larger than this size in MB (default: 128)
+  --max-request-size   Reject tool calls whose serialized request body is larger than this size in MB (default: 10)
+  --max-argument-size  Reject tool calls with an argument other than requestBody larger than this size in KB (default: 64)
+  --upload-dir         Let binary uploads (e.g. application/octet-stream bodies) send files from this directory via requestBodyFile
+  --max-upload-size    Reject requestBodyFile uploads larger than this size in MB (default: 1024)
+  --graphql            Give POST /graphql operations query, operationName, and variables arguments and list GraphQL errors readably
+  --code-sample        Show the operations' x-codeSamples in this language (e.g. curl) in tool descriptions (repeatable)
+  --timezone           IANA time zone of the timestamp://current resource and the convert_time tool, e.g. Europe/Berlin (default: local)
+  --relative-dates     Let date parameters take relative values such as yesterday, -7d, or last_month_start, resolved in --timezone
+  --coerce-args        Convert stringly-typed arguments before validation, e.g. "123" to 123 for integer and "true" to true for boolean parameters, and trim whitespace
+  --auto-if-match      Fetch the current ETag or version for PUT/PATCH calls documenting If-Match or a version field when the agent leaves it out
+  --session-cookies    Keep the cookies the API sets per MCP session and send them with the session's later calls
+  --coalesce-requests  Let identical GET calls of an MCP session share the upstream request of one still in flight
+  --schedule-calls     Add schedule_call, list_scheduled, and cancel_scheduled tools queuing tool calls to run after a delay or at a time
+  --call-groups        Add begin_group, commit_group, and abort_group tools recording state-changing calls and suggesting how to undo them on abort
+  --group-rollback     Let abort_group execute the calls undoing a group's calls (implies --call-groups)
+  --workflows          YAML/JSON file with workflows, each served as one tool calling several operations in order and passing data between them
+  --read-only          Block calls of all operations other than GET, HEAD, OPTIONS, and TRACE with a read_only error (toggle at runtime via --admin-addr)
+  --admin-addr         Serve the admin endpoint /read-only on this address, e.g. 127.0.0.1:9091: GET shows read-only mode, POST ?enabled=true|false toggles it
+  --arazzo             Arazzo document describing workflows of the spec's operations, each served as one tool (repeatable)
+  --csrf-header        Header sending a CSRF token with POST, PUT, PATCH, and DELETE calls, e.g. X-CSRF-Token (overrides the spec's x-mcp-csrf)
+  --csrf-cookie        Cookie the CSRF token is read from, e.g. csrftoken (with --csrf-header)
+  --csrf-endpoint      Path below the base URL, or URL, answering a GET with the CSRF token (with --csrf-header)
+  --csrf-field         JSON field of the --csrf-endpoint response holding the token (default: its --csrf-header response header)
+  --response-limit     Show at most this many KB of response bodies over a transport, e.g. stdio:256 or http:1024; the rest stays available as a resource (repeatable)
+  --guard-responses    Wrap response bodies in delimited data blocks and flag text that looks like instructions to the model
+  --strip-html         Strip scripts, styles, comments, and tags from HTML and JSON responses (implies --guard-responses)
+  --html-to-markdown   Show HTML responses as Markdown of their readable content (raw HTML stays available as a resource)
+  --no-rate-limit-pacing Don't delay calls when an API host's rate limit is nearly used up
+  --rate-limit-warning Warn in results when an API host's remaining rate limit quota is at or below this percentage of its limit, e.g. 10
+  --repro-command      Append an equivalent command of each request to results: curl or httpie (credentials as env variables)
+  --description-overrides YAML/JSON file replacing tool titles, descriptions, argument descriptions, and examples by operationId
+  --presets            YAML/JSON file with named argument presets by operationId, selected with the __preset argument
+  --max-description-length Shorten tool descriptions longer than this many bytes; the complete ones stay available via describe_tool
+  --trim-config        YAML/JSON file with per-operation response trimming rules (drop paths, max array/string lengths)
+  --compact-schemas    Emit component schemas used more than once within a tool once and reference them via $ref
+  --client-profile     Fit tool names, schemas, descriptions, and confirmations to a client: claude, cursor, or generic
   --help, -h           Show help
 
 By default, output is minimal and agent-friendly. Use --extended for banners, help, and human-readable output.
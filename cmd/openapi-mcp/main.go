@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -136,6 +137,38 @@ func collectUsedSchemas(doc *openapi3.T) map[string]bool {
 	return used
 }
 
+// serverOperations extracts the operations to serve from doc, applying the INCLUDE_DESC_REGEX and
+// EXCLUDE_DESC_REGEX filters, sorted by tags, operationId, and path for a stable tool order.
+func serverOperations(doc *openapi3.T) ([]openapi2mcp.OpenAPIOperation, error) {
+	var includeRegex, excludeRegex *regexp.Regexp
+	var err error
+	if val := os.Getenv("INCLUDE_DESC_REGEX"); val != "" {
+		includeRegex, err = regexp.Compile(val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid INCLUDE_DESC_REGEX: %w", err)
+		}
+	}
+	if val := os.Getenv("EXCLUDE_DESC_REGEX"); val != "" {
+		excludeRegex, err = regexp.Compile(val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EXCLUDE_DESC_REGEX: %w", err)
+		}
+	}
+
+	ops := openapi2mcp.ExtractFilteredOpenAPIOperations(doc, includeRegex, excludeRegex)
+
+	slices.SortStableFunc(ops, func(a, b openapi2mcp.OpenAPIOperation) int {
+		if tags := slices.Compare(a.Tags, b.Tags); tags != 0 {
+			return tags
+		}
+		if op := strings.Compare(a.OperationID, b.OperationID); op != 0 {
+			return op
+		}
+		return strings.Compare(a.Path, b.Path)
+	})
+	return ops, nil
+}
+
 // main is the entrypoint for the openapi-mcp CLI.
 // It parses flags, loads the OpenAPI spec, and dispatches to the appropriate mode (server, doc, dry-run, etc).
 func main() {
@@ -148,6 +181,15 @@ func main() {
 
 	args := flags.args
 
+	// Mounts serve their own specs, so no <openapi-spec-path> argument is needed
+	if len(flags.mounts) > 0 && len(args) == 0 {
+		if flags.docFile != "" {
+			handleMountDocMode(flags)
+		}
+		handleMountMode(flags)
+		return
+	}
+
 	if len(args) < 1 {
 		fmt.Fprintln(os.Stderr, "Error: missing required <openapi-spec-path> argument.")
 		printHelp()
@@ -194,6 +236,21 @@ func main() {
 			os.Exit(1)
 		}
 		fmt.Fprintln(os.Stderr, "MCP self-test passed: all tools and required arguments are present.")
+		if flags.live {
+			ops, err := serverOperations(doc)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			err = openapi2mcp.SelfTestOpenAPIMCPLive(context.Background(), doc, ops, &openapi2mcp.LiveSelfTestOptions{
+				Operations:     flags.liveOps,
+				ToolGenOptions: toolGenOptions(flags, doc),
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "MCP live self-test failed: %v\n", err)
+				os.Exit(1)
+			}
+		}
 		os.Exit(0)
 	}
 	// --- End validate subcommand ---
@@ -227,6 +284,72 @@ func main() {
 	}
 	// --- End lint subcommand ---
 
+	// --- Bench subcommand ---
+	if args[0] == "bench" {
+		if len(args) < 2 {
+			fmt.Fprintln(os.Stderr, "Error: missing required <openapi-spec-path> argument for bench.")
+			os.Exit(1)
+		}
+		handleBenchCommand(flags, args[1])
+		os.Exit(0)
+	}
+	// --- End bench subcommand ---
+
+	// --- Contract subcommand ---
+	if args[0] == "contract" {
+		if len(args) < 2 {
+			fmt.Fprintln(os.Stderr, "Error: missing required <openapi-spec-path> argument for contract.")
+			os.Exit(1)
+		}
+		handleContractCommand(flags, args[1])
+		os.Exit(0)
+	}
+	// --- End contract subcommand ---
+
+	// --- Compat subcommand ---
+	if args[0] == "compat" {
+		if len(args) < 2 {
+			fmt.Fprintln(os.Stderr, "Error: missing required <openapi-spec-path> argument for compat.")
+			os.Exit(1)
+		}
+		handleCompatCommand(args[1])
+		os.Exit(0)
+	}
+	// --- End compat subcommand ---
+
+	// --- Doctor subcommand ---
+	if args[0] == "doctor" {
+		if len(args) < 2 {
+			fmt.Fprintln(os.Stderr, "Error: missing required <openapi-spec-path> argument for doctor.")
+			os.Exit(1)
+		}
+		handleDoctorCommand(flags, args[1])
+		os.Exit(0)
+	}
+	// --- End doctor subcommand ---
+
+	// --- Manifest subcommand ---
+	if args[0] == "manifest" {
+		if len(args) < 2 {
+			fmt.Fprintln(os.Stderr, "Error: missing required <openapi-spec-path> argument for manifest.")
+			os.Exit(1)
+		}
+		handleManifestCommand(flags, args[1])
+		os.Exit(0)
+	}
+	// --- End manifest subcommand ---
+
+	// --- Codegen subcommand ---
+	if args[0] == "codegen" {
+		if len(args) < 2 {
+			fmt.Fprintln(os.Stderr, "Error: missing required <openapi-spec-path> argument for codegen.")
+			os.Exit(1)
+		}
+		handleCodegenCommand(flags, args[1:])
+		os.Exit(0)
+	}
+	// --- End codegen subcommand ---
+
 	// --- Filter subcommand ---
 	if args[0] == "filter" {
 		if len(args) < 2 {
@@ -259,27 +382,7 @@ func main() {
 
 		ops := openapi2mcp.ExtractFilteredOpenAPIOperations(doc, includeRegex, excludeRegex)
 		// Apply tag filter if present
-		if len(flags.tagFlags) > 0 {
-			var filtered []openapi2mcp.OpenAPIOperation
-			for _, op := range ops {
-				found := false
-				for _, tag := range op.Tags {
-					for _, want := range flags.tagFlags {
-						if tag == want {
-							found = true
-							break
-						}
-					}
-					if found {
-						break
-					}
-				}
-				if found {
-					filtered = append(filtered, op)
-				}
-			}
-			ops = filtered
-		}
+		ops = ops.ByTag(flags.tagFlags...)
 		// Apply function list file filter if present
 		if flags.functionListFile != "" {
 			funcNames := make(map[string]struct{})
@@ -294,13 +397,10 @@ func main() {
 					funcNames[line] = struct{}{}
 				}
 			}
-			var filtered []openapi2mcp.OpenAPIOperation
-			for _, op := range ops {
-				if _, ok := funcNames[op.OperationID]; ok {
-					filtered = append(filtered, op)
-				}
-			}
-			ops = filtered
+			ops = ops.Filter(func(op openapi2mcp.OpenAPIOperation) bool {
+				_, ok := funcNames[op.OperationID]
+				return ok
+			})
 		}
 
 		// Patch doc.Paths to only include filtered operations
@@ -422,46 +522,33 @@ func main() {
 		os.Exit(1)
 	}
 	fmt.Fprintln(os.Stderr, "OpenAPI spec loaded and validated successfully.")
-
-	// Compile regex filters if provided
-	var includeRegex, excludeRegex *regexp.Regexp
-	if val := os.Getenv("INCLUDE_DESC_REGEX"); val != "" {
-		includeRegex, err = regexp.Compile(val)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: Invalid INCLUDE_DESC_REGEX: %v\n", err)
-			os.Exit(1)
-		}
-	}
-	if val := os.Getenv("EXCLUDE_DESC_REGEX"); val != "" {
-		excludeRegex, err = regexp.Compile(val)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: Invalid EXCLUDE_DESC_REGEX: %v\n", err)
-			os.Exit(1)
-		}
+	if flags.prefixTools {
+		flags.toolPrefix = toolNamePrefix(specPath)
 	}
 
-	ops := openapi2mcp.ExtractFilteredOpenAPIOperations(doc, includeRegex, excludeRegex)
-
-	slices.SortStableFunc(ops, func(a, b openapi2mcp.OpenAPIOperation) int {
-		if tags := slices.Compare(a.Tags, b.Tags); tags != 0 {
-			return tags
-		}
-		if op := strings.Compare(a.OperationID, b.OperationID); op != 0 {
-			return op
-		}
-		return strings.Compare(a.Path, b.Path)
-	})
+	ops, err := serverOperations(doc)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
 	// Dispatch to doc, dry-run, or server mode
 	if flags.docFile != "" {
 		handleDocMode(flags, ops, doc)
 		return
 	}
+	if flags.summary {
+		handleSummaryMode(flags, ops, doc)
+		return
+	}
+	if flags.tokens {
+		handleTokensMode(flags, ops, doc)
+		return
+	}
 	if flags.dryRun {
 		handleDryRunMode(flags, ops, doc)
 		return
 	}
 
-	fmt.Fprintln(os.Stderr, "Error: missing command")
-	os.Exit(1)
+	handleServerMode(flags, ops, doc)
 }
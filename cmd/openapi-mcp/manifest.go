@@ -0,0 +1,38 @@
+// manifest.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	openapi2mcp "github.com/evcc-io/openapi-mcp"
+)
+
+// handleManifestCommand prints the registry manifest of the server for the spec at specPath as JSON.
+// The tools are listed with the same options as in server mode.
+func handleManifestCommand(flags *cliFlags, specPath string) {
+	doc, err := openapi2mcp.LoadOpenAPISpec(specPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not load OpenAPI spec: %v\n", err)
+		os.Exit(1)
+	}
+	ops, err := serverOperations(doc)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	manifest, err := openapi2mcp.BuildServerManifest(context.Background(), doc, ops, &openapi2mcp.ManifestOptions{
+		Args:           []string{specPath},
+		HTTPURL:        flags.manifestURL,
+		ToolGenOptions: toolGenOptions(flags, doc),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not build manifest: %v\n", err)
+		os.Exit(1)
+	}
+	out, _ := json.MarshalIndent(manifest, "", "  ")
+	fmt.Println(string(out))
+}
@@ -0,0 +1,104 @@
+// mount.go
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	openapi2mcp "github.com/evcc-io/openapi-mcp"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// mount is an OpenAPI spec served at a base path. The spec is loaded and its tools are
+// registered on the first request to the base path (or at startup with --warm-mounts).
+type mount struct {
+	mountFlag
+
+	mu  sync.Mutex
+	srv *mcp.Server
+}
+
+// server returns the mount's MCP server, loading the spec on first use.
+// Failed loads are not cached, so a fixed spec file is picked up on the next request.
+func (m *mount) server(flags *cliFlags, logHandler slog.Handler) (*mcp.Server, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.srv != nil {
+		return m.srv, nil
+	}
+
+	doc, err := openapi2mcp.LoadOpenAPISpec(m.SpecPath)
+	if err != nil {
+		return nil, err
+	}
+	ops, err := serverOperations(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	// A --mount-base-url replaces --base-url for this spec
+	specFlags := *flags
+	if flags.prefixTools {
+		specFlags.toolPrefix = toolNamePrefix(m.BasePath)
+	}
+	for _, f := range flags.mountBaseURLs {
+		if basePath, baseURL, ok := strings.Cut(f, "="); ok && basePath == m.BasePath {
+			specFlags.baseURL = baseURL
+		}
+	}
+
+	m.srv = newToolServer(&specFlags, ops, doc, logHandler)
+	fmt.Fprintf(os.Stderr, "Mounted %s at %s (%d operations)\n", m.SpecPath, m.BasePath, len(ops))
+	return m.srv, nil
+}
+
+// handleMountMode serves every --mount spec over streamable HTTP at its base path.
+// Specs are loaded lazily on the first request to their base path unless --warm-mounts is set.
+func handleMountMode(flags *cliFlags) {
+	if flags.httpAddr == "" {
+		fmt.Fprintln(os.Stderr, "Error: --mount requires --http.")
+		os.Exit(1)
+	}
+
+	logHandler, closeLog := openLogHandler(flags)
+	defer closeLog()
+	serveAdmin(flags)
+
+	mux := http.NewServeMux()
+	for _, mf := range flags.mounts {
+		m := &mount{mountFlag: mf}
+
+		if flags.warmMounts {
+			if _, err := m.server(flags, logHandler); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: Could not load OpenAPI spec for mount %s: %v\n", m.BasePath, err)
+				os.Exit(1)
+			}
+		}
+
+		handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server {
+			srv, err := m.server(flags, logHandler)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: Could not load OpenAPI spec for mount %s: %v\n", m.BasePath, err)
+				return nil
+			}
+			return srv
+		}, nil)
+
+		basePath := "/" + strings.Trim(m.BasePath, "/")
+		mux.Handle(basePath, handler)
+		if basePath != "/" {
+			mux.Handle(basePath+"/", handler)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Serving %d mounts over HTTP on %s\n", len(flags.mounts), flags.httpAddr)
+	if err := http.ListenAndServe(flags.httpAddr, mux); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: HTTP server failed: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,649 @@
+// server.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	openapi2mcp "github.com/evcc-io/openapi-mcp"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// handleServerMode registers all tools and serves MCP over stdio until the client disconnects,
+// or over streamable HTTP if --http is set.
+func handleServerMode(flags *cliFlags, ops []openapi2mcp.OpenAPIOperation, doc *openapi3.T) {
+	logHandler, closeLog := openLogHandler(flags)
+	defer closeLog()
+	serveAdmin(flags)
+
+	srv := newToolServer(flags, ops, doc, logHandler)
+
+	if flags.httpAddr != "" {
+		handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return srv }, nil)
+		fmt.Fprintf(os.Stderr, "Serving MCP over HTTP on %s\n", flags.httpAddr)
+		if err := http.ListenAndServe(flags.httpAddr, handler); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: HTTP server failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := srv.Run(context.Background(), &mcp.StdioTransport{}); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: MCP server failed: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// readOnlySwitch returns the switch of --read-only, created on first use so that all servers and the admin endpoint
+// share it.
+func readOnlySwitch(flags *cliFlags) *openapi2mcp.ReadOnlySwitch {
+	if flags.readOnlySwitch == nil {
+		flags.readOnlySwitch = openapi2mcp.NewReadOnlySwitch(flags.readOnly)
+	}
+	return flags.readOnlySwitch
+}
+
+// serveAdmin serves the admin endpoint toggling read-only mode on --admin-addr in the background, if set.
+func serveAdmin(flags *cliFlags) {
+	readOnly := readOnlySwitch(flags)
+	if flags.adminAddr == "" {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/read-only", readOnly)
+	fmt.Fprintf(os.Stderr, "Serving the admin endpoint on %s\n", flags.adminAddr)
+	go func() {
+		if err := http.ListenAndServe(flags.adminAddr, mux); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Admin server failed: %v\n", err)
+			os.Exit(1)
+		}
+	}()
+}
+
+// newToolServer creates an MCP server for doc and registers ops as tools.
+// If logHandler is non-nil, MCP and upstream HTTP traffic is logged to it.
+func newToolServer(flags *cliFlags, ops []openapi2mcp.OpenAPIOperation, doc *openapi3.T, logHandler slog.Handler) *mcp.Server {
+	opts := toolGenOptions(flags, doc)
+
+	srv := mcp.NewServer(&mcp.Implementation{Name: doc.Info.Title, Version: doc.Info.Version}, nil)
+
+	if logHandler != nil {
+		opts.LogHandler = logHandler
+		opts.DisableLogTruncation = flags.noLogTruncation
+		srv.AddReceivingMiddleware(openapi2mcp.NewTrafficLogMiddleware(logHandler, opts))
+	}
+
+	openapi2mcp.RegisterOpenAPITools(srv, ops, doc, opts)
+	if flags.toolPrefix != "" {
+		printToolNames(ops, opts)
+	}
+	if flags.tokenBudget > 0 {
+		for _, warning := range openapi2mcp.EstimateToolTokens(ops, doc, opts, nil, flags.tokenBudget).Warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+		}
+	}
+	return srv
+}
+
+// printToolNames lists the tool name of each operation, as renamed by --prefix-tools and --tool-name-format.
+func printToolNames(ops []openapi2mcp.OpenAPIOperation, opts *openapi2mcp.ToolGenOptions) {
+	var sb strings.Builder
+	sb.WriteString("Tool names:\n")
+	for _, op := range openapi2mcp.Operations(ops).ByTag(opts.TagFilter...) {
+		fmt.Fprintf(&sb, "  %s → %s\n", op.OperationID, opts.NameFormat(op.OperationID))
+	}
+	fmt.Fprint(os.Stderr, sb.String())
+}
+
+// toolNamePrefix derives a tool name prefix from a mount's base path or a spec's file name,
+// e.g. "github_" from "/github" or "specs/github.yaml".
+func toolNamePrefix(id string) string {
+	id = strings.TrimSuffix(filepath.Base(id), filepath.Ext(id))
+	id = strings.Trim(strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, id), "_")
+	if id == "" {
+		return ""
+	}
+	return id + "_"
+}
+
+// toolGenOptions builds the tool generation options for serving doc from the CLI flags.
+func toolGenOptions(flags *cliFlags, doc *openapi3.T) *openapi2mcp.ToolGenOptions {
+	opts := &openapi2mcp.ToolGenOptions{
+		TagFilter:               flags.tagFlags,
+		Version:                 doc.Info.Version,
+		ConfirmDangerousActions: !flags.noConfirmDangerous,
+		RequestIDHeader:         flags.requestIDHeader,
+		Telemetry:               flags.telemetry,
+		CompactSchemas:          flags.compactSchemas,
+		MaxResponseBytes:        int64(flags.maxResponseSizeMB) << 20,
+		MaxRequestBodyBytes:     flags.maxRequestSizeMB << 20,
+		MaxArgumentBytes:        flags.maxArgumentSizeKB << 10,
+		Mock:                    flags.mock,
+		BaseURL:                 flags.baseURL,
+		APIKeyHeader:            flags.apiKeyHeader,
+		ForwardHeaders:          flags.forwardHeaders,
+		HTMLToMarkdown:          flags.htmlToMarkdown,
+		DisableRateLimitPacing:  flags.noRateLimitPacing,
+		RateLimitWarning:        flags.rateLimitWarning,
+		UploadRoot:              flags.uploadDir,
+		MaxUploadFileBytes:      int64(flags.maxUploadSizeMB) << 20,
+		GraphQL:                 flags.graphQL,
+		CodeSampleLangs:         flags.codeSamples,
+		RelativeDates:           flags.relativeDates,
+		CoerceArguments:         flags.coerceArgs,
+		AutoIfMatch:             flags.autoIfMatch,
+		SessionCookies:          flags.sessionCookies,
+		CoalesceRequests:        flags.coalesceRequests,
+		ScheduledCalls:          flags.scheduleCalls,
+		CallGroups:              flags.callGroups || flags.groupRollback,
+		GroupRollback:           flags.groupRollback,
+		HostPolicy: &openapi2mcp.HostPolicy{
+			AllowedHosts:         flags.allowHosts,
+			AllowPrivateNetworks: flags.allowPrivateNets,
+		},
+		Redirects: &openapi2mcp.RedirectPolicy{
+			Disabled:     flags.noFollowRedirects,
+			MaxRedirects: flags.maxRedirects,
+		},
+	}
+	if len(flags.redactHeaders) > 0 || len(flags.redactJSONPaths) > 0 {
+		opts.Redaction = &openapi2mcp.RedactionRules{
+			Headers:   flags.redactHeaders,
+			JSONPaths: flags.redactJSONPaths,
+		}
+	}
+	opts.Cassette, opts.RequestHandler = cassette(flags)
+	opts.CallbackReceiver = callbackReceiver(flags)
+	opts.ResponseTrimming = responseTrimming(flags)
+	opts.DescriptionOverrides = descriptionOverrides(flags, doc)
+	opts.Presets = presets(flags, doc)
+	opts.Workflows = workflows(flags, doc)
+	opts.MaxDescriptionLength = flags.maxDescription
+	if flags.guardResponses || flags.stripHTML {
+		opts.ContentGuard = &openapi2mcp.ContentGuard{StripHTML: flags.stripHTML}
+	}
+	opts.Policy = policy(flags)
+	opts.ArgumentRules = argumentRules(flags)
+	opts.ReadOnly = readOnlySwitch(flags)
+	opts.ResponseHeaders, opts.OperationResponseHeaders = responseHeaders(flags)
+	opts.Timeout, opts.OperationTimeouts = flags.timeout, operationTimeouts(flags)
+	opts.ConcurrencyLimits = concurrencyLimits(flags)
+	opts.OperationCosts, opts.ConfirmCost = operationCosts(flags), flags.confirmCost
+	opts.CSRF = csrf(flags)
+	if flags.retryAttempts > 1 {
+		opts.Retry = &openapi2mcp.RetryPolicy{MaxAttempts: flags.retryAttempts, IdempotencyKeyHeader: flags.idempotencyKeyHeader}
+	}
+	opts.BaseURLOverrides = baseURLOverrides(flags)
+	opts.Sandbox = sandbox(flags)
+	opts.SpecDrift = specDrift(flags)
+	opts.OAuthLogin = oauthLogin(flags)
+	opts.Store = stateStore(flags)
+	opts.ReproCommand = reproCommand(flags)
+	opts.ClientProfile = clientProfile(flags)
+	opts.TransportResponseLimits = transportResponseLimits(flags)
+	opts.TimeZone = timeZone(flags)
+	if flags.asyncWait > 0 {
+		opts.AsyncPolling = &openapi2mcp.AsyncPolling{MaxWait: flags.asyncWait}
+	}
+	if flags.toolNameFormat != "" || flags.toolPrefix != "" {
+		opts.NameFormat = func(name string) string {
+			return flags.toolPrefix + formatToolName(flags.toolNameFormat, name)
+		}
+	}
+	return opts
+}
+
+// cassette returns the recorder of --record or the request handler replaying --replay; both are nil if neither is
+// set. They are created once, so that all mounts share one cassette.
+func cassette(flags *cliFlags) (*openapi2mcp.CassetteRecorder, func(req *http.Request) (*http.Response, error)) {
+	cassetteOnce.Do(func() {
+		switch {
+		case flags.recordFile != "" && flags.replayFile != "":
+			fmt.Fprintln(os.Stderr, "Error: --record and --replay cannot be combined")
+			os.Exit(1)
+		case flags.mock && (flags.recordFile != "" || flags.replayFile != ""):
+			fmt.Fprintln(os.Stderr, "Error: --mock cannot be combined with --record or --replay")
+			os.Exit(1)
+		case flags.recordFile != "":
+			var rules *openapi2mcp.RedactionRules
+			if len(flags.redactHeaders) > 0 || len(flags.redactJSONPaths) > 0 {
+				rules = &openapi2mcp.RedactionRules{Headers: flags.redactHeaders, JSONPaths: flags.redactJSONPaths}
+			}
+			cassetteRecorder = openapi2mcp.NewCassetteRecorder(flags.recordFile, nil, rules)
+		case flags.replayFile != "":
+			replayer, err := openapi2mcp.LoadCassette(flags.replayFile)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: Could not load cassette: %v\n", err)
+				os.Exit(1)
+			}
+			cassetteReplay = replayer.RequestHandler
+		}
+	})
+	return cassetteRecorder, cassetteReplay
+}
+
+var (
+	cassetteOnce     sync.Once
+	cassetteRecorder *openapi2mcp.CassetteRecorder
+	cassetteReplay   func(req *http.Request) (*http.Response, error)
+)
+
+// callbackReceiver starts the callback receiver for --callback-addr, or returns nil if it isn't set.
+// The receiver is started once, so that all mounts share it.
+func callbackReceiver(flags *cliFlags) *openapi2mcp.CallbackReceiver {
+	callbackOnce.Do(func() {
+		if flags.callbackAddr == "" {
+			return
+		}
+		publicURL := flags.callbackURL
+		if publicURL == "" {
+			host, port, err := net.SplitHostPort(flags.callbackAddr)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: Invalid --callback-addr: %v\n", err)
+				os.Exit(1)
+			}
+			if host == "" {
+				host = "localhost"
+			}
+			publicURL = "http://" + net.JoinHostPort(host, port)
+		}
+		ln, err := net.Listen("tcp", flags.callbackAddr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Could not start callback receiver: %v\n", err)
+			os.Exit(1)
+		}
+		callbackRecv = openapi2mcp.NewCallbackReceiver(publicURL)
+		fmt.Fprintf(os.Stderr, "Receiving callbacks on %s (public URL %s)\n", flags.callbackAddr, publicURL)
+		go func() {
+			if err := http.Serve(ln, callbackRecv); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: Callback receiver failed: %v\n", err)
+			}
+		}()
+	})
+	return callbackRecv
+}
+
+var (
+	callbackOnce sync.Once
+	callbackRecv *openapi2mcp.CallbackReceiver
+)
+
+// responseTrimming loads the --trim-config rules, or returns nil if none are configured.
+func responseTrimming(flags *cliFlags) openapi2mcp.ResponseTrimming {
+	if flags.trimConfigFile == "" {
+		return nil
+	}
+	trimming, err := openapi2mcp.LoadResponseTrimming(flags.trimConfigFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not load --trim-config: %v\n", err)
+		os.Exit(1)
+	}
+	return trimming
+}
+
+// descriptionOverrides loads the --description-overrides file, warning about operationIds the spec doesn't have.
+func descriptionOverrides(flags *cliFlags, doc *openapi3.T) openapi2mcp.DescriptionOverrides {
+	if flags.overridesFile == "" {
+		return nil
+	}
+	overrides, err := openapi2mcp.LoadDescriptionOverrides(flags.overridesFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not load --description-overrides: %v\n", err)
+		os.Exit(1)
+	}
+	if unknown := overrides.Unknown(openapi2mcp.ExtractOpenAPIOperations(doc)); len(unknown) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: --description-overrides names unknown operations: %s\n", strings.Join(unknown, ", "))
+	}
+	return overrides
+}
+
+// presets loads the --presets file, warning about operationIds the spec doesn't have.
+func presets(flags *cliFlags, doc *openapi3.T) openapi2mcp.ArgumentPresets {
+	if flags.presetsFile == "" {
+		return nil
+	}
+	presets, err := openapi2mcp.LoadArgumentPresets(flags.presetsFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not load --presets: %v\n", err)
+		os.Exit(1)
+	}
+	if unknown := presets.Unknown(openapi2mcp.ExtractOpenAPIOperations(doc)); len(unknown) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: --presets names unknown operations: %s\n", strings.Join(unknown, ", "))
+	}
+	return presets
+}
+
+// workflows loads the --workflows file and the workflows of the --arazzo documents.
+func workflows(flags *cliFlags, doc *openapi3.T) []openapi2mcp.Workflow {
+	var workflows []openapi2mcp.Workflow
+	if flags.workflowsFile != "" {
+		loaded, err := openapi2mcp.LoadWorkflows(flags.workflowsFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Could not load --workflows: %v\n", err)
+			os.Exit(1)
+		}
+		workflows = loaded
+	}
+	for _, path := range flags.arazzoFiles {
+		loaded, err := openapi2mcp.LoadArazzoWorkflows(path, doc)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Could not load --arazzo: %v\n", err)
+			os.Exit(1)
+		}
+		workflows = append(workflows, loaded...)
+	}
+	return workflows
+}
+
+// responseHeaders splits the --response-header flags into headers for all operations and those
+// for single operations ("createPet:Location").
+func responseHeaders(flags *cliFlags) ([]string, map[string][]string) {
+	var all []string
+	byOperation := make(map[string][]string)
+	for _, h := range flags.responseHeaders {
+		if opID, name, ok := strings.Cut(h, ":"); ok {
+			byOperation[opID] = append(byOperation[opID], name)
+		} else {
+			all = append(all, h)
+		}
+	}
+	return all, byOperation
+}
+
+// baseURLOverrides parses the --base-url-for flags ("/admin=http://admin.internal", "tag:billing=https://billing.example.com").
+func baseURLOverrides(flags *cliFlags) []openapi2mcp.BaseURLOverride {
+	var overrides []openapi2mcp.BaseURLOverride
+	for _, f := range flags.baseURLFor {
+		key, baseURL, ok := strings.Cut(f, "=")
+		override := openapi2mcp.BaseURLOverride{URL: baseURL}
+		if tag, isTag := strings.CutPrefix(key, "tag:"); isTag {
+			override.Tag = tag
+		} else if strings.HasPrefix(key, "/") {
+			override.PathPrefix = key
+		} else {
+			ok = false
+		}
+		if !ok || baseURL == "" || key == "tag:" {
+			fmt.Fprintf(os.Stderr, "Error: Invalid --base-url-for %q: expected /path=URL or tag:name=URL\n", f)
+			os.Exit(1)
+		}
+		overrides = append(overrides, override)
+	}
+	return overrides
+}
+
+// oauthLogin builds the interactive OAuth2 sign-in of --oauth-login, or returns nil if it is not set.
+func oauthLogin(flags *cliFlags) *openapi2mcp.OAuthLogin {
+	if flags.oauthLogin == "" {
+		if flags.oauthAuthURL != "" || flags.oauthDeviceURL != "" || flags.oauthRedirectAddr != "" || flags.oauthTokenFile != "" {
+			fmt.Fprintln(os.Stderr, "Error: The --oauth-* flags require --oauth-login")
+			os.Exit(1)
+		}
+		return nil
+	}
+	if flags.oauthLogin != openapi2mcp.OAuthFlowAuthorizationCode && flags.oauthLogin != openapi2mcp.OAuthFlowDeviceCode {
+		fmt.Fprintf(os.Stderr, "Error: Invalid --oauth-login %q: expected %s or %s\n", flags.oauthLogin, openapi2mcp.OAuthFlowAuthorizationCode, openapi2mcp.OAuthFlowDeviceCode)
+		os.Exit(1)
+	}
+	if os.Getenv("CLIENT_ID") == "" {
+		fmt.Fprintln(os.Stderr, "Error: --oauth-login requires the CLIENT_ID environment variable")
+		os.Exit(1)
+	}
+	if flags.oauthLogin == openapi2mcp.OAuthFlowDeviceCode && flags.oauthDeviceURL == "" {
+		fmt.Fprintln(os.Stderr, "Error: --oauth-login=device_code requires --oauth-device-url")
+		os.Exit(1)
+	}
+	login := &openapi2mcp.OAuthLogin{
+		Flow:                   flags.oauthLogin,
+		ClientID:               os.Getenv("CLIENT_ID"),
+		ClientSecret:           os.Getenv("CLIENT_SECRET"),
+		AuthorizationURL:       flags.oauthAuthURL,
+		TokenURL:               os.Getenv("TOKEN_URL"),
+		DeviceAuthorizationURL: flags.oauthDeviceURL,
+		RedirectAddr:           flags.oauthRedirectAddr,
+		TokenFile:              flags.oauthTokenFile,
+	}
+	if scopes := os.Getenv("OAUTH_SCOPES"); scopes != "" {
+		login.Scopes = strings.FieldsFunc(scopes, func(r rune) bool { return r == ' ' || r == ',' })
+	}
+	return login
+}
+
+// stateStore opens the store of --state-dir, or returns nil if it is not set.
+func stateStore(flags *cliFlags) openapi2mcp.Store {
+	if flags.stateDir == "" {
+		return nil
+	}
+	store, err := openapi2mcp.NewFileStore(flags.stateDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --state-dir: %v\n", err)
+		os.Exit(1)
+	}
+	return store
+}
+
+// specDrift builds the comparison with the API's own spec of --spec-drift, or returns nil if it is not set.
+func specDrift(flags *cliFlags) *openapi2mcp.SpecDriftCheck {
+	if flags.specDrift <= 0 {
+		if flags.specDriftURL != "" {
+			fmt.Fprintln(os.Stderr, "Error: --spec-drift-url requires --spec-drift")
+			os.Exit(1)
+		}
+		return nil
+	}
+	return &openapi2mcp.SpecDriftCheck{URL: flags.specDriftURL, Interval: flags.specDrift}
+}
+
+// sandbox builds the sandbox routing of the --sandbox-* flags, or returns nil if --sandbox-url is not set.
+func sandbox(flags *cliFlags) *openapi2mcp.SandboxRouting {
+	if flags.sandboxURL == "" {
+		if len(flags.sandboxOps) > 0 || flags.sandboxPercent > 0 || flags.sandboxSession != "" || len(flags.sandboxHeaders) > 0 {
+			fmt.Fprintln(os.Stderr, "Error: The --sandbox-* flags require --sandbox-url")
+			os.Exit(1)
+		}
+		return nil
+	}
+	if flags.sandboxPercent < 0 || flags.sandboxPercent > 100 {
+		fmt.Fprintf(os.Stderr, "Error: Invalid --sandbox-percent %v: expected 0 to 100\n", flags.sandboxPercent)
+		os.Exit(1)
+	}
+	routing := &openapi2mcp.SandboxRouting{
+		URL:           flags.sandboxURL,
+		Operations:    flags.sandboxOps,
+		Percent:       flags.sandboxPercent,
+		SessionHeader: flags.sandboxSession,
+		Headers:       make(map[string]string),
+	}
+	for _, h := range flags.sandboxHeaders {
+		name, value, ok := strings.Cut(h, "=")
+		if !ok || name == "" {
+			fmt.Fprintf(os.Stderr, "Error: Invalid --sandbox-header %q: expected Name=Value\n", h)
+			os.Exit(1)
+		}
+		routing.Headers[name] = value
+	}
+	return routing
+}
+
+// transportResponseLimits parses the --response-limit flags ("stdio:256", in KB).
+func transportResponseLimits(flags *cliFlags) map[string]int64 {
+	limits := make(map[string]int64)
+	for _, l := range flags.responseLimits {
+		transport, value, _ := strings.Cut(l, ":")
+		kb, err := strconv.Atoi(value)
+		if transport != openapi2mcp.TransportStdio && transport != openapi2mcp.TransportHTTP || err != nil || kb <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: Invalid --response-limit %q: expected stdio:KB or http:KB, e.g. stdio:256\n", l)
+			os.Exit(1)
+		}
+		limits[transport] = int64(kb) << 10
+	}
+	return limits
+}
+
+// timeZone loads the --timezone location, exiting on an unknown one; nil means local time.
+func timeZone(flags *cliFlags) *time.Location {
+	if flags.timeZone == "" {
+		return nil
+	}
+	loc, err := time.LoadLocation(flags.timeZone)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Invalid --timezone %q: expected an IANA time zone such as UTC or Europe/Berlin\n", flags.timeZone)
+		os.Exit(1)
+	}
+	return loc
+}
+
+// reproCommand returns the --repro-command style, exiting on an unknown one.
+func reproCommand(flags *cliFlags) string {
+	switch flags.reproCommand {
+	case "", openapi2mcp.ReproCommandCurl, openapi2mcp.ReproCommandHTTPie:
+		return flags.reproCommand
+	}
+	fmt.Fprintf(os.Stderr, "Error: Invalid --repro-command %q: expected curl or httpie\n", flags.reproCommand)
+	os.Exit(1)
+	return ""
+}
+
+// clientProfile returns the --client-profile name, exiting on an unknown one.
+func clientProfile(flags *cliFlags) string {
+	if flags.clientProfile == "" || slices.Contains(openapi2mcp.ClientProfiles(), flags.clientProfile) {
+		return flags.clientProfile
+	}
+	fmt.Fprintf(os.Stderr, "Error: Invalid --client-profile %q: expected one of %s\n", flags.clientProfile, strings.Join(openapi2mcp.ClientProfiles(), ", "))
+	os.Exit(1)
+	return ""
+}
+
+// csrf returns the CSRF configuration of the --csrf-* flags, or nil if --csrf-header isn't set.
+func csrf(flags *cliFlags) *openapi2mcp.CSRFConfig {
+	if flags.csrfHeader == "" {
+		return nil
+	}
+	if flags.csrfCookie == "" && flags.csrfEndpoint == "" {
+		fmt.Fprintln(os.Stderr, "Error: --csrf-header requires --csrf-cookie or --csrf-endpoint")
+		os.Exit(1)
+	}
+	return &openapi2mcp.CSRFConfig{
+		Header:   flags.csrfHeader,
+		Cookie:   flags.csrfCookie,
+		Endpoint: flags.csrfEndpoint,
+		Field:    flags.csrfField,
+	}
+}
+
+// operationTimeouts parses the --operation-timeout flags ("createReport:5m").
+func operationTimeouts(flags *cliFlags) map[string]time.Duration {
+	timeouts := make(map[string]time.Duration)
+	for _, t := range flags.operationTimeouts {
+		opID, value, _ := strings.Cut(t, ":")
+		timeout, err := time.ParseDuration(value)
+		if opID == "" || err != nil || timeout <= 0 {
+			fmt.Fprintf(os.Stderr, "Error: Invalid --operation-timeout %q: expected operationId:duration, e.g. createReport:5m\n", t)
+			os.Exit(1)
+		}
+		timeouts[opID] = timeout
+	}
+	return timeouts
+}
+
+// concurrencyLimits parses the --max-concurrent flags ("deployRelease:1", "tag:deploy:1").
+func concurrencyLimits(flags *cliFlags) []openapi2mcp.ConcurrencyLimit {
+	var limits []openapi2mcp.ConcurrencyLimit
+	for _, f := range flags.maxConcurrent {
+		limit := openapi2mcp.ConcurrencyLimit{Wait: flags.concurrencyWait}
+		i := strings.LastIndex(f, ":")
+		var err error
+		if i > 0 {
+			limit.Max, err = strconv.Atoi(f[i+1:])
+			if tag, isTag := strings.CutPrefix(f[:i], "tag:"); isTag {
+				limit.Tag = tag
+			} else {
+				limit.OperationID = f[:i]
+			}
+		}
+		if i <= 0 || err != nil || limit.Max <= 0 || limit.OperationID == "" && limit.Tag == "" {
+			fmt.Fprintf(os.Stderr, "Error: Invalid --max-concurrent %q: expected operationId:n or tag:name:n, e.g. deployRelease:1\n", f)
+			os.Exit(1)
+		}
+		limits = append(limits, limit)
+	}
+	return limits
+}
+
+// operationCosts parses the --operation-cost flags ("chargeCard:high:Charges the card") and checks --confirm-cost.
+func operationCosts(flags *cliFlags) map[string]openapi2mcp.OperationCost {
+	if flags.confirmCost != "" && !openapi2mcp.ValidCostLevel(flags.confirmCost) {
+		fmt.Fprintf(os.Stderr, "Error: Invalid --confirm-cost %q: expected low, medium, high, or critical\n", flags.confirmCost)
+		os.Exit(1)
+	}
+	costs := make(map[string]openapi2mcp.OperationCost)
+	for _, f := range flags.operationCosts {
+		parts := strings.SplitN(f, ":", 3)
+		if len(parts) < 2 || parts[0] == "" || !openapi2mcp.ValidCostLevel(parts[1]) {
+			fmt.Fprintf(os.Stderr, "Error: Invalid --operation-cost %q: expected operationId:level[:reason], e.g. chargeCard:high\n", f)
+			os.Exit(1)
+		}
+		cost := openapi2mcp.OperationCost{Level: parts[1]}
+		if len(parts) == 3 {
+			cost.Reason = parts[2]
+		}
+		costs[parts[0]] = cost
+	}
+	return costs
+}
+
+// policy loads the --policy file, or returns nil if none is set.
+func policy(flags *cliFlags) *openapi2mcp.Policy {
+	if flags.policyFile == "" {
+		return nil
+	}
+	p, err := openapi2mcp.LoadPolicy(flags.policyFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not load --policy: %v\n", err)
+		os.Exit(1)
+	}
+	return p
+}
+
+// argumentRules loads the --argument-rules file, or returns nil if none is set.
+func argumentRules(flags *cliFlags) *openapi2mcp.ArgumentRules {
+	if flags.argumentRulesFile == "" {
+		return nil
+	}
+	rules, err := openapi2mcp.LoadArgumentRules(flags.argumentRulesFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not load --argument-rules: %v\n", err)
+		os.Exit(1)
+	}
+	return rules
+}
+
+// openLogHandler opens the --log-file for JSONL traffic logging with rotation.
+// It returns a nil handler if no log file is configured, and a function closing the file.
+func openLogHandler(flags *cliFlags) (slog.Handler, func()) {
+	if flags.logFile == "" {
+		return nil, func() {}
+	}
+	w, err := openapi2mcp.NewRotatingFileWriter(flags.logFile, int64(flags.logMaxSizeMB)<<20, flags.logMaxBackups)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: Could not open log file: %v\n", err)
+		os.Exit(1)
+	}
+	return slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug}), func() { w.Close() }
+}
@@ -6,49 +6,90 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"slices"
 
 	openapi2mcp "github.com/evcc-io/openapi-mcp"
 	"github.com/getkin/kin-openapi/openapi3"
 )
 
-// handleDryRunMode handles the --dry-run mode, printing tool schemas and summaries.
-func handleDryRunMode(flags *cliFlags, ops []openapi2mcp.OpenAPIOperation, doc *openapi3.T) {
-	opts := &openapi2mcp.ToolGenOptions{
+// dryRunOptions returns the tool generation options of --dry-run and --summary.
+func dryRunOptions(flags *cliFlags, doc *openapi3.T) *openapi2mcp.ToolGenOptions {
+	return &openapi2mcp.ToolGenOptions{
 		NameFormat:              nil, // Not used for dry-run output
 		TagFilter:               flags.tagFlags,
 		DryRun:                  true,
 		PrettyPrint:             true,
 		Version:                 doc.Info.Version,
 		ConfirmDangerousActions: !flags.noConfirmDangerous,
+		CompactSchemas:          flags.compactSchemas,
+		ClientProfile:           clientProfile(flags),
+		DescriptionOverrides:    descriptionOverrides(flags, doc),
+		MaxDescriptionLength:    flags.maxDescription,
 	}
-	openapi2mcp.RegisterOpenAPITools(nil, ops, doc, opts)
-	if flags.summary {
-		openapi2mcp.PrintToolSummary(ops)
-	}
+}
+
+// handleDryRunMode handles the --dry-run mode, printing tool schemas and summaries.
+func handleDryRunMode(flags *cliFlags, ops []openapi2mcp.OpenAPIOperation, doc *openapi3.T) {
+	opts := dryRunOptions(flags, doc)
+	out, _ := json.MarshalIndent(openapi2mcp.GenerateToolSummaries(ops, doc, opts), "", "  ")
+	fmt.Println(string(out))
 	if flags.diffFile != "" {
 		compareWithDiffFile(opts, doc, ops, flags.diffFile)
 	}
 	os.Exit(0)
 }
 
+// Exit codes of --summary, for CI pipelines.
+const (
+	summaryExitOK       = 0 // The summary was printed; no breaking changes
+	summaryExitError    = 1 // The spec or the --diff file could not be read
+	summaryExitBreaking = 2 // Breaking changes compared to the --diff file
+)
+
+// handleSummaryMode handles the --summary mode, printing the tool surface as a JSON object. With --diff, the tools
+// are compared to a previous --dry-run output, and the command exits with summaryExitBreaking on breaking changes.
+func handleSummaryMode(flags *cliFlags, ops []openapi2mcp.OpenAPIOperation, doc *openapi3.T) {
+	summaries := openapi2mcp.GenerateToolSummaries(ops, doc, dryRunOptions(flags, doc))
+	surface := openapi2mcp.SummarizeTools(summaries, openapi2mcp.LintOpenAPISpec(doc, false))
+	if flags.diffFile != "" {
+		data, err := os.ReadFile(flags.diffFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Could not read diff file: %v\n", err)
+			os.Exit(summaryExitError)
+		}
+		var previous []openapi2mcp.ToolSummary
+		if err := json.Unmarshal(data, &previous); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Could not parse diff file %s (expected --dry-run output): %v\n", flags.diffFile, err)
+			os.Exit(summaryExitError)
+		}
+		surface.Diff = openapi2mcp.DiffToolSummaries(previous, summaries)
+	}
+	out, _ := json.MarshalIndent(surface, "", "  ")
+	fmt.Println(string(out))
+	if surface.Diff != nil && surface.Diff.Breaking {
+		os.Exit(summaryExitBreaking)
+	}
+	os.Exit(summaryExitOK)
+}
+
+// tokensExitOverBudget is the exit code of --tokens if the tools exceed --token-budget.
+const tokensExitOverBudget = 2
+
+// handleTokensMode handles the --tokens mode, printing the estimated tokens of the tools as a JSON object.
+func handleTokensMode(flags *cliFlags, ops []openapi2mcp.OpenAPIOperation, doc *openapi3.T) {
+	estimate := openapi2mcp.EstimateToolTokens(ops, doc, dryRunOptions(flags, doc), nil, flags.tokenBudget)
+	out, _ := json.MarshalIndent(estimate, "", "  ")
+	fmt.Println(string(out))
+	if estimate.OverBudget() {
+		os.Exit(tokensExitOverBudget)
+	}
+	os.Exit(0)
+}
+
 // compareWithDiffFile compares the generated output to a previous run (file path).
 func compareWithDiffFile(opts *openapi2mcp.ToolGenOptions, doc *openapi3.T, ops []openapi2mcp.OpenAPIOperation, diffFile string) {
 	// Generate current output
 	var toolSummaries []map[string]any
-	for _, op := range ops {
-		if len(opts.TagFilter) > 0 {
-			found := false
-			for _, tag := range opts.TagFilter {
-				if slices.Contains(op.Tags, tag) {
-					found = true
-					break
-				}
-			}
-			if !found {
-				continue
-			}
-		}
+	for _, op := range openapi2mcp.Operations(ops).ByTag(opts.TagFilter...) {
 		name := op.OperationID
 		if opts.NameFormat != nil {
 			name = opts.NameFormat(name)
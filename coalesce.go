@@ -0,0 +1,92 @@
+// coalesce.go
+package openapi2mcp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+)
+
+// inflightGroup coalesces identical GET requests in flight, so that an agent firing the same query again while the
+// first call is pending causes a single upstream request. It is safe for concurrent use.
+type inflightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*inflightCall
+}
+
+// inflightCall is an upstream request whose response is shared by the calls waiting for it.
+type inflightCall struct {
+	done chan struct{}
+	resp *http.Response // without its body, which is read into body
+	body []byte
+	err  error
+}
+
+// newInflightGroup creates an empty group of in-flight requests.
+func newInflightGroup() *inflightGroup {
+	return &inflightGroup{calls: make(map[string]*inflightCall)}
+}
+
+// coalesceKey identifies a request by the MCP session sending it, its method, URL, and headers, except the
+// per-call correlation ID header.
+func coalesceKey(session string, req *http.Request, requestIDHeader string) string {
+	h := sha256.New()
+	io.WriteString(h, session+"\n"+req.Method+" "+req.URL.String()+"\n")
+	for _, name := range slices.Sorted(maps.Keys(req.Header)) {
+		if requestIDHeader != "" && http.CanonicalHeaderKey(requestIDHeader) == name {
+			continue
+		}
+		for _, value := range req.Header[name] {
+			io.WriteString(h, name+": "+value+"\n")
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// do sends req with send, unless a request with the same key is in flight: then it waits for that request's
+// response instead. Every caller gets its own copy of the response, whose body is read up to limit+1 bytes so that
+// truncation can still be detected. shared reports whether the response was another call's.
+func (g *inflightGroup) do(key string, req *http.Request, send func(*http.Request) (*http.Response, error), limit int64) (resp *http.Response, shared bool, err error) {
+	g.mu.Lock()
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.response(req), true, call.err
+		case <-req.Context().Done():
+			return nil, true, req.Context().Err()
+		}
+	}
+	call := &inflightCall{done: make(chan struct{})}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	call.resp, call.err = send(req)
+	if call.err == nil {
+		call.body, call.err = io.ReadAll(io.LimitReader(call.resp.Body, limit+1))
+		call.resp.Body.Close()
+	}
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	close(call.done)
+	return call.response(req), false, call.err
+}
+
+// response returns a copy of the call's response for req, or nil if it failed.
+func (c *inflightCall) response(req *http.Request) *http.Response {
+	if c.err != nil {
+		return nil
+	}
+	resp := *c.resp
+	resp.Header = c.resp.Header.Clone()
+	resp.Body = io.NopCloser(bytes.NewReader(c.body))
+	resp.Request = req
+	return &resp
+}
@@ -0,0 +1,92 @@
+package openapi2mcp
+
+import (
+	"context"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestToolHandler_CoalesceRequests(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	op := OpenAPIOperation{OperationID: "getReport", Path: "/report", Method: "get"}
+	opts := &ToolGenOptions{
+		CoalesceRequests: true,
+		RequestIDHeader:  "X-Request-ID",
+		RequestHandler: func(req *http.Request) (*http.Response, error) {
+			calls.Add(1)
+			<-release
+			return fakeResponse(200, "application/json", `{"total": 42}`)(req)
+		},
+	}
+	rt := newServerRuntime()
+	handler := toolHandler("getReport", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, rt)
+
+	var wg sync.WaitGroup
+	texts := make([]string, 3)
+	for i := range texts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, _, err := handler(context.Background(), nil, map[string]any{})
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			texts[i] = resultText(t, res)
+		}()
+	}
+	for calls.Load() == 0 {
+		runtime.Gosched()
+	}
+	// Give the other calls time to join the one in flight
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected a single upstream request, got %d", n)
+	}
+	for _, text := range texts {
+		if !strings.Contains(text, `"total": 42`) {
+			t.Errorf("expected the shared response, got: %s", text)
+		}
+	}
+	if hits := rt.stats.snapshot()["getReport"].CacheHits; hits != 2 {
+		t.Errorf("expected the calls sharing the response to count as cache hits, got %d", hits)
+	}
+
+	// Calls made after the first completed are sent again
+	if _, _, err := handler(context.Background(), nil, map[string]any{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("expected a new upstream request, got %d in total", n)
+	}
+}
+
+func TestCoalesceKey(t *testing.T) {
+	req := func(query, tenant string) *http.Request {
+		r, _ := http.NewRequest(http.MethodGet, "http://example.com/report"+query, nil)
+		r.Header.Set("X-Tenant", tenant)
+		r.Header.Set("X-Request-ID", query+tenant)
+		return r
+	}
+	key := coalesceKey("s1", req("", "a"), "x-request-id")
+	if coalesceKey("s1", req("", "a"), "x-request-id") != key {
+		t.Error("expected identical requests to have the same key")
+	}
+	if coalesceKey("s2", req("", "a"), "x-request-id") == key {
+		t.Error("expected requests of other sessions to have another key")
+	}
+	if coalesceKey("s1", req("?page=2", "a"), "x-request-id") == key || coalesceKey("s1", req("", "b"), "x-request-id") == key {
+		t.Error("expected requests with other URLs or headers to have another key")
+	}
+}
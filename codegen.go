@@ -0,0 +1,414 @@
+// codegen.go
+package openapi2mcp
+
+import (
+	"fmt"
+	"go/format"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// CodegenOptions controls the Go module generated by GenerateGoServer.
+//
+// ModulePath: module path of the generated module (default: derived from the API title, e.g. "petstore-mcp")
+// LibraryVersion: if set, the generated go.mod requires this version of openapi-mcp; otherwise run go mod tidy
+// TagFilter: only serve operations with at least one of these tags (if non-empty)
+// ConfirmDangerousActions: if true, require confirmation for PUT/POST/DELETE tools
+type CodegenOptions struct {
+	ModulePath              string
+	LibraryVersion          string
+	TagFilter               []string
+	ConfirmDangerousActions bool
+}
+
+// libraryModulePath is the module path of this package, as imported by generated code.
+const libraryModulePath = "github.com/evcc-io/openapi-mcp"
+
+// GenerateGoServer generates a standalone Go module serving ops of doc as MCP tools over stdio
+// (or streamable HTTP with -http). The document and operations are compiled in as Go values, so the
+// generated server doesn't load or parse the spec at runtime. Returns the generated files by name:
+// go.mod, main.go, and spec.go.
+//
+// Example usage for GenerateGoServer:
+//
+//	files, err := openapi2mcp.GenerateGoServer(doc, ops, &openapi2mcp.CodegenOptions{ModulePath: "example.com/petstore-mcp"})
+//	if err != nil { log.Fatal(err) }
+//	for name, data := range files { os.WriteFile(filepath.Join("out", name), data, 0o644) }
+func GenerateGoServer(doc *openapi3.T, ops []OpenAPIOperation, opts *CodegenOptions) (map[string][]byte, error) {
+	if opts == nil {
+		opts = &CodegenOptions{}
+	}
+	modulePath := opts.ModulePath
+	if modulePath == "" {
+		modulePath = codegenModuleName(doc)
+	}
+
+	specSource, err := generateSpecSource(doc, ops)
+	if err != nil {
+		return nil, err
+	}
+	mainSource, err := format.Source([]byte(fmt.Sprintf(codegenMainTemplate, goLiteralStrings(opts.TagFilter), opts.ConfirmDangerousActions)))
+	if err != nil {
+		return nil, fmt.Errorf("formatting main.go: %w", err)
+	}
+
+	var goMod strings.Builder
+	fmt.Fprintf(&goMod, "module %s\n\ngo 1.24.0\n", modulePath)
+	if opts.LibraryVersion != "" {
+		fmt.Fprintf(&goMod, "\nrequire %s %s\n", libraryModulePath, opts.LibraryVersion)
+	}
+
+	return map[string][]byte{
+		"go.mod":  []byte(goMod.String()),
+		"main.go": mainSource,
+		"spec.go": specSource,
+	}, nil
+}
+
+// codegenModuleName derives a module path like "petstore-mcp" from the API title.
+func codegenModuleName(doc *openapi3.T) string {
+	title := "api"
+	if doc.Info != nil && doc.Info.Title != "" {
+		title = doc.Info.Title
+	}
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			sb.WriteRune(r)
+			dash = false
+		} else if !dash && sb.Len() > 0 {
+			sb.WriteByte('-')
+			dash = true
+		}
+	}
+	name := strings.TrimSuffix(sb.String(), "-")
+	if name == "" {
+		name = "api"
+	}
+	return name + "-mcp"
+}
+
+// goLiteralStrings renders a []string as a Go literal.
+func goLiteralStrings(values []string) string {
+	if len(values) == 0 {
+		return "nil"
+	}
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = strconv.Quote(v)
+	}
+	return "[]string{" + strings.Join(quoted, ", ") + "}"
+}
+
+const codegenMainTemplate = `// Code generated by openapi-mcp codegen. DO NOT EDIT.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	openapi2mcp "github.com/evcc-io/openapi-mcp"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func main() {
+	httpAddr := flag.String("http", "", "Serve MCP over streamable HTTP on this address (e.g. :8080) instead of stdio")
+	flag.Parse()
+
+	doc, ops := spec()
+	srv := mcp.NewServer(&mcp.Implementation{Name: doc.Info.Title, Version: doc.Info.Version}, nil)
+	openapi2mcp.RegisterOpenAPITools(srv, ops, doc, &openapi2mcp.ToolGenOptions{
+		Version:                 doc.Info.Version,
+		TagFilter:               %s,
+		ConfirmDangerousActions: %t,
+	})
+
+	if *httpAddr != "" {
+		handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return srv }, nil)
+		fmt.Fprintf(os.Stderr, "Serving MCP over HTTP on %%s\n", *httpAddr)
+		if err := http.ListenAndServe(*httpAddr, handler); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: HTTP server failed: %%v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if err := srv.Run(context.Background(), &mcp.StdioTransport{}); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: MCP server failed: %%v\n", err)
+		os.Exit(1)
+	}
+}
+`
+
+// generateSpecSource renders doc and ops as the Go source of spec.go.
+func generateSpecSource(doc *openapi3.T, ops []OpenAPIOperation) ([]byte, error) {
+	e := &goEmitter{refs: make(map[goPointer]int), names: make(map[goPointer]string)}
+	docValue, opsValue := reflect.ValueOf(doc), reflect.ValueOf(ops)
+	e.count(docValue)
+	e.count(opsValue)
+	docExpr := e.expr(docValue)
+	opsExpr := e.expr(opsValue)
+	if e.err != nil {
+		return nil, e.err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("// Code generated by openapi-mcp codegen. DO NOT EDIT.\n\n")
+	sb.WriteString("package main\n\n")
+	sb.WriteString("import (\n\topenapi2mcp \"" + libraryModulePath + "\"\n\t\"github.com/getkin/kin-openapi/openapi3\"\n)\n\n")
+	sb.WriteString("// spec returns the OpenAPI document and the operations served as tools.\n")
+	sb.WriteString("func spec() (*openapi3.T, []openapi2mcp.OpenAPIOperation) {\n")
+	for _, line := range e.decls {
+		sb.WriteString(line + "\n")
+	}
+	for _, line := range e.stmts {
+		sb.WriteString(line + "\n")
+	}
+	sb.WriteString("return " + docExpr + ", " + opsExpr + "\n}\n\n")
+	sb.WriteString("func ptr[T any](v T) *T { return &v }\n")
+
+	src, err := format.Source([]byte(sb.String()))
+	if err != nil {
+		return nil, fmt.Errorf("formatting spec.go: %w", err)
+	}
+	return src, nil
+}
+
+// goPointer identifies a pointer by address and type (a struct and its first field share an address).
+type goPointer struct {
+	addr uintptr
+	typ  reflect.Type
+}
+
+// goEmitter renders values as Go expressions. Pointers referenced more than once (shared component
+// schemas, recursive schemas) and the map-like kin-openapi types are hoisted into variables.
+type goEmitter struct {
+	refs  map[goPointer]int
+	names map[goPointer]string
+	decls []string
+	stmts []string
+	err   error
+}
+
+var (
+	pathsType     = reflect.TypeFor[openapi3.Paths]()
+	responsesType = reflect.TypeFor[openapi3.Responses]()
+	callbackType  = reflect.TypeFor[openapi3.Callback]()
+)
+
+// isMapLike reports whether t is a kin-openapi type keeping its entries in an unexported map.
+func isMapLike(t reflect.Type) bool {
+	return t == pathsType || t == responsesType || t == callbackType
+}
+
+// count counts the references to each pointer reachable from v.
+func (e *goEmitter) count(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return
+		}
+		key := goPointer{v.Pointer(), v.Type()}
+		e.refs[key]++
+		if e.refs[key] > 1 {
+			return
+		}
+		if isMapLike(v.Type().Elem()) {
+			m := v.MethodByName("Map").Call(nil)[0]
+			for _, k := range m.MapKeys() {
+				e.count(m.MapIndex(k))
+			}
+			e.count(v.Elem().FieldByName("Extensions"))
+			return
+		}
+		e.count(v.Elem())
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				e.count(v.Field(i))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			e.count(v.Index(i))
+		}
+	case reflect.Map:
+		for _, k := range v.MapKeys() {
+			e.count(v.MapIndex(k))
+		}
+	case reflect.Interface:
+		if !v.IsNil() {
+			e.count(v.Elem())
+		}
+	}
+}
+
+// expr returns a Go expression for v, of v's static type.
+func (e *goEmitter) expr(v reflect.Value) string {
+	t := v.Type()
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return "nil"
+		}
+		key := goPointer{v.Pointer(), t}
+		if name, ok := e.names[key]; ok {
+			return name
+		}
+		if isMapLike(t.Elem()) {
+			return e.hoistMapLike(v, key)
+		}
+		if e.refs[key] > 1 {
+			return e.hoist(v, key)
+		}
+		if t.Elem().Kind() == reflect.Struct {
+			return "&" + e.expr(v.Elem())
+		}
+		return "ptr[" + e.typeName(t.Elem()) + "](" + e.expr(v.Elem()) + ")"
+	case reflect.Struct:
+		var fields []string
+		for i := range v.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() || v.Field(i).IsZero() {
+				continue
+			}
+			fields = append(fields, f.Name+": "+e.expr(v.Field(i)))
+		}
+		if len(fields) == 0 {
+			return e.typeName(t) + "{}"
+		}
+		return e.typeName(t) + "{\n" + strings.Join(fields, ",\n") + ",\n}"
+	case reflect.Slice:
+		if v.IsNil() {
+			return "nil"
+		}
+		elems := make([]string, v.Len())
+		for i := range v.Len() {
+			elems[i] = e.expr(v.Index(i))
+		}
+		return e.typeName(t) + "{" + strings.Join(elems, ", ") + "}"
+	case reflect.Map:
+		if v.IsNil() {
+			return "nil"
+		}
+		if t.Key().Kind() != reflect.String {
+			e.fail("unsupported map key type %s", t.Key())
+			return "nil"
+		}
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int { return strings.Compare(a.String(), b.String()) })
+		entries := make([]string, len(keys))
+		for i, k := range keys {
+			entries[i] = strconv.Quote(k.String()) + ": " + e.expr(v.MapIndex(k))
+		}
+		if len(entries) == 0 {
+			return e.typeName(t) + "{}"
+		}
+		return e.typeName(t) + "{\n" + strings.Join(entries, ",\n") + ",\n}"
+	case reflect.Interface:
+		if v.IsNil() {
+			return "nil"
+		}
+		// Keep the dynamic type of basic values: an untyped constant would default to string, bool, int, or float64
+		elem := v.Elem()
+		switch elem.Kind() {
+		case reflect.Pointer, reflect.Struct, reflect.Slice, reflect.Map, reflect.Interface:
+			return e.expr(elem)
+		case reflect.String, reflect.Bool:
+			if elem.Type().PkgPath() == "" {
+				return e.expr(elem)
+			}
+		}
+		return e.typeName(elem.Type()) + "(" + e.expr(elem) + ")"
+	case reflect.String:
+		return strconv.Quote(v.String())
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			e.fail("unsupported number %v", f)
+			return "0"
+		}
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	e.fail("unsupported value of type %s", t)
+	return "nil"
+}
+
+// hoist declares a variable for the struct pointer v, so that it can be shared and refer to itself.
+func (e *goEmitter) hoist(v reflect.Value, key goPointer) string {
+	name := "p" + strconv.Itoa(len(e.names)+1)
+	e.names[key] = name
+	e.decls = append(e.decls, name+" := new("+e.typeName(v.Type().Elem())+")")
+	e.stmts = append(e.stmts, "*"+name+" = "+e.expr(v.Elem()))
+	return name
+}
+
+// hoistMapLike declares a variable for a Paths, Responses, or Callback pointer and fills it with Set.
+func (e *goEmitter) hoistMapLike(v reflect.Value, key goPointer) string {
+	name := "p" + strconv.Itoa(len(e.names)+1)
+	e.names[key] = name
+	m := v.MethodByName("Map").Call(nil)[0]
+	e.decls = append(e.decls, fmt.Sprintf("%s := openapi3.New%sWithCapacity(%d)", name, v.Type().Elem().Name(), m.Len()))
+
+	keys := m.MapKeys()
+	slices.SortFunc(keys, func(a, b reflect.Value) int { return strings.Compare(a.String(), b.String()) })
+	for _, k := range keys {
+		e.stmts = append(e.stmts, fmt.Sprintf("%s.Set(%s, %s)", name, strconv.Quote(k.String()), e.expr(m.MapIndex(k))))
+	}
+	if ext := v.Elem().FieldByName("Extensions"); !ext.IsNil() {
+		e.stmts = append(e.stmts, name+".Extensions = "+e.expr(ext))
+	}
+	return name
+}
+
+// typeName returns the Go name of t as written in generated code.
+func (e *goEmitter) typeName(t reflect.Type) string {
+	if t.Name() != "" {
+		switch t.PkgPath() {
+		case "":
+			return t.Name()
+		case "github.com/getkin/kin-openapi/openapi3":
+			return "openapi3." + t.Name()
+		case libraryModulePath:
+			return "openapi2mcp." + t.Name()
+		}
+		e.fail("unsupported type %s", t)
+		return t.Name()
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return "*" + e.typeName(t.Elem())
+	case reflect.Slice:
+		return "[]" + e.typeName(t.Elem())
+	case reflect.Map:
+		return "map[" + e.typeName(t.Key()) + "]" + e.typeName(t.Elem())
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "any"
+		}
+	}
+	e.fail("unsupported type %s", t)
+	return t.String()
+}
+
+// fail records the first error.
+func (e *goEmitter) fail(format string, args ...any) {
+	if e.err == nil {
+		e.err = fmt.Errorf("codegen: "+format, args...)
+	}
+}
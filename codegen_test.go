@@ -0,0 +1,181 @@
+package openapi2mcp
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const codegenSpec = `openapi: 3.0.3
+info: {title: "Pet Store (v2)", version: "2.0.0"}
+servers: [{url: "https://pets.example.com/v2"}]
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      tags: [pets]
+      parameters:
+        - {name: limit, in: query, schema: {type: integer, minimum: 1, maximum: 100, default: 20}}
+        - {name: status, in: query, schema: {type: string, enum: [available, sold]}}
+      responses:
+        "200":
+          description: Pets
+          content:
+            application/json:
+              schema: {type: array, items: {$ref: '#/components/schemas/Pet'}}
+    post:
+      operationId: createPet
+      tags: [pets]
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/Pet'}
+      responses:
+        "201":
+          description: Created
+          links:
+            GetPet:
+              operationId: getPet
+              parameters: {id: '$response.body#/id'}
+  /pets/{id}:
+    parameters:
+      - {name: id, in: path, required: true, schema: {type: integer, format: int64}}
+    get:
+      operationId: getPet
+      security: [{apiKey: []}]
+      responses:
+        "200":
+          description: Pet
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Pet'}
+        default: {description: Error}
+components:
+  securitySchemes:
+    apiKey: {type: apiKey, in: header, name: X-API-Key}
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      x-internal: false
+      properties:
+        id: {type: integer, readOnly: true}
+        name: {type: string, maxLength: 64, example: Rex}
+        weight: {type: number, example: 4.5}
+        tags: {type: array, items: {$ref: '#/components/schemas/Tag'}}
+    Tag:
+      type: object
+      properties:
+        label: {type: string}
+`
+
+func TestGenerateGoServer(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(codegenSpec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ops := ExtractOpenAPIOperations(doc)
+
+	files, err := GenerateGoServer(doc, ops, &CodegenOptions{LibraryVersion: "v1.2.3"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := string(files["go.mod"]); !strings.HasPrefix(got, "module pet-store-v2-mcp\n") || !strings.Contains(got, "require github.com/evcc-io/openapi-mcp v1.2.3") {
+		t.Errorf("unexpected go.mod:\n%s", got)
+	}
+	again, err := GenerateGoServer(doc, ops, &CodegenOptions{LibraryVersion: "v1.2.3"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(again["spec.go"]) != string(files["spec.go"]) {
+		t.Error("expected generated code to be deterministic")
+	}
+
+	if testing.Short() {
+		t.Skip("skipping build of generated server in short mode")
+	}
+
+	// Build the generated package inside this module, so that it compiles against this checkout
+	dir, err := os.MkdirTemp("testdata", "codegen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	for _, name := range []string{"main.go", "spec.go"} {
+		if err := os.WriteFile(filepath.Join(dir, name), files[name], 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bin := filepath.Join(t.TempDir(), "server")
+	if out, err := exec.Command("go", "build", "-o", bin, "./"+filepath.ToSlash(dir)).CombinedOutput(); err != nil {
+		t.Fatalf("generated code does not build: %v\n%s", err, out)
+	}
+
+	// The compiled-in server must expose exactly the tools of the spec it was generated from
+	ctx := context.Background()
+	client := mcp.NewClient(&mcp.Implementation{Name: "codegen-test", Version: "1.0.0"}, nil)
+	cs, err := client.Connect(ctx, &mcp.CommandTransport{Command: exec.Command(bin)}, nil)
+	if err != nil {
+		t.Fatalf("failed to connect to generated server: %v", err)
+	}
+	defer cs.Close()
+	var tools []*mcp.Tool
+	for tool, err := range cs.Tools(ctx, nil) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		tools = append(tools, tool)
+	}
+	got, err := MarshalToolsSnapshot(tools)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := ToolsSnapshot(ctx, doc, ops, &ToolGenOptions{Version: doc.Info.Version})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("generated server tools differ from the spec's:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestGenerateSpecSource_Cycles(t *testing.T) {
+	// A schema referring to itself is emitted as a variable assigned after its declaration
+	node := &openapi3.Schema{Type: typesPtr("object")}
+	node.Properties = openapi3.Schemas{"parent": &openapi3.SchemaRef{Ref: "#/components/schemas/Node", Value: node}}
+	doc := minimalOpenAPIDoc()
+	doc.Components = &openapi3.Components{Schemas: openapi3.Schemas{"Node": &openapi3.SchemaRef{Value: node}}}
+
+	src, err := generateSpecSource(doc, ExtractOpenAPIOperations(doc))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := string(src)
+	for _, want := range []string{"p1 := new(openapi3.Schema)", "*p1 = openapi3.Schema{", "Value: p1,"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected generated code to contain %q, got:\n%s", want, text)
+		}
+	}
+}
+
+func TestCodegenModuleName(t *testing.T) {
+	for title, want := range map[string]string{
+		"Swagger Petstore": "swagger-petstore-mcp",
+		"  API (v2)!  ":    "api-v2-mcp",
+		"":                 "api-mcp",
+		"---":              "api-mcp",
+	} {
+		doc := minimalOpenAPIDoc()
+		doc.Info.Title = title
+		if got := codegenModuleName(doc); got != want {
+			t.Errorf("codegenModuleName(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
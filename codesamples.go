@@ -0,0 +1,72 @@
+// codesamples.go
+package openapi2mcp
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// CodeSample is an example request of an operation documented with the x-codeSamples (or x-code-samples)
+// extension, as used by Redoc and other documentation generators.
+type CodeSample struct {
+	Lang   string `json:"lang"`
+	Label  string `json:"label,omitempty"`
+	Source string `json:"source"`
+}
+
+// OperationCodeSamples returns the code samples documented for the operation, in spec order.
+func OperationCodeSamples(op OpenAPIOperation, doc *openapi3.T) []CodeSample {
+	if doc == nil || doc.Paths == nil {
+		return nil
+	}
+	item := doc.Paths.Value(op.Path)
+	if item == nil {
+		return nil
+	}
+	o := item.GetOperation(strings.ToUpper(op.Method))
+	if o == nil {
+		return nil
+	}
+	raw, ok := o.Extensions["x-codeSamples"]
+	if !ok {
+		raw = o.Extensions["x-code-samples"]
+	}
+	entries, _ := raw.([]any)
+	var samples []CodeSample
+	for _, entry := range entries {
+		m, _ := entry.(map[string]any)
+		source, _ := m["source"].(string)
+		if strings.TrimSpace(source) == "" {
+			continue
+		}
+		lang, _ := m["lang"].(string)
+		label, _ := m["label"].(string)
+		samples = append(samples, CodeSample{Lang: lang, Label: label, Source: source})
+	}
+	return samples
+}
+
+// describeCodeSamples appends the operation's code samples in the given languages (matched case-insensitively
+// against lang and label) to its tool description, as they often show the payload structure better than the schema.
+func describeCodeSamples(op OpenAPIOperation, doc *openapi3.T, langs []string) string {
+	if len(langs) == 0 {
+		return ""
+	}
+	var desc string
+	for _, sample := range OperationCodeSamples(op, doc) {
+		if !slices.ContainsFunc(langs, func(lang string) bool {
+			return strings.EqualFold(lang, sample.Lang) || strings.EqualFold(lang, sample.Label)
+		}) {
+			continue
+		}
+		name := sample.Lang
+		if sample.Label != "" && !strings.EqualFold(sample.Label, sample.Lang) {
+			name = fmt.Sprintf("%s (%s)", sample.Label, sample.Lang)
+		}
+		desc += fmt.Sprintf("\n\nCODE SAMPLE %s:\n%s", name, strings.TrimRight(sample.Source, "\n"))
+	}
+	return desc
+}
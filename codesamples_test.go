@@ -0,0 +1,43 @@
+package openapi2mcp
+
+import "testing"
+
+func TestCodeSamples(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /pets:
+    post:
+      operationId: createPet
+      x-codeSamples:
+        - {lang: Shell, label: cURL, source: "curl -X POST https://api.example.com/pets -d '{\"name\":\"Rex\"}'\n"}
+        - {lang: JavaScript, source: "await client.pets.create({name: 'Rex'})"}
+        - {lang: Go}
+      responses: {"201": {description: created}}
+    get:
+      operationId: listPets
+      x-code-samples: [{lang: Python, source: "client.pets.list()"}]
+      responses: {"200": {description: ok}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ops := map[string]OpenAPIOperation{}
+	for _, op := range ExtractOpenAPIOperations(doc) {
+		ops[op.OperationID] = op
+	}
+	if samples := OperationCodeSamples(ops["createPet"], doc); len(samples) != 2 || samples[0].Label != "cURL" || samples[1].Lang != "JavaScript" {
+		t.Errorf("unexpected samples: %+v", samples)
+	}
+	if samples := OperationCodeSamples(ops["listPets"], doc); len(samples) != 1 || samples[0].Source != "client.pets.list()" {
+		t.Errorf("expected x-code-samples to be read too, got %+v", samples)
+	}
+
+	want := "\n\nCODE SAMPLE cURL (Shell):\ncurl -X POST https://api.example.com/pets -d '{\"name\":\"Rex\"}'"
+	if got := describeCodeSamples(ops["createPet"], doc, []string{"curl"}); got != want {
+		t.Errorf("describeCodeSamples() = %q, want %q", got, want)
+	}
+	if got := describeCodeSamples(ops["createPet"], doc, nil); got != "" {
+		t.Errorf("expected no samples without languages, got %q", got)
+	}
+}
@@ -0,0 +1,115 @@
+// coerce.go
+package openapi2mcp
+
+import (
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// schemaTypes returns the types a schema allows, or nil if it doesn't restrict them.
+func schemaTypes(s *jsonschema.Schema) []string {
+	if s.Type != "" {
+		return []string{s.Type}
+	}
+	return s.Types
+}
+
+// coerceValue converts a string value to the type its schema expects: "123" to 123 for integers, "1.5" to 1.5 for
+// numbers, and "true" or "false" to booleans, after trimming surrounding whitespace. Strings stay strings where the
+// schema allows them, but are trimmed. Arrays have their elements converted. It returns false if v is unchanged.
+func coerceValue(s *jsonschema.Schema, v any) (any, bool) {
+	if s == nil {
+		return v, false
+	}
+	types := schemaTypes(s)
+	if len(types) == 0 {
+		return v, false
+	}
+	allows := func(t string) bool { return slices.Contains(types, t) }
+
+	switch v := v.(type) {
+	case string:
+		trimmed := strings.TrimSpace(v)
+		if allows("string") {
+			return trimmed, trimmed != v
+		}
+		if allows("integer") {
+			if n, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
+				return n, true
+			}
+		}
+		if allows("number") {
+			if f, err := strconv.ParseFloat(trimmed, 64); err == nil {
+				return f, true
+			}
+		}
+		if allows("boolean") {
+			switch strings.ToLower(trimmed) {
+			case "true":
+				return true, true
+			case "false":
+				return false, true
+			}
+		}
+	case []any:
+		if !allows("array") || s.Items == nil {
+			return v, false
+		}
+		var out []any
+		for i, item := range v {
+			c, ok := coerceValue(s.Items, item)
+			if !ok {
+				continue
+			}
+			if out == nil {
+				out = append([]any(nil), v...)
+			}
+			out[i] = c
+		}
+		if out != nil {
+			return out, true
+		}
+	}
+	return v, false
+}
+
+// coerceArguments converts stringly-typed parameter arguments to the types of their input schema properties,
+// e.g. "123" to 123 for integer parameters, and trims whitespace around string parameters. The requestBody is
+// left as it is. It returns the raw arguments unchanged if nothing was converted or they aren't a JSON object.
+func coerceArguments(schema *jsonschema.Schema, raw json.RawMessage) json.RawMessage {
+	var args map[string]json.RawMessage
+	if schema == nil || len(raw) == 0 || json.Unmarshal(raw, &args) != nil {
+		return raw
+	}
+	changed := false
+	for name, value := range args {
+		prop := schema.Properties[name]
+		if prop == nil || name == "requestBody" {
+			continue
+		}
+		var v any
+		if json.Unmarshal(value, &v) != nil {
+			continue
+		}
+		c, ok := coerceValue(prop, v)
+		if !ok {
+			continue
+		}
+		if encoded, err := json.Marshal(c); err == nil {
+			args[name] = encoded
+			changed = true
+		}
+	}
+	if !changed {
+		return raw
+	}
+	out, err := json.Marshal(args)
+	if err != nil {
+		return raw
+	}
+	return out
+}
@@ -0,0 +1,108 @@
+package openapi2mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestCoerceArguments(t *testing.T) {
+	schema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"limit":       {Type: "integer"},
+			"ratio":       {Type: "number"},
+			"active":      {Type: "boolean"},
+			"name":        {Type: "string"},
+			"ids":         {Type: "array", Items: &jsonschema.Schema{Type: "integer"}},
+			"page":        {Types: []string{"integer", "null"}},
+			"requestBody": {Type: "object"},
+		},
+	}
+	got := coerceArguments(schema, json.RawMessage(`{"limit": " 123 ", "ratio": "1.5", "active": "TRUE", "name": "  abc ", "ids": ["1", 2], "page": "3", "requestBody": {"limit": "7"}, "extra": "9"}`))
+	var args map[string]any
+	if err := json.Unmarshal(got, &args); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]any{
+		"limit":       float64(123),
+		"ratio":       1.5,
+		"active":      true,
+		"name":        "abc",
+		"ids":         []any{float64(1), float64(2)},
+		"page":        float64(3),
+		"requestBody": map[string]any{"limit": "7"},
+		"extra":       "9",
+	}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("coerceArguments() = %v, want %v", args, want)
+	}
+
+	// Values that can't be converted, and arguments that need no conversion, are left for validation
+	for _, raw := range []string{`{"limit": "many", "active": "yes"}`, `{"limit": 5, "name": "abc"}`, `not json`} {
+		if got := coerceArguments(schema, json.RawMessage(raw)); string(got) != raw {
+			t.Errorf("coerceArguments(%s) = %s, want it unchanged", raw, got)
+		}
+	}
+}
+
+func TestRegisterOpenAPITools_CoerceArguments(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+servers: [{url: "https://api.example.com"}]
+paths:
+  /items:
+    get:
+      operationId: listItems
+      parameters:
+        - {name: limit, in: query, schema: {type: integer}}
+        - {name: archived, in: query, schema: {type: boolean}}
+        - {name: status, in: query, schema: {type: string, enum: [open, closed]}}
+      responses: {"200": {description: ok}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	call := func(coerce bool) (*mcp.CallToolResult, string, error) {
+		var query string
+		server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+		RegisterOpenAPITools(server, ExtractOpenAPIOperations(doc), doc, &ToolGenOptions{
+			CoerceArguments: coerce,
+			RequestHandler: func(req *http.Request) (*http.Response, error) {
+				query = req.URL.RawQuery
+				return fakeResponse(200, "application/json", `[]`)(req)
+			},
+		})
+		serverTransport, clientTransport := mcp.NewInMemoryTransports()
+		if _, err := server.Connect(context.Background(), serverTransport, nil); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		cs, err := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil).Connect(context.Background(), clientTransport, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer cs.Close()
+		res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "listItems", Arguments: map[string]any{"limit": "10", "archived": "false", "status": " open "}})
+		return res, query, err
+	}
+
+	if res, _, err := call(false); err == nil && !res.IsError {
+		t.Errorf("expected stringly-typed arguments to be rejected without coercion")
+	}
+	res, query, err := call(true)
+	if err != nil || res.IsError {
+		t.Fatalf("unexpected error: %v %v", err, res)
+	}
+	for _, want := range []string{"limit=10", "archived=false", "status=open"} {
+		if !strings.Contains(query, want) {
+			t.Errorf("expected %s in the query, got %s", want, query)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+// compat.go
+package openapi2mcp
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Levels of compatibility issues.
+const (
+	CompatUnsupported = "unsupported" // the feature can't be used through the tool
+	CompatPartial     = "partial"     // the feature works with limitations
+)
+
+// CompatIssue is a feature of an operation that openapi-mcp cannot fully handle.
+type CompatIssue struct {
+	Level   string `json:"level"`   // CompatUnsupported or CompatPartial
+	Feature string `json:"feature"` // The feature, e.g. "request body multipart/form-data"
+	Detail  string `json:"detail"`  // What to expect when calling the tool
+}
+
+// CompatResult lists the compatibility issues of one operation.
+type CompatResult struct {
+	OperationID string        `json:"operation_id"`
+	Method      string        `json:"method"`
+	Path        string        `json:"path"`
+	Issues      []CompatIssue `json:"issues"`
+}
+
+// CompatReport summarizes the features of a spec that openapi-mcp cannot fully handle.
+type CompatReport struct {
+	Results     []CompatResult `json:"results"`     // Operations with at least one issue
+	Operations  int            `json:"operations"`  // Number of operations checked
+	Unsupported int            `json:"unsupported"` // Operations with at least one unsupported feature
+	Partial     int            `json:"partial"`     // Operations with only partially supported features
+}
+
+// OK reports whether every operation is fully supported.
+func (r *CompatReport) OK() bool {
+	return len(r.Results) == 0
+}
+
+// String renders the report as a human-readable summary.
+func (r *CompatReport) String() string {
+	var sb strings.Builder
+	for _, res := range r.Results {
+		sb.WriteString(fmt.Sprintf("%s (%s %s):\n", res.OperationID, strings.ToUpper(res.Method), res.Path))
+		for _, issue := range res.Issues {
+			sb.WriteString(fmt.Sprintf("  [%s] %s: %s\n", strings.ToUpper(issue.Level), issue.Feature, issue.Detail))
+		}
+	}
+	fullySupported := r.Operations - r.Unsupported - r.Partial
+	sb.WriteString(fmt.Sprintf("\n%d operations: %d fully supported, %d partially supported, %d with unsupported features\n", r.Operations, fullySupported, r.Partial, r.Unsupported))
+	return sb.String()
+}
+
+// CheckCompatibility lists, per operation, the features of the spec that openapi-mcp cannot fully handle today:
+// request and response media types it doesn't parse, parameters it can't send as documented, oneOf/anyOf
+// request bodies, callbacks, and security schemes it has no credentials for. Use it to know what to expect
+// before serving a spec.
+//
+//	report := openapi2mcp.CheckCompatibility(doc, openapi2mcp.ExtractOpenAPIOperations(doc))
+//	fmt.Print(report)
+func CheckCompatibility(doc *openapi3.T, ops []OpenAPIOperation) *CompatReport {
+	report := &CompatReport{Operations: len(ops)}
+	for _, op := range ops {
+		var issues []CompatIssue
+		issues = append(issues, parameterCompatIssues(op.Parameters)...)
+		issues = append(issues, requestBodyCompatIssues(op.RequestBody)...)
+		issues = append(issues, responseCompatIssues(op.Responses)...)
+		if len(op.Callbacks) > 0 {
+			issues = append(issues, CompatIssue{
+				Level:   CompatPartial,
+				Feature: "callbacks " + strings.Join(slices.Sorted(maps.Keys(op.Callbacks)), ", "),
+				Detail:  "callbacks are only received with a callback receiver (--callback-addr), through the await_callback tool",
+			})
+		}
+		issues = append(issues, securityCompatIssues(operationSecurity(op, doc), doc)...)
+		if len(issues) == 0 {
+			continue
+		}
+
+		report.Results = append(report.Results, CompatResult{OperationID: op.OperationID, Method: op.Method, Path: op.Path, Issues: issues})
+		if slices.ContainsFunc(issues, func(issue CompatIssue) bool { return issue.Level == CompatUnsupported }) {
+			report.Unsupported++
+		} else {
+			report.Partial++
+		}
+	}
+	return report
+}
+
+// parameterCompatIssues reports parameters that aren't offered as arguments or can't be sent as documented.
+func parameterCompatIssues(params openapi3.Parameters) []CompatIssue {
+	var issues []CompatIssue
+	for _, paramRef := range params {
+		if paramRef == nil || paramRef.Value == nil {
+			continue
+		}
+		p := paramRef.Value
+		feature := fmt.Sprintf("%s parameter %s", p.In, p.Name)
+		switch {
+		case p.In != "query" && p.In != "path" && p.In != "header" && p.In != "cookie":
+			issues = append(issues, CompatIssue{Level: CompatUnsupported, Feature: feature, Detail: "the parameter location isn't supported; the value is never sent"})
+		case p.Schema == nil || p.Schema.Value == nil:
+			issues = append(issues, CompatIssue{Level: CompatUnsupported, Feature: feature, Detail: "parameters described by content instead of a schema aren't offered as arguments"})
+		case p.Style == openapi3.SerializationDeepObject || p.Schema.Value.Type.Is("object"):
+			issues = append(issues, CompatIssue{Level: CompatPartial, Feature: feature, Detail: "object values are sent as a single string, not serialized per style (e.g. deepObject)"})
+		case p.Schema.Value.Type.Is("array") && p.In == "query":
+			issues = append(issues, CompatIssue{Level: CompatPartial, Feature: feature, Detail: "array values are sent as a single string, not as repeated or comma-separated values"})
+		}
+	}
+	return issues
+}
+
+// requestBodyCompatIssues reports request bodies the tool can't send, and oneOf/anyOf bodies it only partly describes.
+func requestBodyCompatIssues(requestBody *openapi3.RequestBodyRef) []CompatIssue {
+	if requestBody == nil || requestBody.Value == nil || len(requestBody.Value.Content) == 0 {
+		return nil
+	}
+	content := requestBody.Value.Content
+	if !acceptsJSON(content) && patchRequestType(content) == "" && ndjsonRequestType(content) == "" && binaryRequestType(content) == "" {
+		return []CompatIssue{{
+			Level:   CompatUnsupported,
+			Feature: "request body " + strings.Join(slices.Sorted(maps.Keys(content)), ", "),
+			Detail:  "only JSON, JSON (Merge) Patch, NDJSON, and raw binary request bodies are supported; the tool takes no requestBody argument",
+		}}
+	}
+
+	mt := getContentByType(content, "application/json")
+	if mt == nil {
+		mt = getContentByType(content, "application/vnd.api+json")
+	}
+	if mt != nil && mt.Schema != nil && usesAlternatives(mt.Schema, map[*openapi3.Schema]bool{}) {
+		return []CompatIssue{{
+			Level:   CompatPartial,
+			Feature: "request body oneOf/anyOf",
+			Detail:  "alternative schemas have basic support only; the model may not be guided to a valid alternative",
+		}}
+	}
+	return nil
+}
+
+// usesAlternatives reports whether a schema or one of its subschemas uses oneOf or anyOf.
+func usesAlternatives(ref *openapi3.SchemaRef, seen map[*openapi3.Schema]bool) bool {
+	if ref == nil || ref.Value == nil || seen[ref.Value] {
+		return false
+	}
+	s := ref.Value
+	seen[s] = true
+	if len(s.OneOf) > 0 || len(s.AnyOf) > 0 {
+		return true
+	}
+	for _, sub := range s.AllOf {
+		if usesAlternatives(sub, seen) {
+			return true
+		}
+	}
+	for _, prop := range s.Properties {
+		if usesAlternatives(prop, seen) {
+			return true
+		}
+	}
+	return usesAlternatives(s.Items, seen)
+}
+
+// responseCompatIssues reports structured response media types that are returned as binary content instead of
+// being parsed, such as XML. Images, documents, and other binary types are expected to be returned as binary.
+func responseCompatIssues(responses *openapi3.Responses) []CompatIssue {
+	if responses == nil {
+		return nil
+	}
+	types := map[string]bool{}
+	for _, respRef := range responses.Map() {
+		if respRef == nil || respRef.Value == nil {
+			continue
+		}
+		for contentType := range respRef.Value.Content {
+			base := baseMediaType(contentType)
+			if base == "application/json" || base == "application/vnd.api+json" || base == "application/graphql-response+json" {
+				continue
+			}
+			if strings.HasSuffix(base, "+json") || (strings.HasSuffix(base, "/xml") || strings.HasSuffix(base, "+xml")) && !isHTML(base) {
+				types[base] = true
+			}
+		}
+	}
+	if len(types) == 0 {
+		return nil
+	}
+	return []CompatIssue{{
+		Level:   CompatPartial,
+		Feature: "response " + strings.Join(slices.Sorted(maps.Keys(types)), ", "),
+		Detail:  "these responses are returned as binary content, not parsed or shown as text",
+	}}
+}
+
+// securityCompatIssues reports security schemes openapi-mcp has no or only limited credentials for. Schemes
+// without credentials only make an operation unusable if every alternative requirement includes one.
+func securityCompatIssues(security openapi3.SecurityRequirements, doc *openapi3.T) []CompatIssue {
+	if len(security) == 0 || allowsAnonymous(security) || doc == nil || doc.Components == nil {
+		return nil
+	}
+	schemeType := func(name string) string {
+		if ref := doc.Components.SecuritySchemes[name]; ref != nil && ref.Value != nil {
+			return ref.Value.Type
+		}
+		return ""
+	}
+	hasClientCredentialsFlow := func(name string) bool {
+		ref := doc.Components.SecuritySchemes[name]
+		return ref != nil && ref.Value != nil && ref.Value.Flows != nil && ref.Value.Flows.ClientCredentials != nil
+	}
+	noCredentials := func(name string) bool {
+		t := schemeType(name)
+		return t == "openIdConnect" || t == "mutualTLS"
+	}
+
+	level := CompatUnsupported
+	names := map[string]bool{}
+	for _, secReq := range security {
+		if !slices.ContainsFunc(slices.Collect(maps.Keys(secReq)), noCredentials) {
+			level = CompatPartial
+		}
+		for name := range secReq {
+			names[name] = true
+		}
+	}
+
+	var issues []CompatIssue
+	for _, name := range slices.Sorted(maps.Keys(names)) {
+		feature := fmt.Sprintf("security scheme %s (%s)", name, schemeType(name))
+		switch {
+		case schemeType(name) == "oauth2" && hasClientCredentialsFlow(name):
+			issues = append(issues, CompatIssue{Level: CompatPartial, Feature: feature, Detail: "only the client credentials flow is run, with CLIENT_ID and CLIENT_SECRET, and the authorization code and device flows with --oauth-login; set BEARER_TOKEN for access tokens of other flows"})
+		case schemeType(name) == "oauth2":
+			issues = append(issues, CompatIssue{Level: CompatPartial, Feature: feature, Detail: "no OAuth flow is run unless the user signs in with --oauth-login; otherwise set BEARER_TOKEN to an access token obtained separately, or CLIENT_ID, CLIENT_SECRET, and TOKEN_URL for the client credentials flow"})
+		case noCredentials(name):
+			issues = append(issues, CompatIssue{Level: level, Feature: feature, Detail: "no credentials are sent for this scheme"})
+		}
+	}
+	return issues
+}
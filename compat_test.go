@@ -0,0 +1,74 @@
+package openapi2mcp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+components:
+  securitySchemes:
+    oidc: {type: openIdConnect, openIdConnectUrl: "https://id.example.com/.well-known/openid-configuration"}
+    key: {type: apiKey, in: header, name: X-Key}
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      parameters:
+        - {name: filter, in: query, style: deepObject, schema: {type: object}}
+        - {name: tags, in: query, schema: {type: array, items: {type: string}}}
+      responses:
+        "200": {description: ok, content: {application/json: {schema: {type: object}}, application/xml: {schema: {type: object}}}}
+    post:
+      operationId: uploadPet
+      security: [{oidc: []}]
+      requestBody:
+        content:
+          multipart/form-data: {schema: {type: object}}
+      responses: {"201": {description: created}}
+  /orders:
+    post:
+      operationId: createOrder
+      security: [{oidc: []}, {key: []}]
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                payment: {oneOf: [{type: string}, {type: integer}]}
+      responses: {"201": {description: created, content: {text/plain: {schema: {type: string}}}}}
+  /health:
+    get:
+      operationId: health
+      responses: {"200": {description: ok, content: {application/json: {schema: {type: object}}}}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	report := CheckCompatibility(doc, ExtractOpenAPIOperations(doc))
+	if report.Operations != 4 || report.Unsupported != 1 || report.Partial != 2 || report.OK() {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+
+	issues := map[string][]string{}
+	for _, res := range report.Results {
+		for _, issue := range res.Issues {
+			issues[res.OperationID] = append(issues[res.OperationID], issue.Level+" "+issue.Feature)
+		}
+	}
+	for op, want := range map[string]string{
+		"listPets":    "partial query parameter filter, partial query parameter tags, partial response application/xml",
+		"uploadPet":   "unsupported request body multipart/form-data, unsupported security scheme oidc (openIdConnect)",
+		"createOrder": "partial request body oneOf/anyOf, partial security scheme oidc (openIdConnect)",
+	} {
+		if got := strings.Join(issues[op], ", "); got != want {
+			t.Errorf("%s: issues = %s, want %s", op, got, want)
+		}
+	}
+	if out := report.String(); !strings.Contains(out, "4 operations: 1 fully supported, 2 partially supported, 1 with unsupported features") {
+		t.Errorf("unexpected summary: %s", out)
+	}
+}
@@ -0,0 +1,125 @@
+// concurrency.go
+package openapi2mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// versionFields are the names of request body fields treated as the version of the resource, case-insensitively.
+var versionFields = []string{"version", "etag", "revision"}
+
+// maxConcurrencyTokenBytes limits the response body read when fetching the current version of a resource.
+const maxConcurrencyTokenBytes = 1 << 20
+
+// concurrencyControl describes how a PUT or PATCH operation guards against overwriting concurrent changes.
+type concurrencyControl struct {
+	ifMatch      string // name of the documented If-Match header parameter, if any
+	versionField string // name of the top-level request body field holding the resource's version, if any
+}
+
+// operationConcurrencyControl returns the concurrency control documented by op, or nil if op is not a PUT or PATCH
+// operation documenting an If-Match header or version field, or its resource can't be fetched with a GET on its path.
+func operationConcurrencyControl(op OpenAPIOperation, doc *openapi3.T) *concurrencyControl {
+	if method := strings.ToUpper(op.Method); method != http.MethodPut && method != http.MethodPatch {
+		return nil
+	}
+	if doc == nil || doc.Paths == nil || doc.Paths.Value(op.Path) == nil || doc.Paths.Value(op.Path).Get == nil {
+		return nil
+	}
+
+	cc := &concurrencyControl{}
+	for _, paramRef := range op.Parameters {
+		if paramRef != nil && paramRef.Value != nil && paramRef.Value.In == "header" && strings.EqualFold(paramRef.Value.Name, "If-Match") {
+			cc.ifMatch = paramRef.Value.Name
+		}
+	}
+	if op.RequestBody != nil && op.RequestBody.Value != nil {
+		if mt := getContentByType(op.RequestBody.Value.Content, "application/json"); mt != nil && mt.Schema != nil && mt.Schema.Value != nil {
+			for name := range mt.Schema.Value.Properties {
+				for _, field := range versionFields {
+					if strings.EqualFold(name, field) {
+						cc.versionField = name
+					}
+				}
+			}
+		}
+	}
+	if cc.ifMatch == "" && cc.versionField == "" {
+		return nil
+	}
+	return cc
+}
+
+// fillConcurrencyToken fetches the current ETag or version of the resource req updates, with a GET on its URL, and
+// sets the If-Match header or the version field of body the agent left out. It returns the body to send and a note
+// for the result listing the values set, or "" if none was. Failures to fetch them leave the request unchanged.
+func fillConcurrencyToken(req *http.Request, body []byte, cc *concurrencyControl, requestHandler func(*http.Request) (*http.Response, error)) ([]byte, string) {
+	needIfMatch := cc.ifMatch != "" && req.Header.Get(cc.ifMatch) == ""
+	var fields map[string]any
+	needVersion := false
+	if cc.versionField != "" && json.Unmarshal(body, &fields) == nil && fields != nil {
+		_, sent := fields[cc.versionField]
+		needVersion = !sent
+	}
+	if !needIfMatch && !needVersion {
+		return body, ""
+	}
+
+	getReq := req.Clone(req.Context())
+	getReq.Method = http.MethodGet
+	getReq.Body, getReq.GetBody, getReq.ContentLength = http.NoBody, nil, 0
+	getReq.Header.Del("Content-Type")
+	if cc.ifMatch != "" {
+		getReq.Header.Del(cc.ifMatch)
+	}
+	resp, err := requestHandler(getReq)
+	if err != nil {
+		return body, ""
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return body, ""
+	}
+
+	var notes []string
+	if etag := resp.Header.Get("ETag"); needIfMatch && etag != "" {
+		req.Header.Set(cc.ifMatch, etag)
+		notes = append(notes, fmt.Sprintf("%s: %s", cc.ifMatch, etag))
+	}
+	if needVersion {
+		var current map[string]any
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, maxConcurrencyTokenBytes))
+		if json.Unmarshal(data, &current) == nil && current[cc.versionField] != nil {
+			fields[cc.versionField] = current[cc.versionField]
+			if updated, err := json.Marshal(fields); err == nil {
+				body = updated
+				req.Body = io.NopCloser(bytes.NewReader(body))
+				req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+				req.ContentLength = int64(len(body))
+				version, _ := json.Marshal(current[cc.versionField])
+				notes = append(notes, fmt.Sprintf("requestBody.%s: %s", cc.versionField, version))
+			}
+		}
+	}
+	if len(notes) == 0 {
+		return body, ""
+	}
+	return body, "Sent the current version of the resource, fetched before the update:\n" + strings.Join(notes, "\n")
+}
+
+// preconditionFailedText explains a 412 response to an update guarded by If-Match or a version field.
+func preconditionFailedText(filled bool) string {
+	if filled {
+		return "PRECONDITION FAILED: the resource changed between fetching its current version and updating it. " +
+			"Fetch it again and reapply your changes to its current state instead of repeating the same call."
+	}
+	return "PRECONDITION FAILED: the If-Match or version value you sent is no longer current. " +
+		"Fetch the resource again and send its current ETag or version with your changes, instead of repeating the same call."
+}
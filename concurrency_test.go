@@ -0,0 +1,176 @@
+package openapi2mcp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestToolHandler_AutoIfMatch(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /items/{id}:
+    parameters:
+      - {name: id, in: path, required: true, schema: {type: string}}
+    get:
+      operationId: getItem
+      responses: {"200": {description: ok}}
+    put:
+      operationId: updateItem
+      parameters:
+        - {name: If-Match, in: header, schema: {type: string}}
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name: {type: string}
+                version: {type: integer}
+      responses: {"200": {description: ok}, "412": {description: precondition failed}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var op OpenAPIOperation
+	for _, o := range ExtractOpenAPIOperations(doc) {
+		if o.OperationID == "updateItem" {
+			op = o
+		}
+	}
+
+	var sent []string
+	putStatus := http.StatusOK
+	opts := &ToolGenOptions{
+		AutoIfMatch: true,
+		RequestHandler: func(req *http.Request) (*http.Response, error) {
+			body, _ := io.ReadAll(req.Body)
+			sent = append(sent, req.Method+" "+req.URL.Path+" "+req.Header.Get("If-Match")+" "+string(body))
+			if req.Method == http.MethodGet {
+				return &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Etag": []string{`"v7"`}, "Content-Type": []string{"application/json"}},
+					Body: io.NopCloser(strings.NewReader(`{"name": "old", "version": 7}`)), Request: req}, nil
+			}
+			return &http.Response{StatusCode: putStatus, Header: http.Header{"Content-Type": []string{"application/json"}}, Body: io.NopCloser(strings.NewReader(`{}`)), Request: req}, nil
+		},
+	}
+	handler := toolHandler("updateItem", op, doc, jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+
+	res, _, err := handler(context.Background(), nil, map[string]any{"id": "1", "requestBody": map[string]any{"name": "new"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{`GET /items/1  `, `PUT /items/1 "v7" {"name":"new","version":7}`}
+	if strings.Join(sent, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected requests:\n%s\nwant:\n%s", strings.Join(sent, "\n"), strings.Join(want, "\n"))
+	}
+	if text := res.Content[len(res.Content)-1].(*mcp.TextContent).Text; !strings.Contains(text, "If-Match: \"v7\"") || !strings.Contains(text, "requestBody.version: 7") {
+		t.Errorf("expected the fetched values in the result, got: %s", text)
+	}
+
+	// Values sent by the agent are kept, and a 412 explains how to recover
+	sent, putStatus = nil, http.StatusPreconditionFailed
+	res, _, err = handler(context.Background(), nil, map[string]any{"id": "1", "If-Match": `"v6"`, "requestBody": map[string]any{"name": "new", "version": 6}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sent) != 1 || sent[0] != `PUT /items/1 "v6" {"name":"new","version":6}` {
+		t.Errorf("expected only the update with the agent's values, got %q", sent)
+	}
+	if text := res.Content[len(res.Content)-1].(*mcp.TextContent).Text; !strings.Contains(text, "PRECONDITION FAILED: the If-Match or version value you sent is no longer current") {
+		t.Errorf("expected a recovery hint, got: %s", text)
+	}
+}
+
+func TestToolHandler_AutoIfMatchWithinLimits(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /items/{id}:
+    parameters:
+      - {name: id, in: path, required: true, schema: {type: string}}
+    get:
+      operationId: getItem
+      responses: {"200": {description: ok}}
+    put:
+      operationId: updateItem
+      parameters:
+        - {name: If-Match, in: header, schema: {type: string}}
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name: {type: string}
+                version: {type: integer}
+      responses: {"200": {description: ok}, "412": {description: precondition failed}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var op OpenAPIOperation
+	for _, o := range ExtractOpenAPIOperations(doc) {
+		if o.OperationID == "updateItem" {
+			op = o
+		}
+	}
+
+	var mu sync.Mutex
+	var gets int
+	putStarted, releasePut := make(chan struct{}), make(chan struct{})
+	opts := &ToolGenOptions{
+		AutoIfMatch:       true,
+		Telemetry:         true,
+		ConcurrencyLimits: []ConcurrencyLimit{{OperationID: "updateItem", Max: 1}},
+		RequestHandler: func(req *http.Request) (*http.Response, error) {
+			if req.Method == http.MethodGet {
+				mu.Lock()
+				gets++
+				mu.Unlock()
+				return &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Etag": []string{`"v7"`}, "Content-Type": []string{"application/json"}},
+					Body: io.NopCloser(strings.NewReader(`{"name": "old", "version": 7}`)), Request: req}, nil
+			}
+			close(putStarted)
+			<-releasePut
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Content-Type": []string{"application/json"}}, Body: io.NopCloser(strings.NewReader(`{}`)), Request: req}, nil
+		},
+	}
+	rt := newServerRuntime()
+	handler := toolHandler("updateItem", op, doc, jsonschema.Schema{}, []string{"http://example.com"}, opts, rt)
+	args := map[string]any{"id": "1", "requestBody": map[string]any{"name": "new"}}
+
+	// A call rejected as busy doesn't fetch the ETag
+	first := make(chan *mcp.CallToolResult)
+	go func() {
+		res, _, _ := handler(context.Background(), nil, args)
+		first <- res
+	}()
+	<-putStarted
+	res, _, err := handler(context.Background(), nil, args)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mu.Lock()
+	busyGets := gets
+	mu.Unlock()
+	if !res.IsError || busyGets != 1 {
+		t.Errorf("expected the busy call to be rejected before fetching the ETag, got %d GET requests: %s", busyGets, resultText(t, res))
+	}
+	close(releasePut)
+
+	// The fetch is counted in the call's telemetry and the server stats
+	res = <-first
+	if text := res.Content[len(res.Content)-1].(*mcp.TextContent).Text; !strings.Contains(text, "prefetches 1") {
+		t.Errorf("expected the prefetch in the telemetry, got: %s", text)
+	}
+	if stats := rt.stats.snapshot()["updateItem"]; stats.Prefetches != 1 {
+		t.Errorf("expected one prefetch in the stats, got %+v", stats)
+	}
+}
@@ -0,0 +1,186 @@
+// contract.go
+package openapi2mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// ContractTestOptions configures RunContractTest.
+type ContractTestOptions struct {
+	Operations     []string        // operationIds to call, of any method (default: all GET operations)
+	BaseURL        string          // Base URL of the API under test (default: OPENAPI_BASE_URL or the spec's servers)
+	Timeout        time.Duration   // Timeout per call (default 10s)
+	ToolGenOptions *ToolGenOptions // Options used to register the tools; Mock and DryRun are ignored
+}
+
+// ContractResult is the outcome of calling one operation during a contract test.
+type ContractResult struct {
+	OperationID string        `json:"operation_id"`
+	Method      string        `json:"method"`
+	URL         string        `json:"url,omitempty"`
+	Status      int           `json:"status,omitempty"`
+	Duration    time.Duration `json:"duration"`
+	Drift       []string      `json:"drift,omitempty"` // Differences between the live response and the spec
+	Error       string        `json:"error,omitempty"` // Set if the call did not produce a response
+}
+
+// ContractReport summarizes a contract test run.
+type ContractReport struct {
+	Results []ContractResult `json:"results"`
+	Passed  int              `json:"passed"`
+	Drifted int              `json:"drifted"`
+	Failed  int              `json:"failed"`
+}
+
+// OK reports whether every operation responded as documented.
+func (r *ContractReport) OK() bool {
+	return r.Drifted == 0 && r.Failed == 0
+}
+
+// String renders the report as a human-readable summary.
+func (r *ContractReport) String() string {
+	var sb strings.Builder
+	for _, res := range r.Results {
+		switch {
+		case res.Error != "":
+			sb.WriteString(fmt.Sprintf("[FAIL]  %s: %s\n", res.OperationID, res.Error))
+		case len(res.Drift) > 0:
+			sb.WriteString(fmt.Sprintf("[DRIFT] %s: %s %s -> HTTP %d\n", res.OperationID, strings.ToUpper(res.Method), res.URL, res.Status))
+			for _, d := range res.Drift {
+				sb.WriteString("  - " + d + "\n")
+			}
+		default:
+			sb.WriteString(fmt.Sprintf("[OK]    %s: %s %s -> HTTP %d in %s\n", res.OperationID, strings.ToUpper(res.Method), res.URL, res.Status, res.Duration))
+		}
+	}
+	sb.WriteString(fmt.Sprintf("\n%d operations: %d passed, %d drifted, %d failed\n", len(r.Results), r.Passed, r.Drifted, r.Failed))
+	return sb.String()
+}
+
+// RunContractTest calls the selected operations against a live API, with example arguments generated from
+// each tool's input schema, and reports spec drift: undocumented status codes and response bodies that
+// don't match the documented schema. Unlike SelfTestOpenAPIMCPLive, operations listed in Operations are
+// called whatever their method, so only list modifying operations when testing against a disposable environment.
+//
+// Example usage for RunContractTest:
+//
+//	report, err := openapi2mcp.RunContractTest(ctx, doc, ops, &openapi2mcp.ContractTestOptions{BaseURL: "https://staging.example.com"})
+//	if err != nil { log.Fatal(err) }
+//	fmt.Print(report)
+func RunContractTest(ctx context.Context, doc *openapi3.T, ops []OpenAPIOperation, opts *ContractTestOptions) (*ContractReport, error) {
+	if opts == nil {
+		opts = &ContractTestOptions{}
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	var selected []OpenAPIOperation
+	if len(opts.Operations) > 0 {
+		for _, id := range opts.Operations {
+			i := slices.IndexFunc(ops, func(op OpenAPIOperation) bool { return op.OperationID == id })
+			if i < 0 {
+				return nil, fmt.Errorf("contract test: unknown operation %q", id)
+			}
+			selected = append(selected, ops[i])
+		}
+	} else {
+		for _, op := range ops {
+			if strings.EqualFold(op.Method, http.MethodGet) {
+				selected = append(selected, op)
+			}
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("contract test: no operations to call")
+	}
+
+	toolOpts := ToolGenOptions{}
+	if opts.ToolGenOptions != nil {
+		toolOpts = *opts.ToolGenOptions
+	}
+	if opts.BaseURL != "" {
+		toolOpts.BaseURL = opts.BaseURL
+	}
+	session, err := newProbeSession(ctx, doc, selected, &toolOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	report := &ContractReport{}
+	for _, op := range selected {
+		current, args, res, err := session.call(ctx, op, timeout)
+		result := ContractResult{
+			OperationID: op.OperationID,
+			Method:      op.Method,
+			URL:         current.url,
+			Status:      current.status,
+			Duration:    current.duration,
+		}
+		switch {
+		case args == nil:
+			result.Error = err.Error()
+		case current.err != nil:
+			result.Error = current.err.Error()
+		case current.status == 0 && err != nil:
+			result.Error = err.Error()
+		case current.status == 0:
+			result.Error = "request was not sent: " + liveResultText(res)
+		default:
+			result.Drift = contractDrift(op, current.status, current.body)
+		}
+
+		switch {
+		case result.Error != "":
+			report.Failed++
+		case len(result.Drift) > 0:
+			report.Drifted++
+		default:
+			report.Passed++
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report, nil
+}
+
+// contractDrift compares a live response with the operation's documented responses.
+func contractDrift(op OpenAPIOperation, status int, body []byte) []string {
+	var drift []string
+	if !statusDocumented(op, status) {
+		drift = append(drift, fmt.Sprintf("status %d is not documented (documented: %s)", status, documentedStatuses(op)))
+	}
+	return append(drift, validateResponseBody(op, status, body)...)
+}
+
+// statusDocumented reports whether the operation documents the status code, a matching range (e.g. 4XX), or a default response.
+func statusDocumented(op OpenAPIOperation, status int) bool {
+	if op.Responses == nil {
+		return false
+	}
+	return op.Responses.Value(strconv.Itoa(status)) != nil ||
+		op.Responses.Value(strconv.Itoa(status/100)+"XX") != nil ||
+		op.Responses.Default() != nil
+}
+
+// documentedStatuses lists the operation's documented response codes.
+func documentedStatuses(op OpenAPIOperation) string {
+	if op.Responses == nil || op.Responses.Len() == 0 {
+		return "none"
+	}
+	var codes []string
+	for code := range op.Responses.Map() {
+		codes = append(codes, code)
+	}
+	slices.Sort(codes)
+	return strings.Join(codes, ", ")
+}
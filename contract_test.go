@@ -0,0 +1,53 @@
+package openapi2mcp
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRunContractTest(t *testing.T) {
+	var urls []string
+	handler := func(req *http.Request) (*http.Response, error) {
+		urls = append(urls, req.Method+" "+req.URL.String())
+		switch req.Method + " " + req.URL.Path {
+		case "GET /v2/pets":
+			return fakeResponse(200, "application/json", `{"id":1,"name":"Rex"}`)(req)
+		case "GET /v2/pets/123":
+			return fakeResponse(200, "application/json", `{"id":"123"}`)(req)
+		}
+		return fakeResponse(418, "application/json", `{}`)(req)
+	}
+
+	report, err := RunContractTest(context.Background(), minimalOpenAPIDoc(), liveTestOperations(), &ContractTestOptions{
+		BaseURL:        "http://staging.example.com/v2",
+		Operations:     []string{"listPets", "getPet", "createPet"},
+		ToolGenOptions: &ToolGenOptions{RequestHandler: handler, ConfirmDangerousActions: true},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(urls) != 3 || !strings.HasPrefix(urls[0], "GET http://staging.example.com/v2/pets") {
+		t.Errorf("expected all listed operations to be called at the base URL, got %v", urls)
+	}
+	if report.Passed != 1 || report.Drifted != 2 || report.Failed != 0 || report.OK() {
+		t.Errorf("unexpected report counts: %+v", report)
+	}
+
+	getPet := report.Results[1]
+	if getPet.OperationID != "getPet" || len(getPet.Drift) == 0 {
+		t.Errorf("expected schema drift for getPet, got %+v", getPet)
+	}
+	createPet := report.Results[2]
+	if len(createPet.Drift) == 0 || !strings.Contains(createPet.Drift[0], "status 418 is not documented") {
+		t.Errorf("expected undocumented status drift for createPet, got %+v", createPet)
+	}
+	if text := report.String(); !strings.Contains(text, "[DRIFT] getPet") || !strings.Contains(text, "1 passed, 2 drifted, 0 failed") {
+		t.Errorf("unexpected report text:\n%s", text)
+	}
+
+	if _, err := RunContractTest(context.Background(), minimalOpenAPIDoc(), liveTestOperations(), &ContractTestOptions{Operations: []string{"nope"}}); err == nil {
+		t.Error("expected error for unknown operation")
+	}
+}
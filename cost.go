@@ -0,0 +1,131 @@
+// cost.go
+package openapi2mcp
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// costLevels are the levels of OperationCost, from the least to the most costly.
+var costLevels = []string{"low", "medium", "high", "critical"}
+
+// costMetaKey is the tool _meta key carrying the cost level of the operation.
+const costMetaKey = "openapi-mcp/cost"
+
+// OperationCost is the cost or blast radius of calling an operation, e.g. "high" for charging a card or "critical"
+// for deleting a tenant, which the method alone doesn't tell. It is set by ToolGenOptions.OperationCosts or the
+// operation's x-mcp-cost extension, either a level or an object with level and reason:
+//
+//	x-mcp-cost: {level: high, reason: Charges the customer's card}
+type OperationCost struct {
+	Level  string `json:"level" yaml:"level"`                       // low, medium, high, or critical
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"` // shown to the model, e.g. "Sends email to all users"
+}
+
+// costRank returns the position of level among costLevels, counting from 1, or 0 if it isn't one.
+func costRank(level string) int {
+	return slices.Index(costLevels, strings.ToLower(level)) + 1
+}
+
+// ValidCostLevel reports whether level is a level of OperationCost.
+func ValidCostLevel(level string) bool {
+	return costRank(level) > 0
+}
+
+// operationCost returns the cost of calling the operation, from the options or else the x-mcp-cost extension, or
+// the zero value if none is declared.
+func operationCost(opts *ToolGenOptions, op OpenAPIOperation, doc *openapi3.T) OperationCost {
+	if opts != nil {
+		if cost, ok := opts.OperationCosts[op.OperationID]; ok && ValidCostLevel(cost.Level) {
+			return cost
+		}
+	}
+	if doc == nil || doc.Paths == nil {
+		return OperationCost{}
+	}
+	item := doc.Paths.Value(op.Path)
+	if item == nil {
+		return OperationCost{}
+	}
+	o := item.GetOperation(strings.ToUpper(op.Method))
+	if o == nil {
+		return OperationCost{}
+	}
+	var cost OperationCost
+	switch v := o.Extensions["x-mcp-cost"].(type) {
+	case string:
+		cost.Level = v
+	case map[string]any:
+		cost.Level, _ = v["level"].(string)
+		cost.Reason, _ = v["reason"].(string)
+	}
+	if !ValidCostLevel(cost.Level) {
+		return OperationCost{}
+	}
+	return cost
+}
+
+// costConfirmationRequired reports whether calls of an operation with the cost need confirmation, being at or above
+// the ToolGenOptions.ConfirmCost level.
+func costConfirmationRequired(opts *ToolGenOptions, cost OperationCost) bool {
+	return opts != nil && ValidCostLevel(opts.ConfirmCost) && costRank(cost.Level) >= costRank(opts.ConfirmCost)
+}
+
+// confirmationRequired reports whether calls of op need {"__confirmed": true}: PUT, POST, and DELETE calls with
+// ToolGenOptions.ConfirmDangerousActions, and calls at or above the ToolGenOptions.ConfirmCost level.
+func confirmationRequired(opts *ToolGenOptions, op OpenAPIOperation, doc *openapi3.T) bool {
+	method := strings.ToUpper(op.Method)
+	return opts != nil && opts.ConfirmDangerousActions && (method == "PUT" || method == "POST" || method == "DELETE") ||
+		costConfirmationRequired(opts, operationCost(opts, op, doc))
+}
+
+// describeCost tells the model how costly or far-reaching a call is, so that it weighs it before making it.
+func describeCost(cost OperationCost, confirm bool) string {
+	rank := costRank(cost.Level)
+	if rank == 0 {
+		return ""
+	}
+	level := strings.ToLower(cost.Level)
+	desc := "\n\nCOST: " + strings.ToUpper(level[:1]) + level[1:]
+	if cost.Reason != "" {
+		desc += " — " + strings.TrimSuffix(cost.Reason, ".")
+	}
+	desc += "."
+	switch {
+	case confirm:
+		desc += " Calls must be confirmed with {\"__confirmed\": true}; ask the user first."
+	case rank >= costRank("high"):
+		desc += " Only call it when the user asked for exactly this."
+	}
+	return desc
+}
+
+// costAnnotations records the cost level in the tool's _meta field and marks high and critical operations as
+// destructive, so that clients can ask the user before calling them.
+func costAnnotations(tool *mcp.Tool, cost OperationCost) {
+	rank := costRank(cost.Level)
+	if rank == 0 {
+		return
+	}
+	if tool.Meta == nil {
+		tool.Meta = mcp.Meta{}
+	}
+	tool.Meta[costMetaKey] = strings.ToLower(cost.Level)
+	if rank >= costRank("high") && tool.Annotations != nil {
+		destructive := true
+		tool.Annotations.DestructiveHint = &destructive
+	}
+}
+
+// costConfirmationText asks for confirmation of a call of an operation whose cost is at or above the threshold.
+func costConfirmationText(name string, cost OperationCost) string {
+	impact := strings.ToLower(cost.Level)
+	if cost.Reason != "" {
+		impact += " — " + cost.Reason
+	}
+	return fmt.Sprintf("⚠️  CONFIRMATION REQUIRED\n\nAction: %s\nCost: %s\nThe call has not been made. Ask the user whether to proceed.\n\nTo confirm, retry the call with {\"__confirmed\": true} added to your arguments.", name, impact)
+}
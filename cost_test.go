@@ -0,0 +1,124 @@
+package openapi2mcp
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const costSpec = `openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /charges:
+    get:
+      operationId: listCharges
+      responses: {"200": {description: ok}}
+    post:
+      operationId: chargeCard
+      x-mcp-cost: {level: high, reason: Charges the customer's card}
+      responses: {"201": {description: created}}
+  /emails:
+    get:
+      operationId: sendNewsletter
+      x-mcp-cost: critical
+      responses: {"200": {description: ok}}
+`
+
+func TestOperationCost(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(costSpec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ops := ExtractOpenAPIOperations(doc)
+	find := func(id string) OpenAPIOperation {
+		for _, op := range ops {
+			if op.OperationID == id {
+				return op
+			}
+		}
+		t.Fatalf("operation %s not found", id)
+		return OpenAPIOperation{}
+	}
+
+	if cost := operationCost(nil, find("chargeCard"), doc); cost.Level != "high" || cost.Reason != "Charges the customer's card" {
+		t.Errorf("expected the x-mcp-cost object, got %+v", cost)
+	}
+	if cost := operationCost(nil, find("sendNewsletter"), doc); cost.Level != "critical" {
+		t.Errorf("expected the x-mcp-cost level, got %+v", cost)
+	}
+	opts := &ToolGenOptions{OperationCosts: map[string]OperationCost{"chargeCard": {Level: "medium"}}}
+	if cost := operationCost(opts, find("chargeCard"), doc); cost.Level != "medium" {
+		t.Errorf("expected the options to override the extension, got %+v", cost)
+	}
+	if cost := operationCost(opts, find("listCharges"), doc); cost.Level != "" {
+		t.Errorf("expected no cost, got %+v", cost)
+	}
+}
+
+func TestRegisterOpenAPITools_ConfirmCost(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(costSpec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var sent []string
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	RegisterOpenAPITools(server, ExtractOpenAPIOperations(doc), doc, &ToolGenOptions{
+		ConfirmCost: "critical",
+		BaseURL:     "http://example.com",
+		RequestHandler: func(req *http.Request) (*http.Response, error) {
+			sent = append(sent, req.Method+" "+req.URL.Path)
+			return fakeResponse(200, "application/json", `{}`)(req)
+		},
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(context.Background(), serverTransport, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil).Connect(context.Background(), clientTransport, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cs.Close()
+
+	tools, err := cs.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, tool := range tools.Tools {
+		switch tool.Name {
+		case "chargeCard":
+			if !strings.Contains(tool.Description, "COST: High — Charges the customer's card.") || tool.Meta[costMetaKey] != "high" {
+				t.Errorf("expected the cost in the description and _meta, got %q, %v", tool.Description, tool.Meta)
+			}
+			if tool.Annotations == nil || tool.Annotations.DestructiveHint == nil || !*tool.Annotations.DestructiveHint {
+				t.Errorf("expected a high-cost tool to be annotated as destructive")
+			}
+		case "sendNewsletter":
+			if !strings.Contains(tool.Description, "Calls must be confirmed") {
+				t.Errorf("expected the confirmation in the description, got %q", tool.Description)
+			}
+		}
+	}
+
+	// The GET operation above the threshold is confirmed before it is sent
+	res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "sendNewsletter", Arguments: map[string]any{}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(t, res); !strings.Contains(text, "CONFIRMATION REQUIRED") || !strings.Contains(text, "Cost: critical") {
+		t.Errorf("expected a confirmation request, got: %s", text)
+	}
+	if len(sent) != 0 {
+		t.Errorf("expected no request before confirmation, got %v", sent)
+	}
+	if _, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "sendNewsletter", Arguments: map[string]any{"__confirmed": true}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sent) != 1 || sent[0] != "GET /emails" {
+		t.Errorf("expected the confirmed call to be sent, got %v", sent)
+	}
+}
@@ -0,0 +1,151 @@
+// datetime.go
+package openapi2mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// timeLayouts are the layouts convert_time parses besides Unix timestamps, most specific first.
+// Layouts without a zone are read in the configured time zone.
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02T15:04", time.DateOnly}
+
+// timeRepresentations is a point in time in the formats APIs commonly expect.
+type timeRepresentations struct {
+	UnixTimestamp    int64  `json:"unix_timestamp"`
+	UnixMillis       int64  `json:"unix_millis"`
+	RFC3339          string `json:"rfc3339"`
+	RFC3339UTC       string `json:"rfc3339_utc"`
+	Date             string `json:"date"`
+	Weekday          string `json:"weekday"`
+	Timezone         string `json:"timezone"`
+	TimezoneAbbrev   string `json:"timezone_abbreviation"`
+	UTCOffsetSeconds int    `json:"utc_offset_seconds"`
+}
+
+// newTimeRepresentations renders t in loc.
+func newTimeRepresentations(t time.Time, loc *time.Location) timeRepresentations {
+	t = t.In(loc)
+	abbrev, offset := t.Zone()
+	return timeRepresentations{
+		UnixTimestamp:    t.Unix(),
+		UnixMillis:       t.UnixMilli(),
+		RFC3339:          t.Format(time.RFC3339),
+		RFC3339UTC:       t.UTC().Format(time.RFC3339),
+		Date:             t.Format(time.DateOnly),
+		Weekday:          t.Weekday().String(),
+		Timezone:         loc.String(),
+		TimezoneAbbrev:   abbrev,
+		UTCOffsetSeconds: offset,
+	}
+}
+
+// parseTimeValue reads a Unix timestamp (seconds, or milliseconds if it is too large for seconds), an RFC 3339
+// timestamp, or a date, as a number or a string. Values without a zone are read in loc.
+func parseTimeValue(v any, loc *time.Location) (time.Time, error) {
+	var n float64
+	switch v := v.(type) {
+	case float64:
+		n = v
+	case string:
+		s := strings.TrimSpace(v)
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			n = f
+			break
+		}
+		for _, layout := range timeLayouts {
+			if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("cannot read %q as a Unix timestamp, an RFC 3339 timestamp, or a date (YYYY-MM-DD)", s)
+	default:
+		return time.Time{}, fmt.Errorf("expected a timestamp or date, got %T", v)
+	}
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return time.Time{}, fmt.Errorf("invalid timestamp %v", n)
+	}
+	// Timestamps in seconds reach 1e12 only in the year 33658, so larger values are milliseconds
+	if math.Abs(n) >= 1e12 {
+		return time.UnixMilli(int64(n)), nil
+	}
+	sec, frac := math.Modf(n)
+	return time.Unix(int64(sec), int64(frac*1e9)), nil
+}
+
+// timeZone returns the configured time zone, or the local one.
+func timeZone(opts *ToolGenOptions) *time.Location {
+	if opts != nil && opts.TimeZone != nil {
+		return opts.TimeZone
+	}
+	return time.Local
+}
+
+// registerTimestampResource serves the current time in loc as timestamp://current.
+func registerTimestampResource(server *mcp.Server, loc *time.Location) {
+	resource := &mcp.Resource{
+		URI:         "timestamp://current",
+		Name:        "Current Unix Timestamp",
+		Description: fmt.Sprintf("Provides the current time as a Unix timestamp, RFC 3339 timestamp, and date in %s to help the AI understand the current date and time", loc),
+		MIMEType:    "application/json",
+	}
+	server.AddResource(resource, func(context.Context, *mcp.ServerRequest[*mcp.ReadResourceParams]) (*mcp.ReadResourceResult, error) {
+		now := newTimeRepresentations(time.Now(), loc)
+		content, _ := json.Marshal(map[string]any{
+			"unix_timestamp":     now.UnixTimestamp,
+			"iso8601":            now.RFC3339,
+			"date":               now.Date,
+			"weekday":            now.Weekday,
+			"timezone":           now.TimezoneAbbrev,
+			"location":           now.Timezone,
+			"utc_offset_seconds": now.UTCOffsetSeconds,
+		})
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{URI: resource.URI, MIMEType: "application/json", Text: string(content)}},
+		}, nil
+	})
+}
+
+// registerConvertTimeTool adds the convert_time tool, converting between Unix timestamps, RFC 3339 timestamps,
+// and dates, so that agents don't compute them themselves.
+func registerConvertTimeTool(server *mcp.Server, loc *time.Location, opts *ToolGenOptions) {
+	var annotations *mcp.ToolAnnotations
+	if opts != nil && opts.Version != "" {
+		annotations = &mcp.ToolAnnotations{Title: "OpenAPI " + opts.Version}
+	}
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "convert_time",
+		Description: fmt.Sprintf("Convert a point in time between a Unix timestamp (seconds or milliseconds), an RFC 3339 timestamp, and a date (YYYY-MM-DD). "+
+			"Returns all representations, e.g. to fill date parameters correctly. Values without a time zone are read in %s; without a value, the current time is converted.", loc),
+		InputSchema: &jsonschema.Schema{Type: "object", Properties: map[string]*jsonschema.Schema{
+			"value":    {Types: []string{"string", "number"}, Description: "Unix timestamp, RFC 3339 timestamp (e.g. 2024-05-01T12:00:00Z), or date (e.g. 2024-05-01)."},
+			"timezone": {Type: "string", Description: fmt.Sprintf("IANA time zone of the result and of values without a zone, e.g. Europe/Berlin (default %s).", loc)},
+		}},
+		Annotations: annotations,
+	}, func(_ context.Context, _ *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+		zone := loc
+		if name, _ := args["timezone"].(string); name != "" {
+			var err error
+			if zone, err = time.LoadLocation(name); err != nil {
+				return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Unknown time zone %q: use an IANA name such as UTC or Europe/Berlin.", name)}}, IsError: true}, nil, nil
+			}
+		}
+		t := time.Now()
+		if v, ok := args["value"]; ok && v != nil {
+			var err error
+			if t, err = parseTimeValue(v, zone); err != nil {
+				return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "Invalid value: " + err.Error()}}, IsError: true}, nil, nil
+			}
+		}
+		text, _ := json.MarshalIndent(newTimeRepresentations(t, zone), "", "  ")
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: string(text)}}}, nil, nil
+	})
+}
@@ -0,0 +1,91 @@
+package openapi2mcp
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestParseTimeValue(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+	want := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, v := range []any{1714564800.0, "1714564800", 1714564800000.0, "2024-05-01T12:00:00Z", "2024-05-01T14:00:00+02:00", "2024-05-01T14:00:00"} {
+		got, err := parseTimeValue(v, berlin)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("parseTimeValue(%v) = %v, %v; want %v", v, got, err, want)
+		}
+	}
+	if got, err := parseTimeValue("2024-05-01", berlin); err != nil || !got.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, berlin)) {
+		t.Errorf("expected midnight in Berlin, got %v, %v", got, err)
+	}
+	if _, err := parseTimeValue("next tuesday", berlin); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestRegisterOpenAPITools_DateHelpers(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /events:
+    get:
+      operationId: listEvents
+      parameters: [{name: since, in: query, schema: {type: string, format: date}}]
+      responses: {"200": {description: ok}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	names := RegisterOpenAPITools(server, ExtractOpenAPIOperations(doc), doc, &ToolGenOptions{TimeZone: tokyo})
+	if !slices.Contains(names, "convert_time") {
+		t.Fatalf("expected the convert_time tool, got %v", names)
+	}
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(context.Background(), serverTransport, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil).Connect(context.Background(), clientTransport, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cs.Close()
+
+	res, err := cs.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "timestamp://current"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := res.Contents[0].Text; !strings.Contains(text, `"location":"Asia/Tokyo"`) || !strings.Contains(text, `"utc_offset_seconds":32400`) {
+		t.Errorf("expected the current time in Tokyo, got %s", text)
+	}
+
+	call, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "convert_time", Arguments: map[string]any{"value": "2024-05-01"}})
+	if err != nil || call.IsError {
+		t.Fatalf("unexpected error: %v %v", err, call)
+	}
+	var got timeRepresentations
+	if err := json.Unmarshal([]byte(resultText(t, call)), &got); err != nil {
+		t.Fatalf("expected JSON: %v", err)
+	}
+	if got.UnixTimestamp != 1714489200 || got.RFC3339 != "2024-05-01T00:00:00+09:00" || got.Date != "2024-05-01" {
+		t.Errorf("unexpected conversion: %+v", got)
+	}
+
+	call, _ = cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "convert_time", Arguments: map[string]any{"value": 1714564800, "timezone": "Mars/Olympus"}})
+	if !call.IsError {
+		t.Errorf("expected an error for an unknown time zone, got %v", call.Content)
+	}
+}
@@ -0,0 +1,147 @@
+// describe.go
+package openapi2mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const toolURIPrefix = "openapi://tools/"
+
+// toolDetail is the complete definition of a tool whose listed description may be shortened.
+type toolDetail struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	InputSchema *jsonschema.Schema `json:"inputSchema"`
+	Shortened   bool               `json:"shortened"` // whether tools/list shows a shortened description
+}
+
+// toolDetailStore keeps the complete definitions of the operation tools, for describe_tool and the
+// openapi://tools/{name} resources.
+type toolDetailStore struct {
+	mu    sync.RWMutex
+	tools map[string]toolDetail
+}
+
+// newToolDetailStore creates an empty store.
+func newToolDetailStore() *toolDetailStore {
+	return &toolDetailStore{tools: make(map[string]toolDetail)}
+}
+
+// add records the complete definition of a tool. A later tool with the same name replaces the earlier one.
+func (s *toolDetailStore) add(detail toolDetail) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tools[detail.Name] = detail
+}
+
+// get returns the complete definition of the tool name.
+func (s *toolDetailStore) get(name string) (toolDetail, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	detail, ok := s.tools[name]
+	return detail, ok
+}
+
+// shortened returns the names of the tools with shortened descriptions, sorted.
+func (s *toolDetailStore) shortened() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var names []string
+	for name, detail := range s.tools {
+		if detail.Shortened {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// offloadDescription cuts desc to maxLength bytes (if positive), at the last paragraph break if that keeps most of
+// the text, e.g. before the PARAMETERS section. The cut description points to describe_tool and the tool's resource
+// for the rest. It reports whether desc was cut.
+func offloadDescription(name, desc string, maxLength int) (string, bool) {
+	note := fmt.Sprintf("\n\n(Description shortened: call describe_tool with {\"name\": %q} or read %s for all parameters and examples.)",
+		name, toolURI(name))
+	if maxLength <= 0 || len(desc) <= maxLength || maxLength <= len(note) {
+		return desc, false
+	}
+	cut := desc[:maxLength-len(note)]
+	for !utf8.ValidString(cut) {
+		cut = cut[:len(cut)-1]
+	}
+	if i := strings.LastIndex(cut, "\n\n"); i > len(cut)/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimSpace(cut) + note, true
+}
+
+// toolURI returns the URI of the resource with the complete definition of the tool name.
+func toolURI(name string) string {
+	return toolURIPrefix + url.PathEscape(name)
+}
+
+// registerDescribeTool adds the describe_tool tool and the openapi://tools/{name} template, serving the complete
+// descriptions and input schemas of the tools in store.
+func registerDescribeTool(server *mcp.Server, store *toolDetailStore, opts *ToolGenOptions) {
+	var annotations *mcp.ToolAnnotations
+	if opts != nil && opts.Version != "" {
+		annotations = &mcp.ToolAnnotations{Title: "OpenAPI " + opts.Version}
+	}
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "describe_tool",
+		Description: "Show the complete description of a tool, with all parameters and examples, and its input schema. " +
+			"Use it for tools whose description ends with \"(Description shortened: ...)\" before calling them.",
+		InputSchema: &jsonschema.Schema{Type: "object", Properties: map[string]*jsonschema.Schema{
+			"name": {Type: "string", Description: "Name of the tool to describe."},
+		}, Required: []string{"name"}},
+		Annotations: annotations,
+	}, func(_ context.Context, _ *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+		name, _ := args["name"].(string)
+		detail, ok := store.get(name)
+		if !ok {
+			text := fmt.Sprintf("Unknown tool %q.", name)
+			if shortened := store.shortened(); len(shortened) > 0 {
+				text += " Tools with shortened descriptions: " + strings.Join(shortened, ", ")
+			}
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}, IsError: true}, nil, nil
+		}
+		schema, _ := json.MarshalIndent(detail.InputSchema, "", "  ")
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: detail.Description + "\n\nINPUT SCHEMA:\n" + string(schema)}},
+		}, nil, nil
+	})
+
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: toolURIPrefix + "{name}",
+		Name:        "Tool Description",
+		Description: "Complete description and input schema of a tool by its URL-escaped name, e.g. openapi://tools/listPets",
+		MIMEType:    "application/json",
+	}, func(_ context.Context, req *mcp.ServerRequest[*mcp.ReadResourceParams]) (*mcp.ReadResourceResult, error) {
+		uri := req.Params.URI
+		name, err := url.PathUnescape(strings.TrimPrefix(uri, toolURIPrefix))
+		if err != nil {
+			return nil, mcp.ResourceNotFoundError(uri)
+		}
+		detail, ok := store.get(name)
+		if !ok {
+			return nil, mcp.ResourceNotFoundError(uri)
+		}
+		text, err := json.MarshalIndent(detail, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{URI: uri, MIMEType: "application/json", Text: string(text)}},
+		}, nil
+	})
+}
@@ -0,0 +1,87 @@
+package openapi2mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestOffloadDescription(t *testing.T) {
+	desc := "Lists pets.\n\n" + strings.Repeat("Details. ", 20) + "\n\nPARAMETERS:\n" + strings.Repeat("• limit (integer)\n", 20)
+	short, shortened := offloadDescription("listPets", desc, 300)
+	if !shortened || len(short) > 300 {
+		t.Fatalf("expected the description to be shortened to 300 bytes, got %d bytes", len(short))
+	}
+	if strings.Contains(short, "PARAMETERS") || !strings.HasSuffix(short, `(Description shortened: call describe_tool with {"name": "listPets"} or read openapi://tools/listPets for all parameters and examples.)`) {
+		t.Errorf("expected the description to be cut at a paragraph break with a pointer to the rest, got %q", short)
+	}
+	if got, shortened := offloadDescription("listPets", desc, 0); shortened || got != desc {
+		t.Error("expected no limit to keep the description")
+	}
+}
+
+func TestRegisterOpenAPITools_DescribeTool(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      description: "` + strings.Repeat("Lists the pets of the store. ", 20) + `"
+      parameters: [{name: limit, in: query, schema: {type: integer}}]
+      responses: {"200": {description: ok}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	RegisterOpenAPITools(server, ExtractOpenAPIOperations(doc), doc, &ToolGenOptions{MaxDescriptionLength: 400})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(context.Background(), serverTransport, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil).Connect(context.Background(), clientTransport, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cs.Close()
+
+	tools, err := cs.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, tool := range tools.Tools {
+		if tool.Name == "listPets" && (len(tool.Description) > 400 || strings.Contains(tool.Description, "PARAMETERS")) {
+			t.Errorf("expected a shortened description, got %q", tool.Description)
+		}
+	}
+
+	res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "describe_tool", Arguments: map[string]any{"name": "listPets"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(t, res); !strings.Contains(text, "PARAMETERS:") || !strings.Contains(text, "INPUT SCHEMA:") || !strings.Contains(text, `"limit"`) {
+		t.Errorf("expected the complete description and schema, got: %s", text)
+	}
+	res, err = cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "describe_tool", Arguments: map[string]any{"name": "listPet"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(t, res); !res.IsError || !strings.Contains(text, "shortened descriptions: listPets") {
+		t.Errorf("expected an error listing the shortened tools, got: %s", text)
+	}
+
+	detail, err := cs.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "openapi://tools/listPets"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := detail.Contents[0].Text; !strings.Contains(text, `"shortened": true`) || !strings.Contains(text, "PARAMETERS:") {
+		t.Errorf("expected the complete definition, got %s", text)
+	}
+	if _, err := cs.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "openapi://tools/unknown"}); err == nil {
+		t.Error("expected an error for an unknown tool")
+	}
+}
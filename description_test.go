@@ -56,7 +56,7 @@ func TestGenerateAIFriendlyDescription_WithJsonSchema(t *testing.T) {
 	}
 
 	// Generate the description
-	description := generateAIFriendlyDescription(op, schema)
+	description := generateAIFriendlyDescription(op, schema, nil)
 
 	// Verify that the description contains expected content
 	if !strings.Contains(description, "This is a test operation") {
@@ -140,7 +140,7 @@ func TestGenerateExampleValueFromSchema(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := generateExampleValueFromSchema(tt.schema)
+			result := generateExampleValueFromSchema(tt.schema, nil)
 			if result != tt.expected {
 				t.Errorf("Expected %v, got %v", tt.expected, result)
 			}
@@ -149,7 +149,7 @@ func TestGenerateExampleValueFromSchema(t *testing.T) {
 }
 
 func TestGenerateExampleValueFromSchema_Nil(t *testing.T) {
-	result := generateExampleValueFromSchema(nil)
+	result := generateExampleValueFromSchema(nil, nil)
 	if result != nil {
 		t.Errorf("Expected nil for nil schema, got %v", result)
 	}
@@ -0,0 +1,274 @@
+// doctor.go
+package openapi2mcp
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Statuses of doctor checks.
+const (
+	DoctorOK   = "ok"   // nothing to fix
+	DoctorWarn = "warn" // likely to cause problems for some operations
+	DoctorFail = "fail" // tools will not work until fixed
+)
+
+// DoctorCheck is the result of one preflight check.
+type DoctorCheck struct {
+	Name    string `json:"name"`          // The checked area: "spec", "self-test", "auth", "server", or "clock"
+	Status  string `json:"status"`        // DoctorOK, DoctorWarn, or DoctorFail
+	Message string `json:"message"`       // What was found
+	Fix     string `json:"fix,omitempty"` // How to fix it
+}
+
+// DoctorReport lists the results of the preflight checks of a spec.
+type DoctorReport struct {
+	Checks []DoctorCheck `json:"checks"`
+}
+
+// OK reports whether no check failed. Warnings don't count as failures.
+func (r *DoctorReport) OK() bool {
+	return !slices.ContainsFunc(r.Checks, func(c DoctorCheck) bool { return c.Status == DoctorFail })
+}
+
+// String renders the report as a human-readable checklist with fixes.
+func (r *DoctorReport) String() string {
+	var sb strings.Builder
+	for _, c := range r.Checks {
+		sb.WriteString(fmt.Sprintf("[%s] %s: %s\n", strings.ToUpper(c.Status), c.Name, c.Message))
+		if c.Fix != "" {
+			sb.WriteString(fmt.Sprintf("  Fix: %s\n", c.Fix))
+		}
+	}
+	return sb.String()
+}
+
+func (r *DoctorReport) add(name, status, message, fix string) {
+	r.Checks = append(r.Checks, DoctorCheck{Name: name, Status: status, Message: message, Fix: fix})
+}
+
+// DoctorOptions configures DiagnoseSpec.
+type DoctorOptions struct {
+	BaseURL        string                                          // Base URL overriding OPENAPI_BASE_URL and the spec's servers
+	Timeout        time.Duration                                   // Timeout per server probe (default 5s)
+	MaxClockSkew   time.Duration                                   // Clock difference to a server to warn about (default 1m)
+	RequestHandler func(req *http.Request) (*http.Response, error) // Sends the server probes (default: http.DefaultClient)
+}
+
+// DiagnoseSpec checks for common misconfigurations before serving the spec at path: $ref and validation problems,
+// the MCP self-test (see SelfTestOpenAPIMCP), credentials missing from the environment for the security schemes
+// operations require, unreachable server URLs, and clock skew to the servers. Each problem comes with a fix.
+// opts may be nil.
+//
+//	report := openapi2mcp.DiagnoseSpec(ctx, "petstore.yaml", nil)
+//	fmt.Print(report)
+//	if !report.OK() { os.Exit(1) }
+func DiagnoseSpec(ctx context.Context, path string, opts *DoctorOptions) *DoctorReport {
+	var o DoctorOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Timeout == 0 {
+		o.Timeout = 5 * time.Second
+	}
+	if o.MaxClockSkew == 0 {
+		o.MaxClockSkew = time.Minute
+	}
+	if o.RequestHandler == nil {
+		o.RequestHandler = http.DefaultClient.Do
+	}
+
+	report := &DoctorReport{}
+	doc, err := LoadOpenAPISpec(path)
+	if err != nil {
+		cause := loadErrorCause(err)
+		fix := "Run `openapi-mcp validate` on the spec for troubleshooting steps."
+		if strings.Contains(cause, "external reference") {
+			fix = "External $refs are not loaded; bundle the spec into a single file first, e.g. with `redocly bundle`."
+		} else if strings.Contains(cause, "$ref") || strings.Contains(cause, "reference") || strings.Contains(cause, "failed to resolve") {
+			fix = "Check that every $ref points to an existing component, e.g. '#/components/schemas/Pet', with matching case."
+		}
+		report.add("spec", DoctorFail, cause, fix)
+		return report
+	}
+	report.add("spec", DoctorOK, "spec loaded, $refs resolved, and validated", "")
+
+	ops := ExtractOpenAPIOperations(doc)
+	if lint := LintOpenAPISpec(doc, false); lint.ErrorCount > 0 {
+		issue := lint.Issues[slices.IndexFunc(lint.Issues, func(i LintIssue) bool { return i.Type == "error" })]
+		report.add("self-test", DoctorFail, fmt.Sprintf("%d errors, e.g. %s", lint.ErrorCount, issue.Message), issue.Suggestion)
+	} else {
+		report.add("self-test", DoctorOK, fmt.Sprintf("%d tools with their required arguments", len(ops)), "")
+	}
+
+	authChecks(report, doc, ops)
+	serverChecks(ctx, report, doc, &o)
+	return report
+}
+
+// loadErrorCause returns the original error of a LoadOpenAPISpec error, without its troubleshooting steps.
+func loadErrorCause(err error) string {
+	msg := err.Error()
+	// LoadOpenAPISpec wraps errors of LoadOpenAPISpecFromBytes, so the cause follows the last marker
+	if i := strings.LastIndex(msg, "ORIGINAL ERROR:\n"); i >= 0 {
+		msg = msg[i+len("ORIGINAL ERROR:\n"):]
+	}
+	line, _, _ := strings.Cut(msg, "\n")
+	return line
+}
+
+// schemeEnvVar returns the environment variable the credentials of scheme are read from, or "" if there is none.
+func schemeEnvVar(scheme *openapi3.SecurityScheme) string {
+	switch {
+	case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "bearer"), scheme.Type == "oauth2":
+		return "BEARER_TOKEN"
+	case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "basic"):
+		return "BASIC_AUTH"
+	case scheme.Type == "apiKey":
+		return "API_KEY"
+	}
+	return ""
+}
+
+// authChecks reports the security schemes required by operations whose credentials are missing from the
+// environment. An operation is only affected if none of its alternative requirements can be fulfilled.
+func authChecks(report *DoctorReport, doc *openapi3.T, ops []OpenAPIOperation) {
+	scheme := func(name string) *openapi3.SecurityScheme {
+		if doc.Components != nil && doc.Components.SecuritySchemes[name] != nil {
+			return doc.Components.SecuritySchemes[name].Value
+		}
+		return nil
+	}
+	fulfilled := func(name string) bool {
+		s := scheme(name)
+		if s != nil && s.Type == "oauth2" && schemeClientCredentials(s) != nil {
+			return true
+		}
+		return s != nil && schemeEnvVar(s) != "" && os.Getenv(schemeEnvVar(s)) != ""
+	}
+
+	missing := map[string][]string{} // env var or scheme -> affected operations
+	unsupported := map[string]bool{}
+	for _, op := range ops {
+		security := operationSecurity(op, doc)
+		if len(security) == 0 || allowsAnonymous(security) {
+			continue
+		}
+		if slices.ContainsFunc(security, func(req openapi3.SecurityRequirement) bool {
+			return !slices.ContainsFunc(slices.Collect(maps.Keys(req)), func(name string) bool { return !fulfilled(name) })
+		}) {
+			continue
+		}
+		for name := range security[0] {
+			switch s := scheme(name); {
+			case s == nil:
+				missing["scheme "+name] = append(missing["scheme "+name], op.OperationID)
+			case schemeEnvVar(s) == "":
+				unsupported[fmt.Sprintf("%s (%s)", name, s.Type)] = true
+			case os.Getenv(schemeEnvVar(s)) == "":
+				missing[schemeEnvVar(s)] = append(missing[schemeEnvVar(s)], op.OperationID)
+			}
+		}
+	}
+
+	if len(missing) == 0 && len(unsupported) == 0 {
+		report.add("auth", DoctorOK, "credentials are set for all required security schemes", "")
+		return
+	}
+	for _, key := range slices.Sorted(maps.Keys(missing)) {
+		affected := missing[key]
+		example := affected[0]
+		if len(affected) > 1 {
+			example += fmt.Sprintf(" and %d more", len(affected)-1)
+		}
+		if name, ok := strings.CutPrefix(key, "scheme "); ok {
+			report.add("auth", DoctorFail, fmt.Sprintf("security scheme %s is required by %s but not defined", name, example),
+				fmt.Sprintf("Add %s to components.securitySchemes.", name))
+			continue
+		}
+		fix := fmt.Sprintf("export %s=...", key)
+		if key == "BASIC_AUTH" {
+			fix = "export BASIC_AUTH=user:password"
+		}
+		report.add("auth", DoctorFail, fmt.Sprintf("%s is not set, but required by %s", key, example), fix)
+	}
+	for _, name := range slices.Sorted(maps.Keys(unsupported)) {
+		report.add("auth", DoctorWarn, fmt.Sprintf("no credentials can be sent for security scheme %s", name),
+			"Use an alternative security scheme, or send credentials with --forward-header over HTTP.")
+	}
+}
+
+// serverURLs returns the base URLs tools are called with, with server variables set to their defaults.
+func serverURLs(doc *openapi3.T, baseURL string) []string {
+	if baseURL != "" {
+		return []string{baseURL}
+	}
+	if env := os.Getenv("OPENAPI_BASE_URL"); env != "" {
+		return []string{env}
+	}
+	var urls []string
+	for _, s := range doc.Servers {
+		if s == nil || s.URL == "" {
+			continue
+		}
+		u := s.URL
+		for name, v := range s.Variables {
+			if v != nil {
+				u = strings.ReplaceAll(u, "{"+name+"}", v.Default)
+			}
+		}
+		urls = append(urls, u)
+	}
+	return urls
+}
+
+// serverChecks probes the base URLs and reports unreachable ones, and clock skew to the reachable ones.
+func serverChecks(ctx context.Context, report *DoctorReport, doc *openapi3.T, o *DoctorOptions) {
+	urls := serverURLs(doc, o.BaseURL)
+	if len(urls) == 0 {
+		report.add("server", DoctorWarn, "the spec has no servers; tools call http://localhost:8080",
+			"Add servers to the spec, or set OPENAPI_BASE_URL or --base-url.")
+		return
+	}
+
+	for _, u := range urls {
+		if !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+			report.add("server", DoctorFail, fmt.Sprintf("%s is not an absolute URL", u),
+				"Set OPENAPI_BASE_URL or --base-url to the full URL of the API, e.g. https://api.example.com"+u+".")
+			continue
+		}
+		probeCtx, cancel := context.WithTimeout(ctx, o.Timeout)
+		req, err := http.NewRequestWithContext(probeCtx, http.MethodHead, u, nil)
+		var resp *http.Response
+		if err == nil {
+			resp, err = o.RequestHandler(req)
+		}
+		cancel()
+		if err != nil {
+			report.add("server", DoctorFail, fmt.Sprintf("%s is unreachable: %v", u, err),
+				"Check the URL, DNS, proxy, and VPN settings, or point OPENAPI_BASE_URL or --base-url to a reachable server.")
+			continue
+		}
+		resp.Body.Close()
+		report.add("server", DoctorOK, fmt.Sprintf("%s is reachable (HTTP %d)", u, resp.StatusCode), "")
+
+		date, err := http.ParseTime(resp.Header.Get("Date"))
+		if err != nil {
+			continue
+		}
+		if skew := time.Since(date).Truncate(time.Second); skew > o.MaxClockSkew || -skew > o.MaxClockSkew {
+			report.add("clock", DoctorWarn, fmt.Sprintf("the local clock differs from %s by %s", u, skew),
+				"Synchronize the system clock (e.g. enable NTP); tokens and signed requests are rejected with a skewed clock.")
+		} else {
+			report.add("clock", DoctorOK, fmt.Sprintf("the local clock is in sync with %s", u), "")
+		}
+	}
+}
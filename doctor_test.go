@@ -0,0 +1,95 @@
+package openapi2mcp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiagnoseSpec(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.yaml")
+	if err := os.WriteFile(path, []byte(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+servers: [{url: "https://{region}.example.com", variables: {region: {default: eu}}}, {url: "https://down.example.com"}]
+components:
+  securitySchemes:
+    token: {type: http, scheme: bearer}
+    key: {type: apiKey, in: header, name: X-Key}
+security: [{token: []}]
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      summary: List pets
+      responses: {"200": {description: ok}}
+  /orders:
+    get:
+      operationId: listOrders
+      summary: List orders
+      security: [{token: []}, {key: []}]
+      responses: {"200": {description: ok}}
+  /health:
+    get:
+      operationId: health
+      summary: Health
+      security: []
+      responses: {"200": {description: ok}}
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("OPENAPI_BASE_URL", "")
+	t.Setenv("BEARER_TOKEN", "")
+	t.Setenv("API_KEY", "secret")
+
+	report := DiagnoseSpec(context.Background(), path, &DoctorOptions{
+		RequestHandler: func(req *http.Request) (*http.Response, error) {
+			if req.URL.Host == "down.example.com" {
+				return nil, errors.New("no such host")
+			}
+			header := http.Header{"Date": []string{time.Now().Add(-10 * time.Minute).UTC().Format(http.TimeFormat)}}
+			return &http.Response{StatusCode: 200, Header: header, Body: io.NopCloser(strings.NewReader(""))}, nil
+		},
+	})
+	var got []string
+	for _, c := range report.Checks {
+		got = append(got, c.Status+" "+c.Name+": "+c.Message)
+	}
+	want := []string{
+		"ok spec: spec loaded, $refs resolved, and validated",
+		"ok self-test: 3 tools with their required arguments",
+		"fail auth: BEARER_TOKEN is not set, but required by listPets",
+		"ok server: https://eu.example.com is reachable (HTTP 200)",
+		"warn clock: the local clock differs from https://eu.example.com by 10m0s",
+		"fail server: https://down.example.com is unreachable: no such host",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected checks:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if report.OK() || !strings.Contains(report.String(), "  Fix: export BEARER_TOKEN=...") {
+		t.Errorf("unexpected report:\n%s", report)
+	}
+}
+
+func TestDiagnoseSpec_UnresolvedRef(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.yaml")
+	if err := os.WriteFile(path, []byte(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      responses: {"200": {description: ok, content: {application/json: {schema: {$ref: "#/components/schemas/Pet"}}}}}
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	report := DiagnoseSpec(context.Background(), path, nil)
+	if len(report.Checks) != 1 || report.Checks[0].Status != DoctorFail || !strings.Contains(report.Checks[0].Fix, "$ref") {
+		t.Errorf("expected a failing spec check with a $ref fix, got %+v", report.Checks)
+	}
+}
@@ -3,14 +3,19 @@ package openapi2mcp
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 // generateAI400ErrorResponse creates a comprehensive, AI-optimized error response for 400 HTTP errors
 // that helps agents understand how to correctly use the tool.
-func generateAI400ErrorResponse(op OpenAPIOperation, inputSchema jsonschema.Schema, args map[string]any, responseBody string) string {
+func generateAI400ErrorResponse(op OpenAPIOperation, inputSchema jsonschema.Schema, args map[string]any, responseBody string, gens ExampleGenerators) string {
 	var response strings.Builder
 
 	// Start with clear explanation
@@ -115,7 +120,7 @@ func generateAI400ErrorResponse(op OpenAPIOperation, inputSchema jsonschema.Sche
 		// Prioritize required parameters
 		for _, reqStr := range required {
 			if prop, ok := properties[reqStr]; ok && prop != nil {
-				exampleArgs[reqStr] = generateExampleValueFromSchema(prop)
+				exampleArgs[reqStr] = generateExampleValueFromSchema(prop, gens)
 			}
 		}
 
@@ -123,7 +128,7 @@ func generateAI400ErrorResponse(op OpenAPIOperation, inputSchema jsonschema.Sche
 		count := 0
 		for paramName, prop := range properties {
 			if _, exists := exampleArgs[paramName]; !exists && count < 3 && prop != nil {
-				exampleArgs[paramName] = generateExampleValueFromSchema(prop)
+				exampleArgs[paramName] = generateExampleValueFromSchema(prop, gens)
 				count++
 			}
 		}
@@ -293,7 +298,7 @@ func generateAI404ErrorResponse(op OpenAPIOperation, inputSchema jsonschema.Sche
 }
 
 // generateAI5xxErrorResponse creates comprehensive, AI-optimized error response for server errors
-func generateAI5xxErrorResponse(op OpenAPIOperation, inputSchema jsonschema.Schema, args map[string]any, responseBody string, statusCode int) string {
+func generateAI5xxErrorResponse(op OpenAPIOperation, inputSchema jsonschema.Schema, args map[string]any, responseBody string, statusCode int, gens ExampleGenerators) string {
 	var response strings.Builder
 
 	response.WriteString(fmt.Sprintf("SERVER ERROR (%d): The server encountered an error processing your request.\n\n", statusCode))
@@ -400,7 +405,7 @@ func generateAI5xxErrorResponse(op OpenAPIOperation, inputSchema jsonschema.Sche
 		// Add required parameters to example
 		for _, reqStr := range required {
 			if prop, ok := properties[reqStr]; ok && prop != nil {
-				exampleArgs[reqStr] = generateExampleValueFromSchema(prop)
+				exampleArgs[reqStr] = generateExampleValueFromSchema(prop, gens)
 			}
 		}
 
@@ -408,7 +413,7 @@ func generateAI5xxErrorResponse(op OpenAPIOperation, inputSchema jsonschema.Sche
 		count := 0
 		for paramName, prop := range properties {
 			if _, exists := exampleArgs[paramName]; !exists && count < 2 && prop != nil {
-				exampleArgs[paramName] = generateExampleValueFromSchema(prop)
+				exampleArgs[paramName] = generateExampleValueFromSchema(prop, gens)
 				count++
 			}
 		}
@@ -419,3 +424,345 @@ func generateAI5xxErrorResponse(op OpenAPIOperation, inputSchema jsonschema.Sche
 
 	return response.String()
 }
+
+// Error output formats for ToolGenOptions.ErrorFormat.
+const (
+	ErrorFormatText = "text" // prose only (default)
+	ErrorFormatJSON = "json" // structured JSON only
+	ErrorFormatBoth = "both" // prose followed by structured JSON
+)
+
+// FieldError describes a validation failure for a single tool argument.
+type FieldError struct {
+	Field   string `json:"field"`          // Tool argument name (or path within requestBody)
+	Message string `json:"message"`        // Validation message reported by the API
+	Hint    string `json:"hint,omitempty"` // Suggested correction, if any
+}
+
+// ToolError is a machine-readable description of a failed tool call.
+// It is returned as structured content so programmatic MCP clients can branch on the error type.
+type ToolError struct {
+	Code        string       `json:"code"`                   // Stable error code, e.g. "not_found" or "rate_limited"
+	HTTPStatus  int          `json:"http_status,omitempty"`  // Upstream HTTP status code
+	Message     string       `json:"message"`                // Short human-readable message
+	Retriable   bool         `json:"retriable"`              // Whether retrying the same call may succeed
+	RetryAfter  int          `json:"retry_after,omitempty"`  // Seconds to wait before retrying, from Retry-After
+	FieldErrors []FieldError `json:"field_errors,omitempty"` // Per-argument validation errors
+	Operation   string       `json:"operation,omitempty"`    // Operation ID of the tool
+	CallID      string       `json:"call_id,omitempty"`      // Correlation ID of the tool call, as logged and sent upstream
+	DocsURL     string       `json:"docs_url,omitempty"`     // Documentation page of the operation, from its externalDocs
+}
+
+// errorCodeForStatus maps an HTTP status code to a stable ToolError code.
+func errorCodeForStatus(statusCode int) string {
+	switch {
+	case statusCode == 400:
+		return "bad_request"
+	case statusCode == 401:
+		return "unauthorized"
+	case statusCode == 403:
+		return "forbidden"
+	case statusCode == 404:
+		return "not_found"
+	case statusCode == 405:
+		return "method_not_allowed"
+	case statusCode == 408:
+		return "timeout"
+	case statusCode == 409:
+		return "conflict"
+	case statusCode == 412:
+		return "precondition_failed"
+	case statusCode == 413:
+		return "payload_too_large"
+	case statusCode == 415:
+		return "unsupported_media_type"
+	case statusCode == 422:
+		return "validation_failed"
+	case statusCode == 429:
+		return "rate_limited"
+	case statusCode == 503:
+		return "unavailable"
+	case statusCode >= 500:
+		return "server_error"
+	default:
+		return "http_error"
+	}
+}
+
+// isRetriableStatus reports whether a call failing with the given status may succeed when retried unchanged.
+func isRetriableStatus(statusCode int) bool {
+	switch statusCode {
+	case 408, 425, 429, 500, 502, 503, 504:
+		return true
+	}
+	return false
+}
+
+// parseRetryAfter parses a Retry-After header value (delay-seconds or HTTP-date) into seconds.
+// Returns 0 if the header is empty or invalid.
+func parseRetryAfter(value string) int {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs >= 0 {
+		return secs
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if secs := int(time.Until(t).Seconds()); secs > 0 {
+			return secs
+		}
+	}
+	return 0
+}
+
+// newHTTPToolError builds a ToolError from a non-2xx upstream response.
+func newHTTPToolError(op OpenAPIOperation, resp *http.Response) *ToolError {
+	return &ToolError{
+		Code:       errorCodeForStatus(resp.StatusCode),
+		HTTPStatus: resp.StatusCode,
+		Message:    fmt.Sprintf("%s (HTTP %d)", http.StatusText(resp.StatusCode), resp.StatusCode),
+		Retriable:  isRetriableStatus(resp.StatusCode),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		Operation:  op.OperationID,
+		DocsURL:    operationDocsURL(op),
+	}
+}
+
+// toolErrorResult renders an error as a tool result according to the configured error format.
+// The structured error is always attached as structured content unless the format is text-only.
+func toolErrorResult(errorText string, toolErr *ToolError, format string) *mcp.CallToolResult {
+	result := &mcp.CallToolResult{IsError: true}
+
+	switch format {
+	case ErrorFormatJSON, ErrorFormatBoth:
+		errorJSON, _ := json.MarshalIndent(map[string]any{"error": toolErr}, "", "  ")
+		if format == ErrorFormatBoth {
+			result.Content = append(result.Content, &mcp.TextContent{Text: errorText})
+		}
+		result.Content = append(result.Content, &mcp.TextContent{Text: string(errorJSON)})
+		result.StructuredContent = map[string]any{"error": toolErr}
+	default:
+		result.Content = []mcp.Content{&mcp.TextContent{Text: errorText}}
+	}
+
+	return result
+}
+
+// generateAI422ErrorResponse creates an AI-optimized error response for 422 validation failures,
+// listing each rejected field with the tool argument it maps to and a correction hint.
+func generateAI422ErrorResponse(op OpenAPIOperation, inputSchema jsonschema.Schema, args map[string]any, responseBody string, fieldErrors []FieldError) string {
+	var response strings.Builder
+
+	response.WriteString("VALIDATION FAILED (422): The API understood the request but rejected one or more argument values.\n\n")
+
+	// Operation context
+	response.WriteString(fmt.Sprintf("OPERATION: %s", op.OperationID))
+	if op.Summary != "" {
+		response.WriteString(fmt.Sprintf(" - %s", op.Summary))
+	}
+	response.WriteString("\n\n")
+
+	if len(fieldErrors) > 0 {
+		response.WriteString("FIELD ERRORS:\n")
+		for _, fe := range fieldErrors {
+			response.WriteString(fmt.Sprintf("• %s: %s\n", fe.Field, fe.Message))
+			if fe.Hint != "" {
+				response.WriteString(fmt.Sprintf("  Hint: %s\n", fe.Hint))
+			}
+			if val, ok := lookupArgument(args, fe.Field); ok {
+				valJSON, _ := json.Marshal(val)
+				response.WriteString(fmt.Sprintf("  You sent: %s\n", string(valJSON)))
+			}
+		}
+		response.WriteString("\n")
+	} else if responseBody != "" {
+		response.WriteString("SERVER ERROR DETAILS:\n")
+		response.WriteString(responseBody)
+		response.WriteString("\n\n")
+	}
+
+	response.WriteString("TROUBLESHOOTING STEPS:\n")
+	response.WriteString("1. Correct only the fields listed above and retry\n")
+	response.WriteString("2. Check value formats, ranges, and lengths against the tool schema\n")
+	response.WriteString("3. Ensure enum values are from the allowed list\n")
+	response.WriteString("4. Do not change arguments that were not rejected\n")
+
+	return response.String()
+}
+
+// extractFieldErrors parses common validation error payload shapes and maps each field back to a tool argument name.
+// Supported shapes include:
+//
+//	{"errors": {"field": ["msg"]}}                                  (Rails-style)
+//	{"errors": [{"field": "name", "message": "msg"}]}               (generic list)
+//	{"errors": [{"source": {"pointer": "/data/x"}, "detail": "msg"}]} (JSON:API)
+//	{"detail": [{"loc": ["body", "name"], "msg": "msg"}]}           (FastAPI/pydantic)
+func extractFieldErrors(op OpenAPIOperation, inputSchema jsonschema.Schema, responseBody []byte) []FieldError {
+	var payload map[string]any
+	if err := json.Unmarshal(responseBody, &payload); err != nil {
+		return nil
+	}
+
+	var fieldErrors []FieldError
+	add := func(field, message string) {
+		if field == "" {
+			return
+		}
+		arg := mapFieldToArgument(op, field)
+		fieldErrors = append(fieldErrors, FieldError{
+			Field:   arg,
+			Message: message,
+			Hint:    fieldHint(inputSchema, arg),
+		})
+	}
+
+	for _, key := range []string{"errors", "detail", "field_errors", "fieldErrors", "violations"} {
+		switch v := payload[key].(type) {
+		case map[string]any:
+			for field, msgs := range v {
+				add(field, joinMessages(msgs))
+			}
+		case []any:
+			for _, item := range v {
+				m, ok := item.(map[string]any)
+				if !ok {
+					continue
+				}
+				add(fieldFromErrorItem(m), messageFromErrorItem(m))
+			}
+		}
+	}
+
+	slices.SortStableFunc(fieldErrors, func(a, b FieldError) int {
+		return strings.Compare(a.Field, b.Field)
+	})
+	return fieldErrors
+}
+
+// fieldFromErrorItem extracts the field path from a single error object in a validation payload.
+func fieldFromErrorItem(m map[string]any) string {
+	for _, key := range []string{"field", "path", "param", "parameter", "property", "propertyPath", "name"} {
+		if s, ok := m[key].(string); ok && s != "" {
+			return s
+		}
+	}
+	if src, ok := m["source"].(map[string]any); ok {
+		if s, ok := src["pointer"].(string); ok && s != "" {
+			return s
+		}
+		if s, ok := src["parameter"].(string); ok && s != "" {
+			return s
+		}
+	}
+	if loc, ok := m["loc"].([]any); ok {
+		var parts []string
+		for _, p := range loc {
+			parts = append(parts, fmt.Sprintf("%v", p))
+		}
+		return strings.Join(parts, ".")
+	}
+	return ""
+}
+
+// messageFromErrorItem extracts the human-readable message from a single error object in a validation payload.
+func messageFromErrorItem(m map[string]any) string {
+	for _, key := range []string{"message", "msg", "detail", "title", "error", "reason"} {
+		if s, ok := m[key].(string); ok && s != "" {
+			return s
+		}
+	}
+	return "invalid value"
+}
+
+// joinMessages flattens a message value (string or list of strings) into a single string.
+func joinMessages(v any) string {
+	switch msgs := v.(type) {
+	case string:
+		return msgs
+	case []any:
+		var parts []string
+		for _, m := range msgs {
+			parts = append(parts, fmt.Sprintf("%v", m))
+		}
+		return strings.Join(parts, "; ")
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// mapFieldToArgument translates an upstream field path into the tool argument name the agent used.
+// Parameter names are mapped to their escaped form; everything else is treated as a path within requestBody.
+func mapFieldToArgument(op OpenAPIOperation, field string) string {
+	// Normalize JSON pointers and location prefixes
+	path := strings.TrimPrefix(field, "#")
+	path = strings.TrimPrefix(path, "/")
+	path = strings.ReplaceAll(path, "/", ".")
+	for _, prefix := range []string{"body.", "query.", "path.", "header.", "cookie."} {
+		path = strings.TrimPrefix(path, prefix)
+	}
+
+	for _, paramRef := range op.Parameters {
+		if paramRef == nil || paramRef.Value == nil {
+			continue
+		}
+		name := paramRef.Value.Name
+		if field == name || path == name || path == escapeParameterName(name) {
+			return escapeParameterName(name)
+		}
+	}
+
+	if op.RequestBody != nil && path != "" && path != "requestBody" && !strings.HasPrefix(path, "requestBody.") {
+		return "requestBody." + path
+	}
+	return path
+}
+
+// lookupArgument finds the value the agent supplied for a (possibly dotted requestBody) argument path.
+func lookupArgument(args map[string]any, field string) (any, bool) {
+	var cur any = args
+	for _, part := range strings.Split(field, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// fieldHint builds a correction hint for an argument path from the tool's input schema.
+func fieldHint(inputSchema jsonschema.Schema, field string) string {
+	prop := &inputSchema
+	for _, part := range strings.Split(field, ".") {
+		if prop == nil || prop.Properties == nil {
+			return ""
+		}
+		prop = prop.Properties[part]
+	}
+	if prop == nil {
+		return ""
+	}
+
+	var hints []string
+	if prop.Type != "" {
+		hint := "expected " + prop.Type
+		if prop.Format != "" {
+			hint += " (" + prop.Format + ")"
+		}
+		hints = append(hints, hint)
+	}
+	if len(prop.Enum) > 0 {
+		var enumStrs []string
+		for _, e := range prop.Enum {
+			enumStrs = append(enumStrs, fmt.Sprintf("%v", e))
+		}
+		hints = append(hints, "valid values: "+strings.Join(enumStrs, ", "))
+	}
+	if prop.Description != "" {
+		hints = append(hints, prop.Description)
+	}
+	return strings.Join(hints, "; ")
+}
@@ -0,0 +1,105 @@
+package openapi2mcp
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	if got := parseRetryAfter("120"); got != 120 {
+		t.Errorf("expected 120, got %d", got)
+	}
+	if got := parseRetryAfter(""); got != 0 {
+		t.Errorf("expected 0 for empty header, got %d", got)
+	}
+	if got := parseRetryAfter("garbage"); got != 0 {
+		t.Errorf("expected 0 for invalid header, got %d", got)
+	}
+}
+
+func TestToolHandler_StructuredErrors(t *testing.T) {
+	op := OpenAPIOperation{OperationID: "getPet", Path: "/pet", Method: "get"}
+	handler := toolHandler("getPet", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, &ToolGenOptions{
+		ErrorFormat: ErrorFormatJSON,
+		RequestHandler: func(req *http.Request) (*http.Response, error) {
+			resp, _ := fakeResponse(429, "application/json", `{"message": "slow down"}`)(req)
+			resp.Header.Set("Retry-After", "30")
+			return resp, nil
+		},
+	}, nil)
+	res, _, err := handler(context.Background(), nil, map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.IsError {
+		t.Fatalf("expected error result")
+	}
+	structured, ok := res.StructuredContent.(map[string]any)
+	if !ok {
+		t.Fatalf("expected structured content, got %T", res.StructuredContent)
+	}
+	toolErr, ok := structured["error"].(*ToolError)
+	if !ok {
+		t.Fatalf("expected *ToolError, got %T", structured["error"])
+	}
+	if toolErr.Code != "rate_limited" || !toolErr.Retriable || toolErr.RetryAfter != 30 || toolErr.HTTPStatus != 429 {
+		t.Errorf("unexpected tool error: %+v", toolErr)
+	}
+	if text := resultText(t, res); !strings.Contains(text, `"code": "rate_limited"`) {
+		t.Errorf("expected JSON error text, got: %s", text)
+	}
+}
+
+func TestExtractFieldErrors(t *testing.T) {
+	op := OpenAPIOperation{
+		OperationID: "createPet",
+		Parameters: openapi3.Parameters{
+			&openapi3.ParameterRef{Value: &openapi3.Parameter{
+				Name:   "filter[status]",
+				In:     "query",
+				Schema: &openapi3.SchemaRef{Value: &openapi3.Schema{Type: typesPtr("string"), Enum: []any{"available", "sold"}}},
+			}},
+		},
+		RequestBody: &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithJSONSchema(&openapi3.Schema{
+			Type: typesPtr("object"),
+			Properties: openapi3.Schemas{
+				"name": &openapi3.SchemaRef{Value: &openapi3.Schema{Type: typesPtr("string")}},
+			},
+		})},
+	}
+	inputSchema := BuildInputSchema(op.Parameters, op.RequestBody)
+
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{"rails", `{"errors": {"name": ["can't be blank"], "filter[status]": ["is invalid"]}}`, []string{"filter_status_", "requestBody.name"}},
+		{"list", `{"errors": [{"field": "name", "message": "too short"}]}`, []string{"requestBody.name"}},
+		{"jsonapi", `{"errors": [{"source": {"pointer": "/name"}, "detail": "required"}]}`, []string{"requestBody.name"}},
+		{"fastapi", `{"detail": [{"loc": ["query", "filter[status]"], "msg": "bad"}]}`, []string{"filter_status_"}},
+		{"unknown", `{"message": "nope"}`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fieldErrors := extractFieldErrors(op, inputSchema, []byte(tt.body))
+			var got []string
+			for _, fe := range fieldErrors {
+				got = append(got, fe.Field)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("expected fields %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	fieldErrors := extractFieldErrors(op, inputSchema, []byte(`{"errors": {"filter[status]": ["is invalid"]}}`))
+	if len(fieldErrors) != 1 || !strings.Contains(fieldErrors[0].Hint, "available, sold") {
+		t.Errorf("expected enum hint, got %+v", fieldErrors)
+	}
+}
@@ -0,0 +1,41 @@
+// examples.go
+package openapi2mcp
+
+import (
+	"math/rand/v2"
+	"strings"
+)
+
+// ExampleGenerator returns an example value, e.g. an ID in a company-specific format. rnd is seeded deterministically,
+// so that tool descriptions and mock responses stay the same between runs.
+type ExampleGenerator func(rnd *rand.Rand) any
+
+// ExampleGenerators maps component schema names (e.g. "CustomerID") and formats (e.g. "customer-id") to the
+// generators of their example values.
+type ExampleGenerators map[string]ExampleGenerator
+
+// lookup returns the generator registered for the component schema name or, failing that, for the format.
+func (g ExampleGenerators) lookup(schemaName, format string) ExampleGenerator {
+	if gen, ok := g[schemaName]; ok && schemaName != "" {
+		return gen
+	}
+	if gen, ok := g[format]; ok && format != "" {
+		return gen
+	}
+	return nil
+}
+
+// exampleRand returns the random source of example values generated for tool descriptions and errors.
+func exampleRand() *rand.Rand {
+	return rand.New(rand.NewPCG(0, 0))
+}
+
+// componentName returns the name of the component schema a $ref points to, e.g. "CustomerID" for
+// "#/components/schemas/CustomerID".
+func componentName(ref string) string {
+	name, ok := strings.CutPrefix(ref, "#/components/schemas/")
+	if !ok {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,62 @@
+package openapi2mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExampleGenerators(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /customers/{id}:
+    get:
+      operationId: getCustomer
+      parameters: [{name: id, in: path, required: true, schema: {type: string, format: customer-id}}]
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  id: {type: string, format: customer-id}
+                  account: {$ref: "#/components/schemas/AccountNumber"}
+                  email: {type: string, format: email}
+components:
+  schemas:
+    AccountNumber: {type: string}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	gens := ExampleGenerators{
+		"customer-id":   func(rnd *rand.Rand) any { return fmt.Sprintf("CUS-%06d", rnd.IntN(1000000)) },
+		"AccountNumber": func(*rand.Rand) any { return "DE-0001" },
+	}
+	op := ExtractOpenAPIOperations(doc)[0]
+
+	// Tool description examples use generators for formats
+	desc := generateAIFriendlyDescription(op, BuildInputSchema(op.Parameters, op.RequestBody), gens)
+	if !strings.Contains(desc, `{"id":"CUS-`) {
+		t.Errorf("expected a generated customer ID in the example, got: %s", desc)
+	}
+
+	// Mock responses use generators for component schemas and formats
+	resp, _ := mockRequestHandler(op, gens)(httptest.NewRequest("GET", "http://example.com/customers/1", nil))
+	var customer map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&customer); err != nil {
+		t.Fatalf("expected JSON body: %v", err)
+	}
+	if id, _ := customer["id"].(string); !strings.HasPrefix(id, "CUS-") || customer["account"] != "DE-0001" {
+		t.Errorf("expected generated values, got %v", customer)
+	}
+	if email, _ := customer["email"].(string); !strings.HasSuffix(email, "@example.com") {
+		t.Errorf("expected the built-in email default, got %v", customer["email"])
+	}
+}
@@ -0,0 +1,26 @@
+// externaldocs.go
+package openapi2mcp
+
+import "strings"
+
+// operationDocsURL returns the URL of the operation's own documentation page (its externalDocs), if any.
+func operationDocsURL(op OpenAPIOperation) string {
+	if op.ExternalDocs == nil {
+		return ""
+	}
+	return strings.TrimSpace(op.ExternalDocs.URL)
+}
+
+// describeExternalDocs points to the operation's documentation page in its tool description, so that the model
+// can refer users to it, e.g. when a call fails.
+func describeExternalDocs(op OpenAPIOperation) string {
+	url := operationDocsURL(op)
+	if url == "" {
+		return ""
+	}
+	desc := "\n\nDOCUMENTATION: " + url
+	if d := strings.TrimSpace(op.ExternalDocs.Description); d != "" {
+		desc += " (" + strings.TrimSuffix(d, ".") + ")"
+	}
+	return desc + "."
+}
@@ -0,0 +1,46 @@
+package openapi2mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestExternalDocs(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /pets/{id}:
+    get:
+      operationId: getPet
+      externalDocs: {url: "https://docs.example.com/pets#get", description: "Pet lookup guide."}
+      parameters: [{name: id, in: path, required: true, schema: {type: string}}]
+      responses: {"200": {description: ok}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	op := ExtractOpenAPIOperations(doc)[0]
+	if got := describeExternalDocs(op); got != "\n\nDOCUMENTATION: https://docs.example.com/pets#get (Pet lookup guide)." {
+		t.Errorf("describeExternalDocs() = %q", got)
+	}
+	if got := describeExternalDocs(OpenAPIOperation{}); got != "" {
+		t.Errorf("expected no documentation line, got %q", got)
+	}
+
+	// Failing calls point to the operation's documentation
+	opts := &ToolGenOptions{ErrorFormat: ErrorFormatBoth, RequestHandler: fakeResponse(404, "application/json", `{"error":"not found"}`)}
+	handler := toolHandler("getPet", op, doc, jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+	res, _, err := handler(context.Background(), nil, map[string]any{"id": "1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(resultText(t, res), "\nDocumentation: https://docs.example.com/pets#get\n") {
+		t.Errorf("expected the documentation URL in the error, got: %s", resultText(t, res))
+	}
+	if toolErr := res.StructuredContent.(map[string]any)["error"].(*ToolError); toolErr.DocsURL != "https://docs.example.com/pets#get" {
+		t.Errorf("expected docs_url in the structured error, got %+v", toolErr)
+	}
+}
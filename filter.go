@@ -0,0 +1,55 @@
+// filter.go
+package openapi2mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// filterArgument is the reserved tool argument holding a JMESPath expression applied to JSON responses.
+const filterArgument = "__filter"
+
+// filterArgumentSchema describes the __filter argument added to every tool's input schema.
+func filterArgumentSchema() *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:        "string",
+		Description: "Optional JMESPath expression applied to the JSON response; only the selected data is returned. Use it to cut down large responses, e.g. \"items[].{id: id, name: name}\" or \"items[?status=='active'].id\".",
+	}
+}
+
+// compileFilterArgument compiles the __filter argument of args, if set.
+func compileFilterArgument(args map[string]any) (*jmespathExpr, error) {
+	expr, _ := args[filterArgument].(string)
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil
+	}
+	return compileJMESPath(expr)
+}
+
+// filterResponseBody applies filter to the JSON body and returns the compact JSON of the result.
+func filterResponseBody(filter *jmespathExpr, body []byte) ([]byte, error) {
+	data, err := decodeJSONNumbers(body)
+	if err != nil {
+		return nil, fmt.Errorf("the response is not valid JSON: %w", err)
+	}
+	result, err := filter.search(data)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(result); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// filterNotAppliedNotice explains why the __filter argument was ignored for a response.
+func filterNotAppliedNotice(reason string) string {
+	return "\n\n[FILTER NOT APPLIED: " + reason + "; the full response is shown.]"
+}
@@ -0,0 +1,39 @@
+// forward.go
+package openapi2mcp
+
+import (
+	"net/http"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// hopByHopHeaders describe a single HTTP connection and are never forwarded upstream.
+var hopByHopHeaders = map[string]bool{
+	"Connection":          true,
+	"Content-Length":      true,
+	"Host":                true,
+	"Keep-Alive":          true,
+	"Proxy-Authenticate":  true,
+	"Proxy-Authorization": true,
+	"Te":                  true,
+	"Trailer":             true,
+	"Transfer-Encoding":   true,
+	"Upgrade":             true,
+}
+
+// forwardHeaders copies the allowed headers of the incoming MCP HTTP request, if any, to the upstream request.
+// Forwarded headers replace those set from the environment, so that each client can send its own credentials.
+func forwardHeaders(httpReq *http.Request, req *mcp.CallToolRequest, allowed []string) {
+	if len(allowed) == 0 || req == nil || req.Extra == nil || req.Extra.Header == nil {
+		return
+	}
+	for _, name := range allowed {
+		name = http.CanonicalHeaderKey(name)
+		if hopByHopHeaders[name] {
+			continue
+		}
+		if values := req.Extra.Header.Values(name); len(values) > 0 {
+			httpReq.Header[name] = append([]string(nil), values...)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package openapi2mcp
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestToolHandler_ForwardHeaders(t *testing.T) {
+	t.Setenv("BEARER_TOKEN", "env-token")
+	var got http.Header
+	op := OpenAPIOperation{OperationID: "getFoo", Path: "/foo", Method: "get"}
+	opts := &ToolGenOptions{
+		RequestHandler: func(req *http.Request) (*http.Response, error) {
+			got = req.Header.Clone()
+			return fakeResponse(200, "application/json", `{}`)(req)
+		},
+		ForwardHeaders: []string{"authorization", "X-Tenant", "Host"},
+	}
+	handler := toolHandler("getFoo", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+
+	req := &mcp.CallToolRequest{Extra: &mcp.RequestExtra{Header: http.Header{
+		"Authorization": {"Bearer client-token"},
+		"X-Tenant":      {"a", "b"},
+		"Host":          {"evil.example.com"},
+		"Cookie":        {"session=secret"},
+	}}}
+	if _, _, err := handler(context.Background(), req, map[string]any{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Get("Authorization") != "Bearer client-token" {
+		t.Errorf("expected forwarded Authorization to replace the environment's, got %q", got.Get("Authorization"))
+	}
+	if len(got.Values("X-Tenant")) != 2 {
+		t.Errorf("expected all X-Tenant values, got %v", got.Values("X-Tenant"))
+	}
+	if got.Get("Cookie") != "" || got.Get("Host") != "" {
+		t.Errorf("expected only allowed end-to-end headers to be forwarded, got %v", got)
+	}
+
+	// Without an incoming HTTP request (stdio), the environment's credentials are used
+	if _, _, err := handler(context.Background(), nil, map[string]any{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Get("Authorization") != "Bearer env-token" {
+		t.Errorf("expected the environment's token, got %q", got.Get("Authorization"))
+	}
+}
@@ -0,0 +1,162 @@
+package openapi2mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// fuzzOperation has a parameter in every location, a bracketed query parameter name, and a JSON body.
+func fuzzOperation() OpenAPIOperation {
+	param := func(name, in, typ string, required bool) *openapi3.ParameterRef {
+		return &openapi3.ParameterRef{Value: &openapi3.Parameter{
+			Name: name, In: in, Required: required,
+			Schema: &openapi3.SchemaRef{Value: &openapi3.Schema{Type: typesPtr(typ)}},
+		}}
+	}
+	body := &openapi3.Schema{
+		Type: typesPtr("object"),
+		Properties: openapi3.Schemas{
+			"name": &openapi3.SchemaRef{Value: &openapi3.Schema{Type: typesPtr("string")}},
+			"tags": &openapi3.SchemaRef{Value: &openapi3.Schema{Type: typesPtr("array"), Items: &openapi3.SchemaRef{Value: &openapi3.Schema{Type: typesPtr("string")}}}},
+		},
+	}
+	return OpenAPIOperation{
+		OperationID: "updateItem",
+		Path:        "/items/{id}/versions/{version}",
+		Method:      "put",
+		Parameters: openapi3.Parameters{
+			param("id", "path", "string", true),
+			param("version", "path", "integer", true),
+			param("filter[status]", "query", "string", false),
+			param("limit", "query", "integer", false),
+			param("X-Trace", "header", "string", false),
+			param("session", "cookie", "string", false),
+		},
+		RequestBody: &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithJSONSchema(body)},
+	}
+}
+
+// FuzzToolHandlerArguments feeds arbitrary argument maps through input schema validation and
+// request construction. The handler must never panic, and path, query, and cookie values must
+// arrive upstream exactly as given, without altering the URL structure or injecting cookies.
+func FuzzToolHandlerArguments(f *testing.F) {
+	for _, seed := range []string{
+		`{"id": "abc", "version": 3}`,
+		`{"id": "a/b", "version": 1, "filter_status_": "open&closed"}`,
+		`{"id": "..", "version": 2}`,
+		`{"id": "%2F?#", "version": -1, "limit": 1e300}`,
+		`{"id": "ünï©ødé 🚀", "version": 0, "X-Trace": "line\r\nInjected: 1"}`,
+		`{"id": 12, "version": "not a number", "filter[status]": ["a", "b"]}`,
+		`{"id": "x", "version": 1, "session": "a; admin=true", "requestBody": {"name": "n", "tags": [1, null]}}`,
+		`{"id": null, "version": {}, "requestBody": "not an object", "__confirmed": true}`,
+		`{"id": "` + strings.Repeat("x", 10000) + `", "version": 1}`,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	op := fuzzOperation()
+	inputSchema := BuildInputSchema(op.Parameters, op.RequestBody)
+	resolved, err := inputSchema.Resolve(nil)
+	if err != nil {
+		f.Fatalf("failed to resolve input schema: %v", err)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var args map[string]any
+		if err := json.Unmarshal(data, &args); err != nil || args == nil {
+			return
+		}
+		// Invalid arguments are normally rejected by the MCP server; the handler must still cope with them
+		_ = resolved.Validate(args)
+		args["__confirmed"] = true
+
+		var sent *http.Request
+		opts := &ToolGenOptions{
+			ConfirmDangerousActions: true,
+			RequestHandler: func(req *http.Request) (*http.Response, error) {
+				sent = req
+				return fakeResponse(200, "application/json", `{}`)(req)
+			},
+		}
+		handler := toolHandler("updateItem", op, minimalOpenAPIDoc(), inputSchema, []string{"http://api.example.com/v1"}, opts, nil)
+		if _, _, err := handler(context.Background(), nil, args); err != nil || sent == nil {
+			return
+		}
+
+		// Path parameters must stay within their segment
+		segments := strings.Split(strings.TrimPrefix(sent.URL.EscapedPath(), "/v1/items/"), "/")
+		// An empty value leaves an empty segment, which is cleaned from the path
+		_, hasID := args["id"]
+		hasID = hasID && formatParameterValue(args["id"], false) != ""
+		if hasID && len(segments) == 3 && segments[1] == "versions" {
+			got, err := url.PathUnescape(segments[0])
+			if want := formatParameterValue(args["id"], false); err != nil || got != want {
+				t.Errorf("path parameter id = %q, want %q (URL %s)", got, want, sent.URL)
+			}
+		} else if hasID {
+			t.Errorf("path parameters changed the URL structure: %s", sent.URL)
+		}
+
+		if v, ok := args["filter_status_"]; ok {
+			if got, want := sent.URL.Query().Get("filter[status]"), formatParameterValue(v, false); got != want {
+				t.Errorf("query parameter filter[status] = %q, want %q", got, want)
+			}
+		}
+
+		if v, ok := args["session"]; ok {
+			cookies := sent.Cookies()
+			if len(cookies) != 1 || cookies[0].Name != "session" {
+				t.Errorf("cookie parameter session = %v injected cookies: %v", v, cookies)
+			} else if got, _ := url.PathUnescape(cookies[0].Value); got != formatParameterValue(v, false) {
+				t.Errorf("cookie parameter session = %q, want %q", got, formatParameterValue(v, false))
+			}
+		}
+	})
+}
+
+// FuzzParameterNames checks that arbitrary parameter names, including bracketed ones, produce a
+// resolvable input schema and that values given under the escaped name reach the upstream request.
+func FuzzParameterNames(f *testing.F) {
+	for _, seed := range []string{"q", "filter[status]", "page[size]", "a]b[c", "[]", "x_", "ünï[©]", "a b", "$ref", "~1/"} {
+		f.Add(seed, "value")
+	}
+
+	f.Fuzz(func(t *testing.T, name, value string) {
+		if name == "" {
+			return
+		}
+		params := openapi3.Parameters{{Value: &openapi3.Parameter{
+			Name: name, In: "query", Required: true,
+			Schema: &openapi3.SchemaRef{Value: &openapi3.Schema{Type: typesPtr("string")}},
+		}}}
+		inputSchema := BuildInputSchema(params, nil)
+		resolved, err := inputSchema.Resolve(nil)
+		if err != nil {
+			t.Fatalf("input schema for parameter %q does not resolve: %v", name, err)
+		}
+		args := map[string]any{escapeParameterName(name): value}
+		if err := resolved.Validate(args); err != nil {
+			t.Errorf("arguments under escaped name %q rejected: %v", escapeParameterName(name), err)
+		}
+
+		var sent *http.Request
+		op := OpenAPIOperation{OperationID: "search", Path: "/search", Method: "get", Parameters: params}
+		opts := &ToolGenOptions{RequestHandler: func(req *http.Request) (*http.Response, error) {
+			sent = req
+			return fakeResponse(200, "application/json", `{}`)(req)
+		}}
+		handler := toolHandler("search", op, minimalOpenAPIDoc(), inputSchema, []string{"http://api.example.com"}, opts, nil)
+		if _, _, err := handler(context.Background(), nil, args); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := sent.URL.Query().Get(name); got != value {
+			t.Errorf("query parameter %q = %q, want %q", name, got, value)
+		}
+	})
+}
@@ -0,0 +1,113 @@
+// graphql.go
+package openapi2mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// graphQLAccept is the Accept header of GraphQL requests (GraphQL over HTTP).
+const graphQLAccept = "application/graphql-response+json, application/json"
+
+// isGraphQLOperation reports whether the operation is a generic GraphQL endpoint: a POST to a path ending in /graphql.
+func isGraphQLOperation(op OpenAPIOperation) bool {
+	return strings.EqualFold(op.Method, "post") && strings.EqualFold(path.Base(op.Path), "graphql")
+}
+
+// graphQLInputSchema replaces the requestBody argument of a GraphQL operation with the query, operationName,
+// and variables arguments of a GraphQL request. Other arguments (e.g. header parameters) are kept.
+func graphQLInputSchema(schema jsonschema.Schema) jsonschema.Schema {
+	props := make(map[string]*jsonschema.Schema, len(schema.Properties)+2)
+	maps.Copy(props, schema.Properties)
+	delete(props, "requestBody")
+	props["query"] = &jsonschema.Schema{
+		Type:        "string",
+		Description: "The GraphQL document: a query or mutation, e.g. \"query($id: ID!) { user(id: $id) { name } }\".",
+	}
+	props["operationName"] = &jsonschema.Schema{
+		Type:        "string",
+		Description: "Name of the operation to run, if the document contains several.",
+	}
+	props["variables"] = &jsonschema.Schema{
+		Type:        "object",
+		Description: "Values of the variables declared by the operation, e.g. {\"id\": \"42\"}.",
+	}
+	schema.Properties = props
+	schema.Required = append(slices.DeleteFunc(slices.Clone(schema.Required), func(name string) bool { return name == "requestBody" }), "query")
+	return schema
+}
+
+// graphQLRequestBody builds the JSON body of a GraphQL request from the tool arguments.
+func graphQLRequestBody(args map[string]any) ([]byte, error) {
+	query, _ := args["query"].(string)
+	if strings.TrimSpace(query) == "" {
+		return nil, errors.New("query is required")
+	}
+	req := map[string]any{"query": query}
+	if name, _ := args["operationName"].(string); name != "" {
+		req["operationName"] = name
+	}
+	if variables, ok := args["variables"].(map[string]any); ok && len(variables) > 0 {
+		req["variables"] = variables
+	}
+	return json.Marshal(req)
+}
+
+// graphQLError is an entry of the errors of a GraphQL response.
+type graphQLError struct {
+	Message   string `json:"message"`
+	Locations []struct {
+		Line   int `json:"line"`
+		Column int `json:"column"`
+	} `json:"locations"`
+	Path       []any          `json:"path"`
+	Extensions map[string]any `json:"extensions"`
+}
+
+// graphQLErrors returns the errors of a GraphQL response body and whether it carries data, i.e. whether it
+// succeeded at least partially. It returns no errors for bodies that are not GraphQL responses.
+func graphQLErrors(body []byte) (errs []graphQLError, hasData bool) {
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []graphQLError  `json:"errors"`
+	}
+	if json.Unmarshal(body, &resp) != nil {
+		return nil, false
+	}
+	return resp.Errors, len(resp.Data) > 0 && string(resp.Data) != "null"
+}
+
+// formatGraphQLErrors renders GraphQL errors as a numbered list, e.g.
+// "1. Cannot query field "nam" on type "User". (line 1, column 20; path: user.nam; code: GRAPHQL_VALIDATION_FAILED)".
+func formatGraphQLErrors(errs []graphQLError) string {
+	var b strings.Builder
+	for i, e := range errs {
+		fmt.Fprintf(&b, "%d. %s", i+1, e.Message)
+		var details []string
+		for _, loc := range e.Locations {
+			details = append(details, fmt.Sprintf("line %d, column %d", loc.Line, loc.Column))
+		}
+		if len(e.Path) > 0 {
+			segments := make([]string, len(e.Path))
+			for j, segment := range e.Path {
+				segments[j] = fmt.Sprint(segment)
+			}
+			details = append(details, "path: "+strings.Join(segments, "."))
+		}
+		if code, ok := e.Extensions["code"]; ok {
+			details = append(details, fmt.Sprintf("code: %v", code))
+		}
+		if len(details) > 0 {
+			b.WriteString(" (" + strings.Join(details, "; ") + ")")
+		}
+		b.WriteByte('\n')
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
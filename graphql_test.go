@@ -0,0 +1,82 @@
+package openapi2mcp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestFormatGraphQLErrors(t *testing.T) {
+	errs, hasData := graphQLErrors([]byte(`{"data":null,"errors":[{"message":"Cannot query field \"nam\" on type \"User\".","locations":[{"line":1,"column":20}],"path":["user",0,"nam"],"extensions":{"code":"GRAPHQL_VALIDATION_FAILED"}},{"message":"Not allowed"}]}`))
+	if hasData {
+		t.Error("expected no data for null")
+	}
+	want := "1. Cannot query field \"nam\" on type \"User\". (line 1, column 20; path: user.0.nam; code: GRAPHQL_VALIDATION_FAILED)\n2. Not allowed"
+	if got := formatGraphQLErrors(errs); got != want {
+		t.Errorf("formatGraphQLErrors() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestToolHandler_GraphQL(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /api/graphql:
+    post:
+      operationId: graphql
+      requestBody: {required: true, content: {application/json: {schema: {type: object}}}}
+      responses: {"200": {description: ok}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	op := ExtractOpenAPIOperations(doc)[0]
+	if !isGraphQLOperation(op) {
+		t.Fatal("expected a GraphQL operation")
+	}
+	schema := graphQLInputSchema(buildInputSchema(op.Parameters, op.RequestBody, newSchemaCache(nil), false))
+	if schema.Properties["requestBody"] != nil || schema.Properties["variables"] == nil || !slices.Equal(schema.Required, []string{"query"}) {
+		t.Errorf("expected query and variables arguments, got %v (required %v)", schema.Properties, schema.Required)
+	}
+
+	var received, accept, respBody string
+	opts := &ToolGenOptions{GraphQL: true, RequestHandler: func(req *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(req.Body)
+		received, accept = string(b), req.Header.Get("Accept")
+		return fakeResponse(200, "application/graphql-response+json", respBody)(req)
+	}}
+	handler := toolHandler("graphql", op, doc, jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+
+	respBody = `{"data":{"user":{"name":"Ada"}}}`
+	res, _, err := handler(context.Background(), nil, map[string]any{"query": "query($id: ID!) { user(id: $id) { name } }", "variables": map[string]any{"id": "1"}})
+	if err != nil || res.IsError {
+		t.Fatalf("unexpected error: %v %v", err, res)
+	}
+	if received != `{"query":"query($id: ID!) { user(id: $id) { name } }","variables":{"id":"1"}}` || accept != graphQLAccept {
+		t.Errorf("unexpected request %s (Accept: %s)", received, accept)
+	}
+
+	// Errors without data fail the call
+	respBody = `{"errors":[{"message":"Syntax Error","locations":[{"line":1,"column":3}]}]}`
+	res, _, _ = handler(context.Background(), nil, map[string]any{"query": "{ ("})
+	if !res.IsError || !strings.Contains(resultText(t, res), "1. Syntax Error (line 1, column 3)") {
+		t.Errorf("expected a GraphQL error, got %v", res.Content)
+	}
+
+	// Partial data is returned with the errors
+	respBody = `{"data":{"user":null},"errors":[{"message":"Not found","path":["user"]}]}`
+	res, _, _ = handler(context.Background(), nil, map[string]any{"query": "{ user(id: 2) { name } }"})
+	if res.IsError || !strings.Contains(resultText(t, res), "[GRAPHQL ERRORS: partial data was returned]\n1. Not found (path: user)") {
+		t.Errorf("expected partial data with errors, got %v", res.Content)
+	}
+
+	res, _, _ = handler(context.Background(), nil, map[string]any{})
+	if !res.IsError || !strings.Contains(resultText(t, res), "query is required") {
+		t.Errorf("expected an error without a query, got %v", res.Content)
+	}
+}
@@ -0,0 +1,56 @@
+// headers.go
+package openapi2mcp
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// responseHeaderPatterns returns the patterns of the response headers included in results of the operation.
+func responseHeaderPatterns(opts *ToolGenOptions, operationID string) []string {
+	return append(slices.Clip(opts.ResponseHeaders), opts.OperationResponseHeaders[operationID]...)
+}
+
+// selectResponseHeaders returns the headers matching patterns as sorted "Name: value" lines, with credentials
+// redacted. Patterns are header names, matched case-insensitively; a trailing "*" matches any suffix ("X-RateLimit-*").
+func selectResponseHeaders(header http.Header, patterns []string, rules *RedactionRules) []string {
+	if len(patterns) == 0 || len(header) == 0 {
+		return nil
+	}
+	selected := http.Header{}
+	for name, values := range header {
+		if matchesHeaderPattern(patterns, name) {
+			selected[name] = values
+		}
+	}
+	redacted := redactHeaders(selected, rules)
+	lines := make([]string, 0, len(redacted))
+	for _, name := range slices.Sorted(maps.Keys(redacted)) {
+		lines = append(lines, name+": "+redacted[name])
+	}
+	return lines
+}
+
+// matchesHeaderPattern reports whether the header name matches any of the patterns.
+func matchesHeaderPattern(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if len(name) >= len(prefix) && strings.EqualFold(name[:len(prefix)], prefix) {
+				return true
+			}
+		} else if strings.EqualFold(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatHeaderLines renders selected response headers as a "Headers:" section, or "" if there are none.
+func formatHeaderLines(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return "Headers:\n" + strings.Join(lines, "\n") + "\n"
+}
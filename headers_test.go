@@ -0,0 +1,110 @@
+package openapi2mcp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestSelectResponseHeaders(t *testing.T) {
+	header := http.Header{
+		"Location":              {"/pets/42"},
+		"X-Ratelimit-Remaining": {"99"},
+		"X-Ratelimit-Reset":     {"1700000000"},
+		"Set-Cookie":            {"session=secret"},
+		"Content-Type":          {"application/json"},
+	}
+	got := selectResponseHeaders(header, []string{"location", "X-RateLimit-*", "Set-Cookie"}, nil)
+	want := []string{"Location: /pets/42", "Set-Cookie: [REDACTED]", "X-Ratelimit-Remaining: 99", "X-Ratelimit-Reset: 1700000000"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("selectResponseHeaders() = %v, want %v", got, want)
+	}
+	if got := selectResponseHeaders(header, nil, nil); got != nil {
+		t.Errorf("expected no headers without patterns, got %v", got)
+	}
+}
+
+func TestToolHandler_ResponseHeaders(t *testing.T) {
+	status := 201
+	op := OpenAPIOperation{OperationID: "createPet", Path: "/pets", Method: "post"}
+	opts := &ToolGenOptions{
+		RequestHandler: func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: status,
+				Header:     http.Header{"Content-Type": {"application/json"}, "Location": {"/pets/42"}, "Etag": {`"v1"`}, "Retry-After": {"30"}},
+				Body:       io.NopCloser(strings.NewReader(`{}`)),
+				Request:    req,
+			}, nil
+		},
+		ResponseHeaders:          []string{"ETag", "Retry-After"},
+		OperationResponseHeaders: map[string][]string{"createPet": {"Location"}},
+	}
+	handler := toolHandler("createPet", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+	res, _, err := handler(context.Background(), nil, map[string]any{"__confirmed": true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(t, res); !strings.Contains(text, "Headers:\nEtag: \"v1\"\nLocation: /pets/42\nRetry-After: 30\nResponse:\n{}") {
+		t.Errorf("expected selected headers before the response, got: %s", text)
+	}
+
+	status = 429
+	res, _, _ = handler(context.Background(), nil, map[string]any{"__confirmed": true})
+	if text := resultText(t, res); !res.IsError || !strings.Contains(text, "Retry-After: 30") {
+		t.Errorf("expected selected headers in the error, got: %s", text)
+	}
+}
+
+func TestParameterValues(t *testing.T) {
+	exploded := true
+	tests := []struct {
+		name  string
+		param *openapi3.Parameter
+		val   any
+		want  [][2]string
+	}{
+		{"header scalar", &openapi3.Parameter{Name: "X-Id", In: "header", Schema: openapi3.NewIntegerSchema().NewRef()}, 5.0, [][2]string{{"X-Id", "5"}}},
+		{"header array", &openapi3.Parameter{Name: "X-Tags", In: "header", Schema: openapi3.NewArraySchema().WithItems(openapi3.NewIntegerSchema()).NewRef()}, []any{1.0, 2.0}, [][2]string{{"X-Tags", "1,2"}}},
+		{"exploded header array", &openapi3.Parameter{Name: "X-Tags", In: "header", Explode: &exploded}, []any{"a", "b"}, [][2]string{{"X-Tags", "a"}, {"X-Tags", "b"}}},
+		{"header object", &openapi3.Parameter{Name: "X-Dim", In: "header"}, map[string]any{"w": 2.0, "h": 1.0}, [][2]string{{"X-Dim", "h,1,w,2"}}},
+		{"exploded header object", &openapi3.Parameter{Name: "X-Dim", In: "header", Explode: &exploded}, map[string]any{"w": 2.0, "h": 1.0}, [][2]string{{"X-Dim", "h=1,w=2"}}},
+		{"cookie array", &openapi3.Parameter{Name: "id", In: "cookie"}, []any{"3", "4"}, [][2]string{{"id", "3"}, {"id", "4"}}},
+		{"cookie object", &openapi3.Parameter{Name: "prefs", In: "cookie"}, map[string]any{"theme": "dark"}, [][2]string{{"theme", "dark"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parameterValues(tt.param, tt.val, nil); !slices.Equal(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestToolHandler_MultiValueHeaderAndCookie(t *testing.T) {
+	var got http.Header
+	notExploded := false
+	op := OpenAPIOperation{OperationID: "listPets", Path: "/pets", Method: "get", Parameters: openapi3.Parameters{
+		{Value: &openapi3.Parameter{Name: "X-Tags", In: "header", Schema: openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema()).NewRef()}},
+		{Value: &openapi3.Parameter{Name: "ids", In: "cookie", Explode: &notExploded, Schema: openapi3.NewArraySchema().WithItems(openapi3.NewIntegerSchema()).NewRef()}},
+	}}
+	opts := &ToolGenOptions{RequestHandler: func(req *http.Request) (*http.Response, error) {
+		got = req.Header.Clone()
+		return fakeResponse(200, "application/json", `{}`)(req)
+	}}
+	handler := toolHandler("listPets", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+	if _, _, err := handler(context.Background(), nil, map[string]any{"X-Tags": []any{"cat", "dog"}, "ids": []any{1.0, 2.0}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Get("X-Tags") != "cat,dog" {
+		t.Errorf("expected comma-joined header values, got %q", got.Values("X-Tags"))
+	}
+	if got.Get("Cookie") != "ids=1,2" {
+		t.Errorf("expected a comma-joined cookie value, got %q", got.Get("Cookie"))
+	}
+}
@@ -0,0 +1,199 @@
+// hostpolicy.go
+package openapi2mcp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// HostPolicy restricts the hosts tool calls may send requests to, so that a crafted spec or
+// argument can't turn the server into a proxy into the network it runs in (SSRF).
+//
+// Requests may only go to the hosts of opts.BaseURL, OPENAPI_BASE_URL, the spec's servers, and AllowedHosts.
+// Unless AllowPrivateNetworks is set, hosts resolving to link-local addresses (such as the cloud metadata
+// endpoint 169.254.169.254) are always rejected, and hosts taken from the spec's servers or matched by a
+// wildcard may not resolve to private or loopback addresses. Redirects are subject to the same checks, and so are
+// requests sent through the proxy of HTTP_PROXY or HTTPS_PROXY, which may itself be on a private network.
+//
+// Address checks apply to requests sent by the default HTTP client only; a custom opts.RequestHandler
+// is responsible for its own connections.
+type HostPolicy struct {
+	AllowedHosts         []string // additional hosts: "api.example.com", "api.example.com:8443", or "*.example.com"
+	AllowPrivateNetworks bool     // if true, hosts may resolve to private, loopback, and link-local addresses
+	Disabled             bool     // if true, requests may go to any host
+}
+
+// hostGuard enforces a HostPolicy for the tools registered in one RegisterOpenAPITools call.
+type hostGuard struct {
+	allowed      map[string]bool // lowercase "host" (any port) or "host:port"
+	wildcards    []string        // lowercase domain suffixes including the leading dot
+	trusted      map[string]bool // lowercase hostnames that may resolve to private addresses
+	allowPrivate bool
+	transport    *http.Transport // dials only checked addresses, or the proxy
+}
+
+// newHostGuard builds the guard for tools sending requests to baseURLs. Unlike the spec's servers, the
+// configured URLs (opts.BaseURL and opts.BaseURLOverrides) and OPENAPI_BASE_URL are set by the operator and
+// may be on a private network. It returns nil if the policy is disabled.
+func newHostGuard(policy *HostPolicy, doc *openapi3.T, configuredURLs []string, baseURLs []string) *hostGuard {
+	if policy == nil {
+		policy = &HostPolicy{}
+	}
+	if policy.Disabled {
+		return nil
+	}
+	g := &hostGuard{
+		allowed:      make(map[string]bool),
+		trusted:      make(map[string]bool),
+		allowPrivate: policy.AllowPrivateNetworks,
+	}
+
+	var servers []string
+	if doc != nil {
+		for _, s := range doc.Servers {
+			if s != nil {
+				servers = append(servers, s.URL)
+			}
+		}
+		servers = append(servers, specTokenURLs(doc)...)
+	}
+	configured := append(slices.Clip(configuredURLs), os.Getenv("OPENAPI_BASE_URL"), os.Getenv("TOKEN_URL"))
+	if len(servers) == 0 {
+		// The default base URL
+		configured = append(configured, baseURLs...)
+	}
+	for _, raw := range append(servers, configured...) {
+		if u, err := url.Parse(raw); err == nil && u.Host != "" {
+			g.allowed[strings.ToLower(u.Hostname())] = true
+		}
+	}
+	for _, raw := range configured {
+		if u, err := url.Parse(raw); err == nil && u.Host != "" {
+			g.trusted[strings.ToLower(u.Hostname())] = true
+		}
+	}
+	for _, host := range policy.AllowedHosts {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if suffix, ok := strings.CutPrefix(host, "*"); ok && strings.HasPrefix(suffix, ".") {
+			g.wildcards = append(g.wildcards, suffix)
+			continue
+		}
+		g.allowed[host] = true
+		hostname := host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			hostname = h
+		}
+		g.trusted[strings.Trim(hostname, "[]")] = true
+	}
+
+	// The proxy is set by the operator, so it may be on a private network
+	proxies := httpproxy.FromEnvironment()
+	for _, raw := range []string{proxies.HTTPProxy, proxies.HTTPSProxy} {
+		if u, err := url.Parse(raw); err == nil && u.Host != "" {
+			g.trusted[strings.ToLower(u.Hostname())] = true
+		}
+	}
+	proxy := proxies.ProxyFunc()
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = func(req *http.Request) (*url.URL, error) { return proxy(req.URL) }
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		ips, err := g.lookup(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		// Dial the checked address, so that a second lookup can't return a different one
+		var dialErr error
+		for _, ip := range ips {
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+			dialErr = err
+		}
+		return nil, dialErr
+	}
+	g.transport = transport
+	return g
+}
+
+// lookup resolves host and returns its addresses, or an error if host may not connect to one of them.
+func (g *hostGuard) lookup(ctx context.Context, host string) ([]netip.Addr, error) {
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+	for _, ip := range ips {
+		if err := g.checkAddr(host, ip); err != nil {
+			return nil, err
+		}
+	}
+	return ips, nil
+}
+
+// RoundTrip sends req with the guarded transport. A request sent through a proxy has the addresses of its own host
+// checked first, as the transport then only dials the proxy.
+func (g *hostGuard) RoundTrip(req *http.Request) (*http.Response, error) {
+	proxyURL, err := g.transport.Proxy(req)
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL != nil {
+		if _, err := g.lookup(req.Context(), req.URL.Hostname()); err != nil {
+			return nil, err
+		}
+	}
+	return g.transport.RoundTrip(req)
+}
+
+// checkURL returns an error if the policy doesn't allow requests to the host of u.
+func (g *hostGuard) checkURL(u *url.URL) error {
+	host := strings.ToLower(u.Host)
+	hostname := strings.ToLower(u.Hostname())
+	if g.allowed[hostname] || g.allowed[host] {
+		return nil
+	}
+	for _, suffix := range g.wildcards {
+		if strings.HasSuffix(hostname, suffix) {
+			return nil
+		}
+	}
+	return fmt.Errorf("host %q is not allowed: requests may only go to the API's servers", u.Host)
+}
+
+// checkAddr returns an error if host may not connect to ip.
+func (g *hostGuard) checkAddr(host string, ip netip.Addr) error {
+	if g.allowPrivate {
+		return nil
+	}
+	ip = ip.Unmap()
+	if ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || isMetadataAddr(ip) {
+		return fmt.Errorf("host %q resolves to link-local address %s, which is not allowed", host, ip)
+	}
+	if (ip.IsPrivate() || ip.IsLoopback() || ip.IsUnspecified()) && !g.trusted[strings.ToLower(host)] {
+		return fmt.Errorf("host %q resolves to private address %s, which is not allowed for this host", host, ip)
+	}
+	return nil
+}
+
+// isMetadataAddr reports whether ip is a cloud metadata endpoint outside the link-local ranges,
+// such as the IPv6 endpoint of AWS.
+func isMetadataAddr(ip netip.Addr) bool {
+	return ip == netip.MustParseAddr("fd00:ec2::254")
+}
@@ -0,0 +1,161 @@
+package openapi2mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestHostGuard_CheckURL(t *testing.T) {
+	t.Setenv("OPENAPI_BASE_URL", "")
+	doc := minimalOpenAPIDoc()
+	doc.Servers = openapi3.Servers{{URL: "https://api.example.com/v1"}}
+	g := newHostGuard(&HostPolicy{AllowedHosts: []string{"*.cdn.example.com", "uploads.example.com:8443"}}, doc, nil, nil)
+
+	tests := map[string]bool{
+		"https://api.example.com/v1/pets":       true,
+		"https://API.example.com:8080/pets":     true,
+		"https://img.cdn.example.com/a.png":     true,
+		"https://uploads.example.com:8443/file": true,
+		"https://uploads.example.com/file":      false,
+		"https://evil.example.org/":             false,
+		"http://169.254.169.254/latest":         false,
+	}
+	for raw, want := range tests {
+		u, _ := url.Parse(raw)
+		if err := g.checkURL(u); (err == nil) != want {
+			t.Errorf("checkURL(%s) = %v, want allowed %v", raw, err, want)
+		}
+	}
+
+	if newHostGuard(&HostPolicy{Disabled: true}, doc, nil, nil) != nil {
+		t.Error("expected no guard for a disabled policy")
+	}
+}
+
+func TestHostGuard_CheckAddr(t *testing.T) {
+	t.Setenv("OPENAPI_BASE_URL", "")
+	doc := minimalOpenAPIDoc()
+	doc.Servers = openapi3.Servers{{URL: "http://internal.example.com"}}
+	g := newHostGuard(nil, doc, []string{"http://localhost:8080"}, nil)
+
+	tests := []struct {
+		host, ip string
+		want     bool
+	}{
+		{"internal.example.com", "93.184.216.34", true},
+		{"internal.example.com", "10.0.0.5", false},
+		{"internal.example.com", "127.0.0.1", false},
+		{"internal.example.com", "::ffff:192.168.1.1", false},
+		{"localhost", "127.0.0.1", true},
+		{"localhost", "169.254.169.254", false},
+		{"internal.example.com", "fd00:ec2::254", false},
+	}
+	for _, tc := range tests {
+		if err := g.checkAddr(tc.host, netip.MustParseAddr(tc.ip)); (err == nil) != tc.want {
+			t.Errorf("checkAddr(%s, %s) = %v, want allowed %v", tc.host, tc.ip, err, tc.want)
+		}
+	}
+
+	g = newHostGuard(&HostPolicy{AllowPrivateNetworks: true}, doc, nil, nil)
+	if err := g.checkAddr("internal.example.com", netip.MustParseAddr("169.254.169.254")); err != nil {
+		t.Errorf("expected AllowPrivateNetworks to allow any address, got %v", err)
+	}
+}
+
+func TestToolHandler_HostPolicy(t *testing.T) {
+	t.Setenv("OPENAPI_BASE_URL", "")
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "http://metadata.internal/latest", http.StatusFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok": true}`))
+	}))
+	defer upstream.Close()
+
+	doc := minimalOpenAPIDoc()
+	doc.Servers = openapi3.Servers{{URL: upstream.URL}}
+	op := OpenAPIOperation{OperationID: "getFoo", Path: "/foo", Method: "get"}
+	call := func(policy *HostPolicy, op OpenAPIOperation, baseURL string) (string, error) {
+		rt := newServerRuntime()
+		rt.hosts = newHostGuard(policy, doc, nil, []string{upstream.URL})
+		handler := toolHandler("getFoo", op, doc, jsonschema.Schema{}, []string{baseURL}, &ToolGenOptions{}, rt)
+		res, _, err := handler(context.Background(), nil, map[string]any{})
+		if err != nil {
+			return "", err
+		}
+		return resultText(t, res), nil
+	}
+
+	// The spec's servers may not point into private networks
+	if _, err := call(nil, op, upstream.URL); err == nil || !strings.Contains(err.Error(), "private address") {
+		t.Errorf("expected private address to be rejected, got %v", err)
+	}
+	if text, err := call(&HostPolicy{AllowPrivateNetworks: true}, op, upstream.URL); err != nil || !strings.Contains(text, `"ok"`) {
+		t.Errorf("expected request to succeed, got %q, %v", text, err)
+	}
+
+	// Redirects to other hosts are rejected
+	redirect := OpenAPIOperation{OperationID: "redirect", Path: "/redirect", Method: "get"}
+	if _, err := call(&HostPolicy{AllowPrivateNetworks: true}, redirect, upstream.URL); err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Errorf("expected redirect to be rejected, got %v", err)
+	}
+
+	// Requests to hosts outside the allowlist never leave the server
+	text, err := call(nil, op, "http://169.254.169.254")
+	if err != nil || !strings.Contains(text, "Request blocked") {
+		t.Errorf("expected blocked request, got %q, %v", text, err)
+	}
+}
+
+func TestToolHandler_HostPolicyBehindProxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok": true}`))
+	}))
+	defer proxy.Close()
+	t.Setenv("HTTP_PROXY", proxy.URL)
+	t.Setenv("NO_PROXY", "")
+	t.Setenv("OPENAPI_BASE_URL", "")
+
+	op := OpenAPIOperation{OperationID: "getFoo", Path: "/foo", Method: "get"}
+	call := func(server string) (string, error) {
+		doc := minimalOpenAPIDoc()
+		doc.Servers = openapi3.Servers{{URL: server}}
+		rt := newServerRuntime()
+		rt.hosts = newHostGuard(nil, doc, nil, []string{server})
+		handler := toolHandler("getFoo", op, doc, jsonschema.Schema{}, []string{server}, &ToolGenOptions{}, rt)
+		res, _, err := handler(context.Background(), nil, map[string]any{})
+		if err != nil {
+			return "", err
+		}
+		return resultText(t, res), nil
+	}
+
+	// The target's address is checked, not the proxy's
+	if _, err := call("http://10.0.0.1"); err == nil || !strings.Contains(err.Error(), "private address 10.0.0.1") {
+		t.Errorf("expected the private target to be rejected, got %v", err)
+	}
+	if len(proxied) != 0 {
+		t.Errorf("expected no request to reach the proxy, got %q", proxied)
+	}
+
+	// The proxy may be on a private network
+	if text, err := call("http://203.0.113.7"); err != nil || !strings.Contains(text, `"ok"`) {
+		t.Errorf("expected the request to go through the proxy, got %q, %v", text, err)
+	}
+	if len(proxied) != 1 || proxied[0] != "http://203.0.113.7/foo" {
+		t.Errorf("expected the request to be proxied, got %q", proxied)
+	}
+}
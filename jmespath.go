@@ -0,0 +1,75 @@
+// jmespath.go
+package openapi2mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jmespath/go-jmespath"
+)
+
+// jmespathExpr is a compiled JMESPath expression (https://jmespath.org/specification.html), used by the __filter
+// tool argument to select parts of JSON responses.
+type jmespathExpr struct {
+	jp *jmespath.JMESPath
+}
+
+// compileJMESPath parses a JMESPath expression.
+func compileJMESPath(expr string) (*jmespathExpr, error) {
+	jp, err := jmespath.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &jmespathExpr{jp: jp}, nil
+}
+
+// search evaluates the expression against data, as decoded by decodeJSONNumbers. Panics of the evaluator on odd
+// input are returned as errors, since the expression comes from the model.
+func (e *jmespathExpr) search(data any) (result any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("evaluating JMESPath expression: %v", r)
+		}
+	}()
+	return e.jp.Search(jmespathValue(data))
+}
+
+// jmespathValue converts the json.Number values of data to float64, as JMESPath numbers are doubles.
+func jmespathValue(data any) any {
+	switch v := data.(type) {
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	case []any:
+		out := make([]any, len(v))
+		for i, elem := range v {
+			out[i] = jmespathValue(elem)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, elem := range v {
+			out[key] = jmespathValue(elem)
+		}
+		return out
+	}
+	return data
+}
+
+// decodeJSONNumbers decodes a JSON document keeping numbers as json.Number, so that large integers survive.
+func decodeJSONNumbers(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err == nil {
+		return nil, errors.New("unexpected data after JSON value")
+	}
+	return v, nil
+}
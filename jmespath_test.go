@@ -0,0 +1,101 @@
+package openapi2mcp
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJMESPath(t *testing.T) {
+	data := `{
+		"items": [
+			{"metadata": {"name": "a", "labels": {"app": "web"}}, "status": "active", "replicas": 3, "tags": ["x", "y"]},
+			{"metadata": {"name": "b", "labels": {"app": "db"}}, "status": "failed", "replicas": 1, "tags": ["z"]},
+			{"metadata": {"name": "c"}, "status": "active", "replicas": 5}
+		],
+		"total": 3,
+		"weird key": true
+	}`
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"total", `3`},
+		{`"weird key"`, `true`},
+		{"missing.field", `null`},
+		{"items[0].metadata.name", `"a"`},
+		{"items[-1].metadata.name", `"c"`},
+		{"items[5]", `null`},
+		{"items[*].metadata.name", `["a","b","c"]`},
+		{"items[].metadata.labels.app", `["web","db"]`},
+		{"items[1:].status", `["failed","active"]`},
+		{"items[::-1].metadata.name", `["c","b","a"]`},
+		{"items[].tags[]", `["x","y","z"]`},
+		{"items[].tags | []", `["x","y","z"]`},
+		{"items[?status=='active'].metadata.name", `["a","c"]`},
+		{"items[?replicas > `2` && status != 'failed'].metadata.name", `["a","c"]`},
+		{"items[?!tags].metadata.name", `["c"]`},
+		{"items[?metadata.labels.app == 'db' || replicas == `3`].metadata.name", `["a","b"]`},
+		{"items[].{name: metadata.name, app: metadata.labels.app}", `[{"app":"web","name":"a"},{"app":"db","name":"b"},{"app":null,"name":"c"}]`},
+		{"items[0].[status, replicas]", `["active",3]`},
+		{"items[0].metadata.labels.*", `["web"]`},
+		{"items[*].metadata.name | [0]", `"a"`},
+		{"items[2].replicas", `5`},
+		{"length(items)", `3`},
+		{"sort(keys(items[0].metadata))", `["labels","name"]`},
+		{"sort_by(items, &metadata.name)[].metadata.name | reverse(@)", `["c","b","a"]`},
+		{"max_by(items, &replicas).metadata.name", `"c"`},
+		{"join(', ', items[].metadata.name)", `"a, b, c"`},
+		{"items[?contains(tags || `[]`, 'z')].metadata.name", `["b"]`},
+		{"items[?starts_with(status, 'fail')] | length(@)", `1`},
+		{"sum(items[0:2].replicas)", `4`},
+		{"not_null(missing, total)", `3`},
+		{"map(&status, items)", `["active","failed","active"]`},
+		{"to_string(total)", `"3"`},
+		{"`[1, 2]`", `[1,2]`},
+		{"'raw'", `"raw"`},
+	}
+	for _, tc := range tests {
+		expr, err := compileJMESPath(tc.expr)
+		if err != nil {
+			t.Errorf("compile %q: %v", tc.expr, err)
+			continue
+		}
+		doc, err := decodeJSONNumbers([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := expr.search(doc)
+		if err != nil {
+			t.Errorf("search %q: %v", tc.expr, err)
+			continue
+		}
+		got, _ := json.Marshal(result)
+		if string(got) != tc.want {
+			t.Errorf("search %q = %s, want %s", tc.expr, got, tc.want)
+		}
+	}
+}
+
+func TestJMESPath_Errors(t *testing.T) {
+	for _, expr := range []string{"", "items[", "items[?a ==]", "a.", "{a b}", "a = b", "'open", "a b"} {
+		if _, err := compileJMESPath(expr); err == nil {
+			t.Errorf("expected error compiling %q", expr)
+		}
+	}
+	for _, expr := range []string{"unknown_fn(a)", "items[0:1:0]"} {
+		compiled, err := compileJMESPath(expr)
+		if err == nil {
+			_, err = compiled.search(map[string]any{"items": []any{}})
+		}
+		if err == nil {
+			t.Errorf("expected error evaluating %q", expr)
+		}
+	}
+	expr, err := compileJMESPath("length(total)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := expr.search(map[string]any{"total": json.Number("3")}); err == nil {
+		t.Error("expected type error for length() of a number")
+	}
+}
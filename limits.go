@@ -0,0 +1,53 @@
+// limits.go
+package openapi2mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// DefaultMaxRequestBodyBytes is the serialized request body size limit used when ToolGenOptions.MaxRequestBodyBytes is 0.
+const DefaultMaxRequestBodyBytes = 10 << 20
+
+// DefaultMaxUploadFileBytes is the size limit of files uploaded via requestBodyFile, used when
+// ToolGenOptions.MaxUploadFileBytes is 0. Files are streamed, so it is independent of DefaultMaxRequestBodyBytes.
+const DefaultMaxUploadFileBytes = 1 << 30
+
+// DefaultMaxArgumentBytes is the size limit of each argument other than requestBody, used when
+// ToolGenOptions.MaxArgumentBytes is 0.
+const DefaultMaxArgumentBytes = 64 << 10
+
+// oversizedArgument returns the name and size of the first argument other than requestBody that
+// exceeds limit bytes, measured as the string itself or, for other values, as JSON.
+func oversizedArgument(args map[string]any, limit int) (name string, size int, ok bool) {
+	for _, name := range slices.Sorted(maps.Keys(args)) {
+		if name == "requestBody" {
+			continue
+		}
+		switch v := args[name].(type) {
+		case nil:
+			continue
+		case string:
+			size = len(v)
+		default:
+			b, err := json.Marshal(v)
+			if err != nil {
+				continue
+			}
+			size = len(b)
+		}
+		if size > limit {
+			return name, size, true
+		}
+	}
+	return "", 0, false
+}
+
+// payloadTooLargeText explains a rejected oversized argument or request body to the model.
+func payloadTooLargeText(what string, size, limit int, operationID, callID string) string {
+	return fmt.Sprintf("%s is too large: %d bytes, the limit is %d bytes. The request was not sent.\n"+
+		"Reduce the payload, e.g. by sending fewer items per call, splitting the data across several calls, or omitting optional fields.\n"+
+		"Operation: %s\nCall ID: %s", what, size, limit, operationID, callID)
+}
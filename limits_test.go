@@ -0,0 +1,57 @@
+package openapi2mcp
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestToolHandler_PayloadLimits(t *testing.T) {
+	op := OpenAPIOperation{
+		OperationID: "createPet",
+		Path:        "/pets",
+		Method:      "post",
+		Parameters: openapi3.Parameters{
+			&openapi3.ParameterRef{Value: &openapi3.Parameter{Name: "q", In: "query", Schema: openapi3.NewStringSchema().NewRef()}},
+		},
+		RequestBody: &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithJSONSchema(openapi3.NewObjectSchema())},
+	}
+	sent := 0
+	opts := &ToolGenOptions{
+		RequestHandler: func(req *http.Request) (*http.Response, error) {
+			sent++
+			return fakeResponse(200, "application/json", `{}`)(req)
+		},
+		MaxRequestBodyBytes: 100,
+		MaxArgumentBytes:    10,
+	}
+	handler := toolHandler("createPet", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+
+	tests := []struct {
+		args map[string]any
+		want string
+	}{
+		{map[string]any{"q": strings.Repeat("x", 11)}, "Argument q is too large: 11 bytes, the limit is 10 bytes"},
+		{map[string]any{"requestBody": map[string]any{"name": strings.Repeat("x", 100)}}, "The request body is too large"},
+	}
+	for _, tc := range tests {
+		res, _, err := handler(context.Background(), nil, tc.args)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if text := resultText(t, res); !res.IsError || !strings.Contains(text, tc.want) || !strings.Contains(text, "Reduce the payload") {
+			t.Errorf("expected %q, got: %s", tc.want, text)
+		}
+	}
+	if sent != 0 {
+		t.Errorf("expected no request to be sent, got %d", sent)
+	}
+
+	if res, _, _ := handler(context.Background(), nil, map[string]any{"q": "dogs", "requestBody": map[string]any{"name": "Rex"}}); res.IsError {
+		t.Errorf("unexpected error result: %s", resultText(t, res))
+	}
+}
@@ -0,0 +1,250 @@
+// links.go
+package openapi2mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// responseLinksFor returns the links documented for the given status code, falling back to the default response.
+func responseLinksFor(op OpenAPIOperation, statusCode int) openapi3.Links {
+	if op.Responses == nil {
+		return nil
+	}
+	respRef := op.Responses.Status(statusCode)
+	if respRef == nil {
+		respRef = op.Responses.Default()
+	}
+	if respRef == nil || respRef.Value == nil {
+		return nil
+	}
+	return respRef.Value.Links
+}
+
+// linkTargetOperationID returns the operationId a link points to, resolving local operationRefs
+// like "#/paths/~1pets~1{id}/get" against doc. Returns "" if the target is unknown.
+func linkTargetOperationID(link *openapi3.Link, doc *openapi3.T) string {
+	if link.OperationID != "" {
+		return link.OperationID
+	}
+	ref, ok := strings.CutPrefix(link.OperationRef, "#/paths/")
+	if !ok || doc == nil || doc.Paths == nil {
+		return ""
+	}
+	i := strings.LastIndexByte(ref, '/')
+	if i < 0 {
+		return ""
+	}
+	item := doc.Paths.Value(unescapeJSONPointer(ref[:i]))
+	if item == nil {
+		return ""
+	}
+	if op := item.GetOperation(strings.ToUpper(ref[i+1:])); op != nil {
+		return op.OperationID
+	}
+	return ""
+}
+
+// parameterLocations are the locations that may qualify link parameter names.
+var parameterLocations = []string{"path", "query", "header", "cookie"}
+
+// linkContext holds the exchange that link runtime expressions are evaluated against.
+type linkContext struct {
+	method     string
+	url        string
+	args       map[string]any // tool arguments of the request
+	statusCode int
+	header     http.Header
+	body       any // decoded JSON response body, or nil
+}
+
+// evaluate evaluates a link parameter value: runtime expressions like "$response.body#/id" or
+// "{$request.path.id}" are looked up in the exchange, other values are constants.
+func (c *linkContext) evaluate(value any) (any, string, bool) {
+	expr, ok := value.(string)
+	if !ok || !strings.Contains(expr, "$") {
+		return value, "", true
+	}
+	expr = strings.TrimSuffix(strings.TrimPrefix(expr, "{"), "}")
+	switch {
+	case expr == "$url":
+		return c.url, "the request URL", true
+	case expr == "$method":
+		return strings.ToUpper(c.method), "the request method", true
+	case expr == "$statusCode":
+		return c.statusCode, "the response status", true
+	case strings.HasPrefix(expr, "$response.body"):
+		ptr := strings.TrimPrefix(strings.TrimPrefix(expr, "$response.body"), "#")
+		v, ok := lookupJSONPointer(c.body, ptr)
+		return v, "response field " + pointerOrRoot(ptr), ok
+	case strings.HasPrefix(expr, "$response.header."):
+		name := strings.TrimPrefix(expr, "$response.header.")
+		v := c.header.Get(name)
+		return v, "response header " + name, v != ""
+	case strings.HasPrefix(expr, "$request.body"):
+		ptr := strings.TrimPrefix(strings.TrimPrefix(expr, "$request.body"), "#")
+		v, ok := lookupJSONPointer(c.args["requestBody"], ptr)
+		return v, "request field " + pointerOrRoot(ptr), ok
+	case strings.HasPrefix(expr, "$request."):
+		// $request.path.id, $request.query.limit, $request.header.X-Id
+		_, name, _ := strings.Cut(strings.TrimPrefix(expr, "$request."), ".")
+		v, ok := c.args[escapeParameterName(name)]
+		return v, "request argument " + name, ok
+	}
+	return value, "", true
+}
+
+// formatNextSteps renders the links of a successful response as a NEXT STEPS section appended to tool results.
+// Returns "" if the response documents no links.
+func formatNextSteps(links openapi3.Links, doc *openapi3.T, c *linkContext, nameFormat func(string) string) string {
+	if len(links) == 0 {
+		return ""
+	}
+	var lines []string
+	for _, linkName := range slices.Sorted(maps.Keys(links)) {
+		ref := links[linkName]
+		if ref == nil || ref.Value == nil {
+			continue
+		}
+		target := linkTargetOperationID(ref.Value, doc)
+		if target == "" {
+			continue
+		}
+		if nameFormat != nil {
+			target = nameFormat(target)
+		}
+
+		args := make(map[string]any)
+		var sources []string
+		complete := true
+		for _, param := range slices.Sorted(maps.Keys(ref.Value.Parameters)) {
+			v, source, ok := c.evaluate(ref.Value.Parameters[param])
+			if !ok {
+				complete = false
+				continue
+			}
+			// Parameters may be qualified by location, e.g. "path.id"
+			if in, name, found := strings.Cut(param, "."); found && slices.Contains(parameterLocations, in) {
+				param = name
+			}
+			param = escapeParameterName(param)
+			args[param] = v
+			if source != "" {
+				sources = append(sources, fmt.Sprintf("%s from %s", param, source))
+			}
+		}
+		if ref.Value.RequestBody != nil {
+			if v, _, ok := c.evaluate(ref.Value.RequestBody); ok {
+				args["requestBody"] = v
+			} else {
+				complete = false
+			}
+		}
+		if !complete {
+			continue
+		}
+
+		argsJSON, _ := json.Marshal(args)
+		line := fmt.Sprintf("• %s: call %s with %s", linkName, target, argsJSON)
+		if len(sources) > 0 {
+			line += " (" + strings.Join(sources, ", ") + ")"
+		}
+		if ref.Value.Description != "" {
+			line += " — " + ref.Value.Description
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "\n\nNEXT STEPS: The API documents these follow-up operations:\n" + strings.Join(lines, "\n")
+}
+
+// describeLinks lists the operations that the operation's responses link to, for tool descriptions.
+func describeLinks(op OpenAPIOperation, doc *openapi3.T, nameFormat func(string) string) string {
+	if op.Responses == nil {
+		return ""
+	}
+	var lines []string
+	for _, status := range slices.Sorted(maps.Keys(op.Responses.Map())) {
+		respRef := op.Responses.Value(status)
+		if respRef == nil || respRef.Value == nil {
+			continue
+		}
+		for _, linkName := range slices.Sorted(maps.Keys(respRef.Value.Links)) {
+			ref := respRef.Value.Links[linkName]
+			if ref == nil || ref.Value == nil {
+				continue
+			}
+			target := linkTargetOperationID(ref.Value, doc)
+			if target == "" {
+				continue
+			}
+			if nameFormat != nil {
+				target = nameFormat(target)
+			}
+			var params []string
+			for _, param := range slices.Sorted(maps.Keys(ref.Value.Parameters)) {
+				params = append(params, fmt.Sprintf("%s=%v", param, ref.Value.Parameters[param]))
+			}
+			line := fmt.Sprintf("\n• %s → %s(%s)", status, target, strings.Join(params, ", "))
+			if ref.Value.Description != "" {
+				line += ": " + ref.Value.Description
+			}
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "\n\nLINKS: Responses of this operation can be passed on to:" + strings.Join(lines, "")
+}
+
+// lookupJSONPointer returns the value at the JSON pointer ptr (e.g. "/items/0/id") within v.
+func lookupJSONPointer(v any, ptr string) (any, bool) {
+	if ptr == "" {
+		return v, v != nil
+	}
+	if !strings.HasPrefix(ptr, "/") {
+		return nil, false
+	}
+	for _, token := range strings.Split(ptr[1:], "/") {
+		token = unescapeJSONPointer(token)
+		switch node := v.(type) {
+		case map[string]any:
+			var ok bool
+			if v, ok = node[token]; !ok {
+				return nil, false
+			}
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			v = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, v != nil
+}
+
+// unescapeJSONPointer reverses escapeJSONPointer for a single reference token.
+func unescapeJSONPointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
+
+// pointerOrRoot returns ptr, or "/" for the whole document.
+func pointerOrRoot(ptr string) string {
+	if ptr == "" {
+		return "/"
+	}
+	return ptr
+}
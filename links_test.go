@@ -0,0 +1,89 @@
+package openapi2mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func linkedPetOperation() OpenAPIOperation {
+	responses := petResponses()
+	responses.Value("200").Value.Links = openapi3.Links{
+		"GetPetById": &openapi3.LinkRef{Value: &openapi3.Link{
+			OperationID: "getPet",
+			Parameters:  map[string]any{"path.id": "$response.body#/id"},
+			Description: "Fetch the created pet",
+		}},
+		"ListOwnerPets": &openapi3.LinkRef{Value: &openapi3.Link{
+			OperationRef: "#/paths/~1owners~1{owner}~1pets/get",
+			Parameters:   map[string]any{"owner": "{$request.body#/owner}", "limit": 10},
+		}},
+		"Missing": &openapi3.LinkRef{Value: &openapi3.Link{
+			OperationID: "getTag",
+			Parameters:  map[string]any{"tag": "$response.body#/tag"},
+		}},
+	}
+	return OpenAPIOperation{OperationID: "createPet", Path: "/pets", Method: "post", Responses: responses}
+}
+
+func linkedPetDoc() *openapi3.T {
+	doc := minimalOpenAPIDoc()
+	doc.Paths.Set("/owners/{owner}/pets", &openapi3.PathItem{Get: &openapi3.Operation{OperationID: "listOwnerPets"}})
+	return doc
+}
+
+func TestToolHandler_NextSteps(t *testing.T) {
+	op := linkedPetOperation()
+	opts := &ToolGenOptions{RequestHandler: fakeResponse(200, "application/json", `{"id": 42, "name": "Rex"}`)}
+	handler := toolHandler("createPet", op, linkedPetDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+	res, _, err := handler(context.Background(), nil, map[string]any{"requestBody": map[string]any{"owner": "alice"}, "__confirmed": true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(t, res)
+	for _, want := range []string{
+		"NEXT STEPS:",
+		`GetPetById: call getPet with {"id":42} (id from response field /id) — Fetch the created pet`,
+		`ListOwnerPets: call listOwnerPets with {"limit":10,"owner":"alice"} (owner from request field /owner)`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected result to contain %q, got:\n%s", want, text)
+		}
+	}
+	// Links whose values can't be resolved from the exchange are left out
+	if strings.Contains(text, "getTag") {
+		t.Errorf("expected unresolvable link to be omitted, got:\n%s", text)
+	}
+
+	opts.RequestHandler = fakeResponse(404, "application/json", `{"message": "not found"}`)
+	handler = toolHandler("createPet", op, linkedPetDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+	res, _, _ = handler(context.Background(), nil, map[string]any{"__confirmed": true})
+	if text := resultText(t, res); strings.Contains(text, "NEXT STEPS") {
+		t.Errorf("expected no next steps for error responses, got:\n%s", text)
+	}
+}
+
+func TestDescribeLinks(t *testing.T) {
+	desc := describeLinks(linkedPetOperation(), linkedPetDoc(), strings.ToUpper)
+	if !strings.Contains(desc, "LINKS:") || !strings.Contains(desc, "200 → GETPET(path.id=$response.body#/id): Fetch the created pet") {
+		t.Errorf("unexpected links description:\n%s", desc)
+	}
+	if got := describeLinks(OpenAPIOperation{Responses: petResponses()}, nil, nil); got != "" {
+		t.Errorf("expected no links section, got %q", got)
+	}
+}
+
+func TestLookupJSONPointer(t *testing.T) {
+	body := map[string]any{"items": []any{map[string]any{"a/b": "x"}}}
+	if v, ok := lookupJSONPointer(body, "/items/0/a~1b"); !ok || v != "x" {
+		t.Errorf("expected x, got %v, %v", v, ok)
+	}
+	for _, ptr := range []string{"/items/1", "/missing", "items"} {
+		if _, ok := lookupJSONPointer(body, ptr); ok {
+			t.Errorf("expected %q not to resolve", ptr)
+		}
+	}
+}
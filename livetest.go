@@ -0,0 +1,284 @@
+// livetest.go
+package openapi2mcp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// LiveSelfTestOptions configures SelfTestOpenAPIMCPLive.
+type LiveSelfTestOptions struct {
+	Operations     []string        // operationIds to call (default: GET operations, those with fewest required arguments first)
+	MaxOperations  int             // Maximum number of operations to call when Operations is empty (default 5)
+	Timeout        time.Duration   // Timeout per call (default 10s)
+	ToolGenOptions *ToolGenOptions // Options used to register the tools; Mock and DryRun are ignored
+}
+
+// liveCall is the upstream exchange captured for one probe call.
+type liveCall struct {
+	url      string
+	status   int
+	body     []byte
+	err      error
+	duration time.Duration
+}
+
+// probeSession calls tools on an in-process MCP server, one at a time, and captures the
+// upstream exchange of each call. It is used by the live self-test and contract testing.
+type probeSession struct {
+	cs         *mcp.ClientSession
+	tools      map[string]*mcp.Tool
+	nameFormat func(string) string
+	examples   ExampleGenerators
+	current    liveCall
+}
+
+// newProbeSession registers ops with opts (Mock and DryRun are ignored) and connects a client to them.
+func newProbeSession(ctx context.Context, doc *openapi3.T, ops []OpenAPIOperation, opts *ToolGenOptions) (*probeSession, error) {
+	toolOpts := ToolGenOptions{}
+	if opts != nil {
+		toolOpts = *opts
+	}
+	toolOpts.DryRun = false
+	toolOpts.Mock = false
+	toolOpts.SpecDrift = nil
+	p := &probeSession{nameFormat: toolOpts.NameFormat, examples: toolOpts.ExampleGenerators}
+
+	next := toolOpts.RequestHandler
+	if next == nil {
+		next = defaultRequestHandler
+	}
+	toolOpts.RequestHandler = func(req *http.Request) (*http.Response, error) {
+		p.current.url = req.URL.String()
+		resp, err := next(req)
+		if err != nil {
+			p.current.err = err
+			return nil, err
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			p.current.err = err
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		p.current.status = resp.StatusCode
+		p.current.body = body
+		return resp, nil
+	}
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "openapi-mcp-probe", Version: doc.Info.Version}, nil)
+	RegisterOpenAPITools(server, ops, doc, &toolOpts)
+	client := mcp.NewClient(&mcp.Implementation{Name: "openapi-mcp-probe-client", Version: doc.Info.Version}, nil)
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, serverTransport, nil); err != nil {
+		return nil, err
+	}
+	cs, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		return nil, err
+	}
+	p.cs = cs
+
+	listed, err := cs.ListTools(ctx, nil)
+	if err != nil {
+		cs.Close()
+		return nil, err
+	}
+	p.tools = make(map[string]*mcp.Tool, len(listed.Tools))
+	for _, tool := range listed.Tools {
+		p.tools[tool.Name] = tool
+	}
+	return p, nil
+}
+
+// call invokes the tool for op with example arguments generated from its input schema.
+// Dangerous operations are called with confirmation. It returns the captured exchange, the arguments,
+// and the tool result; err is only set if the tool is missing or the call itself failed.
+func (p *probeSession) call(ctx context.Context, op OpenAPIOperation, timeout time.Duration) (liveCall, map[string]any, *mcp.CallToolResult, error) {
+	name := op.OperationID
+	if p.nameFormat != nil {
+		name = p.nameFormat(name)
+	}
+	tool, ok := p.tools[name]
+	if !ok {
+		return liveCall{}, nil, nil, fmt.Errorf("tool '%s' (operationId) is missing from MCP server", op.OperationID)
+	}
+
+	args := generateExampleArguments(tool.InputSchema, p.examples)
+	if !strings.EqualFold(op.Method, http.MethodGet) {
+		args["__confirmed"] = true
+	}
+	p.current = liveCall{}
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	start := time.Now()
+	res, err := p.cs.CallTool(callCtx, &mcp.CallToolParams{Name: name, Arguments: args})
+	p.current.duration = time.Since(start).Round(time.Millisecond)
+	delete(args, "__confirmed")
+	return p.current, args, res, err
+}
+
+// Close closes the client session.
+func (p *probeSession) Close() error {
+	return p.cs.Close()
+}
+
+// SelfTestOpenAPIMCPLive registers the operations on an in-process MCP server and calls a subset of
+// safe (GET) operations against the real API, with example arguments generated from each tool's input schema.
+// It verifies that the base URL is reachable, that authentication is accepted, and that successful
+// responses match the documented response schemas. Results are printed to stderr like SelfTestOpenAPIMCP.
+// Only GET operations are ever called; listing another operation in Operations is an error.
+//
+// Example usage for SelfTestOpenAPIMCPLive:
+//
+//	err := openapi2mcp.SelfTestOpenAPIMCPLive(ctx, doc, ops, &openapi2mcp.LiveSelfTestOptions{Operations: []string{"listPets"}})
+//	if err != nil { log.Fatal(err) }
+func SelfTestOpenAPIMCPLive(ctx context.Context, doc *openapi3.T, ops []OpenAPIOperation, opts *LiveSelfTestOptions) error {
+	if opts == nil {
+		opts = &LiveSelfTestOptions{}
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	selected, err := liveSelfTestOperations(ops, opts)
+	if err != nil {
+		return err
+	}
+	if len(selected) == 0 {
+		fmt.Fprintf(os.Stderr, "[WARN] Live self-test skipped: no GET operations to call.\n")
+		return nil
+	}
+
+	session, err := newProbeSession(ctx, doc, selected, opts.ToolGenOptions)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	failures, warnings := 0, 0
+	for _, op := range selected {
+		current, args, res, err := session.call(ctx, op, timeout)
+		if args == nil {
+			fmt.Fprintf(os.Stderr, "[ERROR] Tool '%s' (operationId) is missing from MCP server.\n", op.OperationID)
+			failures++
+			continue
+		}
+
+		switch {
+		case current.err != nil || (current.status == 0 && err != nil):
+			cause := current.err
+			if cause == nil {
+				cause = err
+			}
+			fmt.Fprintf(os.Stderr, "[ERROR] Operation '%s' could not reach the API: %v\n", op.OperationID, cause)
+			fmt.Fprintf(os.Stderr, "  Suggestion: Check the base URL (servers in the spec, or OPENAPI_BASE_URL) and network access.\n")
+			failures++
+		case current.status == 0:
+			fmt.Fprintf(os.Stderr, "[ERROR] Operation '%s' was not sent: %s\n", op.OperationID, liveResultText(res))
+			failures++
+		case current.status == http.StatusUnauthorized || current.status == http.StatusForbidden:
+			fmt.Fprintf(os.Stderr, "[ERROR] Operation '%s' was rejected with HTTP %d (GET %s).\n", op.OperationID, current.status, current.url)
+			fmt.Fprintf(os.Stderr, "  Suggestion: Check the credentials (API_KEY, BEARER_TOKEN, or BASIC_AUTH) and their permissions.\n")
+			failures++
+		case current.status >= 500:
+			fmt.Fprintf(os.Stderr, "[ERROR] Operation '%s' failed with HTTP %d (GET %s).\n", op.OperationID, current.status, current.url)
+			failures++
+		case current.status >= 400:
+			// Generated example arguments often refer to resources that don't exist
+			fmt.Fprintf(os.Stderr, "[WARN] Operation '%s' returned HTTP %d with example arguments %s (GET %s).\n", op.OperationID, current.status, formatArgs(args), current.url)
+			fmt.Fprintf(os.Stderr, "  Suggestion: Add 'example' values to the required parameters, or pick operations without required arguments.\n")
+			warnings++
+		default:
+			if mismatches := validateResponseBody(op, current.status, current.body); len(mismatches) > 0 {
+				fmt.Fprintf(os.Stderr, "[ERROR] Operation '%s' returned HTTP %d, but the response does not match the documented schema:\n", op.OperationID, current.status)
+				for _, m := range mismatches {
+					fmt.Fprintf(os.Stderr, "  - %s\n", m)
+				}
+				fmt.Fprintf(os.Stderr, "  Suggestion: Update the response schema in the spec to match what the API returns.\n")
+				failures++
+			} else {
+				fmt.Fprintf(os.Stderr, "[OK] Operation '%s': HTTP %d in %s.\n", op.OperationID, current.status, current.duration)
+			}
+		}
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("live self-test failed: %d of %d operations failed, %d warnings. See errors and suggestions above.", failures, len(selected), warnings)
+	}
+	if warnings > 0 {
+		fmt.Fprintf(os.Stderr, "[INFO] Live self-test passed with %d warnings.\n", warnings)
+	} else {
+		fmt.Fprintf(os.Stderr, "[INFO] Live self-test passed: %d operations called successfully.\n", len(selected))
+	}
+	return nil
+}
+
+// liveSelfTestOperations selects the operations to call: the listed ones, which must all be GET
+// operations, or up to MaxOperations GET operations, preferring those with fewer required arguments.
+func liveSelfTestOperations(ops []OpenAPIOperation, opts *LiveSelfTestOptions) ([]OpenAPIOperation, error) {
+	if len(opts.Operations) > 0 {
+		var selected []OpenAPIOperation
+		for _, id := range opts.Operations {
+			i := slices.IndexFunc(ops, func(op OpenAPIOperation) bool { return op.OperationID == id })
+			if i < 0 {
+				return nil, fmt.Errorf("live self-test: unknown operation %q", id)
+			}
+			if !strings.EqualFold(ops[i].Method, http.MethodGet) {
+				return nil, fmt.Errorf("live self-test: operation %q is %s, only GET operations are called", id, strings.ToUpper(ops[i].Method))
+			}
+			selected = append(selected, ops[i])
+		}
+		return selected, nil
+	}
+
+	limit := opts.MaxOperations
+	if limit <= 0 {
+		limit = 5
+	}
+	var selected []OpenAPIOperation
+	for _, op := range ops {
+		if strings.EqualFold(op.Method, http.MethodGet) {
+			selected = append(selected, op)
+		}
+	}
+	slices.SortStableFunc(selected, func(a, b OpenAPIOperation) int {
+		return len(BuildInputSchema(a.Parameters, a.RequestBody).Required) - len(BuildInputSchema(b.Parameters, b.RequestBody).Required)
+	})
+	return selected[:min(limit, len(selected))], nil
+}
+
+// liveResultText returns the text of a tool result, for reporting calls that never reached the API.
+func liveResultText(res *mcp.CallToolResult) string {
+	if res != nil && len(res.Content) > 0 {
+		if text, ok := res.Content[0].(*mcp.TextContent); ok {
+			return text.Text
+		}
+	}
+	return "no result"
+}
+
+// formatArgs renders example arguments compactly for messages.
+func formatArgs(args map[string]any) string {
+	if len(args) == 0 {
+		return "{}"
+	}
+	parts := make([]string, 0, len(args))
+	for _, k := range slices.Sorted(maps.Keys(args)) {
+		parts = append(parts, fmt.Sprintf("%s=%v", k, args[k]))
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
@@ -1,90 +1,330 @@
 package openapi2mcp
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"os"
 	"strings"
+	"sync"
 	"time"
 )
 
-// logHTTPRequest logs an HTTP request in human-readable format
-func logHTTPRequest(req *http.Request, body []byte) {
-	timestamp := time.Now().Format("2006-01-02 15:04:05 MST")
-
-	log.Printf("┌─ HTTP REQUEST ────────────────────────────────────────────────────────────────")
-	log.Printf("│ 🕐 %s", timestamp)
-	log.Printf("│ 🌐 %s %s", req.Method, req.URL.String())
-
-	// Log headers (excluding sensitive auth headers in detail)
-	if len(req.Header) > 0 {
-		log.Printf("│ 📋 Headers:")
-		for name, values := range req.Header {
-			if strings.ToLower(name) == "authorization" {
-				log.Printf("│    %s: [REDACTED]", name)
-			} else if strings.ToLower(name) == "cookie" {
-				log.Printf("│    %s: [REDACTED]", name)
-			} else {
-				log.Printf("│    %s: %s", name, strings.Join(values, ", "))
-			}
-		}
+// Log message names used for HTTP traffic records.
+const (
+	logMsgHTTPRequest  = "http_request"
+	logMsgHTTPResponse = "http_response"
+)
+
+// maxLoggedBodyBytes is the maximum number of body bytes included in a log record.
+const maxLoggedBodyBytes = 1000
+
+// newLogger returns the logger used by tool handlers.
+// If opts.LogHandler is set it is used as-is. Otherwise HTTP traffic is logged to stderr when
+// MCP_LOG_HTTP or DEBUG is set, in the pretty human format or as JSON if MCP_LOG_FORMAT=json.
+func newLogger(opts *ToolGenOptions) *slog.Logger {
+	if opts != nil && opts.LogHandler != nil {
+		return slog.New(opts.LogHandler)
+	}
+	if os.Getenv("MCP_LOG_HTTP") == "" && os.Getenv("DEBUG") == "" {
+		return slog.New(discardHandler{})
+	}
+	handlerOpts := &slog.HandlerOptions{Level: slog.LevelDebug}
+	if strings.EqualFold(os.Getenv("MCP_LOG_FORMAT"), "json") {
+		return slog.New(slog.NewJSONHandler(os.Stderr, handlerOpts))
+	}
+	return slog.New(NewPrettyLogHandler(os.Stderr, handlerOpts))
+}
+
+// warnLogger returns the logger of warnings about the spec and configuration: opts.LogHandler if set, else nil, so
+// that warnf writes them to stderr.
+func warnLogger(opts *ToolGenOptions) *slog.Logger {
+	if opts == nil || opts.LogHandler == nil {
+		return nil
+	}
+	return slog.New(opts.LogHandler)
+}
+
+// warnf reports a problem with the spec or configuration to logger at warn level,
+// or to stderr if logger is nil.
+func warnf(logger *slog.Logger, format string, args ...any) {
+	if logger == nil {
+		fmt.Fprintf(os.Stderr, "[WARN] "+format+"\n", args...)
+		return
 	}
+	logger.Warn(fmt.Sprintf(format, args...))
+}
 
-	// Log body if present and not too large
+// discardHandler is a slog.Handler that drops all records.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// logHTTPRequest logs an outgoing upstream HTTP request at debug level.
+// Headers and body are redacted and truncated according to opts.
+func logHTTPRequest(ctx context.Context, logger *slog.Logger, req *http.Request, body []byte, opts *ToolGenOptions) {
+	if !logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("url", req.URL.String()),
+		slog.Any("headers", redactHeaders(req.Header, opts.Redaction)),
+	}
 	if len(body) > 0 {
-		if len(body) > 1000 {
-			log.Printf("│ 📄 Body: %s... (%d bytes)", string(body[:1000]), len(body))
+		attrs = append(attrs, slog.String("body", logBody(body, opts)), slog.Int("body_bytes", len(body)))
+	}
+	logger.LogAttrs(ctx, slog.LevelDebug, logMsgHTTPRequest, attrs...)
+}
+
+// logHTTPResponse logs an upstream HTTP response at debug level, or at warn level for 4xx/5xx responses.
+// The body is redacted and truncated according to opts.
+func logHTTPResponse(ctx context.Context, logger *slog.Logger, resp *http.Response, body []byte, opts *ToolGenOptions) {
+	level := slog.LevelDebug
+	if resp.StatusCode >= 400 {
+		level = slog.LevelWarn
+	}
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+	contentType := resp.Header.Get("Content-Type")
+	attrs := []slog.Attr{
+		slog.Int("status", resp.StatusCode),
+		slog.String("status_text", resp.Status),
+		slog.String("content_type", contentType),
+	}
+	if contentLength := resp.Header.Get("Content-Length"); contentLength != "" {
+		attrs = append(attrs, slog.String("content_length", contentLength))
+	}
+	if len(body) > 0 {
+		if strings.Contains(contentType, "json") || strings.Contains(contentType, "text") {
+			attrs = append(attrs, slog.String("body", logBody(body, opts)))
+		}
+		attrs = append(attrs, slog.Int("body_bytes", len(body)))
+	}
+	logger.LogAttrs(ctx, level, logMsgHTTPResponse, attrs...)
+}
+
+// redactHeaders returns a flattened copy of the headers with credentials and configured headers replaced.
+func redactHeaders(header http.Header, rules *RedactionRules) map[string]string {
+	out := make(map[string]string, len(header))
+	for name, values := range header {
+		if rules.redactsHeader(name) {
+			out[name] = redactedValue
 		} else {
-			log.Printf("│ 📄 Body: %s", string(body))
+			out[name] = strings.Join(values, ", ")
 		}
 	}
+	return out
+}
 
-	log.Printf("└───────────────────────────────────────────────────────────────────────────────")
+// logBody returns the body as a string for logging, redacted and truncated according to opts.
+func logBody(body []byte, opts *ToolGenOptions) string {
+	body = opts.Redaction.redactBody(body)
+	if !opts.DisableLogTruncation {
+		return truncateBody(body)
+	}
+	return string(body)
+}
+
+// truncateBody returns the body as a string, truncated to maxLoggedBodyBytes.
+func truncateBody(body []byte) string {
+	if len(body) > maxLoggedBodyBytes {
+		return fmt.Sprintf("%s... (%d bytes)", string(body[:maxLoggedBodyBytes]), len(body))
+	}
+	return string(body)
+}
+
+// PrettyLogHandler is a slog.Handler that renders HTTP traffic records in a human-readable box format.
+// Other records are rendered as a single line of key=value pairs.
+type PrettyLogHandler struct {
+	mu    *sync.Mutex
+	w     io.Writer
+	level slog.Leveler
+	attrs []slog.Attr
+	group string
 }
 
-// logHTTPResponse logs an HTTP response in human-readable format
-func logHTTPResponse(resp *http.Response, body []byte) {
-	timestamp := time.Now().Format("2006-01-02 15:04:05 MST")
+// NewPrettyLogHandler creates a PrettyLogHandler writing to w.
+// If opts is nil, records at info level and above are logged.
+func NewPrettyLogHandler(w io.Writer, opts *slog.HandlerOptions) *PrettyLogHandler {
+	h := &PrettyLogHandler{mu: &sync.Mutex{}, w: w, level: slog.LevelInfo}
+	if opts != nil && opts.Level != nil {
+		h.level = opts.Level
+	}
+	return h
+}
+
+// Enabled reports whether records at the given level are logged.
+func (h *PrettyLogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// WithAttrs returns a handler that includes the given attributes in every record.
+func (h *PrettyLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = append(append([]slog.Attr{}, h.attrs...), h.qualify(attrs)...)
+	return &h2
+}
+
+// WithGroup returns a handler that prefixes subsequent attribute keys with the group name.
+func (h *PrettyLogHandler) WithGroup(name string) slog.Handler {
+	h2 := *h
+	if h.group != "" {
+		name = h.group + "." + name
+	}
+	h2.group = name
+	return &h2
+}
+
+func (h *PrettyLogHandler) qualify(attrs []slog.Attr) []slog.Attr {
+	if h.group == "" {
+		return attrs
+	}
+	out := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		out[i] = slog.Attr{Key: h.group + "." + a.Key, Value: a.Value}
+	}
+	return out
+}
+
+// Handle renders a single record.
+func (h *PrettyLogHandler) Handle(_ context.Context, r slog.Record) error {
+	attrs := append([]slog.Attr{}, h.attrs...)
+	var recAttrs []slog.Attr
+	r.Attrs(func(a slog.Attr) bool {
+		recAttrs = append(recAttrs, a)
+		return true
+	})
+	attrs = append(attrs, h.qualify(recAttrs)...)
+
+	timestamp := r.Time
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	var sb strings.Builder
+	switch r.Message {
+	case logMsgHTTPRequest:
+		writePrettyHTTPRequest(&sb, timestamp, attrs)
+	case logMsgHTTPResponse:
+		writePrettyHTTPResponse(&sb, timestamp, attrs)
+	default:
+		sb.WriteString(fmt.Sprintf("%s %s %s", timestamp.Format("2006-01-02 15:04:05 MST"), r.Level, r.Message))
+		for _, a := range attrs {
+			sb.WriteString(fmt.Sprintf(" %s=%v", a.Key, a.Value.Any()))
+		}
+		sb.WriteString("\n")
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, sb.String())
+	return err
+}
+
+// writePrettyHTTPRequest renders an http_request record in box format.
+func writePrettyHTTPRequest(sb *strings.Builder, timestamp time.Time, attrs []slog.Attr) {
+	get := attrGetter(attrs)
+
+	sb.WriteString("┌─ HTTP REQUEST ────────────────────────────────────────────────────────────────\n")
+	sb.WriteString(fmt.Sprintf("│ 🕐 %s\n", timestamp.Format("2006-01-02 15:04:05 MST")))
+	sb.WriteString(fmt.Sprintf("│ 🌐 %s %s\n", get("method"), get("url")))
+
+	if headers, ok := attrValue(attrs, "headers").(map[string]string); ok && len(headers) > 0 {
+		sb.WriteString("│ 📋 Headers:\n")
+		for name, value := range headers {
+			sb.WriteString(fmt.Sprintf("│    %s: %s\n", name, value))
+		}
+	}
+	if body := get("body"); body != "" {
+		sb.WriteString(fmt.Sprintf("│ 📄 Body: %s\n", body))
+	}
+	writePrettyExtras(sb, attrs, "method", "url", "headers", "body", "body_bytes")
+
+	sb.WriteString("└───────────────────────────────────────────────────────────────────────────────\n")
+}
+
+// writePrettyHTTPResponse renders an http_response record in box format.
+func writePrettyHTTPResponse(sb *strings.Builder, timestamp time.Time, attrs []slog.Attr) {
+	get := attrGetter(attrs)
 
 	// Status icon based on response code
+	status, _ := attrValue(attrs, "status").(int64)
 	var statusIcon string
 	switch {
-	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+	case status >= 200 && status < 300:
 		statusIcon = "✅"
-	case resp.StatusCode >= 300 && resp.StatusCode < 400:
+	case status >= 300 && status < 400:
 		statusIcon = "🔄"
-	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+	case status >= 400 && status < 500:
 		statusIcon = "❌"
-	case resp.StatusCode >= 500:
+	case status >= 500:
 		statusIcon = "💥"
 	default:
 		statusIcon = "❓"
 	}
 
-	log.Printf("┌─ HTTP RESPONSE ───────────────────────────────────────────────────────────────")
-	log.Printf("│ 🕐 %s", timestamp)
-	log.Printf("│ %s %d %s", statusIcon, resp.StatusCode, resp.Status)
+	sb.WriteString("┌─ HTTP RESPONSE ───────────────────────────────────────────────────────────────\n")
+	sb.WriteString(fmt.Sprintf("│ 🕐 %s\n", timestamp.Format("2006-01-02 15:04:05 MST")))
+	sb.WriteString(fmt.Sprintf("│ %s %d %s\n", statusIcon, status, get("status_text")))
 
-	// Log important headers
-	if contentType := resp.Header.Get("Content-Type"); contentType != "" {
-		log.Printf("│ 📋 Content-Type: %s", contentType)
+	contentType := get("content_type")
+	if contentType != "" {
+		sb.WriteString(fmt.Sprintf("│ 📋 Content-Type: %s\n", contentType))
 	}
-	if contentLength := resp.Header.Get("Content-Length"); contentLength != "" {
-		log.Printf("│ 📋 Content-Length: %s", contentLength)
+	if contentLength := get("content_length"); contentLength != "" {
+		sb.WriteString(fmt.Sprintf("│ 📋 Content-Length: %s\n", contentLength))
+	}
+	if body := get("body"); body != "" {
+		sb.WriteString(fmt.Sprintf("│ 📄 Body: %s\n", body))
+	} else if n := get("body_bytes"); n != "" {
+		sb.WriteString(fmt.Sprintf("│ 📄 Body: [Binary content, %s bytes, type: %s]\n", n, contentType))
 	}
+	writePrettyExtras(sb, attrs, "status", "status_text", "content_type", "content_length", "body", "body_bytes")
 
-	// Log body if present and not too large
-	if len(body) > 0 {
-		contentType := resp.Header.Get("Content-Type")
-		if strings.Contains(contentType, "json") || strings.Contains(contentType, "text") {
-			if len(body) > 1000 {
-				log.Printf("│ 📄 Body: %s... (%d bytes)", string(body[:1000]), len(body))
-			} else {
-				log.Printf("│ 📄 Body: %s", string(body))
+	sb.WriteString("└───────────────────────────────────────────────────────────────────────────────\n")
+}
+
+// writePrettyExtras renders any attributes not already shown in the box.
+func writePrettyExtras(sb *strings.Builder, attrs []slog.Attr, known ...string) {
+	for _, a := range attrs {
+		isKnown := false
+		for _, k := range known {
+			if a.Key == k {
+				isKnown = true
+				break
 			}
-		} else {
-			log.Printf("│ 📄 Body: [Binary content, %d bytes, type: %s]", len(body), contentType)
+		}
+		if !isKnown {
+			sb.WriteString(fmt.Sprintf("│ 🔖 %s: %v\n", a.Key, a.Value.Any()))
+		}
+	}
+}
+
+// attrValue returns the resolved value of the last attribute with the given key.
+func attrValue(attrs []slog.Attr, key string) any {
+	var v any
+	for _, a := range attrs {
+		if a.Key == key {
+			v = a.Value.Resolve().Any()
 		}
 	}
+	return v
+}
 
-	log.Printf("└───────────────────────────────────────────────────────────────────────────────")
+// attrGetter returns a function that formats attribute values as strings.
+func attrGetter(attrs []slog.Attr) func(key string) string {
+	return func(key string) string {
+		v := attrValue(attrs, key)
+		if v == nil {
+			return ""
+		}
+		return fmt.Sprintf("%v", v)
+	}
 }
@@ -0,0 +1,105 @@
+package openapi2mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestLogHandler_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	op := OpenAPIOperation{OperationID: "getPet", Path: "/pet", Method: "get"}
+	opts := &ToolGenOptions{
+		LogHandler:     slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}),
+		RequestHandler: fakeResponse(200, "application/json", `{"id": 1}`),
+	}
+	t.Setenv("BEARER_TOKEN", "secret")
+	handler := toolHandler("getPet", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+	if _, _, err := handler(context.Background(), nil, map[string]any{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log records, got %d: %s", len(lines), buf.String())
+	}
+	var rec map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+		t.Fatalf("invalid JSON log record: %v", err)
+	}
+	if rec["msg"] != logMsgHTTPRequest || rec["url"] != "http://example.com/pet" {
+		t.Errorf("unexpected request record: %v", rec)
+	}
+	if strings.Contains(buf.String(), "secret") {
+		t.Errorf("expected Authorization header to be redacted: %s", buf.String())
+	}
+}
+
+func TestPrettyLogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewPrettyLogHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	logger.Debug(logMsgHTTPResponse, "status", 404, "status_text", "404 Not Found", "content_type", "application/json", "body", `{"error": "missing"}`)
+	out := buf.String()
+	for _, want := range []string{"HTTP RESPONSE", "❌ 404 404 Not Found", `Body: {"error": "missing"}`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	logger.Info("hello", "key", "value")
+	if !strings.Contains(buf.String(), "INFO hello key=value") {
+		t.Errorf("unexpected plain record: %s", buf.String())
+	}
+}
+
+func TestToolHandler_CallID(t *testing.T) {
+	var buf bytes.Buffer
+	var upstreamID string
+	op := OpenAPIOperation{OperationID: "getPet", Path: "/pet", Method: "get"}
+	opts := &ToolGenOptions{
+		LogHandler:      slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}),
+		RequestIDHeader: "X-Request-ID",
+		RequestHandler: func(req *http.Request) (*http.Response, error) {
+			upstreamID = req.Header.Get("X-Request-ID")
+			return fakeResponse(200, "application/json", `{}`)(req)
+		},
+	}
+	handler := toolHandler("getPet", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+	res, _, err := handler(context.Background(), nil, map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	callID, _ := res.Meta[callIDMetaKey].(string)
+	if callID == "" || callID != upstreamID {
+		t.Fatalf("expected call ID %q to match upstream header %q", callID, upstreamID)
+	}
+	if !strings.Contains(resultText(t, res), "Call ID: "+callID) {
+		t.Errorf("expected call ID in result text")
+	}
+	if !strings.Contains(buf.String(), `"call_id":"`+callID+`"`) {
+		t.Errorf("expected call ID in logs: %s", buf.String())
+	}
+}
+
+func TestRegisterOpenAPITools_Warnings(t *testing.T) {
+	var buf bytes.Buffer
+	doc := minimalOpenAPIDoc()
+	doc.Paths.Value("/foo").Get.Parameters = openapi3.Parameters{
+		&openapi3.ParameterRef{Value: &openapi3.Parameter{Name: "id", In: "matrix", Schema: openapi3.NewStringSchema().NewRef()}},
+	}
+	opts := &ToolGenOptions{LogHandler: slog.NewTextHandler(&buf, nil)}
+	RegisterOpenAPITools(mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil), ExtractOpenAPIOperations(doc), doc, opts)
+	if out := buf.String(); !strings.Contains(out, "level=WARN") || !strings.Contains(out, "Parameter 'id' uses unsupported location 'matrix'.") {
+		t.Errorf("expected warning to be logged, got: %q", out)
+	}
+}
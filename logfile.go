@@ -0,0 +1,191 @@
+// logfile.go
+package openapi2mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Log message names used for MCP traffic records.
+const (
+	logMsgMCPRequest  = "mcp_request"
+	logMsgMCPResponse = "mcp_response"
+)
+
+// RotatingFileWriter is an io.WriteCloser that writes to a file and rotates it once it exceeds a size limit.
+// Rotated files are renamed to <path>.1, <path>.2, ... up to MaxBackups; older files are removed.
+type RotatingFileWriter struct {
+	mu         sync.Mutex
+	path       string
+	maxBytes   int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// NewRotatingFileWriter opens (or creates) the log file at path for appending.
+// If maxBytes is <= 0 the file is never rotated. maxBackups is the number of rotated files to keep.
+func NewRotatingFileWriter(path string, maxBytes int64, maxBackups int) (*RotatingFileWriter, error) {
+	w := &RotatingFileWriter{path: path, maxBytes: maxBytes, maxBackups: maxBackups}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *RotatingFileWriter) open() error {
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file = f
+	w.size = info.Size()
+	return nil
+}
+
+// Write appends p to the current file, rotating first if p would exceed the size limit.
+func (w *RotatingFileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.maxBytes > 0 && w.size > 0 && w.size+int64(len(p)) > w.maxBytes {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// rotate shifts existing backups, moves the current file to <path>.1, and reopens a fresh file.
+func (w *RotatingFileWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	if w.maxBackups > 0 {
+		os.Remove(fmt.Sprintf("%s.%d", w.path, w.maxBackups))
+		for i := w.maxBackups - 1; i >= 1; i-- {
+			os.Rename(fmt.Sprintf("%s.%d", w.path, i), fmt.Sprintf("%s.%d", w.path, i+1))
+		}
+		if err := os.Rename(w.path, w.path+".1"); err != nil {
+			return err
+		}
+	} else if err := os.Truncate(w.path, 0); err != nil {
+		return err
+	}
+	return w.open()
+}
+
+// Close closes the underlying file.
+func (w *RotatingFileWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
+
+// sessionIDs holds generated correlation IDs for sessions without a transport-assigned ID (e.g. stdio).
+var sessionIDs sync.Map
+
+// sessionCorrelationID returns a stable ID identifying the MCP session in logs.
+func sessionCorrelationID(session mcp.Session) string {
+	if session == nil {
+		return ""
+	}
+	if id := session.ID(); id != "" {
+		return id
+	}
+	if id, ok := sessionIDs.Load(session); ok {
+		return id.(string)
+	}
+	id, _ := sessionIDs.LoadOrStore(session, newRandomID())
+	return id.(string)
+}
+
+// requestSessionID returns the correlation ID of the MCP session of req, "" if there is none.
+func requestSessionID(req *mcp.CallToolRequest) string {
+	if req == nil || req.Session == nil {
+		return ""
+	}
+	return sessionCorrelationID(req.Session)
+}
+
+// newRandomID returns a random 16-character hex identifier.
+func newRandomID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// redactedJSON marshals v to JSON with sensitive keys and configured tool argument paths redacted,
+// truncating the result unless disabled in opts.
+func redactedJSON(v any, opts *ToolGenOptions) string {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	var generic any
+	if err := json.Unmarshal(raw, &generic); err == nil {
+		raw, _ = json.Marshal(opts.Redaction.redactToolArguments(redactJSONValue(generic)))
+	}
+	if !opts.DisableLogTruncation {
+		return truncateBody(raw)
+	}
+	return string(raw)
+}
+
+// NewTrafficLogMiddleware returns MCP receiving middleware that logs every request and response
+// with its session correlation ID, method, duration, and redacted parameters/results.
+// Truncation and redaction follow opts.DisableLogTruncation and opts.Redaction (opts may be nil).
+// Use it together with a JSON slog.Handler over a RotatingFileWriter to produce JSONL traffic logs.
+//
+//	w, _ := openapi2mcp.NewRotatingFileWriter("mcp.log", 10<<20, 3)
+//	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: slog.LevelDebug})
+//	srv.AddReceivingMiddleware(openapi2mcp.NewTrafficLogMiddleware(handler, opts))
+func NewTrafficLogMiddleware(handler slog.Handler, opts *ToolGenOptions) mcp.Middleware {
+	if opts == nil {
+		opts = &ToolGenOptions{}
+	}
+	logger := slog.New(handler)
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			sessionID := sessionCorrelationID(req.GetSession())
+			logger.LogAttrs(ctx, slog.LevelInfo, logMsgMCPRequest,
+				slog.String("session_id", sessionID),
+				slog.String("method", method),
+				slog.String("params", redactedJSON(req.GetParams(), opts)),
+			)
+
+			start := time.Now()
+			result, err := next(ctx, method, req)
+
+			attrs := []slog.Attr{
+				slog.String("session_id", sessionID),
+				slog.String("method", method),
+				slog.Duration("duration", time.Since(start)),
+			}
+			if err != nil {
+				attrs = append(attrs, slog.String("error", err.Error()))
+				logger.LogAttrs(ctx, slog.LevelError, logMsgMCPResponse, attrs...)
+			} else {
+				attrs = append(attrs, slog.String("result", redactedJSON(result, opts)))
+				logger.LogAttrs(ctx, slog.LevelInfo, logMsgMCPResponse, attrs...)
+			}
+			return result, err
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package openapi2mcp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotatingFileWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mcp.log")
+	w, err := NewRotatingFileWriter(path, 10, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer w.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("write failed: %v", err)
+		}
+	}
+
+	want := map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	}
+	for file, content := range want {
+		got, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("expected %s to exist: %v", file, err)
+		}
+		if string(got) != content {
+			t.Errorf("%s: expected %q, got %q", file, content, string(got))
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected only 2 backups to be kept")
+	}
+}
+
+func TestRedactedJSON(t *testing.T) {
+	out := redactedJSON(map[string]any{
+		"name":      "widget",
+		"api_key":   "abc123",
+		"nested":    map[string]any{"Password": "hunter2"},
+		"arguments": []any{map[string]any{"token": "xyz"}},
+	}, &ToolGenOptions{DisableLogTruncation: true})
+	for _, secret := range []string{"abc123", "hunter2", "xyz"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("expected %q to be redacted: %s", secret, out)
+		}
+	}
+	if !strings.Contains(out, "widget") {
+		t.Errorf("expected non-sensitive values to be kept: %s", out)
+	}
+}
+
+func TestRedactionRules(t *testing.T) {
+	rules := &RedactionRules{
+		Headers:   []string{"X-Card-Token"},
+		JSONPaths: []string{"$.card.number", "$.items[*].cvv"},
+	}
+	if !rules.redactsHeader("x-card-token") || !rules.redactsHeader("Authorization") || rules.redactsHeader("Accept") {
+		t.Errorf("unexpected header redaction decisions")
+	}
+
+	body := rules.redactBody([]byte(`{"card": {"number": "4111111111111111", "brand": "visa"}, "items": [{"cvv": "123"}, {"cvv": "456"}]}`))
+	for _, secret := range []string{"4111111111111111", "123", "456"} {
+		if strings.Contains(string(body), secret) {
+			t.Errorf("expected %q to be redacted: %s", secret, body)
+		}
+	}
+	if !strings.Contains(string(body), "visa") {
+		t.Errorf("expected unrelated fields to be kept: %s", body)
+	}
+
+	out := redactedJSON(map[string]any{
+		"name":      "charge",
+		"arguments": map[string]any{"requestBody": map[string]any{"card": map[string]any{"number": "4242"}}},
+	}, &ToolGenOptions{Redaction: rules})
+	if strings.Contains(out, "4242") {
+		t.Errorf("expected tool argument body path to be redacted: %s", out)
+	}
+}
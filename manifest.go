@@ -0,0 +1,204 @@
+// manifest.go
+package openapi2mcp
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ServerManifest is a machine-readable description of the MCP server generated for a spec, for publishing
+// to MCP server registries and directories.
+type ServerManifest struct {
+	Name           string              `json:"name"`
+	Title          string              `json:"title,omitempty"`
+	Description    string              `json:"description,omitempty"`
+	Version        string              `json:"version"`
+	Homepage       string              `json:"homepage,omitempty"`
+	License        string              `json:"license,omitempty"`
+	Transports     []ManifestTransport `json:"transports"`
+	Auth           []ManifestAuth      `json:"auth,omitempty"`
+	Environment    []ManifestEnvVar    `json:"environment,omitempty"`
+	Tools          []ManifestTool      `json:"tools"`
+	APIBaseURLs    []string            `json:"apiBaseUrls,omitempty"`
+	OpenAPIVersion string              `json:"openapiVersion,omitempty"`
+}
+
+// ManifestTransport is a way to connect to the server: "stdio" with Command, or "streamable-http" with URL.
+type ManifestTransport struct {
+	Type    string   `json:"type"`
+	Command string   `json:"command,omitempty"`
+	Args    []string `json:"args,omitempty"`
+	URL     string   `json:"url,omitempty"`
+}
+
+// ManifestAuth is a security scheme of the API and the environment variable supplying its credentials.
+type ManifestAuth struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme,omitempty"`
+	In          string `json:"in,omitempty"`
+	ParamName   string `json:"paramName,omitempty"`
+	Description string `json:"description,omitempty"`
+	EnvVar      string `json:"envVar,omitempty"`
+}
+
+// ManifestEnvVar is an environment variable read by the server.
+type ManifestEnvVar struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Secret      bool   `json:"secret,omitempty"`
+}
+
+// ManifestTool summarizes a tool of the server.
+type ManifestTool struct {
+	Name        string               `json:"name"`
+	Description string               `json:"description,omitempty"`
+	Annotations *mcp.ToolAnnotations `json:"annotations,omitempty"`
+}
+
+// ManifestOptions controls BuildServerManifest.
+//
+// Name: registry name of the server (default: derived from the API title, e.g. "petstore-mcp")
+// Command, Args: command line starting the server over stdio (default: "openapi-mcp" with no arguments)
+// HTTPURL: if set, the public URL of the streamable HTTP endpoint
+// ToolGenOptions: options the tools are registered with, as in server mode
+type ManifestOptions struct {
+	Name           string
+	Command        string
+	Args           []string
+	HTTPURL        string
+	ToolGenOptions *ToolGenOptions
+}
+
+// BuildServerManifest describes the MCP server serving ops of doc: its identity from the spec's info section,
+// its transports, the credentials required by the spec's security schemes, and its tools as MCP clients list them.
+//
+// Example usage for BuildServerManifest:
+//
+//	manifest, err := openapi2mcp.BuildServerManifest(ctx, doc, ops, &openapi2mcp.ManifestOptions{Args: []string{"petstore.yaml"}})
+//	if err != nil { log.Fatal(err) }
+//	json.NewEncoder(os.Stdout).Encode(manifest)
+func BuildServerManifest(ctx context.Context, doc *openapi3.T, ops []OpenAPIOperation, opts *ManifestOptions) (*ServerManifest, error) {
+	if opts == nil {
+		opts = &ManifestOptions{}
+	}
+	tools, err := registeredTools(ctx, doc, ops, opts.ToolGenOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &ServerManifest{
+		Name:           opts.Name,
+		OpenAPIVersion: doc.OpenAPI,
+	}
+	if manifest.Name == "" {
+		manifest.Name = codegenModuleName(doc)
+	}
+	if doc.Info != nil {
+		manifest.Title = doc.Info.Title
+		manifest.Description = firstParagraph(doc.Info.Description)
+		manifest.Version = doc.Info.Version
+		if doc.Info.License != nil {
+			manifest.License = doc.Info.License.Name
+		}
+	}
+	if doc.ExternalDocs != nil {
+		manifest.Homepage = doc.ExternalDocs.URL
+	}
+	for _, server := range doc.Servers {
+		if server != nil && server.URL != "" {
+			manifest.APIBaseURLs = append(manifest.APIBaseURLs, server.URL)
+		}
+	}
+
+	command := opts.Command
+	if command == "" {
+		command = "openapi-mcp"
+	}
+	manifest.Transports = []ManifestTransport{{Type: "stdio", Command: command, Args: opts.Args}}
+	if opts.HTTPURL != "" {
+		manifest.Transports = append(manifest.Transports, ManifestTransport{Type: "streamable-http", URL: opts.HTTPURL})
+	}
+
+	manifest.Auth = manifestAuth(doc, ops)
+	manifest.Environment = []ManifestEnvVar{{Name: "OPENAPI_BASE_URL", Description: "Base URL of the API, overriding the spec's servers"}}
+	seen := make(map[string]bool)
+	for _, auth := range manifest.Auth {
+		if auth.EnvVar != "" && !seen[auth.EnvVar] {
+			seen[auth.EnvVar] = true
+			manifest.Environment = append(manifest.Environment, ManifestEnvVar{Name: auth.EnvVar, Description: "Credentials for " + auth.Name, Secret: true})
+		}
+	}
+
+	for _, tool := range tools {
+		manifest.Tools = append(manifest.Tools, ManifestTool{
+			Name:        tool.Name,
+			Description: firstParagraph(tool.Description),
+			Annotations: tool.Annotations,
+		})
+	}
+	slices.SortFunc(manifest.Tools, func(a, b ManifestTool) int { return strings.Compare(a.Name, b.Name) })
+	return manifest, nil
+}
+
+// manifestAuth lists the security schemes required by doc or any of ops, with the environment
+// variables fulfillSecurity reads their credentials from.
+func manifestAuth(doc *openapi3.T, ops []OpenAPIOperation) []ManifestAuth {
+	if doc.Components == nil || len(doc.Components.SecuritySchemes) == 0 {
+		return nil
+	}
+	used := make(map[string]bool)
+	for _, req := range doc.Security {
+		for name := range req {
+			used[name] = true
+		}
+	}
+	for _, op := range ops {
+		for _, req := range op.Security {
+			for name := range req {
+				used[name] = true
+			}
+		}
+	}
+
+	var auth []ManifestAuth
+	for _, name := range slices.Sorted(maps.Keys(used)) {
+		ref := doc.Components.SecuritySchemes[name]
+		if ref == nil || ref.Value == nil {
+			continue
+		}
+		scheme := ref.Value
+		entry := ManifestAuth{
+			Name:        name,
+			Type:        scheme.Type,
+			Scheme:      scheme.Scheme,
+			In:          scheme.In,
+			ParamName:   scheme.Name,
+			Description: scheme.Description,
+		}
+		switch {
+		case scheme.Type == "http" && scheme.Scheme == "bearer", scheme.Type == "oauth2":
+			entry.EnvVar = "BEARER_TOKEN"
+		case scheme.Type == "http" && scheme.Scheme == "basic":
+			entry.EnvVar = "BASIC_AUTH"
+		case scheme.Type == "apiKey":
+			entry.EnvVar = "API_KEY"
+		}
+		auth = append(auth, entry)
+	}
+	return auth
+}
+
+// firstParagraph returns the first paragraph of s, trimmed.
+func firstParagraph(s string) string {
+	s = strings.TrimSpace(strings.ReplaceAll(s, "\r\n", "\n"))
+	if i := strings.Index(s, "\n\n"); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,48 @@
+package openapi2mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestBuildServerManifest(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(codegenSpec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	doc.Info.Description = "Manage pets.\n\nLonger details."
+
+	manifest, err := BuildServerManifest(context.Background(), doc, ExtractOpenAPIOperations(doc), &ManifestOptions{
+		Args:    []string{"petstore.yaml"},
+		HTTPURL: "https://mcp.example.com/petstore",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if manifest.Name != "pet-store-v2-mcp" || manifest.Version != "2.0.0" || manifest.Description != "Manage pets." {
+		t.Errorf("unexpected identity: %+v", manifest)
+	}
+	if len(manifest.Transports) != 2 || manifest.Transports[0].Command != "openapi-mcp" || manifest.Transports[1].URL != "https://mcp.example.com/petstore" {
+		t.Errorf("unexpected transports: %+v", manifest.Transports)
+	}
+	if len(manifest.Auth) != 1 || manifest.Auth[0].Name != "apiKey" || manifest.Auth[0].EnvVar != "API_KEY" || manifest.Auth[0].ParamName != "X-API-Key" {
+		t.Errorf("unexpected auth: %+v", manifest.Auth)
+	}
+	var names []string
+	for _, tool := range manifest.Tools {
+		names = append(names, tool.Name)
+	}
+	if got := strings.Join(names, ","); got != "createPet,getPet,info,listPets" {
+		t.Errorf("unexpected tools: %s", got)
+	}
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `{"name":"API_KEY","description":"Credentials for apiKey","secret":true}`) {
+		t.Errorf("expected API_KEY environment variable, got %s", data)
+	}
+}
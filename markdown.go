@@ -0,0 +1,304 @@
+// markdown.go
+package openapi2mcp
+
+import (
+	"bytes"
+	"mime"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+// isHTML reports whether the media type is HTML.
+func isHTML(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
+
+// skippedElements hold page chrome or no readable text; their content is dropped.
+var skippedElements = map[string]bool{
+	"head": true, "script": true, "style": true, "noscript": true, "template": true, "svg": true,
+	"nav": true, "aside": true, "footer": true, "iframe": true, "button": true, "select": true,
+}
+
+// blockElements start on a new paragraph.
+var blockElements = map[string]bool{
+	"p": true, "div": true, "section": true, "article": true, "main": true, "header": true, "blockquote": true,
+	"table": true, "ul": true, "ol": true, "dl": true, "figure": true, "form": true, "address": true,
+}
+
+// htmlToMarkdown converts an HTML page to Markdown text, keeping its readable content: if the page has a
+// <main> or <article> element, only the first such element is converted, and navigation, scripts, styles, and
+// similar elements are dropped. Headings, paragraphs, lists, links, emphasis, code, and table rows are kept.
+func htmlToMarkdown(body []byte) string {
+	root := readableElement(body)
+	depth := 0 // nesting of root elements being in
+	m := &markdownWriter{}
+	z := html.NewTokenizer(bytes.NewReader(body))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			return m.String()
+		}
+		token := z.Token()
+		if root != "" && token.Data == root {
+			switch tt {
+			case html.StartTagToken:
+				depth++
+				continue
+			case html.EndTagToken:
+				if depth--; depth == 0 {
+					return m.String()
+				}
+				continue
+			}
+		}
+		if root != "" && depth == 0 {
+			continue
+		}
+		switch tt {
+		case html.TextToken:
+			m.text(token.Data)
+		case html.StartTagToken:
+			m.start(token.Data, token.Attr)
+		case html.SelfClosingTagToken:
+			m.start(token.Data, token.Attr)
+			m.end(token.Data)
+		case html.EndTagToken:
+			m.end(token.Data)
+		}
+	}
+}
+
+// readableElement returns "main" if the page has a <main> element, else "article" if it has an <article>
+// element, else "".
+func readableElement(body []byte) string {
+	var article bool
+	z := html.NewTokenizer(bytes.NewReader(body))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if article {
+				return "article"
+			}
+			return ""
+		case html.StartTagToken:
+			name, _ := z.TagName()
+			switch string(name) {
+			case "main":
+				return "main"
+			case "article":
+				article = true
+			}
+		}
+	}
+}
+
+// markdownWriter accumulates the Markdown rendering of HTML elements.
+type markdownWriter struct {
+	buf       []byte
+	skip      string   // name of the skipped element being in, if any
+	skipDepth int      // nesting of skip elements of the same name
+	pre       int      // nesting of <pre> elements
+	links     []string // targets of the open <a> elements
+	lists     []int    // open lists: the next item number for <ol>, -1 for <ul>
+	cells     int      // cells written in the current table row
+}
+
+func (m *markdownWriter) String() string {
+	return string(bytes.TrimSpace(m.buf))
+}
+
+func (m *markdownWriter) write(s string) {
+	m.buf = append(m.buf, s...)
+}
+
+// text writes the unescaped text between tags, collapsing whitespace outside of <pre>.
+func (m *markdownWriter) text(s string) {
+	if m.skip != "" || s == "" {
+		return
+	}
+	if m.pre > 0 {
+		m.write(s)
+		return
+	}
+	words := strings.Fields(s)
+	if len(words) == 0 || unicode.IsSpace(rune(s[0])) {
+		m.space()
+	}
+	if len(words) == 0 {
+		return
+	}
+	m.write(strings.Join(words, " "))
+	if unicode.IsSpace(rune(s[len(s)-1])) {
+		m.space()
+	}
+}
+
+// space separates words of adjacent text nodes.
+func (m *markdownWriter) space() {
+	if !m.atLineStart() && m.buf[len(m.buf)-1] != ' ' {
+		m.buf = append(m.buf, ' ')
+	}
+}
+
+func (m *markdownWriter) atLineStart() bool {
+	return len(m.buf) == 0 || m.buf[len(m.buf)-1] == '\n'
+}
+
+// newline ends the current line, if any.
+func (m *markdownWriter) newline() {
+	m.trimSpace()
+	if !m.atLineStart() {
+		m.buf = append(m.buf, '\n')
+	}
+}
+
+// paragraph starts a new paragraph.
+func (m *markdownWriter) paragraph() {
+	m.newline()
+	if len(m.buf) > 0 && !bytes.HasSuffix(m.buf, []byte("\n\n")) {
+		m.buf = append(m.buf, '\n')
+	}
+}
+
+// trimSpace removes trailing spaces of the current line.
+func (m *markdownWriter) trimSpace() {
+	m.buf = bytes.TrimRight(m.buf, " ")
+}
+
+func (m *markdownWriter) start(name string, attrs []html.Attribute) {
+	if m.skip != "" {
+		if name == m.skip {
+			m.skipDepth++
+		}
+		return
+	}
+	if skippedElements[name] {
+		m.skip, m.skipDepth = name, 1
+		return
+	}
+	switch {
+	case len(name) == 2 && name[0] == 'h' && name[1] >= '1' && name[1] <= '6':
+		m.paragraph()
+		m.write(strings.Repeat("#", int(name[1]-'0')) + " ")
+	case blockElements[name]:
+		if (name == "ul" || name == "ol") && len(m.lists) > 0 {
+			m.newline() // nested lists continue the item
+		} else {
+			m.paragraph()
+		}
+		if name == "ul" || name == "ol" {
+			next := -1
+			if name == "ol" {
+				next = 1
+			}
+			m.lists = append(m.lists, next)
+		}
+		if name == "blockquote" {
+			m.write("> ")
+		}
+	case name == "li":
+		m.newline()
+		m.write(strings.Repeat("  ", max(len(m.lists)-1, 0)))
+		if n := len(m.lists); n > 0 && m.lists[n-1] > 0 {
+			m.write(strconv.Itoa(m.lists[n-1]) + ". ")
+			m.lists[n-1]++
+		} else {
+			m.write("- ")
+		}
+	case name == "br":
+		m.newline()
+	case name == "hr":
+		m.paragraph()
+		m.write("---\n\n")
+	case name == "pre":
+		m.paragraph()
+		m.write("```\n")
+		m.pre++
+	case name == "code" && m.pre == 0:
+		m.write("`")
+	case name == "strong" || name == "b":
+		m.write("**")
+	case name == "em" || name == "i":
+		m.write("_")
+	case name == "a":
+		var href string
+		for _, attr := range attrs {
+			if attr.Namespace == "" && attr.Key == "href" {
+				href = strings.TrimSpace(attr.Val)
+				break
+			}
+		}
+		if strings.HasPrefix(strings.ToLower(href), "javascript:") || strings.HasPrefix(href, "#") {
+			href = ""
+		}
+		m.links = append(m.links, href)
+		if href != "" {
+			m.write("[")
+		}
+	case name == "tr":
+		m.newline()
+		m.cells = 0
+	case name == "td" || name == "th":
+		if m.cells > 0 {
+			m.write(" | ")
+		}
+		m.cells++
+	case name == "dt":
+		m.newline()
+	case name == "dd":
+		m.newline()
+		m.write(": ")
+	}
+}
+
+func (m *markdownWriter) end(name string) {
+	if m.skip != "" {
+		if name == m.skip {
+			if m.skipDepth--; m.skipDepth == 0 {
+				m.skip = ""
+			}
+		}
+		return
+	}
+	switch {
+	case len(name) == 2 && name[0] == 'h' && name[1] >= '1' && name[1] <= '6':
+		m.paragraph()
+	case blockElements[name]:
+		if (name == "ul" || name == "ol") && len(m.lists) > 0 {
+			if m.lists = m.lists[:len(m.lists)-1]; len(m.lists) > 0 {
+				m.newline()
+				break
+			}
+		}
+		m.paragraph()
+	case name == "pre":
+		if m.pre > 0 {
+			m.pre--
+			m.newline()
+			m.write("```\n\n")
+		}
+	case name == "code" && m.pre == 0:
+		m.trimSpace()
+		m.write("`")
+	case name == "strong" || name == "b":
+		m.trimSpace()
+		m.write("**")
+	case name == "em" || name == "i":
+		m.trimSpace()
+		m.write("_")
+	case name == "a":
+		if n := len(m.links); n > 0 {
+			if href := m.links[n-1]; href != "" {
+				m.trimSpace()
+				m.write("](" + href + ")")
+			}
+			m.links = m.links[:n-1]
+		}
+	case name == "tr":
+		m.newline()
+	}
+}
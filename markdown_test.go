@@ -0,0 +1,143 @@
+package openapi2mcp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const testHTMLPage = `<!DOCTYPE html>
+<html><head><title>Docs</title><style>body { color: red; }</style></head>
+<body>
+<nav><a href="/">Home</a> | <a href="/about">About</a></nav>
+<main>
+  <h1>Getting  started</h1>
+  <!-- build: 42 -->
+  <p>Install the <code>cli</code> tool, then read the <a href="/guide?a=1&amp;b=2">guide</a>.
+     It is <strong>fast</strong> &amp; <em>small</em>.</p>
+  <script>if (a < b) { alert("x"); }</script>
+  <ol><li>Download</li><li>Run<ul><li>on Linux</li></ul></li></ol>
+  <pre>make
+make install</pre>
+  <table><tr><th>Name</th><th>Value</th></tr><tr><td>a</td><td>1</td></tr></table>
+</main>
+<footer>Copyright</footer>
+</body></html>`
+
+func TestHTMLToMarkdown(t *testing.T) {
+	want := "# Getting started\n\n" +
+		"Install the `cli` tool, then read the [guide](/guide?a=1&b=2). It is **fast** & _small_.\n\n" +
+		"1. Download\n2. Run\n  - on Linux\n\n" +
+		"```\nmake\nmake install\n```\n\n" +
+		"Name | Value\na | 1"
+	if got := htmlToMarkdown([]byte(testHTMLPage)); got != want {
+		t.Errorf("htmlToMarkdown() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestHTMLToMarkdown_Markup(t *testing.T) {
+	tests := map[string]struct {
+		html string
+		want string
+	}{
+		"nesting": {
+			`<div><p>a <b>b <i>c</i></b></p><div><div><p>d</p></div></div></div>`,
+			"a **b _c_**\n\nd",
+		},
+		"nested skipped elements": {
+			`<nav>x<nav>y</nav>z</nav><p>after</p>`,
+			"after",
+		},
+		"attribute containing >": {
+			`<p title="a > b"><a data-x='1>2' href="/x?q=a>b">link</a> text</p>`,
+			"[link](/x?q=a>b) text",
+		},
+		"unquoted attribute": {
+			`<a href=/plain>plain</a>`,
+			"[plain](/plain)",
+		},
+		"comments": {
+			`<p>a<!-- <b>hidden</b> -->b</p><!-- <script> --><p>c</p>`,
+			"ab\n\nc",
+		},
+		"script and style": {
+			`<p>a</p><script>document.write("</p><p>x")</script><style>p > b { color: red }</style><SCRIPT type="module">if (a < b) {}</SCRIPT><p>b</p>`,
+			"a\n\nb",
+		},
+		"unclosed tags": {
+			`<ul><li>one<li>two</ul><p>para <b>bold`,
+			"- one\n- two\n\npara **bold",
+		},
+		"escaped text": {
+			`<p>&lt;b&gt; &amp;amp; <code>List&lt;String&gt;</code></p>`,
+			"<b> &amp; `List<String>`",
+		},
+		"stray less-than": {
+			`<p>a < b and 1 <2</p>`,
+			"a < b and 1 <2",
+		},
+		"first article": {
+			`<p>intro</p><article><h2>One</h2><article>inner</article></article><article>Two</article>`,
+			"## One\n\ninner",
+		},
+	}
+	for name, tc := range tests {
+		if got := htmlToMarkdown([]byte(tc.html)); got != tc.want {
+			t.Errorf("%s: htmlToMarkdown() = %q, want %q", name, got, tc.want)
+		}
+	}
+}
+
+func TestRegisterOpenAPITools_HTMLToMarkdown(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /docs:
+    get: {operationId: getDocs, responses: {"200": {description: ok, content: {text/html: {schema: {type: string}}}}}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	RegisterOpenAPITools(server, ExtractOpenAPIOperations(doc), doc, &ToolGenOptions{
+		HTMLToMarkdown: true,
+		RequestHandler: func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: 200, Header: http.Header{"Content-Type": {"text/html; charset=utf-8"}}, Body: io.NopCloser(strings.NewReader(testHTMLPage)), Request: req}, nil
+		},
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(context.Background(), serverTransport, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil).Connect(context.Background(), clientTransport, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cs.Close()
+
+	res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "getDocs", Arguments: map[string]any{}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(t, res)
+	callID, _ := res.Meta[callIDMetaKey].(string)
+	if !strings.Contains(text, "Response:\n# Getting started") || !strings.Contains(text, "[CONVERTED FROM HTML: the raw page is available as the resource result://"+callID+".]") {
+		t.Errorf("expected Markdown with a pointer to the raw page, got: %s", text)
+	}
+
+	raw, err := cs.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "result://" + callID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if raw.Contents[0].Text != testHTMLPage || raw.Contents[0].MIMEType != "text/html; charset=utf-8" {
+		t.Errorf("expected the raw page, got %+v", raw.Contents[0])
+	}
+	if _, err := cs.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "result://unknown"}); err == nil {
+		t.Error("expected an error for an unknown call ID")
+	}
+}
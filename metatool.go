@@ -0,0 +1,146 @@
+// metatool.go
+package openapi2mcp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// MetaToolHandler handles a call of a user-defined meta tool. ops gives access to the operation
+// tools registered alongside it, so that a meta tool can combine or wrap API calls.
+type MetaToolHandler func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any, ops *OperationRegistry) (*mcp.CallToolResult, error)
+
+// MetaTool is a user-defined tool registered by RegisterOpenAPITools next to the generated ones,
+// e.g. a company-specific "open_ticket" tool. If Tool.InputSchema is nil, any object is accepted.
+type MetaTool struct {
+	Tool    *mcp.Tool
+	Handler MetaToolHandler
+}
+
+// OperationRegistry gives meta tools access to the operation tools registered in the same
+// RegisterOpenAPITools call. It is safe for concurrent use.
+type OperationRegistry struct {
+	doc *openapi3.T
+
+	mu       sync.RWMutex
+	names    []string
+	ops      map[string]OpenAPIOperation
+	handlers map[string]func(context.Context, *mcp.CallToolRequest, map[string]any) (*mcp.CallToolResult, any, error)
+}
+
+// newOperationRegistry creates an empty registry for the operations of doc.
+func newOperationRegistry(doc *openapi3.T) *OperationRegistry {
+	return &OperationRegistry{
+		doc:      doc,
+		ops:      make(map[string]OpenAPIOperation),
+		handlers: make(map[string]func(context.Context, *mcp.CallToolRequest, map[string]any) (*mcp.CallToolResult, any, error)),
+	}
+}
+
+// add records the handler of an operation tool. A later tool with the same name replaces the earlier one.
+func (r *OperationRegistry) add(name string, op OpenAPIOperation, handler func(context.Context, *mcp.CallToolRequest, map[string]any) (*mcp.CallToolResult, any, error)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.ops[name]; !ok {
+		r.names = append(r.names, name)
+	}
+	r.ops[name] = op
+	r.handlers[name] = handler
+}
+
+// Doc returns the OpenAPI document the operations were generated from.
+func (r *OperationRegistry) Doc() *openapi3.T {
+	return r.doc
+}
+
+// ToolNames returns the names of the operation tools, sorted.
+func (r *OperationRegistry) ToolNames() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := slices.Clone(r.names)
+	slices.Sort(names)
+	return names
+}
+
+// Operation returns the operation behind the tool named toolName.
+func (r *OperationRegistry) Operation(toolName string) (OpenAPIOperation, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	op, ok := r.ops[toolName]
+	return op, ok
+}
+
+// Call invokes the operation tool named toolName with args, exactly as an MCP client would, except that args
+// are not validated against the tool's input schema beforehand. Dangerous operations still require
+// "__confirmed": true in args if confirmation is enabled.
+//
+// Example usage for Call:
+//
+//	res, err := ops.Call(ctx, "createIssue", map[string]any{"requestBody": map[string]any{"title": title}, "__confirmed": true})
+//	if err != nil { return nil, err }
+func (r *OperationRegistry) Call(ctx context.Context, toolName string, args map[string]any) (*mcp.CallToolResult, error) {
+	return r.callAs(ctx, nil, toolName, args)
+}
+
+// callAs invokes the operation tool like Call, on behalf of the MCP request req that led to the call, e.g. that of
+// schedule_call, so that the policy, forwarded headers, sandbox routing, and session cookies apply as for a direct
+// call. req may be nil.
+func (r *OperationRegistry) callAs(ctx context.Context, req *mcp.CallToolRequest, toolName string, args map[string]any) (*mcp.CallToolResult, error) {
+	r.mu.RLock()
+	handler, ok := r.handlers[toolName]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown operation tool %q", toolName)
+	}
+	if args == nil {
+		args = map[string]any{}
+	}
+	res, _, err := handler(ctx, req, args)
+	return res, err
+}
+
+// registerMetaTools adds the user-defined meta tools to server and returns their names.
+func registerMetaTools(server *mcp.Server, tools []MetaTool, registry *OperationRegistry, logger *slog.Logger) []string {
+	var names []string
+	for _, meta := range tools {
+		if meta.Tool == nil || meta.Handler == nil {
+			continue
+		}
+		if _, ok := registry.Operation(meta.Tool.Name); ok {
+			warnf(logger, "Meta tool %q replaces the operation tool of the same name", meta.Tool.Name)
+		}
+		handler := meta.Handler
+		mcp.AddTool(server, meta.Tool, func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+			res, err := handler(ctx, req, args, registry)
+			return res, nil, err
+		})
+		names = append(names, meta.Tool.Name)
+	}
+	return names
+}
+
+// isMetaTool reports whether name is a built-in or user-defined meta tool rather than an operation.
+func isMetaTool(name string, opts *ToolGenOptions) bool {
+	if slices.Contains(metaToolNames, name) {
+		return true
+	}
+	if opts != nil {
+		for _, meta := range opts.MetaTools {
+			if meta.Tool != nil && meta.Tool.Name == name {
+				return true
+			}
+		}
+		for _, w := range opts.Workflows {
+			if w.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package openapi2mcp
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestRegisterOpenAPITools_MetaTools(t *testing.T) {
+	doc := minimalOpenAPIDoc()
+	doc.ExternalDocs = &openapi3.ExternalDocs{URL: "https://docs.example.com"}
+	openTicket := MetaTool{
+		Tool: &mcp.Tool{Name: "open_ticket", Description: "Open a support ticket about the Foo resource."},
+		Handler: func(ctx context.Context, _ *mcp.CallToolRequest, args map[string]any, ops *OperationRegistry) (*mcp.CallToolResult, error) {
+			if _, ok := ops.Operation("getFoo"); !ok {
+				t.Errorf("expected getFoo in registry, got %v", ops.ToolNames())
+			}
+			res, err := ops.Call(ctx, "getFoo", nil)
+			if err != nil {
+				return nil, err
+			}
+			text := "ticket " + args["title"].(string) + ": " + res.Content[0].(*mcp.TextContent).Text
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}, nil
+		},
+	}
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	names := RegisterOpenAPITools(server, ExtractOpenAPIOperations(doc), doc, &ToolGenOptions{
+		RequestHandler:          fakeResponse(200, "application/json", `{"foo": "bar"}`),
+		DisableInfoTool:         true,
+		DisableExternalDocsTool: true,
+		MetaTools:               []MetaTool{openTicket},
+	})
+	if !toolSetEqual(names, []string{"getFoo", "open_ticket"}) {
+		t.Errorf("unexpected tools: %v", names)
+	}
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(context.Background(), serverTransport, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil).Connect(context.Background(), clientTransport, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cs.Close()
+
+	listed, err := cs.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var listedNames []string
+	for _, tool := range listed.Tools {
+		listedNames = append(listedNames, tool.Name)
+	}
+	if slices.Contains(listedNames, "info") || slices.Contains(listedNames, "externalDocs") {
+		t.Errorf("expected disabled built-ins to be absent, got %v", listedNames)
+	}
+
+	res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "open_ticket", Arguments: map[string]any{"title": "broken"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(t, res); !strings.HasPrefix(text, "ticket broken: ") || !strings.Contains(text, `"foo": "bar"`) {
+		t.Errorf("unexpected meta tool result: %s", text)
+	}
+}
+
+func TestOperationRegistry_UnknownTool(t *testing.T) {
+	if _, err := newOperationRegistry(nil).Call(context.Background(), "missing", nil); err == nil {
+		t.Error("expected error for unknown tool")
+	}
+}
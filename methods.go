@@ -0,0 +1,61 @@
+// methods.go
+package openapi2mcp
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// headersResultMethod reports whether results of method lead with the response headers:
+// HEAD responses have no body, and OPTIONS responses carry their answer in the Allow header.
+func headersResultMethod(method string) bool {
+	return strings.EqualFold(method, http.MethodHead) || strings.EqualFold(method, http.MethodOptions)
+}
+
+// describeMethod explains the result of HEAD, OPTIONS, and TRACE operations in their tool description.
+// For OPTIONS, the methods documented for the path are listed.
+func describeMethod(op OpenAPIOperation, doc *openapi3.T) string {
+	switch strings.ToUpper(op.Method) {
+	case http.MethodHead:
+		return "\n\nHEAD REQUEST: Returns the status and response headers only, without a body. Use it to check that a resource exists or to read its metadata (size, type, modification date) without downloading it."
+	case http.MethodOptions:
+		desc := "\n\nOPTIONS REQUEST: Returns the status and response headers; the Allow header lists the methods the server supports for this path."
+		if doc != nil && doc.Paths != nil {
+			if item := doc.Paths.Value(op.Path); item != nil {
+				methods := slices.Sorted(maps.Keys(item.Operations()))
+				desc += fmt.Sprintf(" Documented methods for %s: %s.", op.Path, strings.Join(methods, ", "))
+			}
+		}
+		return desc
+	case http.MethodTrace:
+		return "\n\nTRACE REQUEST: The server echoes the request it received back as the response body, for diagnosing what intermediaries changed."
+	}
+	return ""
+}
+
+// formatHeadersResult renders the result of HEAD and OPTIONS calls: the status, the response headers
+// (sorted, with credentials redacted), the allowed methods of an OPTIONS response, and the body if any.
+func formatHeadersResult(method, fullURL string, status int, callID string, header http.Header, body []byte, rules *RedactionRules) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "HTTP %s %s\nStatus: %d\nCall ID: %s\n", method, fullURL, status, callID)
+	if strings.EqualFold(method, http.MethodOptions) {
+		if allow := header.Values("Allow"); len(allow) > 0 {
+			fmt.Fprintf(&sb, "Allowed methods: %s\n", strings.Join(allow, ", "))
+		}
+	}
+	sb.WriteString("Headers:\n")
+	redacted := redactHeaders(header, rules)
+	for _, name := range slices.Sorted(maps.Keys(redacted)) {
+		fmt.Fprintf(&sb, "%s: %s\n", name, redacted[name])
+	}
+	if len(body) > 0 {
+		sb.WriteString("Response:\n")
+		sb.Write(body)
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
@@ -0,0 +1,76 @@
+package openapi2mcp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestToolHandler_HeadOptionsTrace(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /files/{id}:
+    parameters: [{name: id, in: path, required: true, schema: {type: string}}]
+    get: {operationId: getFile, responses: {"200": {description: ok}}}
+    head: {operationId: headFile, responses: {"200": {description: ok}}}
+    options: {operationId: optionsFile, responses: {"204": {description: ok}}}
+    trace: {operationId: traceFile, responses: {"200": {description: ok}}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ops := map[string]OpenAPIOperation{}
+	for _, op := range ExtractOpenAPIOperations(doc) {
+		ops[op.OperationID] = op
+	}
+	if len(ops) != 4 {
+		t.Fatalf("expected 4 operations, got %v", ops)
+	}
+
+	var method string
+	opts := &ToolGenOptions{RequestHandler: func(req *http.Request) (*http.Response, error) {
+		method = req.Method
+		resp := &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("")), Request: req}
+		switch req.Method {
+		case http.MethodHead:
+			resp.Header.Set("Content-Type", "image/png")
+			resp.Header.Set("Content-Length", "1024")
+			resp.Header.Set("Set-Cookie", "session=secret")
+		case http.MethodOptions:
+			resp.StatusCode = 204
+			resp.Header.Set("Allow", "GET, HEAD, OPTIONS")
+		case http.MethodTrace:
+			resp.Header.Set("Content-Type", "message/http")
+			resp.Body = io.NopCloser(strings.NewReader("TRACE /files/1 HTTP/1.1\r\nHost: example.com\r\n\r\n"))
+		}
+		return resp, nil
+	}}
+	call := func(name string) string {
+		handler := toolHandler(name, ops[name], doc, jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+		res, _, err := handler(context.Background(), nil, map[string]any{"id": "1"})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		return resultText(t, res)
+	}
+
+	text := call("headFile")
+	if method != http.MethodHead || !strings.Contains(text, "Headers:\nContent-Length: 1024\nContent-Type: image/png\nSet-Cookie: [REDACTED]") || strings.Contains(text, "file_base64") {
+		t.Errorf("expected headers as the HEAD result, got: %s", text)
+	}
+	if text := call("optionsFile"); method != http.MethodOptions || !strings.Contains(text, "Status: 204") || !strings.Contains(text, "Allowed methods: GET, HEAD, OPTIONS") {
+		t.Errorf("expected allowed methods as the OPTIONS result, got: %s", text)
+	}
+	if text := call("traceFile"); method != http.MethodTrace || !strings.Contains(text, "Response:\nTRACE /files/1 HTTP/1.1") {
+		t.Errorf("expected the echoed request as text, got: %s", text)
+	}
+
+	if desc := describeMethod(ops["optionsFile"], doc); !strings.Contains(desc, "Documented methods for /files/{id}: GET, HEAD, OPTIONS, TRACE.") {
+		t.Errorf("expected documented methods in the description, got: %s", desc)
+	}
+}
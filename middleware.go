@@ -0,0 +1,52 @@
+// middleware.go
+package openapi2mcp
+
+import "net/http"
+
+// Rounder sends an upstream request of an operation and returns its response.
+type Rounder interface {
+	Round(req *http.Request, op OpenAPIOperation) (*http.Response, error)
+}
+
+// RounderFunc adapts a function to the Rounder interface.
+type RounderFunc func(req *http.Request, op OpenAPIOperation) (*http.Response, error)
+
+// Round calls f(req, op).
+func (f RounderFunc) Round(req *http.Request, op OpenAPIOperation) (*http.Response, error) {
+	return f(req, op)
+}
+
+// Middleware wraps the Rounder sending upstream requests, e.g. to sign, cache, or measure them. It is called once
+// per tool when tools are registered; the Rounder it returns is called for every request.
+type Middleware func(next Rounder) Rounder
+
+// Use adds middleware around every upstream request of the tools, in the order given: the first middleware added
+// sees requests first and responses last. Middleware sees each request sent, including retries, status polls of
+// asynchronous operations, the chunks of resumable uploads, and the requests fetching ETags and CSRF tokens;
+// redirects are followed below it.
+//
+//	opts.Use(func(next openapi2mcp.Rounder) openapi2mcp.Rounder {
+//		return openapi2mcp.RounderFunc(func(req *http.Request, op openapi2mcp.OpenAPIOperation) (*http.Response, error) {
+//			req.Header.Set("X-Signature", sign(req))
+//			return next.Round(req, op)
+//		})
+//	})
+func (o *ToolGenOptions) Use(middleware ...Middleware) {
+	o.Middleware = append(o.Middleware, middleware...)
+}
+
+// withMiddleware returns a request handler sending the requests of op through middleware to handler.
+func withMiddleware(handler func(*http.Request) (*http.Response, error), middleware []Middleware, op OpenAPIOperation) func(*http.Request) (*http.Response, error) {
+	if len(middleware) == 0 {
+		return handler
+	}
+	var r Rounder = RounderFunc(func(req *http.Request, _ OpenAPIOperation) (*http.Response, error) {
+		return handler(req)
+	})
+	for i := len(middleware) - 1; i >= 0; i-- {
+		r = middleware[i](r)
+	}
+	return func(req *http.Request) (*http.Response, error) {
+		return r.Round(req, op)
+	}
+}
@@ -0,0 +1,82 @@
+package openapi2mcp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestToolGenOptions_Use(t *testing.T) {
+	doc := minimalOpenAPIDoc()
+	var trace []string
+	opts := &ToolGenOptions{RequestHandler: func(req *http.Request) (*http.Response, error) {
+		trace = append(trace, "upstream "+req.Header.Get("X-Signature"))
+		return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{"ok": true}`)), Request: req}, nil
+	}}
+	tracing := func(name string) Middleware {
+		return func(next Rounder) Rounder {
+			return RounderFunc(func(req *http.Request, op OpenAPIOperation) (*http.Response, error) {
+				trace = append(trace, name+" "+op.OperationID)
+				resp, err := next.Round(req, op)
+				trace = append(trace, name+" done")
+				return resp, err
+			})
+		}
+	}
+	signing := func(next Rounder) Rounder {
+		return RounderFunc(func(req *http.Request, op OpenAPIOperation) (*http.Response, error) {
+			req.Header.Set("X-Signature", op.Method+" "+req.URL.Path)
+			return next.Round(req, op)
+		})
+	}
+	opts.Use(tracing("outer"), tracing("inner"))
+	opts.Use(signing)
+
+	op := OpenAPIOperation{OperationID: "getReport", Method: "get", Path: "/reports"}
+	handler := toolHandler("getReport", op, doc, jsonschema.Schema{}, []string{"https://api.example.com"}, opts, newServerRuntime())
+	if _, _, err := handler(context.Background(), nil, map[string]any{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"outer getReport", "inner getReport", "upstream get /reports", "inner done", "outer done"}
+	if strings.Join(trace, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected trace:\n%s\nwant:\n%s", strings.Join(trace, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestWithMiddleware_ShortCircuit(t *testing.T) {
+	upstream := 0
+	handler := func(req *http.Request) (*http.Response, error) {
+		upstream++
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader("fresh"))}, nil
+	}
+	cache := func(next Rounder) Rounder {
+		cached := false
+		return RounderFunc(func(req *http.Request, op OpenAPIOperation) (*http.Response, error) {
+			if cached {
+				return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader("cached"))}, nil
+			}
+			cached = true
+			return next.Round(req, op)
+		})
+	}
+
+	send := withMiddleware(handler, []Middleware{cache}, OpenAPIOperation{OperationID: "getReport"})
+	var bodies []string
+	for range 2 {
+		req, _ := http.NewRequest(http.MethodGet, "https://api.example.com/reports", nil)
+		resp, err := send(req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		data, _ := io.ReadAll(resp.Body)
+		bodies = append(bodies, string(data))
+	}
+	if upstream != 1 || strings.Join(bodies, ",") != "fresh,cached" {
+		t.Errorf("expected the second request to be answered from the cache, got %v with %d upstream requests", bodies, upstream)
+	}
+}
@@ -0,0 +1,316 @@
+// mock.go
+package openapi2mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"maps"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// maxMockDepth limits how deep nested (or recursive) schemas are expanded in mock responses.
+const maxMockDepth = 6
+
+// mockRequestHandler returns a request handler that never contacts the upstream API.
+// It answers with the operation's first documented success response, using the documented
+// example if there is one and otherwise a value generated from the response schema.
+// Generated values are seeded by the operation ID, so repeated calls return the same response.
+// Schemas with a generator in gens, by component name or format, take their values from it.
+func mockRequestHandler(op OpenAPIOperation, gens ExampleGenerators) func(req *http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		if req.Body != nil {
+			io.Copy(io.Discard, req.Body)
+		}
+
+		status, respRef := mockResponseFor(op)
+		header := http.Header{"X-Mock-Response": []string{"true"}}
+		var body []byte
+
+		if respRef != nil && respRef.Value != nil && status != http.StatusNoContent {
+			contentType, mt := mockMediaType(respRef.Value.Content)
+			if mt != nil {
+				header.Set("Content-Type", contentType)
+				body = mockBody(op, contentType, mt, gens)
+			}
+		}
+
+		return &http.Response{
+			StatusCode:    status,
+			Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+			Header:        header,
+			ContentLength: int64(len(body)),
+			Body:          io.NopCloser(bytes.NewReader(body)),
+			Request:       req,
+		}, nil
+	}
+}
+
+// mockResponseFor picks the lowest documented 2xx response, falling back to the default response.
+func mockResponseFor(op OpenAPIOperation) (int, *openapi3.ResponseRef) {
+	if op.Responses == nil {
+		return http.StatusOK, nil
+	}
+	var codes []int
+	for code := range op.Responses.Map() {
+		if n, err := strconv.Atoi(code); err == nil && n >= 200 && n < 300 {
+			codes = append(codes, n)
+		}
+	}
+	if len(codes) > 0 {
+		slices.Sort(codes)
+		return codes[0], op.Responses.Status(codes[0])
+	}
+	if op.Responses.Value("2XX") != nil {
+		return http.StatusOK, op.Responses.Value("2XX")
+	}
+	return http.StatusOK, op.Responses.Default()
+}
+
+// mockMediaType prefers JSON content, then any other documented media type.
+func mockMediaType(content openapi3.Content) (string, *openapi3.MediaType) {
+	for _, ct := range []string{"application/json", "application/vnd.api+json"} {
+		if mt := getContentByType(content, ct); mt != nil {
+			return ct, mt
+		}
+	}
+	if keys := slices.Sorted(maps.Keys(content)); len(keys) > 0 {
+		return keys[0], content[keys[0]]
+	}
+	return "", nil
+}
+
+// mockBody renders the documented example, or a generated value, for the media type.
+func mockBody(op OpenAPIOperation, contentType string, mt *openapi3.MediaType, gens ExampleGenerators) []byte {
+	value := mt.Example
+	if value == nil {
+		for _, name := range slices.Sorted(maps.Keys(mt.Examples)) {
+			if ex := mt.Examples[name]; ex != nil && ex.Value != nil && ex.Value.Value != nil {
+				value = ex.Value.Value
+				break
+			}
+		}
+	}
+	if value == nil && mt.Schema != nil && mt.Schema.Value != nil {
+		h := fnv.New64a()
+		h.Write([]byte(op.OperationID))
+		g := &mockGenerator{rnd: rand.New(rand.NewPCG(h.Sum64(), 0)), gens: gens}
+		value = g.ref(mt.Schema, "", 0)
+	}
+
+	if !strings.Contains(contentType, "json") {
+		if s, ok := value.(string); ok {
+			return []byte(s)
+		}
+		if value == nil {
+			return nil
+		}
+		return []byte(fmt.Sprint(value))
+	}
+	body, _ := json.Marshal(value)
+	return body
+}
+
+// mockGenerator produces plausible, faker-style values for schemas.
+type mockGenerator struct {
+	rnd  *rand.Rand
+	gens ExampleGenerators
+}
+
+var (
+	mockFirstNames = []string{"Alice", "Bob", "Carla", "David", "Emma", "Farid", "Grace", "Hiro"}
+	mockLastNames  = []string{"Smith", "Mueller", "Rossi", "Tanaka", "Nguyen", "Garcia", "Kowalski"}
+	mockCities     = []string{"Berlin", "Lisbon", "Osaka", "Toronto", "Nairobi", "Melbourne"}
+	mockCountries  = []string{"DE", "PT", "JP", "CA", "KE", "AU"}
+	mockWords      = []string{"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf"}
+)
+
+func (g *mockGenerator) pick(values []string) string {
+	return values[g.rnd.IntN(len(values))]
+}
+
+// ref generates a value for the schema ref points to, from the generator of its component schema if there is one.
+func (g *mockGenerator) ref(ref *openapi3.SchemaRef, name string, depth int) any {
+	if ref == nil || ref.Value == nil {
+		return nil
+	}
+	if gen := g.gens.lookup(componentName(ref.Ref), ""); gen != nil && ref.Value.Example == nil && len(ref.Value.Enum) == 0 {
+		return gen(g.rnd)
+	}
+	return g.value(ref.Value, name, depth)
+}
+
+// value generates a value for schema; name is the property name, used for faker-style hints.
+func (g *mockGenerator) value(schema *openapi3.Schema, name string, depth int) any {
+	if schema == nil || depth > maxMockDepth {
+		return nil
+	}
+	if schema.Example != nil {
+		return schema.Example
+	}
+	if len(schema.Enum) > 0 {
+		return schema.Enum[g.rnd.IntN(len(schema.Enum))]
+	}
+	if schema.Default != nil {
+		return schema.Default
+	}
+	if gen := g.gens.lookup("", schema.Format); gen != nil {
+		return gen(g.rnd)
+	}
+	if len(schema.AllOf) > 0 {
+		merged := map[string]any{}
+		for _, sub := range schema.AllOf {
+			if sub == nil {
+				continue
+			}
+			if obj, ok := g.ref(sub, name, depth+1).(map[string]any); ok {
+				for k, v := range obj {
+					merged[k] = v
+				}
+			}
+		}
+		return merged
+	}
+	if len(schema.OneOf) > 0 && schema.OneOf[0] != nil {
+		return g.ref(schema.OneOf[0], name, depth+1)
+	}
+	if len(schema.AnyOf) > 0 && schema.AnyOf[0] != nil {
+		return g.ref(schema.AnyOf[0], name, depth+1)
+	}
+
+	switch {
+	case schema.Type.Is("string"):
+		return g.string(schema, name)
+	case schema.Type.Is("integer"):
+		return int64(g.number(schema, 1, 1000))
+	case schema.Type.Is("number"):
+		return float64(int(g.number(schema, 1, 1000)*100)) / 100
+	case schema.Type.Is("boolean"):
+		return g.rnd.IntN(2) == 0
+	case schema.Type.Is("array"):
+		n := 1 + g.rnd.IntN(3)
+		if schema.MinItems > uint64(n) {
+			n = int(schema.MinItems)
+		}
+		if schema.MaxItems != nil && uint64(n) > *schema.MaxItems {
+			n = int(*schema.MaxItems)
+		}
+		items := make([]any, 0, n)
+		for range n {
+			if schema.Items != nil {
+				items = append(items, g.ref(schema.Items, singular(name), depth+1))
+			}
+		}
+		return items
+	case schema.Type.Is("object") || len(schema.Properties) > 0:
+		obj := make(map[string]any, len(schema.Properties))
+		for _, prop := range slices.Sorted(maps.Keys(schema.Properties)) {
+			ref := schema.Properties[prop]
+			if ref == nil || ref.Value == nil || ref.Value.WriteOnly {
+				continue
+			}
+			if v := g.ref(ref, prop, depth+1); v != nil || slices.Contains(schema.Required, prop) {
+				obj[prop] = v
+			}
+		}
+		return obj
+	}
+	return nil
+}
+
+// number returns a value within the schema's bounds, or within [lo, hi] if unbounded.
+func (g *mockGenerator) number(schema *openapi3.Schema, lo, hi float64) float64 {
+	if schema.Min != nil {
+		lo = *schema.Min
+		hi = max(hi, lo+1000)
+	}
+	if schema.Max != nil {
+		hi = *schema.Max
+		if schema.Min == nil {
+			lo = min(lo, hi)
+		}
+	}
+	return lo + g.rnd.Float64()*(hi-lo)
+}
+
+// string generates a string based on the schema format and the property name.
+func (g *mockGenerator) string(schema *openapi3.Schema, name string) string {
+	first, last := g.pick(mockFirstNames), g.pick(mockLastNames)
+	switch schema.Format {
+	case "email":
+		return strings.ToLower(first) + "." + strings.ToLower(last) + "@example.com"
+	case "uuid":
+		return fmt.Sprintf("%08x-%04x-4%03x-a%03x-%012x", g.rnd.Uint32(), g.rnd.IntN(1<<16), g.rnd.IntN(1<<12), g.rnd.IntN(1<<12), g.rnd.Int64N(1<<48))
+	case "date-time":
+		return mockTime(g).Format(time.RFC3339)
+	case "date":
+		return mockTime(g).Format(time.DateOnly)
+	case "uri", "url":
+		return "https://example.com/" + g.pick(mockWords)
+	case "hostname":
+		return g.pick(mockWords) + ".example.com"
+	case "ipv4":
+		return fmt.Sprintf("192.0.2.%d", 1+g.rnd.IntN(254))
+	case "ipv6":
+		return fmt.Sprintf("2001:db8::%x", 1+g.rnd.IntN(0xffff))
+	case "byte":
+		return "ZXhhbXBsZQ=="
+	}
+
+	n := strings.ToLower(name)
+	var s string
+	switch {
+	case strings.Contains(n, "email"):
+		s = strings.ToLower(first) + "." + strings.ToLower(last) + "@example.com"
+	case n == "id" || strings.HasSuffix(n, "_id") || strings.HasSuffix(name, "Id"):
+		s = strconv.Itoa(1000 + g.rnd.IntN(9000))
+	case strings.Contains(n, "first"):
+		s = first
+	case strings.Contains(n, "last") || strings.Contains(n, "surname"):
+		s = last
+	case strings.Contains(n, "name"):
+		s = first + " " + last
+	case strings.Contains(n, "phone"):
+		s = fmt.Sprintf("+1-555-%04d", g.rnd.IntN(10000))
+	case strings.Contains(n, "city"):
+		s = g.pick(mockCities)
+	case strings.Contains(n, "country"):
+		s = g.pick(mockCountries)
+	case strings.Contains(n, "url") || strings.Contains(n, "link") || strings.Contains(n, "href"):
+		s = "https://example.com/" + g.pick(mockWords)
+	case strings.Contains(n, "date") || strings.HasSuffix(n, "_at"):
+		s = mockTime(g).Format(time.RFC3339)
+	default:
+		s = g.pick(mockWords) + " " + g.pick(mockWords)
+	}
+
+	if schema.MaxLength != nil && uint64(len(s)) > *schema.MaxLength {
+		s = s[:*schema.MaxLength]
+	}
+	for uint64(len(s)) < schema.MinLength {
+		s += "x"
+	}
+	return s
+}
+
+// mockTime returns a timestamp in 2024, so generated dates are stable and plausible.
+func mockTime(g *mockGenerator) time.Time {
+	return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(g.rnd.IntN(365*24)) * time.Hour)
+}
+
+// singular strips a plural "s" from array property names, so "emails" items look like emails.
+func singular(name string) string {
+	if len(name) > 1 && strings.HasSuffix(name, "s") {
+		return name[:len(name)-1]
+	}
+	return name
+}
@@ -0,0 +1,63 @@
+package openapi2mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestMockRequestHandler_Example(t *testing.T) {
+	op := OpenAPIOperation{OperationID: "getPet", Path: "/pet", Method: "get", Responses: openapi3.NewResponses(
+		openapi3.WithStatus(201, &openapi3.ResponseRef{Value: openapi3.NewResponse().WithContent(openapi3.Content{
+			"application/json": &openapi3.MediaType{Example: map[string]any{"id": 7, "name": "Rex"}},
+		})}),
+	)}
+	opts := &ToolGenOptions{Mock: true}
+	handler := toolHandler("getPet", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://unreachable.invalid"}, opts, nil)
+	res, _, err := handler(context.Background(), nil, map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(t, res)
+	if !strings.Contains(text, "Status: 201") || !strings.Contains(text, `{"id":7,"name":"Rex"}`) {
+		t.Errorf("expected documented example, got: %s", text)
+	}
+}
+
+func TestMockRequestHandler_Schema(t *testing.T) {
+	op := OpenAPIOperation{OperationID: "getPet", Responses: petResponses()}
+	op.Responses.Status(200).Value.Content["application/json"].Schema.Value.Properties["email"] = &openapi3.SchemaRef{Value: &openapi3.Schema{Type: typesPtr("string"), Format: "email"}}
+
+	req := httptest.NewRequest("GET", "http://example.com/pet", nil)
+	resp, _ := mockRequestHandler(op, nil)(req)
+	var pet map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&pet); err != nil {
+		t.Fatalf("expected JSON body: %v", err)
+	}
+	if _, ok := pet["id"].(float64); !ok {
+		t.Errorf("expected integer id, got %v", pet["id"])
+	}
+	if name, _ := pet["name"].(string); !strings.Contains(name, " ") {
+		t.Errorf("expected a person-like name, got %v", pet["name"])
+	}
+	if email, _ := pet["email"].(string); !strings.HasSuffix(email, "@example.com") {
+		t.Errorf("expected an email, got %v", pet["email"])
+	}
+	body, _ := json.Marshal(pet)
+	if m := validateResponseBody(op, 200, body); len(m) != 0 {
+		t.Errorf("expected mock response to match its schema, got %v", m)
+	}
+
+	// Same operation, same response
+	again, _ := mockRequestHandler(op, nil)(req)
+	var pet2 map[string]any
+	json.NewDecoder(again.Body).Decode(&pet2)
+	if pet["name"] != pet2["name"] {
+		t.Errorf("expected deterministic mock responses, got %v and %v", pet["name"], pet2["name"])
+	}
+}
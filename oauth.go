@@ -0,0 +1,269 @@
+// oauth.go
+package openapi2mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// oauthExpiryMargin is how long before their expiry cached access tokens are replaced, so that a token doesn't
+// expire while a request is on its way.
+const oauthExpiryMargin = 30 * time.Second
+
+// maxTokenResponseBytes caps the token endpoint responses read.
+const maxTokenResponseBytes = 64 << 10
+
+// clientCredentials are the settings of the OAuth2 client credentials grant (RFC 6749, section 4.4) of a security
+// scheme: CLIENT_ID and CLIENT_SECRET from the environment, and TOKEN_URL or else the tokenUrl of the scheme's
+// clientCredentials flow. OAUTH_SCOPES (space- or comma-separated) replaces the scopes the operations require.
+type clientCredentials struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scopes       []string
+}
+
+// schemeClientCredentials returns the client credentials for an oauth2 scheme, or nil if the environment doesn't
+// set them or no token URL is known.
+func schemeClientCredentials(scheme *openapi3.SecurityScheme) *clientCredentials {
+	creds := &clientCredentials{
+		tokenURL:     os.Getenv("TOKEN_URL"),
+		clientID:     os.Getenv("CLIENT_ID"),
+		clientSecret: os.Getenv("CLIENT_SECRET"),
+	}
+	if creds.tokenURL == "" && scheme.Flows != nil && scheme.Flows.ClientCredentials != nil {
+		creds.tokenURL = scheme.Flows.ClientCredentials.TokenURL
+	}
+	if creds.tokenURL == "" || creds.clientID == "" || creds.clientSecret == "" {
+		return nil
+	}
+	if scopes := os.Getenv("OAUTH_SCOPES"); scopes != "" {
+		creds.scopes = strings.FieldsFunc(scopes, func(r rune) bool { return r == ' ' || r == ',' })
+	}
+	return creds
+}
+
+// specTokenURLs returns the token URLs of the spec's oauth2 client credentials and authorization code flows, so that
+// tokens can be fetched from hosts other than the API's.
+func specTokenURLs(doc *openapi3.T) []string {
+	if doc.Components == nil {
+		return nil
+	}
+	var urls []string
+	for _, name := range slices.Sorted(maps.Keys(doc.Components.SecuritySchemes)) {
+		ref := doc.Components.SecuritySchemes[name]
+		if ref == nil || ref.Value == nil || ref.Value.Flows == nil {
+			continue
+		}
+		for _, flow := range []*openapi3.OAuthFlow{ref.Value.Flows.ClientCredentials, ref.Value.Flows.AuthorizationCode} {
+			if flow != nil && flow.TokenURL != "" {
+				urls = append(urls, flow.TokenURL)
+			}
+		}
+	}
+	return urls
+}
+
+// oauthTokens fetches access tokens with the client credentials grant and caches them until shortly before they
+// expire. It is safe for concurrent use; concurrent calls needing the same token wait for a single fetch.
+type oauthTokens struct {
+	mu     sync.Mutex
+	tokens map[string]*oauthToken // by token URL, client ID, and scopes
+	now    func() time.Time
+}
+
+// oauthToken is a cached access token.
+type oauthToken struct {
+	mu          sync.Mutex // held while the token is fetched
+	accessToken string
+	expiry      time.Time // zero if the token endpoint didn't say
+}
+
+// newOAuthTokens creates an empty token cache.
+func newOAuthTokens() *oauthTokens {
+	return &oauthTokens{tokens: make(map[string]*oauthToken), now: time.Now}
+}
+
+// token returns a valid access token for the client credentials and scopes, fetching a new one with send if none is
+// cached or the cached one is about to expire. A relative token URL is resolved against base, the request's URL.
+func (t *oauthTokens) token(send func(*http.Request) (*http.Response, error), creds *clientCredentials, scopes []string, base *url.URL) (string, error) {
+	if creds.scopes != nil {
+		scopes = creds.scopes
+	}
+	scopes = slices.Sorted(slices.Values(scopes))
+	tokenURL, err := base.Parse(creds.tokenURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid token URL %q: %w", creds.tokenURL, err)
+	}
+	key := tokenURL.String() + "\x00" + creds.clientID + "\x00" + strings.Join(scopes, " ")
+
+	t.mu.Lock()
+	entry := t.tokens[key]
+	if entry == nil {
+		entry = &oauthToken{}
+		t.tokens[key] = entry
+	}
+	t.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.accessToken != "" && (entry.expiry.IsZero() || t.now().Before(entry.expiry.Add(-oauthExpiryMargin))) {
+		return entry.accessToken, nil
+	}
+	accessToken, expiresIn, err := fetchClientCredentialsToken(send, tokenURL.String(), creds, scopes)
+	if err != nil {
+		return "", err
+	}
+	entry.accessToken, entry.expiry = accessToken, time.Time{}
+	if expiresIn > 0 {
+		entry.expiry = t.now().Add(expiresIn)
+	}
+	return accessToken, nil
+}
+
+// forget drops a cached access token the API rejected, e.g. because it was revoked, so that the next call fetches a
+// new one.
+func (t *oauthTokens) forget(accessToken string) {
+	if accessToken == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key, entry := range t.tokens {
+		if entry.mu.TryLock() {
+			if entry.accessToken == accessToken {
+				delete(t.tokens, key)
+			}
+			entry.mu.Unlock()
+		}
+	}
+}
+
+// fetchClientCredentialsToken requests an access token with the client credentials grant.
+func fetchClientCredentialsToken(send func(*http.Request) (*http.Response, error), tokenURL string, creds *clientCredentials, scopes []string) (string, time.Duration, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(scopes) > 0 {
+		form.Set("scope", strings.Join(scopes, " "))
+	}
+	token, err := requestToken(send, tokenURL, form, creds.clientID, creds.clientSecret)
+	if err != nil {
+		return "", 0, err
+	}
+	return token.AccessToken, token.lifetime(), nil
+}
+
+// requestToken posts form to the token endpoint. A client with a secret authenticates with HTTP Basic authentication
+// and, if the endpoint rejects that, with the credentials in the request body, as some authorization servers only
+// support one of them; public clients only send their ID. The returned response holds the endpoint's error code,
+// if any, e.g. authorization_pending while a device authorization is pending.
+func requestToken(send func(*http.Request) (*http.Response, error), tokenURL string, form url.Values, clientID, clientSecret string) (tokenResponse, error) {
+	var token tokenResponse
+	var err error
+	for _, inBody := range []bool{clientSecret == "", true} {
+		form := maps.Clone(form)
+		if inBody {
+			form.Set("client_id", clientID)
+			if clientSecret != "" {
+				form.Set("client_secret", clientSecret)
+			}
+		}
+		req, reqErr := http.NewRequest(http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+		if reqErr != nil {
+			return tokenResponse{}, reqErr
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Accept", "application/json")
+		if !inBody {
+			req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+		}
+
+		resp, sendErr := send(req)
+		if sendErr != nil {
+			return tokenResponse{}, fmt.Errorf("token request to %s failed: %w", tokenURL, sendErr)
+		}
+		body, readErr := io.ReadAll(io.LimitReader(resp.Body, maxTokenResponseBytes))
+		resp.Body.Close()
+		if readErr != nil {
+			return tokenResponse{}, fmt.Errorf("reading the token response of %s failed: %w", tokenURL, readErr)
+		}
+		token, err = parseTokenResponse(resp, body)
+		if err == nil {
+			return token, nil
+		}
+		err = fmt.Errorf("token endpoint %s: %w", tokenURL, err)
+		// Only a rejected client authentication is retried with the credentials in the body
+		clientRejected := resp.StatusCode == http.StatusUnauthorized || token.Error == "invalid_client" || token.Error == "unauthorized_client" || token.Error == "invalid_request"
+		if inBody || !clientRejected {
+			break
+		}
+	}
+	return token, err
+}
+
+// tokenResponse is the response of a token endpoint (RFC 6749, sections 5.1 and 5.2).
+type tokenResponse struct {
+	AccessToken      string      `json:"access_token"`
+	TokenType        string      `json:"token_type"`
+	ExpiresIn        json.Number `json:"expires_in"`
+	RefreshToken     string      `json:"refresh_token"`
+	Error            string      `json:"error"`
+	ErrorDescription string      `json:"error_description"`
+}
+
+// lifetime returns how long the access token is valid, or 0 if the endpoint didn't say.
+func (t tokenResponse) lifetime() time.Duration {
+	expiresIn, _ := strconv.ParseInt(t.ExpiresIn.String(), 10, 64)
+	return time.Duration(expiresIn) * time.Second
+}
+
+// parseTokenResponse reads a token endpoint response, which is JSON or, with some older servers, form-encoded. It
+// returns an error if the response holds no bearer access token, along with the error code of the endpoint, if any.
+func parseTokenResponse(resp *http.Response, body []byte) (tokenResponse, error) {
+	var token tokenResponse
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "application/x-www-form-urlencoded" || mediaType == "text/plain" {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return token, fmt.Errorf("HTTP %d: invalid response: %w", resp.StatusCode, err)
+		}
+		token = tokenResponse{
+			AccessToken:      values.Get("access_token"),
+			TokenType:        values.Get("token_type"),
+			ExpiresIn:        json.Number(values.Get("expires_in")),
+			RefreshToken:     values.Get("refresh_token"),
+			Error:            values.Get("error"),
+			ErrorDescription: values.Get("error_description"),
+		}
+	} else if err := json.Unmarshal(body, &token); err != nil && resp.StatusCode < 300 {
+		return token, fmt.Errorf("HTTP %d: invalid response: %w", resp.StatusCode, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 || token.Error != "" || token.AccessToken == "" {
+		switch {
+		case token.Error != "" && token.ErrorDescription != "":
+			return token, fmt.Errorf("HTTP %d: %s: %s", resp.StatusCode, token.Error, token.ErrorDescription)
+		case token.Error != "":
+			return token, fmt.Errorf("HTTP %d: %s", resp.StatusCode, token.Error)
+		case resp.StatusCode >= 200 && resp.StatusCode < 300:
+			return token, fmt.Errorf("HTTP %d: the response has no access_token", resp.StatusCode)
+		}
+		return token, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	if token.TokenType != "" && !strings.EqualFold(token.TokenType, "bearer") {
+		return token, fmt.Errorf("unsupported token type %q", token.TokenType)
+	}
+	return token, nil
+}
@@ -0,0 +1,134 @@
+package openapi2mcp
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// clientCredentialsDoc returns a spec whose operations need an oauth2 scheme with a client credentials flow.
+func clientCredentialsDoc() *openapi3.T {
+	doc := minimalOpenAPIDoc()
+	doc.Components = &openapi3.Components{SecuritySchemes: openapi3.SecuritySchemes{
+		"oauth": &openapi3.SecuritySchemeRef{Value: &openapi3.SecurityScheme{
+			Type: "oauth2",
+			Flows: &openapi3.OAuthFlows{ClientCredentials: &openapi3.OAuthFlow{
+				TokenURL: "https://auth.example.com/token",
+				Scopes:   map[string]string{"orders:read": "Read orders"},
+			}},
+		}},
+	}}
+	doc.Security = openapi3.SecurityRequirements{{"oauth": {"orders:read"}}}
+	return doc
+}
+
+func TestToolHandler_OAuthClientCredentials(t *testing.T) {
+	t.Setenv("BEARER_TOKEN", "")
+	t.Setenv("CLIENT_ID", "my-client")
+	t.Setenv("CLIENT_SECRET", "s3cr3t")
+
+	var tokenRequests []string
+	var authorizations []string
+	apiStatus := 200
+	opts := &ToolGenOptions{
+		RequestHandler: func(req *http.Request) (*http.Response, error) {
+			if req.URL.Host == "auth.example.com" {
+				req.ParseForm()
+				user, pass, _ := req.BasicAuth()
+				tokenRequests = append(tokenRequests, req.PostForm.Get("grant_type")+" "+req.PostForm.Get("scope")+" "+user+":"+pass)
+				token := "token" + string(rune('0'+len(tokenRequests)))
+				return fakeResponse(200, "application/json", `{"access_token":"`+token+`","token_type":"Bearer","expires_in":3600}`)(req)
+			}
+			authorizations = append(authorizations, req.Header.Get("Authorization"))
+			return fakeResponse(apiStatus, "application/json", `{}`)(req)
+		},
+	}
+	rt := newServerRuntime()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	rt.tokens.now = func() time.Time { return now }
+	handler := toolHandler("getFoo", OpenAPIOperation{OperationID: "getFoo", Path: "/foo", Method: "get"}, clientCredentialsDoc(), jsonschema.Schema{}, []string{"https://api.example.com"}, opts, rt)
+	call := func() {
+		t.Helper()
+		res, _, err := handler(context.Background(), nil, map[string]any{})
+		if err != nil || res.IsError != (apiStatus != 200) {
+			t.Fatalf("unexpected result: %v %v", err, res)
+		}
+	}
+
+	call()
+	call()
+	now = now.Add(time.Hour - time.Second*10) // within the expiry margin
+	call()
+	apiStatus = http.StatusUnauthorized
+	call()
+	apiStatus = 200
+	call()
+
+	if want := "client_credentials orders:read my-client:s3cr3t"; tokenRequests[0] != want {
+		t.Errorf("expected token request %q, got %q", want, tokenRequests[0])
+	}
+	if len(tokenRequests) != 3 {
+		t.Errorf("expected 3 token requests, got %d: %q", len(tokenRequests), tokenRequests)
+	}
+	want := []string{"Bearer token1", "Bearer token1", "Bearer token2", "Bearer token2", "Bearer token3"}
+	if strings.Join(authorizations, "|") != strings.Join(want, "|") {
+		t.Errorf("expected authorizations %q, got %q", want, authorizations)
+	}
+}
+
+func TestOAuthTokens_CredentialsInBody(t *testing.T) {
+	var attempts []string
+	send := func(req *http.Request) (*http.Response, error) {
+		req.ParseForm()
+		if _, _, ok := req.BasicAuth(); ok {
+			attempts = append(attempts, "basic")
+			return fakeResponse(401, "application/json", `{"error":"invalid_client"}`)(req)
+		}
+		attempts = append(attempts, "body "+req.PostForm.Get("client_id"))
+		return fakeResponse(200, "application/x-www-form-urlencoded", "access_token=abc&token_type=bearer")(req)
+	}
+	creds := &clientCredentials{tokenURL: "/oauth/token", clientID: "my-client", clientSecret: "s3cr3t"}
+	base, _ := http.NewRequest(http.MethodGet, "https://api.example.com/orders", nil)
+	token, err := newOAuthTokens().token(send, creds, nil, base.URL)
+	if err != nil || token != "abc" {
+		t.Fatalf("expected token abc, got %q, %v", token, err)
+	}
+	if strings.Join(attempts, "|") != "basic|body my-client" {
+		t.Errorf("unexpected attempts %q", attempts)
+	}
+}
+
+func TestToolHandler_OAuthTokenFailed(t *testing.T) {
+	t.Setenv("BEARER_TOKEN", "")
+	t.Setenv("CLIENT_ID", "my-client")
+	t.Setenv("CLIENT_SECRET", "wrong")
+
+	var apiCalled bool
+	opts := &ToolGenOptions{
+		RequestHandler: func(req *http.Request) (*http.Response, error) {
+			if req.URL.Host == "auth.example.com" {
+				return fakeResponse(400, "application/json", `{"error":"invalid_client","error_description":"bad secret"}`)(req)
+			}
+			apiCalled = true
+			return fakeResponse(200, "application/json", `{}`)(req)
+		},
+	}
+	handler := toolHandler("getFoo", OpenAPIOperation{OperationID: "getFoo", Path: "/foo", Method: "get"}, clientCredentialsDoc(), jsonschema.Schema{}, []string{"https://api.example.com"}, opts, newServerRuntime())
+	res, _, err := handler(context.Background(), nil, map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.IsError || apiCalled {
+		t.Fatalf("expected the call to fail before reaching the API, got %v", res)
+	}
+	text := res.Content[0].(*mcp.TextContent).Text
+	if !strings.Contains(text, "OAuth2 access token") || !strings.Contains(text, "invalid_client: bad secret") {
+		t.Errorf("unexpected error text: %s", text)
+	}
+}
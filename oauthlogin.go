@@ -0,0 +1,432 @@
+// oauthlogin.go
+package openapi2mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"log/slog"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// The interactive OAuth2 flows of OAuthLogin.
+const (
+	OAuthFlowAuthorizationCode = "authorization_code" // the user signs in in a browser redirecting to a local listener
+	OAuthFlowDeviceCode        = "device_code"        // the user enters a code on another device, for headless use
+)
+
+// DefaultOAuthRedirectAddr is the address of the redirect listener of the authorization code flow if
+// OAuthLogin.RedirectAddr is not set; the redirect URI to register with the authorization server is
+// http://127.0.0.1:8085/callback.
+const DefaultOAuthRedirectAddr = "127.0.0.1:8085"
+
+// oauthLoginTimeout is how long a started sign-in waits for the user.
+const oauthLoginTimeout = 10 * time.Minute
+
+// errNoOAuthToken reports that no OAuth2 access token can be obtained for a scheme, so that its credentials are left
+// out as if the environment didn't provide them.
+var errNoOAuthToken = errors.New("no OAuth2 credentials configured")
+
+// OAuthLogin signs the user in with an interactive OAuth2 flow for APIs requiring user-delegated access, and uses the
+// resulting access token for the operations secured by oauth2 schemes. The flow starts with the first call needing a
+// token: the call fails with instructions for the user (a URL to open, and with the device flow a code to enter),
+// which the model passes on, and calls succeed once the user signed in. Access tokens are refreshed with the refresh
+// token, if the authorization server issued one, and persisted to TokenFile, if set, so that restarts keep them.
+type OAuthLogin struct {
+	Flow                   string   // OAuthFlowAuthorizationCode or OAuthFlowDeviceCode
+	ClientID               string   // client ID registered with the authorization server
+	ClientSecret           string   // client secret; empty for public clients, which are verified with PKCE only
+	AuthorizationURL       string   // default: the authorizationUrl of the spec's authorizationCode flow
+	TokenURL               string   // default: the tokenUrl of the spec's authorizationCode flow
+	DeviceAuthorizationURL string   // device authorization endpoint of the device flow (RFC 8628)
+	Scopes                 []string // requested scopes; default: those of the spec's authorizationCode flow
+	RedirectAddr           string   // address of the local redirect listener; "" means DefaultOAuthRedirectAddr
+	TokenFile              string   // file persisting the token across restarts; none if empty
+}
+
+// withDefaults returns a copy of the login with the endpoints and scopes of the spec's authorizationCode flow filled
+// in, and an error if the flow still lacks an endpoint it needs.
+func (l OAuthLogin) withDefaults(doc *openapi3.T) (OAuthLogin, error) {
+	if flow := specAuthorizationCodeFlow(doc); flow != nil {
+		if l.AuthorizationURL == "" {
+			l.AuthorizationURL = flow.AuthorizationURL
+		}
+		if l.TokenURL == "" {
+			l.TokenURL = flow.TokenURL
+		}
+		if l.Scopes == nil {
+			l.Scopes = slices.Sorted(maps.Keys(flow.Scopes))
+		}
+	}
+	if l.RedirectAddr == "" {
+		l.RedirectAddr = DefaultOAuthRedirectAddr
+	}
+	switch {
+	case l.Flow != OAuthFlowAuthorizationCode && l.Flow != OAuthFlowDeviceCode:
+		return l, fmt.Errorf("unknown OAuth2 flow %q: expected %s or %s", l.Flow, OAuthFlowAuthorizationCode, OAuthFlowDeviceCode)
+	case l.ClientID == "":
+		return l, errors.New("the OAuth2 client ID is missing")
+	case l.TokenURL == "":
+		return l, errors.New("the OAuth2 token URL is missing and the spec has no authorizationCode flow")
+	case l.Flow == OAuthFlowAuthorizationCode && l.AuthorizationURL == "":
+		return l, errors.New("the OAuth2 authorization URL is missing and the spec has no authorizationCode flow")
+	case l.Flow == OAuthFlowDeviceCode && l.DeviceAuthorizationURL == "":
+		return l, errors.New("the device authorization URL of the device flow is missing")
+	}
+	return l, nil
+}
+
+// specAuthorizationCodeFlow returns the authorizationCode flow of the first oauth2 scheme of the spec having one.
+func specAuthorizationCodeFlow(doc *openapi3.T) *openapi3.OAuthFlow {
+	if doc == nil || doc.Components == nil {
+		return nil
+	}
+	for _, name := range slices.Sorted(maps.Keys(doc.Components.SecuritySchemes)) {
+		ref := doc.Components.SecuritySchemes[name]
+		if ref != nil && ref.Value != nil && ref.Value.Type == "oauth2" && ref.Value.Flows != nil && ref.Value.Flows.AuthorizationCode != nil {
+			return ref.Value.Flows.AuthorizationCode
+		}
+	}
+	return nil
+}
+
+// savedToken is the token of a signed in user, as persisted to OAuthLogin.TokenFile.
+type savedToken struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry,omitzero"` // zero if the token endpoint didn't say
+}
+
+// pendingLogin is a sign-in waiting for the user.
+type pendingLogin struct {
+	url      string // URL the user opens
+	userCode string // code the user enters with the device flow
+	expiry   time.Time
+	cancel   func()
+}
+
+// instructions tells the user how to sign in.
+func (p *pendingLogin) instructions() string {
+	if p.userCode != "" {
+		return fmt.Sprintf("Open %s in a browser and enter the code %s to authorize access to the API.", p.url, p.userCode)
+	}
+	return fmt.Sprintf("Open %s in a browser and sign in to authorize access to the API.", p.url)
+}
+
+// loginRequiredError reports that a call needs the user to sign in first.
+type loginRequiredError struct {
+	login *pendingLogin
+}
+
+func (e *loginRequiredError) Error() string {
+	return "sign-in required: " + e.login.instructions()
+}
+
+// oauthLoginSession holds the token of the signed in user and runs the sign-in when there is none. It is safe for
+// concurrent use; concurrent calls share one sign-in.
+type oauthLoginSession struct {
+	cfg    OAuthLogin
+	cfgErr error
+	send   func(*http.Request) (*http.Response, error)
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu      sync.Mutex
+	token   *savedToken
+	pending *pendingLogin
+	err     error // why the last sign-in failed, reported to the next call
+}
+
+// newOAuthLoginSession creates the sign-in of cfg, sending token requests with send, and loads the token persisted
+// by an earlier run, if any.
+func newOAuthLoginSession(cfg *OAuthLogin, doc *openapi3.T, send func(*http.Request) (*http.Response, error), logger *slog.Logger) *oauthLoginSession {
+	s := &oauthLoginSession{send: send, logger: logger, now: time.Now}
+	s.cfg, s.cfgErr = cfg.withDefaults(doc)
+	if s.cfgErr != nil {
+		warnf(logger, "OAuth2 sign-in is not possible: %v", s.cfgErr)
+	}
+	if s.cfg.TokenFile != "" {
+		if data, err := os.ReadFile(s.cfg.TokenFile); err == nil {
+			var token savedToken
+			if err := json.Unmarshal(data, &token); err != nil {
+				warnf(logger, "Ignoring the OAuth2 token file %s: %v", s.cfg.TokenFile, err)
+			} else {
+				s.token = &token
+			}
+		} else if !errors.Is(err, os.ErrNotExist) {
+			warnf(logger, "Ignoring the OAuth2 token file %s: %v", s.cfg.TokenFile, err)
+		}
+	}
+	return s
+}
+
+// accessToken returns the access token of the signed in user, refreshing it if it is about to expire. Without a
+// token, it starts a sign-in, unless one is already waiting for the user, and returns a *loginRequiredError telling
+// the user how to sign in.
+func (s *oauthLoginSession) accessToken() (string, error) {
+	if s.cfgErr != nil {
+		return "", s.cfgErr
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if s.token != nil && s.token.AccessToken != "" && (s.token.Expiry.IsZero() || now.Before(s.token.Expiry.Add(-oauthExpiryMargin))) {
+		return s.token.AccessToken, nil
+	}
+	if s.token != nil && s.token.RefreshToken != "" {
+		err := s.refresh()
+		if err == nil {
+			return s.token.AccessToken, nil
+		}
+		warnf(s.logger, "Refreshing the OAuth2 access token failed, the user needs to sign in again: %v", err)
+		s.token = nil
+	}
+	if err := s.err; err != nil {
+		s.err = nil
+		return "", err
+	}
+	if s.pending == nil || !now.Before(s.pending.expiry) {
+		if s.pending != nil {
+			s.pending.cancel()
+		}
+		var err error
+		if s.cfg.Flow == OAuthFlowDeviceCode {
+			s.pending, err = s.beginDeviceCode()
+		} else {
+			s.pending, err = s.beginAuthorizationCode()
+		}
+		if err != nil {
+			s.pending = nil
+			return "", err
+		}
+		// Also tell the people at the terminal, in case the model doesn't pass it on
+		warnf(s.logger, "OAuth2 sign-in required: %s", s.pending.instructions())
+	}
+	return "", &loginRequiredError{login: s.pending}
+}
+
+// forget drops an access token the API rejected, so that the next call refreshes it or signs in again.
+func (s *oauthLoginSession) forget(accessToken string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if accessToken != "" && s.token != nil && s.token.AccessToken == accessToken {
+		s.token.AccessToken = ""
+	}
+}
+
+// refresh replaces the access token using the refresh token. s.mu is held.
+func (s *oauthLoginSession) refresh() error {
+	form := url.Values{"grant_type": {"refresh_token"}, "refresh_token": {s.token.RefreshToken}}
+	token, err := requestToken(s.send, s.cfg.TokenURL, form, s.cfg.ClientID, s.cfg.ClientSecret)
+	if err != nil {
+		return err
+	}
+	if token.RefreshToken == "" {
+		token.RefreshToken = s.token.RefreshToken
+	}
+	s.store(token)
+	return nil
+}
+
+// store keeps the token of a token response and persists it. s.mu is held.
+func (s *oauthLoginSession) store(token tokenResponse) {
+	s.token = &savedToken{AccessToken: token.AccessToken, RefreshToken: token.RefreshToken}
+	if lifetime := token.lifetime(); lifetime > 0 {
+		s.token.Expiry = s.now().Add(lifetime)
+	}
+	if s.cfg.TokenFile == "" {
+		return
+	}
+	data, _ := json.MarshalIndent(s.token, "", "  ")
+	if err := os.WriteFile(s.cfg.TokenFile, data, 0o600); err != nil {
+		warnf(s.logger, "Could not save the OAuth2 token to %s: %v", s.cfg.TokenFile, err)
+	}
+}
+
+// finish ends the sign-in p with the token response, or with the error if the user didn't sign in.
+func (s *oauthLoginSession) finish(p *pendingLogin, token tokenResponse, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pending != p {
+		return
+	}
+	s.pending = nil
+	p.cancel()
+	if err != nil {
+		warnf(s.logger, "OAuth2 sign-in failed: %v", err)
+		s.err = fmt.Errorf("sign-in failed: %w", err)
+		return
+	}
+	s.store(token)
+}
+
+// beginAuthorizationCode starts the authorization code flow with PKCE (RFC 7636): it listens for the redirect of the
+// authorization server and returns the authorization URL the user opens. s.mu is held.
+func (s *oauthLoginSession) beginAuthorizationCode() (*pendingLogin, error) {
+	listener, err := net.Listen("tcp", s.cfg.RedirectAddr)
+	if err != nil {
+		return nil, fmt.Errorf("cannot listen for the OAuth2 redirect on %s: %w", s.cfg.RedirectAddr, err)
+	}
+	host, _, _ := net.SplitHostPort(s.cfg.RedirectAddr)
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	redirectURI := "http://" + net.JoinHostPort(host, port) + "/callback"
+	state, verifier := randomToken(), randomToken()
+	challenge := sha256.Sum256([]byte(verifier))
+
+	authURL, err := url.Parse(s.cfg.AuthorizationURL)
+	if err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("invalid authorization URL %q: %w", s.cfg.AuthorizationURL, err)
+	}
+	q := authURL.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", s.cfg.ClientID)
+	q.Set("redirect_uri", redirectURI)
+	q.Set("state", state)
+	q.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	q.Set("code_challenge_method", "S256")
+	if len(s.cfg.Scopes) > 0 {
+		q.Set("scope", strings.Join(s.cfg.Scopes, " "))
+	}
+	authURL.RawQuery = q.Encode()
+
+	srv := &http.Server{ReadHeaderTimeout: 10 * time.Second}
+	timer := time.AfterFunc(oauthLoginTimeout, func() { srv.Close() })
+	p := &pendingLogin{url: authURL.String(), expiry: s.now().Add(oauthLoginTimeout)}
+	p.cancel = func() {
+		timer.Stop()
+		// Shut down gracefully, so that the page of the redirect is still sent
+		go srv.Shutdown(context.Background())
+	}
+	srv.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.URL.Path != "/callback" || query.Get("state") != state {
+			http.NotFound(w, r)
+			return
+		}
+		var token tokenResponse
+		var err error
+		if e := query.Get("error"); e != "" {
+			err = fmt.Errorf("the authorization server answered %s %s", e, query.Get("error_description"))
+		} else {
+			form := url.Values{"grant_type": {"authorization_code"}, "code": {query.Get("code")}, "redirect_uri": {redirectURI}, "code_verifier": {verifier}}
+			token, err = requestToken(s.send, s.cfg.TokenURL, form, s.cfg.ClientID, s.cfg.ClientSecret)
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "<p>Sign-in failed: %s</p>", html.EscapeString(err.Error()))
+		} else {
+			io.WriteString(w, "<p>Signed in. You can close this window and return to your agent.</p>")
+		}
+		s.finish(p, token, err)
+	})
+	go srv.Serve(listener)
+	return p, nil
+}
+
+// deviceAuthorization is the response of a device authorization endpoint (RFC 8628, section 3.2).
+type deviceAuthorization struct {
+	DeviceCode              string      `json:"device_code"`
+	UserCode                string      `json:"user_code"`
+	VerificationURI         string      `json:"verification_uri"`
+	VerificationURIComplete string      `json:"verification_uri_complete"`
+	ExpiresIn               json.Number `json:"expires_in"`
+	Interval                json.Number `json:"interval"`
+	Error                   string      `json:"error"`
+	ErrorDescription        string      `json:"error_description"`
+}
+
+// beginDeviceCode starts the device authorization flow (RFC 8628): it requests a user code, which the user enters at
+// the verification URL, and polls the token endpoint until the user signed in. s.mu is held.
+func (s *oauthLoginSession) beginDeviceCode() (*pendingLogin, error) {
+	form := url.Values{"client_id": {s.cfg.ClientID}}
+	if s.cfg.ClientSecret != "" {
+		form.Set("client_secret", s.cfg.ClientSecret)
+	}
+	if len(s.cfg.Scopes) > 0 {
+		form.Set("scope", strings.Join(s.cfg.Scopes, " "))
+	}
+	req, err := http.NewRequest(http.MethodPost, s.cfg.DeviceAuthorizationURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := s.send(req)
+	if err != nil {
+		return nil, fmt.Errorf("device authorization request to %s failed: %w", s.cfg.DeviceAuthorizationURL, err)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxTokenResponseBytes))
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	var device deviceAuthorization
+	json.Unmarshal(body, &device)
+	if resp.StatusCode != http.StatusOK || device.DeviceCode == "" || device.VerificationURI == "" {
+		if device.Error != "" {
+			return nil, fmt.Errorf("device authorization endpoint %s: HTTP %d: %s %s", s.cfg.DeviceAuthorizationURL, resp.StatusCode, device.Error, device.ErrorDescription)
+		}
+		return nil, fmt.Errorf("device authorization endpoint %s: HTTP %d: the response has no device_code and verification_uri", s.cfg.DeviceAuthorizationURL, resp.StatusCode)
+	}
+
+	lifetime := oauthLoginTimeout
+	if n, err := device.ExpiresIn.Int64(); err == nil && n > 0 {
+		lifetime = time.Duration(n) * time.Second
+	}
+	interval := 5 * time.Second
+	if n, err := device.Interval.Int64(); err == nil && n > 0 {
+		interval = time.Duration(n) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), lifetime)
+	p := &pendingLogin{url: device.VerificationURI, userCode: device.UserCode, expiry: s.now().Add(lifetime), cancel: cancel}
+	if device.VerificationURIComplete != "" {
+		p.url = device.VerificationURIComplete
+	}
+
+	go func() {
+		form := url.Values{"grant_type": {"urn:ietf:params:oauth:grant-type:device_code"}, "device_code": {device.DeviceCode}}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+			}
+			token, err := requestToken(s.send, s.cfg.TokenURL, form, s.cfg.ClientID, s.cfg.ClientSecret)
+			switch token.Error {
+			case "authorization_pending":
+				continue
+			case "slow_down":
+				interval += 5 * time.Second
+				continue
+			}
+			s.finish(p, token, err)
+			return
+		}
+	}()
+	return p, nil
+}
+
+// randomToken returns a random URL-safe string for the state and the PKCE code verifier.
+func randomToken() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
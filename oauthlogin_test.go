@@ -0,0 +1,166 @@
+package openapi2mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// authorizationCodeDoc returns a spec whose operations need an oauth2 scheme with an authorization code flow.
+func authorizationCodeDoc() *openapi3.T {
+	doc := minimalOpenAPIDoc()
+	doc.Components = &openapi3.Components{SecuritySchemes: openapi3.SecuritySchemes{
+		"oauth": &openapi3.SecuritySchemeRef{Value: &openapi3.SecurityScheme{
+			Type: "oauth2",
+			Flows: &openapi3.OAuthFlows{AuthorizationCode: &openapi3.OAuthFlow{
+				AuthorizationURL: "https://auth.example.com/authorize",
+				TokenURL:         "https://auth.example.com/token",
+				Scopes:           map[string]string{"repo": "Repositories", "user": "Profile"},
+			}},
+		}},
+	}}
+	doc.Security = openapi3.SecurityRequirements{{"oauth": {"repo"}}}
+	return doc
+}
+
+func TestOAuthLogin_AuthorizationCode(t *testing.T) {
+	var mu sync.Mutex
+	var form url.Values
+	send := func(req *http.Request) (*http.Response, error) {
+		req.ParseForm()
+		mu.Lock()
+		form = req.PostForm
+		mu.Unlock()
+		return fakeResponse(200, "application/json", `{"access_token":"user-token","token_type":"bearer","expires_in":3600,"refresh_token":"refresh"}`)(req)
+	}
+	tokenFile := filepath.Join(t.TempDir(), "token.json")
+	login := newOAuthLoginSession(&OAuthLogin{Flow: OAuthFlowAuthorizationCode, ClientID: "my-app", RedirectAddr: "127.0.0.1:0", TokenFile: tokenFile}, authorizationCodeDoc(), send, slog.New(discardHandler{}))
+
+	_, err := login.accessToken()
+	var loginErr *loginRequiredError
+	if !errors.As(err, &loginErr) {
+		t.Fatalf("expected a sign-in to start, got %v", err)
+	}
+	authURL, _ := url.Parse(loginErr.login.url)
+	q := authURL.Query()
+	if authURL.Host != "auth.example.com" || q.Get("client_id") != "my-app" || q.Get("scope") != "repo user" || q.Get("code_challenge_method") != "S256" {
+		t.Fatalf("unexpected authorization URL %s", authURL)
+	}
+	// A second call reuses the sign-in waiting for the user
+	if _, err := login.accessToken(); !errors.As(err, &loginErr) || loginErr.login.url != authURL.String() {
+		t.Fatalf("expected the sign-in to be reused, got %v", err)
+	}
+
+	resp, err := http.Get(q.Get("redirect_uri") + "?code=the-code&state=" + url.QueryEscape(q.Get("state")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected the redirect to succeed, got HTTP %d", resp.StatusCode)
+	}
+
+	token, err := login.accessToken()
+	if err != nil || token != "user-token" {
+		t.Fatalf("expected the user's token, got %q, %v", token, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	challenge := sha256.Sum256([]byte(form.Get("code_verifier")))
+	if form.Get("code") != "the-code" || form.Get("grant_type") != "authorization_code" || base64.RawURLEncoding.EncodeToString(challenge[:]) != q.Get("code_challenge") {
+		t.Errorf("unexpected token request %v", form)
+	}
+	if data, err := os.ReadFile(tokenFile); err != nil || !strings.Contains(string(data), `"refresh_token": "refresh"`) {
+		t.Errorf("expected the token to be saved, got %s, %v", data, err)
+	}
+}
+
+func TestOAuthLogin_Refresh(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token.json")
+	os.WriteFile(tokenFile, []byte(`{"access_token":"old","refresh_token":"refresh","expiry":"2020-01-01T00:00:00Z"}`), 0o600)
+	var grants []string
+	send := func(req *http.Request) (*http.Response, error) {
+		req.ParseForm()
+		grants = append(grants, req.PostForm.Get("grant_type")+" "+req.PostForm.Get("refresh_token"))
+		return fakeResponse(200, "application/json", `{"access_token":"new","expires_in":3600}`)(req)
+	}
+	login := newOAuthLoginSession(&OAuthLogin{Flow: OAuthFlowAuthorizationCode, ClientID: "my-app", ClientSecret: "s3cr3t", TokenFile: tokenFile}, authorizationCodeDoc(), send, slog.New(discardHandler{}))
+
+	token, err := login.accessToken()
+	if err != nil || token != "new" {
+		t.Fatalf("expected the refreshed token, got %q, %v", token, err)
+	}
+	if strings.Join(grants, "|") != "refresh_token refresh" {
+		t.Errorf("unexpected token requests %q", grants)
+	}
+	// The refresh token is kept if the endpoint doesn't issue a new one
+	if data, _ := os.ReadFile(tokenFile); !strings.Contains(string(data), `"refresh_token": "refresh"`) {
+		t.Errorf("expected the refresh token to be kept, got %s", data)
+	}
+}
+
+func TestToolHandler_OAuthLoginDeviceCode(t *testing.T) {
+	t.Setenv("BEARER_TOKEN", "")
+	var mu sync.Mutex
+	var polls int
+	var authorization string
+	opts := &ToolGenOptions{
+		OAuthLogin: &OAuthLogin{Flow: OAuthFlowDeviceCode, ClientID: "my-app", DeviceAuthorizationURL: "https://auth.example.com/device"},
+		RequestHandler: func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			switch req.URL.String() {
+			case "https://auth.example.com/device":
+				return fakeResponse(200, "application/json", `{"device_code":"dev","user_code":"WDJB-MJHT","verification_uri":"https://auth.example.com/activate","expires_in":60,"interval":1}`)(req)
+			case "https://auth.example.com/token":
+				if polls++; polls == 1 {
+					return fakeResponse(400, "application/json", `{"error":"authorization_pending"}`)(req)
+				}
+				return fakeResponse(200, "application/json", `{"access_token":"device-token","token_type":"Bearer"}`)(req)
+			}
+			authorization = req.Header.Get("Authorization")
+			return fakeResponse(200, "application/json", `{}`)(req)
+		},
+		LogHandler: discardHandler{},
+	}
+	doc := authorizationCodeDoc()
+	rt := newServerRuntime()
+	rt.login = newOAuthLoginSession(opts.OAuthLogin, doc, opts.RequestHandler, warnLogger(opts))
+	handler := toolHandler("getFoo", OpenAPIOperation{OperationID: "getFoo", Path: "/foo", Method: "get"}, doc, jsonschema.Schema{}, []string{"https://api.example.com"}, opts, rt)
+
+	res, _, err := handler(context.Background(), nil, map[string]any{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := res.Content[0].(*mcp.TextContent).Text
+	if !res.IsError || !strings.Contains(text, "Sign-in required") || !strings.Contains(text, "https://auth.example.com/activate") || !strings.Contains(text, "WDJB-MJHT") {
+		t.Fatalf("expected sign-in instructions, got %s", text)
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for res.IsError && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+		if res, _, err = handler(context.Background(), nil, map[string]any{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if res.IsError || authorization != "Bearer device-token" {
+		t.Errorf("expected the call to use the device token, got %q: %v", authorization, res.Content)
+	}
+}
@@ -3,25 +3,31 @@
 package openapi2mcp
 
 import (
+	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 // OpenAPIOperation describes a single OpenAPI operation to be mapped to an MCP tool.
 // It includes the operation's ID, summary, description, HTTP path/method, parameters, request body, and tags.
 type OpenAPIOperation struct {
-	OperationID string
-	Summary     string
-	Description string
-	Path        string
-	Method      string
-	Parameters  openapi3.Parameters
-	RequestBody *openapi3.RequestBodyRef
-	Tags        []string
-	Security    openapi3.SecurityRequirements
-	Deprecated  bool
+	OperationID  string
+	Summary      string
+	Description  string
+	Path         string
+	Method       string
+	Parameters   openapi3.Parameters
+	RequestBody  *openapi3.RequestBodyRef
+	Tags         []string
+	Security     openapi3.SecurityRequirements
+	Deprecated   bool
+	Responses    *openapi3.Responses
+	Callbacks    openapi3.Callbacks
+	ExternalDocs *openapi3.ExternalDocs
 }
 
 // ToolGenOptions controls tool generation and output for OpenAPI-MCP conversion.
@@ -31,17 +37,211 @@ type OpenAPIOperation struct {
 // DryRun: if true, only print the generated tool schemas, don't register
 // PrettyPrint: if true, pretty-print the output
 // Version: version string to embed in tool annotations
-// PostProcessSchema: optional hook to modify each tool's input schema before registration/output
-// ConfirmDangerousActions: if true (default), require confirmation for PUT/POST/DELETE tools
+// PostProcessSchema: optional hook to modify each tool's input schema before registration/output (called for one tool
+// at a time; nested component schemas are shared between tools, so replace rather than modify them in place)
 //
 //	func(toolName string, schema jsonschema.Schema) jsonschema.Schema
+//
+// ConfirmDangerousActions: if true (default), require confirmation for PUT/POST/DELETE tools
+// ValidateResponses: if true, check successful responses against the documented response schema
+// ErrorFormat: how errors are returned: ErrorFormatText (default), ErrorFormatJSON, or ErrorFormatBoth
+// LogHandler: optional slog.Handler receiving upstream HTTP traffic logs (see NewPrettyLogHandler), and warnings about
+// unsupported spec features during registration instead of stderr
+// DisableLogTruncation: if true, log full request and response bodies
+// Redaction: optional extra header names and JSON body paths to mask in logs
+// RequestIDHeader: if set (e.g. "X-Request-ID"), send each call's correlation ID upstream in this header
+// Telemetry: if true, append a latency/outcome line to each result and register the server_stats tool
+// MaxResponseBytes: upstream response bodies beyond this size are truncated (0 means DefaultMaxResponseBytes)
+// MaxRequestBodyBytes: calls whose serialized request body exceeds this size are rejected (0 means DefaultMaxRequestBodyBytes)
+// MaxArgumentBytes: calls with an argument other than requestBody exceeding this size are rejected (0 means DefaultMaxArgumentBytes)
+// TransportResponseLimits: response bytes shown to the model by transport (TransportStdio, TransportHTTP), if less than
+// MaxResponseBytes; longer bodies are cut off with a marker, and the complete body is served by the result://{call_id} resource
+// MaxTabularRecords: CSV, TSV, and NDJSON responses are parsed into up to this many records, returned as structured
+// content with a preview of the first ones as text (0 means DefaultMaxTabularRecords)
+// HTMLToMarkdown: if true, successful HTML responses are shown as Markdown of their readable content; the raw HTML of
+// recent calls is served by the result://{call_id} resource template
+// DisableRateLimitPacing: if true, calls nearing the rate limit of an API host (as reported by X-RateLimit-*/RateLimit-*
+// headers or documented with the x-ratelimit extension) are not delayed; the quota of each host is still served by the
+// ratelimit://{host} resource template, and that of all hosts by ratelimit://status
+// RateLimitWarning: if set, results warn the model when the remaining rate limit quota of the API host is at or below
+// this percentage of its limit; the quota of each host is served by the ratelimit://{host} resource template
+// ReproCommand: if ReproCommandCurl or ReproCommandHTTPie, results end with an equivalent command line of the request
+// sent, with credentials replaced by environment variable references, so that reviewers can reproduce calls
+// UploadRoot: if set, operations taking a raw binary request body (e.g. application/octet-stream) also accept a
+// requestBodyFile argument naming a file below this directory, which is streamed as the body; other files can't be read
+// MaxUploadFileBytes: requestBodyFile uploads larger than this size are rejected (0 means DefaultMaxUploadFileBytes);
+// MaxRequestBodyBytes doesn't apply to them
+// GraphQL: if true, generic GraphQL endpoints (POST operations on a path ending in /graphql) take query, operationName,
+// and variables arguments instead of a requestBody, and GraphQL errors in their responses are listed readably
+// CodeSampleLangs: the operations' x-codeSamples in these languages (e.g. "curl", "JavaScript"; matched against lang or
+// label) are appended to the tool descriptions; none if empty
+// ExampleGenerators: generators of example values by component schema name or format (e.g. "customer-id"), used
+// instead of the built-in defaults in tool description and error examples and in mock responses; component schema
+// names take precedence, but only formats apply to examples built from input schemas
+// RelativeDates: if true, date and date-time parameters also accept relative values such as "yesterday", "-7d", or
+// "last_month_start", which are resolved in TimeZone before the request is sent and listed in the result
+// CoerceArguments: if true, stringly-typed arguments are converted before validation, e.g. "123" to 123 for integer
+// parameters and "true" to true for booleans, and whitespace around string parameters is trimmed
+// Cassette: if set, the upstream interactions of tool calls are recorded to it, with the spec's credential headers
+// and apiKey query parameters redacted; requests still go through RequestHandler or the default client
+// Mock: if true, tool calls never reach the upstream API; responses are synthesized from the spec's examples and schemas
+// CompactSchemas: if true, a component schema used more than once within a tool is emitted once and then referenced via $ref
+// BaseURL: if set, requests go to this base URL instead of OPENAPI_BASE_URL or the spec's servers
+// BaseURLOverrides: base URLs of the operations having a tag or lying below a path prefix, e.g. /admin paths on an
+// internal host; they take precedence over BaseURL, and the first matching override wins
+// Sandbox: routes calls of some operations, of sessions asking for it with a header, or of a share of the sessions to
+// a sandbox base URL such as a payment provider's test mode; their results are marked as sandboxed
+// SpecDrift: if set, the spec is compared at startup and then periodically with the one the API serves, e.g. at
+// /openapi.json, until its Context is done; divergence is logged once and shown by the openapi://spec/drift resource
+// OAuthLogin: if set, operations secured by oauth2 schemes use the access token of a user signed in with the
+// authorization code or device flow; the first call needing it starts the sign-in and tells the user what to do
+// Store: if set, session cookies and CSRF tokens, raw responses of result://{call_id}, scheduled calls, and audit
+// records of tool calls are persisted in it, so that they survive restarts and are shared by the replicas of an HTTP
+// deployment; nil keeps them in memory
+// CallbackReceiver: if set, operations with callbacks explain how to direct them to the receiver, and the
+// await_callback and list_received_callbacks tools are registered
+// DisableInfoTool, DisableExternalDocsTool, DisableTimestampResource, DisableConvertTimeTool: if true, the corresponding
+// built-in is not registered
+// TimeZone: time zone of the timestamp://current resource and the convert_time tool, registered for APIs with date
+// parameters (default: the local time zone)
+// APIKeyHeader: header sending API_KEY for operations whose security requirements don't place it (default: the API_KEY_HEADER
+// environment variable, then the spec's first apiKey security scheme)
+// ResponseTrimming: per-operation rules dropping JSON paths and capping array and string lengths in successful responses
+// ScheduledCalls: if true, the schedule_call, list_scheduled, and cancel_scheduled tools are registered, letting agents
+// queue operation tool calls to run after a delay or at a time, e.g. once a rate limit resets
+// CallGroups: if true, the begin_group, commit_group, and abort_group tools are registered: the successful POST, PUT,
+// PATCH, and DELETE calls of an MCP session's open group are recorded, and abort_group lists the calls undoing them,
+// derived from response links to DELETE operations or the DELETE operation on the path a POST created a resource at
+// GroupRollback: if true (with CallGroups), abort_group executes the compensating calls when asked to instead of only listing them
+// Workflows: multi-step sequences of operation calls, each registered as one tool running the steps in order and
+// passing data between them (see Workflow, LoadWorkflows, and LoadArazzoWorkflows)
+// MetaTools: user-defined tools registered after the generated ones, with access to them via an OperationRegistry
+// ForwardHeaders: headers of incoming MCP HTTP requests copied to upstream requests, replacing those set from the
+// environment (e.g. "Authorization" for per-client credentials); no other incoming header is ever forwarded
+// ResponseHeaders: response headers included in results, e.g. "Location", "ETag", "Link", "X-RateLimit-*" (trailing * matches any suffix)
+// OperationResponseHeaders: additional response headers included in the results of specific operations, by operationId
+// Timeout, OperationTimeouts: limit how long calls wait for the API (by operationId, overriding Timeout); they are
+// stated in tool descriptions and the server_config resource so that agents don't cancel long-running calls early
+// AsyncPolling: if set, operations answering 202 Accepted with a status URL are polled until they complete (bounded);
+// otherwise their result is reported as pending, with the status URL and a ready-made tool call checking it
+// Retry: if set, calls failing with a connection error or a 408, 425, 429, 500, 502, 503, or 504 response are retried with backoff;
+// only idempotent methods are retried unless an idempotency key header is configured (see RetryPolicy)
+// AutoIfMatch: if true, PUT and PATCH operations documenting an If-Match header or a version field in their request
+// body fetch the resource's current ETag or version with a GET on the same path when the agent leaves it out
+// SessionCookies: if true, cookies set by the API are kept per MCP session and sent with the session's later calls
+// ConcurrencyLimits: limit how many calls of some operations, by operationId or tag, run at once; extra calls queue for
+// a slot or are rejected with a "busy" error (see ConcurrencyLimit)
+// OperationCosts: the cost or blast radius of operations, by operationId, overriding their x-mcp-cost extension; it is
+// stated in tool descriptions and the tool's _meta, and high and critical operations are annotated as destructive
+// ConfirmCost: if set to a cost level, calls of operations at or above it need {"__confirmed": true} before they are
+// sent, whatever their method
+// CoalesceRequests: if true, a GET call identical to one of the same MCP session that is still in flight (same URL and
+// headers) waits for it and shares its response instead of sending another upstream request
+// CSRF: how CSRF tokens are acquired and sent with state-changing calls, overriding the spec's x-mcp-csrf extension;
+// session cookies are kept if set (see CSRFConfig)
+// Redirects: whether and how far redirects are followed; credentials are never sent to another host (see RedirectPolicy)
+// ReadOnly: a switch that, while on, rejects calls of operations other than GET, HEAD, OPTIONS, and TRACE with a
+// read_only error, whichever tools are registered; it can be turned on and off while the server runs
+// ArgumentRules: optional constraints on argument values beyond the schemas, e.g. an environment argument that must be
+// "staging"; they are stated in tool descriptions, and calls breaking them get an argument_not_permitted error
+// Policy: optional rules allowing or denying each tool call by operation, method, path, arguments, and caller identity
+// HostPolicy: restricts the hosts requests may go to (default: the base URL's and the spec's servers' hosts, and no
+// private or link-local addresses for hosts from the spec); see HostPolicy
+// ClientProfile: adapts the tools to the constraints of a known client (ClientProfileClaude, ClientProfileCursor,
+// ClientProfileGeneric): tool names are sanitized and shortened, long descriptions shortened, $ref and allOf avoided in
+// schemas, and for clients confirming tool calls themselves, tools are annotated instead of asking for __confirmed
+// MaxDescriptionLength: tool descriptions longer than this many bytes are shortened in tools/list; the complete
+// descriptions are served by the describe_tool tool and openapi://tools/{name} resources (default: the client profile's)
+// ContentGuard: hardens results against prompt injection by the API's responses: bodies are wrapped in delimited data
+// blocks, text that looks like instructions to the model is flagged, and markup is optionally stripped (see ContentGuard)
+// DescriptionOverrides: titles, descriptions, argument descriptions, and examples replacing those generated from the
+// spec, by operationId, e.g. to sharpen descriptions for the model without editing the spec (see LoadDescriptionOverrides)
+// Presets: named argument sets of common calls, by operationId; tools with presets accept a __preset argument filling
+// in the preset's arguments, which the call's own arguments override (see LoadArgumentPresets)
+// Middleware: wraps every upstream request of the tools with access to the operation, e.g. for signing, caching, or
+// metrics; the first middleware sees requests first (see Use)
+// TransformResult: optional hook reshaping successful (2xx) responses before they reach the model; it receives the
+// possibly truncated body and returns the content replacing the formatted response, or nil to keep it
+//
+//	func(op OpenAPIOperation, status int, body []byte) (mcp.Content, error)
 type ToolGenOptions struct {
-	NameFormat              func(string) string
-	TagFilter               []string
-	DryRun                  bool
-	PrettyPrint             bool
-	Version                 string
-	PostProcessSchema       func(toolName string, schema jsonschema.Schema) jsonschema.Schema
-	ConfirmDangerousActions bool // if true, add confirmation prompt for dangerous actions
-	RequestHandler          func(req *http.Request) (*http.Response, error)
+	NameFormat               func(string) string
+	TagFilter                []string
+	DryRun                   bool
+	PrettyPrint              bool
+	Version                  string
+	PostProcessSchema        func(toolName string, schema jsonschema.Schema) jsonschema.Schema
+	ConfirmDangerousActions  bool // if true, add confirmation prompt for dangerous actions
+	RequestHandler           func(req *http.Request) (*http.Response, error)
+	Cassette                 *CassetteRecorder // if set, upstream interactions are recorded to it
+	ValidateResponses        bool              // if true, append a warning section when a response does not match its schema
+	ErrorFormat              string            // "text" (default), "json", or "both"; structured errors are also set as structured content
+	LogHandler               slog.Handler      // if nil, logs to stderr when MCP_LOG_HTTP or DEBUG is set, and warnings to stderr
+	DisableLogTruncation     bool              // if true, bodies are logged in full
+	Redaction                *RedactionRules
+	RequestIDHeader          string            // if set, the per-call correlation ID is sent upstream in this header
+	Telemetry                bool              // if true, append telemetry to results and expose the server_stats tool
+	CompactSchemas           bool              // if true, repeated component schemas within a tool are emitted as $ref
+	MaxResponseBytes         int64             // response bodies are truncated beyond this size; 0 means DefaultMaxResponseBytes
+	MaxRequestBodyBytes      int               // larger request bodies are rejected; 0 means DefaultMaxRequestBodyBytes
+	MaxArgumentBytes         int               // larger arguments (except requestBody) are rejected; 0 means DefaultMaxArgumentBytes
+	TransportResponseLimits  map[string]int64  // response bytes shown by transport ("stdio", "http"); the rest is kept as a resource
+	Mock                     bool              // if true, responses are generated from the spec instead of calling RequestHandler
+	BaseURL                  string            // if set, overrides OPENAPI_BASE_URL and the spec's servers
+	BaseURLOverrides         []BaseURLOverride // base URLs of operations by tag or path prefix; the first match wins
+	Sandbox                  *SandboxRouting   // if set, some calls go to a sandbox base URL instead
+	SpecDrift                *SpecDriftCheck   // if set, the spec is compared periodically with the API's own
+	OAuthLogin               *OAuthLogin       // if set, oauth2 schemes use the token of a user signed in interactively
+	Store                    Store             // if set, server state is persisted in it instead of only in memory
+	CallbackReceiver         *CallbackReceiver // if set, callbacks are received locally and exposed via await_callback
+	DisableInfoTool          bool              // if true, the info tool is not registered
+	DisableExternalDocsTool  bool              // if true, the externalDocs tool is not registered
+	DisableTimestampResource bool              // if true, the timestamp://current resource is not registered
+	DisableConvertTimeTool   bool              // if true, the convert_time tool is not registered
+	TimeZone                 *time.Location    // time zone of the date helpers; nil means local time
+	APIKeyHeader             string            // fallback header for API_KEY; defaults to API_KEY_HEADER, then the spec's apiKey scheme
+	ResponseTrimming         ResponseTrimming  // rules pruning successful JSON responses, by operationId or "*"
+	MetaTools                []MetaTool        // user-defined tools sharing the operation registry
+	Workflows                []Workflow        // multi-step sequences of operation calls served as single tools
+	ScheduledCalls           bool              // if true, agents can queue operation calls for later with schedule_call
+	CallGroups               bool              // if true, agents can group calls and get compensating calls on abort
+	GroupRollback            bool              // if true, abort_group can execute the compensating calls
+	HostPolicy               *HostPolicy       // if nil, the default policy protects against SSRF
+	Policy                   *Policy           // if set, calls it denies are rejected before reaching the API
+	ReadOnly                 *ReadOnlySwitch   // if set and on, calls of non-GET operations are rejected
+	ArgumentRules            *ArgumentRules    // if set, calls with argument values it rejects fail before reaching the API
+	ForwardHeaders           []string          // incoming HTTP request headers forwarded upstream; none if empty
+	TransformResult          func(op OpenAPIOperation, status int, body []byte) (mcp.Content, error)
+	Middleware               []Middleware             // wrap every upstream request, outermost first; see Use
+	ClientProfile            string                   // "claude", "cursor", or "generic" to fit the tools to the client; none if empty
+	MaxDescriptionLength     int                      // shorten longer tool descriptions, offloading the rest; 0 means no limit
+	ContentGuard             *ContentGuard            // if set, response bodies are marked as untrusted data
+	DescriptionOverrides     DescriptionOverrides     // titles, descriptions, and examples of tools by operationId
+	Presets                  ArgumentPresets          // named argument sets of common calls, by operationId
+	ResponseHeaders          []string                 // response headers shown in every result; none if empty
+	OperationResponseHeaders map[string][]string      // response headers shown in addition, by operationId
+	Redirects                *RedirectPolicy          // if nil, up to DefaultMaxRedirects redirects are followed
+	Retry                    *RetryPolicy             // if nil, failed calls are not retried
+	AutoIfMatch              bool                     // if true, updates send the current ETag or version the agent left out
+	SessionCookies           bool                     // if true, each MCP session keeps the cookies the API sets
+	CoalesceRequests         bool                     // if true, identical GET calls in flight within a session share one request
+	ConcurrencyLimits        []ConcurrencyLimit       // calls of operations running at once, by operationId or tag
+	OperationCosts           map[string]OperationCost // costs overriding the x-mcp-cost extension, by operationId
+	ConfirmCost              string                   // cost level from which calls need confirmation; none if empty
+	CSRF                     *CSRFConfig              // if nil, the spec's x-mcp-csrf extension applies, if any
+	Timeout                  time.Duration            // per-call timeout; 0 means no timeout
+	OperationTimeouts        map[string]time.Duration // timeouts overriding Timeout, by operationId
+	AsyncPolling             *AsyncPolling            // if nil, accepted asynchronous operations are reported as pending
+	MaxTabularRecords        int                      // CSV rows and NDJSON records parsed per response; 0 means DefaultMaxTabularRecords
+	HTMLToMarkdown           bool                     // if true, HTML responses are converted to Markdown
+	DisableRateLimitPacing   bool                     // if true, calls are not delayed when nearing rate limits
+	RateLimitWarning         float64                  // warn in results below this percentage of a host's rate limit; 0 never warns
+	ReproCommand             string                   // "curl" or "httpie" to show each request as a command line; none if empty
+	UploadRoot               string                   // directory binary request bodies may be uploaded from; none if empty
+	MaxUploadFileBytes       int64                    // larger requestBodyFile uploads are rejected; 0 means DefaultMaxUploadFileBytes
+	GraphQL                  bool                     // if true, GraphQL endpoints take query and variables arguments
+	CodeSampleLangs          []string                 // languages of the x-codeSamples shown in tool descriptions
+	ExampleGenerators        ExampleGenerators        // example values by component schema name or format
+	RelativeDates            bool                     // if true, date parameters accept values such as "yesterday"
+	CoerceArguments          bool                     // if true, arguments such as "123" are converted to their parameter's type
 }
@@ -0,0 +1,207 @@
+// Package openapi2mcptest provides utilities for end-to-end testing of MCP servers generated from OpenAPI specs:
+// an in-memory MCP client connected to a server built from a spec, and an httptest-backed fake upstream API.
+//
+//	upstream := openapi2mcptest.NewUpstream(t)
+//	upstream.JSON("GET /pets/{id}", http.StatusOK, map[string]any{"id": 1, "name": "Rex"})
+//
+//	client := openapi2mcptest.NewClient(t, doc, &openapi2mcptest.Options{BaseURL: upstream.URL})
+//	text := client.CallText("getPet", map[string]any{"id": 1})
+package openapi2mcptest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	openapi2mcp "github.com/evcc-io/openapi-mcp"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Options configures NewClient.
+type Options struct {
+	Operations     []openapi2mcp.OpenAPIOperation // Operations to register (default: all operations of the spec)
+	BaseURL        string                         // Base URL of the upstream API, e.g. Upstream.URL (default: the spec's servers)
+	ToolGenOptions *openapi2mcp.ToolGenOptions    // Options used to register the tools
+}
+
+// Client is an MCP client session connected in memory to a server built from a spec.
+// Its methods fail the test on protocol errors; tool errors are returned in the results.
+type Client struct {
+	Session *mcp.ClientSession
+	Server  *mcp.Server
+	t       testing.TB
+}
+
+// NewClient builds an MCP server for doc and connects a client to it in memory.
+// The session is closed when the test finishes.
+func NewClient(t testing.TB, doc *openapi3.T, opts *Options) *Client {
+	t.Helper()
+	if opts == nil {
+		opts = &Options{}
+	}
+	ops := opts.Operations
+	if ops == nil {
+		ops = openapi2mcp.ExtractOpenAPIOperations(doc)
+	}
+	toolOpts := openapi2mcp.ToolGenOptions{}
+	if opts.ToolGenOptions != nil {
+		toolOpts = *opts.ToolGenOptions
+	}
+	if opts.BaseURL != "" {
+		toolOpts.BaseURL = opts.BaseURL
+	}
+
+	name, version := "openapi-mcp-test", "test"
+	if doc.Info != nil {
+		name, version = doc.Info.Title, doc.Info.Version
+	}
+	server := mcp.NewServer(&mcp.Implementation{Name: name, Version: version}, nil)
+	openapi2mcp.RegisterOpenAPITools(server, ops, doc, &toolOpts)
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("openapi2mcptest: failed to connect server: %v", err)
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "openapi2mcptest", Version: "test"}, nil)
+	cs, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("openapi2mcptest: failed to connect client: %v", err)
+	}
+	t.Cleanup(func() { cs.Close() })
+	return &Client{Session: cs, Server: server, t: t}
+}
+
+// NewClientFromFile loads the spec at path and returns a client for it, like NewClient.
+func NewClientFromFile(t testing.TB, path string, opts *Options) *Client {
+	t.Helper()
+	doc, err := openapi2mcp.LoadOpenAPISpec(path)
+	if err != nil {
+		t.Fatalf("openapi2mcptest: failed to load spec: %v", err)
+	}
+	return NewClient(t, doc, opts)
+}
+
+// Tools lists all tools of the server.
+func (c *Client) Tools() []*mcp.Tool {
+	c.t.Helper()
+	var tools []*mcp.Tool
+	for tool, err := range c.Session.Tools(context.Background(), nil) {
+		if err != nil {
+			c.t.Fatalf("openapi2mcptest: failed to list tools: %v", err)
+		}
+		tools = append(tools, tool)
+	}
+	return tools
+}
+
+// ToolNames lists the names of all tools of the server, sorted.
+func (c *Client) ToolNames() []string {
+	c.t.Helper()
+	var names []string
+	for _, tool := range c.Tools() {
+		names = append(names, tool.Name)
+	}
+	return names
+}
+
+// Call calls the tool with args and returns the result, which may be a tool error (IsError).
+func (c *Client) Call(name string, args map[string]any) *mcp.CallToolResult {
+	c.t.Helper()
+	res, err := c.Session.CallTool(context.Background(), &mcp.CallToolParams{Name: name, Arguments: args})
+	if err != nil {
+		c.t.Fatalf("openapi2mcptest: calling %s failed: %v", name, err)
+	}
+	return res
+}
+
+// CallText calls the tool with args and returns the text content of the result, joined by newlines.
+func (c *Client) CallText(name string, args map[string]any) string {
+	c.t.Helper()
+	return Text(c.Call(name, args))
+}
+
+// Text returns the text content of a tool result, joined by newlines.
+func Text(res *mcp.CallToolResult) string {
+	var parts []string
+	for _, content := range res.Content {
+		if text, ok := content.(*mcp.TextContent); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// Request is a request received by an Upstream.
+type Request struct {
+	Method string
+	Path   string
+	Query  string
+	Header http.Header
+	Body   []byte
+}
+
+// Upstream is a fake upstream API backed by an httptest.Server. Routes use http.ServeMux
+// patterns such as "GET /pets/{id}"; unmatched requests get 404.
+type Upstream struct {
+	*httptest.Server
+	mux *http.ServeMux
+
+	mu       sync.Mutex
+	requests []Request
+}
+
+// NewUpstream starts a fake upstream API that is closed when the test finishes.
+func NewUpstream(t testing.TB) *Upstream {
+	u := &Upstream{mux: http.NewServeMux()}
+	u.Server = httptest.NewServer(http.HandlerFunc(u.serveHTTP))
+	t.Cleanup(u.Close)
+	return u
+}
+
+func (u *Upstream) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	u.mu.Lock()
+	u.requests = append(u.requests, Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.RawQuery,
+		Header: r.Header.Clone(),
+		Body:   body,
+	})
+	u.mu.Unlock()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	u.mux.ServeHTTP(w, r)
+}
+
+// Handle registers a handler for the route pattern.
+func (u *Upstream) Handle(pattern string, handler http.HandlerFunc) {
+	u.mux.HandleFunc(pattern, handler)
+}
+
+// JSON registers a route answering with status and body encoded as JSON.
+func (u *Upstream) JSON(pattern string, status int, body any) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		panic("openapi2mcptest: " + err.Error())
+	}
+	u.Handle(pattern, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write(data)
+	})
+}
+
+// Requests returns the requests received so far, in order.
+func (u *Upstream) Requests() []Request {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return append([]Request(nil), u.requests...)
+}
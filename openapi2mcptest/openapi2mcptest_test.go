@@ -0,0 +1,76 @@
+package openapi2mcptest
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	openapi2mcp "github.com/evcc-io/openapi-mcp"
+)
+
+const petstore = `
+openapi: 3.0.0
+info:
+  title: Petstore
+  version: 1.0.0
+paths:
+  /pets/{id}:
+    get:
+      operationId: getPet
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+      responses:
+        "200":
+          description: A pet
+  /pets:
+    post:
+      operationId: createPet
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                name:
+                  type: string
+      responses:
+        "201":
+          description: Created
+`
+
+func TestClientAndUpstream(t *testing.T) {
+	doc, err := openapi2mcp.LoadOpenAPISpecFromString(petstore)
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+	upstream := NewUpstream(t)
+	upstream.JSON("GET /pets/{id}", http.StatusOK, map[string]any{"id": 7, "name": "Rex"})
+	upstream.JSON("POST /pets", http.StatusCreated, map[string]any{"id": 8})
+
+	client := NewClient(t, doc, &Options{BaseURL: upstream.URL})
+
+	if names := client.ToolNames(); !slices.Contains(names, "getPet") || !slices.Contains(names, "createPet") {
+		t.Errorf("expected operation tools, got %v", names)
+	}
+	if text := client.CallText("getPet", map[string]any{"id": 7}); !strings.Contains(text, `"name":"Rex"`) {
+		t.Errorf("expected upstream response, got: %s", text)
+	}
+	client.Call("createPet", map[string]any{"requestBody": map[string]any{"name": "Max"}, "__confirmed": true})
+
+	requests := upstream.Requests()
+	if len(requests) != 2 || requests[0].Path != "/pets/7" || requests[1].Method != "POST" || string(requests[1].Body) != `{"name":"Max"}` {
+		t.Errorf("unexpected upstream requests: %+v", requests)
+	}
+
+	// Unrouted requests get 404, which is reported as a tool error
+	upstream2 := NewUpstream(t)
+	res := NewClient(t, doc, &Options{BaseURL: upstream2.URL}).Call("getPet", map[string]any{"id": 1})
+	if !res.IsError {
+		t.Errorf("expected tool error for unrouted request, got: %s", Text(res))
+	}
+}
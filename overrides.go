@@ -0,0 +1,86 @@
+// overrides.go
+package openapi2mcp
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"go.yaml.in/yaml/v3"
+)
+
+// DescriptionOverride replaces what an operation's tool shows the model, without changing the spec.
+//
+// Title sets the tool's display title. Description replaces the operation's description or summary at the start of
+// the tool description. Parameters replaces the descriptions of arguments, by argument name. Example replaces the
+// generated arguments of the tool description's EXAMPLE line. Empty fields keep what the spec documents.
+type DescriptionOverride struct {
+	Title       string            `json:"title,omitempty" yaml:"title,omitempty"`
+	Description string            `json:"description,omitempty" yaml:"description,omitempty"`
+	Parameters  map[string]string `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	Example     map[string]any    `json:"example,omitempty" yaml:"example,omitempty"`
+}
+
+// DescriptionOverrides maps operationIds to the overrides of their tools.
+type DescriptionOverrides map[string]DescriptionOverride
+
+// LoadDescriptionOverrides reads description overrides from a YAML or JSON file:
+//
+//	searchIssues:
+//	  title: Search issues
+//	  description: Find issues with a JQL query. Prefer this over listIssues for anything but the latest issues.
+//	  parameters:
+//	    jql: 'JQL query, e.g. project = OPS AND status = "In Progress"'
+//	  example: {jql: "assignee = currentUser()", maxResults: 10}
+//
+// Example usage for LoadDescriptionOverrides:
+//
+//	overrides, err := openapi2mcp.LoadDescriptionOverrides("descriptions.yaml")
+//	if err != nil { log.Fatal(err) }
+//	opts.DescriptionOverrides = overrides
+func LoadDescriptionOverrides(path string) (DescriptionOverrides, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var overrides DescriptionOverrides
+	if err := yaml.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("invalid description overrides %s: %w", path, err)
+	}
+	return overrides, nil
+}
+
+// Unknown returns the operationIds of the overrides that match none of ops, e.g. because of typos.
+func (o DescriptionOverrides) Unknown(ops []OpenAPIOperation) []string {
+	known := make(map[string]bool, len(ops))
+	for _, op := range ops {
+		known[op.OperationID] = true
+	}
+	var unknown []string
+	for _, opID := range slices.Sorted(maps.Keys(o)) {
+		if !known[opID] {
+			unknown = append(unknown, opID)
+		}
+	}
+	return unknown
+}
+
+// overrideParameterDescriptions returns schema with the argument descriptions of override replaced. The properties
+// are copied, so that schemas shared between tools are never modified.
+func overrideParameterDescriptions(schema jsonschema.Schema, override DescriptionOverride) jsonschema.Schema {
+	if len(override.Parameters) == 0 || len(schema.Properties) == 0 {
+		return schema
+	}
+	props := make(map[string]*jsonschema.Schema, len(schema.Properties))
+	for name, prop := range schema.Properties {
+		if desc, ok := override.Parameters[name]; ok && prop != nil {
+			prop = shallowCopy(prop)
+			prop.Description = desc
+		}
+		props[name] = prop
+	}
+	schema.Properties = props
+	return schema
+}
@@ -0,0 +1,65 @@
+package openapi2mcp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDescriptionOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "descriptions.yaml")
+	if err := os.WriteFile(path, []byte(`searchIssues:
+  title: Search issues
+  description: Find issues with a JQL query.
+  parameters:
+    jql: 'JQL query, e.g. project = OPS'
+  example: {jql: "assignee = currentUser()"}
+deleteIsue:
+  description: typo
+`), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	overrides, err := LoadDescriptionOverrides(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /search:
+    get:
+      operationId: searchIssues
+      summary: Search
+      parameters:
+        - {name: jql, in: query, required: true, description: the query, schema: {type: string}}
+        - {name: maxResults, in: query, schema: {type: integer}}
+      responses: {"200": {description: ok}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ops := ExtractOpenAPIOperations(doc)
+	if unknown := overrides.Unknown(ops); strings.Join(unknown, ",") != "deleteIsue" {
+		t.Errorf("expected the misspelled operationId to be reported, got %v", unknown)
+	}
+
+	summaries := GenerateToolSummaries(ops, doc, &ToolGenOptions{DescriptionOverrides: overrides})
+	desc := summaries[0].Description
+	if !strings.HasPrefix(desc, "Find issues with a JQL query.") {
+		t.Errorf("expected the description to be replaced, got %q", desc)
+	}
+	if !strings.Contains(desc, "jql (string): JQL query, e.g. project = OPS") {
+		t.Errorf("expected the argument description to be replaced, got %q", desc)
+	}
+	if !strings.Contains(desc, `EXAMPLE: call searchIssues {"jql":"assignee = currentUser()"}`) {
+		t.Errorf("expected the example to be replaced, got %q", desc)
+	}
+	if got := summaries[0].InputSchema.Properties["jql"].Description; got != "JQL query, e.g. project = OPS" {
+		t.Errorf("expected the argument description in the schema to be replaced, got %q", got)
+	}
+	if got := ops[0].Parameters[0].Value.Description; got != "the query" {
+		t.Errorf("expected the spec to be unchanged, got %q", got)
+	}
+}
@@ -0,0 +1,464 @@
+// pagination.go
+package openapi2mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// paginationMetaKey is the result _meta key holding the pagination of list responses.
+const paginationMetaKey = "openapi-mcp/pagination"
+
+// Pagination tells the model whether a list response has more pages and with which arguments to get the next one, so
+// that it doesn't have to dig cursors or page numbers out of the body. It is shown after the response and in the
+// result's _meta.
+type Pagination struct {
+	HasMore       bool           `json:"has_more"`
+	NextArguments map[string]any `json:"next_arguments,omitempty"` // the complete arguments of the call for the next page
+	Page          int64          `json:"page,omitempty"`           // the page returned, for page numbers
+	TotalPages    int64          `json:"total_pages,omitempty"`
+	Total         int64          `json:"total,omitempty"` // the number of items of all pages
+}
+
+// paginationSpec describes how an operation pages its results, as documented by its x-mcp-pagination extension or
+// else found from the names of its query parameters and response fields:
+//
+//	x-mcp-pagination: {style: cursor, param: cursor, next: /meta/next_cursor, hasMore: /meta/has_more}
+//
+// Response fields are JSON pointers into the body; next may hold a cursor, a page number, or the URL of the next page.
+// x-mcp-pagination: false turns pagination off for an operation.
+type paginationSpec struct {
+	Style      string `json:"style"`                // cursor, page, or offset
+	Param      string `json:"param"`                // argument taking the cursor, page number, or offset
+	SizeParam  string `json:"sizeParam,omitempty"`  // argument taking the page size, if any
+	Next       string `json:"next,omitempty"`       // the next cursor, page number, or page URL
+	HasMore    string `json:"hasMore,omitempty"`    // whether there are more pages
+	Total      string `json:"total,omitempty"`      // the number of items of all pages
+	TotalPages string `json:"totalPages,omitempty"` // the number of pages
+	Items      string `json:"items,omitempty"`      // the items of the page; "" if the body is the array of items
+
+	first       int64 // the page number or offset of the first page
+	defaultSize int64 // the page size if the call sets none; 0 if unknown
+}
+
+// Parameter and response field names of common pagination schemes, lowercased without separators, e.g. "perpage"
+// for per_page, "top" for OData's $top, or "odatanextlink" for @odata.nextLink.
+var (
+	cursorParamNames = []string{"cursor", "pagetoken", "nextpagetoken", "startingafter", "after", "nexttoken", "continuationtoken", "continuation", "marker", "pagecursor"}
+	pageParamNames   = []string{"page", "pagenumber", "pageno", "pagenum"}
+	offsetParamNames = []string{"offset", "skip", "start", "startindex"}
+	sizeParamNames   = []string{"perpage", "pagesize", "limit", "size", "maxresults", "top", "pagelength", "maxitems", "first"}
+	nextFieldNames   = []string{"nextcursor", "nextpagetoken", "nexttoken", "endcursor", "continuationtoken", "nextmarker", "next", "nextpage", "nextlink", "odatanextlink", "nextpageurl", "nexturl"}
+	hasMoreNames     = []string{"hasmore", "hasnextpage", "hasnext", "more"}
+	totalNames       = []string{"total", "totalcount", "totalitems", "totalresults", "totalsize"}
+	totalPagesNames  = []string{"totalpages", "pagecount", "lastpage", "numpages"}
+	itemsNames       = []string{"data", "items", "results", "records", "entries", "nodes", "value"}
+)
+
+// paginationName normalizes a parameter or field name for matching the names of common pagination schemes.
+func paginationName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '_', '-', '.', '$', '@', '[', ']':
+			return -1
+		}
+		return r
+	}, strings.ToLower(name))
+}
+
+// operationPagination returns how the operation pages its results, or nil if it doesn't.
+func operationPagination(op OpenAPIOperation, doc *openapi3.T) *paginationSpec {
+	if doc != nil && doc.Paths != nil {
+		if item := doc.Paths.Value(op.Path); item != nil {
+			if o := item.GetOperation(strings.ToUpper(op.Method)); o != nil {
+				switch v := o.Extensions["x-mcp-pagination"].(type) {
+				case bool:
+					if !v {
+						return nil
+					}
+				case map[string]any:
+					if spec := parsePaginationExtension(v); spec != nil {
+						spec.defaults(op)
+						return spec
+					}
+				}
+			}
+		}
+	}
+
+	// Find the parameter selecting the page, preferring cursors over page numbers over offsets
+	params := make(map[string]string) // normalized names of query parameters to argument names
+	for _, ref := range op.Parameters {
+		if ref != nil && ref.Value != nil && ref.Value.In == "query" {
+			params[paginationName(ref.Value.Name)] = escapeParameterName(ref.Value.Name)
+		}
+	}
+	spec := &paginationSpec{}
+	for _, scheme := range []struct {
+		style string
+		names []string
+	}{{"cursor", cursorParamNames}, {"page", pageParamNames}, {"offset", offsetParamNames}} {
+		for _, name := range scheme.names {
+			if arg, ok := params[name]; ok && spec.Param == "" {
+				spec.Style, spec.Param = scheme.style, arg
+			}
+		}
+	}
+	if spec.Param == "" {
+		return nil
+	}
+	for _, name := range sizeParamNames {
+		if arg, ok := params[name]; ok && spec.SizeParam == "" {
+			spec.SizeParam = arg
+		}
+	}
+
+	// Find the response fields telling where the page is, at the top level or in an object of pagination metadata
+	if schema := responseSchemaFor(op, http.StatusOK); schema != nil {
+		spec.Next = findPaginationField(schema, nextFieldNames)
+		spec.HasMore = findPaginationField(schema, hasMoreNames)
+		spec.Total = findPaginationField(schema, totalNames)
+		spec.TotalPages = findPaginationField(schema, totalPagesNames)
+		if !schema.Type.Is("array") {
+			spec.Items = findItemsField(schema)
+		}
+	}
+	spec.defaults(op)
+	return spec
+}
+
+// parsePaginationExtension reads an x-mcp-pagination extension, returning nil if it is invalid.
+func parsePaginationExtension(ext map[string]any) *paginationSpec {
+	data, err := json.Marshal(ext)
+	if err != nil {
+		return nil
+	}
+	var spec paginationSpec
+	if json.Unmarshal(data, &spec) != nil || spec.Param == "" || !slices.Contains([]string{"cursor", "page", "offset"}, spec.Style) {
+		return nil
+	}
+	return &spec
+}
+
+// defaults sets the first page and the default page size from the parameters' schema defaults.
+func (p *paginationSpec) defaults(op OpenAPIOperation) {
+	if p.Style == "page" {
+		p.first = 1
+	}
+	for _, ref := range op.Parameters {
+		if ref == nil || ref.Value == nil || ref.Value.Schema == nil || ref.Value.Schema.Value == nil {
+			continue
+		}
+		value, ok := paginationNumber(ref.Value.Schema.Value.Default)
+		if !ok {
+			continue
+		}
+		switch escapeParameterName(ref.Value.Name) {
+		case p.Param:
+			if p.Style != "cursor" {
+				p.first = value
+			}
+		case p.SizeParam:
+			p.defaultSize = value
+		}
+	}
+}
+
+// findPaginationField returns the JSON pointer to the first field of the schema with one of the names, looking at
+// the top-level properties first and then at those of top-level objects, e.g. meta.next_cursor.
+func findPaginationField(schema *openapi3.Schema, names []string) string {
+	for _, nested := range []bool{false, true} {
+		for _, name := range names {
+			for _, prop := range slices.Sorted(maps.Keys(schema.Properties)) {
+				ref := schema.Properties[prop]
+				if ref == nil || ref.Value == nil {
+					continue
+				}
+				if !nested && paginationName(prop) == name {
+					return "/" + escapeJSONPointer(prop)
+				}
+				if nested && ref.Value.Type.Is("object") {
+					for _, field := range slices.Sorted(maps.Keys(ref.Value.Properties)) {
+						if paginationName(field) == name {
+							return "/" + escapeJSONPointer(prop) + "/" + escapeJSONPointer(field)
+						}
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// findItemsField returns the JSON pointer to the array holding the items of a page, preferring common names if the
+// schema has several arrays, or "" if it has none.
+func findItemsField(schema *openapi3.Schema) string {
+	var arrays []string
+	for _, prop := range slices.Sorted(maps.Keys(schema.Properties)) {
+		if ref := schema.Properties[prop]; ref != nil && ref.Value != nil && ref.Value.Type.Is("array") {
+			arrays = append(arrays, prop)
+		}
+	}
+	for _, name := range itemsNames {
+		for _, prop := range arrays {
+			if paginationName(prop) == name {
+				return "/" + escapeJSONPointer(prop)
+			}
+		}
+	}
+	if len(arrays) == 1 {
+		return "/" + escapeJSONPointer(arrays[0])
+	}
+	return ""
+}
+
+// paginate returns the pagination of a response to a call with args, or nil if the response doesn't tell whether
+// there are more pages. body is the decoded JSON body, or nil.
+func (p *paginationSpec) paginate(args map[string]any, header http.Header, body any) *Pagination {
+	pagination := &Pagination{}
+	known := false // whether the response tells if there are more pages
+
+	// The response may name the next page: in a field, or in a Link header (RFC 8288)
+	var next any
+	if p.Next != "" && body != nil {
+		next, known = p.nextValue(jsonPointerValue(body, p.Next)), hasJSONPointer(body, p.Next)
+	}
+	if link := nextLink(header); next == nil && link != "" {
+		next, known = p.nextValue(link), true
+	}
+
+	var hasMore, hasMoreKnown bool
+	if p.HasMore != "" {
+		hasMore, hasMoreKnown = jsonPointerValue(body, p.HasMore).(bool)
+	}
+	if p.Total != "" {
+		pagination.Total, _ = paginationNumber(jsonPointerValue(body, p.Total))
+	}
+	if p.TotalPages != "" {
+		pagination.TotalPages, _ = paginationNumber(jsonPointerValue(body, p.TotalPages))
+	}
+	items := int64(-1) // unknown
+	if v, ok := jsonPointerValue(body, p.Items).([]any); ok {
+		items = int64(len(v))
+	}
+	size, ok := paginationNumber(args[p.SizeParam])
+	if !ok {
+		size = p.defaultSize
+	}
+
+	// Page numbers and offsets can be counted on if the response doesn't name the next page
+	switch p.Style {
+	case "page":
+		page, ok := paginationNumber(args[p.Param])
+		if !ok {
+			page = p.first
+		}
+		pagination.Page = page
+		more, moreKnown := hasMore, hasMoreKnown
+		switch {
+		case moreKnown:
+		case pagination.TotalPages > 0:
+			more, moreKnown = page < pagination.TotalPages, true
+		case pagination.Total > 0 && size > 0:
+			more, moreKnown = (page-p.first+1)*size < pagination.Total, true
+		case items >= 0 && size > 0:
+			more, moreKnown = items >= size, true
+		}
+		if next == nil && more {
+			next = page + 1
+		}
+		known = known || moreKnown
+	case "offset":
+		offset, ok := paginationNumber(args[p.Param])
+		if !ok {
+			offset = p.first
+		}
+		count := items
+		if count < 0 {
+			count = size
+		}
+		more, moreKnown := hasMore, hasMoreKnown
+		switch {
+		case moreKnown:
+		case pagination.Total > 0 && count >= 0:
+			more, moreKnown = offset+count < pagination.Total, true
+		case items >= 0 && size > 0:
+			more, moreKnown = items >= size, true
+		}
+		if next == nil && more && count > 0 {
+			next = offset + count
+		}
+		known = known || moreKnown
+	default:
+		// Cursors such as Stripe's starting_after take the ID of the last item
+		if next == nil && hasMore {
+			next = lastItemID(jsonPointerValue(body, p.Items))
+		}
+		known = known || hasMoreKnown
+	}
+	if hasMoreKnown && !hasMore {
+		next = nil
+	}
+	if !known && next == nil {
+		return nil
+	}
+
+	pagination.HasMore = next != nil || hasMore
+	if next != nil {
+		pagination.NextArguments = maps.Clone(args)
+		delete(pagination.NextArguments, "__confirmed")
+		pagination.NextArguments[p.Param] = next
+	}
+	return pagination
+}
+
+// nextValue returns the argument for the next page from a field or Link header naming it: the URL of the next page,
+// whose query has the argument, or else the value itself. It returns nil for values naming no page.
+func (p *paginationSpec) nextValue(v any) any {
+	switch v := v.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		if u, err := url.Parse(v); err == nil && (u.IsAbs() || strings.HasPrefix(v, "/") || strings.HasPrefix(v, "?")) {
+			param := u.Query().Get(p.Param)
+			if param == "" {
+				return nil
+			}
+			v = param
+		}
+		if p.Style == "cursor" {
+			return v
+		}
+		if n, ok := paginationNumber(v); ok {
+			return n
+		}
+		return nil
+	case bool:
+		return nil
+	}
+	if p.Style == "cursor" {
+		return v
+	}
+	if n, ok := paginationNumber(v); ok {
+		return n
+	}
+	return nil
+}
+
+// lastItemID returns the id of the last of the items, or nil.
+func lastItemID(items any) any {
+	list, ok := items.([]any)
+	if !ok || len(list) == 0 {
+		return nil
+	}
+	item, _ := list[len(list)-1].(map[string]any)
+	return item["id"]
+}
+
+// hasJSONPointer reports whether the field at ptr exists in v, even if it is null.
+func hasJSONPointer(v any, ptr string) bool {
+	i := strings.LastIndex(ptr, "/")
+	if i < 0 {
+		return false
+	}
+	parent, ok := lookupJSONPointer(v, ptr[:i])
+	if !ok {
+		return false
+	}
+	obj, ok := parent.(map[string]any)
+	if !ok {
+		return false
+	}
+	_, ok = obj[unescapeJSONPointer(ptr[i+1:])]
+	return ok
+}
+
+// jsonPointerValue returns the value at ptr within v, or nil.
+func jsonPointerValue(v any, ptr string) any {
+	value, _ := lookupJSONPointer(v, ptr)
+	return value
+}
+
+// nextLink returns the URL of the Link header's rel="next" link, or "".
+func nextLink(header http.Header) string {
+	for _, value := range header.Values("Link") {
+		for _, link := range strings.Split(value, ",") {
+			target, params, ok := strings.Cut(strings.TrimSpace(link), ";")
+			if !ok || !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range strings.Split(params, ";") {
+				name, rel, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if strings.EqualFold(name, "rel") && slices.Contains(strings.Fields(strings.Trim(rel, `"`)), "next") {
+					return target[1 : len(target)-1]
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// paginationNumber returns v as an integer, if it is one.
+func paginationNumber(v any) (int64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return int64(n), n == float64(int64(n))
+	case int:
+		return int64(n), true
+	case int64:
+		return n, true
+	case json.Number:
+		i, err := n.Int64()
+		return i, err == nil
+	case string:
+		i, err := strconv.ParseInt(n, 10, 64)
+		return i, err == nil
+	}
+	return 0, false
+}
+
+// setResultPagination records the pagination, if any, in the result's _meta field.
+func setResultPagination(result *mcp.CallToolResult, pagination *Pagination) {
+	if result == nil || pagination == nil {
+		return
+	}
+	if result.Meta == nil {
+		result.Meta = mcp.Meta{}
+	}
+	result.Meta[paginationMetaKey] = pagination
+}
+
+// paginationText tells the model whether there are more pages, and how to call the tool for the next one.
+func paginationText(p *Pagination, toolName string) string {
+	var facts []string
+	if p.Page > 0 && p.TotalPages > 0 {
+		facts = append(facts, fmt.Sprintf("page %d of %d", p.Page, p.TotalPages))
+	} else if p.Page > 0 {
+		facts = append(facts, fmt.Sprintf("page %d", p.Page))
+	}
+	if p.Total > 0 {
+		facts = append(facts, fmt.Sprintf("%d in total", p.Total))
+	}
+	details := ""
+	if len(facts) > 0 {
+		details = " (" + strings.Join(facts, ", ") + ")"
+	}
+	if !p.HasMore {
+		return "\n\nPAGINATION: This is the last page" + details + "."
+	}
+	if p.NextArguments == nil {
+		return "\n\nPAGINATION: More results are available" + details + ", but the response doesn't say how to get the next page."
+	}
+	args, _ := json.Marshal(p.NextArguments)
+	return fmt.Sprintf("\n\nPAGINATION: More results are available%s. For the next page, call %s with: %s", details, toolName, args)
+}
@@ -0,0 +1,190 @@
+package openapi2mcp
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+const paginatedSpec = `openapi: 3.0.0
+info: {title: Shop, version: "1.0"}
+paths:
+  /orders:
+    get:
+      operationId: listOrders
+      parameters:
+        - {name: cursor, in: query, schema: {type: string}}
+        - {name: limit, in: query, schema: {type: integer, default: 20}}
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  orders: {type: array, items: {type: object}}
+                  meta: {type: object, properties: {next_cursor: {type: string, nullable: true}, total_count: {type: integer}}}
+  /customers:
+    get:
+      operationId: listCustomers
+      parameters:
+        - {name: page, in: query, schema: {type: integer}}
+        - {name: per_page, in: query, schema: {type: integer, default: 2}}
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema: {type: array, items: {type: object}}
+  /charges:
+    get:
+      operationId: listCharges
+      parameters:
+        - {name: starting_after, in: query, schema: {type: string}}
+      responses:
+        "200":
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  data: {type: array, items: {type: object}}
+                  has_more: {type: boolean}
+  /events:
+    get:
+      operationId: listEvents
+      x-mcp-pagination: {style: offset, param: from, items: /hits, total: /total}
+      parameters:
+        - {name: from, in: query, schema: {type: integer}}
+      responses: {"200": {description: OK}}
+  /logs:
+    get:
+      operationId: listLogs
+      x-mcp-pagination: false
+      parameters:
+        - {name: page, in: query, schema: {type: integer}}
+      responses: {"200": {description: OK}}
+`
+
+func TestPaginate(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(paginatedSpec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	specs := make(map[string]*paginationSpec)
+	for _, op := range ExtractOpenAPIOperations(doc) {
+		specs[op.OperationID] = operationPagination(op, doc)
+	}
+	if specs["listLogs"] != nil {
+		t.Errorf("expected x-mcp-pagination: false to turn pagination off")
+	}
+
+	for _, tt := range []struct {
+		name      string
+		operation string
+		args      map[string]any
+		header    http.Header
+		body      any
+		want      *Pagination
+	}{
+		{
+			name:      "cursor in a nested field",
+			operation: "listOrders",
+			args:      map[string]any{"limit": 10.0},
+			body:      map[string]any{"orders": []any{}, "meta": map[string]any{"next_cursor": "c2", "total_count": 42.0}},
+			want:      &Pagination{HasMore: true, NextArguments: map[string]any{"limit": 10.0, "cursor": "c2"}, Total: 42},
+		},
+		{
+			name:      "cursor field of the last page",
+			operation: "listOrders",
+			args:      map[string]any{"cursor": "c9"},
+			body:      map[string]any{"orders": []any{}, "meta": map[string]any{"next_cursor": nil}},
+			want:      &Pagination{},
+		},
+		{
+			name:      "page number counted from the page size",
+			operation: "listCustomers",
+			args:      map[string]any{"page": 2.0},
+			body:      []any{map[string]any{}, map[string]any{}},
+			want:      &Pagination{HasMore: true, NextArguments: map[string]any{"page": int64(3)}, Page: 2},
+		},
+		{
+			name:      "short page",
+			operation: "listCustomers",
+			args:      map[string]any{},
+			body:      []any{map[string]any{}},
+			want:      &Pagination{Page: 1},
+		},
+		{
+			name:      "page number from the Link header",
+			operation: "listCustomers",
+			args:      map[string]any{"per_page": 50.0},
+			header:    http.Header{"Link": {`<https://api.example.com/customers?page=2&per_page=50>; rel="next", <https://api.example.com/customers?page=9&per_page=50>; rel="last"`}},
+			want:      &Pagination{HasMore: true, NextArguments: map[string]any{"per_page": 50.0, "page": int64(2)}, Page: 1},
+		},
+		{
+			name:      "ID of the last item",
+			operation: "listCharges",
+			args:      map[string]any{"__confirmed": true},
+			body:      map[string]any{"data": []any{map[string]any{"id": "ch_1"}, map[string]any{"id": "ch_2"}}, "has_more": true},
+			want:      &Pagination{HasMore: true, NextArguments: map[string]any{"starting_after": "ch_2"}},
+		},
+		{
+			name:      "offset from the extension",
+			operation: "listEvents",
+			args:      map[string]any{"from": 20.0},
+			body:      map[string]any{"hits": []any{1.0, 2.0, 3.0}, "total": 50.0},
+			want:      &Pagination{HasMore: true, NextArguments: map[string]any{"from": int64(23)}, Total: 50},
+		},
+		{
+			name:      "offset of the last page",
+			operation: "listEvents",
+			body:      map[string]any{"hits": []any{1.0, 2.0, 3.0}, "total": 3.0},
+			want:      &Pagination{Total: 3},
+		},
+		{
+			name:      "response telling nothing",
+			operation: "listEvents",
+			body:      map[string]any{"hits": []any{1.0}},
+			want:      nil,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := specs[tt.operation].paginate(tt.args, tt.header, tt.body); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestToolHandler_Pagination(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(paginatedSpec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var op OpenAPIOperation
+	for _, o := range ExtractOpenAPIOperations(doc) {
+		if o.OperationID == "listOrders" {
+			op = o
+		}
+	}
+	opts := &ToolGenOptions{RequestHandler: fakeResponse(200, "application/json", `{"orders": [], "meta": {"next_cursor": "c2"}}`)}
+	handler := toolHandler("listOrders", op, doc, jsonschema.Schema{}, []string{"http://example.com"}, opts, newServerRuntime())
+
+	res, _, err := handler(context.Background(), nil, map[string]any{"limit": 5.0})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(t, res); !strings.Contains(text, `PAGINATION: More results are available. For the next page, call listOrders with: {"cursor":"c2","limit":5}`) {
+		t.Errorf("expected the next call in the result, got: %s", text)
+	}
+	if pagination, _ := res.Meta[paginationMetaKey].(*Pagination); pagination == nil || pagination.NextArguments["cursor"] != "c2" {
+		t.Errorf("expected the pagination in _meta, got %v", res.Meta)
+	}
+}
@@ -0,0 +1,168 @@
+// patch.go
+package openapi2mcp
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// Media types of patch documents.
+const (
+	mergePatchType = "application/merge-patch+json" // JSON Merge Patch, RFC 7396
+	jsonPatchType  = "application/json-patch+json"  // JSON Patch, RFC 6902
+)
+
+// jsonPatchOps are the operations of JSON Patch documents.
+var jsonPatchOps = []string{"add", "remove", "replace", "move", "copy", "test"}
+
+// patchRequestType returns the patch media type of the request body, JSON Merge Patch before JSON Patch, or "" if
+// there is none or the operation accepts JSON.
+func patchRequestType(content openapi3.Content) string {
+	if acceptsJSON(content) {
+		return ""
+	}
+	for _, patchType := range []string{mergePatchType, jsonPatchType} {
+		for contentType := range content {
+			if baseMediaType(contentType) == patchType {
+				return contentType
+			}
+		}
+	}
+	return ""
+}
+
+// patchRequestBodySchema describes the requestBody argument of a patch document: for JSON Merge Patch, the
+// documented object with every field optional and nullable; for JSON Patch, an array of operations.
+func patchRequestBodySchema(contentType string, mt *openapi3.MediaType, cache *schemaCache) *jsonschema.Schema {
+	if baseMediaType(contentType) == jsonPatchType {
+		var opsEnum []any
+		for _, op := range jsonPatchOps {
+			opsEnum = append(opsEnum, op)
+		}
+		return &jsonschema.Schema{
+			Type:        "array",
+			Description: "The JSON Patch (RFC 6902) to apply: operations applied in order, e.g. [{\"op\": \"replace\", \"path\": \"/name\", \"value\": \"Rex\"}].",
+			Items: &jsonschema.Schema{
+				Type:     "object",
+				Required: []string{"op", "path"},
+				Properties: map[string]*jsonschema.Schema{
+					"op":    {Type: "string", Enum: opsEnum},
+					"path":  {Type: "string", Description: "JSON Pointer to the target location, e.g. /tags/0; ~1 escapes / and ~0 escapes ~."},
+					"value": {Description: "The value to add, replace with, or test for (add, replace, test)."},
+					"from":  {Type: "string", Description: "JSON Pointer to the source location (move, copy)."},
+				},
+			},
+		}
+	}
+
+	var patch *jsonschema.Schema
+	if mt != nil && mt.Schema != nil && mt.Schema.Value != nil {
+		patch = mergePatchSchema(extractProperty(mt.Schema, cache), map[*jsonschema.Schema]*jsonschema.Schema{})
+	}
+	if patch == nil {
+		patch = &jsonschema.Schema{Type: "object"}
+	}
+	patch.Description = "The JSON Merge Patch (RFC 7396) to apply: only the fields to change, nested objects are merged; set a field to null to remove it."
+	return patch
+}
+
+// mergePatchSchema returns a copy of the object schema s in which no field is required and every field may be null.
+func mergePatchSchema(s *jsonschema.Schema, seen map[*jsonschema.Schema]*jsonschema.Schema) *jsonschema.Schema {
+	if s == nil {
+		return nil
+	}
+	if c, ok := seen[s]; ok {
+		return c
+	}
+	c := shallowCopy(s)
+	seen[s] = c
+	c.Required = nil
+	if len(s.Properties) > 0 {
+		c.Properties = make(map[string]*jsonschema.Schema, len(s.Properties))
+		for name, prop := range s.Properties {
+			c.Properties[name] = nullable(mergePatchSchema(prop, seen))
+		}
+	}
+	return c
+}
+
+// nullable returns s allowing null values, modifying s, which must be a copy.
+func nullable(s *jsonschema.Schema) *jsonschema.Schema {
+	if s == nil {
+		return nil
+	}
+	switch {
+	case s.Type != "" && s.Type != "null":
+		s.Types, s.Type = []string{s.Type, "null"}, ""
+	case len(s.Types) > 0 && !slices.Contains(s.Types, "null"):
+		s.Types = append(slices.Clone(s.Types), "null")
+	}
+	if len(s.Enum) > 0 && !slices.Contains(s.Enum, nil) {
+		s.Enum = append(slices.Clone(s.Enum), nil)
+	}
+	return s
+}
+
+// validateJSONPatch checks a JSON Patch document before it is sent, returning the first invalid operation.
+func validateJSONPatch(v any) error {
+	ops, ok := v.([]any)
+	if !ok {
+		return fmt.Errorf("expected an array of operations, got %T", v)
+	}
+	for i, raw := range ops {
+		op, ok := raw.(map[string]any)
+		if !ok {
+			return fmt.Errorf("operation %d: expected an object with op and path, got %T", i, raw)
+		}
+		name, _ := op["op"].(string)
+		if !slices.Contains(jsonPatchOps, name) {
+			return fmt.Errorf("operation %d: op must be one of %s, got %v", i, strings.Join(jsonPatchOps, ", "), op["op"])
+		}
+		path, ok := op["path"].(string)
+		if !ok {
+			return fmt.Errorf("operation %d (%s): path is required", i, name)
+		}
+		if err := validateJSONPointer(path); err != nil {
+			return fmt.Errorf("operation %d (%s %s): path %v", i, name, path, err)
+		}
+		switch name {
+		case "add", "replace", "test":
+			if _, ok := op["value"]; !ok {
+				return fmt.Errorf("operation %d (%s %s): value is required", i, name, path)
+			}
+		case "move", "copy":
+			from, ok := op["from"].(string)
+			if !ok {
+				return fmt.Errorf("operation %d (%s %s): from is required", i, name, path)
+			}
+			if err := validateJSONPointer(from); err != nil {
+				return fmt.Errorf("operation %d (%s %s): from %v", i, name, path, err)
+			}
+			if name == "move" && strings.HasPrefix(path+"/", from+"/") && path != from {
+				return fmt.Errorf("operation %d (move %s): a location can't be moved into one of its children", i, path)
+			}
+		}
+	}
+	return nil
+}
+
+// validateJSONPointer checks the syntax of a JSON Pointer (RFC 6901): empty, or /-separated tokens in which ~ is
+// only followed by 0 or 1.
+func validateJSONPointer(pointer string) error {
+	if pointer == "" {
+		return nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return fmt.Errorf("must be a JSON Pointer starting with /, e.g. /%s", strings.ReplaceAll(strings.TrimPrefix(pointer, "$."), ".", "/"))
+	}
+	for i := 0; i < len(pointer); i++ {
+		if pointer[i] == '~' && (i+1 == len(pointer) || pointer[i+1] != '0' && pointer[i+1] != '1') {
+			return fmt.Errorf("has an invalid escape at %d: ~ must be followed by 0 (for ~) or 1 (for /)", i)
+		}
+	}
+	return nil
+}
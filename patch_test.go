@@ -0,0 +1,107 @@
+package openapi2mcp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestPatchRequestBodySchema(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /pets/{id}:
+    patch:
+      operationId: updatePet
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+      requestBody:
+        required: true
+        content:
+          application/merge-patch+json:
+            schema:
+              type: object
+              required: [name]
+              properties:
+                name: {type: string}
+                owner: {type: object, required: [email], properties: {email: {type: string}}}
+      responses: {"200": {description: ok}}
+  /pets/{id}/tags:
+    patch:
+      operationId: patchPetTags
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+      requestBody:
+        content:
+          application/json-patch+json:
+            schema: {type: array, items: {type: object}}
+      responses: {"200": {description: ok}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ops := map[string]OpenAPIOperation{}
+	for _, op := range ExtractOpenAPIOperations(doc) {
+		ops[op.OperationID] = op
+	}
+
+	merge := BuildInputSchema(ops["updatePet"].Parameters, ops["updatePet"].RequestBody).Properties["requestBody"]
+	if merge == nil || merge.Type != "object" || len(merge.Required) > 0 {
+		t.Fatalf("expected a partial object, got %+v", merge)
+	}
+	owner := merge.Properties["owner"]
+	if len(owner.Required) > 0 || strings.Join(owner.Types, ",") != "object,null" || strings.Join(merge.Properties["name"].Types, ",") != "string,null" {
+		t.Errorf("expected optional, nullable fields, got owner %+v, name %+v", owner, merge.Properties["name"])
+	}
+
+	patch := BuildInputSchema(ops["patchPetTags"].Parameters, ops["patchPetTags"].RequestBody).Properties["requestBody"]
+	if patch == nil || patch.Type != "array" || patch.Items.Properties["op"] == nil || len(patch.Items.Required) != 2 {
+		t.Errorf("expected an array of operations, got %+v", patch)
+	}
+
+	var contentType, sent string
+	opts := &ToolGenOptions{RequestHandler: func(req *http.Request) (*http.Response, error) {
+		body, _ := io.ReadAll(req.Body)
+		contentType, sent = req.Header.Get("Content-Type"), string(body)
+		return fakeResponse(200, "application/json", `{}`)(req)
+	}}
+	handler := toolHandler("updatePet", ops["updatePet"], doc, BuildInputSchema(ops["updatePet"].Parameters, ops["updatePet"].RequestBody), []string{"http://example.com"}, opts, nil)
+	if _, _, err := handler(context.Background(), nil, map[string]any{"id": "1", "requestBody": map[string]any{"owner": nil}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if contentType != "application/merge-patch+json" || sent != `{"owner":null}` {
+		t.Errorf("expected the merge patch to be sent as is, got %s %s", contentType, sent)
+	}
+
+	// Malformed JSON Patch documents are rejected before they are sent
+	sent = ""
+	handler = toolHandler("patchPetTags", ops["patchPetTags"], doc, BuildInputSchema(ops["patchPetTags"].Parameters, ops["patchPetTags"].RequestBody), []string{"http://example.com"}, opts, nil)
+	res, _, err := handler(context.Background(), nil, map[string]any{"id": "1", "requestBody": []any{map[string]any{"op": "replace", "path": "tags.0"}}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(t, res); !res.IsError || sent != "" || !strings.Contains(text, "operation 0 (replace tags.0): path must be a JSON Pointer starting with /, e.g. /tags/0") {
+		t.Errorf("expected the patch to be rejected, got: %s", text)
+	}
+}
+
+func TestValidateJSONPatch(t *testing.T) {
+	for _, tc := range []struct {
+		patch []any
+		err   string
+	}{
+		{patch: []any{map[string]any{"op": "add", "path": "/tags/-", "value": "cute"}, map[string]any{"op": "remove", "path": "/a~1b"}}},
+		{patch: []any{map[string]any{"op": "update", "path": "/name"}}, err: "operation 0: op must be one of add, remove, replace, move, copy, test, got update"},
+		{patch: []any{map[string]any{"op": "replace", "path": "/name"}}, err: "operation 0 (replace /name): value is required"},
+		{patch: []any{map[string]any{"op": "copy", "path": "/b"}}, err: "operation 0 (copy /b): from is required"},
+		{patch: []any{map[string]any{"op": "move", "from": "/a", "path": "/a/b"}}, err: "operation 0 (move /a/b): a location can't be moved into one of its children"},
+		{patch: []any{map[string]any{"op": "remove", "path": "/a~2"}}, err: "operation 0 (remove /a~2): path has an invalid escape at 2"},
+	} {
+		err := validateJSONPatch(tc.patch)
+		if tc.err == "" && err != nil || tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+			t.Errorf("validateJSONPatch(%v) = %v, want %q", tc.patch, err, tc.err)
+		}
+	}
+}
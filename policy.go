@@ -0,0 +1,221 @@
+// policy.go
+package openapi2mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.yaml.in/yaml/v3"
+)
+
+// Policy authorizes tool calls, e.g. to expose a spec read-write to some sessions and read-only to others.
+// Rules are evaluated in order and the first matching rule decides; if none matches, Default applies.
+type Policy struct {
+	Default        string       `json:"default,omitempty" yaml:"default,omitempty"`               // "allow" (default) or "deny"
+	IdentityHeader string       `json:"identityHeader,omitempty" yaml:"identityHeader,omitempty"` // incoming HTTP header carrying the caller's identity, e.g. "X-User"
+	Rules          []PolicyRule `json:"rules,omitempty" yaml:"rules,omitempty"`
+
+	// Identity optionally returns the caller's identity, e.g. from req.Extra.TokenInfo.
+	// If nil, the IdentityHeader value is used, or else the MCP session ID.
+	Identity func(req *mcp.CallToolRequest) string `json:"-" yaml:"-"`
+}
+
+// PolicyRule matches tool calls by all of its non-empty conditions; each list matches if any of its entries does.
+//
+// Operations, Paths, and Sessions are path.Match patterns ("list*", "/admin/*"); a path pattern ending in "/**"
+// also matches everything below it. Arguments maps argument names, with "." selecting fields of object
+// arguments (e.g. "requestBody.status"), to patterns matching their value as a string.
+type PolicyRule struct {
+	Effect     string            `json:"effect" yaml:"effect"` // "allow" or "deny"
+	Operations []string          `json:"operations,omitempty" yaml:"operations,omitempty"`
+	Methods    []string          `json:"methods,omitempty" yaml:"methods,omitempty"`
+	Paths      []string          `json:"paths,omitempty" yaml:"paths,omitempty"`
+	Arguments  map[string]string `json:"arguments,omitempty" yaml:"arguments,omitempty"`
+	Sessions   []string          `json:"sessions,omitempty" yaml:"sessions,omitempty"`
+	Reason     string            `json:"reason,omitempty" yaml:"reason,omitempty"` // shown to the model when a call is denied
+}
+
+// LoadPolicy reads and validates a policy from a YAML or JSON file:
+//
+//	default: deny
+//	identityHeader: X-User
+//	rules:
+//	  - effect: allow
+//	    sessions: ["admin-*"]
+//	  - effect: deny
+//	    operations: [deletePet]
+//	    reason: Deleting pets is disabled
+//	  - effect: allow
+//	    methods: [GET]
+//
+// Example usage for LoadPolicy:
+//
+//	policy, err := openapi2mcp.LoadPolicy("policy.yaml")
+//	if err != nil { log.Fatal(err) }
+//	opts.Policy = policy
+func LoadPolicy(file string) (*Policy, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var policy Policy
+	if err := yaml.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %w", file, err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %w", file, err)
+	}
+	return &policy, nil
+}
+
+// Validate checks the effects and patterns of the policy.
+func (p *Policy) Validate() error {
+	if p.Default != "" && p.Default != "allow" && p.Default != "deny" {
+		return fmt.Errorf("default must be \"allow\" or \"deny\", got %q", p.Default)
+	}
+	for i, rule := range p.Rules {
+		if rule.Effect != "allow" && rule.Effect != "deny" {
+			return fmt.Errorf("rule %d: effect must be \"allow\" or \"deny\", got %q", i+1, rule.Effect)
+		}
+		patterns := append(append(append([]string(nil), rule.Operations...), rule.Paths...), rule.Sessions...)
+		for _, pattern := range rule.Arguments {
+			patterns = append(patterns, pattern)
+		}
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("rule %d: invalid pattern %q", i+1, pattern)
+			}
+		}
+	}
+	return nil
+}
+
+// authorize evaluates the policy for a call of op and returns whether it is allowed, and the reason if not.
+func (p *Policy) authorize(op OpenAPIOperation, req *mcp.CallToolRequest, args map[string]any) (bool, string) {
+	identity := p.identity(req)
+	for i, rule := range p.Rules {
+		if !rule.matches(op, identity, args) {
+			continue
+		}
+		if rule.Effect == "allow" {
+			return true, ""
+		}
+		if rule.Reason != "" {
+			return false, rule.Reason
+		}
+		return false, fmt.Sprintf("denied by policy rule %d", i+1)
+	}
+	if p.Default == "deny" {
+		return false, "no policy rule allows this operation"
+	}
+	return true, ""
+}
+
+// identity returns the caller's identity matched by the rules' Sessions.
+func (p *Policy) identity(req *mcp.CallToolRequest) string {
+	if p.Identity != nil {
+		return p.Identity(req)
+	}
+	if req == nil {
+		return ""
+	}
+	if p.IdentityHeader != "" {
+		if req.Extra != nil && req.Extra.Header != nil {
+			return req.Extra.Header.Get(p.IdentityHeader)
+		}
+		return ""
+	}
+	return sessionCorrelationID(req.Session)
+}
+
+// matches reports whether the call satisfies all conditions of the rule.
+func (r PolicyRule) matches(op OpenAPIOperation, identity string, args map[string]any) bool {
+	if len(r.Operations) > 0 && !matchAny(r.Operations, op.OperationID) {
+		return false
+	}
+	if len(r.Methods) > 0 && !containsFold(r.Methods, op.Method) {
+		return false
+	}
+	if len(r.Paths) > 0 && !matchAnyPath(r.Paths, op.Path) {
+		return false
+	}
+	if len(r.Sessions) > 0 && (identity == "" || !matchAny(r.Sessions, identity)) {
+		return false
+	}
+	for name, pattern := range r.Arguments {
+		value, ok := argumentString(args, name)
+		if !ok {
+			return false
+		}
+		if matched, _ := path.Match(pattern, value); !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// matchAny reports whether s matches any of the path.Match patterns.
+func matchAny(patterns []string, s string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, s); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAnyPath is matchAny for path templates, where a trailing "/**" matches any path below the prefix.
+func matchAnyPath(patterns []string, p string) bool {
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "/**"); ok {
+			if p == prefix || strings.HasPrefix(p, prefix+"/") {
+				return true
+			}
+			continue
+		}
+		if matched, _ := path.Match(pattern, p); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold reports whether list contains s, ignoring case.
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// argumentString returns the argument addressed by a dotted name as a string; objects and arrays are returned as JSON.
+func argumentString(args map[string]any, name string) (string, bool) {
+	var v any = args
+	for _, key := range strings.Split(name, ".") {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return "", false
+		}
+		if v, ok = m[key]; !ok {
+			return "", false
+		}
+	}
+	switch val := v.(type) {
+	case string:
+		return val, true
+	case nil:
+		return "null", true
+	default:
+		b, err := json.Marshal(val)
+		if err != nil {
+			return "", false
+		}
+		return string(b), true
+	}
+}
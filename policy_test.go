@@ -0,0 +1,104 @@
+package openapi2mcp
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestPolicy_Authorize(t *testing.T) {
+	policy := &Policy{
+		Default:        "deny",
+		IdentityHeader: "X-User",
+		Rules: []PolicyRule{
+			{Effect: "allow", Sessions: []string{"admin-*"}},
+			{Effect: "deny", Operations: []string{"delete*"}, Reason: "deleting is disabled"},
+			{Effect: "deny", Paths: []string{"/internal/**"}},
+			{Effect: "allow", Operations: []string{"updatePet"}, Arguments: map[string]string{"requestBody.status": "available"}},
+			{Effect: "allow", Methods: []string{"GET"}},
+		},
+	}
+	as := func(user string) *mcp.CallToolRequest {
+		return &mcp.CallToolRequest{Extra: &mcp.RequestExtra{Header: http.Header{"X-User": {user}}}}
+	}
+	getPet := OpenAPIOperation{OperationID: "getPet", Path: "/pets/{id}", Method: "get"}
+	deletePet := OpenAPIOperation{OperationID: "deletePet", Path: "/pets/{id}", Method: "delete"}
+	updatePet := OpenAPIOperation{OperationID: "updatePet", Path: "/pets/{id}", Method: "put"}
+	getConfig := OpenAPIOperation{OperationID: "getConfig", Path: "/internal/config", Method: "get"}
+
+	tests := []struct {
+		op   OpenAPIOperation
+		req  *mcp.CallToolRequest
+		args map[string]any
+		want bool
+	}{
+		{getPet, as("bob"), nil, true},
+		{getPet, nil, nil, true},
+		{deletePet, as("bob"), nil, false},
+		{deletePet, as("admin-alice"), nil, true},
+		{getConfig, as("bob"), nil, false},
+		{updatePet, as("bob"), map[string]any{"requestBody": map[string]any{"status": "available"}}, true},
+		{updatePet, as("bob"), map[string]any{"requestBody": map[string]any{"status": "sold"}}, false},
+		{updatePet, as("bob"), map[string]any{}, false},
+	}
+	for _, tc := range tests {
+		if allowed, reason := policy.authorize(tc.op, tc.req, tc.args); allowed != tc.want {
+			t.Errorf("authorize(%s, %v) = %v (%s), want %v", tc.op.OperationID, tc.args, allowed, reason, tc.want)
+		}
+	}
+	if _, reason := policy.authorize(deletePet, as("bob"), nil); reason != "deleting is disabled" {
+		t.Errorf("expected the rule's reason, got %q", reason)
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(file, []byte("default: deny\nrules:\n  - effect: allow\n    methods: [GET]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := LoadPolicy(file)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if policy.Default != "deny" || len(policy.Rules) != 1 || policy.Rules[0].Methods[0] != "GET" {
+		t.Errorf("unexpected policy: %+v", policy)
+	}
+
+	for _, invalid := range []string{"rules:\n  - effect: permit\n", "rules:\n  - effect: deny\n    paths: [\"/pets/[\"]\n", "default: maybe\n"} {
+		if err := os.WriteFile(file, []byte(invalid), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadPolicy(file); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
+
+func TestToolHandler_Policy(t *testing.T) {
+	sent := false
+	op := OpenAPIOperation{OperationID: "deletePet", Path: "/pets/{id}", Method: "delete"}
+	opts := &ToolGenOptions{
+		RequestHandler: func(req *http.Request) (*http.Response, error) {
+			sent = true
+			return fakeResponse(204, "", "")(req)
+		},
+		Policy: &Policy{Rules: []PolicyRule{{Effect: "deny", Methods: []string{"DELETE"}, Reason: "read-only session"}}},
+	}
+	handler := toolHandler("deletePet", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+	res, _, err := handler(context.Background(), nil, map[string]any{"id": 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(t, res); !res.IsError || !strings.Contains(text, "Operation not permitted: read-only session") {
+		t.Errorf("expected policy denial, got: %s", text)
+	}
+	if sent {
+		t.Error("expected no request to be sent")
+	}
+}
@@ -1,10 +1,14 @@
 package openapi2mcp
 
 import (
+	"fmt"
+	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 func TestPostProcessSchema_Integration(t *testing.T) {
@@ -88,3 +92,38 @@ func TestPostProcessSchema_TypesIntegrity(t *testing.T) {
 		t.Error("Function should return the schema")
 	}
 }
+
+// TestPostProcessSchema_CalledSerially registers many tools with a hook that isn't safe for concurrent use;
+// run with -race to check that registration calls it one tool at a time.
+func TestPostProcessSchema_CalledSerially(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4)) // schemas are built concurrently even on a single CPU
+	var spec strings.Builder
+	spec.WriteString("openapi: 3.0.0\ninfo: {title: Test, version: \"1.0\"}\npaths:\n")
+	for i := range 64 {
+		fmt.Fprintf(&spec, "  /items%d:\n    get: {operationId: getItems%d, parameters: [{name: q, in: query, schema: {type: string}}], responses: {\"200\": {description: ok}}}\n", i, i)
+	}
+	doc, err := LoadOpenAPISpecFromString(spec.String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	seen := map[string]int{}
+	var order []string
+	opts := &ToolGenOptions{PostProcessSchema: func(toolName string, schema jsonschema.Schema) jsonschema.Schema {
+		seen[toolName]++
+		order = append(order, toolName)
+		schema.Description = fmt.Sprintf("tool %d", len(order))
+		return schema
+	}}
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	RegisterOpenAPITools(server, ExtractOpenAPIOperations(doc), doc, opts)
+
+	if len(seen) != 64 || len(order) != 64 {
+		t.Fatalf("expected the hook to be called once per tool, got %d calls for %d tools", len(order), len(seen))
+	}
+	for name, calls := range seen {
+		if calls != 1 {
+			t.Errorf("expected one call for %s, got %d", name, calls)
+		}
+	}
+}
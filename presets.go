@@ -0,0 +1,195 @@
+// presets.go
+package openapi2mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.yaml.in/yaml/v3"
+)
+
+// presetArgument is the reserved tool argument naming the preset whose arguments fill in a call.
+const presetArgument = "__preset"
+
+// ArgumentPreset is a named set of arguments for a common call of an operation, e.g. the filters of "recent failed
+// orders", so that the model passes the preset's name instead of repeating long argument payloads.
+type ArgumentPreset struct {
+	Description string         `json:"description,omitempty" yaml:"description,omitempty"`
+	Arguments   map[string]any `json:"arguments" yaml:"arguments"`
+}
+
+// ArgumentPresets maps operationIds to their presets, by name.
+type ArgumentPresets map[string]map[string]ArgumentPreset
+
+// LoadArgumentPresets reads argument presets from a YAML or JSON file:
+//
+//	listOrders:
+//	  recent_failed:
+//	    description: Failed orders of the last 7 days, newest first
+//	    arguments: {status: failed, created_after: 7 days ago, sort: -created}
+//
+// Example usage for LoadArgumentPresets:
+//
+//	presets, err := openapi2mcp.LoadArgumentPresets("presets.yaml")
+//	if err != nil { log.Fatal(err) }
+//	opts.Presets = presets
+func LoadArgumentPresets(path string) (ArgumentPresets, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var presets ArgumentPresets
+	if err := yaml.Unmarshal(data, &presets); err != nil {
+		return nil, fmt.Errorf("invalid presets %s: %w", path, err)
+	}
+	for _, opID := range slices.Sorted(maps.Keys(presets)) {
+		for _, name := range slices.Sorted(maps.Keys(presets[opID])) {
+			if name == "" || strings.ContainsAny(name, " \t\n") {
+				return nil, fmt.Errorf("invalid presets %s: %s: invalid preset name %q", path, opID, name)
+			}
+			if len(presets[opID][name].Arguments) == 0 {
+				return nil, fmt.Errorf("invalid presets %s: %s.%s: arguments are missing", path, opID, name)
+			}
+		}
+	}
+	return presets, nil
+}
+
+// Unknown returns the operationIds of the presets that match none of ops, e.g. because of typos.
+func (p ArgumentPresets) Unknown(ops []OpenAPIOperation) []string {
+	known := make(map[string]bool, len(ops))
+	for _, op := range ops {
+		known[op.OperationID] = true
+	}
+	var unknown []string
+	for _, opID := range slices.Sorted(maps.Keys(p)) {
+		if !known[opID] {
+			unknown = append(unknown, opID)
+		}
+	}
+	return unknown
+}
+
+// presetArgumentSchema describes the __preset argument of tools with presets.
+func presetArgumentSchema(presets map[string]ArgumentPreset) *jsonschema.Schema {
+	names := slices.Sorted(maps.Keys(presets))
+	enum := make([]any, len(names))
+	for i, name := range names {
+		enum[i] = name
+	}
+	return &jsonschema.Schema{
+		Type:        "string",
+		Enum:        enum,
+		Description: "Optional name of a preset filling in the arguments of a common call (see PRESETS in the tool description); arguments passed along override the preset's.",
+	}
+}
+
+// describePresets lists the operation's presets and their arguments in the tool description.
+func describePresets(presets map[string]ArgumentPreset) string {
+	if len(presets) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\n\nPRESETS: Pass __preset instead of repeating the arguments of these common calls:")
+	for _, name := range slices.Sorted(maps.Keys(presets)) {
+		preset := presets[name]
+		args, _ := json.Marshal(preset.Arguments)
+		fmt.Fprintf(&sb, "\n- %s", name)
+		if preset.Description != "" {
+			fmt.Fprintf(&sb, ": %s", strings.TrimSuffix(preset.Description, "."))
+		}
+		fmt.Fprintf(&sb, " %s", args)
+	}
+	return sb.String()
+}
+
+// unknownPresetArguments returns the preset arguments, as "preset.argument", missing from the tool's input schema.
+func unknownPresetArguments(presets map[string]ArgumentPreset, schema jsonschema.Schema) []string {
+	var unknown []string
+	for _, name := range slices.Sorted(maps.Keys(presets)) {
+		for _, arg := range slices.Sorted(maps.Keys(presets[name].Arguments)) {
+			if _, ok := schema.Properties[arg]; !ok {
+				unknown = append(unknown, name+"."+arg)
+			}
+		}
+	}
+	return unknown
+}
+
+// applyPreset returns args with the arguments of the preset named by __preset filled in, and __preset removed.
+// Arguments of the call take precedence, with object arguments merged field by field. It returns args unchanged if
+// no preset is named, and false if the named preset doesn't exist.
+func applyPreset(presets map[string]ArgumentPreset, args map[string]any) (map[string]any, bool) {
+	name, named := args[presetArgument]
+	if !named {
+		return args, true
+	}
+	s, _ := name.(string)
+	preset, ok := presets[s]
+	if !ok {
+		return args, false
+	}
+	explicit := maps.Clone(args)
+	delete(explicit, presetArgument)
+	return mergeArguments(preset.Arguments, explicit), true
+}
+
+// mergeArguments returns a copy of base with the values of override replacing its own, merging objects.
+func mergeArguments(base, override map[string]any) map[string]any {
+	merged := make(map[string]any, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = copyArgument(v)
+	}
+	for k, v := range override {
+		baseObj, baseIsObj := merged[k].(map[string]any)
+		obj, isObj := v.(map[string]any)
+		if baseIsObj && isObj {
+			merged[k] = mergeArguments(baseObj, obj)
+			continue
+		}
+		merged[k] = v
+	}
+	return merged
+}
+
+// copyArgument deep-copies the objects and arrays of a preset argument, so that calls never modify the preset.
+func copyArgument(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		return mergeArguments(v, nil)
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = copyArgument(item)
+		}
+		return items
+	}
+	return v
+}
+
+// unknownPresetResult explains a call naming a preset the operation doesn't have.
+func unknownPresetResult(op OpenAPIOperation, presets map[string]ArgumentPreset, name any, callID string, format string) *mcp.CallToolResult {
+	names := slices.Sorted(maps.Keys(presets))
+	available := strings.Join(names, ", ")
+	if available == "" {
+		available = "none"
+	}
+	text := fmt.Sprintf("Unknown preset %v: this operation's presets are %s.\nRetry with one of them, or leave __preset out and pass the arguments yourself.\nOperation: %s", name, available, op.OperationID)
+	if callID != "" {
+		text += "\nCall ID: " + callID
+	}
+	toolErr := &ToolError{
+		Code:        "unknown_preset",
+		Message:     fmt.Sprintf("unknown preset %v", name),
+		FieldErrors: []FieldError{{Field: presetArgument, Message: "unknown preset", Hint: "one of: " + available}},
+		Operation:   op.OperationID,
+		CallID:      callID,
+	}
+	return toolErrorResult(text, toolErr, format)
+}
@@ -0,0 +1,124 @@
+package openapi2mcp
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const presetsSpec = `openapi: 3.0.0
+info: {title: Shop, version: "1.0"}
+paths:
+  /orders:
+    get:
+      operationId: listOrders
+      parameters:
+        - {name: status, in: query, required: true, schema: {type: string}}
+        - {name: sort, in: query, schema: {type: string}}
+      responses: {"200": {description: OK}}
+`
+
+func TestLoadArgumentPresets(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "presets.yaml")
+	os.WriteFile(valid, []byte("listOrders:\n  recent_failed:\n    description: Failed orders\n    arguments: {status: failed}\n"), 0o600)
+	presets, err := LoadArgumentPresets(valid)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if presets["listOrders"]["recent_failed"].Arguments["status"] != "failed" {
+		t.Errorf("unexpected presets: %+v", presets)
+	}
+	if unknown := presets.Unknown([]OpenAPIOperation{{OperationID: "getOrder"}}); !reflect.DeepEqual(unknown, []string{"listOrders"}) {
+		t.Errorf("expected listOrders to be unknown, got %v", unknown)
+	}
+
+	invalid := filepath.Join(dir, "invalid.yaml")
+	os.WriteFile(invalid, []byte("listOrders:\n  recent_failed:\n    description: Failed orders\n"), 0o600)
+	if _, err := LoadArgumentPresets(invalid); err == nil {
+		t.Error("expected an error for a preset without arguments")
+	}
+}
+
+func TestApplyPreset(t *testing.T) {
+	presets := map[string]ArgumentPreset{
+		"eu": {Arguments: map[string]any{"region": "eu", "requestBody": map[string]any{"tier": "gold", "tags": []any{"a"}}}},
+	}
+	args, ok := applyPreset(presets, map[string]any{"__preset": "eu", "region": "us", "requestBody": map[string]any{"name": "x"}})
+	want := map[string]any{"region": "us", "requestBody": map[string]any{"tier": "gold", "tags": []any{"a"}, "name": "x"}}
+	if !ok || !reflect.DeepEqual(args, want) {
+		t.Errorf("expected %v, got %v", want, args)
+	}
+	args["requestBody"].(map[string]any)["tier"] = "silver"
+	if presets["eu"].Arguments["requestBody"].(map[string]any)["tier"] != "gold" {
+		t.Error("expected the preset to be left unchanged")
+	}
+	if _, ok := applyPreset(presets, map[string]any{"__preset": "us"}); ok {
+		t.Error("expected an unknown preset to be reported")
+	}
+	if args, ok := applyPreset(presets, map[string]any{"region": "us"}); !ok || len(args) != 1 {
+		t.Errorf("expected arguments without a preset to be unchanged, got %v", args)
+	}
+}
+
+func TestRegisterOpenAPITools_Presets(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(presetsSpec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var sent []string
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	RegisterOpenAPITools(server, ExtractOpenAPIOperations(doc), doc, &ToolGenOptions{
+		BaseURL: "http://example.com",
+		Presets: ArgumentPresets{"listOrders": {
+			"recent_failed": {Description: "Failed orders, newest first", Arguments: map[string]any{"status": "failed", "sort": "-created"}},
+		}},
+		RequestHandler: func(req *http.Request) (*http.Response, error) {
+			sent = append(sent, req.URL.RawQuery)
+			return fakeResponse(200, "application/json", `[]`)(req)
+		},
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(context.Background(), serverTransport, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil).Connect(context.Background(), clientTransport, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cs.Close()
+
+	tools, err := cs.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, tool := range tools.Tools {
+		if tool.Name == "listOrders" && !strings.Contains(tool.Description, `- recent_failed: Failed orders, newest first {"sort":"-created","status":"failed"}`) {
+			t.Errorf("expected the preset in the description, got %q", tool.Description)
+		}
+	}
+
+	// The preset fills in the required status, and the call's own arguments override the preset's
+	res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "listOrders", Arguments: map[string]any{"__preset": "recent_failed", "sort": "total"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.IsError || len(sent) != 1 || sent[0] != "sort=total&status=failed" {
+		t.Errorf("expected the preset's arguments to be sent, got %v: %s", sent, resultText(t, res))
+	}
+
+	res, err = cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "listOrders", Arguments: map[string]any{"__preset": "recent"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(t, res); !res.IsError || !strings.Contains(text, "Unknown preset recent: this operation's presets are recent_failed.") {
+		t.Errorf("expected an unknown preset error, got: %s", text)
+	}
+}
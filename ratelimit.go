@@ -0,0 +1,276 @@
+// ratelimit.go
+package openapi2mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxPacingDelay caps the delay of a call nearing a rate limit; calls are slowed down, never held back for long.
+const maxPacingDelay = 2 * time.Second
+
+// rateLimitURIPrefix is the URI prefix of the rate limit resources.
+const rateLimitURIPrefix = "ratelimit://"
+
+// rateLimitSpec is a rate limit documented with the x-ratelimit (or x-rateLimit) extension of the operation
+// or document, e.g. {limit: 100, period: 60} or "x-ratelimit-limit: 100" with "x-ratelimit-period: 1m".
+type rateLimitSpec struct {
+	Limit  int
+	Period time.Duration
+}
+
+// specRateLimit returns the rate limit documented for the operation, else for the document, or nil.
+func specRateLimit(op OpenAPIOperation, doc *openapi3.T) *rateLimitSpec {
+	if doc == nil {
+		return nil
+	}
+	if doc.Paths != nil {
+		if item := doc.Paths.Value(op.Path); item != nil {
+			if o := item.GetOperation(strings.ToUpper(op.Method)); o != nil {
+				if spec := parseRateLimitExtensions(o.Extensions); spec != nil {
+					return spec
+				}
+			}
+		}
+	}
+	return parseRateLimitExtensions(doc.Extensions)
+}
+
+// parseRateLimitExtensions reads the rate limit extensions, matching their names case-insensitively.
+// The period defaults to a minute; it is given in seconds or as a duration ("1m").
+func parseRateLimitExtensions(extensions map[string]any) *rateLimitSpec {
+	var limit, period any
+	for name, value := range extensions {
+		switch strings.ToLower(name) {
+		case "x-ratelimit":
+			if m, ok := value.(map[string]any); ok {
+				for k, v := range m {
+					switch strings.ToLower(k) {
+					case "limit":
+						limit = v
+					case "period", "window", "interval":
+						period = v
+					}
+				}
+			} else {
+				limit = value
+			}
+		case "x-ratelimit-limit":
+			limit = value
+		case "x-ratelimit-period", "x-ratelimit-window":
+			period = value
+		}
+	}
+	n, ok := extensionNumber(limit)
+	if !ok || n < 1 {
+		return nil
+	}
+	spec := &rateLimitSpec{Limit: int(n), Period: time.Minute}
+	if s, ok := period.(string); ok {
+		if d, err := time.ParseDuration(s); err == nil && d > 0 {
+			spec.Period = d
+		}
+	} else if secs, ok := extensionNumber(period); ok && secs > 0 {
+		spec.Period = time.Duration(secs * float64(time.Second))
+	}
+	return spec
+}
+
+// extensionNumber returns the numeric value of an extension, which may be a number or a numeric string.
+func extensionNumber(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// RateLimitStatus is the known rate limit budget of an API host, as shown by the ratelimit://{host} resource template
+// and, for all hosts, the ratelimit://status resource.
+type RateLimitStatus struct {
+	Host      string    `json:"host"`
+	Limit     int       `json:"limit,omitempty"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset,omitzero"`
+	Source    string    `json:"source"` // "headers" if reported by the API, "spec" if counted from the documented limit
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// rateLimiter tracks the rate limit budget of each API host. It is safe for concurrent use.
+type rateLimiter struct {
+	mu    sync.Mutex
+	hosts map[string]*RateLimitStatus
+	now   func() time.Time
+}
+
+// newRateLimiter creates a rate limiter without known budgets.
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{hosts: make(map[string]*RateLimitStatus), now: time.Now}
+}
+
+// acquire accounts for a call to host and returns how long to delay it: if the budget is nearly used up, the
+// remaining calls are spread over the time until it resets, by at most maxPacingDelay each. If the API hasn't
+// reported its budget, the documented limit (if any) is counted locally.
+func (l *rateLimiter) acquire(host string, spec *rateLimitSpec) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	status := l.hosts[host]
+	if spec != nil && (status == nil || status.Source == "spec" && !now.Before(status.Reset)) {
+		status = &RateLimitStatus{Host: host, Limit: spec.Limit, Remaining: spec.Limit, Reset: now.Add(spec.Period), Source: "spec"}
+		l.hosts[host] = status
+	}
+	if status == nil || status.Limit <= 0 || !status.Reset.After(now) {
+		return 0
+	}
+	var delay time.Duration
+	if status.Remaining <= max(status.Limit/10, 1) {
+		delay = min(status.Reset.Sub(now)/time.Duration(max(status.Remaining, 0)+1), maxPacingDelay)
+	}
+	if status.Source == "spec" {
+		status.Remaining--
+		status.UpdatedAt = now
+	}
+	return delay
+}
+
+// observe updates the budget of host from the rate limit headers of a response: X-RateLimit-Limit/-Remaining/-Reset,
+// their IETF RateLimit-* equivalents, and Retry-After of a 429 response.
+func (l *rateLimiter) observe(host string, resp *http.Response) {
+	header := resp.Header
+	get := func(name string) (int, bool) {
+		for _, prefix := range []string{"X-RateLimit-", "RateLimit-", "X-Rate-Limit-"} {
+			if v := strings.TrimSpace(header.Get(prefix + name)); v != "" {
+				// Values may carry parameters, e.g. "100, 100;w=60"
+				v, _, _ = strings.Cut(v, ",")
+				v, _, _ = strings.Cut(v, ";")
+				if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+					return n, true
+				}
+			}
+		}
+		return 0, false
+	}
+	remaining, hasRemaining := get("Remaining")
+	retryAfter := 0
+	if resp.StatusCode == http.StatusTooManyRequests {
+		retryAfter = parseRetryAfter(header.Get("Retry-After"))
+	}
+	if !hasRemaining && retryAfter == 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	status := &RateLimitStatus{Host: host, Source: "headers", UpdatedAt: now}
+	if limit, ok := get("Limit"); ok {
+		status.Limit = limit
+	}
+	status.Remaining = remaining
+	if reset, ok := get("Reset"); ok {
+		// Seconds until the reset, or a Unix timestamp
+		if reset > 1_000_000_000 {
+			status.Reset = time.Unix(int64(reset), 0)
+		} else {
+			status.Reset = now.Add(time.Duration(reset) * time.Second)
+		}
+	}
+	if retryAfter > 0 {
+		status.Remaining = 0
+		status.Reset = now.Add(time.Duration(retryAfter) * time.Second)
+		if status.Limit == 0 {
+			if previous := l.hosts[host]; previous != nil {
+				status.Limit = previous.Limit
+			}
+		}
+	}
+	l.hosts[host] = status
+}
+
+// snapshot returns the known budgets, sorted by host.
+func (l *rateLimiter) snapshot() []RateLimitStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	statuses := make([]RateLimitStatus, 0, len(l.hosts))
+	for _, host := range slices.Sorted(maps.Keys(l.hosts)) {
+		statuses = append(statuses, *l.hosts[host])
+	}
+	return statuses
+}
+
+// status returns the known budget of host.
+func (l *rateLimiter) status(host string) (RateLimitStatus, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	status, ok := l.hosts[host]
+	if !ok {
+		return RateLimitStatus{}, false
+	}
+	return *status, true
+}
+
+// rateLimitWarning warns the model that the budget of a host is at or below percent of its limit, so that it slows
+// down before calls fail. It returns "" if there's enough budget left, the limit is unknown, or percent is 0.
+func rateLimitWarning(status RateLimitStatus, percent float64, now time.Time) string {
+	if percent <= 0 || status.Limit <= 0 || float64(status.Remaining)*100 > percent*float64(status.Limit) {
+		return ""
+	}
+	reset := ""
+	if status.Reset.After(now) {
+		reset = fmt.Sprintf(", resetting in %s", status.Reset.Sub(now).Round(time.Second))
+	}
+	return fmt.Sprintf("RATE LIMIT: Only %d of %d calls are left for %s%s. Make fewer calls (filter, batch, or page less) or wait for the reset; %s%s shows the current quota.", max(status.Remaining, 0), status.Limit, status.Host, reset, rateLimitURIPrefix, status.Host)
+}
+
+// registerRateLimitResource adds the ratelimit://status resource showing the remaining quota of each API host, and
+// the ratelimit://{host} resource template showing that of a single one.
+func registerRateLimitResource(server *mcp.Server, limiter *rateLimiter) {
+	resource := &mcp.Resource{
+		URI:         rateLimitURIPrefix + "status",
+		Name:        "Rate Limits",
+		Description: "Remaining rate limit quota of each API host, as reported by its rate limit headers or counted from the limits documented in the spec",
+		MIMEType:    "application/json",
+	}
+	server.AddResource(resource, func(context.Context, *mcp.ServerRequest[*mcp.ReadResourceParams]) (*mcp.ReadResourceResult, error) {
+		text, _ := json.MarshalIndent(limiter.snapshot(), "", "  ")
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{URI: resource.URI, MIMEType: "application/json", Text: string(text)}},
+		}, nil
+	})
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: rateLimitURIPrefix + "{host}",
+		Name:        "Host Rate Limit",
+		Description: "Remaining rate limit quota of one API host (host or host:port, as listed by ratelimit://status), updated with every response",
+		MIMEType:    "application/json",
+	}, func(_ context.Context, req *mcp.ServerRequest[*mcp.ReadResourceParams]) (*mcp.ReadResourceResult, error) {
+		uri := req.Params.URI
+		status, ok := limiter.status(strings.TrimPrefix(uri, rateLimitURIPrefix))
+		if !ok {
+			return nil, mcp.ResourceNotFoundError(uri)
+		}
+		text, _ := json.MarshalIndent(status, "", "  ")
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{URI: uri, MIMEType: "application/json", Text: string(text)}},
+		}, nil
+	})
+}
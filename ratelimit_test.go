@@ -0,0 +1,156 @@
+package openapi2mcp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestParseRateLimitExtensions(t *testing.T) {
+	tests := []struct {
+		extensions map[string]any
+		want       *rateLimitSpec
+	}{
+		{map[string]any{"x-ratelimit": map[string]any{"limit": 100.0, "period": 60.0}}, &rateLimitSpec{100, time.Minute}},
+		{map[string]any{"x-rateLimit": map[string]any{"Limit": "10", "window": "1s"}}, &rateLimitSpec{10, time.Second}},
+		{map[string]any{"x-rateLimit-limit": 5.0, "x-ratelimit-period": "1h"}, &rateLimitSpec{5, time.Hour}},
+		{map[string]any{"x-ratelimit": 30.0}, &rateLimitSpec{30, time.Minute}},
+		{map[string]any{"x-ratelimit": map[string]any{"limit": 0.0}}, nil},
+		{map[string]any{"x-other": 1.0}, nil},
+	}
+	for _, tt := range tests {
+		got := parseRateLimitExtensions(tt.extensions)
+		if (got == nil) != (tt.want == nil) || got != nil && *got != *tt.want {
+			t.Errorf("parseRateLimitExtensions(%v) = %v, want %v", tt.extensions, got, tt.want)
+		}
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := newRateLimiter()
+	limiter.now = func() time.Time { return now }
+
+	// A documented limit is counted locally; calls are only delayed once the budget is nearly used up
+	spec := &rateLimitSpec{Limit: 10, Period: 10 * time.Second}
+	for i := range 9 {
+		if delay := limiter.acquire("api.example.com", spec); delay != 0 {
+			t.Fatalf("call %d: unexpected delay %s", i+1, delay)
+		}
+	}
+	if delay := limiter.acquire("api.example.com", spec); delay != maxPacingDelay {
+		t.Errorf("expected the last call to be delayed, got %s", delay)
+	}
+	now = now.Add(10 * time.Second)
+	if delay := limiter.acquire("api.example.com", spec); delay != 0 {
+		t.Errorf("expected the budget to be renewed, got delay %s", delay)
+	}
+
+	// Budgets reported by the API replace the documented ones
+	limiter.observe("api.example.com", &http.Response{StatusCode: 200, Header: http.Header{
+		"Ratelimit-Limit":     {"100"},
+		"Ratelimit-Remaining": {"4"},
+		"Ratelimit-Reset":     {"5"},
+	}})
+	if delay := limiter.acquire("api.example.com", spec); delay != time.Second {
+		t.Errorf("expected the reported budget to spread the calls until the reset, got %s", delay)
+	}
+	status := limiter.snapshot()[0]
+	if status.Source != "headers" || status.Limit != 100 || status.Remaining != 4 || !status.Reset.Equal(now.Add(5*time.Second)) {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	// A 429 response uses up the budget until Retry-After
+	limiter.observe("api.example.com", &http.Response{StatusCode: 429, Header: http.Header{"Retry-After": {"60"}}})
+	if delay := limiter.acquire("api.example.com", nil); delay != maxPacingDelay {
+		t.Errorf("expected the maximum delay, got %s", delay)
+	}
+	if status := limiter.snapshot()[0]; status.Remaining != 0 || status.Limit != 100 {
+		t.Errorf("unexpected status: %+v", status)
+	}
+}
+
+func TestRegisterOpenAPITools_RateLimitResource(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+servers: [{url: "https://api.example.com"}]
+x-ratelimit: {limit: 1000, period: 3600}
+paths:
+  /pets:
+    get: {operationId: listPets, responses: {"200": {description: ok}}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	RegisterOpenAPITools(server, ExtractOpenAPIOperations(doc), doc, &ToolGenOptions{
+		RateLimitWarning: 99,
+		RequestHandler: func(req *http.Request) (*http.Response, error) {
+			header := http.Header{"Content-Type": {"application/json"}, "X-Ratelimit-Limit": {"60"}, "X-Ratelimit-Remaining": {"59"}}
+			return &http.Response{StatusCode: 200, Header: header, Body: io.NopCloser(strings.NewReader(`[]`)), Request: req}, nil
+		},
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(context.Background(), serverTransport, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil).Connect(context.Background(), clientTransport, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cs.Close()
+
+	call, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "listPets", Arguments: map[string]any{}})
+	if err != nil || call.IsError {
+		t.Fatalf("unexpected error: %v %v", err, call)
+	}
+	if note := call.Content[len(call.Content)-1].(*mcp.TextContent).Text; !strings.HasPrefix(note, "RATE LIMIT: Only 59 of 60 calls are left for api.example.com.") {
+		t.Errorf("expected a warning below the threshold, got %q", note)
+	}
+	res, err := cs.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "ratelimit://status"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var statuses []RateLimitStatus
+	if err := json.Unmarshal([]byte(res.Contents[0].Text), &statuses); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(statuses) != 1 || statuses[0].Host != "api.example.com" || statuses[0].Source != "headers" || statuses[0].Limit != 60 || statuses[0].Remaining != 59 {
+		t.Errorf("expected the reported budget, got %+v", statuses)
+	}
+
+	res, err = cs.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "ratelimit://api.example.com"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var status RateLimitStatus
+	if err := json.Unmarshal([]byte(res.Contents[0].Text), &status); err != nil || status.Remaining != 59 {
+		t.Errorf("expected the budget of the host, got %s", res.Contents[0].Text)
+	}
+	if _, err := cs.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "ratelimit://other.example.com"}); err == nil {
+		t.Error("expected an error for a host without a known budget")
+	}
+}
+
+func TestRateLimitWarning(t *testing.T) {
+	now := time.Now()
+	status := RateLimitStatus{Host: "api.example.com", Limit: 100, Remaining: 5, Reset: now.Add(90 * time.Second)}
+	if warning := rateLimitWarning(status, 10, now); !strings.Contains(warning, "Only 5 of 100 calls are left for api.example.com, resetting in 1m30s.") {
+		t.Errorf("unexpected warning: %q", warning)
+	}
+	for _, percent := range []float64{0, 4} {
+		if warning := rateLimitWarning(status, percent, now); warning != "" {
+			t.Errorf("expected no warning at %v%%, got %q", percent, warning)
+		}
+	}
+	if warning := rateLimitWarning(RateLimitStatus{Remaining: 0}, 10, now); warning != "" {
+		t.Errorf("expected no warning without a known limit, got %q", warning)
+	}
+}
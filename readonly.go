@@ -0,0 +1,83 @@
+// readonly.go
+package openapi2mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ReadOnlySwitch is a safety brake that can be pulled while the server runs, e.g. during an incident: while it is
+// on, calls of operations other than GET, HEAD, OPTIONS, and TRACE are rejected before they reach the API, whichever
+// tools are registered. It is safe for concurrent use.
+type ReadOnlySwitch struct {
+	mu      sync.Mutex
+	on      bool
+	reason  string
+	changed time.Time
+}
+
+// NewReadOnlySwitch creates a switch that is on if on is true.
+func NewReadOnlySwitch(on bool) *ReadOnlySwitch {
+	return &ReadOnlySwitch{on: on, changed: time.Now()}
+}
+
+// Set turns the switch on or off; reason, if any, is shown to the model with rejected calls.
+func (s *ReadOnlySwitch) Set(on bool, reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.on, s.reason, s.changed = on, reason, time.Now()
+}
+
+// Enabled reports whether the switch is on, and why.
+func (s *ReadOnlySwitch) Enabled() (bool, string) {
+	if s == nil {
+		return false, ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.on, s.reason
+}
+
+// readOnlyState is the JSON state of a ReadOnlySwitch served by its HTTP handler.
+type readOnlyState struct {
+	ReadOnly bool      `json:"read_only"`
+	Reason   string    `json:"reason,omitempty"`
+	Changed  time.Time `json:"changed"`
+}
+
+// ServeHTTP answers GET with the state of the switch as JSON and turns it on or off on POST or PUT with
+// ?enabled=true or false (and an optional reason), answering with the new state. It must only be served to
+// operators, e.g. on a separate admin address.
+func (s *ReadOnlySwitch) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+	case http.MethodPost, http.MethodPut:
+		on, err := strconv.ParseBool(req.URL.Query().Get("enabled"))
+		if err != nil {
+			http.Error(w, "enabled must be true or false", http.StatusBadRequest)
+			return
+		}
+		s.Set(on, req.URL.Query().Get("reason"))
+	default:
+		w.Header().Set("Allow", "GET, POST, PUT")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.mu.Lock()
+	state := readOnlyState{ReadOnly: s.on, Reason: s.reason, Changed: s.changed}
+	s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(state)
+}
+
+// readOnlyText explains a call that was rejected because the server is read-only.
+func readOnlyText(reason, method, operationID, callID string) string {
+	if reason == "" {
+		reason = "the operator switched the server to read-only"
+	}
+	return fmt.Sprintf("Server is read-only: %s.\n%s calls are blocked until the operator lifts it; only reading operations (GET) work. Don't retry this call or work around it with other operations; tell the user instead.\nOperation: %s\nCall ID: %s", reason, method, operationID, callID)
+}
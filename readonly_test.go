@@ -0,0 +1,77 @@
+package openapi2mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestToolHandler_ReadOnly(t *testing.T) {
+	var sent []string
+	readOnly := NewReadOnlySwitch(true)
+	opts := &ToolGenOptions{
+		ReadOnly:    readOnly,
+		ErrorFormat: ErrorFormatJSON,
+		RequestHandler: func(req *http.Request) (*http.Response, error) {
+			sent = append(sent, req.Method)
+			return fakeResponse(200, "application/json", `{}`)(req)
+		},
+	}
+	rt := newServerRuntime()
+	create := toolHandler("createPet", OpenAPIOperation{OperationID: "createPet", Path: "/pets", Method: "post"}, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, rt)
+	list := toolHandler("listPets", OpenAPIOperation{OperationID: "listPets", Path: "/pets", Method: "get"}, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, rt)
+
+	res, _, err := create(context.Background(), nil, map[string]any{"__confirmed": true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(t, res); !res.IsError || !strings.Contains(text, `"code": "read_only"`) {
+		t.Errorf("expected a read_only error, got: %s", text)
+	}
+	if _, _, err := list(context.Background(), nil, map[string]any{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Lifting read-only mode at runtime lets the call through
+	readOnly.Set(false, "")
+	if res, _, _ := create(context.Background(), nil, map[string]any{"__confirmed": true}); res.IsError {
+		t.Errorf("expected the call to succeed, got: %s", resultText(t, res))
+	}
+	if strings.Join(sent, ",") != "GET,POST" {
+		t.Errorf("expected only the GET call and the later POST call to be sent, got %v", sent)
+	}
+}
+
+func TestReadOnlySwitch_ServeHTTP(t *testing.T) {
+	readOnly := NewReadOnlySwitch(false)
+	srv := httptest.NewServer(readOnly)
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"?enabled=true&reason=incident+42", "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var state readOnlyState
+	json.NewDecoder(resp.Body).Decode(&state)
+	resp.Body.Close()
+	if !state.ReadOnly || state.Reason != "incident 42" {
+		t.Errorf("expected the switch to be on, got %+v", state)
+	}
+	if on, reason := readOnly.Enabled(); !on || reason != "incident 42" {
+		t.Errorf("expected the switch to be on, got %v %q", on, reason)
+	}
+
+	resp, err = http.Post(srv.URL+"?enabled=maybe", "", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid value, got %d", resp.StatusCode)
+	}
+}
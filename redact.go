@@ -0,0 +1,163 @@
+// redact.go
+package openapi2mcp
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// redactedValue replaces masked values in logs and audit records.
+const redactedValue = "[REDACTED]"
+
+// RedactionRules configures which values are masked in HTTP logs and MCP traffic (audit) records,
+// in addition to the built-in Authorization/Cookie headers and credential-like JSON keys.
+//
+// JSONPaths use a small JSONPath subset: "$.card.number", "$.items[*].token", "$.cards[0].cvv", "$.*.secret".
+// For HTTP logs paths are applied to request and response bodies; for MCP records they are applied to
+// the tool arguments and to the requestBody argument.
+type RedactionRules struct {
+	Headers   []string // Additional header names to redact (case-insensitive)
+	JSONPaths []string // JSON body paths to redact
+}
+
+// sensitiveKeys lists lower-cased JSON keys whose values are always redacted from logs.
+var sensitiveKeys = []string{"password", "passwd", "secret", "token", "api_key", "apikey", "authorization", "cookie", "client_secret"}
+
+// isSensitiveKey reports whether a JSON key looks like it holds a credential.
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range sensitiveKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactJSONValue returns a copy of v with the values of sensitive keys replaced.
+func redactJSONValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(val))
+		for k, item := range val {
+			if isSensitiveKey(k) {
+				out[k] = redactedValue
+			} else {
+				out[k] = redactJSONValue(item)
+			}
+		}
+		return out
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = redactJSONValue(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// redactsHeader reports whether the named header must be redacted.
+func (r *RedactionRules) redactsHeader(name string) bool {
+	switch strings.ToLower(name) {
+	case "authorization", "cookie", "set-cookie", "proxy-authorization":
+		return true
+	}
+	if r == nil {
+		return false
+	}
+	for _, h := range r.Headers {
+		if strings.EqualFold(h, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactJSON applies the configured JSON paths to v in place and returns it.
+func (r *RedactionRules) redactJSON(v any) any {
+	if r == nil {
+		return v
+	}
+	for _, p := range r.JSONPaths {
+		v = redactJSONPath(v, parseJSONPath(p))
+	}
+	return v
+}
+
+// redactBody redacts the configured JSON paths and credential-like keys from a JSON body.
+// Non-JSON bodies are returned unchanged.
+func (r *RedactionRules) redactBody(body []byte) []byte {
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return body
+	}
+	out, err := json.Marshal(r.redactJSON(redactJSONValue(v)))
+	if err != nil {
+		return body
+	}
+	return out
+}
+
+// parseJSONPath splits a path like "$.items[*].card.number" into segments ["items", "*", "card", "number"].
+func parseJSONPath(path string) []string {
+	path = strings.TrimPrefix(strings.TrimSpace(path), "$")
+	path = strings.ReplaceAll(path, "[", ".")
+	path = strings.ReplaceAll(path, "]", "")
+	var segments []string
+	for _, s := range strings.Split(path, ".") {
+		s = strings.Trim(s, `'"`)
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+	return segments
+}
+
+// redactJSONPath replaces the value(s) addressed by segments with redactedValue.
+func redactJSONPath(v any, segments []string) any {
+	if len(segments) == 0 {
+		return redactedValue
+	}
+	seg, rest := segments[0], segments[1:]
+	switch val := v.(type) {
+	case map[string]any:
+		for k, item := range val {
+			if seg == "*" || seg == k {
+				val[k] = redactJSONPath(item, rest)
+			}
+		}
+	case []any:
+		if seg == "*" {
+			for i, item := range val {
+				val[i] = redactJSONPath(item, rest)
+			}
+		} else if i, err := strconv.Atoi(seg); err == nil && i >= 0 && i < len(val) {
+			val[i] = redactJSONPath(val[i], rest)
+		}
+	}
+	return v
+}
+
+// redactToolArguments applies the JSON paths to the arguments of a tools/call request
+// (both to the arguments object and to its requestBody), returning the modified value.
+func (r *RedactionRules) redactToolArguments(v any) any {
+	if r == nil {
+		return v
+	}
+	params, ok := v.(map[string]any)
+	if !ok {
+		return v
+	}
+	args, ok := params["arguments"].(map[string]any)
+	if !ok {
+		return v
+	}
+	r.redactJSON(args)
+	if body, ok := args["requestBody"]; ok {
+		args["requestBody"] = r.redactJSON(body)
+	}
+	return v
+}
@@ -0,0 +1,81 @@
+// redirect.go
+package openapi2mcp
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// DefaultMaxRedirects is the number of redirects followed when RedirectPolicy.MaxRedirects is 0.
+const DefaultMaxRedirects = 10
+
+// RedirectPolicy configures how redirects of upstream responses are handled.
+// Credentials are never sent to a host other than the one of the original request.
+type RedirectPolicy struct {
+	Disabled     bool // if true, redirects are not followed: the 3xx response is returned with its Location
+	MaxRedirects int  // maximum number of redirects followed; 0 means DefaultMaxRedirects
+}
+
+// newUpstreamClient returns the HTTP client sending the requests of tools, applying opts.Redirects,
+// the host policy of guard (which may be nil), and removing credentials from cross-host redirects.
+func newUpstreamClient(guard *hostGuard, doc *openapi3.T, opts *ToolGenOptions) *http.Client {
+	policy := opts.Redirects
+	if policy == nil {
+		policy = &RedirectPolicy{}
+	}
+	maxRedirects := DefaultMaxRedirects
+	if policy.MaxRedirects > 0 {
+		maxRedirects = policy.MaxRedirects
+	}
+	credentials := credentialHeaders(doc, opts)
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if policy.Disabled {
+				return http.ErrUseLastResponse
+			}
+			if len(via) > maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			if guard != nil {
+				if err := guard.checkURL(req.URL); err != nil {
+					return err
+				}
+			}
+			if !strings.EqualFold(req.URL.Host, via[0].URL.Host) {
+				for _, name := range credentials {
+					req.Header.Del(name)
+				}
+			}
+			return nil
+		},
+	}
+	if guard != nil {
+		client.Transport = guard
+	}
+	return client
+}
+
+// credentialHeaders returns the names of the request headers that may carry credentials: the standard
+// ones, those of the spec's apiKey schemes, the fallback API key header, and forwarded headers.
+func credentialHeaders(doc *openapi3.T, opts *ToolGenOptions) []string {
+	names := []string{"Authorization", "Cookie", "Proxy-Authorization", opts.APIKeyHeader, os.Getenv("API_KEY_HEADER")}
+	names = append(names, opts.ForwardHeaders...)
+	if doc != nil && doc.Components != nil {
+		for _, ref := range doc.Components.SecuritySchemes {
+			if ref != nil && ref.Value != nil && ref.Value.Type == "apiKey" && ref.Value.In == "header" {
+				names = append(names, ref.Value.Name)
+			}
+		}
+	}
+	return names
+}
+
+// formatRedirectResult renders a 3xx response that was not followed, pointing the model to its Location.
+func formatRedirectResult(method, fullURL string, status int, callID, location, headers string) string {
+	return fmt.Sprintf("HTTP %s %s\nStatus: %d\nCall ID: %s\n%sRedirect not followed: the resource is at %s", method, fullURL, status, callID, headers, location)
+}
@@ -0,0 +1,66 @@
+package openapi2mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestToolHandler_Redirects(t *testing.T) {
+	var received http.Header
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer other.Close()
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/loop":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		case "/same":
+			http.Redirect(w, r, "/final", http.StatusFound)
+		case "/final":
+			received = r.Header.Clone()
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"ok":true}`))
+		default:
+			http.Redirect(w, r, other.URL+"/elsewhere", http.StatusFound)
+		}
+	}))
+	defer upstream.Close()
+
+	doc := minimalOpenAPIDoc()
+	call := func(path string, redirects *RedirectPolicy) (string, error) {
+		op := OpenAPIOperation{OperationID: "get", Path: path, Method: "get"}
+		opts := &ToolGenOptions{Redirects: redirects, HostPolicy: &HostPolicy{Disabled: true}}
+		t.Setenv("BEARER_TOKEN", "secret")
+		handler := toolHandler("get", op, doc, jsonschema.Schema{}, []string{upstream.URL}, opts, nil)
+		res, _, err := handler(context.Background(), nil, map[string]any{})
+		if err != nil {
+			return "", err
+		}
+		return resultText(t, res), nil
+	}
+
+	// Credentials are kept on the same host and dropped on another one
+	if _, err := call("/same", nil); err != nil || received.Get("Authorization") != "Bearer secret" {
+		t.Errorf("expected credentials on a same-host redirect, got %v, %v", received, err)
+	}
+	if text, err := call("/cross", nil); err != nil || !strings.Contains(text, `"ok"`) || received.Get("Authorization") != "" {
+		t.Errorf("expected no credentials on a cross-host redirect, got %q, %v, %v", text, received, err)
+	}
+
+	if _, err := call("/loop", &RedirectPolicy{MaxRedirects: 2}); err == nil || !strings.Contains(err.Error(), "stopped after 2 redirects") {
+		t.Errorf("expected the redirect limit to apply, got %v", err)
+	}
+
+	text, err := call("/cross", &RedirectPolicy{Disabled: true})
+	if err != nil || !strings.Contains(text, "Status: 302") || !strings.Contains(text, "Redirect not followed: the resource is at "+other.URL+"/elsewhere") {
+		t.Errorf("expected the redirect's location, got %q, %v", text, err)
+	}
+}
@@ -5,10 +5,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"net/url"
 	"os"
+	"runtime"
 	"slices"
 	"strings"
-	"time"
+	"sync"
+	"sync/atomic"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/google/jsonschema-go/jsonschema"
@@ -52,9 +56,106 @@ func formatParameterValue(val any, isInteger bool) string {
 	return fmt.Sprintf("%v", val)
 }
 
+// escapePathParameter escapes a path parameter value so that it stays within its path segment:
+// slashes, query and fragment delimiters, and dot segments ("." and "..") are percent-encoded.
+func escapePathParameter(value string) string {
+	escaped := url.PathEscape(value)
+	if escaped == "." || escaped == ".." {
+		return strings.ReplaceAll(escaped, ".", "%2E")
+	}
+	return escaped
+}
+
+// escapeCookieValue percent-encodes the bytes not allowed in an RFC 6265 cookie value (and "%" itself),
+// so that a value can't end the cookie early or inject further cookies.
+func escapeCookieValue(value string) string {
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c <= ' ' || c >= 0x7f || c == '"' || c == ',' || c == ';' || c == '\\' || c == '%' {
+			fmt.Fprintf(&sb, "%%%02X", c)
+		} else {
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// parameterValues formats a header or cookie parameter as name/value pairs. Arrays and objects are comma-joined,
+// as in "a,b" or "k1,v1,k2,v2" ("k1=v1,k2=v2" for exploded header objects). Exploded arrays become one pair per
+// item instead, sent as repeated header fields (equal to one comma-joined field per RFC 7230) or repeated cookies,
+// and exploded cookie objects one cookie per property. Cookies are exploded unless the spec says otherwise. escape,
+// if set, is applied to each item, key, and value before they are joined.
+func parameterValues(p *openapi3.Parameter, val any, escape func(string) string) [][2]string {
+	var schema *openapi3.Schema
+	if p.Schema != nil {
+		schema = p.Schema.Value
+	}
+	format := func(v any, s *openapi3.Schema) string {
+		formatted := formatParameterValue(v, s != nil && s.Type != nil && s.Type.Is("integer"))
+		if escape != nil {
+			return escape(formatted)
+		}
+		return formatted
+	}
+	explode := p.In == "cookie"
+	if p.Explode != nil {
+		explode = *p.Explode
+	}
+
+	switch v := val.(type) {
+	case []any:
+		var itemSchema *openapi3.Schema
+		if schema != nil && schema.Items != nil {
+			itemSchema = schema.Items.Value
+		}
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = format(item, itemSchema)
+		}
+		if !explode {
+			return [][2]string{{p.Name, strings.Join(items, ",")}}
+		}
+		pairs := make([][2]string, len(items))
+		for i, item := range items {
+			pairs[i] = [2]string{p.Name, item}
+		}
+		return pairs
+	case map[string]any:
+		var pairs [][2]string
+		var fields []string
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			var propSchema *openapi3.Schema
+			if schema != nil && schema.Properties[key] != nil {
+				propSchema = schema.Properties[key].Value
+			}
+			key, value := format(key, nil), format(v[key], propSchema)
+			switch {
+			case explode && p.In == "cookie":
+				pairs = append(pairs, [2]string{key, value})
+			case explode:
+				fields = append(fields, key+"="+value)
+			default:
+				fields = append(fields, key, value)
+			}
+		}
+		if pairs != nil {
+			return pairs
+		}
+		return [][2]string{{p.Name, strings.Join(fields, ",")}}
+	}
+	return [][2]string{{p.Name, format(val, schema)}}
+}
+
 // generateAIFriendlyDescription creates a comprehensive, AI-optimized description for an operation
 // that includes all the information an AI agent needs to understand how to use the tool.
-func generateAIFriendlyDescription(op OpenAPIOperation, inputSchema jsonschema.Schema) string {
+func generateAIFriendlyDescription(op OpenAPIOperation, inputSchema jsonschema.Schema, gens ExampleGenerators) string {
+	return describeOperation(op, inputSchema, gens, nil)
+}
+
+// describeOperation implements generateAIFriendlyDescription, showing the example arguments instead of generated
+// ones if set.
+func describeOperation(op OpenAPIOperation, inputSchema jsonschema.Schema, gens ExampleGenerators, example map[string]any) string {
 	var desc strings.Builder
 
 	// Start with the original description or summary
@@ -69,7 +170,7 @@ func generateAIFriendlyDescription(op OpenAPIOperation, inputSchema jsonschema.S
 		desc.WriteString("\n\nAUTHENTICATION: ")
 		var authMethods []string
 		for _, secReq := range op.Security {
-			for schemeName := range secReq {
+			for _, schemeName := range slices.Sorted(maps.Keys(secReq)) {
 				authMethods = append(authMethods, schemeName)
 			}
 		}
@@ -110,7 +211,8 @@ func generateAIFriendlyDescription(op OpenAPIOperation, inputSchema jsonschema.S
 
 		// Optional parameters
 		var optionalParams []string
-		for paramName, prop := range properties {
+		for _, paramName := range slices.Sorted(maps.Keys(properties)) {
+			prop := properties[paramName]
 			isRequired := false
 			for _, reqParam := range requiredParams {
 				if reqParam == paramName {
@@ -154,21 +256,25 @@ func generateAIFriendlyDescription(op OpenAPIOperation, inputSchema jsonschema.S
 		// Add required parameters to example
 		for _, reqStr := range requiredParams {
 			if prop, ok := properties[reqStr]; ok && prop != nil {
-				exampleArgs[reqStr] = generateExampleValueFromSchema(prop)
+				exampleArgs[reqStr] = generateExampleValueFromSchema(prop, gens)
 			}
 		}
 		// Add one or two optional parameters to show structure
 		count := 0
-		for paramName, prop := range properties {
+		for _, paramName := range slices.Sorted(maps.Keys(properties)) {
+			prop := properties[paramName]
 			if _, exists := exampleArgs[paramName]; !exists && count < 2 && prop != nil {
 				// Skip adding optional params if there are already many required ones
 				if len(exampleArgs) < 3 {
-					exampleArgs[paramName] = generateExampleValueFromSchema(prop)
+					exampleArgs[paramName] = generateExampleValueFromSchema(prop, gens)
 					count++
 				}
 			}
 		}
 	}
+	if example != nil {
+		exampleArgs = example
+	}
 
 	exampleJSON, _ := json.Marshal(exampleArgs)
 	desc.WriteString(string(exampleJSON))
@@ -189,8 +295,10 @@ func generateAIFriendlyDescription(op OpenAPIOperation, inputSchema jsonschema.S
 	return desc.String()
 }
 
-// generateExampleValueFromSchema creates appropriate example values based on the jsonschema.Schema
-func generateExampleValueFromSchema(prop *jsonschema.Schema) any {
+// generateExampleValueFromSchema creates appropriate example values based on the jsonschema.Schema.
+// Generators registered for a format take precedence over the built-in defaults; input schemas don't
+// retain component schema names, so generators registered for those don't apply here.
+func generateExampleValueFromSchema(prop *jsonschema.Schema, gens ExampleGenerators) any {
 	if prop == nil {
 		return nil
 	}
@@ -205,6 +313,10 @@ func generateExampleValueFromSchema(prop *jsonschema.Schema) any {
 		return prop.Examples[0]
 	}
 
+	if gen := gens.lookup("", prop.Format); gen != nil {
+		return gen(exampleRand())
+	}
+
 	// Generate based on type
 	switch prop.Type {
 	case "string":
@@ -230,16 +342,34 @@ func generateExampleValueFromSchema(prop *jsonschema.Schema) any {
 		return true
 	case "array":
 		if prop.Items != nil {
-			return []any{generateExampleValueFromSchema(prop.Items)}
+			return []any{generateExampleValueFromSchema(prop.Items, gens)}
 		}
 		return []any{"item1", "item2"}
 	case "object":
+		if obj := generateExampleArguments(prop, gens); len(obj) > 0 {
+			return obj
+		}
 		return map[string]any{"key": "value"}
 	default:
 		return nil
 	}
 }
 
+// generateExampleArguments creates example values for the required properties of an object schema,
+// e.g. to build valid tool arguments from a tool's input schema.
+func generateExampleArguments(schema *jsonschema.Schema, gens ExampleGenerators) map[string]any {
+	args := make(map[string]any)
+	if schema == nil {
+		return args
+	}
+	for _, name := range schema.Required {
+		if prop, ok := schema.Properties[name]; ok && prop != nil {
+			args[name] = generateExampleValueFromSchema(prop, gens)
+		}
+	}
+	return args
+}
+
 // hasDateTimeParameters checks if an operation has any date/time related parameters
 func hasDateTimeParameters(op OpenAPIOperation) bool {
 	// Check regular parameters
@@ -331,13 +461,76 @@ func hasDateTimeInSchema(schema *openapi3.Schema) bool {
 	return false
 }
 
+// metaToolNames lists the tools RegisterOpenAPITools adds in addition to the operations.
+var metaToolNames = []string{"externalDocs", "info", "server_stats", "await_callback", "list_received_callbacks", "convert_time", "describe_tool", "schedule_call", "list_scheduled", "cancel_scheduled", "begin_group", "commit_group", "abort_group"}
+
+// forEachParallel calls fn for every index in [0, n) using up to GOMAXPROCS goroutines
+// and waits for all calls to finish. A panic in fn is re-raised on the calling goroutine.
+func forEachParallel(n int, fn func(i int)) {
+	var (
+		wg       sync.WaitGroup
+		next     atomic.Int64
+		once     sync.Once
+		panicVal any
+	)
+	for range min(runtime.GOMAXPROCS(0), n) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					once.Do(func() { panicVal = r })
+				}
+			}()
+			for {
+				i := int(next.Add(1)) - 1
+				if i >= n {
+					return
+				}
+				fn(i)
+			}
+		}()
+	}
+	wg.Wait()
+	if panicVal != nil {
+		panic(panicVal)
+	}
+}
+
 // RegisterOpenAPITools registers each OpenAPI operation as an MCP tool with a real HTTP handler.
-// Also adds tools for externalDocs, info, and describe if present in the OpenAPI spec.
+// Also adds tools for externalDocs and info if present in the OpenAPI spec (unless disabled in opts),
+// and the user-defined opts.MetaTools.
 // The handler validates arguments, builds the HTTP request, and returns the HTTP response as the tool result.
+// Tools are built and registered concurrently; the returned names keep the order of ops.
 // Returns the list of tool names registered.
 func RegisterOpenAPITools(server *mcp.Server, ops []OpenAPIOperation, doc *openapi3.T, opts *ToolGenOptions) []string {
+	toolNames, toolSummaries := registerOpenAPITools(server, ops, doc, opts)
+	if opts != nil && opts.DryRun {
+		if opts.PrettyPrint {
+			out, _ := json.MarshalIndent(toolSummaries, "", "  ")
+			fmt.Println(string(out))
+		} else {
+			out, _ := json.Marshal(toolSummaries)
+			fmt.Println(string(out))
+		}
+	}
+	return toolNames
+}
+
+// registerOpenAPITools implements RegisterOpenAPITools, returning the summaries of the operations' tools
+// instead of printing them in dry runs. The server is not used in dry runs and may be nil.
+func registerOpenAPITools(server *mcp.Server, ops []OpenAPIOperation, doc *openapi3.T, opts *ToolGenOptions) ([]string, []ToolSummary) {
+	opts = withClientProfile(opts)
+	var profile *clientProfile
+	if opts != nil {
+		if p, ok := clientProfiles[opts.ClientProfile]; ok {
+			profile = &p
+		}
+	}
 	baseURLs := []string{}
-	if os.Getenv("OPENAPI_BASE_URL") != "" {
+	if opts != nil && opts.BaseURL != "" {
+		baseURLs = append(baseURLs, opts.BaseURL)
+	} else if os.Getenv("OPENAPI_BASE_URL") != "" {
 		baseURLs = append(baseURLs, os.Getenv("OPENAPI_BASE_URL"))
 	} else if len(doc.Servers) > 0 {
 		for _, s := range doc.Servers {
@@ -349,42 +542,160 @@ func RegisterOpenAPITools(server *mcp.Server, ops []OpenAPIOperation, doc *opena
 		baseURLs = append(baseURLs, "http://localhost:8080")
 	}
 
+	// State shared by all tools registered here (stats, ...)
+	rt := newServerRuntime()
+	rt.ops.doc = doc
+	var policy *HostPolicy
+	var configuredURLs []string
+	var overrides []BaseURLOverride
+	if opts != nil {
+		policy, configuredURLs, overrides = opts.HostPolicy, []string{opts.BaseURL}, opts.BaseURLOverrides
+		for _, o := range overrides {
+			configuredURLs = append(configuredURLs, o.URL)
+		}
+		if opts.Sandbox != nil {
+			configuredURLs = append(configuredURLs, opts.Sandbox.URL)
+		}
+		if opts.SpecDrift != nil {
+			configuredURLs = append(configuredURLs, opts.SpecDrift.URL)
+		}
+		if opts.OAuthLogin != nil {
+			configuredURLs = append(configuredURLs, opts.OAuthLogin.TokenURL, opts.OAuthLogin.DeviceAuthorizationURL)
+		}
+	}
+	rt.hosts = newHostGuard(policy, doc, configuredURLs, baseURLs)
+
 	// Map from operationID to inputSchema JSON for validation
 	// toolSchemas := make(map[string][]byte)
 	var toolNames []string
-	var toolSummaries []map[string]any
+	var toolSummaries []ToolSummary
 
 	// Tag filtering
-	filterByTag := func(op OpenAPIOperation) bool {
-		if opts == nil || len(opts.TagFilter) == 0 {
-			return true
-		}
-		found := false
-		for _, tag := range opts.TagFilter {
-			if slices.Contains(op.Tags, tag) {
-				found = true
-				break
-			}
+	selected := Operations(ops)
+	if opts != nil {
+		selected = selected.ByTag(opts.TagFilter...)
+	}
+
+	// Tool names are resolved up front so that duplicates keep the sequential "last one wins" behavior
+	names := make([]string, len(selected))
+	lastIndex := make(map[string]int, len(selected))
+	for i, op := range selected {
+		names[i] = op.OperationID
+		if opts != nil && opts.NameFormat != nil {
+			names[i] = opts.NameFormat(names[i])
 		}
-		return found
+		lastIndex[names[i]] = i
 	}
 
-	for _, op := range ops {
-		if !filterByTag(op) {
-			continue
+	dryRun := opts != nil && opts.DryRun
+
+	// Component schemas are converted once and shared by all tools referencing them
+	logger := warnLogger(opts)
+	cache := newSchemaCache(logger)
+	compact := opts != nil && opts.CompactSchemas
+
+	// The token of the user signed in with an interactive OAuth2 flow is shared by all tools
+	if opts != nil && opts.OAuthLogin != nil && !opts.Mock && !dryRun {
+		rt.login = newOAuthLoginSession(opts.OAuthLogin, doc, upstreamRequestHandler(rt, doc, opts), logger)
+	}
+
+	// Persist session cookies and raw responses, so that they survive restarts and are shared by replicas
+	if opts != nil && opts.Store != nil {
+		rt.results.store, rt.sessions.store = opts.Store, opts.Store
+	}
+
+	// Schema building and resolution dominate registration time for large specs, so input schemas are built
+	// concurrently. The tools are then described and added serially, so user hooks such as PostProcessSchema,
+	// NameFormat, and ExampleGenerators needn't be safe for concurrent use.
+	schemas := make([]jsonschema.Schema, len(selected))
+	forEachParallel(len(selected), func(i int) {
+		op := selected[i]
+		var override DescriptionOverride
+		if opts != nil {
+			override = opts.DescriptionOverrides[op.OperationID]
+		}
+		inputSchema := buildInputSchema(op.Parameters, op.RequestBody, cache, compact)
+		inputSchema = overrideParameterDescriptions(inputSchema, override)
+		if opts != nil && opts.GraphQL && isGraphQLOperation(op) {
+			inputSchema = graphQLInputSchema(inputSchema)
 		}
+		if opts != nil && opts.RelativeDates {
+			inputSchema = relativeDateSchema(inputSchema, op.Parameters)
+		}
+		schemas[i] = inputSchema
+	})
+
+	tools := make([]*mcp.Tool, len(selected))
+	for i, op := range selected {
+		op.Security = operationSecurity(op, doc)
+		name := names[i]
 
-		inputSchema := BuildInputSchema(op.Parameters, op.RequestBody)
+		// Descriptions sharpened for the model replace those of the spec
+		var override DescriptionOverride
+		if opts != nil {
+			override = opts.DescriptionOverrides[op.OperationID]
+		}
+		if override.Description != "" {
+			op.Description = override.Description
+		}
+
+		inputSchema := schemas[i]
 		if opts != nil && opts.PostProcessSchema != nil {
 			inputSchema = opts.PostProcessSchema(op.OperationID, inputSchema)
 		}
 
 		// Generate AI-friendly description
-		desc := generateAIFriendlyDescription(op, inputSchema)
+		var nameFormat func(string) string
+		var receiver *CallbackReceiver
+		var gens ExampleGenerators
+		if opts != nil {
+			nameFormat, receiver, gens = opts.NameFormat, opts.CallbackReceiver, opts.ExampleGenerators
+		}
+		desc := describeOperation(op, inputSchema, gens, override.Example)
+		desc += describeMethod(op, doc)
+		desc += describeLinks(op, doc, nameFormat)
+		desc += describeCallbacks(op, receiver)
+		desc += describeTimeout(operationTimeout(opts, op.OperationID))
+		desc += describeConcurrencyLimit(opts, op)
+		cost := operationCost(opts, op, doc)
+		desc += describeCost(cost, costConfirmationRequired(opts, cost))
+		var presets map[string]ArgumentPreset
+		if opts != nil {
+			desc += describeArgumentRules(opts.ArgumentRules.forOperation(op))
+			presets = opts.Presets[op.OperationID]
+			desc += describePresets(presets)
+		}
+		desc += describeExternalDocs(op)
+		if opts != nil {
+			desc += describeCodeSamples(op, doc, opts.CodeSampleLangs)
+		}
+		fullDesc := desc
+		shortened := false
+		if opts != nil {
+			desc, shortened = offloadDescription(name, desc, opts.MaxDescriptionLength)
+		}
+
+		// Every tool accepts the reserved __filter argument, and __accept if several response types are documented;
+		// they're left out of the description's parameter list
+		// (the properties are copied, as PostProcessSchema may return a map shared between tools)
+		props := make(map[string]*jsonschema.Schema, len(inputSchema.Properties)+2)
+		maps.Copy(props, inputSchema.Properties)
+		props[filterArgument] = filterArgumentSchema()
+		if types := responseContentTypes(op); len(types) > 1 {
+			props[acceptArgument] = acceptArgumentSchema(types)
+		}
+		if len(presets) > 0 {
+			props[presetArgument] = presetArgumentSchema(presets)
+			if unknown := unknownPresetArguments(presets, inputSchema); len(unknown) > 0 && !dryRun {
+				warnf(logger, "Presets of %s set arguments the operation doesn't have: %s", op.OperationID, strings.Join(unknown, ", "))
+			}
+		}
+		inputSchema.Properties = props
 
-		name := op.OperationID
-		if opts != nil && opts.NameFormat != nil {
-			name = opts.NameFormat(name)
+		// Binary uploads may also come from a local file, if enabled; requestBody is then optional
+		if opts != nil && opts.UploadRoot != "" && op.RequestBody != nil && op.RequestBody.Value != nil && binaryRequestType(op.RequestBody.Value.Content) != "" {
+			props[requestBodyFileArgument] = requestBodyFileSchema(opts.UploadRoot)
+			inputSchema.Required = slices.DeleteFunc(slices.Clone(inputSchema.Required), func(name string) bool { return name == "requestBody" })
 		}
 
 		annotations := mcp.ToolAnnotations{}
@@ -398,49 +709,70 @@ func RegisterOpenAPITools(server *mcp.Server, ops []OpenAPIOperation, doc *opena
 		if len(titleParts) > 0 {
 			annotations.Title = strings.Join(titleParts, " | ")
 		}
+		if profile != nil && profile.nativeConfirmation {
+			profileAnnotations(&annotations, op.Method)
+		}
 
 		tool := &mcp.Tool{
 			Name:        name,
+			Title:       override.Title,
 			Description: desc,
 			InputSchema: &inputSchema,
 		}
 		tool.Annotations = &annotations
+		costAnnotations(tool, cost)
+		tools[i] = tool
 
-		if opts != nil && opts.DryRun {
-			// For dry run, collect summary info
-			toolSummaries = append(toolSummaries, map[string]any{
-				"name":        name,
-				"description": desc,
-				"tags":        op.Tags,
-				"inputSchema": inputSchema,
-			})
-			toolNames = append(toolNames, name)
+		if dryRun || lastIndex[name] != i {
 			continue
 		}
 
-		requestHandler := defaultRequestHandler
-		if opts != nil && opts.RequestHandler != nil {
-			requestHandler = opts.RequestHandler
-		}
-
-		j, _ := json.MarshalIndent(inputSchema, "", "  ")
-		fmt.Println(string(j))
-
-		mcp.AddTool(server, tool, toolHandler(
+		handler := toolHandler(
 			name,
 			op,
 			doc,
 			inputSchema,
-			baseURLs,
-			opts != nil && opts.ConfirmDangerousActions,
-			requestHandler,
-		))
+			operationBaseURLs(overrides, op, baseURLs),
+			opts,
+			rt,
+		)
+		mcp.AddTool(server, tool, handler)
+		rt.ops.add(name, op, handler)
+		if opts != nil && opts.MaxDescriptionLength > 0 {
+			rt.details.add(toolDetail{Name: name, Description: fullDesc, InputSchema: tool.InputSchema, Shortened: shortened})
+		}
+	}
 
-		toolNames = append(toolNames, name)
+	// Arguments are coerced before the server validates them, and missing ones are reported in detail
+	if !dryRun {
+		args := make(map[string]toolArguments, len(tools))
+		for i, tool := range tools {
+			var presets map[string]ArgumentPreset
+			if opts != nil {
+				presets = opts.Presets[selected[i].OperationID]
+			}
+			args[tool.Name] = toolArguments{op: selected[i], schema: tool.InputSchema, presets: presets}
+		}
+		server.AddReceivingMiddleware(toolArgumentsMiddleware(args, opts))
+	}
+
+	for i, tool := range tools {
+		if dryRun {
+			// For dry run, collect summary info
+			toolSummaries = append(toolSummaries, ToolSummary{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Tags:        selected[i].Tags,
+				Method:      strings.ToUpper(selected[i].Method),
+				Path:        selected[i].Path,
+				InputSchema: *tool.InputSchema,
+			})
+		}
+		toolNames = append(toolNames, tool.Name)
 	}
 
 	// Add a tool for externalDocs if present
-	if doc.ExternalDocs != nil && doc.ExternalDocs.URL != "" && (opts == nil || !opts.DryRun) {
+	if doc.ExternalDocs != nil && doc.ExternalDocs.URL != "" && (opts == nil || !opts.DryRun && !opts.DisableExternalDocsTool) {
 		tool := &mcp.Tool{
 			Name:        "externalDocs",
 			Description: "Show the OpenAPI external documentation URL and description.",
@@ -469,7 +801,7 @@ func RegisterOpenAPITools(server *mcp.Server, ops []OpenAPIOperation, doc *opena
 	}
 
 	// Add a tool for info if present
-	if doc.Info != nil && (opts == nil || !opts.DryRun) {
+	if doc.Info != nil && (opts == nil || !opts.DryRun && !opts.DisableInfoTool) {
 		tool := &mcp.Tool{
 			Name:        "info",
 			Description: "Show API metadata: title, version, description, and terms of service.",
@@ -506,14 +838,113 @@ func RegisterOpenAPITools(server *mcp.Server, ops []OpenAPIOperation, doc *opena
 		toolNames = append(toolNames, "info")
 	}
 
-	if opts != nil && opts.DryRun {
-		if opts.PrettyPrint {
-			out, _ := json.MarshalIndent(toolSummaries, "", "  ")
-			fmt.Println(string(out))
-		} else {
-			out, _ := json.Marshal(toolSummaries)
-			fmt.Println(string(out))
+	// Add a tool exposing per-operation latency and outcome aggregates
+	if opts != nil && opts.Telemetry && !opts.DryRun {
+		tool := &mcp.Tool{
+			Name:        "server_stats",
+			Description: "Show per-tool call statistics since server start: call and error counts, error rate, average/max latency, retries, cache hits, bytes received, and status code counts. Use it to spot slow or flaky endpoints.",
+		}
+
+		if opts.Version != "" {
+			tool.Annotations = &mcp.ToolAnnotations{
+				Title: "OpenAPI " + opts.Version,
+			}
+		}
+
+		mcp.AddTool(server, tool, func(_ context.Context, req *mcp.CallToolRequest, _ any) (*mcp.CallToolResult, any, error) {
+			statsJSON, _ := json.MarshalIndent(rt.stats.snapshot(), "", "  ")
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{
+					&mcp.TextContent{
+						Text: string(statsJSON),
+					},
+				},
+			}, nil, nil
+		})
+		toolNames = append(toolNames, "server_stats")
+	}
+
+	// Expose the limits of tool calls, so that agents can set expectations (e.g. for long-running calls)
+	if !dryRun {
+		registerServerConfigResource(server, newServerConfig(opts, func(operationID string) string {
+			if opts != nil && opts.NameFormat != nil {
+				return opts.NameFormat(operationID)
+			}
+			return operationID
+		}))
+	}
+
+	// Show the remaining rate limit quota of the API hosts
+	if !dryRun {
+		registerRateLimitResource(server, rt.limits)
+	}
+
+	// Let agents inspect the document the tools were generated from
+	if !dryRun {
+		registerSpecResources(server, servedDocument(doc, selected))
+	}
+
+	// Compare the loaded spec with the one the API serves, warning when the tools are outdated
+	if opts != nil && opts.SpecDrift != nil && !opts.Mock && !dryRun {
+		drift := newSpecDriftChecker(opts.SpecDrift, baseURLs[0], upstreamRequestHandler(rt, doc, opts), doc, opts, logger)
+		registerSpecDriftResource(server, drift)
+		ctx := opts.SpecDrift.Context
+		if ctx == nil {
+			ctx = context.Background()
 		}
+		drift.start(ctx, opts.SpecDrift.Interval)
+	}
+
+	// Serve the raw HTML of responses shown as Markdown and the complete bodies of cut off responses
+	if opts != nil && (opts.HTMLToMarkdown || len(opts.TransportResponseLimits) > 0) && !dryRun {
+		registerResultResource(server, rt.results)
+	}
+
+	// Document the webhooks of OpenAPI 3.1 documents as resources
+	var receiver *CallbackReceiver
+	if opts != nil {
+		receiver = opts.CallbackReceiver
+	}
+	webhooks, err := ExtractWebhooks(doc)
+	if err != nil {
+		warnf(logger, "Ignoring webhooks: %v", err)
+	}
+	if len(webhooks) > 0 && !dryRun {
+		registerWebhookResources(server, webhooks, receiver)
+	}
+
+	// Serve the complete descriptions of the tools whose listed descriptions are shortened
+	if opts != nil && opts.MaxDescriptionLength > 0 && !dryRun {
+		registerDescribeTool(server, rt.details, opts)
+		toolNames = append(toolNames, "describe_tool")
+	}
+
+	// Add tools for awaiting callbacks if a receiver is configured and any operation defines callbacks, or the API sends webhooks
+	if receiver != nil && !dryRun && (hasCallbacks(ops) || len(webhooks) > 0) {
+		registerCallbackTools(server, receiver, opts)
+		toolNames = append(toolNames, "await_callback", "list_received_callbacks")
+	}
+
+	// Add tools running operation calls later
+	if opts != nil && opts.ScheduledCalls && !dryRun {
+		registerScheduleTools(server, newCallScheduler(rt.ops, opts.Store), opts)
+		toolNames = append(toolNames, "schedule_call", "list_scheduled", "cancel_scheduled")
+	}
+
+	// Add tools grouping state-changing calls, so that partial multi-step changes can be undone
+	if opts != nil && opts.CallGroups && !dryRun {
+		registerCallGroupTools(server, rt.groups, rt.ops, opts.GroupRollback, opts)
+		toolNames = append(toolNames, "begin_group", "commit_group", "abort_group")
+	}
+
+	// Add the tools running workflows of several operation calls
+	if opts != nil && len(opts.Workflows) > 0 && !dryRun {
+		toolNames = append(toolNames, registerWorkflowTools(server, opts.Workflows, rt.ops, opts, logger)...)
+	}
+
+	// Add the user-defined meta tools last, so that they can replace built-in ones
+	if opts != nil && len(opts.MetaTools) > 0 && !dryRun {
+		toolNames = append(toolNames, registerMetaTools(server, opts.MetaTools, rt.ops, logger)...)
 	}
 
 	// Check if any operations use date/time parameters
@@ -525,33 +956,17 @@ func RegisterOpenAPITools(server *mcp.Server, ops []OpenAPIOperation, doc *opena
 		}
 	}
 
-	// Add a resource that provides the current Unix timestamp only if there are time-related operations
-	if hasTimeRelatedOps && (opts == nil || !opts.DryRun) {
-		timestampResource := mcp.Resource{
-			URI:         "timestamp://current",
-			Name:        "Current Unix Timestamp",
-			Description: "Provides the current Unix timestamp in seconds to help the AI understand the current date and time",
-			MIMEType:    "application/json",
+	// Help with dates only if there are time-related operations
+	if hasTimeRelatedOps && !dryRun {
+		loc := timeZone(opts)
+		if opts == nil || !opts.DisableTimestampResource {
+			registerTimestampResource(server, loc)
+		}
+		if opts == nil || !opts.DisableConvertTimeTool {
+			registerConvertTimeTool(server, loc, opts)
+			toolNames = append(toolNames, "convert_time")
 		}
-
-		server.AddResource(&timestampResource, func(ctx context.Context, req *mcp.ServerRequest[*mcp.ReadResourceParams]) (*mcp.ReadResourceResult, error) {
-			now := time.Now().Unix()
-			content := fmt.Sprintf(`{"unix_timestamp": %d, "iso8601": "%s", "timezone": "%s"}`,
-				now,
-				time.Now().Format(time.RFC3339),
-				time.Now().Format("MST"))
-
-			return &mcp.ReadResourceResult{
-				Contents: []*mcp.ResourceContents{
-					{
-						URI:      timestampResource.URI,
-						MIMEType: "application/json",
-						Text:     content,
-					},
-				},
-			}, nil
-		})
 	}
 
-	return toolNames
+	return toolNames, toolSummaries
 }
@@ -1,10 +1,14 @@
 package openapi2mcp
 
 import (
+	"context"
+	"fmt"
+	"net/http"
 	"strings"
 	"testing"
 
 	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -625,3 +629,168 @@ func TestGetParameterValue(t *testing.T) {
 		t.Errorf("Expected to not find non-existent parameter, but found: %v", val)
 	}
 }
+
+// largeOpenAPIDoc builds a synthetic spec with n operations, each with a path parameter,
+// a query parameter, and a shared component request body, similar in shape to large public APIs.
+func largeOpenAPIDoc(n int) *openapi3.T {
+	address := &openapi3.Schema{
+		Type: typesPtr("object"),
+		Properties: openapi3.Schemas{
+			"line1":   &openapi3.SchemaRef{Value: &openapi3.Schema{Type: typesPtr("string")}},
+			"city":    &openapi3.SchemaRef{Value: &openapi3.Schema{Type: typesPtr("string")}},
+			"country": &openapi3.SchemaRef{Value: &openapi3.Schema{Type: typesPtr("string"), Enum: []any{"DE", "US", "FR"}}},
+		},
+	}
+	body := &openapi3.Schema{
+		Type:     typesPtr("object"),
+		Required: []string{"name"},
+		Properties: openapi3.Schemas{
+			"name":     &openapi3.SchemaRef{Value: &openapi3.Schema{Type: typesPtr("string"), Description: "Display name"}},
+			"metadata": &openapi3.SchemaRef{Value: &openapi3.Schema{Type: typesPtr("object")}},
+			"address":  &openapi3.SchemaRef{Ref: "#/components/schemas/Address", Value: address},
+			"tags": &openapi3.SchemaRef{Value: &openapi3.Schema{
+				Type:  typesPtr("array"),
+				Items: &openapi3.SchemaRef{Value: &openapi3.Schema{Type: typesPtr("string")}},
+			}},
+		},
+	}
+
+	bodyRef := &openapi3.SchemaRef{Ref: "#/components/schemas/Resource", Value: body}
+
+	paths := openapi3.NewPaths()
+	for i := range n {
+		paths.Set(fmt.Sprintf("/resources%d/{id}", i), &openapi3.PathItem{
+			Post: &openapi3.Operation{
+				OperationID: fmt.Sprintf("updateResource%d", i),
+				Summary:     "Update a resource",
+				Tags:        []string{"resources"},
+				Parameters: openapi3.Parameters{
+					{Value: &openapi3.Parameter{Name: "id", In: "path", Required: true, Schema: &openapi3.SchemaRef{Value: &openapi3.Schema{Type: typesPtr("string")}}}},
+					{Value: &openapi3.Parameter{Name: "expand[]", In: "query", Schema: &openapi3.SchemaRef{Value: &openapi3.Schema{Type: typesPtr("string")}}}},
+				},
+				RequestBody: &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithJSONSchemaRef(bodyRef)},
+			},
+		})
+	}
+
+	return &openapi3.T{
+		Info:  &openapi3.Info{Title: "Large API", Version: "1.0.0"},
+		Paths: paths,
+	}
+}
+
+func TestRegisterOpenAPITools_Large(t *testing.T) {
+	doc := largeOpenAPIDoc(500)
+	srv := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	ops := ExtractOpenAPIOperations(doc)
+	names := RegisterOpenAPITools(srv, ops, doc, &ToolGenOptions{})
+	if len(names) != 501 {
+		t.Fatalf("expected 501 tools, got %d", len(names))
+	}
+	// Tools are registered in operation order regardless of parallel schema building
+	for i, op := range ops {
+		if names[i] != op.OperationID {
+			t.Fatalf("expected tool %d to be %s, got %s", i, op.OperationID, names[i])
+		}
+	}
+}
+
+func BenchmarkExtractOpenAPIOperations(b *testing.B) {
+	doc := largeOpenAPIDoc(2000)
+	b.ResetTimer()
+	for b.Loop() {
+		ExtractOpenAPIOperations(doc)
+	}
+}
+
+func BenchmarkRegisterOpenAPITools(b *testing.B) {
+	doc := largeOpenAPIDoc(2000)
+	ops := ExtractOpenAPIOperations(doc)
+	b.ResetTimer()
+	for b.Loop() {
+		srv := mcp.NewServer(&mcp.Implementation{Name: "bench", Version: "1.0.0"}, nil)
+		RegisterOpenAPITools(srv, ops, doc, &ToolGenOptions{})
+	}
+}
+
+func TestToolHandler_APIKeyFallback(t *testing.T) {
+	doc := minimalOpenAPIDoc()
+	doc.Components = &openapi3.Components{SecuritySchemes: openapi3.SecuritySchemes{
+		"token": &openapi3.SecuritySchemeRef{Value: &openapi3.SecurityScheme{Type: "apiKey", In: "header", Name: "X-Token"}},
+	}}
+	op := OpenAPIOperation{OperationID: "getFoo", Path: "/foo", Method: "get"}
+	t.Setenv("API_KEY", "secret")
+	t.Setenv("API_KEY_HEADER", "")
+
+	tests := []struct {
+		name      string
+		optHeader string
+		envHeader string
+		want      string
+	}{
+		{"spec apiKey scheme", "", "", "X-Token"},
+		{"env header", "", "X-Env-Key", "X-Env-Key"},
+		{"option header", "X-Opt-Key", "X-Env-Key", "X-Opt-Key"},
+	}
+	for _, tc := range tests {
+		t.Setenv("API_KEY_HEADER", tc.envHeader)
+		var got *http.Request
+		opts := &ToolGenOptions{
+			APIKeyHeader: tc.optHeader,
+			RequestHandler: func(req *http.Request) (*http.Response, error) {
+				got = req
+				return fakeResponse(200, "application/json", `{}`)(req)
+			},
+		}
+		handler := toolHandler("getFoo", op, doc, jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+		if _, _, err := handler(context.Background(), nil, map[string]any{}); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if got.Header.Get(tc.want) != "secret" {
+			t.Errorf("%s: expected API key in %s, got headers %v", tc.name, tc.want, got.Header)
+		}
+	}
+}
+
+func TestToolHandler_DocumentSecurity(t *testing.T) {
+	doc := minimalOpenAPIDoc()
+	doc.Components = &openapi3.Components{SecuritySchemes: openapi3.SecuritySchemes{
+		"token":  &openapi3.SecuritySchemeRef{Value: &openapi3.SecurityScheme{Type: "apiKey", In: "query", Name: "token"}},
+		"bearer": &openapi3.SecuritySchemeRef{Value: &openapi3.SecurityScheme{Type: "http", Scheme: "bearer"}},
+	}}
+	doc.Security = openapi3.SecurityRequirements{{"token": {}}}
+	t.Setenv("API_KEY", "secret")
+	t.Setenv("API_KEY_HEADER", "")
+	t.Setenv("BEARER_TOKEN", "")
+
+	tests := []struct {
+		name      string
+		security  openapi3.SecurityRequirements
+		wantQuery string
+		wantAuth  bool
+	}{
+		{"inherits document security", nil, "token=secret", true},
+		{"empty array means no auth", openapi3.SecurityRequirements{}, "", false},
+		{"optional auth", openapi3.SecurityRequirements{{"bearer": {}}, {}}, "", false},
+		// Both schemes of a requirement must be fulfilled; BEARER_TOKEN is unset, so the second alternative is used
+		{"all schemes of a requirement", openapi3.SecurityRequirements{{"bearer": {}, "token": {}}, {"token": {}}}, "token=secret", true},
+	}
+	for _, tc := range tests {
+		var got *http.Request
+		opts := &ToolGenOptions{RequestHandler: func(req *http.Request) (*http.Response, error) {
+			got = req
+			return fakeResponse(200, "application/json", `{}`)(req)
+		}}
+		op := OpenAPIOperation{OperationID: "getFoo", Path: "/foo", Method: "get", Security: tc.security}
+		handler := toolHandler("getFoo", op, doc, jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+		if _, _, err := handler(context.Background(), nil, map[string]any{}); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if got.URL.RawQuery != tc.wantQuery {
+			t.Errorf("%s: expected query %q, got %q", tc.name, tc.wantQuery, got.URL.RawQuery)
+		}
+		if hasAuth := got.URL.Query().Has("token") || got.Header.Get("Authorization") != ""; hasAuth != tc.wantAuth {
+			t.Errorf("%s: expected credentials %v, got query %q, headers %v", tc.name, tc.wantAuth, got.URL.RawQuery, got.Header)
+		}
+	}
+}
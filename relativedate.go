@@ -0,0 +1,159 @@
+// relativedate.go
+package openapi2mcp
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// relativeOffset matches offsets from now such as "-7d", "+2w", "-3h", "-30min", "-1mo", or "+1y".
+var relativeOffset = regexp.MustCompile(`^([+-]?)(\d+)\s*(min|h|d|w|mo|y)$`)
+
+// relativePeriod matches the bounds of calendar periods such as "last_month_start" or "this_week_end".
+var relativePeriod = regexp.MustCompile(`^(this|last|next)_(week|month|year)_(start|end)$`)
+
+// relativeDateHint is appended to the descriptions of date parameters accepting relative values.
+const relativeDateHint = ` Relative values are accepted and resolved by the server: "now", "today", "yesterday", "tomorrow", offsets such as "-7d", "-2w", "-1mo", or "-3h", and period bounds such as "last_month_start" or "this_week_end" (weeks start on Monday).`
+
+// dateFormat returns the format of a date or date-time parameter, or "" for other parameters.
+func dateFormat(p *openapi3.Parameter) string {
+	if p.Schema == nil || p.Schema.Value == nil || !p.Schema.Value.Type.Is("string") {
+		return ""
+	}
+	if format := p.Schema.Value.Format; format == "date" || format == "date-time" {
+		return format
+	}
+	return ""
+}
+
+// resolveRelativeDate resolves a relative date expression at now in now's location, returning false if s is
+// not one. Day names and period bounds refer to the start of a day, the end of a period to its last second.
+func resolveRelativeDate(s string, now time.Time) (time.Time, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch s {
+	case "now":
+		return now, true
+	case "today":
+		return day, true
+	case "yesterday":
+		return day.AddDate(0, 0, -1), true
+	case "tomorrow":
+		return day.AddDate(0, 0, 1), true
+	}
+
+	if m := relativeOffset.FindStringSubmatch(s); m != nil {
+		n, err := strconv.Atoi(m[2])
+		if err != nil {
+			return time.Time{}, false
+		}
+		if m[1] == "-" {
+			n = -n
+		}
+		switch m[3] {
+		case "min":
+			return now.Add(time.Duration(n) * time.Minute), true
+		case "h":
+			return now.Add(time.Duration(n) * time.Hour), true
+		case "d":
+			return now.AddDate(0, 0, n), true
+		case "w":
+			return now.AddDate(0, 0, 7*n), true
+		case "mo":
+			return now.AddDate(0, n, 0), true
+		case "y":
+			return now.AddDate(n, 0, 0), true
+		}
+	}
+
+	m := relativePeriod.FindStringSubmatch(strings.NewReplacer(" ", "_", "-", "_").Replace(s))
+	if m == nil {
+		return time.Time{}, false
+	}
+	shift := map[string]int{"this": 0, "last": -1, "next": 1}[m[1]]
+	var start, next time.Time
+	switch m[2] {
+	case "week":
+		start = day.AddDate(0, 0, -((int(day.Weekday())+6)%7)+7*shift)
+		next = start.AddDate(0, 0, 7)
+	case "month":
+		start = time.Date(day.Year(), day.Month()+time.Month(shift), 1, 0, 0, 0, 0, day.Location())
+		next = start.AddDate(0, 1, 0)
+	case "year":
+		start = time.Date(day.Year()+shift, 1, 1, 0, 0, 0, 0, day.Location())
+		next = start.AddDate(1, 0, 0)
+	}
+	if m[3] == "end" {
+		return next.Add(-time.Second), true
+	}
+	return start, true
+}
+
+// resolveRelativeDates replaces relative values of date and date-time parameters in args, e.g. "yesterday",
+// with dates in loc. It returns the arguments to send (args itself if nothing was resolved) and a description
+// of each resolution, to be shown with the result.
+func resolveRelativeDates(params openapi3.Parameters, args map[string]any, now time.Time, loc *time.Location) (map[string]any, []string) {
+	var resolved []string
+	out := args
+	for _, paramRef := range params {
+		if paramRef == nil || paramRef.Value == nil {
+			continue
+		}
+		format := dateFormat(paramRef.Value)
+		if format == "" {
+			continue
+		}
+		for _, key := range []string{escapeParameterName(paramRef.Value.Name), paramRef.Value.Name} {
+			s, ok := args[key].(string)
+			if !ok {
+				continue
+			}
+			t, ok := resolveRelativeDate(s, now.In(loc))
+			if !ok {
+				break
+			}
+			value := t.Format(time.RFC3339)
+			if format == "date" {
+				value = t.Format(time.DateOnly)
+			}
+			if len(resolved) == 0 {
+				out = maps.Clone(args)
+			}
+			out[key] = value
+			resolved = append(resolved, fmt.Sprintf("%s: %q → %s", paramRef.Value.Name, s, value))
+			break
+		}
+	}
+	return out, resolved
+}
+
+// formatRelativeDates renders the note listing the relative dates resolved for a call.
+func formatRelativeDates(resolved []string) string {
+	return "Resolved relative dates:\n" + strings.Join(resolved, "\n")
+}
+
+// relativeDateSchema mentions the accepted relative values in the descriptions of the date parameters.
+// The properties are copied, as the input schema's may be shared.
+func relativeDateSchema(schema jsonschema.Schema, params openapi3.Parameters) jsonschema.Schema {
+	props := maps.Clone(schema.Properties)
+	for _, paramRef := range params {
+		if paramRef == nil || paramRef.Value == nil || dateFormat(paramRef.Value) == "" {
+			continue
+		}
+		name := escapeParameterName(paramRef.Value.Name)
+		if prop := props[name]; prop != nil {
+			prop = shallowCopy(prop)
+			prop.Description = strings.TrimSpace(prop.Description + relativeDateHint)
+			props[name] = prop
+		}
+	}
+	schema.Properties = props
+	return schema
+}
@@ -0,0 +1,100 @@
+package openapi2mcp
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestResolveRelativeDate(t *testing.T) {
+	now := time.Date(2024, 5, 15, 10, 30, 0, 0, time.UTC) // a Wednesday
+	for s, want := range map[string]string{
+		"now":              "2024-05-15T10:30:00Z",
+		"Today":            "2024-05-15T00:00:00Z",
+		"yesterday":        "2024-05-14T00:00:00Z",
+		"-7d":              "2024-05-08T10:30:00Z",
+		"+2w":              "2024-05-29T10:30:00Z",
+		"-3h":              "2024-05-15T07:30:00Z",
+		"-30min":           "2024-05-15T10:00:00Z",
+		"-1mo":             "2024-04-15T10:30:00Z",
+		"last_month_start": "2024-04-01T00:00:00Z",
+		"last month end":   "2024-04-30T23:59:59Z",
+		"this_week_start":  "2024-05-13T00:00:00Z",
+		"next_week_end":    "2024-05-26T23:59:59Z",
+		"last_year_start":  "2023-01-01T00:00:00Z",
+	} {
+		got, ok := resolveRelativeDate(s, now)
+		if !ok || got.Format(time.RFC3339) != want {
+			t.Errorf("resolveRelativeDate(%q) = %v, %v; want %s", s, got, ok, want)
+		}
+	}
+	for _, s := range []string{"2024-05-01", "last_decade_start", "-7", ""} {
+		if _, ok := resolveRelativeDate(s, now); ok {
+			t.Errorf("resolveRelativeDate(%q): expected no relative date", s)
+		}
+	}
+}
+
+func TestRegisterOpenAPITools_RelativeDates(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+servers: [{url: "https://api.example.com"}]
+paths:
+  /events:
+    get:
+      operationId: listEvents
+      parameters:
+        - {name: since, in: query, schema: {type: string, format: date}}
+        - {name: until, in: query, schema: {type: string, format: date-time}}
+      responses: {"200": {description: ok}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var query string
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	RegisterOpenAPITools(server, ExtractOpenAPIOperations(doc), doc, &ToolGenOptions{
+		RelativeDates: true,
+		TimeZone:      time.UTC,
+		RequestHandler: func(req *http.Request) (*http.Response, error) {
+			query = req.URL.RawQuery
+			return fakeResponse(200, "application/json", `[]`)(req)
+		},
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(context.Background(), serverTransport, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil).Connect(context.Background(), clientTransport, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cs.Close()
+
+	tools, err := cs.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	i := slices.IndexFunc(tools.Tools, func(tool *mcp.Tool) bool { return tool.Name == "listEvents" })
+	if desc := tools.Tools[i].InputSchema.Properties["since"].Description; !strings.Contains(desc, "last_month_start") {
+		t.Errorf("expected the relative values in the description, got %q", desc)
+	}
+
+	res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "listEvents", Arguments: map[string]any{"since": "yesterday", "until": "2024-05-01T00:00:00Z"}})
+	if err != nil || res.IsError {
+		t.Fatalf("unexpected error: %v %v", err, res)
+	}
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format(time.DateOnly)
+	if !strings.Contains(query, "since="+yesterday) || !strings.Contains(query, "until=2024-05-01T00%3A00%3A00Z") {
+		t.Errorf("expected yesterday's date to be sent, got %s", query)
+	}
+	if note := res.Content[len(res.Content)-1].(*mcp.TextContent).Text; note != "Resolved relative dates:\nsince: \"yesterday\" → "+yesterday {
+		t.Errorf("unexpected note: %s", note)
+	}
+}
@@ -0,0 +1,155 @@
+// repro.go
+package openapi2mcp
+
+import (
+	"encoding/base64"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Command styles for ToolGenOptions.ReproCommand.
+const (
+	ReproCommandCurl   = "curl"   // curl command line
+	ReproCommandHTTPie = "httpie" // HTTPie (http) command line
+)
+
+// reproSecrets are the environment variables holding the credentials the server sends, in the order they are substituted.
+var reproSecrets = []string{"BEARER_TOKEN", "API_KEY"}
+
+// placeholderMark delimits environment variable placeholders in command arguments until they are quoted.
+const placeholderMark = "\x00"
+
+// placeholder marks a reference to the environment variable name in a command argument.
+func placeholder(name string) string {
+	return placeholderMark + name + placeholderMark
+}
+
+// envName returns the environment variable name standing in for an unknown credential header, e.g. X_API_TOKEN.
+func envName(header string) string {
+	return strings.ToUpper(strings.ReplaceAll(header, "-", "_"))
+}
+
+// reproCommand renders the request as a curl or HTTPie command line. Credentials from the environment (BEARER_TOKEN,
+// API_KEY, BASIC_AUTH) are replaced with references to their variables, other credential headers with a variable
+// named after the header, and JSON bodies are redacted as in logs, so the command can be shared safely. A body
+// streamed from bodyFile is read from that file.
+func reproCommand(style string, req *http.Request, body []byte, bodyFile string, doc *openapi3.T, opts *ToolGenOptions) string {
+	secrets := func(s string) string {
+		for _, name := range reproSecrets {
+			if value := os.Getenv(name); len(value) >= 4 {
+				s = strings.ReplaceAll(s, value, placeholder(name))
+			}
+		}
+		return s
+	}
+
+	credentials := credentialHeaders(doc, opts)
+	var basicAuth bool
+	var headers []string
+	for _, name := range slices.Sorted(maps.Keys(req.Header)) {
+		for _, value := range req.Header[name] {
+			sensitive := opts.Redaction.redactsHeader(name) || slices.ContainsFunc(credentials, func(c string) bool { return strings.EqualFold(c, name) })
+			if basic := os.Getenv("BASIC_AUTH"); basic != "" && strings.EqualFold(name, "Authorization") && value == "Basic "+base64.StdEncoding.EncodeToString([]byte(basic)) {
+				basicAuth = true
+				continue
+			}
+			value = secrets(value)
+			if sensitive && !strings.Contains(value, placeholderMark) {
+				value = placeholder(envName(name))
+			}
+			headers = append(headers, name+": "+value)
+		}
+	}
+	url := secrets(req.URL.String())
+	if len(body) > 0 {
+		body = opts.Redaction.redactBody(body)
+	}
+
+	var args []string
+	var stdin string // file redirected to the command's standard input
+	switch style {
+	case ReproCommandHTTPie:
+		args = append(args, "http", req.Method, url)
+		if basicAuth {
+			args = append(args, "--auth", placeholder("BASIC_AUTH"))
+		}
+		for _, header := range headers {
+			name, value, _ := strings.Cut(header, ": ")
+			args = append(args, name+":"+value)
+		}
+		if bodyFile != "" {
+			stdin = bodyFile
+		} else if len(body) > 0 {
+			if utf8.Valid(body) {
+				args = append(args, "--raw", string(body))
+			} else {
+				args = append(args, "--raw", fmt.Sprintf("<binary body of %d bytes>", len(body)))
+			}
+		}
+	default:
+		args = append(args, "curl", "-X", req.Method, url)
+		if basicAuth {
+			args = append(args, "-u", placeholder("BASIC_AUTH"))
+		}
+		for _, header := range headers {
+			args = append(args, "-H", header)
+		}
+		if bodyFile != "" {
+			args = append(args, "--data-binary", "@"+bodyFile)
+		} else if len(body) > 0 {
+			if utf8.Valid(body) {
+				args = append(args, "--data-binary", string(body))
+			} else {
+				args = append(args, "--data-binary", fmt.Sprintf("@<binary body of %d bytes>", len(body)))
+			}
+		}
+	}
+
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	if stdin != "" {
+		quoted = append(quoted, "<", shellQuote(stdin))
+	}
+	return strings.Join(quoted, " ")
+}
+
+// shellQuote quotes a command argument for POSIX shells. Arguments referencing environment variables are
+// double-quoted so that the variables expand; all others are single-quoted unless they are plain words.
+func shellQuote(arg string) string {
+	if !strings.Contains(arg, placeholderMark) {
+		if arg != "" && strings.Trim(arg, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:=@") == "" {
+			return arg
+		}
+		return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for i, part := range strings.Split(arg, placeholderMark) {
+		if i%2 == 1 {
+			b.WriteString("${" + part + "}")
+			continue
+		}
+		for _, r := range part {
+			if strings.ContainsRune(`"\$`+"`", r) {
+				b.WriteByte('\\')
+			}
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// formatReproCommand renders the note appended to results showing how to reproduce the call.
+func formatReproCommand(command string) string {
+	return "Reproduce with:\n" + command
+}
@@ -0,0 +1,62 @@
+package openapi2mcp
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestReproCommand(t *testing.T) {
+	t.Setenv("BEARER_TOKEN", "s3cr3t-token")
+	t.Setenv("API_KEY", "k3y-value")
+	t.Setenv("BASIC_AUTH", "")
+
+	req, _ := http.NewRequest("POST", "https://api.example.com/pets?api_key=k3y-value&q=it's", nil)
+	req.Header.Set("Authorization", "Bearer s3cr3t-token")
+	req.Header.Set("X-Session", "abc")
+	req.Header.Set("Content-Type", "application/json")
+	body := []byte(`{"name":"Rex","password":"hunter2"}`)
+	opts := &ToolGenOptions{ForwardHeaders: []string{"X-Session"}}
+
+	want := `curl -X POST "https://api.example.com/pets?api_key=${API_KEY}&q=it's" -H "Authorization: Bearer ${BEARER_TOKEN}" -H 'Content-Type: application/json' -H "X-Session: ${X_SESSION}" --data-binary '{"name":"Rex","password":"[REDACTED]"}'`
+	if got := reproCommand(ReproCommandCurl, req, body, "", minimalOpenAPIDoc(), opts); got != want {
+		t.Errorf("reproCommand(curl) =\n%s\nwant:\n%s", got, want)
+	}
+	want = `http POST "https://api.example.com/pets?api_key=${API_KEY}&q=it's" "Authorization:Bearer ${BEARER_TOKEN}" Content-Type:application/json "X-Session:${X_SESSION}" --raw '{"name":"Rex","password":"[REDACTED]"}'`
+	if got := reproCommand(ReproCommandHTTPie, req, body, "", minimalOpenAPIDoc(), opts); got != want {
+		t.Errorf("reproCommand(httpie) =\n%s\nwant:\n%s", got, want)
+	}
+
+	// Basic credentials become the user option
+	t.Setenv("BASIC_AUTH", "user:pass")
+	req, _ = http.NewRequest("GET", "https://api.example.com/me", nil)
+	req.SetBasicAuth("user", "pass")
+	if got := reproCommand(ReproCommandCurl, req, nil, "", minimalOpenAPIDoc(), opts); got != `curl -X GET https://api.example.com/me -u "${BASIC_AUTH}"` {
+		t.Errorf("unexpected command: %s", got)
+	}
+}
+
+func TestToolHandler_ReproCommand(t *testing.T) {
+	op := OpenAPIOperation{OperationID: "listPets", Path: "/pets", Method: "get"}
+	status := 200
+	opts := &ToolGenOptions{ReproCommand: ReproCommandCurl, RequestHandler: func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: status, Header: http.Header{"Content-Type": {"application/json"}}, Body: io.NopCloser(bytes.NewReader([]byte(`[]`))), Request: req}, nil
+	}}
+	handler := toolHandler("listPets", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+
+	for _, status = range []int{200, 500} {
+		res, _, err := handler(context.Background(), nil, map[string]any{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if text := res.Content[len(res.Content)-1].(*mcp.TextContent).Text; !strings.HasPrefix(text, "Reproduce with:\ncurl -X GET http://example.com/pets -H 'Accept: application/json, application/vnd.api+json'") {
+			t.Errorf("HTTP %d: expected the curl command, got: %s", status, text)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+// requestbody.go
+package openapi2mcp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// requestBodyFileArgument names the local file sent as a binary request body instead of requestBody.
+const requestBodyFileArgument = "requestBodyFile"
+
+// binaryRequestType returns the media type of a raw binary request body the operation accepts: application/octet-stream,
+// else the first (in name order) media type with a {type: string, format: binary} schema or without a schema.
+// JSON, patch, and NDJSON bodies take precedence, so it returns "" if the operation accepts any of them.
+func binaryRequestType(content openapi3.Content) string {
+	if acceptsJSON(content) || ndjsonRequestType(content) != "" || patchRequestType(content) != "" {
+		return ""
+	}
+	for contentType := range content {
+		if baseMediaType(contentType) == "application/octet-stream" {
+			return contentType
+		}
+	}
+	for _, contentType := range slices.Sorted(maps.Keys(content)) {
+		mt := content[contentType]
+		base := baseMediaType(contentType)
+		if base == "multipart/form-data" || base == "application/x-www-form-urlencoded" || strings.HasPrefix(base, "text/") || mt == nil {
+			continue
+		}
+		if mt.Schema == nil || mt.Schema.Value == nil || mt.Schema.Value.Format == "binary" && mt.Schema.Value.Type.Is("string") {
+			return contentType
+		}
+	}
+	return ""
+}
+
+// acceptsJSON reports whether the request body may be application/json or application/vnd.api+json.
+func acceptsJSON(content openapi3.Content) bool {
+	return getContentByType(content, "application/json") != nil || getContentByType(content, "application/vnd.api+json") != nil
+}
+
+// ndjsonRequestType returns the NDJSON (JSON Lines) media type of the request body, e.g. application/x-ndjson
+// for bulk-ingest endpoints, or "" if there is none or the operation accepts JSON.
+func ndjsonRequestType(content openapi3.Content) string {
+	if acceptsJSON(content) {
+		return ""
+	}
+	for _, contentType := range slices.Sorted(maps.Keys(content)) {
+		if tabularKind(contentType) == tabularNDJSON {
+			return contentType
+		}
+	}
+	return ""
+}
+
+// ndjsonRequestBodySchema describes the requestBody argument of an NDJSON body: an array of the documents sent
+// one per line. The media type's schema usually describes a single line, but may describe the whole array.
+func ndjsonRequestBodySchema(contentType string, mt *openapi3.MediaType, cache *schemaCache) *jsonschema.Schema {
+	description := fmt.Sprintf("The request body as an array of JSON documents, sent one per line (%s).", contentType)
+	var item *jsonschema.Schema
+	if mt != nil && mt.Schema != nil && mt.Schema.Value != nil {
+		item = extractProperty(mt.Schema, cache)
+	}
+	if item != nil && item.Type == "array" {
+		array := shallowCopy(item)
+		array.Description = description
+		return array
+	}
+	return &jsonschema.Schema{Type: "array", Items: item, Description: description}
+}
+
+// encodeNDJSON serializes an array of documents as NDJSON, one document per line.
+func encodeNDJSON(v any) ([]byte, error) {
+	docs, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("expected an array of JSON documents, got %T", v)
+	}
+	var body []byte
+	for _, doc := range docs {
+		line, err := json.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		body = append(append(body, line...), '\n')
+	}
+	return body, nil
+}
+
+// baseMediaType returns the media type without parameters, e.g. "image/png" for "image/png; q=0.9".
+func baseMediaType(contentType string) string {
+	base, _, _ := strings.Cut(contentType, ";")
+	return strings.TrimSpace(base)
+}
+
+// binaryRequestBodySchema describes the requestBody argument of an operation taking a raw binary body.
+func binaryRequestBodySchema(contentType string) *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:            "string",
+		ContentEncoding: "base64",
+		Description:     fmt.Sprintf("The request body (%s) as base64-encoded bytes.", contentType),
+	}
+}
+
+// requestBodyFileSchema describes the requestBodyFile argument offered if uploads from local files are enabled.
+func requestBodyFileSchema(root string) *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type:        "string",
+		Description: fmt.Sprintf("Path of a local file to send as the request body instead of requestBody, relative to %s.", root),
+	}
+}
+
+// uploadFile is a local file sent as a request body.
+type uploadFile struct {
+	file *os.File
+	size int64
+	path string // the path within the upload root, for messages and reproduction
+}
+
+// openUploadFile opens the file at path within root for upload. Paths may be relative to root or absolute
+// below it; paths escaping root (including through symlinks) are rejected.
+func openUploadFile(root, path string, maxBytes int64) (*uploadFile, error) {
+	if root == "" {
+		return nil, errors.New("uploads from local files are not enabled")
+	}
+	if filepath.IsAbs(path) {
+		absRoot, err := filepath.Abs(root)
+		if err != nil {
+			return nil, err
+		}
+		if path, err = filepath.Rel(absRoot, path); err != nil {
+			return nil, err
+		}
+	}
+	r, err := os.OpenRoot(root)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	f, err := r.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err == nil && !info.Mode().IsRegular() {
+		err = fmt.Errorf("%s is not a regular file", path)
+	}
+	if err == nil && info.Size() > maxBytes {
+		err = fmt.Errorf("%s is %d bytes, the limit is %d bytes", path, info.Size(), maxBytes)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &uploadFile{file: f, size: info.Size(), path: path}, nil
+}
+
+// setUploadBody streams the file as the request body, reporting the bytes sent to progress if it isn't nil.
+func setUploadBody(req *http.Request, upload *uploadFile, progress func(sent int64)) {
+	req.ContentLength = upload.size
+	req.Body = withProgress(upload.file, 0, progress)
+	req.GetBody = nil // a streamed file can't be sent again, e.g. by retries
+	if upload.size == 0 {
+		upload.file.Close()
+		req.Body = http.NoBody
+	}
+}
+
+// decodeBinaryBody decodes a base64 requestBody argument (standard or URL-safe alphabet, padding optional).
+func decodeBinaryBody(v any) ([]byte, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected a base64-encoded string, got %T", v)
+	}
+	s = strings.TrimRight(strings.Join(strings.Fields(s), ""), "=")
+	if data, err := base64.RawStdEncoding.DecodeString(s); err == nil {
+		return data, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("requestBody is not valid base64")
+	}
+	return data, nil
+}
+
+// binaryContentType resolves a wildcard media type such as "image/*" from the file name or the content.
+func binaryContentType(contentType, fileName string, head []byte) string {
+	if !strings.Contains(contentType, "*") {
+		return contentType
+	}
+	if t := mime.TypeByExtension(filepath.Ext(fileName)); t != "" {
+		return t
+	}
+	if len(head) > 0 {
+		return http.DetectContentType(head)
+	}
+	return "application/octet-stream"
+}
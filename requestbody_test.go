@@ -0,0 +1,201 @@
+package openapi2mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestBinaryRequestType(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /octet:
+    put:
+      requestBody: {content: {application/octet-stream: {}}}
+      responses: {"204": {description: ok}}
+  /image:
+    put:
+      requestBody: {content: {text/plain: {schema: {type: string}}, image/png: {schema: {type: string, format: binary}}}}
+      responses: {"204": {description: ok}}
+  /json:
+    put:
+      requestBody: {content: {application/json: {schema: {type: object}}, application/octet-stream: {}}}
+      responses: {"204": {description: ok}}
+  /form:
+    put:
+      requestBody: {content: {multipart/form-data: {schema: {type: object}}}}
+      responses: {"204": {description: ok}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for path, want := range map[string]string{"/octet": "application/octet-stream", "/image": "image/png", "/json": "", "/form": ""} {
+		if got := binaryRequestType(doc.Paths.Value(path).Put.RequestBody.Value.Content); got != want {
+			t.Errorf("binaryRequestType(%s) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestRegisterOpenAPITools_BinaryRequestBody(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+servers: [{url: "https://api.example.com"}]
+paths:
+  /files/{name}:
+    put:
+      operationId: uploadFile
+      parameters: [{name: name, in: path, required: true, schema: {type: string}}]
+      requestBody: {required: true, content: {application/octet-stream: {schema: {type: string, format: binary}}}}
+      responses: {"204": {description: stored}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "data.bin"), []byte{0, 1, 2, 0xff}, 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	secret := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(secret, []byte("secret"), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var contentType string
+	var contentLength int64
+	var received []byte
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	RegisterOpenAPITools(server, ExtractOpenAPIOperations(doc), doc, &ToolGenOptions{
+		UploadRoot: root,
+		RequestHandler: func(req *http.Request) (*http.Response, error) {
+			contentType, contentLength = req.Header.Get("Content-Type"), req.ContentLength
+			received, _ = io.ReadAll(req.Body)
+			return &http.Response{StatusCode: 204, Header: http.Header{}, Body: http.NoBody, Request: req}, nil
+		},
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(context.Background(), serverTransport, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil).Connect(context.Background(), clientTransport, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cs.Close()
+
+	tools, err := cs.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	i := slices.IndexFunc(tools.Tools, func(tool *mcp.Tool) bool { return tool.Name == "uploadFile" })
+	schema := tools.Tools[i].InputSchema
+	if schema.Properties["requestBody"].ContentEncoding != "base64" || schema.Properties[requestBodyFileArgument] == nil {
+		t.Errorf("expected base64 and file arguments, got %v", schema.Properties)
+	}
+	if slices.Contains(schema.Required, "requestBody") {
+		t.Errorf("expected requestBody to be optional with requestBodyFile, got %v", schema.Required)
+	}
+
+	call := func(args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		args["name"] = "x"
+		res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "uploadFile", Arguments: args})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return res
+	}
+
+	if res := call(map[string]any{"requestBody": base64.StdEncoding.EncodeToString([]byte("hello"))}); res.IsError || string(received) != "hello" || contentType != "application/octet-stream" {
+		t.Errorf("expected the decoded bytes, got %q (%s): %v", received, contentType, res.Content)
+	}
+	if res := call(map[string]any{requestBodyFileArgument: "data.bin"}); res.IsError || string(received) != "\x00\x01\x02\xff" || contentLength != 4 {
+		t.Errorf("expected the file streamed with its length, got %q (%d): %v", received, contentLength, res.Content)
+	}
+	if res := call(map[string]any{requestBodyFileArgument: filepath.Join(root, "data.bin")}); res.IsError {
+		t.Errorf("expected an absolute path below the root to work: %v", res.Content)
+	}
+
+	// Files outside the upload root can't be sent
+	received = nil
+	for _, path := range []string{secret, "../" + filepath.Base(filepath.Dir(secret)) + "/secret"} {
+		if res := call(map[string]any{requestBodyFileArgument: path}); !res.IsError || received != nil {
+			t.Errorf("%s: expected an error, got %v", path, res.Content)
+		}
+	}
+	if err := os.Symlink(secret, filepath.Join(root, "link")); err == nil {
+		if res := call(map[string]any{requestBodyFileArgument: "link"}); !res.IsError || received != nil {
+			t.Errorf("expected an error for a symlink leaving the root, got %v", res.Content)
+		}
+	}
+	if res := call(map[string]any{"requestBody": "not base64!"}); !res.IsError || !strings.Contains(resultText(t, res), "Invalid requestBody") {
+		t.Errorf("expected an error for invalid base64, got %v", res.Content)
+	}
+}
+
+func TestRegisterOpenAPITools_NDJSONRequestBody(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+servers: [{url: "https://api.example.com"}]
+paths:
+  /_bulk:
+    post:
+      operationId: bulk
+      requestBody:
+        required: true
+        content:
+          application/x-ndjson:
+            schema: {type: object, properties: {index: {type: object}, title: {type: string}}}
+      responses: {"200": {description: ok}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var contentType string
+	var received []byte
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	RegisterOpenAPITools(server, ExtractOpenAPIOperations(doc), doc, &ToolGenOptions{
+		RequestHandler: func(req *http.Request) (*http.Response, error) {
+			contentType = req.Header.Get("Content-Type")
+			received, _ = io.ReadAll(req.Body)
+			return &http.Response{StatusCode: 200, Header: http.Header{"Content-Type": {"application/json"}}, Body: io.NopCloser(strings.NewReader(`{}`)), Request: req}, nil
+		},
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(context.Background(), serverTransport, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil).Connect(context.Background(), clientTransport, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cs.Close()
+
+	tools, err := cs.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	i := slices.IndexFunc(tools.Tools, func(tool *mcp.Tool) bool { return tool.Name == "bulk" })
+	if body := tools.Tools[i].InputSchema.Properties["requestBody"]; body.Type != "array" || body.Items == nil || body.Items.Properties["title"] == nil {
+		t.Errorf("expected an array of the documented lines, got %+v", body)
+	}
+
+	docs := []any{map[string]any{"index": map[string]any{"_id": "1"}}, map[string]any{"title": "line\nbreak"}}
+	res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "bulk", Arguments: map[string]any{"requestBody": docs}})
+	if err != nil || res.IsError {
+		t.Fatalf("unexpected error: %v %v", err, res)
+	}
+	if want := "{\"index\":{\"_id\":\"1\"}}\n{\"title\":\"line\\nbreak\"}\n"; string(received) != want || contentType != "application/x-ndjson" {
+		t.Errorf("expected one document per line, got %q (%s)", received, contentType)
+	}
+}
@@ -0,0 +1,196 @@
+// response.go
+package openapi2mcp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DefaultMaxResponseBytes is the response body size limit used when ToolGenOptions.MaxResponseBytes is 0.
+const DefaultMaxResponseBytes = 128 << 20
+
+// maxPooledBufferBytes caps the size of buffers returned to bufferPool, so that a single
+// huge response doesn't pin its buffer in memory for the lifetime of the process.
+const maxPooledBufferBytes = 4 << 20
+
+// bufferPool holds buffers used to read upstream response bodies.
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// readResponseBody reads at most limit bytes of the response body into a pooled buffer.
+// It reports whether the body was truncated. The returned bytes are only valid until release is called.
+func readResponseBody(resp *http.Response, limit int64) (body []byte, truncated bool, release func(), err error) {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	release = func() {
+		if buf.Cap() <= maxPooledBufferBytes {
+			bufferPool.Put(buf)
+		}
+	}
+
+	if resp.ContentLength > 0 {
+		buf.Grow(int(min(resp.ContentLength, limit)) + bytes.MinRead)
+	}
+	n, err := buf.ReadFrom(io.LimitReader(resp.Body, limit+1))
+	if n > limit {
+		buf.Truncate(int(limit))
+		truncated = true
+	}
+	return buf.Bytes(), truncated, release, err
+}
+
+// Transports of ToolGenOptions.TransportResponseLimits.
+const (
+	TransportStdio = "stdio" // stdio and other transports without incoming HTTP requests
+	TransportHTTP  = "http"  // streamable HTTP and SSE
+)
+
+// transportOf returns the transport a tool call arrived on.
+func transportOf(req *mcp.CallToolRequest) string {
+	if req != nil && req.Extra != nil && req.Extra.Header != nil {
+		return TransportHTTP
+	}
+	return TransportStdio
+}
+
+// truncationMetaKey is the result _meta key describing a response body that was cut off.
+const truncationMetaKey = "openapi-mcp/truncation"
+
+// ResponseTruncation describes a response body that was cut off. It is set in the result's _meta field
+// under "openapi-mcp/truncation", and a marker line with the same fields follows the body in the text.
+type ResponseTruncation struct {
+	OriginalBytes int64  `json:"original_bytes,omitempty"` // size of the complete body; 0 if unknown
+	ShownBytes    int    `json:"shown_bytes"`              // size of the part shown
+	LimitBytes    int64  `json:"limit_bytes"`              // limit the body exceeded
+	Resource      string `json:"resource,omitempty"`       // resource serving the complete body, if it was kept
+}
+
+// notice renders the marker following a cut off body, e.g.
+// "[RESPONSE TRUNCATED original_bytes=5242880 shown_bytes=1048576 limit_bytes=1048576 resource=result://1a2b]",
+// and how to get the rest.
+func (t *ResponseTruncation) notice() string {
+	original := "unknown"
+	if t.OriginalBytes > 0 {
+		original = strconv.FormatInt(t.OriginalBytes, 10)
+	}
+	marker := fmt.Sprintf("\n\n[RESPONSE TRUNCATED original_bytes=%s shown_bytes=%d limit_bytes=%d", original, t.ShownBytes, t.LimitBytes)
+	if t.Resource != "" {
+		return marker + " resource=" + t.Resource + "]\nThe complete body is available as the resource " + t.Resource + "; read it only if the part shown is not enough."
+	}
+	return marker + "]\nThe body exceeded " + formatBytes(int(t.LimitBytes)) + " and was cut off. Narrow the request (filters, pagination, fields) to get a complete response."
+}
+
+// setResultTruncation records the truncation, if any, in the result's _meta field.
+func setResultTruncation(result *mcp.CallToolResult, truncation *ResponseTruncation) {
+	if result == nil || truncation == nil {
+		return
+	}
+	if result.Meta == nil {
+		result.Meta = mcp.Meta{}
+	}
+	result.Meta[truncationMetaKey] = truncation
+}
+
+// cutBody returns the first limit bytes of body, moving the cut back so that it doesn't split a UTF-8 sequence.
+func cutBody(body []byte, limit int) []byte {
+	for i := 0; i < utf8.UTFMax-1 && limit > 0 && !utf8.RuneStart(body[limit]); i++ {
+		limit--
+	}
+	return body[:limit]
+}
+
+// formatTextResult renders the success text "HTTP <METHOD> <URL>\nStatus: <status>\nCall ID: <id>\n<headers>Response:\n<body>"
+// with a single allocation for the result. headers is a section from formatHeaderLines, or "".
+func formatTextResult(method, fullURL string, status int, callID, headers string, body []byte) string {
+	header := fmt.Sprintf("HTTP %s %s\nStatus: %d\nCall ID: %s\n%sResponse:\n", method, fullURL, status, callID, headers)
+	var sb strings.Builder
+	sb.Grow(len(header) + len(body))
+	sb.WriteString(header)
+	sb.Write(body)
+	return sb.String()
+}
+
+// formatBinaryResult renders the non-empty obj as indented JSON with an added "file_base64" field holding data.
+// The base64 encoding is streamed into the result instead of being built as a separate string and
+// copied again by json.MarshalIndent, which matters for multi-megabyte files.
+func formatBinaryResult(obj map[string]any, data []byte) string {
+	head, _ := json.MarshalIndent(obj, "", "  ")
+	head = bytes.TrimSuffix(head, []byte("\n}"))
+
+	var sb strings.Builder
+	sb.Grow(len(head) + base64.StdEncoding.EncodedLen(len(data)) + 32)
+	sb.Write(head)
+	// base64 output never needs JSON escaping
+	sb.WriteString(",\n  \"file_base64\": \"")
+	enc := base64.NewEncoder(base64.StdEncoding, &sb)
+	enc.Write(data)
+	enc.Close()
+	sb.WriteString("\"\n}")
+	return sb.String()
+}
+
+// maxFilenameBytes is the maximum length of a file name taken from Content-Disposition.
+const maxFilenameBytes = 255
+
+// responseFilename returns a safe file name for a binary response from its Content-Disposition header
+// (RFC 6266), preferring the RFC 5987 encoded filename* parameter. Directory components, control
+// characters, and reserved names are removed; "file" is returned if no usable name remains.
+func responseFilename(header http.Header) string {
+	cd := header.Get("Content-Disposition")
+	if cd == "" {
+		return "file"
+	}
+	var name string
+	if _, params, err := mime.ParseMediaType(cd); err == nil {
+		// mime decodes filename* into filename
+		name = params["filename"]
+	} else if _, raw, ok := strings.Cut(cd, "filename="); ok {
+		// Lenient fallback for headers with unquoted special characters
+		name, _, _ = strings.Cut(raw, ";")
+		name = strings.Trim(strings.TrimSpace(name), `"`)
+	}
+	return sanitizeFilename(name)
+}
+
+// sanitizeFilename reduces name to its last path component, safe to be used as a file name on any platform.
+func sanitizeFilename(name string) string {
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(`<>:"|?*`, r) {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.Trim(name, " .")
+	if len(name) > maxFilenameBytes {
+		// Keep the extension and cut whole runes
+		ext := path.Ext(name)
+		if len(ext) > maxFilenameBytes/2 {
+			ext = ""
+		}
+		stem := name[:maxFilenameBytes-len(ext)]
+		for !utf8.ValidString(stem) {
+			stem = stem[:len(stem)-1]
+		}
+		name = stem + ext
+	}
+	if name == "" {
+		return "file"
+	}
+	return name
+}
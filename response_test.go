@@ -0,0 +1,239 @@
+package openapi2mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestReadResponseBody(t *testing.T) {
+	resp, _ := fakeResponse(200, "text/plain", "0123456789")(nil)
+	body, truncated, release, err := readResponseBody(resp, 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(body) != "0123" || !truncated {
+		t.Errorf("expected truncated body 0123, got %q (truncated=%v)", body, truncated)
+	}
+	release()
+
+	resp, _ = fakeResponse(200, "text/plain", "0123")(nil)
+	body, truncated, release, _ = readResponseBody(resp, 4)
+	if string(body) != "0123" || truncated {
+		t.Errorf("expected complete body 0123, got %q (truncated=%v)", body, truncated)
+	}
+	release()
+}
+
+func TestFormatBinaryResult(t *testing.T) {
+	data := []byte{0, 1, 2, 250, 251, 252}
+	text := formatBinaryResult(map[string]any{"mime_type": "application/octet-stream", "file_name": "a.bin"}, data)
+
+	var decoded map[string]any
+	if err := json.Unmarshal([]byte(text), &decoded); err != nil {
+		t.Fatalf("expected valid JSON, got %v:\n%s", err, text)
+	}
+	if decoded["file_base64"] != base64.StdEncoding.EncodeToString(data) || decoded["file_name"] != "a.bin" {
+		t.Errorf("unexpected result: %s", text)
+	}
+}
+
+func TestResponseFilename(t *testing.T) {
+	tests := map[string]string{
+		"":                                  "file",
+		`attachment; filename="report.pdf"`: "report.pdf",
+		`attachment; filename=report.pdf; size=1`:                                     "report.pdf",
+		`attachment; filename="../../etc/passwd"`:                                     "passwd",
+		`attachment; filename="C:\\tmp\\a.txt"`:                                       "a.txt",
+		`attachment; filename="fallback.txt"; filename*=UTF-8''%E2%82%AC%20rates.txt`: "€ rates.txt",
+		`attachment; filename=my report.pdf`:                                          "my report.pdf",
+		`attachment; filename=".."`:                                                   "file",
+		"attachment; filename=\"a\x01b|c.txt\"":                                       "abc.txt",
+		"attachment; filename=\"" + strings.Repeat("ä", 200) + ".pdf\"":               strings.Repeat("ä", 125) + ".pdf",
+	}
+	for cd, want := range tests {
+		header := http.Header{}
+		if cd != "" {
+			header.Set("Content-Disposition", cd)
+		}
+		if got := responseFilename(header); got != want {
+			t.Errorf("responseFilename(%q) = %q, want %q", cd, got, want)
+		}
+	}
+}
+
+func TestToolHandler_TruncatedResponse(t *testing.T) {
+	op := OpenAPIOperation{OperationID: "getPet", Path: "/pet", Method: "get"}
+	opts := &ToolGenOptions{
+		MaxResponseBytes: 8,
+		RequestHandler:   fakeResponse(200, "application/json", `{"name": "a very long name"}`),
+	}
+	handler := toolHandler("getPet", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+	res, _, err := handler(context.Background(), nil, map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(t, res)
+	if !strings.Contains(text, "Response:\n{\"name\":\n\n[RESPONSE TRUNCATED original_bytes=unknown shown_bytes=8 limit_bytes=8]\nThe body exceeded 8 B") {
+		t.Errorf("expected truncated response, got: %s", text)
+	}
+	if truncation, ok := res.Meta[truncationMetaKey].(*ResponseTruncation); !ok || truncation.ShownBytes != 8 || truncation.Resource != "" {
+		t.Errorf("expected the truncation in _meta, got %v", res.Meta)
+	}
+}
+
+func TestToolHandler_TransportResponseLimits(t *testing.T) {
+	op := OpenAPIOperation{OperationID: "getPet", Path: "/pet", Method: "get"}
+	body := `{"name": "Zoë the very long-named dog"}`
+	opts := &ToolGenOptions{
+		TransportResponseLimits: map[string]int64{TransportStdio: 13},
+		RequestHandler:          fakeResponse(200, "application/json", body),
+	}
+	rt := newServerRuntime()
+	handler := toolHandler("getPet", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, rt)
+	res, _, err := handler(context.Background(), nil, map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The cut doesn't split the two bytes of "ë", and the complete body is kept
+	callID := res.Meta[callIDMetaKey].(string)
+	want := fmt.Sprintf("Response:\n{\"name\": \"Zo\n\n[RESPONSE TRUNCATED original_bytes=%d shown_bytes=12 limit_bytes=13 resource=result://%s]", len(body), callID)
+	if text := resultText(t, res); !strings.Contains(text, want) {
+		t.Errorf("expected the body cut off at the transport's limit, got: %s", text)
+	}
+	if stored, ok := rt.results.get(callID); !ok || string(stored.body) != body {
+		t.Errorf("expected the complete body to be kept, got %q", stored.body)
+	}
+
+	// Other transports show the complete body
+	req := &mcp.CallToolRequest{Extra: &mcp.RequestExtra{Header: http.Header{}}}
+	res, _, _ = handler(context.Background(), req, map[string]any{})
+	if text := resultText(t, res); !strings.Contains(text, body) || res.Meta[truncationMetaKey] != nil {
+		t.Errorf("expected the complete body over HTTP, got: %s", text)
+	}
+}
+
+func TestToolHandler_TransformResult(t *testing.T) {
+	op := OpenAPIOperation{OperationID: "listPets", Path: "/pets", Method: "get"}
+	opts := &ToolGenOptions{
+		RequestHandler: fakeResponse(200, "application/json", `{"data": [{"name": "Rex"}], "meta": {"page": 1}}`),
+		TransformResult: func(op OpenAPIOperation, status int, body []byte) (mcp.Content, error) {
+			var envelope struct {
+				Data json.RawMessage `json:"data"`
+			}
+			if err := json.Unmarshal(body, &envelope); err != nil {
+				return nil, err
+			}
+			return &mcp.TextContent{Text: fmt.Sprintf("%s %d: %s", op.OperationID, status, envelope.Data)}, nil
+		},
+	}
+	handler := toolHandler("listPets", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+	res, _, err := handler(context.Background(), nil, map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(t, res); text != `listPets 200: [{"name": "Rex"}]` {
+		t.Errorf("unexpected transformed result: %s", text)
+	}
+
+	// A failing transformation is reported as a tool error
+	opts.RequestHandler = fakeResponse(200, "application/json", `not json`)
+	handler = toolHandler("listPets", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+	res, _, err = handler(context.Background(), nil, map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.IsError || !strings.Contains(resultText(t, res), "failed to transform the response") {
+		t.Errorf("expected transform error, got: %+v", res)
+	}
+
+	// Error responses keep the default formatting
+	opts.RequestHandler = fakeResponse(404, "application/json", `{"message": "not found"}`)
+	handler = toolHandler("listPets", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+	res, _, _ = handler(context.Background(), nil, map[string]any{})
+	if text := resultText(t, res); !res.IsError || !strings.Contains(text, "HTTP 404") {
+		t.Errorf("expected untransformed error result, got: %s", text)
+	}
+}
+
+func TestToolHandler_Filter(t *testing.T) {
+	op := OpenAPIOperation{OperationID: "listPets", Path: "/pets", Method: "get"}
+	opts := &ToolGenOptions{RequestHandler: fakeResponse(200, "application/json", `{"items": [{"id": 1, "name": "Rex", "bio": "long"}, {"id": 2, "name": "Tom"}]}`)}
+	handler := toolHandler("listPets", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+	res, _, err := handler(context.Background(), nil, map[string]any{"__filter": "items[].name"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(t, res); !strings.HasSuffix(text, "Response:\n[\"Rex\",\"Tom\"]") {
+		t.Errorf("expected filtered response, got: %s", text)
+	}
+
+	// Invalid expressions are rejected before calling the API
+	opts.RequestHandler = func(*http.Request) (*http.Response, error) {
+		t.Fatal("unexpected upstream request")
+		return nil, nil
+	}
+	handler = toolHandler("listPets", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+	res, _, _ = handler(context.Background(), nil, map[string]any{"__filter": "items[?"})
+	if !res.IsError || !strings.Contains(resultText(t, res), "Invalid __filter argument") {
+		t.Errorf("expected invalid filter error, got: %+v", res)
+	}
+
+	// Non-JSON responses are returned in full
+	opts.RequestHandler = fakeResponse(200, "text/plain", "plain text")
+	handler = toolHandler("listPets", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+	res, _, _ = handler(context.Background(), nil, map[string]any{"__filter": "items"})
+	if text := resultText(t, res); !strings.Contains(text, "plain text") || !strings.Contains(text, "FILTER NOT APPLIED") {
+		t.Errorf("expected unfiltered response with notice, got: %s", text)
+	}
+}
+
+func BenchmarkToolHandler_LargeResponse(b *testing.B) {
+	op := OpenAPIOperation{OperationID: "getExport", Path: "/export", Method: "get"}
+	for _, size := range []int{10 << 20, 50 << 20, 100 << 20} {
+		jsonBody := []byte(`[` + strings.Repeat(`{"id": 12345, "name": "item"},`, size/30) + `{}]`)
+		binaryBody := bytes.Repeat([]byte{0xde, 0xad, 0xbe, 0xef}, size/4)
+
+		for _, tc := range []struct {
+			kind        string
+			contentType string
+			body        []byte
+		}{
+			{"json", "application/json", jsonBody},
+			{"binary", "application/octet-stream", binaryBody},
+		} {
+			b.Run(fmt.Sprintf("%s/%dMB", tc.kind, size>>20), func(b *testing.B) {
+				opts := &ToolGenOptions{
+					MaxResponseBytes: int64(len(tc.body)),
+					RequestHandler: func(req *http.Request) (*http.Response, error) {
+						return &http.Response{
+							StatusCode:    200,
+							Header:        http.Header{"Content-Type": []string{tc.contentType}},
+							ContentLength: int64(len(tc.body)),
+							Body:          io.NopCloser(bytes.NewReader(tc.body)),
+							Request:       req,
+						}, nil
+					},
+				}
+				handler := toolHandler("getExport", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+				b.SetBytes(int64(len(tc.body)))
+				b.ReportAllocs()
+				for b.Loop() {
+					if _, _, err := handler(context.Background(), nil, map[string]any{}); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// resultstore.go
+package openapi2mcp
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxStoredResults is the number of raw response bodies kept for the result://{call_id} resource.
+const maxStoredResults = 32
+
+// storedResultTTL is how long raw response bodies are kept in a Store.
+const storedResultTTL = time.Hour
+
+// resultURIPrefix is the prefix of the URIs of stored raw response bodies, followed by the call ID.
+const resultURIPrefix = "result://"
+
+// storedResult is a raw response body kept after the model was shown a converted version of it.
+type storedResult struct {
+	contentType string
+	body        []byte
+}
+
+// savedResult is a storedResult as persisted in a Store.
+type savedResult struct {
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+// resultStore keeps the raw response bodies of the most recent calls whose result shows a converted or cut off body.
+// With a Store, they are also kept under "results/<call ID>" for storedResultTTL, so that any replica of an HTTP
+// deployment serves them, also after a restart. It is safe for concurrent use.
+type resultStore struct {
+	store Store // persists the results if set
+
+	mu      sync.Mutex
+	order   []string // call IDs, oldest first
+	results map[string]storedResult
+}
+
+// newResultStore creates an empty result store.
+func newResultStore() *resultStore {
+	return &resultStore{results: make(map[string]storedResult)}
+}
+
+// put stores a copy of body for the call, evicting the oldest result beyond maxStoredResults.
+func (s *resultStore) put(callID, contentType string, body []byte) {
+	if s.store != nil {
+		storeJSON(context.Background(), s.store, "results/"+callID, savedResult{ContentType: contentType, Body: body}, storedResultTTL)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.results[callID]; !ok {
+		s.order = append(s.order, callID)
+	}
+	s.results[callID] = storedResult{contentType: contentType, body: bytes.Clone(body)}
+	for len(s.order) > maxStoredResults {
+		delete(s.results, s.order[0])
+		s.order = s.order[1:]
+	}
+}
+
+// get returns the stored result of the call, from the Store if it isn't kept in memory.
+func (s *resultStore) get(callID string) (storedResult, bool) {
+	s.mu.Lock()
+	result, ok := s.results[callID]
+	s.mu.Unlock()
+	if ok || s.store == nil {
+		return result, ok
+	}
+	var saved savedResult
+	if ok, err := loadJSON(context.Background(), s.store, "results/"+callID, &saved); err != nil || !ok {
+		return storedResult{}, false
+	}
+	return storedResult{contentType: saved.ContentType, body: saved.Body}, true
+}
+
+// registerResultResource adds the result://{call_id} resource template serving the stored raw response bodies.
+func registerResultResource(server *mcp.Server, store *resultStore) {
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: resultURIPrefix + "{call_id}",
+		Name:        "Raw Response",
+		Description: "Raw response body of a recent tool call whose result shows a converted (e.g. HTML as Markdown) or cut off version, by call ID",
+	}, func(_ context.Context, req *mcp.ServerRequest[*mcp.ReadResourceParams]) (*mcp.ReadResourceResult, error) {
+		uri := req.Params.URI
+		result, ok := store.get(strings.TrimPrefix(uri, resultURIPrefix))
+		if !ok {
+			return nil, mcp.ResourceNotFoundError(uri)
+		}
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{URI: uri, MIMEType: result.contentType, Text: string(result.body)}},
+		}, nil
+	})
+}
@@ -0,0 +1,152 @@
+// retry.go
+package openapi2mcp
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Defaults of RetryPolicy.
+const (
+	DefaultRetryAttempts = 3                      // attempts per call when RetryPolicy.MaxAttempts is 0
+	DefaultRetryBackoff  = 500 * time.Millisecond // delay before the first retry when RetryPolicy.Backoff is 0
+)
+
+// maxRetryWait is the longest Retry-After a retry waits for; responses asking for longer waits are returned as they are.
+const maxRetryWait = 30 * time.Second
+
+// RetryPolicy configures automatic retries of upstream calls that failed transiently: connection errors and
+// responses isRetriableStatus accepts (408, 425, 429, 500, 502, 503, and 504). Only idempotent methods (GET, HEAD, OPTIONS, PUT, DELETE) are retried, as
+// repeating other calls may apply them twice, unless IdempotencyKeyHeader lets the API detect repeated calls.
+// The x-mcp-retry extension of an operation (or the spec) overrides the policy: false disables retries, true
+// enables them regardless of the method, and a number sets the attempts.
+type RetryPolicy struct {
+	MaxAttempts          int           // attempts per call, including the first; 0 means DefaultRetryAttempts
+	Backoff              time.Duration // delay before the first retry, doubled for each further one; 0 means DefaultRetryBackoff
+	IdempotencyKeyHeader string        // if set, POST and PATCH calls send a unique key in this header, e.g. Idempotency-Key, and are retried too
+}
+
+// retryConfig is the retry policy of one operation.
+type retryConfig struct {
+	attempts             int
+	backoff              time.Duration
+	idempotencyKeyHeader string // header of the key sent with every attempt of a call, if any
+}
+
+// idempotentMethod reports whether repeating a request with method has the same effect as sending it once (RFC 9110).
+func idempotentMethod(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// operationRetry returns how calls to op are retried, or nil if they aren't.
+func operationRetry(opts *ToolGenOptions, op OpenAPIOperation, doc *openapi3.T) *retryConfig {
+	if opts == nil || opts.Retry == nil || opts.Mock {
+		return nil
+	}
+	cfg := &retryConfig{attempts: opts.Retry.MaxAttempts, backoff: opts.Retry.Backoff}
+	if cfg.attempts == 0 {
+		cfg.attempts = DefaultRetryAttempts
+	}
+	if cfg.backoff == 0 {
+		cfg.backoff = DefaultRetryBackoff
+	}
+	retryable := idempotentMethod(op.Method)
+	if !retryable && opts.Retry.IdempotencyKeyHeader != "" {
+		cfg.idempotencyKeyHeader = opts.Retry.IdempotencyKeyHeader
+		retryable = true
+	}
+
+	switch v := specRetry(op, doc).(type) {
+	case bool:
+		retryable = v
+	case float64:
+		cfg.attempts = int(v)
+	case int:
+		cfg.attempts = v
+	}
+	if !retryable || cfg.attempts <= 1 {
+		return nil
+	}
+	return cfg
+}
+
+// specRetry returns the x-mcp-retry extension of the operation, or of the spec if the operation has none.
+func specRetry(op OpenAPIOperation, doc *openapi3.T) any {
+	if doc == nil {
+		return nil
+	}
+	if doc.Paths != nil {
+		if item := doc.Paths.Value(op.Path); item != nil {
+			if o := item.GetOperation(strings.ToUpper(op.Method)); o != nil {
+				if v, ok := o.Extensions["x-mcp-retry"]; ok {
+					return v
+				}
+			}
+		}
+	}
+	return doc.Extensions["x-mcp-retry"]
+}
+
+// withRetries returns a request handler repeating requests next failed transiently, as configured by cfg.
+// Requests whose body can't be replayed are sent once.
+func withRetries(next func(*http.Request) (*http.Response, error), cfg *retryConfig, logger *slog.Logger, operationID string) func(*http.Request) (*http.Response, error) {
+	if cfg == nil {
+		return next
+	}
+	return func(req *http.Request) (*http.Response, error) {
+		if cfg.idempotencyKeyHeader != "" && req.Header.Get(cfg.idempotencyKeyHeader) == "" {
+			req.Header.Set(cfg.idempotencyKeyHeader, newRandomID()+newRandomID())
+		}
+		ctx := req.Context()
+		wait := cfg.backoff
+		for attempt := 1; ; attempt++ {
+			resp, err := next(req)
+			if attempt >= cfg.attempts || ctx.Err() != nil || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+				return resp, err
+			}
+			if err == nil && !isRetriableStatus(resp.StatusCode) {
+				return resp, nil
+			}
+
+			delay := wait
+			if err == nil {
+				if retryAfter := time.Duration(parseRetryAfter(resp.Header.Get("Retry-After"))) * time.Second; retryAfter > maxRetryWait {
+					return resp, nil
+				} else if retryAfter > 0 {
+					delay = retryAfter
+				}
+				io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+				logger.DebugContext(ctx, "http_retry", "operation", operationID, "attempt", attempt, "status", resp.StatusCode, "delay", delay)
+			} else {
+				logger.DebugContext(ctx, "http_retry", "operation", operationID, "attempt", attempt, "error", err, "delay", delay)
+			}
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(delay):
+			}
+			wait *= 2
+			countRetry(ctx)
+
+			retry := req.Clone(ctx)
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				retry.Body = body
+			}
+			req = retry
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package openapi2mcp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestToolHandler_Retry(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /orders:
+    get: {operationId: listOrders, responses: {"200": {description: ok}}}
+    post: {operationId: createOrder, responses: {"201": {description: created}}}
+  /reports:
+    get: {operationId: getReport, x-mcp-retry: false, responses: {"200": {description: ok}}}
+    post: {operationId: createReport, x-mcp-retry: true, responses: {"201": {description: created}}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, tc := range []struct {
+		op, method           string
+		idempotencyKeyHeader string
+		attempts             int
+	}{
+		{op: "listOrders", method: "get", attempts: 3},
+		{op: "createOrder", method: "post", attempts: 1},
+		{op: "createOrder", method: "post", idempotencyKeyHeader: "Idempotency-Key", attempts: 3},
+		{op: "getReport", method: "get", attempts: 1},
+		{op: "createReport", method: "post", attempts: 3},
+	} {
+		var attempts int
+		var keys []string
+		opts := &ToolGenOptions{
+			Retry: &RetryPolicy{Backoff: time.Millisecond, IdempotencyKeyHeader: tc.idempotencyKeyHeader},
+			RequestHandler: func(req *http.Request) (*http.Response, error) {
+				attempts++
+				keys = append(keys, req.Header.Get("Idempotency-Key"))
+				return &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+			},
+		}
+		path := "/orders"
+		if strings.Contains(tc.op, "Report") {
+			path = "/reports"
+		}
+		op := OpenAPIOperation{OperationID: tc.op, Path: path, Method: tc.method}
+		rt := newServerRuntime()
+		handler := toolHandler(tc.op, op, doc, jsonschema.Schema{}, []string{"http://example.com"}, opts, rt)
+		if _, _, err := handler(context.Background(), nil, map[string]any{}); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.op, err)
+		}
+		if attempts != tc.attempts {
+			t.Errorf("%s (idempotency key header %q): %d attempts, want %d", tc.op, tc.idempotencyKeyHeader, attempts, tc.attempts)
+		}
+		if retries := rt.stats.snapshot()[tc.op].Retries; retries != tc.attempts-1 {
+			t.Errorf("%s: %d retries in the stats, want %d", tc.op, retries, tc.attempts-1)
+		}
+		if tc.idempotencyKeyHeader != "" && (keys[0] == "" || keys[0] != keys[len(keys)-1]) {
+			t.Errorf("%s: expected the same idempotency key on every attempt, got %q", tc.op, keys)
+		}
+	}
+}
+
+func TestWithRetries_RetryAfter(t *testing.T) {
+	var attempts int
+	next := func(req *http.Request) (*http.Response, error) {
+		attempts++
+		status := http.StatusTooManyRequests
+		if attempts == 2 {
+			status = http.StatusOK
+		}
+		return &http.Response{StatusCode: status, Header: http.Header{"Retry-After": []string{"0"}}, Body: io.NopCloser(strings.NewReader(""))}, nil
+	}
+	handler := withRetries(next, &retryConfig{attempts: 5, backoff: time.Millisecond}, newLogger(nil), "listOrders")
+	req, _ := http.NewRequest(http.MethodPost, "http://example.com/orders", strings.NewReader(`{"id":1}`))
+	resp, err := handler(req)
+	if err != nil || resp.StatusCode != http.StatusOK || attempts != 2 {
+		t.Fatalf("expected success on the second attempt, got %v after %d attempts", err, attempts)
+	}
+
+	// Waits longer than maxRetryWait are left to the model
+	attempts = 0
+	next = func(req *http.Request) (*http.Response, error) {
+		attempts++
+		return &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"3600"}}, Body: io.NopCloser(strings.NewReader(""))}, nil
+	}
+	if resp, _ := withRetries(next, &retryConfig{attempts: 5, backoff: time.Millisecond}, newLogger(nil), "listOrders")(req); resp.StatusCode != http.StatusTooManyRequests || attempts != 1 {
+		t.Errorf("expected no retry for a long Retry-After, got %d attempts", attempts)
+	}
+}
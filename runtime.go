@@ -0,0 +1,36 @@
+// runtime.go
+package openapi2mcp
+
+// serverRuntime holds state shared by all tools registered in a single RegisterOpenAPITools call.
+type serverRuntime struct {
+	stats    *statsRegistry
+	ops      *OperationRegistry
+	hosts    *hostGuard   // nil if requests may go to any host
+	results  *resultStore // raw bodies of results showing converted ones
+	limits   *rateLimiter
+	sessions *sessionStore // cookies and CSRF tokens by MCP session
+	details  *toolDetailStore
+	inflight *inflightGroup     // identical GET requests in flight, by session
+	slots    *callSlots         // slots of the concurrency limits
+	groups   *callGroups        // open call groups, by session
+	tokens   *oauthTokens       // OAuth2 access tokens of the client credentials grant
+	login    *oauthLoginSession // the token of the signed in user; nil without OAuthLogin
+	audit    *auditLog          // audit records waiting to be written to the Store
+}
+
+// newServerRuntime creates the shared runtime state for a set of tools.
+func newServerRuntime() *serverRuntime {
+	return &serverRuntime{
+		stats:    newStatsRegistry(),
+		ops:      newOperationRegistry(nil),
+		results:  newResultStore(),
+		limits:   newRateLimiter(),
+		sessions: newSessionStore(),
+		details:  newToolDetailStore(),
+		inflight: newInflightGroup(),
+		slots:    newCallSlots(),
+		groups:   newCallGroups(),
+		tokens:   newOAuthTokens(),
+		audit:    newAuditLog(),
+	}
+}
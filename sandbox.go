@@ -0,0 +1,69 @@
+// sandbox.go
+package openapi2mcp
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// sandboxMetaKey is the result _meta key marking calls routed to the sandbox.
+const sandboxMetaKey = "openapi-mcp/sandbox"
+
+// SandboxRouting sends some calls to a sandbox base URL instead of the production API, e.g. Stripe's test mode, so that
+// agents can be tried out safely with the tools they'll use in production. Calls are routed to the sandbox if their
+// operation matches Operations, if the MCP session asked for it with SessionHeader, or, for a Percent share of the
+// other sessions, as a canary. Sessions are assigned to the canary by their ID, so that all of a session's calls go
+// to the same API.
+type SandboxRouting struct {
+	URL           string            // base URL of the sandbox
+	Operations    []string          // path.Match patterns of operationIds always routed to the sandbox
+	Percent       float64           // share of sessions, 0 to 100, whose calls are routed to the sandbox
+	SessionHeader string            // incoming HTTP header routing a session's calls to the sandbox if true, e.g. "X-Sandbox"
+	Headers       map[string]string // headers replacing those of sandboxed requests, e.g. Authorization with test credentials
+}
+
+// routes reports whether the call of op made in session with the request req goes to the sandbox.
+func (s *SandboxRouting) routes(op OpenAPIOperation, req *mcp.CallToolRequest) bool {
+	if s == nil || s.URL == "" {
+		return false
+	}
+	if matchAny(s.Operations, op.OperationID) {
+		return true
+	}
+	if s.SessionHeader != "" && req != nil && req.Extra != nil && req.Extra.Header != nil {
+		if on, err := strconv.ParseBool(req.Extra.Header.Get(s.SessionHeader)); err == nil {
+			return on
+		}
+	}
+	if s.Percent <= 0 {
+		return false
+	}
+	var session mcp.Session
+	if req != nil {
+		session = req.Session
+	}
+	id := sessionCorrelationID(session)
+	if id == "" {
+		return rand.Float64()*100 < s.Percent
+	}
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	return float64(h.Sum32()%10000) < s.Percent*100
+}
+
+// apply sets the sandbox headers on a request routed to the sandbox.
+func (s *SandboxRouting) apply(httpReq *http.Request) {
+	for name, value := range s.Headers {
+		httpReq.Header.Set(name, value)
+	}
+}
+
+// sandboxText tells the model that a call went to the sandbox, so that it doesn't take its effects for real ones.
+func sandboxText(url string) string {
+	return "SANDBOX: This call was sent to the sandbox API at " + strings.TrimSuffix(url, "/") + ", not to production; its effects are not real."
+}
@@ -0,0 +1,74 @@
+package openapi2mcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestSandboxRouting_Routes(t *testing.T) {
+	sandbox := &SandboxRouting{URL: "https://sandbox.example.com", Operations: []string{"create*"}, SessionHeader: "X-Sandbox"}
+	withHeader := func(value string) *mcp.CallToolRequest {
+		return &mcp.CallToolRequest{Extra: &mcp.RequestExtra{Header: http.Header{"X-Sandbox": {value}}}}
+	}
+	for _, tt := range []struct {
+		operationID string
+		req         *mcp.CallToolRequest
+		want        bool
+	}{
+		{"createRefund", nil, true},
+		{"listCharges", nil, false},
+		{"listCharges", withHeader("true"), true},
+		{"listCharges", withHeader("false"), false},
+		{"listCharges", withHeader("maybe"), false},
+	} {
+		if got := sandbox.routes(OpenAPIOperation{OperationID: tt.operationID}, tt.req); got != tt.want {
+			t.Errorf("routes(%s, %v) = %v, want %v", tt.operationID, tt.req, got, tt.want)
+		}
+	}
+
+	var nilSandbox *SandboxRouting
+	if nilSandbox.routes(OpenAPIOperation{OperationID: "createRefund"}, nil) {
+		t.Error("expected no routing without a sandbox")
+	}
+	if !(&SandboxRouting{URL: "https://sandbox.example.com", Percent: 100}).routes(OpenAPIOperation{}, nil) {
+		t.Error("expected all calls to be routed at 100 percent")
+	}
+}
+
+func TestToolHandler_Sandbox(t *testing.T) {
+	var sent []string
+	opts := &ToolGenOptions{
+		Sandbox: &SandboxRouting{
+			URL:        "https://sandbox.example.com",
+			Operations: []string{"createRefund"},
+			Headers:    map[string]string{"Authorization": "Bearer sk_test"},
+		},
+		RequestHandler: func(req *http.Request) (*http.Response, error) {
+			sent = append(sent, fmt.Sprintf("%s %s", req.URL, req.Header.Get("Authorization")))
+			return fakeResponse(200, "application/json", `{}`)(req)
+		},
+	}
+	rt := newServerRuntime()
+	for _, id := range []string{"createRefund", "listCharges"} {
+		handler := toolHandler(id, OpenAPIOperation{OperationID: id, Path: "/" + id, Method: "get"}, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"https://api.example.com"}, opts, rt)
+		res, _, err := handler(context.Background(), nil, map[string]any{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		sandboxed, _ := res.Meta[sandboxMetaKey].(bool)
+		note, _ := res.Content[len(res.Content)-1].(*mcp.TextContent)
+		if sandboxed != (id == "createRefund") || strings.HasPrefix(note.Text, "SANDBOX:") != sandboxed {
+			t.Errorf("%s: unexpected sandbox marking, meta %v: %s", id, res.Meta, note.Text)
+		}
+	}
+	want := []string{"https://sandbox.example.com/createRefund Bearer sk_test", "https://api.example.com/listCharges "}
+	if strings.Join(sent, "|") != strings.Join(want, "|") {
+		t.Errorf("expected requests %q, got %q", want, sent)
+	}
+}
@@ -0,0 +1,357 @@
+// schedule.go
+package openapi2mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxScheduleDelay caps how far in the future calls can be scheduled.
+const maxScheduleDelay = 7 * 24 * time.Hour
+
+// maxScheduledCalls caps the scheduled calls kept; beyond it, the oldest completed calls are forgotten.
+const maxScheduledCalls = 256
+
+// storedScheduleTTL is how long scheduled calls are kept in a Store, long enough to list them after they ran.
+const storedScheduleTTL = maxScheduleDelay + 24*time.Hour
+
+// scheduleOverdue is how long after its run time a call kept in a Store may still be pending on another replica.
+const scheduleOverdue = time.Minute
+
+// Status values of scheduled calls.
+const (
+	scheduledPending   = "pending"
+	scheduledRunning   = "running"
+	scheduledDone      = "done"
+	scheduledFailed    = "failed"
+	scheduledCancelled = "cancelled"
+)
+
+// scheduledCall is an operation tool call queued by schedule_call, as listed by list_scheduled.
+type scheduledCall struct {
+	ID        string         `json:"id"`
+	Tool      string         `json:"tool"`
+	Arguments map[string]any `json:"arguments,omitempty"`
+	RunAt     time.Time      `json:"run_at"`
+	Status    string         `json:"status"`           // pending, running, done, failed, or cancelled
+	Result    string         `json:"result,omitempty"` // text of the tool result, or the error, once completed
+
+	session string               // correlation ID of the MCP session that scheduled the call, the only one seeing it
+	req     *mcp.CallToolRequest // the schedule_call request, on whose behalf the call runs
+	timer   *time.Timer
+}
+
+// savedScheduledCall is a scheduledCall as persisted in a Store.
+type savedScheduledCall struct {
+	scheduledCall
+	Session string `json:"session"`
+}
+
+// callScheduler runs operation tool calls at a later time, on behalf of the request that scheduled them, so that
+// the policy, forwarded headers, sandbox routing, and session cookies apply as if the call were made directly. With
+// a Store, the calls are also kept under "schedules/<id>", so that the replicas of an HTTP deployment list and cancel
+// each other's calls. Only the replica a call was scheduled on runs it, as the others don't have its request; calls
+// of a replica that stopped are listed as failed once they are overdue. It is safe for concurrent use.
+type callScheduler struct {
+	ops   *OperationRegistry
+	store Store // persists the calls if set
+	now   func() time.Time
+
+	mu    sync.Mutex
+	calls []*scheduledCall // oldest first
+}
+
+// newCallScheduler creates a scheduler calling the operation tools of ops, keeping the calls in store if set.
+func newCallScheduler(ops *OperationRegistry, store Store) *callScheduler {
+	return &callScheduler{ops: ops, store: store, now: time.Now}
+}
+
+// scheduleKey returns the store key of the call with the ID.
+func scheduleKey(id string) string {
+	return "schedules/" + id
+}
+
+// save writes the call to the store, if any.
+func (s *callScheduler) save(call scheduledCall) {
+	if s.store != nil {
+		storeJSON(context.Background(), s.store, scheduleKey(call.ID), savedScheduledCall{call, call.session}, storedScheduleTTL)
+	}
+}
+
+// load reads the call with the ID from the store, if any.
+func (s *callScheduler) load(id string) (scheduledCall, bool) {
+	var saved savedScheduledCall
+	if s.store == nil {
+		return saved.scheduledCall, false
+	}
+	ok, err := loadJSON(context.Background(), s.store, scheduleKey(id), &saved)
+	saved.session = saved.Session
+	return saved.scheduledCall, ok && err == nil
+}
+
+// stored returns the calls of the store, by run time. Pending calls overdue by more than scheduleOverdue were
+// scheduled on a replica that stopped, and are returned as failed.
+func (s *callScheduler) stored() []scheduledCall {
+	if s.store == nil {
+		return nil
+	}
+	keys, _ := s.store.Keys(context.Background(), scheduleKey(""))
+	var calls []scheduledCall
+	for _, key := range keys {
+		call, ok := s.load(strings.TrimPrefix(key, scheduleKey("")))
+		if !ok {
+			continue
+		}
+		if call.Status == scheduledPending && s.now().Sub(call.RunAt) > scheduleOverdue {
+			call.Status, call.Result = scheduledFailed, "not run: the server it was scheduled on stopped; schedule the call again"
+		}
+		calls = append(calls, call)
+	}
+	slices.SortFunc(calls, func(a, b scheduledCall) int { return a.RunAt.Compare(b.RunAt) })
+	return calls
+}
+
+// schedule queues a call of the operation tool with args to run at runAt, or at once if runAt has passed, on behalf
+// of the request req; only the session of req lists and cancels it.
+func (s *callScheduler) schedule(req *mcp.CallToolRequest, tool string, args map[string]any, runAt time.Time) (scheduledCall, error) {
+	if _, ok := s.ops.Operation(tool); !ok {
+		return scheduledCall{}, fmt.Errorf("unknown operation tool %q", tool)
+	}
+	delay := runAt.Sub(s.now())
+	if delay > maxScheduleDelay {
+		return scheduledCall{}, fmt.Errorf("calls can be scheduled at most %s ahead", maxScheduleDelay)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.calls) >= maxScheduledCalls {
+		i := slices.IndexFunc(s.calls, func(c *scheduledCall) bool {
+			return c.Status != scheduledPending && c.Status != scheduledRunning
+		})
+		if i < 0 {
+			return scheduledCall{}, fmt.Errorf("%d calls are scheduled already; cancel some first", len(s.calls))
+		}
+		s.calls = slices.Delete(s.calls, i, i+1)
+	}
+	call := &scheduledCall{ID: newRandomID(), Tool: tool, Arguments: args, RunAt: runAt, Status: scheduledPending, session: requestSessionID(req), req: req}
+	s.save(*call)
+	call.timer = time.AfterFunc(max(delay, 0), func() { s.run(call) })
+	s.calls = append(s.calls, call)
+	return *call, nil
+}
+
+// run calls the operation tool of call, unless it was cancelled, also by another replica, and records the result.
+func (s *callScheduler) run(call *scheduledCall) {
+	stored, inStore := s.load(call.ID)
+	s.mu.Lock()
+	if inStore && stored.Status != scheduledPending && call.Status == scheduledPending {
+		call.Status, call.Result = stored.Status, stored.Result
+	}
+	if call.Status != scheduledPending {
+		s.mu.Unlock()
+		return
+	}
+	call.Status = scheduledRunning
+	running := *call
+	s.mu.Unlock()
+	s.save(running)
+
+	res, err := s.ops.callAs(context.Background(), call.req, call.Tool, call.Arguments)
+
+	s.mu.Lock()
+	defer func() {
+		completed := *call
+		s.mu.Unlock()
+		s.save(completed)
+	}()
+	switch {
+	case err != nil:
+		call.Status, call.Result = scheduledFailed, err.Error()
+	case res == nil:
+		call.Status = scheduledDone
+	default:
+		var texts []string
+		for _, c := range res.Content {
+			if text, ok := c.(*mcp.TextContent); ok {
+				texts = append(texts, text.Text)
+			}
+		}
+		call.Status, call.Result = scheduledDone, strings.Join(texts, "\n\n")
+		if res.IsError {
+			call.Status = scheduledFailed
+		}
+	}
+}
+
+// list returns the calls scheduled by the session, oldest first, followed by those only in the store, by run time.
+// The store has the latest status of calls run or cancelled by other replicas.
+func (s *callScheduler) list(session string) []scheduledCall {
+	stored := s.stored()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var calls []scheduledCall
+	local := make(map[string]int, len(s.calls))
+	for _, c := range s.calls {
+		if c.session == session {
+			local[c.ID] = len(calls)
+			calls = append(calls, *c)
+		}
+	}
+	for _, c := range stored {
+		if c.session != session {
+			continue
+		}
+		if i, ok := local[c.ID]; !ok {
+			calls = append(calls, c)
+		} else if calls[i].Status == scheduledPending {
+			calls[i].Status, calls[i].Result = c.Status, c.Result
+		}
+	}
+	return calls
+}
+
+// cancel stops the pending call with the ID the session scheduled from running, also if another replica scheduled it.
+func (s *callScheduler) cancel(session, id string) (scheduledCall, error) {
+	stored, inStore := s.load(id)
+	inStore = inStore && stored.session == session
+	s.mu.Lock()
+	i := slices.IndexFunc(s.calls, func(c *scheduledCall) bool { return c.ID == id && c.session == session })
+	if i < 0 {
+		s.mu.Unlock()
+		if !inStore {
+			return scheduledCall{}, fmt.Errorf("no scheduled call with id %q", id)
+		}
+		if stored.Status != scheduledPending {
+			return stored, fmt.Errorf("the call is %s already and can't be cancelled", stored.Status)
+		}
+		stored.Status = scheduledCancelled
+		s.save(stored)
+		return stored, nil
+	}
+	call := s.calls[i]
+	if inStore && call.Status == scheduledPending {
+		call.Status, call.Result = stored.Status, stored.Result
+	}
+	if call.Status != scheduledPending {
+		s.mu.Unlock()
+		return *call, fmt.Errorf("the call is %s already and can't be cancelled", call.Status)
+	}
+	call.timer.Stop()
+	call.Status = scheduledCancelled
+	cancelled := *call
+	s.mu.Unlock()
+	s.save(cancelled)
+	return cancelled, nil
+}
+
+// scheduledRunAt returns when a call scheduled with the delay_seconds or at argument runs.
+func scheduledRunAt(args map[string]any, now time.Time) (time.Time, error) {
+	delay, hasDelay := args["delay_seconds"].(float64)
+	at, hasAt := args["at"].(string)
+	switch {
+	case hasDelay && hasAt:
+		return time.Time{}, errors.New("set either delay_seconds or at, not both")
+	case hasDelay:
+		if delay < 0 {
+			return time.Time{}, errors.New("delay_seconds must not be negative")
+		}
+		// Checked before the conversion, which overflows for huge values
+		if math.IsNaN(delay) || delay > maxScheduleDelay.Seconds() {
+			return time.Time{}, fmt.Errorf("delay_seconds must be at most %g (%s)", maxScheduleDelay.Seconds(), maxScheduleDelay)
+		}
+		return now.Add(time.Duration(delay * float64(time.Second))), nil
+	case hasAt:
+		t, err := time.Parse(time.RFC3339, at)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("at must be an RFC 3339 timestamp such as 2025-01-31T18:00:00Z: %v", err)
+		}
+		return t, nil
+	}
+	return time.Time{}, errors.New("set delay_seconds or at")
+}
+
+// registerScheduleTools adds the schedule_call, list_scheduled, and cancel_scheduled tools, running operation tool
+// calls with scheduler.
+func registerScheduleTools(server *mcp.Server, scheduler *callScheduler, opts *ToolGenOptions) {
+	var annotations *mcp.ToolAnnotations
+	if opts != nil && opts.Version != "" {
+		annotations = &mcp.ToolAnnotations{Title: "OpenAPI " + opts.Version}
+	}
+	textResult := func(text string, isError bool) *mcp.CallToolResult {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}, IsError: isError}
+	}
+	jsonResult := func(v any) *mcp.CallToolResult {
+		text, _ := json.MarshalIndent(v, "", "  ")
+		return textResult(string(text), false)
+	}
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name: "schedule_call",
+		Description: "Queue a call of an API tool to run later, after a delay or at a time, e.g. to wait for a rate limit " +
+			"to reset or for a time of day. The call runs with the given arguments as if made then (include " +
+			"\"__confirmed\": true for actions needing confirmation); check its result with list_scheduled.",
+		InputSchema: &jsonschema.Schema{Type: "object", Properties: map[string]*jsonschema.Schema{
+			"tool":          {Type: "string", Description: "Name of the API tool to call."},
+			"arguments":     {Type: "object", Description: "Arguments of the call."},
+			"delay_seconds": {Type: "number", Description: fmt.Sprintf("Run the call this many seconds from now, at most %g.", maxScheduleDelay.Seconds())},
+			"at":            {Type: "string", Format: "date-time", Description: "Run the call at this RFC 3339 time instead, e.g. 2025-01-31T18:00:00Z."},
+		}, Required: []string{"tool"}},
+		Annotations: annotations,
+	}, func(_ context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+		tool, _ := args["tool"].(string)
+		arguments, _ := args["arguments"].(map[string]any)
+		runAt, err := scheduledRunAt(args, scheduler.now())
+		if err != nil {
+			return textResult("Cannot schedule the call: "+err.Error(), true), nil, nil
+		}
+		call, err := scheduler.schedule(req, tool, arguments, runAt)
+		if err != nil {
+			return textResult("Cannot schedule the call: "+err.Error(), true), nil, nil
+		}
+		return jsonResult(call), nil, nil
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "list_scheduled",
+		Description: "List the calls this session queued with schedule_call, oldest first, with their status (pending, running, done, failed, or cancelled) and, once completed, their result.",
+		InputSchema: &jsonschema.Schema{Type: "object", Properties: map[string]*jsonschema.Schema{
+			"status": {Type: "string", Enum: []any{scheduledPending, scheduledRunning, scheduledDone, scheduledFailed, scheduledCancelled}, Description: "Only list calls with this status."},
+		}},
+		Annotations: annotations,
+	}, func(_ context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+		status, _ := args["status"].(string)
+		calls := []scheduledCall{}
+		for _, call := range scheduler.list(requestSessionID(req)) {
+			if status == "" || call.Status == status {
+				calls = append(calls, call)
+			}
+		}
+		return jsonResult(calls), nil, nil
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "cancel_scheduled",
+		Description: "Cancel a pending call this session queued with schedule_call, by its id.",
+		InputSchema: &jsonschema.Schema{Type: "object", Properties: map[string]*jsonschema.Schema{
+			"id": {Type: "string", Description: "ID of the scheduled call, as returned by schedule_call."},
+		}, Required: []string{"id"}},
+		Annotations: annotations,
+	}, func(_ context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+		id, _ := args["id"].(string)
+		call, err := scheduler.cancel(requestSessionID(req), id)
+		if err != nil {
+			return textResult("Cannot cancel the call: "+err.Error(), true), nil, nil
+		}
+		return jsonResult(call), nil, nil
+	})
+}
@@ -0,0 +1,215 @@
+package openapi2mcp
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestCallScheduler(t *testing.T) {
+	var calls atomic.Int32
+	registry := newOperationRegistry(nil)
+	registry.add("listPets", OpenAPIOperation{OperationID: "listPets"}, func(_ context.Context, _ *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+		calls.Add(1)
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "pets of page " + args["page"].(string)}}}, nil, nil
+	})
+	scheduler := newCallScheduler(registry, nil)
+	now := time.Now()
+
+	call, err := scheduler.schedule(nil, "listPets", map[string]any{"page": "2"}, now.Add(10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	later, err := scheduler.schedule(nil, "listPets", map[string]any{"page": "3"}, now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cancelled, err := scheduler.cancel("", later.ID); err != nil || cancelled.Status != scheduledCancelled {
+		t.Errorf("expected the call to be cancelled, got %+v, %v", cancelled, err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for scheduler.list("")[0].Status != scheduledDone && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	listed := scheduler.list("")
+	if listed[0].ID != call.ID || listed[0].Status != scheduledDone || listed[0].Result != "pets of page 2" {
+		t.Errorf("expected the call to have run, got %+v", listed[0])
+	}
+	if _, err := scheduler.cancel("", call.ID); err == nil {
+		t.Error("expected completed calls not to be cancellable")
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected only the pending call to run, got %d calls", n)
+	}
+
+	if _, err := scheduler.schedule(nil, "deletePets", nil, now); err == nil || !strings.Contains(err.Error(), "unknown operation tool") {
+		t.Errorf("expected unknown tools to be rejected, got %v", err)
+	}
+	if _, err := scheduler.schedule(nil, "listPets", nil, now.Add(maxScheduleDelay+time.Hour)); err == nil {
+		t.Error("expected calls too far ahead to be rejected")
+	}
+}
+
+func TestCallScheduler_OnBehalfOfRequest(t *testing.T) {
+	got := make(chan *mcp.CallToolRequest, 1)
+	registry := newOperationRegistry(nil)
+	registry.add("listPets", OpenAPIOperation{OperationID: "listPets"}, func(_ context.Context, req *mcp.CallToolRequest, _ map[string]any) (*mcp.CallToolResult, any, error) {
+		got <- req
+		return &mcp.CallToolResult{}, nil, nil
+	})
+	req := &mcp.CallToolRequest{Extra: &mcp.RequestExtra{Header: http.Header{"X-User": {"alice"}}}}
+	if _, err := newCallScheduler(registry, nil).schedule(req, "listPets", nil, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case r := <-got:
+		if r != req {
+			t.Errorf("expected the call to run on behalf of the scheduling request, got %+v", r)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the call to run")
+	}
+}
+
+func TestCallScheduler_OverdueOnStoppedReplica(t *testing.T) {
+	store := NewMemoryStore()
+	storeJSON(context.Background(), store, scheduleKey("abc"), scheduledCall{ID: "abc", Tool: "listPets", RunAt: time.Now().Add(-time.Hour), Status: scheduledPending}, time.Hour)
+	listed := newCallScheduler(newOperationRegistry(nil), store).list("")
+	if len(listed) != 1 || listed[0].Status != scheduledFailed || !strings.Contains(listed[0].Result, "stopped") {
+		t.Errorf("expected the overdue call to be listed as failed, got %+v", listed)
+	}
+}
+
+func TestScheduledRunAt(t *testing.T) {
+	now := time.Date(2025, 1, 31, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		args    map[string]any
+		want    time.Time
+		wantErr bool
+	}{
+		{map[string]any{"delay_seconds": 90.0}, now.Add(90 * time.Second), false},
+		{map[string]any{"at": "2025-01-31T18:00:00Z"}, now.Add(6 * time.Hour), false},
+		{map[string]any{"at": "tomorrow"}, time.Time{}, true},
+		{map[string]any{"delay_seconds": -1.0}, time.Time{}, true},
+		{map[string]any{"delay_seconds": 1e12}, time.Time{}, true},
+		{map[string]any{"delay_seconds": math.Inf(1)}, time.Time{}, true},
+		{map[string]any{"delay_seconds": math.NaN()}, time.Time{}, true},
+		{map[string]any{"delay_seconds": maxScheduleDelay.Seconds()}, now.Add(maxScheduleDelay), false},
+		{map[string]any{"delay_seconds": 1.0, "at": "2025-01-31T18:00:00Z"}, time.Time{}, true},
+		{map[string]any{}, time.Time{}, true},
+	}
+	for _, tt := range tests {
+		got, err := scheduledRunAt(tt.args, now)
+		if (err != nil) != tt.wantErr || !got.Equal(tt.want) {
+			t.Errorf("%v: expected %v (error: %v), got %v, %v", tt.args, tt.want, tt.wantErr, got, err)
+		}
+	}
+}
+
+func TestRegisterOpenAPITools_ScheduleCall(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      responses: {"200": {description: ok}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	names := RegisterOpenAPITools(server, ExtractOpenAPIOperations(doc), doc, &ToolGenOptions{
+		ScheduledCalls: true,
+		BaseURL:        "http://example.com",
+		RequestHandler: func(req *http.Request) (*http.Response, error) {
+			return fakeResponse(200, "application/json", `[{"name": "Rex"}]`)(req)
+		},
+	})
+	for _, name := range []string{"schedule_call", "list_scheduled", "cancel_scheduled"} {
+		if !strings.Contains(strings.Join(names, " "), name) {
+			t.Errorf("expected the %s tool, got %v", name, names)
+		}
+	}
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(context.Background(), serverTransport, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil).Connect(context.Background(), clientTransport, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cs.Close()
+
+	// A delay beyond the limit is a tool error rather than a call scheduled in the past
+	res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "schedule_call", Arguments: map[string]any{"tool": "listPets", "delay_seconds": 1e12}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !res.IsError || !strings.Contains(resultText(t, res), "delay_seconds must be at most") {
+		t.Errorf("expected the delay to be rejected, got: %s", resultText(t, res))
+	}
+
+	res, err = cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "schedule_call", Arguments: map[string]any{"tool": "listPets", "delay_seconds": 0}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var call scheduledCall
+	if err := json.Unmarshal([]byte(resultText(t, res)), &call); err != nil || call.ID == "" {
+		t.Fatalf("expected the scheduled call, got %s (%v)", resultText(t, res), err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		res, err = cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "list_scheduled", Arguments: map[string]any{"status": "done"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if text := resultText(t, res); strings.Contains(text, "Rex") || time.Now().After(deadline) {
+			if !strings.Contains(text, call.ID) || !strings.Contains(text, "Rex") {
+				t.Errorf("expected the completed call with its result, got: %s", text)
+			}
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestCallScheduler_ScopedToSession(t *testing.T) {
+	registry := newOperationRegistry(nil)
+	registry.add("listPets", OpenAPIOperation{OperationID: "listPets"}, func(context.Context, *mcp.CallToolRequest, map[string]any) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{}, nil, nil
+	})
+	store := NewMemoryStore()
+	scheduler := newCallScheduler(registry, store)
+	alice, bob := &mcp.CallToolRequest{Session: new(mcp.ServerSession)}, &mcp.CallToolRequest{Session: new(mcp.ServerSession)}
+	call, err := scheduler.schedule(alice, "listPets", nil, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Other sessions, also on other replicas, neither list nor cancel the call
+	for _, s := range []*callScheduler{scheduler, newCallScheduler(registry, store)} {
+		if listed := s.list(requestSessionID(bob)); len(listed) != 0 {
+			t.Errorf("expected other sessions not to list the call, got %+v", listed)
+		}
+		if _, err := s.cancel(requestSessionID(bob), call.ID); err == nil {
+			t.Error("expected other sessions not to cancel the call")
+		}
+	}
+	if listed := scheduler.list(requestSessionID(alice)); len(listed) != 1 || listed[0].ID != call.ID {
+		t.Errorf("expected the session to list its call, got %+v", listed)
+	}
+	if cancelled, err := newCallScheduler(registry, store).cancel(requestSessionID(alice), call.ID); err != nil || cancelled.Status != scheduledCancelled {
+		t.Errorf("expected the session to cancel its call on another replica, got %+v, %v", cancelled, err)
+	}
+}
@@ -4,8 +4,11 @@ package openapi2mcp
 import (
 	"encoding/json"
 	"fmt"
-	"os"
+	"log/slog"
+	"maps"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/google/jsonschema-go/jsonschema"
@@ -48,45 +51,102 @@ func buildParameterNameMapping(params openapi3.Parameters) map[string]string {
 	return mapping
 }
 
+// schemaCache shares converted component schemas (those referenced via $ref) between tools,
+// so that a component used by many operations is held in memory once instead of once per tool.
+// Cached schemas must not be modified; a nil cache disables sharing.
+// Warnings about unsupported schema features go to logger (stderr if nil).
+type schemaCache struct {
+	mu      sync.Mutex
+	schemas map[*openapi3.Schema]*jsonschema.Schema
+	logger  *slog.Logger
+}
+
+func newSchemaCache(logger *slog.Logger) *schemaCache {
+	return &schemaCache{schemas: make(map[*openapi3.Schema]*jsonschema.Schema), logger: logger}
+}
+
+// warnf reports an unsupported schema feature to the cache's logger.
+func (c *schemaCache) warnf(format string, args ...any) {
+	var logger *slog.Logger
+	if c != nil {
+		logger = c.logger
+	}
+	warnf(logger, format, args...)
+}
+
+func (c *schemaCache) get(val *openapi3.Schema) *jsonschema.Schema {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.schemas[val]
+}
+
+// put stores prop for val unless another goroutine got there first, and returns the cached schema.
+func (c *schemaCache) put(val *openapi3.Schema, prop *jsonschema.Schema) *jsonschema.Schema {
+	if c == nil {
+		return prop
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.schemas[val]; ok {
+		return cached
+	}
+	c.schemas[val] = prop
+	return prop
+}
+
 // extractProperty recursively extracts a property schema from an OpenAPI SchemaRef.
 // Handles allOf, oneOf, anyOf, discriminator, default, example, and basic OpenAPI 3.1 features.
-func extractProperty(s *openapi3.SchemaRef) *jsonschema.Schema {
+// Component schemas ($ref) are shared through cache, if non-nil.
+func extractProperty(s *openapi3.SchemaRef, cache *schemaCache) *jsonschema.Schema {
 	if s == nil || s.Value == nil {
 		return nil
 	}
-	val := s.Value
+	if s.Ref != "" {
+		if cached := cache.get(s.Value); cached != nil {
+			return cached
+		}
+		return cache.put(s.Value, convertSchema(s.Value, cache))
+	}
+	return convertSchema(s.Value, cache)
+}
+
+// convertSchema converts a single OpenAPI schema to a JSON Schema.
+func convertSchema(val *openapi3.Schema, cache *schemaCache) *jsonschema.Schema {
 	prop := &jsonschema.Schema{}
 
 	// Handle allOf (merge all subschemas)
 	if len(val.AllOf) > 0 {
 		allOfSchemas := make([]*jsonschema.Schema, len(val.AllOf))
 		for i, sub := range val.AllOf {
-			allOfSchemas[i] = extractProperty(sub)
+			allOfSchemas[i] = extractProperty(sub, cache)
 		}
 		prop.AllOf = allOfSchemas
 	}
 
 	// Handle oneOf/anyOf
 	if len(val.OneOf) > 0 {
-		fmt.Fprintf(os.Stderr, "[WARN] oneOf used in schema at %p. Only basic support is provided.\n", val)
+		cache.warnf("oneOf used in schema at %p. Only basic support is provided.", val)
 		oneOfSchemas := make([]*jsonschema.Schema, len(val.OneOf))
 		for i, sub := range val.OneOf {
-			oneOfSchemas[i] = extractProperty(sub)
+			oneOfSchemas[i] = extractProperty(sub, cache)
 		}
 		prop.OneOf = oneOfSchemas
 	}
 	if len(val.AnyOf) > 0 {
-		fmt.Fprintf(os.Stderr, "[WARN] anyOf used in schema at %p. Only basic support is provided.\n", val)
+		cache.warnf("anyOf used in schema at %p. Only basic support is provided.", val)
 		anyOfSchemas := make([]*jsonschema.Schema, len(val.AnyOf))
 		for i, sub := range val.AnyOf {
-			anyOfSchemas[i] = extractProperty(sub)
+			anyOfSchemas[i] = extractProperty(sub, cache)
 		}
 		prop.AnyOf = anyOfSchemas
 	}
 
 	// Handle discriminator (OpenAPI 3.0/3.1)
 	if val.Discriminator != nil {
-		fmt.Fprintf(os.Stderr, "[WARN] discriminator used in schema at %p. Only basic support is provided.\n", val)
+		cache.warnf("discriminator used in schema at %p. Only basic support is provided.", val)
 		// Store discriminator in Extra map since it's not a standard JSON Schema field
 		if prop.Extra == nil {
 			prop.Extra = make(map[string]any)
@@ -120,7 +180,15 @@ func extractProperty(s *openapi3.SchemaRef) *jsonschema.Schema {
 	if val.Type != nil && val.Type.Is("object") && val.Properties != nil {
 		prop.Properties = make(map[string]*jsonschema.Schema)
 		for name, sub := range val.Properties {
-			prop.Properties[name] = extractProperty(sub)
+			propSchema := extractProperty(sub, cache)
+			// Point out units and precision, copying shared component schemas before changing them
+			if propSchema != nil {
+				if desc := withUnitHint(propSchema.Description, sub.Value, name); desc != propSchema.Description {
+					propSchema = shallowCopy(propSchema)
+					propSchema.Description = desc
+				}
+			}
+			prop.Properties[name] = propSchema
 		}
 		if len(val.Required) > 0 {
 			prop.Required = val.Required
@@ -129,7 +197,7 @@ func extractProperty(s *openapi3.SchemaRef) *jsonschema.Schema {
 
 	// Array items
 	if val.Type != nil && val.Type.Is("array") && val.Items != nil {
-		prop.Items = extractProperty(val.Items)
+		prop.Items = extractProperty(val.Items, cache)
 	}
 
 	return prop
@@ -144,6 +212,13 @@ func extractProperty(s *openapi3.SchemaRef) *jsonschema.Schema {
 //	schema := openapi2mcp.BuildInputSchema(params, reqBody)
 //	// schema is a jsonschema.Schema representing the JSON schema for tool input
 func BuildInputSchema(params openapi3.Parameters, requestBody *openapi3.RequestBodyRef) jsonschema.Schema {
+	return buildInputSchema(params, requestBody, nil, false)
+}
+
+// buildInputSchema is BuildInputSchema with component schemas shared through cache.
+// Shared schemas that appear more than once in the result are copied, or referenced by
+// JSON pointer to their first occurrence if compact is set, since the schema must form a tree.
+func buildInputSchema(params openapi3.Parameters, requestBody *openapi3.RequestBodyRef, cache *schemaCache, compact bool) jsonschema.Schema {
 	schema := jsonschema.Schema{
 		Type:       "object",
 		Properties: make(map[string]*jsonschema.Schema),
@@ -158,14 +233,20 @@ func BuildInputSchema(params openapi3.Parameters, requestBody *openapi3.RequestB
 		p := paramRef.Value
 		if p.Schema != nil && p.Schema.Value != nil {
 			if p.Schema.Value.Type != nil && p.Schema.Value.Type.Is("string") && p.Schema.Value.Format == "binary" {
-				fmt.Fprintf(os.Stderr, "[WARN] Parameter '%s' uses 'string' with 'binary' format. Non-JSON body types are not fully supported.\n", p.Name)
+				cache.warnf("Parameter '%s' uses 'string' with 'binary' format. Non-JSON body types are not fully supported.", p.Name)
 			}
-			prop := extractProperty(p.Schema)
+			prop := extractProperty(p.Schema, cache)
 			if prop != nil {
 				// Override description if parameter has its own description
 				if p.Description != "" {
+					prop = shallowCopy(prop)
 					prop.Description = p.Description
 				}
+				// Point out units and precision, e.g. amounts in cents
+				if desc := withUnitHint(prop.Description, p.Schema.Value, p.Name); desc != prop.Description {
+					prop = shallowCopy(prop)
+					prop.Description = desc
+				}
 				// Use escaped parameter name for MCP schema compatibility
 				escapedName := escapeParameterName(p.Name)
 				schema.Properties[escapedName] = prop
@@ -176,11 +257,11 @@ func BuildInputSchema(params openapi3.Parameters, requestBody *openapi3.RequestB
 		}
 		// Warn about unsupported parameter locations
 		if p.In != "query" && p.In != "path" && p.In != "header" && p.In != "cookie" {
-			fmt.Fprintf(os.Stderr, "[WARN] Parameter '%s' uses unsupported location '%s'.\n", p.Name, p.In)
+			cache.warnf("Parameter '%s' uses unsupported location '%s'.", p.Name, p.In)
 		}
 	}
 
-	// Request body (application/json and application/vnd.api+json)
+	// Request body (application/json and application/vnd.api+json, JSON (Merge) Patch, NDJSON, or raw binary)
 	if requestBody != nil && requestBody.Value != nil {
 		for mtName := range requestBody.Value.Content {
 			// Check base content type without parameters
@@ -188,8 +269,8 @@ func BuildInputSchema(params openapi3.Parameters, requestBody *openapi3.RequestB
 			if idx := strings.IndexByte(mtName, ';'); idx > 0 {
 				baseMT = strings.TrimSpace(mtName[:idx])
 			}
-			if baseMT != "application/json" && baseMT != "application/vnd.api+json" {
-				fmt.Fprintf(os.Stderr, "[WARN] Request body uses media type '%s'. Only 'application/json' and 'application/vnd.api+json' are fully supported.\n", mtName)
+			if baseMT != "application/json" && baseMT != "application/vnd.api+json" && mtName != patchRequestType(requestBody.Value.Content) && tabularKind(mtName) != tabularNDJSON && mtName != binaryRequestType(requestBody.Value.Content) {
+				cache.warnf("Request body uses media type '%s'. Only 'application/json' and 'application/vnd.api+json' are fully supported.", mtName)
 			}
 		}
 		// Try application/json first, then application/vnd.api+json (including with parameters)
@@ -198,14 +279,33 @@ func BuildInputSchema(params openapi3.Parameters, requestBody *openapi3.RequestB
 			mt = getContentByType(requestBody.Value.Content, "application/vnd.api+json")
 		}
 		if mt != nil && mt.Schema != nil && mt.Schema.Value != nil {
-			bodyProp := extractProperty(mt.Schema)
+			bodyProp := extractProperty(mt.Schema, cache)
 			if bodyProp != nil {
+				bodyProp = shallowCopy(bodyProp)
 				bodyProp.Description = "The JSON request body."
 				schema.Properties["requestBody"] = bodyProp
 				if requestBody.Value.Required {
 					required = append(required, "requestBody")
 				}
 			}
+		} else if patchType := patchRequestType(requestBody.Value.Content); patchType != "" {
+			// Patch documents take a partial object (merge patch) or an array of operations (JSON Patch)
+			schema.Properties["requestBody"] = patchRequestBodySchema(patchType, requestBody.Value.Content[patchType], cache)
+			if requestBody.Value.Required {
+				required = append(required, "requestBody")
+			}
+		} else if ndjsonType := ndjsonRequestType(requestBody.Value.Content); ndjsonType != "" {
+			// NDJSON bodies take an array of the documents sent one per line
+			schema.Properties["requestBody"] = ndjsonRequestBodySchema(ndjsonType, requestBody.Value.Content[ndjsonType], cache)
+			if requestBody.Value.Required {
+				required = append(required, "requestBody")
+			}
+		} else if binaryType := binaryRequestType(requestBody.Value.Content); binaryType != "" {
+			// Raw binary uploads take the bytes base64-encoded
+			schema.Properties["requestBody"] = binaryRequestBodySchema(binaryType)
+			if requestBody.Value.Required {
+				required = append(required, "requestBody")
+			}
 		}
 	}
 
@@ -213,9 +313,100 @@ func BuildInputSchema(params openapi3.Parameters, requestBody *openapi3.RequestB
 		schema.Required = required
 	}
 
+	if cache != nil {
+		t := &schemaTree{seen: make(map[*jsonschema.Schema]string), compact: compact}
+		schema.Properties, _ = t.visitMap(schema.Properties, "/properties")
+	}
+
 	return schema
 }
 
+// shallowCopy returns a copy of s that can be modified without affecting a shared schema.
+func shallowCopy(s *jsonschema.Schema) *jsonschema.Schema {
+	c := *s
+	return &c
+}
+
+// schemaTree turns a schema graph with shared subschemas into a tree, as required for resolution.
+type schemaTree struct {
+	seen    map[*jsonschema.Schema]string // schema -> JSON pointer of its first occurrence
+	compact bool
+}
+
+// visit returns s, or a replacement if s (or one of its subschemas) already occurs in the tree.
+// Shared schemas are never modified; parents of replaced subschemas are copied instead.
+func (t *schemaTree) visit(s *jsonschema.Schema, path string) *jsonschema.Schema {
+	if s == nil {
+		return nil
+	}
+	if first, ok := t.seen[s]; ok {
+		if t.compact {
+			return &jsonschema.Schema{Ref: "#" + first}
+		}
+		return s.CloneSchemas()
+	}
+	t.seen[s] = path
+
+	allOf, allOfChanged := t.visitList(s.AllOf, path+"/allOf")
+	oneOf, oneOfChanged := t.visitList(s.OneOf, path+"/oneOf")
+	anyOf, anyOfChanged := t.visitList(s.AnyOf, path+"/anyOf")
+	props, propsChanged := t.visitMap(s.Properties, path+"/properties")
+	items := t.visit(s.Items, path+"/items")
+	if !allOfChanged && !oneOfChanged && !anyOfChanged && !propsChanged && items == s.Items {
+		return s
+	}
+
+	out := shallowCopy(s)
+	out.AllOf, out.OneOf, out.AnyOf, out.Properties, out.Items = allOf, oneOf, anyOf, props, items
+	return out
+}
+
+// visitList visits a list of subschemas, copying the list if any of them is replaced.
+func (t *schemaTree) visitList(list []*jsonschema.Schema, path string) ([]*jsonschema.Schema, bool) {
+	var out []*jsonschema.Schema
+	for i, sub := range list {
+		if v := t.visit(sub, fmt.Sprintf("%s/%d", path, i)); v != sub {
+			if out == nil {
+				out = slices.Clone(list)
+			}
+			out[i] = v
+		}
+	}
+	if out == nil {
+		return list, false
+	}
+	return out, true
+}
+
+// visitMap visits properties in name order, copying the map if any of them is replaced.
+func (t *schemaTree) visitMap(props map[string]*jsonschema.Schema, path string) (map[string]*jsonschema.Schema, bool) {
+	var out map[string]*jsonschema.Schema
+	for _, name := range slices.Sorted(maps.Keys(props)) {
+		sub := props[name]
+		if v := t.visit(sub, path+"/"+escapeJSONPointer(name)); v != sub {
+			if out == nil {
+				out = maps.Clone(props)
+			}
+			out[name] = v
+		}
+	}
+	if out == nil {
+		return props, false
+	}
+	return out, true
+}
+
+// jsonPointerEscaper escapes "~" and "/" in JSON pointer segments.
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// jsonPointerUnescaper reverses jsonPointerEscaper.
+var jsonPointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+// escapeJSONPointer escapes a property name for use as a JSON pointer segment.
+func escapeJSONPointer(name string) string {
+	return jsonPointerEscaper.Replace(name)
+}
+
 // SchemaToMap converts a jsonschema.Schema to map[string]any for backward compatibility
 func SchemaToMap(schema jsonschema.Schema) map[string]any {
 	schemaBytes, _ := json.Marshal(schema)
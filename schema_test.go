@@ -78,3 +78,55 @@ func TestBuildInputSchema_RequiredFromBody(t *testing.T) {
 		t.Fatalf("expected 'requestBody' to be required, got: %v", schema.Required)
 	}
 }
+
+func TestBuildInputSchema_SharedComponents(t *testing.T) {
+	address := &openapi3.SchemaRef{Ref: "#/components/schemas/Address", Value: &openapi3.Schema{
+		Type: typesPtr("object"),
+		Properties: openapi3.Schemas{
+			"city": &openapi3.SchemaRef{Value: &openapi3.Schema{Type: typesPtr("string")}},
+		},
+	}}
+	body := &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithJSONSchemaRef(&openapi3.SchemaRef{Value: &openapi3.Schema{
+		Type: typesPtr("object"),
+		Properties: openapi3.Schemas{
+			"billing":  address,
+			"shipping": address,
+		},
+	}})}
+	params := openapi3.Parameters{
+		&openapi3.ParameterRef{Value: &openapi3.Parameter{Name: "from", In: "query", Description: "Origin", Schema: address}},
+	}
+
+	cache := newSchemaCache(nil)
+	a := buildInputSchema(params, body, cache, false)
+	b := buildInputSchema(nil, body, cache, false)
+
+	// The first occurrence is shared between tools, repeated occurrences are copies
+	billingA := a.Properties["requestBody"].Properties["billing"]
+	shippingA := a.Properties["requestBody"].Properties["shipping"]
+	if b.Properties["requestBody"].Properties["billing"] != cache.get(address.Value) {
+		t.Errorf("expected component schema to be shared between tools")
+	}
+	if billingA == shippingA || shippingA.Properties["city"] == nil {
+		t.Errorf("expected repeated component to be copied within a tool")
+	}
+	if cache.get(address.Value).Description != "" || a.Properties["from"].Description != "Origin" {
+		t.Errorf("expected parameter description not to leak into the shared schema")
+	}
+	if _, err := a.Resolve(nil); err != nil {
+		t.Errorf("expected schema to resolve: %v", err)
+	}
+
+	compact := buildInputSchema(nil, body, cache, true)
+	if ref := compact.Properties["requestBody"].Properties["shipping"].Ref; ref != "#/properties/requestBody/properties/billing" {
+		t.Errorf("expected $ref to first occurrence, got %q", ref)
+	}
+	resolved, err := compact.Resolve(nil)
+	if err != nil {
+		t.Fatalf("expected compact schema to resolve: %v", err)
+	}
+	err = resolved.Validate(map[string]any{"requestBody": map[string]any{"shipping": map[string]any{"city": 1}}})
+	if err == nil {
+		t.Errorf("expected $ref to validate against the shared component")
+	}
+}
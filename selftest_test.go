@@ -1,7 +1,12 @@
 package openapi2mcp
 
 import (
+	"context"
+	"net/http"
+	"strings"
 	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
 )
 
 func TestSelfTestBasic(t *testing.T) {
@@ -10,3 +15,54 @@ func TestSelfTestBasic(t *testing.T) {
 		t.Log("basic selftest placeholder")
 	})
 }
+
+func liveTestOperations() []OpenAPIOperation {
+	return []OpenAPIOperation{
+		{OperationID: "listPets", Path: "/pets", Method: "get", Responses: petResponses()},
+		{OperationID: "getPet", Path: "/pets/{id}", Method: "get", Responses: petResponses(), Parameters: openapi3.Parameters{
+			{Value: &openapi3.Parameter{Name: "id", In: "path", Required: true, Schema: &openapi3.SchemaRef{Value: &openapi3.Schema{Type: typesPtr("integer")}}}},
+		}},
+		{OperationID: "createPet", Path: "/pets", Method: "post"},
+	}
+}
+
+func TestSelfTestOpenAPIMCPLive(t *testing.T) {
+	var called []string
+	handler := func(body string, status int) func(*http.Request) (*http.Response, error) {
+		return func(req *http.Request) (*http.Response, error) {
+			called = append(called, req.Method+" "+req.URL.Path)
+			if strings.HasPrefix(req.URL.Path, "/pets/") {
+				return fakeResponse(404, "application/json", `{"error":"not found"}`)(req)
+			}
+			return fakeResponse(status, "application/json", body)(req)
+		}
+	}
+	run := func(h func(*http.Request) (*http.Response, error), opts *LiveSelfTestOptions) error {
+		opts.ToolGenOptions = &ToolGenOptions{RequestHandler: h}
+		return SelfTestOpenAPIMCPLive(context.Background(), minimalOpenAPIDoc(), liveTestOperations(), opts)
+	}
+
+	// A 404 for generated example arguments is only a warning
+	if err := run(handler(`{"id":1,"name":"Rex"}`, 200), &LiveSelfTestOptions{}); err != nil {
+		t.Errorf("expected live self-test to pass, got %v", err)
+	}
+	if strings.Join(called, ",") != "GET /pets,GET /pets/123" {
+		t.Errorf("expected only GET operations in order of required arguments, got %v", called)
+	}
+
+	if err := run(handler(`{"id":"one"}`, 200), &LiveSelfTestOptions{Operations: []string{"listPets"}}); err == nil {
+		t.Error("expected schema mismatch to fail the live self-test")
+	}
+	if err := run(handler(`{"error":"unauthorized"}`, 401), &LiveSelfTestOptions{Operations: []string{"listPets"}}); err == nil {
+		t.Error("expected rejected credentials to fail the live self-test")
+	}
+
+	called = nil
+	err := run(handler("{}", 200), &LiveSelfTestOptions{Operations: []string{"createPet"}})
+	if err == nil || !strings.Contains(err.Error(), "only GET operations") {
+		t.Errorf("expected non-GET operation to be refused, got %v", err)
+	}
+	if len(called) != 0 {
+		t.Errorf("expected no calls for refused operations, got %v", called)
+	}
+}
@@ -0,0 +1,319 @@
+// session.go
+package openapi2mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// maxSessions is the number of MCP sessions whose cookies and CSRF tokens are kept.
+const maxSessions = 1024
+
+// storedSessionTTL is how long the cookies and CSRF token of a session are kept in a Store after its last change.
+const storedSessionTTL = 24 * time.Hour
+
+// CSRFConfig configures how a CSRF token is acquired and sent with state-changing calls (POST, PUT, PATCH, DELETE).
+// The token is read from Cookie, fetched from Endpoint, or both: Endpoint is fetched when the cookie isn't set yet.
+// It is kept per MCP session and fetched again once if the API rejects a call with 403 or 419.
+type CSRFConfig struct {
+	Header   string `json:"header" yaml:"header"`     // request header carrying the token, e.g. X-CSRF-Token
+	Cookie   string `json:"cookie" yaml:"cookie"`     // cookie holding the token, e.g. csrftoken
+	Endpoint string `json:"endpoint" yaml:"endpoint"` // path below the base URL, or URL, answering a GET with the token
+	Field    string `json:"field" yaml:"field"`       // JSON field of the endpoint's response holding the token; default: its Header response header
+}
+
+// csrfConfig returns the CSRF configuration of opts, or of the spec's x-mcp-csrf extension, or nil if there is none.
+func csrfConfig(opts *ToolGenOptions, doc *openapi3.T) *CSRFConfig {
+	if opts != nil && opts.CSRF != nil {
+		return opts.CSRF
+	}
+	if doc == nil || doc.Extensions["x-mcp-csrf"] == nil {
+		return nil
+	}
+	data, err := json.Marshal(doc.Extensions["x-mcp-csrf"])
+	if err != nil {
+		return nil
+	}
+	var cfg CSRFConfig
+	if json.Unmarshal(data, &cfg) != nil || cfg.Header == "" || cfg.Cookie == "" && cfg.Endpoint == "" {
+		return nil
+	}
+	return &cfg
+}
+
+// unsafeMethod reports whether requests with method may change state, so that they need a CSRF token.
+func unsafeMethod(method string) bool {
+	switch strings.ToUpper(method) {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return false
+	}
+	return true
+}
+
+// sessionStore keeps the cookies and CSRF token of each MCP session, evicting the oldest session beyond maxSessions.
+// With a Store, sessions are also kept under "sessions/<id>", so that they survive restarts and are shared by the
+// replicas of an HTTP deployment. It is safe for concurrent use.
+type sessionStore struct {
+	store Store // persists the sessions if set
+
+	mu       sync.Mutex
+	order    []string // session IDs, oldest first
+	sessions map[string]*sessionState
+}
+
+// newSessionStore creates an empty session store.
+func newSessionStore() *sessionStore {
+	return &sessionStore{sessions: make(map[string]*sessionState)}
+}
+
+// get returns the state of the session, creating it if needed.
+func (s *sessionStore) get(id string) *sessionState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if state, ok := s.sessions[id]; ok {
+		return state
+	}
+	jar, _ := cookiejar.New(nil)
+	state := &sessionState{jar: jar}
+	if s.store != nil && id != "" {
+		state.store, state.key = s.store, "sessions/"+id
+		state.restore()
+	}
+	s.sessions[id] = state
+	s.order = append(s.order, id)
+	for len(s.order) > maxSessions {
+		delete(s.sessions, s.order[0])
+		s.order = s.order[1:]
+	}
+	return state
+}
+
+// sessionState is the cookie jar and CSRF token of one MCP session.
+type sessionState struct {
+	jar   *cookiejar.Jar
+	store Store  // persists the session under key if set
+	key   string // store key of the session
+
+	mu        sync.Mutex
+	csrfToken string // token fetched from the CSRF endpoint, if any
+
+	savedMu sync.Mutex
+	saved   savedSession // what is persisted in the store
+}
+
+// savedSession is a session as persisted in a Store. The jar can't list its cookies, so the cookies are kept as set.
+type savedSession struct {
+	CSRFToken string        `json:"csrf_token,omitempty"`
+	Cookies   []savedCookie `json:"cookies,omitempty"`
+}
+
+// savedCookie is a cookie set for a URL, as a Set-Cookie header value.
+type savedCookie struct {
+	URL       string `json:"url"`
+	SetCookie string `json:"set_cookie"`
+}
+
+// restore loads the session from the store into the jar.
+func (s *sessionState) restore() {
+	var saved savedSession
+	if ok, err := loadJSON(context.Background(), s.store, s.key, &saved); err != nil || !ok {
+		return
+	}
+	for _, c := range saved.Cookies {
+		u, err := url.Parse(c.URL)
+		if err != nil {
+			continue
+		}
+		if cookie, err := http.ParseSetCookie(c.SetCookie); err == nil {
+			s.jar.SetCookies(u, []*http.Cookie{cookie})
+		}
+	}
+	s.csrfToken = saved.CSRFToken
+	s.saved = saved
+}
+
+// persist updates the saved session with update and writes it to the store, if any.
+func (s *sessionState) persist(update func(*savedSession)) {
+	if s.store == nil {
+		return
+	}
+	s.savedMu.Lock()
+	defer s.savedMu.Unlock()
+	update(&s.saved)
+	storeJSON(context.Background(), s.store, s.key, s.saved, storedSessionTTL)
+}
+
+// handler returns a request handler sending the session's cookies and, for state-changing requests, its CSRF token
+// with requests to next, and keeping the cookies of the responses. Relative CSRF endpoints are below baseURL.
+func (s *sessionState) handler(next func(*http.Request) (*http.Response, error), csrf *CSRFConfig, baseURL string) func(*http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		needsToken := csrf != nil && unsafeMethod(req.Method) && req.Header.Get(csrf.Header) == ""
+		resp, err := s.send(next, req, csrf, baseURL, needsToken, false)
+		if err != nil || !needsToken || resp.StatusCode != http.StatusForbidden && resp.StatusCode != 419 {
+			return resp, err
+		}
+		if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			return resp, nil
+		}
+
+		// The token may have expired: fetch a new one and send the request once more
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		retry := req.Clone(req.Context())
+		if req.GetBody != nil {
+			if retry.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+		return s.send(next, retry, csrf, baseURL, true, true)
+	}
+}
+
+// send sends req with the session's cookies, and its CSRF token if needsToken, and keeps the response's cookies.
+// req is cloned, so that it can be sent again.
+func (s *sessionState) send(next func(*http.Request) (*http.Response, error), req *http.Request, csrf *CSRFConfig, baseURL string, needsToken, refresh bool) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if needsToken {
+		if token := s.token(next, req, csrf, baseURL, refresh); token != "" {
+			req.Header.Set(csrf.Header, token)
+		}
+	}
+	s.addCookies(req)
+	resp, err := next(req)
+	if err != nil {
+		return nil, err
+	}
+	s.keepCookies(resp, req.URL)
+	return resp, nil
+}
+
+// addCookies adds the session's cookies for the URL of req to its Cookie header, unless a cookie of the same
+// name was already set from a cookie parameter.
+func (s *sessionState) addCookies(req *http.Request) {
+	existing := map[string]bool{}
+	for _, c := range req.Cookies() {
+		existing[c.Name] = true
+	}
+	var pairs []string
+	if header := req.Header.Get("Cookie"); header != "" {
+		pairs = append(pairs, header)
+	}
+	for _, c := range s.jar.Cookies(req.URL) {
+		if !existing[c.Name] {
+			pairs = append(pairs, c.Name+"="+c.Value)
+		}
+	}
+	if len(pairs) > 0 {
+		req.Header.Set("Cookie", strings.Join(pairs, "; "))
+	}
+}
+
+// keepCookies stores the cookies set by resp, for the URL of the request that produced it.
+func (s *sessionState) keepCookies(resp *http.Response, fallback *url.URL) {
+	u := fallback
+	if resp.Request != nil && resp.Request.URL != nil {
+		u = resp.Request.URL
+	}
+	cookies := resp.Cookies()
+	if len(cookies) == 0 {
+		return
+	}
+	s.jar.SetCookies(u, cookies)
+	s.persist(func(saved *savedSession) {
+		for _, c := range cookies {
+			// A cookie set again for the same host replaces the saved one, so that the list doesn't grow
+			saved.Cookies = slices.DeleteFunc(saved.Cookies, func(sc savedCookie) bool {
+				prev, err := http.ParseSetCookie(sc.SetCookie)
+				su, _ := url.Parse(sc.URL)
+				return err == nil && su != nil && su.Host == u.Host && prev.Name == c.Name && prev.Path == c.Path
+			})
+			// Max-Age is relative to when the cookie was set, so it is saved as an expiry
+			c := *c
+			if c.MaxAge > 0 {
+				c.Expires, c.MaxAge = time.Now().Add(time.Duration(c.MaxAge)*time.Second), 0
+			}
+			saved.Cookies = append(saved.Cookies, savedCookie{URL: u.String(), SetCookie: c.String()})
+		}
+	})
+}
+
+// token returns the CSRF token for req: the value of the CSRF cookie, else the token fetched from the endpoint,
+// fetching it if there is none yet or refresh is set. It returns "" if no token could be acquired.
+func (s *sessionState) token(next func(*http.Request) (*http.Response, error), req *http.Request, csrf *CSRFConfig, baseURL string, refresh bool) string {
+	cookie := func() string {
+		if csrf.Cookie == "" {
+			return ""
+		}
+		for _, c := range s.jar.Cookies(req.URL) {
+			if c.Name == csrf.Cookie {
+				return c.Value
+			}
+		}
+		return ""
+	}
+	if token := cookie(); token != "" && !refresh {
+		return token
+	}
+	if csrf.Endpoint == "" {
+		return cookie()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.csrfToken != "" && !refresh {
+		return s.csrfToken
+	}
+	endpoint := csrf.Endpoint
+	if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+		var err error
+		if endpoint, err = url.JoinPath(baseURL, endpoint); err != nil {
+			return ""
+		}
+	}
+	fetch, err := http.NewRequestWithContext(req.Context(), http.MethodGet, endpoint, nil)
+	if err != nil {
+		return ""
+	}
+	for _, name := range []string{"Authorization", "Accept-Language", "User-Agent"} {
+		if value := req.Header.Get(name); value != "" {
+			fetch.Header.Set(name, value)
+		}
+	}
+	fetch.Header.Set("Accept", "application/json")
+	s.addCookies(fetch)
+	resp, err := next(fetch)
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+	s.keepCookies(resp, fetch.URL)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return ""
+	}
+
+	token := resp.Header.Get(csrf.Header)
+	if csrf.Field != "" {
+		var fields map[string]any
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		if json.Unmarshal(bytes.TrimSpace(data), &fields) == nil {
+			token, _ = fields[csrf.Field].(string)
+		}
+	}
+	if token == "" {
+		token = cookie()
+	}
+	s.csrfToken = token
+	s.persist(func(saved *savedSession) { saved.CSRFToken = token })
+	return token
+}
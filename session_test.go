@@ -0,0 +1,80 @@
+package openapi2mcp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestToolHandler_SessionCookiesAndCSRF(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+x-mcp-csrf: {header: X-CSRF-Token, cookie: csrftoken}
+paths:
+  /session:
+    get: {operationId: getSession, responses: {"200": {description: ok}}}
+  /orders:
+    post: {operationId: createOrder, responses: {"201": {description: created}}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var sent []string
+	opts := &ToolGenOptions{RequestHandler: func(req *http.Request) (*http.Response, error) {
+		sent = append(sent, req.Method+" "+req.URL.Path+" cookie="+req.Header.Get("Cookie")+" csrf="+req.Header.Get("X-CSRF-Token"))
+		header := http.Header{}
+		if req.URL.Path == "/session" {
+			header.Add("Set-Cookie", "sid=abc; Path=/")
+			header.Add("Set-Cookie", "csrftoken=t1; Path=/")
+		}
+		return &http.Response{StatusCode: 200, Header: header, Body: io.NopCloser(strings.NewReader("{}")), Request: req}, nil
+	}}
+	rt := newServerRuntime()
+	call := func(id, method, path string) {
+		op := OpenAPIOperation{OperationID: id, Method: method, Path: path}
+		handler := toolHandler(id, op, doc, jsonschema.Schema{}, []string{"https://api.example.com"}, opts, rt)
+		if _, _, err := handler(context.Background(), nil, map[string]any{}); err != nil {
+			t.Fatalf("%s: unexpected error: %v", id, err)
+		}
+	}
+	call("getSession", "get", "/session")
+	call("createOrder", "post", "/orders")
+
+	want := []string{"GET /session cookie= csrf=", "POST /orders cookie=sid=abc; csrftoken=t1 csrf=t1"}
+	if strings.Join(sent, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected requests:\n%s\nwant:\n%s", strings.Join(sent, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestSessionState_CSRFEndpoint(t *testing.T) {
+	var sent []string
+	tokens := []string{"t1", "t2"}
+	next := func(req *http.Request) (*http.Response, error) {
+		sent = append(sent, req.Method+" "+req.URL.Path+" csrf="+req.Header.Get("X-CSRF-Token"))
+		if req.URL.Path == "/api/csrf" {
+			body := `{"token": "` + tokens[0] + `"}`
+			tokens = tokens[1:]
+			return &http.Response{StatusCode: 200, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+		}
+		status := http.StatusOK
+		if req.Header.Get("X-CSRF-Token") != "t2" {
+			status = http.StatusForbidden
+		}
+		return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("{}")), Request: req}, nil
+	}
+	session := newSessionStore().get("session-1")
+	handler := session.handler(next, &CSRFConfig{Header: "X-CSRF-Token", Endpoint: "/csrf", Field: "token"}, "https://example.com/api")
+	req, _ := http.NewRequest(http.MethodDelete, "https://example.com/api/orders/1", nil)
+	resp, err := handler(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the call to succeed with a fresh token, got %v, %v", resp, err)
+	}
+	want := []string{"GET /api/csrf csrf=", "DELETE /api/orders/1 csrf=t1", "GET /api/csrf csrf=", "DELETE /api/orders/1 csrf=t2"}
+	if strings.Join(sent, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected requests:\n%s\nwant:\n%s", strings.Join(sent, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -0,0 +1,151 @@
+// snapshot.go
+package openapi2mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ToolsSnapshot registers ops as tools (as RegisterOpenAPITools does) and returns the tool list, as
+// MCP clients see it, in the stable serialization of MarshalToolsSnapshot. DryRun is ignored.
+//
+// Example usage for ToolsSnapshot:
+//
+//	snapshot, err := openapi2mcp.ToolsSnapshot(ctx, doc, ops, opts)
+//	if err != nil { t.Fatal(err) }
+//	if err := openapi2mcp.CompareGolden("testdata/tools.golden.json", snapshot, *update); err != nil { t.Fatal(err) }
+func ToolsSnapshot(ctx context.Context, doc *openapi3.T, ops []OpenAPIOperation, opts *ToolGenOptions) ([]byte, error) {
+	tools, err := registeredTools(ctx, doc, ops, opts)
+	if err != nil {
+		return nil, err
+	}
+	return MarshalToolsSnapshot(tools)
+}
+
+// registeredTools registers ops as tools on an in-memory server and lists them through a client. DryRun is ignored.
+func registeredTools(ctx context.Context, doc *openapi3.T, ops []OpenAPIOperation, opts *ToolGenOptions) ([]*mcp.Tool, error) {
+	toolOpts := ToolGenOptions{}
+	if opts != nil {
+		toolOpts = *opts
+	}
+	toolOpts.DryRun = false
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "openapi-mcp-snapshot", Version: "snapshot"}, nil)
+	RegisterOpenAPITools(server, ops, doc, &toolOpts)
+	client := mcp.NewClient(&mcp.Implementation{Name: "openapi-mcp-snapshot-client", Version: "snapshot"}, nil)
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, serverTransport, nil); err != nil {
+		return nil, err
+	}
+	cs, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer cs.Close()
+
+	var tools []*mcp.Tool
+	for tool, err := range cs.Tools(ctx, nil) {
+		if err != nil {
+			return nil, err
+		}
+		tools = append(tools, tool)
+	}
+	return tools, nil
+}
+
+// MarshalToolsSnapshot serializes tools deterministically for snapshot tests: tools are sorted by name,
+// object keys are sorted, output is indented with two spaces, line endings are normalized to "\n",
+// and trailing whitespace is removed from every line of text. Equal tool surfaces always produce equal bytes.
+func MarshalToolsSnapshot(tools []*mcp.Tool) ([]byte, error) {
+	sorted := make([]*mcp.Tool, len(tools))
+	copy(sorted, tools)
+	slices.SortStableFunc(sorted, func(a, b *mcp.Tool) int { return strings.Compare(a.Name, b.Name) })
+
+	raw, err := json.Marshal(sorted)
+	if err != nil {
+		return nil, err
+	}
+	// Round-trip through generic values: maps are marshaled with sorted keys
+	var generic any
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(normalizeSnapshotText(generic)); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// normalizeSnapshotText normalizes line endings and trailing whitespace in all strings of v.
+func normalizeSnapshotText(v any) any {
+	switch v := v.(type) {
+	case string:
+		lines := strings.Split(strings.ReplaceAll(v, "\r\n", "\n"), "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight(line, " \t")
+		}
+		return strings.Join(lines, "\n")
+	case []any:
+		for i := range v {
+			v[i] = normalizeSnapshotText(v[i])
+		}
+	case map[string]any:
+		for k := range v {
+			v[k] = normalizeSnapshotText(v[k])
+		}
+	}
+	return v
+}
+
+// CompareGolden compares got with the golden file at path. If update is true, the golden file is
+// (re)written instead and nil is returned. A mismatch is reported with the first differing line.
+func CompareGolden(path string, got []byte, update bool) error {
+	if update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(path, got, 0o644)
+	}
+	want, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%s: golden file does not exist (create it by running with update enabled)", path)
+	}
+	if err != nil {
+		return err
+	}
+	want = bytes.ReplaceAll(want, []byte("\r\n"), []byte("\n"))
+	if bytes.Equal(want, got) {
+		return nil
+	}
+
+	wantLines := strings.Split(string(want), "\n")
+	gotLines := strings.Split(string(got), "\n")
+	for i := 0; i < max(len(wantLines), len(gotLines)); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			return fmt.Errorf("%s: snapshot differs at line %d:\n  want: %s\n  got:  %s\n(update the golden file if the change is expected)", path, i+1, w, g)
+		}
+	}
+	return fmt.Errorf("%s: snapshot differs", path)
+}
@@ -0,0 +1,75 @@
+package openapi2mcp
+
+import (
+	"context"
+	"flag"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files in testdata")
+
+func TestToolsSnapshot_Golden(t *testing.T) {
+	doc := minimalOpenAPIDoc()
+	ops := append(liveTestOperations(), fuzzOperation())
+	ops[0].Tags = []string{"pets"}
+	ops[0].Security = openapi3.SecurityRequirements{{"bearerAuth": {}, "apiKey": {}}}
+
+	snapshot, err := ToolsSnapshot(context.Background(), doc, ops, &ToolGenOptions{Version: "1.0.0", ConfirmDangerousActions: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Registering the same spec again must produce identical bytes
+	for range 5 {
+		again, err := ToolsSnapshot(context.Background(), doc, ops, &ToolGenOptions{Version: "1.0.0", ConfirmDangerousActions: true})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(again) != string(snapshot) {
+			t.Fatal("expected snapshots to be deterministic")
+		}
+	}
+	if err := CompareGolden(filepath.Join("testdata", "tools.golden.json"), snapshot, *updateGolden); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMarshalToolsSnapshot(t *testing.T) {
+	tools := []*mcp.Tool{
+		{Name: "b", Description: "line one  \r\nline <two>\t"},
+		{Name: "a", Description: "first"},
+	}
+	got, err := MarshalToolsSnapshot(tools)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := string(got)
+	if strings.Index(text, `"name": "a"`) > strings.Index(text, `"name": "b"`) {
+		t.Errorf("expected tools sorted by name, got:\n%s", text)
+	}
+	if !strings.Contains(text, `"line one\nline <two>"`) {
+		t.Errorf("expected normalized, unescaped description, got:\n%s", text)
+	}
+	if tools[0].Name != "b" {
+		t.Error("expected input slice to be left unchanged")
+	}
+
+	path := filepath.Join(t.TempDir(), "tools.golden.json")
+	if err := CompareGolden(path, got, false); err == nil {
+		t.Error("expected error for missing golden file")
+	}
+	if err := CompareGolden(path, got, true); err != nil {
+		t.Fatalf("failed to write golden file: %v", err)
+	}
+	if err := CompareGolden(path, got, false); err != nil {
+		t.Errorf("expected golden file to match: %v", err)
+	}
+	changed := strings.Replace(text, "first", "second", 1)
+	if err := CompareGolden(path, []byte(changed), false); err == nil || !strings.Contains(err.Error(), `got:      "description": "second"`) {
+		t.Errorf("expected mismatch to report the differing line, got %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+// sniff.go
+package openapi2mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// sniffLength is the number of bytes of a body inspected to tell binary from text.
+const sniffLength = 1024
+
+// sniffResponse decides whether a response body is shown as JSON, text, or binary by inspecting the payload, for APIs
+// labeling binary data as JSON or text, or JSON and text as binary. isJSON and isText are what the Content-Type
+// implies; if the payload disagrees, the returned note tells the model how the body is shown instead.
+func sniffResponse(contentType string, body []byte, truncated, isJSON, isText bool) (bool, bool, string) {
+	if len(body) == 0 {
+		return isJSON, isText, ""
+	}
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	declared := "declared as " + mediaType
+	if mediaType == "" {
+		declared = "sent without a content type"
+	}
+
+	var sniffedJSON, sniffedText bool
+	switch {
+	case looksBinary(body, params["charset"]):
+		if !isJSON && !isText {
+			return isJSON, isText, ""
+		}
+	case looksJSON(body, truncated):
+		if isJSON {
+			return isJSON, isText, ""
+		}
+		sniffedJSON = true
+	case isJSON:
+		// Invalid JSON, such as a plain "OK", is still readable text
+		sniffedText = true
+	case isText:
+		return isJSON, isText, ""
+	default:
+		// Text labeled as binary; real binary formats with a textual header, like PDF, keep their type
+		if !strings.HasPrefix(http.DetectContentType(body), "text/") || binaryMediaType(mediaType) {
+			return isJSON, isText, ""
+		}
+		sniffedText = true
+	}
+
+	shown := "binary data"
+	switch {
+	case sniffedJSON:
+		shown = "JSON"
+	case sniffedText:
+		shown = "text"
+	}
+	return sniffedJSON, sniffedText, fmt.Sprintf("\n\n[CONTENT TYPE MISMATCH: the response was %s but contains %s; it is shown as %s.]", declared, shown, shown)
+}
+
+// looksBinary reports whether body holds binary data: control characters other than whitespace and escape, or
+// invalid UTF-8 if the charset is UTF-8 or not declared. Only the start of body is inspected.
+func looksBinary(body []byte, charset string) bool {
+	sample := body[:min(len(body), sniffLength)]
+	for _, b := range sample {
+		if b <= 0x08 || b == 0x0B || 0x0E <= b && b <= 0x1A || 0x1C <= b && b <= 0x1F {
+			return true
+		}
+	}
+	if charset != "" && !strings.EqualFold(charset, "utf-8") && !strings.EqualFold(charset, "utf8") {
+		return false
+	}
+	for i := 0; i < len(sample); {
+		r, size := utf8.DecodeRune(sample[i:])
+		// A sequence cut off by the end of the sample isn't invalid
+		if r == utf8.RuneError && size == 1 && (len(sample) == len(body) || i < len(sample)-utf8.UTFMax) {
+			return true
+		}
+		i += size
+	}
+	return false
+}
+
+// looksJSON reports whether body is a JSON object or array. A truncated body only needs to start like one.
+func looksJSON(body []byte, truncated bool) bool {
+	trimmed := bytes.TrimLeft(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")), " \t\r\n")
+	if len(trimmed) == 0 || trimmed[0] != '{' && trimmed[0] != '[' {
+		return false
+	}
+	return truncated || json.Valid(trimmed)
+}
+
+// binaryMediaType reports whether mediaType is an image, audio, video, font, or model type, which stay binary
+// even if their payload is text (e.g. SVG images).
+func binaryMediaType(mediaType string) bool {
+	main, _, _ := strings.Cut(mediaType, "/")
+	switch main {
+	case "image", "audio", "video", "font", "model":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package openapi2mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestSniffResponse(t *testing.T) {
+	png := "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"
+	tests := []struct {
+		name, contentType, body string
+		truncated               bool
+		isJSON, isText          bool
+		wantJSON, wantText      bool
+		note                    string
+	}{
+		{"json", "application/json", `{"a": 1}`, false, true, false, true, false, ""},
+		{"binary labeled json", "application/json", png, false, true, false, false, false, "declared as application/json but contains binary data"},
+		{"binary labeled text", "text/plain", png, false, false, true, false, false, "contains binary data"},
+		{"json labeled binary", "application/octet-stream", `[{"a": 1}]`, false, false, false, true, false, "declared as application/octet-stream but contains JSON"},
+		{"truncated json without type", "", `{"items": [1, 2`, true, false, false, true, false, "sent without a content type but contains JSON"},
+		{"json labeled text", "text/plain", ` {"a": 1}`, false, false, true, true, false, "shown as JSON"},
+		{"text labeled json", "application/json", `OK`, false, true, false, false, true, "shown as text"},
+		{"text labeled binary", "application/octet-stream", "name,age\nRex,3\n", false, false, false, false, true, "shown as text"},
+		{"svg", "image/svg+xml", `<svg xmlns="http://www.w3.org/2000/svg"/>`, false, false, false, false, false, ""},
+		{"pdf", "application/pdf", "%PDF-1.4\n", false, false, false, false, false, ""},
+		{"latin-1 text", "text/plain; charset=iso-8859-1", "caf\xe9", false, false, true, false, true, ""},
+		{"utf-8 cut off", "text/plain", strings.Repeat("ä", sniffLength), true, false, true, false, true, ""},
+		{"empty", "application/json", "", false, true, false, true, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isJSON, isText, note := sniffResponse(tt.contentType, []byte(tt.body), tt.truncated, tt.isJSON, tt.isText)
+			if isJSON != tt.wantJSON || isText != tt.wantText {
+				t.Errorf("expected json=%v text=%v, got json=%v text=%v", tt.wantJSON, tt.wantText, isJSON, isText)
+			}
+			if tt.note == "" && note != "" || !strings.Contains(note, tt.note) {
+				t.Errorf("expected a note containing %q, got %q", tt.note, note)
+			}
+		})
+	}
+}
+
+func TestToolHandler_MislabeledResponse(t *testing.T) {
+	op := OpenAPIOperation{OperationID: "getReport", Path: "/report", Method: "get"}
+	opts := &ToolGenOptions{RequestHandler: fakeResponse(200, "application/octet-stream", `{"items": [{"id": 1}, {"id": 2}]}`)}
+	handler := toolHandler("getReport", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+	res, _, err := handler(context.Background(), nil, map[string]any{"__filter": "items[].id"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(t, res)
+	if !strings.Contains(text, "Response:\n[1,2]") || !strings.Contains(text, "[CONTENT TYPE MISMATCH") || strings.Contains(text, "file_base64") {
+		t.Errorf("expected the body to be handled as JSON, got: %s", text)
+	}
+
+	opts.RequestHandler = fakeResponse(200, "application/json", "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	handler = toolHandler("getReport", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+	res, _, _ = handler(context.Background(), nil, map[string]any{})
+	if text := resultText(t, res); !strings.Contains(text, `"mime_type": "image/png"`) || !strings.Contains(text, `"declared_mime_type": "application/json"`) {
+		t.Errorf("expected the body to be handled as binary, got: %s", text)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/getkin/kin-openapi/openapi3"
@@ -139,7 +140,11 @@ func LoadOpenAPISpecFromBytes(data []byte) (*openapi3.T, error) {
 	if err != nil {
 		return nil, generateAIOpenAPILoadError("Spec parsing", "", err)
 	}
-	if err := doc.Validate(loader.Context); err != nil {
+	// kin-openapi doesn't model the webhooks object of OpenAPI 3.1, so it is validated separately
+	if err := doc.Validate(loader.Context, openapi3.AllowExtraSiblingFields("webhooks")); err != nil {
+		return nil, generateAIOpenAPILoadError("Spec validation", "", err)
+	}
+	if _, err := ExtractWebhooks(doc); err != nil {
 		return nil, generateAIOpenAPILoadError("Spec validation", "", err)
 	}
 	return doc, nil
@@ -152,8 +157,8 @@ func LoadOpenAPISpecFromBytes(data []byte) (*openapi3.T, error) {
 //	doc, err := openapi2mcp.LoadOpenAPISpec("petstore.yaml")
 //	if err != nil { log.Fatal(err) }
 //	ops := openapi2mcp.ExtractOpenAPIOperations(doc)
-func ExtractOpenAPIOperations(doc *openapi3.T) []OpenAPIOperation {
-	var ops []OpenAPIOperation
+func ExtractOpenAPIOperations(doc *openapi3.T) Operations {
+	var ops Operations
 	for path, pathItem := range doc.Paths.Map() {
 		for method, op := range pathItem.Operations() {
 			id := op.OperationID
@@ -172,23 +177,25 @@ func ExtractOpenAPIOperations(doc *openapi3.T) []OpenAPIOperation {
 			}
 
 			tags := op.Tags
-			var security openapi3.SecurityRequirements
+			// Operations inherit the document-level security; "security: []" removes it (kept as an empty, non-nil slice)
+			security := doc.Security
 			if op.Security != nil {
-				security = *op.Security
-			} else {
-				security = doc.Security
+				security = append(openapi3.SecurityRequirements{}, *op.Security...)
 			}
 			ops = append(ops, OpenAPIOperation{
-				OperationID: id,
-				Summary:     op.Summary,
-				Description: desc,
-				Path:        path,
-				Method:      method,
-				Parameters:  mergedParams,
-				RequestBody: op.RequestBody,
-				Tags:        tags,
-				Security:    security,
-				Deprecated:  op.Deprecated,
+				OperationID:  id,
+				Summary:      op.Summary,
+				Description:  desc,
+				Path:         path,
+				Method:       method,
+				Parameters:   mergedParams,
+				RequestBody:  op.RequestBody,
+				Tags:         tags,
+				Security:     security,
+				Deprecated:   op.Deprecated,
+				Responses:    op.Responses,
+				Callbacks:    op.Callbacks,
+				ExternalDocs: op.ExternalDocs,
 			})
 		}
 	}
@@ -201,9 +208,9 @@ func ExtractOpenAPIOperations(doc *openapi3.T) []OpenAPIOperation {
 //
 //	include := regexp.MustCompile("pets")
 //	filtered := openapi2mcp.ExtractFilteredOpenAPIOperations(doc, include, nil)
-func ExtractFilteredOpenAPIOperations(doc *openapi3.T, includeRegex, excludeRegex *regexp.Regexp) []OpenAPIOperation {
+func ExtractFilteredOpenAPIOperations(doc *openapi3.T, includeRegex, excludeRegex *regexp.Regexp) Operations {
 	all := ExtractOpenAPIOperations(doc)
-	var filtered []OpenAPIOperation
+	var filtered Operations
 	for _, op := range all {
 		desc := op.Description
 		if desc == "" {
@@ -219,3 +226,48 @@ func ExtractFilteredOpenAPIOperations(doc *openapi3.T, includeRegex, excludeRege
 	}
 	return filtered
 }
+
+// Operations is a list of operations, as returned by ExtractOpenAPIOperations.
+// Its filter methods return new lists and can be chained.
+// Example usage for Operations:
+//
+//	ops := openapi2mcp.ExtractOpenAPIOperations(doc).ByTag("billing").ByMethod("GET")
+//	openapi2mcp.RegisterOpenAPITools(server, ops, doc, nil)
+type Operations []OpenAPIOperation
+
+// Filter returns the operations for which keep returns true.
+func (ops Operations) Filter(keep func(op OpenAPIOperation) bool) Operations {
+	var filtered Operations
+	for _, op := range ops {
+		if keep(op) {
+			filtered = append(filtered, op)
+		}
+	}
+	return filtered
+}
+
+// ByTag returns the operations having at least one of tags. Without tags, all operations are returned.
+func (ops Operations) ByTag(tags ...string) Operations {
+	if len(tags) == 0 {
+		return ops
+	}
+	return ops.Filter(func(op OpenAPIOperation) bool {
+		return slices.ContainsFunc(op.Tags, func(tag string) bool { return slices.Contains(tags, tag) })
+	})
+}
+
+// ByMethod returns the operations using one of the HTTP methods, compared case-insensitively.
+func (ops Operations) ByMethod(methods ...string) Operations {
+	return ops.Filter(func(op OpenAPIOperation) bool {
+		return slices.ContainsFunc(methods, func(method string) bool { return strings.EqualFold(method, op.Method) })
+	})
+}
+
+// ByPathPrefix returns the operations whose path is prefix or lies below it. Prefixes match whole
+// path segments: "/pets" matches "/pets" and "/pets/{id}", but not "/petstore".
+func (ops Operations) ByPathPrefix(prefix string) Operations {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return ops.Filter(func(op OpenAPIOperation) bool {
+		return op.Path == prefix || strings.HasPrefix(op.Path, prefix+"/")
+	})
+}
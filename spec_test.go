@@ -0,0 +1,72 @@
+package openapi2mcp
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestOperations_Filters(t *testing.T) {
+	ops := Operations{
+		{OperationID: "listInvoices", Path: "/billing/invoices", Method: "GET", Tags: []string{"billing"}},
+		{OperationID: "createInvoice", Path: "/billing/invoices", Method: "POST", Tags: []string{"billing", "admin"}},
+		{OperationID: "getPlan", Path: "/billingplans/{id}", Method: "GET", Tags: []string{"plans"}},
+		{OperationID: "getUser", Path: "/users/{id}", Method: "GET"},
+	}
+	ids := func(ops Operations) []string {
+		var ids []string
+		for _, op := range ops {
+			ids = append(ids, op.OperationID)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name string
+		got  Operations
+		want []string
+	}{
+		{"ByTag", ops.ByTag("billing"), []string{"listInvoices", "createInvoice"}},
+		{"ByTag any of", ops.ByTag("admin", "plans"), []string{"createInvoice", "getPlan"}},
+		{"ByTag none", ops.ByTag(), ids(ops)},
+		{"ByMethod", ops.ByMethod("get"), []string{"listInvoices", "getPlan", "getUser"}},
+		{"ByPathPrefix", ops.ByPathPrefix("/billing/"), []string{"listInvoices", "createInvoice"}},
+		{"chained", ops.ByTag("billing").ByMethod("GET"), []string{"listInvoices"}},
+		{"no match", ops.ByPathPrefix("/orders"), nil},
+	}
+	for _, tc := range tests {
+		if got := ids(tc.got); !slices.Equal(got, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestExtractOpenAPIOperations_Security(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+security: [{token: []}]
+components:
+  securitySchemes:
+    token: {type: apiKey, in: header, name: X-Token}
+paths:
+  /pets:
+    get: {operationId: listPets, responses: {"200": {description: ok}}}
+  /health:
+    get: {operationId: health, security: [], responses: {"200": {description: ok}}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ops := ExtractOpenAPIOperations(doc)
+	for _, op := range ops {
+		switch op.OperationID {
+		case "listPets":
+			if len(op.Security) != 1 {
+				t.Errorf("expected listPets to inherit the document security, got %v", op.Security)
+			}
+		case "health":
+			if op.Security == nil || len(op.Security) != 0 {
+				t.Errorf("expected an empty, non-nil security for health, got %#v", op.Security)
+			}
+		}
+	}
+}
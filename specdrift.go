@@ -0,0 +1,222 @@
+// specdrift.go
+package openapi2mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// specDriftURI is the resource showing the result of the last comparison with the API's spec.
+const specDriftURI = "openapi://spec/drift"
+
+// DefaultSpecDriftURL is where the API's own spec is fetched from if SpecDriftCheck.URL is not set.
+const DefaultSpecDriftURL = "/openapi.json"
+
+// DefaultSpecDriftInterval is the time between comparisons if SpecDriftCheck.Interval is not set.
+const DefaultSpecDriftInterval = time.Hour
+
+// maxSpecDriftBytes caps the size of the API's spec read for a comparison.
+const maxSpecDriftBytes = 32 << 20
+
+// specDriftTimeout bounds the fetch of the API's spec.
+const specDriftTimeout = 30 * time.Second
+
+// SpecDriftCheck compares the loaded spec periodically with the one the API serves itself, e.g. at /openapi.json,
+// and warns when they diverge, so that users know their tools are outdated. The specs are compared by the tools
+// they generate, and by their info.version.
+type SpecDriftCheck struct {
+	URL      string          // URL of the API's spec, absolute or relative to the base URL; "" means DefaultSpecDriftURL
+	Interval time.Duration   // time between comparisons, the first right at startup; 0 means DefaultSpecDriftInterval
+	Context  context.Context // the comparisons stop once it is done, e.g. when the server shuts down; nil means never
+}
+
+// SpecDrift is the result of a comparison of the loaded spec with the API's, as served by openapi://spec/drift.
+type SpecDrift struct {
+	URL           string           `json:"url"`                     // URL the API's spec was fetched from
+	CheckedAt     time.Time        `json:"checkedAt"`               // time of the comparison
+	Stale         bool             `json:"stale"`                   // whether the specs diverge
+	LoadedVersion string           `json:"loadedVersion,omitempty"` // info.version of the loaded spec
+	LiveVersion   string           `json:"liveVersion,omitempty"`   // info.version of the API's spec
+	Diff          *ToolSurfaceDiff `json:"diff,omitempty"`          // tools added, removed, and changed in the API's spec
+	Error         string           `json:"error,omitempty"`         // why the API's spec couldn't be compared
+}
+
+// specDriftChecker compares the loaded spec with the API's and keeps the last result.
+type specDriftChecker struct {
+	url    string
+	send   func(*http.Request) (*http.Response, error)
+	doc    *openapi3.T
+	opts   *ToolGenOptions
+	logger *slog.Logger
+	loaded func() []ToolSummary // tools of the loaded spec, generated once
+
+	mu       sync.Mutex
+	last     *SpecDrift
+	reported string // the last drift or error logged, so that it isn't repeated every interval
+}
+
+// newSpecDriftChecker creates a checker fetching the API's spec relative to baseURL with send.
+func newSpecDriftChecker(check *SpecDriftCheck, baseURL string, send func(*http.Request) (*http.Response, error), doc *openapi3.T, opts *ToolGenOptions, logger *slog.Logger) *specDriftChecker {
+	specURL := check.URL
+	if specURL == "" {
+		specURL = DefaultSpecDriftURL
+	}
+	if base, err := url.Parse(baseURL); err == nil {
+		if resolved, err := base.Parse(specURL); err == nil {
+			specURL = resolved.String()
+		}
+	}
+	return &specDriftChecker{
+		url:    specURL,
+		send:   send,
+		doc:    doc,
+		opts:   opts,
+		logger: logger,
+		loaded: sync.OnceValue(func() []ToolSummary {
+			return GenerateToolSummaries(ExtractOpenAPIOperations(doc), doc, opts)
+		}),
+	}
+}
+
+// start compares the specs right away and then every interval, in the background, until ctx is done.
+func (c *specDriftChecker) start(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultSpecDriftInterval
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			c.check(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// check fetches the API's spec, compares it with the loaded one, logs new drift, and returns the result.
+func (c *specDriftChecker) check(ctx context.Context) *SpecDrift {
+	drift := &SpecDrift{URL: c.url, CheckedAt: time.Now().UTC()}
+	if c.doc.Info != nil {
+		drift.LoadedVersion = c.doc.Info.Version
+	}
+	live, err := c.fetch(ctx)
+	if err != nil {
+		drift.Error = err.Error()
+	} else {
+		if live.Info != nil {
+			drift.LiveVersion = live.Info.Version
+		}
+		drift.Diff = DiffToolSummaries(c.loaded(), GenerateToolSummaries(ExtractOpenAPIOperations(live), live, c.opts))
+		drift.Stale = drift.LiveVersion != drift.LoadedVersion || len(drift.Diff.Added)+len(drift.Diff.Removed)+len(drift.Diff.Changed) > 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.last = drift
+	var report string
+	switch {
+	case drift.Error != "":
+		report = fmt.Sprintf("Could not compare the spec with the API's at %s: %s", c.url, drift.Error)
+	case drift.Stale:
+		report = driftText(drift)
+	}
+	if report != "" && report != c.reported {
+		warnf(c.logger, "%s", report)
+	}
+	c.reported = report
+	return drift
+}
+
+// fetch loads the API's spec.
+func (c *specDriftChecker) fetch(ctx context.Context) (*openapi3.T, error) {
+	ctx, cancel := context.WithTimeout(ctx, specDriftTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json, application/yaml;q=0.9, */*;q=0.1")
+	// Some APIs only serve their spec to authenticated clients
+	applySecurity(req, c.doc.Security, c.doc, nil)
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSpecDriftBytes))
+	if err != nil {
+		return nil, err
+	}
+	// The API's spec is only compared, so it isn't validated: specs with minor problems still show drift
+	live, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid spec: %w", err)
+	}
+	return live, nil
+}
+
+// snapshot returns the result of the last comparison, or nil before the first one finished.
+func (c *specDriftChecker) snapshot() *SpecDrift {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.last
+}
+
+// driftText describes how the API's spec diverges from the loaded one.
+func driftText(drift *SpecDrift) string {
+	var changes []string
+	if drift.LiveVersion != drift.LoadedVersion {
+		changes = append(changes, fmt.Sprintf("version %q instead of %q", drift.LiveVersion, drift.LoadedVersion))
+	}
+	for _, c := range []struct {
+		what  string
+		tools []string
+	}{{"added", drift.Diff.Added}, {"removed", drift.Diff.Removed}, {"changed", drift.Diff.Changed}} {
+		if len(c.tools) > 0 {
+			changes = append(changes, fmt.Sprintf("%d tools %s (%s)", len(c.tools), c.what, strings.Join(c.tools, ", ")))
+		}
+	}
+	breaking := ""
+	if drift.Diff.Breaking {
+		breaking = " Some changes are breaking, so calls may fail."
+	}
+	return fmt.Sprintf("The API's spec at %s differs from the loaded one: %s.%s Update the spec and restart the server to refresh the tools; %s shows the details.", drift.URL, strings.Join(changes, "; "), breaking, specDriftURI)
+}
+
+// registerSpecDriftResource adds the openapi://spec/drift resource showing the last comparison with the API's spec.
+func registerSpecDriftResource(server *mcp.Server, checker *specDriftChecker) {
+	resource := &mcp.Resource{
+		URI:         specDriftURI,
+		Name:        "OpenAPI Spec Drift",
+		Description: "Whether the loaded spec is outdated: the last comparison with the spec the API serves at " + checker.url + ", with the tools added, removed, and changed since",
+		MIMEType:    "application/json",
+	}
+	server.AddResource(resource, func(context.Context, *mcp.ServerRequest[*mcp.ReadResourceParams]) (*mcp.ReadResourceResult, error) {
+		drift := checker.snapshot()
+		if drift == nil {
+			drift = &SpecDrift{URL: checker.url, Error: "not compared yet"}
+		}
+		text, _ := json.MarshalIndent(drift, "", "  ")
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{URI: resource.URI, MIMEType: "application/json", Text: string(text)}},
+		}, nil
+	})
+}
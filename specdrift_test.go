@@ -0,0 +1,143 @@
+package openapi2mcp
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const driftLoadedSpec = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Pets", "version": "1.0.0"},
+  "paths": {
+    "/pets": {"get": {"operationId": "listPets", "responses": {"200": {"description": "OK"}}}},
+    "/pets/{id}": {"delete": {"operationId": "deletePet", "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}], "responses": {"204": {"description": "Deleted"}}}}
+  }
+}`
+
+const driftLiveSpec = `{
+  "openapi": "3.0.0",
+  "info": {"title": "Pets", "version": "1.1.0"},
+  "paths": {
+    "/pets": {"get": {"operationId": "listPets", "responses": {"200": {"description": "OK"}}}},
+    "/pets/{id}": {"get": {"operationId": "getPet", "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}], "responses": {"200": {"description": "OK"}}}}
+  }
+}`
+
+func TestSpecDriftChecker(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(driftLoadedSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fetched []string
+	live := driftLiveSpec
+	send := func(req *http.Request) (*http.Response, error) {
+		fetched = append(fetched, req.URL.String())
+		return fakeResponse(200, "application/json", live)(req)
+	}
+	var logs strings.Builder
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	checker := newSpecDriftChecker(&SpecDriftCheck{}, "https://api.example.com/v1", send, doc, &ToolGenOptions{}, logger)
+
+	drift := checker.check(context.Background())
+	if drift.Error != "" || !drift.Stale || drift.LiveVersion != "1.1.0" || drift.LoadedVersion != "1.0.0" {
+		t.Fatalf("unexpected drift: %+v", drift)
+	}
+	if strings.Join(drift.Diff.Added, ",") != "getPet" || strings.Join(drift.Diff.Removed, ",") != "deletePet" || !drift.Diff.Breaking {
+		t.Errorf("unexpected diff: %+v", drift.Diff)
+	}
+	if fetched[0] != "https://api.example.com/openapi.json" {
+		t.Errorf("expected the spec to be fetched from /openapi.json, got %s", fetched[0])
+	}
+
+	// The same drift is logged once
+	checker.check(context.Background())
+	if n := strings.Count(logs.String(), "differs from the loaded one"); n != 1 {
+		t.Errorf("expected the drift to be logged once, got %d times: %s", n, logs.String())
+	}
+
+	live = driftLoadedSpec
+	if drift := checker.check(context.Background()); drift.Stale || drift.Error != "" {
+		t.Errorf("expected identical specs not to drift: %+v", drift)
+	}
+}
+
+func TestSpecDriftChecker_Stops(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(driftLoadedSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fetches atomic.Int32
+	send := func(req *http.Request) (*http.Response, error) {
+		fetches.Add(1)
+		return fakeResponse(200, "application/json", driftLoadedSpec)(req)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	newSpecDriftChecker(&SpecDriftCheck{}, "https://api.example.com", send, doc, &ToolGenOptions{}, slog.New(discardHandler{})).start(ctx, time.Millisecond)
+	for fetches.Load() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	stopped := fetches.Load()
+	time.Sleep(20 * time.Millisecond)
+	if n := fetches.Load(); n != stopped {
+		t.Errorf("expected the comparisons to stop with the context, got %d more", n-stopped)
+	}
+}
+
+func TestSpecDriftChecker_FetchFails(t *testing.T) {
+	doc, _ := LoadOpenAPISpecFromString(driftLoadedSpec)
+	send := fakeResponse(404, "text/plain", "not found")
+	checker := newSpecDriftChecker(&SpecDriftCheck{URL: "docs/openapi.yaml"}, "https://api.example.com/v1/", send, doc, &ToolGenOptions{}, slog.New(discardHandler{}))
+	drift := checker.check(context.Background())
+	if drift.Stale || drift.Error != "HTTP 404" || drift.URL != "https://api.example.com/v1/docs/openapi.yaml" {
+		t.Errorf("unexpected drift: %+v", drift)
+	}
+}
+
+func TestSpecDriftResource(t *testing.T) {
+	doc, _ := LoadOpenAPISpecFromString(driftLoadedSpec)
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	opts := &ToolGenOptions{
+		SpecDrift:      &SpecDriftCheck{},
+		RequestHandler: fakeResponse(200, "application/json", driftLiveSpec),
+		LogHandler:     discardHandler{},
+	}
+	RegisterOpenAPITools(server, ExtractOpenAPIOperations(doc), doc, opts)
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(context.Background(), serverTransport, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil).Connect(context.Background(), clientTransport, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cs.Close()
+
+	var drift SpecDrift
+	for range 100 {
+		res, err := cs.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: specDriftURI})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal([]byte(res.Contents[0].Text), &drift); err != nil {
+			t.Fatal(err)
+		}
+		if !drift.CheckedAt.IsZero() {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !drift.Stale || drift.LiveVersion != "1.1.0" {
+		t.Errorf("expected the resource to show the drift, got %+v", drift)
+	}
+}
@@ -0,0 +1,98 @@
+// specresource.go
+package openapi2mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	specURI           = "openapi://spec"
+	specYAMLURI       = "openapi://spec.yaml"
+	specPathURIPrefix = "openapi://spec/paths/"
+)
+
+// servedDocument returns a copy of doc with only the operations of ops, i.e. the document as the tools
+// registered for ops see it. Paths without any of the operations are dropped; everything else is shared.
+func servedDocument(doc *openapi3.T, ops []OpenAPIOperation) *openapi3.T {
+	served := *doc
+	served.Paths = openapi3.NewPaths()
+	for _, op := range ops {
+		item := doc.Paths.Value(op.Path)
+		if item == nil {
+			continue
+		}
+		operation := item.GetOperation(strings.ToUpper(op.Method))
+		if operation == nil {
+			continue
+		}
+		kept := served.Paths.Value(op.Path)
+		if kept == nil {
+			copied := *item
+			for method := range item.Operations() {
+				copied.SetOperation(method, nil)
+			}
+			kept = &copied
+			served.Paths.Set(op.Path, kept)
+		}
+		kept.SetOperation(strings.ToUpper(op.Method), operation)
+	}
+	return &served
+}
+
+// registerSpecResources adds the openapi://spec (JSON) and openapi://spec.yaml resources serving the document
+// the tools were generated from, and the openapi://spec/paths/{path} template serving a single path item.
+func registerSpecResources(server *mcp.Server, doc *openapi3.T) {
+	specJSON := sync.OnceValues(func() ([]byte, error) { return json.MarshalIndent(doc, "", "  ") })
+	specYAML := sync.OnceValues(func() ([]byte, error) { return yaml.Marshal(doc) })
+
+	for _, r := range []struct {
+		resource *mcp.Resource
+		marshal  func() ([]byte, error)
+	}{
+		{&mcp.Resource{URI: specURI, Name: "OpenAPI Spec", MIMEType: "application/json"}, specJSON},
+		{&mcp.Resource{URI: specYAMLURI, Name: "OpenAPI Spec (YAML)", MIMEType: "application/yaml"}, specYAML},
+	} {
+		r.resource.Description = "OpenAPI document the tools were generated from, with the operations that are not served as tools removed"
+		server.AddResource(r.resource, func(context.Context, *mcp.ServerRequest[*mcp.ReadResourceParams]) (*mcp.ReadResourceResult, error) {
+			text, err := r.marshal()
+			if err != nil {
+				return nil, err
+			}
+			return &mcp.ReadResourceResult{
+				Contents: []*mcp.ResourceContents{{URI: r.resource.URI, MIMEType: r.resource.MIMEType, Text: string(text)}},
+			}, nil
+		})
+	}
+
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		URITemplate: specPathURIPrefix + "{path}",
+		Name:        "OpenAPI Path",
+		Description: "Path item of the OpenAPI document by its URL-escaped path, e.g. openapi://spec/paths/%2Fpets%2F%7BpetId%7D",
+		MIMEType:    "application/json",
+	}, func(_ context.Context, req *mcp.ServerRequest[*mcp.ReadResourceParams]) (*mcp.ReadResourceResult, error) {
+		uri := req.Params.URI
+		path, err := url.PathUnescape(strings.TrimPrefix(uri, specPathURIPrefix))
+		if err != nil {
+			return nil, mcp.ResourceNotFoundError(uri)
+		}
+		item := doc.Paths.Value(path)
+		if item == nil {
+			return nil, mcp.ResourceNotFoundError(uri)
+		}
+		text, err := json.MarshalIndent(item, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{URI: uri, MIMEType: "application/json", Text: string(text)}},
+		}, nil
+	})
+}
@@ -0,0 +1,80 @@
+package openapi2mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestRegisterOpenAPITools_SpecResources(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Pet Store, version: "1.0"}
+paths:
+  /pets:
+    get: {operationId: listPets, tags: [pets], responses: {"200": {description: ok}}}
+    post: {operationId: createPet, tags: [admin], responses: {"201": {description: created}}}
+  /pets/{petId}:
+    get:
+      operationId: getPet
+      tags: [pets]
+      parameters: [{name: petId, in: path, required: true, schema: {type: string}}]
+      responses: {"200": {description: ok}}
+  /admin/users:
+    get: {operationId: listUsers, tags: [admin], responses: {"200": {description: ok}}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	RegisterOpenAPITools(server, ExtractOpenAPIOperations(doc), doc, &ToolGenOptions{TagFilter: []string{"pets"}})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(context.Background(), serverTransport, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil).Connect(context.Background(), clientTransport, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cs.Close()
+
+	read := func(uri string) (string, error) {
+		res, err := cs.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: uri})
+		if err != nil {
+			return "", err
+		}
+		return res.Contents[0].Text, nil
+	}
+
+	// Operations filtered out are not served
+	for _, uri := range []string{"openapi://spec", "openapi://spec.yaml"} {
+		text, err := read(uri)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", uri, err)
+		}
+		if !strings.Contains(text, "listPets") || !strings.Contains(text, "getPet") || strings.Contains(text, "createPet") || strings.Contains(text, "/admin/users") {
+			t.Errorf("%s: expected only the operations tagged pets, got:\n%s", uri, text)
+		}
+	}
+	if text, _ := read("openapi://spec.yaml"); !strings.Contains(text, "title: Pet Store") {
+		t.Errorf("expected YAML, got:\n%s", text)
+	}
+
+	text, err := read("openapi://spec/paths/%2Fpets%2F%7BpetId%7D")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(text, `"operationId": "getPet"`) {
+		t.Errorf("expected the path item, got:\n%s", text)
+	}
+	if _, err := read("openapi://spec/paths/%2Fadmin%2Fusers"); err == nil {
+		t.Error("expected an error for a path that is not served")
+	}
+
+	// The original document is left unchanged
+	if doc.Paths.Value("/pets").Post == nil || doc.Paths.Value("/admin/users") == nil {
+		t.Error("expected the original document to keep all operations")
+	}
+}
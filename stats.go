@@ -0,0 +1,142 @@
+// stats.go
+package openapi2mcp
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+)
+
+// callTelemetry describes the outcome of a single tool call.
+type callTelemetry struct {
+	Elapsed       time.Duration
+	HTTPStatus    int
+	BytesSent     int
+	BytesReceived int
+	Retries       int  // upstream requests repeated by withRetries
+	CacheHit      bool // the response was shared with an identical call in flight
+	Prefetches    int  // GET requests fetching the ETag or version of an update
+	Failed        bool
+}
+
+// callTelemetryKey is the context key of the telemetry of the tool call an upstream request is sent for.
+type callTelemetryKey struct{}
+
+// withCallTelemetry returns ctx recording the retries of the upstream requests sent with it in telemetry.
+func withCallTelemetry(ctx context.Context, telemetry *callTelemetry) context.Context {
+	return context.WithValue(ctx, callTelemetryKey{}, telemetry)
+}
+
+// countRetry counts a retry in the telemetry of ctx, if any.
+func countRetry(ctx context.Context) {
+	if telemetry, _ := ctx.Value(callTelemetryKey{}).(*callTelemetry); telemetry != nil {
+		telemetry.Retries++
+	}
+}
+
+// String renders the telemetry as a compact single line appended to tool results.
+func (t *callTelemetry) String() string {
+	status := "error"
+	if t.HTTPStatus > 0 {
+		status = fmt.Sprintf("HTTP %d", t.HTTPStatus)
+	}
+	cache := "miss"
+	if t.CacheHit {
+		cache = "hit"
+	}
+	line := fmt.Sprintf("[telemetry] %s · %s · %s sent · %s received · retries %d · cache %s",
+		t.Elapsed.Round(time.Millisecond), status, formatBytes(t.BytesSent), formatBytes(t.BytesReceived), t.Retries, cache)
+	if t.Prefetches > 0 {
+		line += fmt.Sprintf(" · prefetches %d", t.Prefetches)
+	}
+	return line
+}
+
+// formatBytes renders a byte count in human-readable units.
+func formatBytes(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+// OperationStats holds aggregated call statistics for a single tool.
+type OperationStats struct {
+	Calls         int            `json:"calls"`
+	Errors        int            `json:"errors"`
+	ErrorRate     float64        `json:"error_rate"`
+	AvgMillis     float64        `json:"avg_ms"`
+	MaxMillis     float64        `json:"max_ms"`
+	LastMillis    float64        `json:"last_ms"`
+	Retries       int            `json:"retries"`
+	CacheHits     int            `json:"cache_hits"`
+	Prefetches    int            `json:"prefetches"`
+	BytesReceived int64          `json:"bytes_received"`
+	StatusCounts  map[string]int `json:"status_counts,omitempty"`
+	LastCalledAt  time.Time      `json:"last_called_at"`
+
+	totalDuration time.Duration
+}
+
+// statsRegistry aggregates call telemetry per tool.
+type statsRegistry struct {
+	mu  sync.Mutex
+	ops map[string]*OperationStats
+}
+
+func newStatsRegistry() *statsRegistry {
+	return &statsRegistry{ops: make(map[string]*OperationStats)}
+}
+
+// record adds the telemetry of one call to the tool's aggregates.
+func (r *statsRegistry) record(tool string, t *callTelemetry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.ops[tool]
+	if !ok {
+		s = &OperationStats{StatusCounts: make(map[string]int)}
+		r.ops[tool] = s
+	}
+	s.Calls++
+	if t.Failed {
+		s.Errors++
+	}
+	s.ErrorRate = float64(s.Errors) / float64(s.Calls)
+	s.totalDuration += t.Elapsed
+	s.AvgMillis = float64(s.totalDuration.Microseconds()) / 1000 / float64(s.Calls)
+	s.LastMillis = float64(t.Elapsed.Microseconds()) / 1000
+	s.MaxMillis = max(s.MaxMillis, s.LastMillis)
+	s.Retries += t.Retries
+	if t.CacheHit {
+		s.CacheHits++
+	}
+	s.Prefetches += t.Prefetches
+	s.BytesReceived += int64(t.BytesReceived)
+	status := "error"
+	if t.HTTPStatus > 0 {
+		status = fmt.Sprintf("%d", t.HTTPStatus)
+	}
+	s.StatusCounts[status]++
+	s.LastCalledAt = time.Now()
+}
+
+// snapshot returns a copy of the aggregates, keyed by tool name.
+func (r *statsRegistry) snapshot() map[string]OperationStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make(map[string]OperationStats, len(r.ops))
+	for name, s := range r.ops {
+		c := *s
+		c.StatusCounts = maps.Clone(s.StatusCounts)
+		out[name] = c
+	}
+	return out
+}
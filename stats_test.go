@@ -0,0 +1,57 @@
+package openapi2mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestCallTelemetryString(t *testing.T) {
+	tel := &callTelemetry{Elapsed: 12 * time.Millisecond, HTTPStatus: 200, BytesReceived: 1229}
+	want := "[telemetry] 12ms · HTTP 200 · 0 B sent · 1.2 KB received · retries 0 · cache miss"
+	if got := tel.String(); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestStatsRegistry(t *testing.T) {
+	r := newStatsRegistry()
+	r.record("getPet", &callTelemetry{Elapsed: 10 * time.Millisecond, HTTPStatus: 200, BytesReceived: 100})
+	r.record("getPet", &callTelemetry{Elapsed: 30 * time.Millisecond, HTTPStatus: 500, Failed: true, Retries: 2})
+
+	s := r.snapshot()["getPet"]
+	if s.Calls != 2 || s.Errors != 1 || s.ErrorRate != 0.5 || s.Retries != 2 || s.BytesReceived != 100 {
+		t.Errorf("unexpected stats: %+v", s)
+	}
+	if s.AvgMillis != 20 || s.MaxMillis != 30 || s.LastMillis != 30 {
+		t.Errorf("unexpected latencies: %+v", s)
+	}
+	if s.StatusCounts["200"] != 1 || s.StatusCounts["500"] != 1 {
+		t.Errorf("unexpected status counts: %v", s.StatusCounts)
+	}
+}
+
+func TestToolHandler_Telemetry(t *testing.T) {
+	op := OpenAPIOperation{OperationID: "getPet", Path: "/pet", Method: "get"}
+	opts := &ToolGenOptions{
+		Telemetry:      true,
+		RequestHandler: fakeResponse(200, "application/json", `{"id": 1}`),
+	}
+	rt := newServerRuntime()
+	handler := toolHandler("getPet", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, rt)
+	res, _, err := handler(context.Background(), nil, map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	last, ok := res.Content[len(res.Content)-1].(*mcp.TextContent)
+	if !ok || !strings.HasPrefix(last.Text, "[telemetry]") || !strings.Contains(last.Text, "HTTP 200") {
+		t.Errorf("expected telemetry line, got %+v", res.Content)
+	}
+	if s := rt.stats.snapshot()["getPet"]; s.Calls != 1 || s.BytesReceived != 9 {
+		t.Errorf("unexpected stats: %+v", s)
+	}
+}
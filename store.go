@@ -0,0 +1,380 @@
+// store.go
+package openapi2mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// auditRetention is how long the audit records of tool calls are kept in a Store.
+const auditRetention = 30 * 24 * time.Hour
+
+// Store persists server state that should survive restarts and be shared by the replicas of an HTTP deployment:
+// the raw responses served by result://{call_id}, the cookies and CSRF tokens of MCP sessions, scheduled calls, and
+// audit records of tool calls. Keys are slash-separated, e.g. "sessions/<id>"; values are opaque. Implementations
+// must be safe for concurrent use. NewMemoryStore and NewFileStore are built in; other backends are used by
+// implementing its four methods.
+type Store interface {
+	// Get returns the value of key, and false if it isn't set or has expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set sets the value of key, expiring after ttl (never if 0).
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes key; removing a key that isn't set is not an error.
+	Delete(ctx context.Context, key string) error
+	// Keys returns the keys starting with prefix that haven't expired, in any order.
+	Keys(ctx context.Context, prefix string) ([]string, error)
+}
+
+// memoryStoreSweep is how often a memoryStore removes its expired entries on write.
+const memoryStoreSweep = time.Minute
+
+// NewMemoryStore creates a Store keeping the state in memory, e.g. for tests; it is lost on restart and not shared.
+func NewMemoryStore() Store {
+	return &memoryStore{entries: make(map[string]storeEntry), now: time.Now}
+}
+
+// memoryStore is the Store of NewMemoryStore.
+type memoryStore struct {
+	mu      sync.Mutex
+	entries map[string]storeEntry
+	swept   time.Time // when expired entries were last removed
+	now     func() time.Time
+}
+
+// storeEntry is a value and its expiry, zero if it never expires.
+type storeEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+// expired reports whether the entry has expired at now.
+func (e storeEntry) expired(now time.Time) bool {
+	return !e.expires.IsZero() && !now.Before(e.expires)
+}
+
+// expiry returns the expiry of a value set at now with ttl.
+func expiry(now time.Time, ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return now.Add(ttl)
+}
+
+func (s *memoryStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[key]
+	if !ok || entry.expired(s.now()) {
+		return nil, false, nil
+	}
+	return bytes.Clone(entry.value), true, nil
+}
+
+func (s *memoryStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	if now.Sub(s.swept) >= memoryStoreSweep {
+		s.swept = now
+		for k, entry := range s.entries {
+			if entry.expired(now) {
+				delete(s.entries, k)
+			}
+		}
+	}
+	s.entries[key] = storeEntry{value: bytes.Clone(value), expires: expiry(now, ttl)}
+	return nil
+}
+
+func (s *memoryStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+func (s *memoryStore) Keys(_ context.Context, prefix string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	var keys []string
+	for key, entry := range s.entries {
+		if entry.expired(now) {
+			delete(s.entries, key)
+			continue
+		}
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// NewFileStore creates a Store keeping each key in a file below dir, which is created if needed. Replicas can share
+// the state through a shared volume, as values are replaced atomically.
+//
+// Example usage for NewFileStore:
+//
+//	store, err := openapi2mcp.NewFileStore("/var/lib/openapi-mcp")
+//	if err != nil { log.Fatal(err) }
+//	opts.Store = store
+func NewFileStore(dir string) (Store, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &fileStore{dir: dir, now: time.Now}, nil
+}
+
+// fileStore is the Store of NewFileStore. Each file holds the expiry as Unix milliseconds (0 for none) on its first
+// line, followed by the value.
+type fileStore struct {
+	dir string
+	now func() time.Time
+}
+
+// path returns the file of key; each segment is escaped, so that keys can't leave the directory.
+func (s *fileStore) path(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = escapeStoreSegment(segment)
+	}
+	return filepath.Join(append([]string{s.dir}, segments...)...)
+}
+
+// escapeStoreSegment escapes a key segment to a file name: characters other than letters, digits, '-', and '_' are
+// written as %XX, which also escapes "." and "..".
+func escapeStoreSegment(segment string) string {
+	var sb strings.Builder
+	for _, b := range []byte(segment) {
+		if 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9' || b == '-' || b == '_' {
+			sb.WriteByte(b)
+		} else {
+			fmt.Fprintf(&sb, "%%%02X", b)
+		}
+	}
+	if sb.Len() == 0 {
+		return "%"
+	}
+	return sb.String()
+}
+
+// unescapeStoreSegment reverses escapeStoreSegment.
+func unescapeStoreSegment(name string) string {
+	if name == "%" {
+		return ""
+	}
+	var sb strings.Builder
+	for i := 0; i < len(name); i++ {
+		if name[i] == '%' && i+2 < len(name) {
+			if b, err := strconv.ParseUint(name[i+1:i+3], 16, 8); err == nil {
+				sb.WriteByte(byte(b))
+				i += 2
+				continue
+			}
+		}
+		sb.WriteByte(name[i])
+	}
+	return sb.String()
+}
+
+// encode returns the file contents of value with ttl.
+func (s *fileStore) encode(value []byte, ttl time.Duration) []byte {
+	var expires int64
+	if e := expiry(s.now(), ttl); !e.IsZero() {
+		expires = e.UnixMilli()
+	}
+	return append([]byte(strconv.FormatInt(expires, 10)+"\n"), value...)
+}
+
+// read returns the entry of the file at path.
+func (s *fileStore) read(path string) (storeEntry, bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return storeEntry{}, false, nil
+	}
+	if err != nil {
+		return storeEntry{}, false, err
+	}
+	header, value, ok := bytes.Cut(data, []byte("\n"))
+	expires, err := strconv.ParseInt(string(header), 10, 64)
+	if !ok || err != nil {
+		return storeEntry{}, false, fmt.Errorf("corrupt store file %s", path)
+	}
+	entry := storeEntry{value: value}
+	if expires > 0 {
+		entry.expires = time.UnixMilli(expires)
+	}
+	return entry, true, nil
+}
+
+func (s *fileStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	entry, ok, err := s.read(s.path(key))
+	if err != nil || !ok || entry.expired(s.now()) {
+		return nil, false, err
+	}
+	return entry.value, true, nil
+}
+
+func (s *fileStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(s.encode(value, ttl)); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *fileStore) Delete(_ context.Context, key string) error {
+	err := os.Remove(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *fileStore) Keys(_ context.Context, prefix string) ([]string, error) {
+	// Only the directory of the prefix is walked, so that e.g. listing "schedules/" doesn't read the audit records
+	root := s.dir
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		root = s.path(prefix[:i])
+	}
+	var keys []string
+	now := s.now()
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".tmp-") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.dir, path)
+		if err != nil {
+			return err
+		}
+		segments := strings.Split(filepath.ToSlash(rel), "/")
+		for i, segment := range segments {
+			segments[i] = unescapeStoreSegment(segment)
+		}
+		key := strings.Join(segments, "/")
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		entry, ok, err := s.read(path)
+		if err != nil || !ok {
+			return err
+		}
+		if entry.expired(now) {
+			os.Remove(path)
+			return nil
+		}
+		keys = append(keys, key)
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	slices.Sort(keys)
+	return keys, err
+}
+
+// storeJSON sets key to the JSON of v.
+func storeJSON(ctx context.Context, store Store, key string, v any, ttl time.Duration) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return store.Set(ctx, key, data, ttl)
+}
+
+// loadJSON reads the JSON value of key into v, and reports whether it is set.
+func loadJSON(ctx context.Context, store Store, key string, v any) (bool, error) {
+	data, ok, err := store.Get(ctx, key)
+	if err != nil || !ok {
+		return false, err
+	}
+	return true, json.Unmarshal(data, v)
+}
+
+// auditRecord records a tool call in the Store, kept for auditRetention under "audit/<time>-<call ID>".
+type auditRecord struct {
+	Time       time.Time `json:"time"`
+	CallID     string    `json:"call_id"`
+	Tool       string    `json:"tool"`
+	Operation  string    `json:"operation"`
+	Session    string    `json:"session,omitempty"`
+	HTTPStatus int       `json:"http_status,omitempty"`
+	Failed     bool      `json:"failed"`
+	ElapsedMS  int64     `json:"elapsed_ms"`
+}
+
+// key returns the store key of the record, sorting by time.
+func (r auditRecord) key() string {
+	return "audit/" + r.Time.UTC().Format("20060102T150405.000000000Z") + "-" + r.CallID
+}
+
+// auditQueueSize is the number of audit records waiting to be written; further records are dropped.
+const auditQueueSize = 1024
+
+// auditWriteTimeout bounds the write of each audit record.
+const auditWriteTimeout = 5 * time.Second
+
+// auditLog writes the audit records of tool calls in the background, so that a slow store doesn't delay the calls.
+type auditLog struct {
+	queue chan pendingAudit
+	once  sync.Once
+}
+
+// pendingAudit is an audit record waiting to be written to store.
+type pendingAudit struct {
+	store  Store
+	record auditRecord
+	logger *slog.Logger
+}
+
+func newAuditLog() *auditLog {
+	return &auditLog{queue: make(chan pendingAudit, auditQueueSize)}
+}
+
+// add queues record for writing to store, dropping it with a warning if the queue is full.
+func (a *auditLog) add(store Store, record auditRecord, logger *slog.Logger) {
+	a.once.Do(func() { go a.run() })
+	select {
+	case a.queue <- pendingAudit{store: store, record: record, logger: logger}:
+	default:
+		warnf(logger, "Dropped the audit record of call %s: the store isn't keeping up", record.CallID)
+	}
+}
+
+// run writes the queued records.
+func (a *auditLog) run() {
+	for p := range a.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), auditWriteTimeout)
+		if err := storeJSON(ctx, p.store, p.record.key(), p.record, auditRetention); err != nil {
+			warnf(p.logger, "Could not write the audit record of call %s: %v", p.record.CallID, err)
+		}
+		cancel()
+	}
+}
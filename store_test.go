@@ -0,0 +1,257 @@
+package openapi2mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestStores(t *testing.T) {
+	files, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, store := range map[string]Store{"memory": NewMemoryStore(), "file": files} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			now := time.Now()
+			switch s := store.(type) {
+			case *memoryStore:
+				s.now = func() time.Time { return now }
+			case *fileStore:
+				s.now = func() time.Time { return now }
+			}
+
+			if _, ok, err := store.Get(ctx, "sessions/a"); ok || err != nil {
+				t.Fatalf("expected a missing key, got %v, %v", ok, err)
+			}
+			store.Set(ctx, "sessions/a", []byte("one"), 0)
+			store.Set(ctx, "sessions/../b c", []byte("two"), time.Minute)
+			store.Set(ctx, "results/x", []byte("three"), 0)
+			if value, ok, _ := store.Get(ctx, "sessions/a"); !ok || string(value) != "one" {
+				t.Errorf("expected the value, got %q, %v", value, ok)
+			}
+			keys, err := store.Keys(ctx, "sessions/")
+			slices.Sort(keys)
+			if err != nil || strings.Join(keys, ",") != "sessions/../b c,sessions/a" {
+				t.Errorf("unexpected keys %q, %v", keys, err)
+			}
+
+			// Values expire after their TTL
+			now = now.Add(2 * time.Minute)
+			if _, ok, _ := store.Get(ctx, "sessions/../b c"); ok {
+				t.Error("expected the value to have expired")
+			}
+			if keys, _ := store.Keys(ctx, "sessions/"); strings.Join(keys, ",") != "sessions/a" {
+				t.Errorf("expected expired keys not to be listed, got %q", keys)
+			}
+
+			store.Delete(ctx, "sessions/a")
+			if err := store.Delete(ctx, "sessions/a"); err != nil {
+				t.Errorf("expected deleting a missing key to succeed, got %v", err)
+			}
+			if _, ok, _ := store.Get(ctx, "sessions/a"); ok {
+				t.Error("expected the key to be deleted")
+			}
+		})
+	}
+}
+
+func TestMemoryStore_RemovesExpiredOnWrite(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	store := NewMemoryStore().(*memoryStore)
+	store.now = func() time.Time { return now }
+	store.Set(ctx, "audit/a", []byte("one"), time.Minute)
+	store.Set(ctx, "audit/b", []byte("two"), time.Hour)
+
+	now = now.Add(2 * memoryStoreSweep)
+	store.Set(ctx, "sessions/c", []byte("three"), 0)
+	if _, ok := store.entries["audit/a"]; ok || len(store.entries) != 2 {
+		t.Errorf("expected the expired entry to be removed, got %d entries", len(store.entries))
+	}
+}
+
+func TestSessionStore_Persisted(t *testing.T) {
+	store := NewMemoryStore()
+	replica := newSessionStore()
+	replica.store = store
+	send := func(req *http.Request) (*http.Response, error) {
+		resp, _ := fakeResponse(200, "application/json", `{"token":"csrf-1"}`)(req)
+		resp.Header.Add("Set-Cookie", "sid=abc; Path=/; Max-Age=3600")
+		return resp, nil
+	}
+	csrf := &CSRFConfig{Header: "X-CSRF-Token", Endpoint: "/csrf", Field: "token"}
+	req, _ := http.NewRequest(http.MethodPost, "https://api.example.com/orders", nil)
+	resp, err := replica.get("session-1").handler(send, csrf, "https://api.example.com")(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	// Another replica, or the server after a restart, continues the session
+	other := newSessionStore()
+	other.store = store
+	var cookie, token string
+	next := func(req *http.Request) (*http.Response, error) {
+		cookie, token = req.Header.Get("Cookie"), req.Header.Get("X-CSRF-Token")
+		return fakeResponse(200, "application/json", `{}`)(req)
+	}
+	req, _ = http.NewRequest(http.MethodPost, "https://api.example.com/orders", nil)
+	if _, err := other.get("session-1").handler(next, csrf, "https://api.example.com")(req); err != nil {
+		t.Fatal(err)
+	}
+	if cookie != "sid=abc" || token != "csrf-1" {
+		t.Errorf("expected the session's cookie and CSRF token, got %q and %q", cookie, token)
+	}
+	u, _ := url.Parse("https://api.example.com/")
+	if len(other.get("session-2").jar.Cookies(u)) != 0 {
+		t.Error("expected other sessions not to get the cookies")
+	}
+}
+
+func TestResultStore_Persisted(t *testing.T) {
+	store := NewMemoryStore()
+	results := newResultStore()
+	results.store = store
+	results.put("call-1", "text/html", []byte("<p>raw</p>"))
+
+	other := newResultStore()
+	other.store = store
+	if result, ok := other.get("call-1"); !ok || string(result.body) != "<p>raw</p>" || result.contentType != "text/html" {
+		t.Errorf("expected the result from the store, got %+v, %v", result, ok)
+	}
+	if _, ok := other.get("call-2"); ok {
+		t.Error("expected unknown calls not to be found")
+	}
+}
+
+func TestCallScheduler_Persisted(t *testing.T) {
+	var calls atomic.Int32
+	registry := newOperationRegistry(nil)
+	registry.add("listPets", OpenAPIOperation{OperationID: "listPets"}, func(context.Context, *mcp.CallToolRequest, map[string]any) (*mcp.CallToolResult, any, error) {
+		calls.Add(1)
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "pets"}}}, nil, nil
+	})
+	store := NewMemoryStore()
+	first := newCallScheduler(registry, store)
+	later, err := first.schedule(nil, "listPets", nil, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	soon, err := first.schedule(nil, "listPets", nil, time.Now().Add(50*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A second replica lists the pending calls, and cancels one the first replica scheduled
+	second := newCallScheduler(registry, store)
+	if listed := second.list(""); len(listed) != 2 {
+		t.Fatalf("expected both calls to be listed, got %+v", listed)
+	}
+	if cancelled, err := second.cancel("", later.ID); err != nil || cancelled.Status != scheduledCancelled {
+		t.Fatalf("expected the call to be cancelled, got %+v, %v", cancelled, err)
+	}
+	if _, err := first.cancel("", later.ID); err == nil {
+		t.Error("expected the first replica to see the call as cancelled")
+	}
+
+	// The call due soon runs on the replica it was scheduled on only, and both list its result
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if i := slices.IndexFunc(first.list(""), func(c scheduledCall) bool { return c.ID == soon.ID }); first.list("")[i].Status == scheduledDone {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected the call to run once, got %d calls", n)
+	}
+	for _, scheduler := range []*callScheduler{first, second} {
+		i := slices.IndexFunc(scheduler.list(""), func(c scheduledCall) bool { return c.ID == soon.ID })
+		if call := scheduler.list("")[i]; call.Status != scheduledDone || call.Result != "pets" {
+			t.Errorf("expected the call to be done, got %+v", call)
+		}
+	}
+}
+
+func TestToolHandler_AuditRecord(t *testing.T) {
+	store := NewMemoryStore()
+	opts := &ToolGenOptions{Store: store, RequestHandler: fakeResponse(404, "application/json", `{"error":"not found"}`)}
+	handler := toolHandler("getFoo", OpenAPIOperation{OperationID: "getFoo", Path: "/foo", Method: "get"}, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"https://api.example.com"}, opts, newServerRuntime())
+	if _, _, err := handler(context.Background(), nil, map[string]any{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The record is written in the background
+	var keys []string
+	for deadline := time.Now().Add(5 * time.Second); len(keys) == 0 && time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		keys, _ = store.Keys(context.Background(), "audit/")
+	}
+	if len(keys) != 1 {
+		t.Fatalf("expected one audit record, got %q", keys)
+	}
+	data, _, _ := store.Get(context.Background(), keys[0])
+	var record auditRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		t.Fatal(err)
+	}
+	if record.Tool != "getFoo" || record.Operation != "getFoo" || record.HTTPStatus != 404 || !record.Failed || record.CallID == "" || !strings.HasSuffix(keys[0], record.CallID) {
+		t.Errorf("unexpected audit record %s: %+v", keys[0], record)
+	}
+}
+
+// slowStore is a Store whose writes block until their context is done or release is closed.
+type slowStore struct {
+	Store
+	release chan struct{}
+}
+
+func (s slowStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	select {
+	case <-s.release:
+		return s.Store.Set(ctx, key, value, ttl)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestToolHandler_AuditRecordDoesNotBlockCalls(t *testing.T) {
+	store := slowStore{Store: NewMemoryStore(), release: make(chan struct{})}
+	opts := &ToolGenOptions{Store: store, LogHandler: discardHandler{}, RequestHandler: fakeResponse(200, "application/json", `{}`)}
+	handler := toolHandler("getFoo", OpenAPIOperation{OperationID: "getFoo", Path: "/foo", Method: "get"}, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"https://api.example.com"}, opts, newServerRuntime())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 3 {
+			if _, _, err := handler(context.Background(), nil, map[string]any{}); err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected calls to return while the store is slow")
+	}
+
+	close(store.release)
+	var keys []string
+	for deadline := time.Now().Add(5 * time.Second); len(keys) < 3 && time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		keys, _ = store.Keys(context.Background(), "audit/")
+	}
+	if len(keys) != 3 {
+		t.Errorf("expected the audit records to be written once the store catches up, got %q", keys)
+	}
+}
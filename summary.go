@@ -1,7 +1,164 @@
 // summary.go
 package openapi2mcp
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// ToolSummary describes the tool generated for an operation, as printed by dry runs.
+type ToolSummary struct {
+	Name        string            `json:"name"`        // Tool name, after NameFormat
+	Description string            `json:"description"` // Tool description as shown to the model
+	Tags        []string          `json:"tags"`        // Tags of the operation
+	Method      string            `json:"method"`      // HTTP method of the operation, e.g. "GET"
+	Path        string            `json:"path"`        // Path of the operation, e.g. "/pets/{petId}"
+	InputSchema jsonschema.Schema `json:"inputSchema"` // Input schema of the tool
+}
+
+// GenerateToolSummaries builds the tools RegisterOpenAPITools would register for ops, without registering
+// them, and returns their summaries in the order of ops, e.g. to build a tool catalog. Tools not generated
+// from an operation (info, externalDocs, ...) are left out. opts may be nil; DryRun is ignored.
+//
+//	doc, _ := openapi2mcp.LoadOpenAPISpec("petstore.yaml")
+//	summaries := openapi2mcp.GenerateToolSummaries(openapi2mcp.ExtractOpenAPIOperations(doc), doc, nil)
+func GenerateToolSummaries(ops []OpenAPIOperation, doc *openapi3.T, opts *ToolGenOptions) []ToolSummary {
+	var toolOpts ToolGenOptions
+	if opts != nil {
+		toolOpts = *opts
+	}
+	toolOpts.DryRun = true
+	_, summaries := registerOpenAPITools(nil, ops, doc, &toolOpts)
+	return summaries
+}
+
+// ToolSurface summarizes the generated tools for CI, as printed by --summary.
+type ToolSurface struct {
+	Tools            int              `json:"tools"`            // Number of generated tools
+	Tags             map[string]int   `json:"tags"`             // Number of tools per tag
+	Warnings         int              `json:"warnings"`         // Number of lint warnings of the spec
+	Errors           int              `json:"errors"`           // Number of lint errors of the spec
+	SchemaBytes      int              `json:"schemaBytes"`      // Total size of the input schemas, as JSON
+	DescriptionBytes int              `json:"descriptionBytes"` // Total size of the tool descriptions
+	Diff             *ToolSurfaceDiff `json:"diff,omitempty"`   // Changes to a previous run, if compared
+}
+
+// ToolSurfaceDiff lists the changes between two sets of tool summaries.
+type ToolSurfaceDiff struct {
+	Added    []string `json:"added"`    // Tools that are new
+	Removed  []string `json:"removed"`  // Tools that no longer exist
+	Changed  []string `json:"changed"`  // Tools whose description or input schema changed
+	Breaking bool     `json:"breaking"` // Whether a change can break existing callers
+	Reasons  []string `json:"reasons"`  // The breaking changes, e.g. "createOrder: requestBody.email is now required"
+}
+
+// SummarizeTools summarizes the tool surface of summaries for CI. lint may be nil.
+//
+//	summaries := openapi2mcp.GenerateToolSummaries(ops, doc, nil)
+//	surface := openapi2mcp.SummarizeTools(summaries, openapi2mcp.LintOpenAPISpec(doc, false))
+func SummarizeTools(summaries []ToolSummary, lint *LintResult) *ToolSurface {
+	surface := &ToolSurface{Tools: len(summaries), Tags: map[string]int{}}
+	for _, s := range summaries {
+		for _, tag := range s.Tags {
+			surface.Tags[tag]++
+		}
+		schema, _ := json.Marshal(s.InputSchema)
+		surface.SchemaBytes += len(schema)
+		surface.DescriptionBytes += len(s.Description)
+	}
+	if lint != nil {
+		surface.Warnings = lint.WarningCount
+		surface.Errors = lint.ErrorCount
+	}
+	return surface
+}
+
+// DiffToolSummaries compares the tools of a previous run to the current ones. Removing a tool, removing
+// an argument, narrowing an argument's type, and requiring an argument that was optional or new are breaking;
+// adding tools or optional arguments, widening types, and changing descriptions are not.
+func DiffToolSummaries(previous, current []ToolSummary) *ToolSurfaceDiff {
+	diff := &ToolSurfaceDiff{Added: []string{}, Removed: []string{}, Changed: []string{}, Reasons: []string{}}
+	prev := map[string]ToolSummary{}
+	for _, s := range previous {
+		prev[s.Name] = s
+	}
+	cur := map[string]ToolSummary{}
+	for _, s := range current {
+		cur[s.Name] = s
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(prev)) {
+		p := prev[name]
+		c, ok := cur[name]
+		if !ok {
+			diff.Removed = append(diff.Removed, name)
+			diff.Reasons = append(diff.Reasons, name+": tool removed")
+			continue
+		}
+		pSchema, _ := json.Marshal(p.InputSchema)
+		cSchema, _ := json.Marshal(c.InputSchema)
+		if p.Description != c.Description || string(pSchema) != string(cSchema) {
+			diff.Changed = append(diff.Changed, name)
+		}
+		for _, reason := range schemaBreakingChanges(&p.InputSchema, &c.InputSchema, "") {
+			diff.Reasons = append(diff.Reasons, name+": "+reason)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(cur)) {
+		if _, ok := prev[name]; !ok {
+			diff.Added = append(diff.Added, name)
+		}
+	}
+	diff.Breaking = len(diff.Reasons) > 0
+	return diff
+}
+
+// schemaBreakingChanges lists the changes from prev to cur that can reject arguments prev accepted.
+func schemaBreakingChanges(prev, cur *jsonschema.Schema, prefix string) []string {
+	if prev == nil || cur == nil {
+		return nil
+	}
+	var reasons []string
+	name := func(prop string) string {
+		if prefix == "" {
+			return prop
+		}
+		return prefix + "." + prop
+	}
+	narrowed := func(pt, ct []string) bool {
+		for _, t := range pt {
+			if !slices.Contains(ct, t) && !(t == "integer" && slices.Contains(ct, "number")) {
+				return true
+			}
+		}
+		return false
+	}
+	if pt, ct := schemaTypes(prev), schemaTypes(cur); len(pt) > 0 && len(ct) > 0 && narrowed(pt, ct) {
+		subject := prefix
+		if subject == "" {
+			subject = "arguments"
+		}
+		return []string{fmt.Sprintf("%s changed type from %s to %s", subject, expectedType(prev), expectedType(cur))}
+	}
+	for _, prop := range slices.Sorted(maps.Keys(prev.Properties)) {
+		if _, ok := cur.Properties[prop]; !ok {
+			reasons = append(reasons, name(prop)+" was removed")
+			continue
+		}
+		reasons = append(reasons, schemaBreakingChanges(prev.Properties[prop], cur.Properties[prop], name(prop))...)
+	}
+	for _, prop := range cur.Required {
+		if !slices.Contains(prev.Required, prop) {
+			reasons = append(reasons, name(prop)+" is now required")
+		}
+	}
+	return append(reasons, schemaBreakingChanges(prev.Items, cur.Items, name("[]"))...)
+}
 
 // PrintToolSummary prints a summary of the generated tools (count, tags, etc).
 func PrintToolSummary(ops []OpenAPIOperation) {
@@ -0,0 +1,70 @@
+package openapi2mcp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestGenerateToolSummaries(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+externalDocs: {url: "https://docs.example.com"}
+paths:
+  /pets/{petId}:
+    get:
+      operationId: getPet
+      tags: [pets]
+      summary: Get a pet
+      parameters:
+        - {name: petId, in: path, required: true, schema: {type: string}}
+      responses: {"200": {description: ok}}
+  /orders:
+    post:
+      operationId: createOrder
+      tags: [store]
+      responses: {"201": {description: created}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	summaries := GenerateToolSummaries(ExtractOpenAPIOperations(doc), doc, &ToolGenOptions{NameFormat: strings.ToLower, TagFilter: []string{"pets"}})
+	if len(summaries) != 1 {
+		t.Fatalf("expected only the getPet tool, got %+v", summaries)
+	}
+	s := summaries[0]
+	if s.Name != "getpet" || s.Method != "GET" || s.Path != "/pets/{petId}" || len(s.Tags) != 1 || s.Tags[0] != "pets" {
+		t.Errorf("unexpected summary: %+v", s)
+	}
+	if !strings.Contains(s.Description, "Get a pet") || s.InputSchema.Properties["petId"] == nil {
+		t.Errorf("expected the description and input schema of the tool, got %+v", s)
+	}
+}
+
+func TestDiffToolSummaries(t *testing.T) {
+	order := func(required []string, props map[string]*jsonschema.Schema) ToolSummary {
+		return ToolSummary{Name: "createOrder", InputSchema: jsonschema.Schema{Type: "object", Required: required, Properties: props}}
+	}
+	previous := []ToolSummary{
+		order([]string{"id"}, map[string]*jsonschema.Schema{"id": {Type: "integer"}, "note": {Type: "string"}, "count": {Type: "integer"}}),
+		{Name: "deleteOrder"},
+	}
+	current := []ToolSummary{
+		order([]string{"id", "email"}, map[string]*jsonschema.Schema{"id": {Type: "string"}, "email": {Type: "string"}, "count": {Type: "number"}}),
+		{Name: "listOrders"},
+	}
+
+	diff := DiffToolSummaries(previous, current)
+	if !diff.Breaking || strings.Join(diff.Added, ",") != "listOrders" || strings.Join(diff.Removed, ",") != "deleteOrder" || strings.Join(diff.Changed, ",") != "createOrder" {
+		t.Fatalf("unexpected diff: %+v", diff)
+	}
+	want := "createOrder: id changed type from integer to string, createOrder: note was removed, createOrder: email is now required, deleteOrder: tool removed"
+	if got := strings.Join(diff.Reasons, ", "); got != want {
+		t.Errorf("reasons = %s, want %s", got, want)
+	}
+
+	if diff := DiffToolSummaries(current, append(current, ToolSummary{Name: "getOrder"})); diff.Breaking {
+		t.Errorf("adding a tool should not be breaking: %+v", diff)
+	}
+}
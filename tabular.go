@@ -0,0 +1,153 @@
+// tabular.go
+package openapi2mcp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+)
+
+// DefaultMaxTabularRecords is the number of CSV rows or NDJSON records parsed when MaxTabularRecords is 0.
+const DefaultMaxTabularRecords = 1000
+
+// tabularPreviewRecords is the number of records shown in the text of CSV and NDJSON results.
+const tabularPreviewRecords = 10
+
+// Kinds of record-oriented response bodies.
+const (
+	tabularCSV    = "CSV"
+	tabularTSV    = "TSV"
+	tabularNDJSON = "NDJSON"
+)
+
+// tabularKind returns the kind of record-oriented content of the media type, or "" if it is none.
+func tabularKind(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	switch mediaType {
+	case "text/csv":
+		return tabularCSV
+	case "text/tab-separated-values":
+		return tabularTSV
+	case "application/ndjson", "application/x-ndjson", "application/jsonl", "application/x-jsonlines", "application/jsonlines":
+		return tabularNDJSON
+	}
+	return ""
+}
+
+// tabularData holds the records parsed from a CSV or NDJSON response; it is the structured content of the result.
+type tabularData struct {
+	Format    string   `json:"format"`
+	Columns   []string `json:"columns,omitempty"` // CSV header row
+	Records   []any    `json:"records"`
+	Count     int      `json:"record_count"`
+	Truncated bool     `json:"truncated,omitempty"` // true if only the first records are included
+}
+
+// parseTabular parses up to maxRecords records of a CSV (with a header row), TSV, or NDJSON body. If the body
+// was truncated, its last, possibly incomplete record is dropped.
+func parseTabular(kind string, body []byte, truncated bool, maxRecords int) (*tabularData, error) {
+	if truncated {
+		if i := bytes.LastIndexByte(body, '\n'); i >= 0 {
+			body = body[:i+1]
+		}
+	}
+	data := &tabularData{Format: kind, Records: []any{}, Truncated: truncated}
+	add := func(record any) bool {
+		if len(data.Records) == maxRecords {
+			data.Truncated = true
+			return false
+		}
+		data.Records = append(data.Records, record)
+		return true
+	}
+
+	if kind == tabularNDJSON {
+		scanner := bufio.NewScanner(bytes.NewReader(body))
+		scanner.Buffer(nil, len(body)+1)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			record, err := decodeJSONNumbers(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", data.Count+1, err)
+			}
+			if !add(record) {
+				break
+			}
+			data.Count++
+		}
+		return data, scanner.Err()
+	}
+
+	r := csv.NewReader(bytes.NewReader(body))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	if kind == tabularTSV {
+		r.Comma = '\t'
+	}
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return data, nil
+	} else if err != nil {
+		return nil, err
+	}
+	data.Columns = header
+	for {
+		row, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		record := make(map[string]any, len(row))
+		for i, value := range row {
+			name := fmt.Sprintf("column%d", i+1)
+			if i < len(header) && header[i] != "" {
+				name = header[i]
+			}
+			record[name] = value
+		}
+		if !add(record) {
+			break
+		}
+		data.Count++
+	}
+	return data, nil
+}
+
+// preview renders the first records compactly, one JSON object per line, for the text of the result.
+func (d *tabularData) preview() []byte {
+	var sb strings.Builder
+	noun := "records"
+	if d.Format != tabularNDJSON {
+		noun = "rows"
+	}
+	fmt.Fprintf(&sb, "%s: %d %s", d.Format, d.Count, noun)
+	if d.Truncated {
+		sb.WriteString(" (more were not parsed)")
+	}
+	if len(d.Columns) > 0 {
+		fmt.Fprintf(&sb, ", columns: %s", strings.Join(d.Columns, ", "))
+	}
+	shown := min(len(d.Records), tabularPreviewRecords)
+	if shown < len(d.Records) {
+		fmt.Fprintf(&sb, "\nFirst %d %s (all parsed %s are in the structured content):", shown, noun, noun)
+	}
+	for _, record := range d.Records[:shown] {
+		line, _ := json.Marshal(record)
+		sb.WriteByte('\n')
+		sb.Write(line)
+	}
+	return []byte(sb.String())
+}
@@ -0,0 +1,76 @@
+package openapi2mcp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestParseTabular(t *testing.T) {
+	data, err := parseTabular(tabularCSV, []byte("id,name\n1,\"Smith, J.\"\n2,Doe,extra\n"), false, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data.Count != 2 || strings.Join(data.Columns, "|") != "id|name" {
+		t.Fatalf("unexpected data: %+v", data)
+	}
+	if got := fmt.Sprint(data.Records[1]); got != "map[column3:extra id:2 name:Doe]" {
+		t.Errorf("unexpected record: %s", got)
+	}
+
+	// The incomplete last line of a truncated body is dropped, and records beyond the limit are not parsed
+	data, err = parseTabular(tabularNDJSON, []byte("{\"n\":1}\n\n{\"n\":2}\n{\"n\":3}\n{\"n\""), true, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data.Count != 2 || !data.Truncated {
+		t.Errorf("unexpected data: %+v", data)
+	}
+	if _, err := parseTabular(tabularNDJSON, []byte("{\"n\":1}\nnot json\n"), false, 10); err == nil {
+		t.Error("expected an error for an invalid line")
+	}
+}
+
+func TestToolHandler_Tabular(t *testing.T) {
+	var rows strings.Builder
+	rows.WriteString("id,name\n")
+	for i := 1; i <= 25; i++ {
+		fmt.Fprintf(&rows, "%d,user%d\n", i, i)
+	}
+	contentType, body := "text/csv; charset=utf-8", rows.String()
+	op := OpenAPIOperation{OperationID: "exportUsers", Path: "/users.csv", Method: "get"}
+	opts := &ToolGenOptions{RequestHandler: func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: 200, Header: http.Header{"Content-Type": {contentType}}, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+	}}
+	handler := toolHandler("exportUsers", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+
+	res, _, err := handler(context.Background(), nil, map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(t, res)
+	if !strings.Contains(text, "Response:\nCSV: 25 rows, columns: id, name\nFirst 10 rows (all parsed rows are in the structured content):\n{\"id\":\"1\",\"name\":\"user1\"}") || strings.Contains(text, "user11") {
+		t.Errorf("expected a preview of the first rows, got: %s", text)
+	}
+	if data, ok := res.StructuredContent.(*tabularData); !ok || data.Count != 25 || len(data.Records) != 25 {
+		t.Errorf("expected all rows as structured content, got %#v", res.StructuredContent)
+	}
+
+	contentType, body = "application/x-ndjson", "{\"event\":\"start\"}\n{\"event\":\"stop\"}\n"
+	res, _, _ = handler(context.Background(), nil, map[string]any{})
+	if text := resultText(t, res); !strings.Contains(text, "NDJSON: 2 records\n{\"event\":\"start\"}\n{\"event\":\"stop\"}") {
+		t.Errorf("expected the NDJSON records, got: %s", text)
+	}
+
+	// Unparseable bodies are shown as they are
+	contentType, body = "application/x-ndjson", "oops\n"
+	res, _, _ = handler(context.Background(), nil, map[string]any{})
+	if text := resultText(t, res); !strings.Contains(text, "Response:\noops") || res.StructuredContent != nil {
+		t.Errorf("expected the raw body, got: %s", text)
+	}
+}
@@ -0,0 +1,124 @@
+// timeout.go
+package openapi2mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// longRunningTimeout is the timeout from which operations are described as long-running.
+const longRunningTimeout = 30 * time.Second
+
+// operationTimeout returns the timeout of calls to the operation, or 0 if calls are not limited.
+func operationTimeout(opts *ToolGenOptions, operationID string) time.Duration {
+	if opts == nil {
+		return 0
+	}
+	if timeout := opts.OperationTimeouts[operationID]; timeout > 0 {
+		return timeout
+	}
+	return opts.Timeout
+}
+
+// describeTimeout tells the model how long a call may take, so that it doesn't give up on it too early.
+func describeTimeout(timeout time.Duration) string {
+	if timeout <= 0 {
+		return ""
+	}
+	desc := fmt.Sprintf("\n\nTIMEOUT: This call may take up to %s", timeout)
+	if timeout >= longRunningTimeout {
+		desc += "; it is long-running, so wait for the result instead of cancelling or repeating the call"
+	}
+	return desc + "."
+}
+
+// timeoutText explains a call that was aborted because the API didn't respond within its timeout.
+func timeoutText(timeout time.Duration, operationID, callID string) string {
+	return fmt.Sprintf("Request timed out: the API did not respond within %s.\nThe operation may still complete on the server; check its state before retrying non-idempotent calls.\nOperation: %s\nCall ID: %s", timeout, operationID, callID)
+}
+
+// timedOut reports whether err was caused by the call's own timeout rather than by the caller cancelling ctx.
+func timedOut(ctx context.Context, err error) bool {
+	return err != nil && ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded)
+}
+
+// serverConfig is the content of the server_config resource: the limits that apply to tool calls.
+type serverConfig struct {
+	Timeout             string            `json:"timeout,omitempty"`            // global timeout per call, if any
+	OperationTimeouts   map[string]string `json:"operation_timeouts,omitempty"` // timeouts overriding it, by tool name
+	FollowRedirects     bool              `json:"follow_redirects"`
+	MaxRedirects        int               `json:"max_redirects,omitempty"`
+	MaxResponseBytes    int64             `json:"max_response_bytes"`
+	MaxRequestBodyBytes int               `json:"max_request_body_bytes"`
+	MaxArgumentBytes    int               `json:"max_argument_bytes"`
+	MaxUploadFileBytes  int64             `json:"max_upload_file_bytes,omitempty"` // if uploads from local files are enabled
+}
+
+// newServerConfig describes the limits of tool calls configured by opts, naming operations by their tool name.
+func newServerConfig(opts *ToolGenOptions, toolName func(operationID string) string) serverConfig {
+	config := serverConfig{
+		FollowRedirects:     true,
+		MaxRedirects:        DefaultMaxRedirects,
+		MaxResponseBytes:    DefaultMaxResponseBytes,
+		MaxRequestBodyBytes: DefaultMaxRequestBodyBytes,
+		MaxArgumentBytes:    DefaultMaxArgumentBytes,
+	}
+	if opts == nil {
+		return config
+	}
+	if opts.Timeout > 0 {
+		config.Timeout = opts.Timeout.String()
+	}
+	for operationID, timeout := range opts.OperationTimeouts {
+		if timeout > 0 {
+			if config.OperationTimeouts == nil {
+				config.OperationTimeouts = map[string]string{}
+			}
+			config.OperationTimeouts[toolName(operationID)] = timeout.String()
+		}
+	}
+	if opts.Redirects != nil {
+		if opts.Redirects.Disabled {
+			config.FollowRedirects, config.MaxRedirects = false, 0
+		} else if opts.Redirects.MaxRedirects > 0 {
+			config.MaxRedirects = opts.Redirects.MaxRedirects
+		}
+	}
+	if opts.MaxResponseBytes > 0 {
+		config.MaxResponseBytes = opts.MaxResponseBytes
+	}
+	if opts.MaxRequestBodyBytes > 0 {
+		config.MaxRequestBodyBytes = opts.MaxRequestBodyBytes
+	}
+	if opts.MaxArgumentBytes > 0 {
+		config.MaxArgumentBytes = opts.MaxArgumentBytes
+	}
+	if opts.UploadRoot != "" {
+		config.MaxUploadFileBytes = DefaultMaxUploadFileBytes
+		if opts.MaxUploadFileBytes > 0 {
+			config.MaxUploadFileBytes = opts.MaxUploadFileBytes
+		}
+	}
+	return config
+}
+
+// registerServerConfigResource adds the server_config resource, letting agents read the limits of tool calls up front.
+func registerServerConfigResource(server *mcp.Server, config serverConfig) {
+	resource := &mcp.Resource{
+		URI:         "server://config",
+		Name:        "server_config",
+		Description: "Limits applying to tool calls: timeouts (global and per tool), redirects, and request and response sizes",
+		MIMEType:    "application/json",
+	}
+	text, _ := json.MarshalIndent(config, "", "  ")
+	server.AddResource(resource, func(context.Context, *mcp.ServerRequest[*mcp.ReadResourceParams]) (*mcp.ReadResourceResult, error) {
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{URI: resource.URI, MIMEType: "application/json", Text: string(text)}},
+		}, nil
+	})
+}
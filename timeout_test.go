@@ -0,0 +1,94 @@
+package openapi2mcp
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestToolHandler_Timeout(t *testing.T) {
+	op := OpenAPIOperation{OperationID: "createReport", Path: "/reports", Method: "get"}
+	opts := &ToolGenOptions{
+		RequestHandler: func(req *http.Request) (*http.Response, error) {
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		},
+		Timeout:           time.Hour,
+		OperationTimeouts: map[string]time.Duration{"createReport": 10 * time.Millisecond},
+	}
+	handler := toolHandler("createReport", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+	res, _, err := handler(context.Background(), nil, map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(t, res); !res.IsError || !strings.Contains(text, "Request timed out: the API did not respond within 10ms.") {
+		t.Errorf("expected a timeout error, got: %s", text)
+	}
+
+	// Cancellation by the client is not reported as a timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := handler(ctx, nil, map[string]any{}); err == nil {
+		t.Error("expected the cancellation to be returned as an error")
+	}
+}
+
+func TestRegisterOpenAPITools_Timeouts(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /reports:
+    post: {operationId: createReport, responses: {"201": {description: created}}}
+  /status:
+    get: {operationId: getStatus, responses: {"200": {description: ok}}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	RegisterOpenAPITools(server, ExtractOpenAPIOperations(doc), doc, &ToolGenOptions{
+		Timeout:           10 * time.Second,
+		OperationTimeouts: map[string]time.Duration{"createReport": time.Minute},
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(context.Background(), serverTransport, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil).Connect(context.Background(), clientTransport, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cs.Close()
+
+	tools, err := cs.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	descriptions := map[string]string{}
+	for _, tool := range tools.Tools {
+		descriptions[tool.Name] = tool.Description
+	}
+	if desc := descriptions["createReport"]; !strings.Contains(desc, "TIMEOUT: This call may take up to 1m0s; it is long-running") {
+		t.Errorf("expected the operation timeout in the description, got: %s", desc)
+	}
+	if desc := descriptions["getStatus"]; !strings.Contains(desc, "TIMEOUT: This call may take up to 10s.") {
+		t.Errorf("expected the global timeout in the description, got: %s", desc)
+	}
+
+	res, err := cs.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "server://config"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := res.Contents[0].Text
+	for _, want := range []string{`"timeout": "10s"`, `"createReport": "1m0s"`, `"max_redirects": 10`} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected server_config to contain %s, got:\n%s", want, text)
+		}
+	}
+}
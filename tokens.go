@@ -0,0 +1,120 @@
+// tokens.go
+package openapi2mcp
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Tokenizer counts the tokens text takes up in a model's context.
+type Tokenizer func(text string) int
+
+// ApproximateTokenizer estimates one token per four bytes of text, which is close for English text and JSON with
+// common BPE tokenizers. Use the tokenizer of the model for exact counts.
+func ApproximateTokenizer(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// ToolTokens is the estimated size of one tool definition.
+type ToolTokens struct {
+	Name        string `json:"name"`        // Tool name
+	Description int    `json:"description"` // Tokens of the description
+	Schema      int    `json:"schema"`      // Tokens of the input schema, as JSON
+	Total       int    `json:"total"`       // Tokens of the tool as listed to the model: name, description, and input schema
+}
+
+// TokenEstimate is the estimated size of the tool list a client sends to the model.
+type TokenEstimate struct {
+	Total        int            `json:"total"`                  // Tokens of all tools
+	CompactTotal int            `json:"compactTotal,omitempty"` // Tokens of all tools with CompactSchemas, if not enabled already
+	Budget       int            `json:"budget,omitempty"`       // Context budget the tools should fit in; 0 means none
+	Tags         map[string]int `json:"tags"`                   // Tokens of the tools per tag, for choosing tag filters
+	Tools        []ToolTokens   `json:"tools"`                  // Tools, largest first
+	Warnings     []string       `json:"warnings"`               // Problems with the budget, and how to fix them
+}
+
+// OverBudget reports whether the tools exceed the budget.
+func (e *TokenEstimate) OverBudget() bool {
+	return e.Budget > 0 && e.Total > e.Budget
+}
+
+// EstimateToolTokens estimates the tokens the tools generated for ops take up in a model's context, as counted by
+// tokenizer (ApproximateTokenizer if nil). If budget is positive, the estimate warns when the tools exceed it or a
+// single tool takes up more than a tenth of it, and suggests compact schemas and the tags to filter by.
+// opts may be nil.
+//
+//	estimate := openapi2mcp.EstimateToolTokens(ops, doc, opts, nil, 20000)
+//	for _, w := range estimate.Warnings { log.Println(w) }
+func EstimateToolTokens(ops []OpenAPIOperation, doc *openapi3.T, opts *ToolGenOptions, tokenizer Tokenizer, budget int) *TokenEstimate {
+	if tokenizer == nil {
+		tokenizer = ApproximateTokenizer
+	}
+	estimate := &TokenEstimate{Budget: max(budget, 0), Tags: map[string]int{}, Tools: []ToolTokens{}, Warnings: []string{}}
+	for _, s := range GenerateToolSummaries(ops, doc, opts) {
+		t := toolTokens(s, tokenizer)
+		estimate.Total += t.Total
+		estimate.Tools = append(estimate.Tools, t)
+		for _, tag := range s.Tags {
+			estimate.Tags[tag] += t.Total
+		}
+	}
+	slices.SortStableFunc(estimate.Tools, func(a, b ToolTokens) int { return cmp.Compare(b.Total, a.Total) })
+
+	if opts == nil || !opts.CompactSchemas {
+		var compactOpts ToolGenOptions
+		if opts != nil {
+			compactOpts = *opts
+		}
+		compactOpts.CompactSchemas = true
+		for _, s := range GenerateToolSummaries(ops, doc, &compactOpts) {
+			estimate.CompactTotal += toolTokens(s, tokenizer).Total
+		}
+		if estimate.CompactTotal >= estimate.Total {
+			estimate.CompactTotal = 0
+		}
+	}
+
+	if !estimate.OverBudget() {
+		for _, t := range estimate.Tools {
+			if budget > 0 && t.Total > budget/10 {
+				estimate.Warnings = append(estimate.Warnings, fmt.Sprintf("%s takes up %d tokens, %d%% of the budget", t.Name, t.Total, t.Total*100/budget))
+			}
+		}
+		return estimate
+	}
+	estimate.Warnings = append(estimate.Warnings, fmt.Sprintf("the tools take up about %d tokens, over the budget of %d", estimate.Total, budget))
+	if estimate.CompactTotal > 0 {
+		estimate.Warnings = append(estimate.Warnings, fmt.Sprintf("compact schemas reduce them to about %d tokens", estimate.CompactTotal))
+	}
+	if len(estimate.Tags) > 0 {
+		tags := slices.SortedFunc(maps.Keys(estimate.Tags), func(a, b string) int {
+			return cmp.Or(cmp.Compare(estimate.Tags[b], estimate.Tags[a]), cmp.Compare(a, b))
+		})
+		var largest []string
+		for _, tag := range tags[:min(len(tags), 3)] {
+			largest = append(largest, fmt.Sprintf("%s (%d)", tag, estimate.Tags[tag]))
+		}
+		estimate.Warnings = append(estimate.Warnings, "the largest tags are "+strings.Join(largest, ", ")+"; filter the tools by the tags the agent needs")
+	}
+	largest := estimate.Tools[0]
+	estimate.Warnings = append(estimate.Warnings, fmt.Sprintf("the largest tool is %s (%d tokens)", largest.Name, largest.Total))
+	return estimate
+}
+
+// toolTokens estimates the tokens of the tool definition of s.
+func toolTokens(s ToolSummary, tokenizer Tokenizer) ToolTokens {
+	schema, _ := json.Marshal(s.InputSchema)
+	tool, _ := json.Marshal(map[string]any{"name": s.Name, "description": s.Description, "inputSchema": json.RawMessage(schema)})
+	return ToolTokens{
+		Name:        s.Name,
+		Description: tokenizer(s.Description),
+		Schema:      tokenizer(string(schema)),
+		Total:       tokenizer(string(tool)),
+	}
+}
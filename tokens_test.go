@@ -0,0 +1,80 @@
+package openapi2mcp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEstimateToolTokens(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      tags: [pets]
+      responses: {"200": {description: ok}}
+    post:
+      operationId: createPet
+      tags: [pets]
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                owner: {$ref: '#/components/schemas/Person'}
+                vet: {$ref: '#/components/schemas/Person'}
+      responses: {"201": {description: created}}
+  /stores:
+    get:
+      operationId: listStores
+      tags: [stores]
+      responses: {"200": {description: ok}}
+components:
+  schemas:
+    Person:
+      type: object
+      properties:
+        name: {type: string, description: "The full name of the person, as shown on their ID"}
+        email: {type: string, format: email, description: The email address the person can be reached at}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ops := ExtractOpenAPIOperations(doc)
+
+	estimate := EstimateToolTokens(ops, doc, nil, nil, 0)
+	if len(estimate.Tools) != 3 || estimate.Tools[0].Name != "createPet" {
+		t.Fatalf("expected the tools sorted by size, got %+v", estimate.Tools)
+	}
+	sum := 0
+	for _, tool := range estimate.Tools {
+		sum += tool.Total
+		if tool.Total < tool.Description+tool.Schema-2 {
+			t.Errorf("%s: expected the total to include the description and schema, got %+v", tool.Name, tool)
+		}
+	}
+	if estimate.Total != sum || estimate.Tags["pets"]+estimate.Tags["stores"] != sum {
+		t.Errorf("expected the totals to add up, got %d (tools: %d, tags: %v)", estimate.Total, sum, estimate.Tags)
+	}
+	if estimate.CompactTotal == 0 || estimate.CompactTotal >= estimate.Total {
+		t.Errorf("expected compact schemas to save tokens, got %d of %d", estimate.CompactTotal, estimate.Total)
+	}
+	if estimate.OverBudget() || len(estimate.Warnings) != 0 {
+		t.Errorf("expected no warnings without a budget, got %v", estimate.Warnings)
+	}
+
+	estimate = EstimateToolTokens(ops, doc, nil, nil, estimate.Total/2)
+	warnings := strings.Join(estimate.Warnings, "\n")
+	if !estimate.OverBudget() || !strings.Contains(warnings, "over the budget") || !strings.Contains(warnings, "the largest tags are pets") ||
+		!strings.Contains(warnings, "compact schemas reduce them") {
+		t.Errorf("unexpected warnings:\n%s", warnings)
+	}
+
+	// A pluggable tokenizer counts instead of the approximation
+	words := func(text string) int { return len(strings.Fields(text)) }
+	if got := EstimateToolTokens(ops, doc, &ToolGenOptions{CompactSchemas: true}, words, 0); got.CompactTotal != 0 || got.Total == 0 {
+		t.Errorf("expected a word count without a compact estimate, got %d (compact %d)", got.Total, got.CompactTotal)
+	}
+}
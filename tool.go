@@ -5,13 +5,18 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
+	"maps"
+	"math"
 	"math/rand"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/google/jsonschema-go/jsonschema"
@@ -22,16 +27,246 @@ func defaultRequestHandler(req *http.Request) (*http.Response, error) {
 	return http.DefaultClient.Do(req)
 }
 
+// upstreamRequestHandler returns the handler sending requests to the API: opts.RequestHandler, or else the client
+// enforcing the host and redirect policies, recording the interactions to opts.Cassette if set.
+func upstreamRequestHandler(rt *serverRuntime, doc *openapi3.T, opts *ToolGenOptions) func(*http.Request) (*http.Response, error) {
+	send := opts.RequestHandler
+	if send == nil {
+		send = newUpstreamClient(rt.hosts, doc, opts).Do
+	}
+	if opts.Cassette != nil {
+		send = opts.Cassette.handler(send, doc, opts)
+	}
+	return send
+}
+
 func toolHandler(
 	name string,
 	op OpenAPIOperation,
 	doc *openapi3.T,
 	inputSchema jsonschema.Schema,
 	baseURLs []string,
-	confirmDangerousActions bool,
-	requestHandler func(req *http.Request) (*http.Response, error),
+	opts *ToolGenOptions,
+	rt *serverRuntime,
 ) func(ctx context.Context, _ *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
-	return func(ctx context.Context, _ *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+	if opts == nil {
+		opts = &ToolGenOptions{}
+	}
+	if rt == nil {
+		rt = newServerRuntime()
+	}
+	confirmDangerousActions := opts.ConfirmDangerousActions
+	var requestHandler func(*http.Request) (*http.Response, error)
+	if opts.Mock {
+		requestHandler = mockRequestHandler(op, opts.ExampleGenerators)
+	} else {
+		requestHandler = upstreamRequestHandler(rt, doc, opts)
+	}
+	logger := newLogger(opts)
+	requestHandler = withMiddleware(requestHandler, opts.Middleware, op)
+	requestHandler = withRetries(requestHandler, operationRetry(opts, op, doc), logger, op.OperationID)
+
+	maxResponseBytes := int64(DefaultMaxResponseBytes)
+	if opts.MaxResponseBytes > 0 {
+		maxResponseBytes = opts.MaxResponseBytes
+	}
+	maxRequestBodyBytes := DefaultMaxRequestBodyBytes
+	if opts.MaxRequestBodyBytes > 0 {
+		maxRequestBodyBytes = opts.MaxRequestBodyBytes
+	}
+	maxUploadFileBytes := int64(DefaultMaxUploadFileBytes)
+	if opts.MaxUploadFileBytes > 0 {
+		maxUploadFileBytes = opts.MaxUploadFileBytes
+	}
+	maxArgumentBytes := DefaultMaxArgumentBytes
+	if opts.MaxArgumentBytes > 0 {
+		maxArgumentBytes = opts.MaxArgumentBytes
+	}
+	timeout := operationTimeout(opts, op.OperationID)
+	accept := acceptHeader(responseContentTypes(op))
+	rateLimit := specRateLimit(op, doc)
+	var resumableUploads *uploadConfig
+	if !opts.Mock {
+		resumableUploads = operationUpload(op, doc)
+	}
+	csrf := csrfConfig(opts, doc)
+	sessionCookies := (opts.SessionCookies || csrf != nil) && !opts.Mock
+	var concurrency *concurrencyControl
+	if opts.AutoIfMatch && !opts.Mock {
+		concurrency = operationConcurrencyControl(op, doc)
+	}
+	var patchType, ndjsonType, binaryType string
+	if op.RequestBody != nil && op.RequestBody.Value != nil {
+		patchType = patchRequestType(op.RequestBody.Value.Content)
+		ndjsonType, binaryType = ndjsonRequestType(op.RequestBody.Value.Content), binaryRequestType(op.RequestBody.Value.Content)
+	}
+	graphQL := opts.GraphQL && isGraphQLOperation(op)
+	callLimits := operationConcurrencyLimits(opts, op)
+	cost := operationCost(opts, op, doc)
+	confirmCost := costConfirmationRequired(opts, cost)
+	argumentRules := opts.ArgumentRules.forOperation(op)
+	presets := opts.Presets[op.OperationID]
+	pagination := operationPagination(op, doc)
+	maxTabularRecords := DefaultMaxTabularRecords
+	if opts.MaxTabularRecords > 0 {
+		maxTabularRecords = opts.MaxTabularRecords
+	}
+
+	return func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (result *mcp.CallToolResult, _ any, _ error) {
+		// Correlate this call across logs, the upstream request, and the result
+		callID := newRandomID()
+		start := time.Now()
+		telemetry := &callTelemetry{}
+		var sent *http.Request // the request sent upstream, if any
+		var sentBody []byte
+		var sentFile string
+		var resolvedDates []string     // relative dates resolved in the arguments
+		var concurrencyNote string     // the ETag or version fetched for the agent, if any
+		var resumable *resumableUpload // the chunked upload of a local file, if any
+		var sandboxURL string          // the sandbox base URL the call was routed to, if any
+		var rateLimitNote string       // the warning about the host's rate limit quota running low, if any
+		defer func() {
+			setResultCallID(result, callID)
+
+			// Mark calls routed to the sandbox
+			if sandboxURL != "" && result != nil {
+				result.Content = append(result.Content, &mcp.TextContent{Text: sandboxText(sandboxURL)})
+				if result.Meta == nil {
+					result.Meta = mcp.Meta{}
+				}
+				result.Meta[sandboxMetaKey] = true
+			}
+
+			// Warn that the API host's rate limit quota is running low
+			if rateLimitNote != "" && result != nil {
+				result.Content = append(result.Content, &mcp.TextContent{Text: rateLimitNote})
+			}
+
+			// Show which dates relative values were resolved to
+			if len(resolvedDates) > 0 && result != nil {
+				result.Content = append(result.Content, &mcp.TextContent{Text: formatRelativeDates(resolvedDates)})
+			}
+
+			// Show the ETag or version sent for the agent, and how to recover from concurrent changes
+			if concurrencyNote != "" && result != nil {
+				result.Content = append(result.Content, &mcp.TextContent{Text: concurrencyNote})
+			}
+			if concurrency != nil && telemetry.HTTPStatus == http.StatusPreconditionFailed && result != nil {
+				result.Content = append(result.Content, &mcp.TextContent{Text: preconditionFailedText(concurrencyNote != "")})
+			}
+
+			// Show how a local file was uploaded in chunks, and where tus uploads can be found
+			if resumable != nil && resumable.chunks > 0 && result != nil {
+				result.Content = append(result.Content, &mcp.TextContent{Text: resumable.Note()})
+			}
+
+			// Show how to reproduce the call outside the agent
+			if opts.ReproCommand != "" && sent != nil && result != nil {
+				result.Content = append(result.Content, &mcp.TextContent{Text: formatReproCommand(reproCommand(opts.ReproCommand, sent, sentBody, sentFile, doc, opts))})
+			}
+
+			// Record outcome and latency for the server_stats tool
+			telemetry.Elapsed = time.Since(start)
+			telemetry.Failed = telemetry.Failed || result == nil || result.IsError
+			rt.stats.record(name, telemetry)
+
+			// Keep an audit record of the call in the store, written in the background
+			if opts.Store != nil {
+				record := auditRecord{Time: start.UTC(), CallID: callID, Tool: name, Operation: op.OperationID, HTTPStatus: telemetry.HTTPStatus, Failed: telemetry.Failed, ElapsedMS: telemetry.Elapsed.Milliseconds()}
+				if req != nil {
+					record.Session = sessionCorrelationID(req.Session)
+				}
+				rt.audit.add(opts.Store, record, logger)
+			}
+			if opts.Telemetry && result != nil {
+				result.Content = append(result.Content, &mcp.TextContent{Text: telemetry.String()})
+			}
+		}()
+
+		logger := logger.With("call_id", callID, "operation", op.OperationID)
+		if req != nil && req.Session != nil {
+			logger = logger.With("session_id", sessionCorrelationID(req.Session))
+		}
+
+		// Fill in the arguments of the named preset; calls through the MCP server have them filled in already
+		if expanded, ok := applyPreset(presets, args); ok {
+			args = expanded
+		} else {
+			return unknownPresetResult(op, presets, args[presetArgument], callID, opts.ErrorFormat), nil, nil
+		}
+
+		// Reject oversized arguments before anything is built from them
+		if argName, size, ok := oversizedArgument(args, maxArgumentBytes); ok {
+			toolErr := &ToolError{
+				Code:      "argument_too_large",
+				Message:   fmt.Sprintf("argument %s is %d bytes, the limit is %d bytes", argName, size, maxArgumentBytes),
+				Operation: op.OperationID,
+				CallID:    callID,
+			}
+			return toolErrorResult(payloadTooLargeText("Argument "+argName, size, maxArgumentBytes, op.OperationID, callID), toolErr, opts.ErrorFormat), nil, nil
+		}
+
+		// Block state-changing calls while the server is switched to read-only
+		if readOnly, reason := opts.ReadOnly.Enabled(); readOnly && unsafeMethod(op.Method) {
+			method := strings.ToUpper(op.Method)
+			toolErr := &ToolError{
+				Code:      "read_only",
+				Message:   "the server is read-only; " + method + " calls are blocked",
+				Operation: op.OperationID,
+				CallID:    callID,
+			}
+			return toolErrorResult(readOnlyText(reason, method, op.OperationID, callID), toolErr, opts.ErrorFormat), nil, nil
+		}
+
+		// Check the call against the operator's policy
+		if opts.Policy != nil {
+			if allowed, reason := opts.Policy.authorize(op, req, args); !allowed {
+				errorText := fmt.Sprintf("Operation not permitted: %s\nThe server's policy does not allow this call. Do not retry it; use another operation or ask the user.\nOperation: %s\nCall ID: %s", reason, op.OperationID, callID)
+				toolErr := &ToolError{
+					Code:      "forbidden_by_policy",
+					Message:   reason,
+					Operation: op.OperationID,
+					CallID:    callID,
+				}
+				return toolErrorResult(errorText, toolErr, opts.ErrorFormat), nil, nil
+			}
+		}
+
+		// Check argument values against the operator's rules
+		if errs := checkArgumentRules(argumentRules, args); len(errs) > 0 {
+			toolErr := &ToolError{
+				Code:        "argument_not_permitted",
+				Message:     errs[0].Field + ": " + errs[0].Message,
+				FieldErrors: errs,
+				Operation:   op.OperationID,
+				CallID:      callID,
+			}
+			return toolErrorResult(argumentRulesText(errs, op.OperationID, callID), toolErr, opts.ErrorFormat), nil, nil
+		}
+
+		// Costly calls are confirmed before they reach the API
+		if _, confirmed := args["__confirmed"]; confirmCost && !confirmed {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: costConfirmationText(name, cost)}}}, nil, nil
+		}
+
+		// Compile the response filter up front, so that an invalid expression doesn't cost an API call
+		filter, err := compileFilterArgument(args)
+		if err != nil {
+			errorText := fmt.Sprintf("Invalid %s argument: %v\nThe %s argument takes a JMESPath expression, e.g. \"items[].{id: id, name: name}\".\nOperation: %s\nCall ID: %s", filterArgument, err, filterArgument, op.OperationID, callID)
+			toolErr := &ToolError{
+				Code:      "invalid_filter",
+				Message:   err.Error(),
+				Operation: op.OperationID,
+				CallID:    callID,
+			}
+			return toolErrorResult(errorText, toolErr, opts.ErrorFormat), nil, nil
+		}
+
+		// Resolve relative values of date parameters, e.g. "yesterday"
+		if opts.RelativeDates {
+			args, resolvedDates = resolveRelativeDates(op.Parameters, args, time.Now(), timeZone(opts))
+		}
+
 		// Build parameter name mapping for escaped parameter names
 		paramNameMapping := buildParameterNameMapping(op.Parameters)
 
@@ -50,7 +285,7 @@ func toolHandler(
 					if p.Schema != nil && p.Schema.Value != nil && p.Schema.Value.Type != nil {
 						isInteger = p.Schema.Value.Type.Is("integer")
 					}
-					path = strings.ReplaceAll(path, "{"+p.Name+"}", formatParameterValue(val, isInteger))
+					path = strings.ReplaceAll(path, "{"+p.Name+"}", escapePathParameter(formatParameterValue(val, isInteger)))
 				}
 			}
 		}
@@ -77,6 +312,9 @@ func toolHandler(
 
 		// Pick a random baseURL for each call using the global rand
 		baseURL := baseURLs[rand.Intn(len(baseURLs))]
+		if opts.Sandbox.routes(op, req) {
+			baseURL, sandboxURL = opts.Sandbox.URL, opts.Sandbox.URL
+		}
 		fullURL, err := url.JoinPath(baseURL, path)
 		if err != nil {
 			return nil, nil, err
@@ -87,7 +325,7 @@ func toolHandler(
 
 		// Build request body if needed
 		var body []byte
-		var requestContentType string
+		var requestContentType, bodyFile string
 		if op.RequestBody != nil && op.RequestBody.Value != nil {
 			// Check for application/json first, then application/vnd.api+json (including with parameters)
 			mt := getContentByType(op.RequestBody.Value.Content, "application/json")
@@ -105,36 +343,198 @@ func toolHandler(
 					body, _ = json.Marshal(v)
 				}
 			}
+
+			invalidBody := func(err error, expected string) *mcp.CallToolResult {
+				errorText := fmt.Sprintf("Invalid requestBody: %v\nThe requestBody of this operation takes %s.\nOperation: %s\nCall ID: %s", err, expected, op.OperationID, callID)
+				toolErr := &ToolError{
+					Code:      "invalid_request_body",
+					Message:   err.Error(),
+					Operation: op.OperationID,
+					CallID:    callID,
+				}
+				return toolErrorResult(errorText, toolErr, opts.ErrorFormat)
+			}
+
+			// Patch documents are checked before they are sent, so that malformed JSON Patch operations fail fast
+			if v, ok := args["requestBody"]; ok && v != nil && patchType != "" {
+				if baseMediaType(patchType) == jsonPatchType {
+					if err := validateJSONPatch(v); err != nil {
+						return invalidBody(err, "a JSON Patch: an array of operations with op, path, and value or from"), nil, nil
+					}
+				}
+				body, _ = json.Marshal(v)
+				requestContentType = patchType
+			}
+
+			// NDJSON bodies take an array of documents, sent one per line
+			if v, ok := args["requestBody"]; ok && v != nil && ndjsonType != "" {
+				if body, err = encodeNDJSON(v); err != nil {
+					return invalidBody(err, "an array of JSON documents"), nil, nil
+				}
+				requestContentType = ndjsonType
+			}
+
+			// Raw binary uploads take base64-encoded bytes or a local file
+			if binaryType != "" {
+				if path, _ := args[requestBodyFileArgument].(string); path != "" {
+					bodyFile = path
+				} else if v, ok := args["requestBody"]; ok && v != nil {
+					if body, err = decodeBinaryBody(v); err != nil {
+						return invalidBody(err, "the bytes to upload base64-encoded"), nil, nil
+					}
+				}
+				requestContentType = binaryContentType(binaryType, bodyFile, body)
+			}
+		}
+
+		// GraphQL endpoints take the query, operationName, and variables arguments instead of a requestBody
+		if graphQL {
+			if body, err = graphQLRequestBody(args); err != nil {
+				errorText := fmt.Sprintf("Invalid GraphQL request: %v\nOperation: %s\nCall ID: %s", err, op.OperationID, callID)
+				toolErr := &ToolError{
+					Code:      "invalid_request_body",
+					Message:   err.Error(),
+					Operation: op.OperationID,
+					CallID:    callID,
+				}
+				return toolErrorResult(errorText, toolErr, opts.ErrorFormat), nil, nil
+			}
+			requestContentType = "application/json"
+		}
+		if len(body) > maxRequestBodyBytes {
+			toolErr := &ToolError{
+				Code:      "request_body_too_large",
+				Message:   fmt.Sprintf("request body is %d bytes, the limit is %d bytes", len(body), maxRequestBodyBytes),
+				Operation: op.OperationID,
+				CallID:    callID,
+			}
+			return toolErrorResult(payloadTooLargeText("The request body", len(body), maxRequestBodyBytes, op.OperationID, callID), toolErr, opts.ErrorFormat), nil, nil
 		}
 
+		telemetry.BytesSent = len(body)
+
 		// Build HTTP request
 		method := strings.ToUpper(op.Method)
-		httpReq, err := http.NewRequestWithContext(ctx, method, fullURL, bytes.NewReader(body))
+		reqCtx := withCallTelemetry(ctx, telemetry)
+		if timeout > 0 {
+			var cancel context.CancelFunc
+			reqCtx, cancel = context.WithTimeout(reqCtx, timeout)
+			defer cancel()
+		}
+		httpReq, err := http.NewRequestWithContext(reqCtx, method, fullURL, bytes.NewReader(body))
 		if err != nil {
 			return nil, nil, err
 		}
-		if len(body) > 0 && requestContentType != "" {
+		if rt.hosts != nil && !opts.Mock {
+			if err := rt.hosts.checkURL(httpReq.URL); err != nil {
+				errorText := fmt.Sprintf("Request blocked: %v\nOperation: %s\nCall ID: %s", err, op.OperationID, callID)
+				toolErr := &ToolError{
+					Code:      "host_not_allowed",
+					Message:   err.Error(),
+					Operation: op.OperationID,
+					CallID:    callID,
+				}
+				return toolErrorResult(errorText, toolErr, opts.ErrorFormat), nil, nil
+			}
+		}
+
+		// Stream a local file as the request body
+		if bodyFile != "" {
+			upload, err := openUploadFile(opts.UploadRoot, bodyFile, maxUploadFileBytes)
+			if err != nil {
+				errorText := fmt.Sprintf("Cannot upload %s: %v\nOperation: %s\nCall ID: %s", bodyFile, err, op.OperationID, callID)
+				toolErr := &ToolError{
+					Code:      "invalid_request_body_file",
+					Message:   err.Error(),
+					Operation: op.OperationID,
+					CallID:    callID,
+				}
+				return toolErrorResult(errorText, toolErr, opts.ErrorFormat), nil, nil
+			}
+			defer upload.file.Close()
+			progress := uploadProgress(ctx, req, upload.path, upload.size)
+			setUploadBody(httpReq, upload, progress)
+			if resumableUploads != nil && upload.size > 0 {
+				resumable = &resumableUpload{
+					cfg:      resumableUploads,
+					upload:   upload,
+					progress: progress,
+					baseURL:  baseURL,
+					backoff:  DefaultRetryBackoff,
+				}
+			}
+			bodyFile = filepath.Join(opts.UploadRoot, upload.path)
+			telemetry.BytesSent = int(upload.size)
+		}
+
+		if (len(body) > 0 || bodyFile != "") && requestContentType != "" {
 			httpReq.Header.Set("Content-Type", requestContentType)
 		}
 
-		// Set Accept header to accept both JSON and JSON:API responses
-		httpReq.Header.Set("Accept", "application/json, application/vnd.api+json")
+		// Accept the documented response types, or the one requested with __accept
+		if requested, _ := args[acceptArgument].(string); requested != "" {
+			httpReq.Header.Set("Accept", requested)
+		} else if graphQL {
+			httpReq.Header.Set("Accept", graphQLAccept)
+		} else {
+			httpReq.Header.Set("Accept", accept)
+		}
+
+		if opts.RequestIDHeader != "" {
+			httpReq.Header.Set(opts.RequestIDHeader, callID)
+		}
 
 		// --- AUTH HANDLING: inject per-operation security requirements ---
-		// For each security requirement object, try to satisfy at least one scheme
-		var securitySatisfied bool
-		for _, secReq := range op.Security {
-			for secName := range secReq {
-				// TODO fulfill ALL requirements
-				securitySatisfied = fulfillSecurity(secName, httpReq, doc)
+		security := operationSecurity(op, doc)
+		var oauth tokenSource
+		if !opts.Mock {
+			oauth = func(creds *clientCredentials, scopes []string) (string, error) {
+				switch {
+				case rt.login != nil:
+					return rt.login.accessToken()
+				case creds == nil:
+					return "", errNoOAuthToken
+				}
+				return rt.tokens.token(requestHandler, creds, scopes, httpReq.URL)
+			}
+		}
+		securitySatisfied, err := applySecurity(httpReq, security, doc, oauth)
+		if loginErr := (*loginRequiredError)(nil); errors.As(err, &loginErr) {
+			logger.InfoContext(ctx, "oauth_login_required", "operation", op.OperationID)
+			errorText := fmt.Sprintf("Sign-in required: the API needs the user to authorize access first. %s\nAsk the user to do this, then retry the call; the sign-in stays open for %d more minutes.\nOperation: %s\nCall ID: %s", loginErr.login.instructions(), int(math.Ceil(time.Until(loginErr.login.expiry).Minutes())), op.OperationID, callID)
+			toolErr := &ToolError{
+				Code:      "oauth_login_required",
+				Message:   loginErr.Error(),
+				Operation: op.OperationID,
+				CallID:    callID,
 			}
+			return toolErrorResult(errorText, toolErr, opts.ErrorFormat), nil, nil
+		}
+		if err != nil {
+			logger.ErrorContext(ctx, "oauth_token_failed", "operation", op.OperationID, "error", err)
+			errorText := fmt.Sprintf("Authentication failed: could not get an OAuth2 access token: %v\nCheck CLIENT_ID, CLIENT_SECRET, and TOKEN_URL (or the spec's tokenUrl); this call was not sent.\nOperation: %s\nCall ID: %s", err, op.OperationID, callID)
+			toolErr := &ToolError{
+				Code:      "oauth_token_failed",
+				Message:   err.Error(),
+				Operation: op.OperationID,
+				CallID:    callID,
+			}
+			return toolErrorResult(errorText, toolErr, opts.ErrorFormat), nil, nil
 		}
 
-		// If no security requirements, fallback to legacy env handling (for backward compatibility)
-		if !securitySatisfied {
-			apiKeyHeader := os.Getenv("API_KEY_HEADER")
-			if apiKey := os.Getenv("API_KEY"); apiKey != "" && apiKeyHeader != "" {
-				httpReq.Header.Set(apiKeyHeader, apiKey)
+		// If no security requirements, fallback to legacy env handling (for backward compatibility).
+		// Operations declaring that they need no authentication are sent without credentials.
+		if !securitySatisfied && !allowsAnonymous(security) {
+			apiKeyHeader := opts.APIKeyHeader
+			if apiKeyHeader == "" {
+				apiKeyHeader = os.Getenv("API_KEY_HEADER")
+			}
+			if apiKey := os.Getenv("API_KEY"); apiKey != "" {
+				if apiKeyHeader != "" {
+					httpReq.Header.Set(apiKeyHeader, apiKey)
+				} else if scheme := defaultAPIKeyScheme(doc); scheme != "" {
+					fulfillSecurity(scheme, nil, httpReq, doc, nil)
+				}
 			}
 			if bearer := os.Getenv("BEARER_TOKEN"); bearer != "" {
 				httpReq.Header.Set("Authorization", "Bearer "+bearer)
@@ -153,12 +553,10 @@ func toolHandler(
 			p := paramRef.Value
 			if p.In == "header" {
 				if val, ok := getParameterValue(args, p.Name, paramNameMapping); ok {
-					// Check if parameter is integer type
-					isInteger := false
-					if p.Schema != nil && p.Schema.Value != nil && p.Schema.Value.Type != nil {
-						isInteger = p.Schema.Value.Type.Is("integer")
+					httpReq.Header.Del(p.Name)
+					for _, pair := range parameterValues(p, val, nil) {
+						httpReq.Header.Add(pair[0], pair[1])
 					}
-					httpReq.Header.Set(p.Name, formatParameterValue(val, isInteger))
 				}
 			}
 		}
@@ -173,12 +571,9 @@ func toolHandler(
 			p := paramRef.Value
 			if p.In == "cookie" {
 				if val, ok := getParameterValue(args, p.Name, paramNameMapping); ok {
-					// Check if parameter is integer type
-					isInteger := false
-					if p.Schema != nil && p.Schema.Value != nil && p.Schema.Value.Type != nil {
-						isInteger = p.Schema.Value.Type.Is("integer")
+					for _, pair := range parameterValues(p, val, escapeCookieValue) {
+						cookiePairs = append(cookiePairs, pair[0]+"="+pair[1])
 					}
-					cookiePairs = append(cookiePairs, fmt.Sprintf("%s=%s", p.Name, formatParameterValue(val, isInteger)))
 				}
 			}
 		}
@@ -187,27 +582,246 @@ func toolHandler(
 			httpReq.Header.Set("Cookie", strings.Join(cookiePairs, "; "))
 		}
 
-		// Log HTTP request if logging is enabled
-		if os.Getenv("MCP_LOG_HTTP") != "" || os.Getenv("DEBUG") != "" {
-			logHTTPRequest(httpReq, body)
+		// Forward the allowed headers of the incoming HTTP request
+		forwardHeaders(httpReq, req, opts.ForwardHeaders)
+		if sandboxURL != "" {
+			opts.Sandbox.apply(httpReq)
+		}
+
+		// Send the session's cookies and CSRF token, and keep the cookies the API sets
+		var session mcp.Session
+		if req != nil {
+			session = req.Session
+		}
+		send := requestHandler
+		if sessionCookies {
+			send = rt.sessions.get(sessionCorrelationID(session)).handler(requestHandler, csrf, baseURL)
+		}
+
+		// Slow down requests nearing the API's rate limit
+		pace := func(host string) error {
+			if opts.Mock {
+				return nil
+			}
+			spec := rateLimit
+			if host != httpReq.URL.Host {
+				spec = nil // the documented limit is that of the operation's host
+			}
+			if delay := rt.limits.acquire(host, spec); delay > 0 && !opts.DisableRateLimitPacing {
+				logger.DebugContext(ctx, "rate_limit_pacing", "operation", op.OperationID, "delay", delay)
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(delay):
+				}
+			}
+			return nil
+		}
+
+		// Status polls of asynchronous operations are sent like the call itself: with the session's cookies and
+		// paced by the rate limit, within the call's concurrency slot
+		sessionSend := send
+		poll := func(r *http.Request) (*http.Response, error) {
+			if err := pace(r.URL.Host); err != nil {
+				return nil, err
+			}
+			resp, err := sessionSend(r)
+			if err == nil && !opts.Mock {
+				rt.limits.observe(r.URL.Host, resp)
+			}
+			return resp, err
+		}
+
+		// Share the response of an identical GET call of the same session that is still in flight
+		if opts.CoalesceRequests && method == http.MethodGet {
+			next := send
+			send = func(r *http.Request) (*http.Response, error) {
+				resp, shared, err := rt.inflight.do(coalesceKey(sessionCorrelationID(session), r, opts.RequestIDHeader), r, next, maxResponseBytes)
+				if shared {
+					telemetry.CacheHit = true
+					logger.DebugContext(ctx, "http_request_coalesced", "operation", op.OperationID)
+				}
+				return resp, err
+			}
+		}
+
+		// Send local files in chunks with the resumable upload protocol the operation declares
+		if resumable != nil {
+			resumable.send = send
+			send = resumable.Do
+		}
+
+		timeoutResult := func() *mcp.CallToolResult {
+			toolErr := &ToolError{
+				Code:      "timeout",
+				Message:   fmt.Sprintf("no response within %s", timeout),
+				Retriable: true,
+				Operation: op.OperationID,
+				CallID:    callID,
+			}
+			return toolErrorResult(timeoutText(timeout, op.OperationID, callID), toolErr, opts.ErrorFormat)
+		}
+
+		// Wait for a slot of the operation's concurrency limits, or reject the call as busy
+		if len(callLimits) > 0 {
+			release, busy, err := rt.slots.acquire(ctx, opts.ConcurrencyLimits, callLimits)
+			if err != nil {
+				return nil, nil, err
+			}
+			if busy != nil {
+				toolErr := &ToolError{
+					Code:      "busy",
+					Message:   fmt.Sprintf("at most %d calls may run at once", busy.Max),
+					Retriable: true,
+					Operation: op.OperationID,
+					CallID:    callID,
+				}
+				return toolErrorResult(busyText(busy, op.OperationID, callID), toolErr, opts.ErrorFormat), nil, nil
+			}
+			defer release()
+		}
+
+		// Guard updates the agent didn't send an ETag or version for against overwriting concurrent changes; the GET
+		// fetching them is paced and counted like the call's other requests
+		if concurrency != nil && bodyFile == "" {
+			prefetch := func(r *http.Request) (*http.Response, error) {
+				if err := pace(r.URL.Host); err != nil {
+					return nil, err
+				}
+				telemetry.Prefetches++
+				resp, err := send(r)
+				if err == nil && !opts.Mock {
+					rt.limits.observe(r.URL.Host, resp)
+				}
+				return resp, err
+			}
+			body, concurrencyNote = fillConcurrencyToken(httpReq, body, concurrency, prefetch)
 		}
 
-		resp, err := requestHandler(httpReq)
+		// Slow down calls nearing the API's rate limit
+		if err := pace(httpReq.URL.Host); err != nil {
+			return nil, nil, err
+		}
+
+		logHTTPRequest(ctx, logger, httpReq, body, opts)
+
+		sent, sentBody, sentFile = httpReq, body, bodyFile
+		resp, err := send(httpReq)
 		if err != nil {
+			logger.ErrorContext(ctx, "http_request_failed", "operation", op.OperationID, "error", err)
+			if timeout > 0 && timedOut(ctx, err) {
+				return timeoutResult(), nil, nil
+			}
 			return nil, nil, err
 		}
 		defer resp.Body.Close()
-		respBody, _ := io.ReadAll(resp.Body)
+		if !opts.Mock {
+			rt.limits.observe(httpReq.URL.Host, resp)
+			if status, ok := rt.limits.status(httpReq.URL.Host); ok {
+				rateLimitNote = rateLimitWarning(status, opts.RateLimitWarning, time.Now())
+			}
+			// A rejected OAuth2 access token is fetched anew by the next call
+			if resp.StatusCode == http.StatusUnauthorized {
+				accessToken := strings.TrimPrefix(httpReq.Header.Get("Authorization"), "Bearer ")
+				rt.tokens.forget(accessToken)
+				if rt.login != nil {
+					rt.login.forget(accessToken)
+				}
+			}
+		}
+		respBody, truncated, release, err := readResponseBody(resp, maxResponseBytes)
+		defer release()
+		if err != nil {
+			logger.ErrorContext(ctx, "http_response_read_failed", "operation", op.OperationID, "error", err)
+			if timeout > 0 && timedOut(ctx, err) {
+				return timeoutResult(), nil, nil
+			}
+			return nil, nil, err
+		}
+		telemetry.HTTPStatus = resp.StatusCode
+		telemetry.BytesReceived = len(respBody)
+
+		logHTTPResponse(ctx, logger, resp, respBody, opts)
 
-		// Log HTTP response if logging is enabled
-		if os.Getenv("MCP_LOG_HTTP") != "" || os.Getenv("DEBUG") != "" {
-			logHTTPResponse(resp, respBody)
+		// An operation accepted for asynchronous processing: wait for it if configured, else report it as pending
+		var pending *asyncPending
+		if resp.StatusCode == http.StatusAccepted && !opts.Mock {
+			if statusURL := asyncStatusURL(resp, respBody); statusURL != nil && (rt.hosts == nil || rt.hosts.checkURL(statusURL) == nil) {
+				pending = &asyncPending{
+					StatusURL:  statusURL.String(),
+					RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+					NextCall:   statusOperationCall(statusURL, baseURLs, rt.ops),
+				}
+				if opts.AsyncPolling != nil {
+					final, err := pollStatus(ctx, poll, httpReq, statusURL, pending.RetryAfter, opts.AsyncPolling)
+					if err != nil {
+						logger.ErrorContext(ctx, "http_status_poll_failed", "operation", op.OperationID, "error", err)
+						return nil, nil, err
+					}
+					if final != nil {
+						defer final.Body.Close()
+						var releaseFinal func()
+						resp = final
+						respBody, truncated, releaseFinal, err = readResponseBody(resp, maxResponseBytes)
+						defer releaseFinal()
+						if err != nil {
+							logger.ErrorContext(ctx, "http_response_read_failed", "operation", op.OperationID, "error", err)
+							return nil, nil, err
+						}
+						telemetry.HTTPStatus = resp.StatusCode
+						telemetry.BytesReceived += len(respBody)
+						logHTTPResponse(ctx, logger, resp, respBody, opts)
+						pending = nil
+					}
+				}
+			}
 		}
 
 		contentType := resp.Header.Get("Content-Type")
-		isJSON := strings.HasPrefix(contentType, "application/json") || strings.HasPrefix(contentType, "application/vnd.api+json")
-		isText := strings.HasPrefix(contentType, "text/")
-		isBinary := !isJSON && !isText
+		// Transcode text in legacy charsets like ISO-8859-1 or Shift_JIS, which would otherwise come out as mojibake
+		if !headersResultMethod(method) {
+			respBody, contentType = decodeCharset(contentType, respBody)
+		}
+		tabular := tabularKind(contentType)
+		isJSON := (strings.HasPrefix(contentType, "application/json") || strings.HasPrefix(contentType, "application/vnd.api+json") || strings.HasPrefix(contentType, "application/graphql-response+json")) && tabular == ""
+		isText := strings.HasPrefix(contentType, "text/") || strings.HasPrefix(contentType, "message/http") || tabular != "" // TRACE echoes the request
+
+		// Decide by the payload if the content type is wrong, e.g. for JSON sent as application/octet-stream
+		var sniffNotes string
+		if tabular == "" && !headersResultMethod(method) {
+			isJSON, isText, sniffNotes = sniffResponse(contentType, respBody, truncated, isJSON, isText)
+		}
+		isBinary := !isJSON && !isText && !headersResultMethod(method)
+		recordStepExchange(ctx, resp, respBody, isJSON && !truncated)
+
+		// Cut off bodies beyond the transport's limit; the complete body stays available as a resource
+		shownLimit := maxResponseBytes
+		if limit := opts.TransportResponseLimits[transportOf(req)]; limit > 0 && limit < shownLimit {
+			shownLimit = limit
+		}
+		limitBody := func(body []byte) ([]byte, *ResponseTruncation) {
+			if truncated {
+				return body, &ResponseTruncation{OriginalBytes: max(resp.ContentLength, 0), ShownBytes: len(body), LimitBytes: maxResponseBytes}
+			}
+			if int64(len(body)) <= shownLimit {
+				return body, nil
+			}
+			rt.results.put(callID, contentType, respBody)
+			shown := cutBody(body, int(shownLimit))
+			return shown, &ResponseTruncation{OriginalBytes: int64(len(body)), ShownBytes: len(shown), LimitBytes: shownLimit, Resource: resultURIPrefix + callID}
+		}
+
+		// Response headers the operator wants the model to see, e.g. Location after a 201
+		selectedHeaders := selectResponseHeaders(resp.Header, responseHeaderPatterns(opts, op.OperationID), opts.Redaction)
+
+		if pending != nil {
+			return pendingResult(op.Method, fullURL, resp.StatusCode, callID, formatHeaderLines(selectedHeaders), respBody, *pending), nil, nil
+		}
+
+		// A redirect that was not followed: point the model to the new location instead of failing
+		if location := resp.Header.Get("Location"); location != "" && resp.StatusCode >= 300 && resp.StatusCode < 400 && resp.StatusCode != http.StatusNotModified {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: formatRedirectResult(op.Method, fullURL, resp.StatusCode, callID, location, formatHeaderLines(selectedHeaders))}}}, nil, nil
+		}
 
 		// LLM-friendly error handling for non-2xx responses
 		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
@@ -217,6 +831,12 @@ func toolHandler(
 			}
 			opDesc := op.Description
 
+			toolErr := newHTTPToolError(op, resp)
+			toolErr.CallID = callID
+			if resp.StatusCode == 400 || resp.StatusCode == 422 {
+				toolErr.FieldErrors = extractFieldErrors(op, inputSchema, respBody)
+			}
+
 			suggestion := "Check the input parameters, authentication, and consult the tool schema. See the OpenAPI documentation for more details."
 
 			// Pass schema directly to error handling functions
@@ -226,20 +846,17 @@ func toolHandler(
 			case resp.StatusCode == 404:
 				suggestion = generateAI404ErrorResponse(op, inputSchema, args, string(respBody))
 			case resp.StatusCode == 400:
-				suggestion = generateAI400ErrorResponse(op, inputSchema, args, string(respBody))
+				suggestion = generateAI400ErrorResponse(op, inputSchema, args, string(respBody), opts.ExampleGenerators)
+			case resp.StatusCode == 422:
+				suggestion = generateAI422ErrorResponse(op, inputSchema, args, string(respBody), toolErr.FieldErrors)
 			case resp.StatusCode >= 500:
-				suggestion = generateAI5xxErrorResponse(op, inputSchema, args, string(respBody), resp.StatusCode)
+				suggestion = generateAI5xxErrorResponse(op, inputSchema, args, string(respBody), resp.StatusCode, opts.ExampleGenerators)
 			}
 
 			// For binary error responses, include base64 and mime type
 			if isBinary {
 				fileBase64 := base64.StdEncoding.EncodeToString(respBody)
-				fileName := "file"
-				if cd := resp.Header.Get("Content-Disposition"); cd != "" {
-					if parts := strings.Split(cd, "filename="); len(parts) > 1 {
-						fileName = strings.Trim(parts[1], `"`)
-					}
-				}
+				fileName := responseFilename(resp.Header)
 				errorObj := map[string]any{
 					"type": "api_response",
 					"error": map[string]any{
@@ -251,6 +868,7 @@ func toolHandler(
 						"mime_type":   contentType,
 						"file_base64": fileBase64,
 						"file_name":   fileName,
+						"call_id":     callID,
 						"operation": map[string]any{
 							"id":          op.OperationID,
 							"summary":     opSummary,
@@ -260,77 +878,252 @@ func toolHandler(
 				}
 				errorJSON, _ := json.MarshalIndent(errorObj, "", "  ")
 
-				return &mcp.CallToolResult{
+				result := &mcp.CallToolResult{
 					Content: []mcp.Content{
 						&mcp.TextContent{
 							Text: string(errorJSON),
 						},
 					},
 					IsError: true,
-				}, nil, nil
+				}
+				if opts.ErrorFormat == ErrorFormatJSON || opts.ErrorFormat == ErrorFormatBoth {
+					result.StructuredContent = map[string]any{"error": toolErr}
+				}
+				return result, nil, nil
 			}
 
 			// Create a simple text error message
 			errorText := fmt.Sprintf("HTTP %s %s\nError: %s (HTTP %d)", op.Method, fullURL, http.StatusText(resp.StatusCode), resp.StatusCode)
-			if len(respBody) > 0 {
-				errorText += "\nDetails: " + string(respBody)
+			if len(selectedHeaders) > 0 {
+				errorText += "\n" + strings.TrimSuffix(formatHeaderLines(selectedHeaders), "\n")
+			}
+			details, truncation := limitBody(stripBody(opts.ContentGuard, respBody, contentType, isJSON, false))
+			if len(details) > 0 {
+				var guardNotice string
+				if opts.ContentGuard != nil {
+					details, guardNotice = guardBody(details)
+				}
+				errorText += "\nDetails: " + string(details)
+				if truncation != nil {
+					errorText += truncation.notice()
+				}
+				errorText += guardNotice
+			}
+			if graphQL {
+				if errs, _ := graphQLErrors(respBody); len(errs) > 0 {
+					errorText += "\nGraphQL errors:\n" + formatGraphQLErrors(errs)
+				}
 			}
 			if suggestion != "" {
 				errorText += "\nSuggestion: " + suggestion
 			}
+			if toolErr.DocsURL != "" {
+				errorText += "\nDocumentation: " + toolErr.DocsURL
+			}
 			errorText += fmt.Sprintf("\nOperation: %s (%s)", op.OperationID, opSummary)
+			errorText += "\nCall ID: " + callID
 
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{
-						Text: errorText,
-					},
-				},
-				IsError: true,
-			}, nil, nil
+			result = toolErrorResult(errorText, toolErr, opts.ErrorFormat)
+			setResultTruncation(result, truncation)
+			return result, nil, nil
 		}
 
-		// Handle binary/file responses for success
-		if isBinary && resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			fileBase64 := base64.StdEncoding.EncodeToString(respBody)
-			fileName := "file"
-			if cd := resp.Header.Get("Content-Disposition"); cd != "" {
-				if parts := strings.Split(cd, "filename="); len(parts) > 1 {
-					fileName = strings.Trim(parts[1], `"`)
+		// GraphQL reports errors with HTTP 200: fail if no data was returned, else point out the partial failure
+		var graphQLNotes string
+		if graphQL && isJSON && !truncated {
+			if errs, hasData := graphQLErrors(respBody); len(errs) > 0 && !hasData {
+				errorText := fmt.Sprintf("HTTP %s %s\nGraphQL errors:\n%s\nOperation: %s\nCall ID: %s", op.Method, fullURL, formatGraphQLErrors(errs), op.OperationID, callID)
+				toolErr := &ToolError{
+					Code:       "graphql_error",
+					HTTPStatus: resp.StatusCode,
+					Message:    errs[0].Message,
+					Operation:  op.OperationID,
+					CallID:     callID,
 				}
+				return toolErrorResult(errorText, toolErr, opts.ErrorFormat), nil, nil
+			} else if len(errs) > 0 {
+				graphQLNotes = "\n\n[GRAPHQL ERRORS: partial data was returned]\n" + formatGraphQLErrors(errs)
 			}
+		}
+
+		// Prune the response as configured by the operator
+		shownBody, bodyNotes := respBody, sniffNotes+graphQLNotes
+		if rule, ok := opts.ResponseTrimming.ruleFor(op.OperationID); ok && isJSON && !truncated {
+			var trimmed bool
+			if shownBody, trimmed = trimResponseBody(rule, respBody); trimmed {
+				bodyNotes += trimmedNotice(rule)
+			}
+		}
+
+		// Select the data requested with __filter; failures keep the full response, as the call already happened
+		if filter != nil {
+			switch {
+			case !isJSON:
+				bodyNotes += filterNotAppliedNotice("the response is not JSON")
+			case truncated:
+				bodyNotes += filterNotAppliedNotice("the response was truncated")
+			default:
+				if filtered, err := filterResponseBody(filter, shownBody); err != nil {
+					bodyNotes += filterNotAppliedNotice(err.Error())
+				} else {
+					shownBody = filtered
+				}
+			}
+		}
+
+		// Let the embedder reshape the successful response before it reaches the model
+		var transformed mcp.Content
+		if opts.TransformResult != nil {
+			transformed, err = opts.TransformResult(op, resp.StatusCode, shownBody)
+			if err != nil {
+				errorText := fmt.Sprintf("HTTP %s %s\nError: failed to transform the response (HTTP %d): %v\nOperation: %s\nCall ID: %s", op.Method, fullURL, resp.StatusCode, err, op.OperationID, callID)
+				toolErr := &ToolError{
+					Code:       "transform_failed",
+					HTTPStatus: resp.StatusCode,
+					Message:    err.Error(),
+					Operation:  op.OperationID,
+					CallID:     callID,
+				}
+				return toolErrorResult(errorText, toolErr, opts.ErrorFormat), nil, nil
+			}
+		}
+
+		// Handle binary/file responses for success
+		if isBinary && transformed == nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			fileName := responseFilename(resp.Header)
 			resultObj := map[string]any{
 				"type":        "api_response",
 				"http_status": resp.StatusCode,
 				"mime_type":   contentType,
-				"file_base64": fileBase64,
 				"file_name":   fileName,
+				"call_id":     callID,
 				"operation": map[string]any{
 					"id":          op.OperationID,
 					"summary":     op.Summary,
 					"description": op.Description,
 				},
 			}
-			resultJSON, _ := json.MarshalIndent(resultObj, "", "  ")
+			if truncated {
+				resultObj["truncated"] = true
+			}
+			if sniffNotes != "" {
+				resultObj["mime_type"], resultObj["declared_mime_type"] = http.DetectContentType(respBody), contentType
+			}
+			if len(selectedHeaders) > 0 {
+				resultObj["headers"] = selectedHeaders
+			}
 			return &mcp.CallToolResult{
 				Content: []mcp.Content{
 					&mcp.TextContent{
-						Text: string(resultJSON),
+						Text: formatBinaryResult(resultObj, respBody),
 					},
 				},
 			}, nil, nil
 		}
 
+		// Convert HTML pages to Markdown for readability; the raw page stays available as a resource
+		converted := opts.HTMLToMarkdown && isHTML(contentType) && isText && transformed == nil && !headersResultMethod(method) && resp.StatusCode >= 200 && resp.StatusCode < 300
+		if converted {
+			rt.results.put(callID, contentType, respBody)
+			shownBody = []byte(htmlToMarkdown(respBody))
+			bodyNotes += fmt.Sprintf("\n\n[CONVERTED FROM HTML: the raw page is available as the resource %s%s.]", resultURIPrefix, callID)
+		}
+
+		// Parse CSV and NDJSON into records: all of them as structured content, the first ones as a compact preview
+		var structured *tabularData
+		if tabular != "" && transformed == nil && !headersResultMethod(method) && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			if data, err := parseTabular(tabular, respBody, truncated, maxTabularRecords); err == nil {
+				shownBody, structured = data.preview(), data
+			} else {
+				logger.WarnContext(ctx, "tabular_parse_failed", "operation", op.OperationID, "error", err)
+			}
+		}
+
+		if transformed == nil {
+			shownBody = stripBody(opts.ContentGuard, shownBody, contentType, isJSON, converted)
+		}
+		shownBody, truncation := limitBody(shownBody)
+
+		// Mark the response as data, so that text in it isn't taken for instructions to the model
+		if opts.ContentGuard != nil && transformed == nil {
+			var guardNotice string
+			shownBody, guardNotice = guardBody(shownBody)
+			bodyNotes += guardNotice
+		}
+
 		// Always format the response as: HTTP <METHOD> <URL>\nStatus: <status>\nResponse:\n<respBody>
-		respText := fmt.Sprintf("HTTP %s %s\nStatus: %d\nResponse:\n%s", op.Method, fullURL, resp.StatusCode, string(respBody))
+		respText := formatTextResult(op.Method, fullURL, resp.StatusCode, callID, formatHeaderLines(selectedHeaders), shownBody)
+		if headersResultMethod(method) {
+			respText = formatHeadersResult(method, fullURL, resp.StatusCode, callID, resp.Header, shownBody, opts.Redaction)
+		}
+		if truncation != nil {
+			respText += truncation.notice()
+		}
+
+		// Notes following the response; a transformed response is followed by them in a separate content block
+		notes := bodyNotes
+
+		// Optionally check the response against the documented schema (a truncated body can't match)
+		if opts.ValidateResponses && isJSON && !truncated {
+			if mismatches := validateResponseBody(op, resp.StatusCode, respBody); len(mismatches) > 0 {
+				notes += formatResponseValidationWarning(resp.StatusCode, mismatches)
+			}
+		}
+
+		// Suggest the follow-up operations documented as links of the response, and record state-changing calls in
+		// the session's call group with how to undo them
+		links := responseLinksFor(op, resp.StatusCode)
+		grouped := opts.CallGroups && unsafeMethod(method)
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 && (len(links) > 0 || grouped) {
+			var body any
+			if isJSON && !truncated {
+				_ = json.Unmarshal(respBody, &body)
+			}
+			exchange := &linkContext{
+				method:     op.Method,
+				url:        fullURL,
+				args:       args,
+				statusCode: resp.StatusCode,
+				header:     resp.Header,
+				body:       body,
+			}
+			notes += formatNextSteps(links, doc, exchange, opts.NameFormat)
+			if grouped {
+				call := groupedCall{Tool: name, Method: method, URL: fullURL, Status: resp.StatusCode}
+				call.Compensation, call.Note = compensate(op, doc, exchange, rt.ops)
+				rt.groups.record(sessionCorrelationID(session), call)
+			}
+		}
+
+		// Show whether there are more pages, and the arguments of the call for the next one
+		var page *Pagination
+		if pagination != nil && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			var body any
+			if isJSON && !truncated {
+				_ = json.Unmarshal(respBody, &body)
+			}
+			if page = pagination.paginate(args, resp.Header, body); page != nil {
+				notes += paginationText(page, name)
+			}
+		}
+
+		content := []mcp.Content{&mcp.TextContent{Text: respText + notes}}
+		if transformed != nil {
+			content = []mcp.Content{transformed}
+			if notes = strings.TrimSpace(notes); notes != "" {
+				content = append(content, &mcp.TextContent{Text: notes})
+			}
+		}
+
+		result = &mcp.CallToolResult{Content: content}
+		if structured != nil {
+			result.StructuredContent = structured
+		}
+		setResultTruncation(result, truncation)
+		setResultPagination(result, page)
+
 		if args["stream"] == true {
-			return &mcp.CallToolResult{
-				Content: []mcp.Content{
-					&mcp.TextContent{
-						Text: respText,
-					},
-				},
-			}, nil, nil
+			return result, nil, nil
 		}
 
 		if confirmDangerousActions && (method == "PUT" || method == "POST" || method == "DELETE") {
@@ -346,17 +1139,96 @@ func toolHandler(
 			}
 		}
 
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{
-					Text: respText,
-				},
-			},
-		}, nil, nil
+		return result, nil, nil
+	}
+}
+
+// callIDMetaKey is the result _meta key carrying the per-call correlation ID.
+const callIDMetaKey = "openapi-mcp/call_id"
+
+// setResultCallID records the call correlation ID in the result's _meta field.
+func setResultCallID(result *mcp.CallToolResult, callID string) {
+	if result == nil {
+		return
+	}
+	if result.Meta == nil {
+		result.Meta = mcp.Meta{}
 	}
+	result.Meta[callIDMetaKey] = callID
 }
 
-func fulfillSecurity(secName string, httpReq *http.Request, doc *openapi3.T) bool {
+// defaultAPIKeyScheme returns the name of the spec's first apiKey security scheme (in name order),
+// used to send API_KEY to operations without a satisfiable security requirement. Returns "" if there is none.
+func defaultAPIKeyScheme(doc *openapi3.T) string {
+	if doc.Components == nil {
+		return ""
+	}
+	for _, name := range slices.Sorted(maps.Keys(doc.Components.SecuritySchemes)) {
+		if ref := doc.Components.SecuritySchemes[name]; ref != nil && ref.Value != nil && ref.Value.Type == "apiKey" && ref.Value.Name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// operationSecurity returns the security requirements of op: its own, or else the document-level ones.
+// An empty, non-nil result means that the operation requires no authentication ("security: []").
+func operationSecurity(op OpenAPIOperation, doc *openapi3.T) openapi3.SecurityRequirements {
+	if op.Security != nil || doc == nil {
+		return op.Security
+	}
+	return doc.Security
+}
+
+// allowsAnonymous reports whether security explicitly allows requests without credentials:
+// it is empty but non-nil, or one of its alternatives is the empty requirement {}.
+func allowsAnonymous(security openapi3.SecurityRequirements) bool {
+	if security == nil {
+		return false
+	}
+	return len(security) == 0 || slices.ContainsFunc(security, func(req openapi3.SecurityRequirement) bool { return len(req) == 0 })
+}
+
+// tokenSource returns an OAuth2 access token: that of the signed in user with an OAuthLogin, or else one for the
+// client credentials (nil if the environment doesn't set them) and scopes. It returns errNoOAuthToken if neither
+// is configured.
+type tokenSource func(creds *clientCredentials, scopes []string) (string, error)
+
+// applySecurity adds the credentials of the first security requirement whose schemes can all be
+// fulfilled from the environment to httpReq, and reports whether there was one. If none was, it returns
+// the error of fetching an OAuth2 access token, if any, as the request would otherwise go out without one.
+func applySecurity(httpReq *http.Request, security openapi3.SecurityRequirements, doc *openapi3.T, oauth tokenSource) (bool, error) {
+	var tokenErr error
+	for _, secReq := range security {
+		if len(secReq) == 0 {
+			continue
+		}
+		// Fulfill the requirement on a copy, so that a partially fulfilled one leaves no credentials behind
+		trial := httpReq.Clone(httpReq.Context())
+		satisfied := true
+		for _, secName := range slices.Sorted(maps.Keys(secReq)) {
+			ok, err := fulfillSecurity(secName, secReq[secName], trial, doc, oauth)
+			if err != nil {
+				tokenErr = err
+			}
+			if !ok {
+				satisfied = false
+				break
+			}
+		}
+		if satisfied {
+			httpReq.Header = trial.Header
+			httpReq.URL = trial.URL
+			return true, nil
+		}
+	}
+	return false, tokenErr
+}
+
+// fulfillSecurity adds the credentials of the security scheme from the environment to httpReq, and reports whether
+// it could. oauth2 schemes get BEARER_TOKEN or else the access token of oauth, if not nil: that of the signed in user,
+// or of the client credentials grant with the scopes if the environment sets CLIENT_ID and CLIENT_SECRET.
+func fulfillSecurity(secName string, scopes []string, httpReq *http.Request, doc *openapi3.T, oauth tokenSource) (bool, error) {
 	if doc.Components != nil && doc.Components.SecuritySchemes != nil {
 		if secSchemeRef, ok := doc.Components.SecuritySchemes[secName]; ok && secSchemeRef.Value != nil {
 			secScheme := secSchemeRef.Value
@@ -365,13 +1237,13 @@ func fulfillSecurity(secName string, httpReq *http.Request, doc *openapi3.T) boo
 				if secScheme.Scheme == "bearer" {
 					if bearer := os.Getenv("BEARER_TOKEN"); bearer != "" {
 						httpReq.Header.Set("Authorization", "Bearer "+bearer)
-						return true
+						return true, nil
 					}
 				} else if secScheme.Scheme == "basic" {
 					if basic := os.Getenv("BASIC_AUTH"); basic != "" {
 						encoded := base64.StdEncoding.EncodeToString([]byte(basic))
 						httpReq.Header.Set("Authorization", "Basic "+encoded)
-						return true
+						return true, nil
 					}
 				}
 
@@ -379,14 +1251,14 @@ func fulfillSecurity(secName string, httpReq *http.Request, doc *openapi3.T) boo
 				if secScheme.In == "header" && secScheme.Name != "" {
 					if apiKey := os.Getenv("API_KEY"); apiKey != "" {
 						httpReq.Header.Set(secScheme.Name, apiKey)
-						return true
+						return true, nil
 					}
 				} else if secScheme.In == "query" && secScheme.Name != "" {
 					if apiKey := os.Getenv("API_KEY"); apiKey != "" {
 						q := httpReq.URL.Query()
 						q.Set(secScheme.Name, apiKey)
 						httpReq.URL.RawQuery = q.Encode()
-						return true
+						return true, nil
 					}
 				} else if secScheme.In == "cookie" && secScheme.Name != "" {
 					if apiKey := os.Getenv("API_KEY"); apiKey != "" {
@@ -396,18 +1268,29 @@ func fulfillSecurity(secName string, httpReq *http.Request, doc *openapi3.T) boo
 						}
 						cookie += secScheme.Name + "=" + apiKey
 						httpReq.Header.Set("Cookie", cookie)
-						return true
+						return true, nil
 					}
 				}
 
 			case "oauth2":
 				if bearer := os.Getenv("BEARER_TOKEN"); bearer != "" {
 					httpReq.Header.Set("Authorization", "Bearer "+bearer)
-					return true
+					return true, nil
+				}
+				if oauth != nil {
+					token, err := oauth(schemeClientCredentials(secScheme), scopes)
+					if errors.Is(err, errNoOAuthToken) {
+						break
+					}
+					if err != nil {
+						return false, err
+					}
+					httpReq.Header.Set("Authorization", "Bearer "+token)
+					return true, nil
 				}
 			}
 		}
 	}
 
-	return false
+	return false, nil
 }
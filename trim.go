@@ -0,0 +1,190 @@
+// trim.go
+package openapi2mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"unicode/utf8"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// TrimRule prunes noisy parts of a JSON response before it is returned to the model.
+//
+// Drop lists JSON paths to remove, in the JSONPath subset of RedactionRules: "$.body_html", "$.items[*].avatar".
+// MaxArrayLength caps arrays (at any depth) to their first elements; MaxStringLength cuts long string values.
+// Zero limits mean no limit.
+type TrimRule struct {
+	Drop            []string `json:"drop,omitempty" yaml:"drop,omitempty"`
+	MaxArrayLength  int      `json:"maxArrayLength,omitempty" yaml:"maxArrayLength,omitempty"`
+	MaxStringLength int      `json:"maxStringLength,omitempty" yaml:"maxStringLength,omitempty"`
+}
+
+// ResponseTrimming maps operationIds to the trimming rules of their successful JSON responses.
+// The rule for "*" applies to all operations: its Drop paths are combined with the operation's,
+// and its limits apply where the operation's rule sets none.
+type ResponseTrimming map[string]TrimRule
+
+// LoadResponseTrimming reads trimming rules from a YAML or JSON file:
+//
+//	"*":
+//	  maxStringLength: 2000
+//	getIssue:
+//	  drop: ["$.fields.description_html", "$.fields.attachments[*].thumbnail"]
+//	  maxArrayLength: 20
+//
+// Example usage for LoadResponseTrimming:
+//
+//	trimming, err := openapi2mcp.LoadResponseTrimming("trim.yaml")
+//	if err != nil { log.Fatal(err) }
+//	opts.ResponseTrimming = trimming
+func LoadResponseTrimming(path string) (ResponseTrimming, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var trimming ResponseTrimming
+	if err := yaml.Unmarshal(data, &trimming); err != nil {
+		return nil, fmt.Errorf("invalid response trimming rules %s: %w", path, err)
+	}
+	for opID, rule := range trimming {
+		if rule.MaxArrayLength < 0 || rule.MaxStringLength < 0 {
+			return nil, fmt.Errorf("invalid response trimming rules %s: negative limit for %q", path, opID)
+		}
+	}
+	return trimming, nil
+}
+
+// ruleFor returns the rule applying to the operation, combined with the "*" rule.
+func (t ResponseTrimming) ruleFor(operationID string) (TrimRule, bool) {
+	rule, ok := t[operationID]
+	all, hasAll := t["*"]
+	if !hasAll {
+		return rule, ok
+	}
+	if !ok {
+		return all, true
+	}
+	rule.Drop = append(append([]string(nil), all.Drop...), rule.Drop...)
+	if rule.MaxArrayLength == 0 {
+		rule.MaxArrayLength = all.MaxArrayLength
+	}
+	if rule.MaxStringLength == 0 {
+		rule.MaxStringLength = all.MaxStringLength
+	}
+	return rule, true
+}
+
+// trimResponseBody applies rule to a JSON body. It returns the body unchanged (and trimmed false)
+// if the body is not JSON or nothing was removed.
+func trimResponseBody(rule TrimRule, body []byte) (out []byte, trimmed bool) {
+	data, err := decodeJSONNumbers(body)
+	if err != nil {
+		return body, false
+	}
+	for _, path := range rule.Drop {
+		var dropped bool
+		data, dropped = dropJSONPath(data, parseJSONPath(path))
+		trimmed = trimmed || dropped
+	}
+	data, capped := capJSONValue(data, rule)
+	if !trimmed && !capped {
+		return body, false
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(data); err != nil {
+		return body, false
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), true
+}
+
+// dropJSONPath removes the value(s) addressed by segments and reports whether any was removed.
+// Array elements are removed by index or, with "*", all of them.
+func dropJSONPath(v any, segments []string) (any, bool) {
+	if len(segments) == 0 {
+		return v, false
+	}
+	seg, rest := segments[0], segments[1:]
+	dropped := false
+	switch val := v.(type) {
+	case map[string]any:
+		for k, item := range val {
+			if seg != "*" && seg != k {
+				continue
+			}
+			if len(rest) == 0 {
+				delete(val, k)
+				dropped = true
+				continue
+			}
+			var d bool
+			val[k], d = dropJSONPath(item, rest)
+			dropped = dropped || d
+		}
+	case []any:
+		i, err := strconv.Atoi(seg)
+		if seg != "*" && (err != nil || i < 0 || i >= len(val)) {
+			return v, false
+		}
+		if len(rest) == 0 {
+			if seg == "*" {
+				return []any{}, len(val) > 0
+			}
+			return append(val[:i:i], val[i+1:]...), true
+		}
+		for j, item := range val {
+			if seg == "*" || j == i {
+				var d bool
+				val[j], d = dropJSONPath(item, rest)
+				dropped = dropped || d
+			}
+		}
+	}
+	return v, dropped
+}
+
+// capJSONValue shortens arrays and strings exceeding the limits of rule and reports whether any was shortened.
+func capJSONValue(v any, rule TrimRule) (any, bool) {
+	capped := false
+	switch val := v.(type) {
+	case map[string]any:
+		for k, item := range val {
+			var c bool
+			val[k], c = capJSONValue(item, rule)
+			capped = capped || c
+		}
+	case []any:
+		if rule.MaxArrayLength > 0 && len(val) > rule.MaxArrayLength {
+			val, capped = val[:rule.MaxArrayLength], true
+		}
+		for i, item := range val {
+			var c bool
+			val[i], c = capJSONValue(item, rule)
+			capped = capped || c
+		}
+		return val, capped
+	case string:
+		if rule.MaxStringLength > 0 && utf8.RuneCountInString(val) > rule.MaxStringLength {
+			runes := []rune(val)
+			return string(runes[:rule.MaxStringLength]) + "…", true
+		}
+	}
+	return v, capped
+}
+
+// trimmedNotice tells the model that configured rules removed parts of the response.
+func trimmedNotice(rule TrimRule) string {
+	notice := "\n\n[RESPONSE TRIMMED: the server is configured to remove parts of this response"
+	if rule.MaxArrayLength > 0 {
+		notice += fmt.Sprintf("; arrays are cut to %d items", rule.MaxArrayLength)
+	}
+	if rule.MaxStringLength > 0 {
+		notice += fmt.Sprintf("; strings are cut to %d characters", rule.MaxStringLength)
+	}
+	return notice + ". Use pagination or more specific operations to see the omitted data.]"
+}
@@ -0,0 +1,103 @@
+package openapi2mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+func TestTrimResponseBody(t *testing.T) {
+	body := []byte(`{"id": 12345678901234567890, "html": "<p>x</p>", "items": [{"name": "a", "avatar": "AAAA"}, {"name": "b", "avatar": "BBBB"}, {"name": "c"}], "bio": "äöüäöü"}`)
+	tests := []struct {
+		rule TrimRule
+		want string
+	}{
+		{TrimRule{Drop: []string{"$.html", "$.items[*].avatar"}}, `{"bio":"äöüäöü","id":12345678901234567890,"items":[{"name":"a"},{"name":"b"},{"name":"c"}]}`},
+		{TrimRule{Drop: []string{"$.items[1]", "$.bio", "$.html"}}, `{"id":12345678901234567890,"items":[{"avatar":"AAAA","name":"a"},{"name":"c"}]}`},
+		{TrimRule{MaxArrayLength: 1, MaxStringLength: 3, Drop: []string{"$.html"}}, `{"bio":"äöü…","id":12345678901234567890,"items":[{"avatar":"AAA…","name":"a"}]}`},
+	}
+	for _, tc := range tests {
+		got, trimmed := trimResponseBody(tc.rule, body)
+		if !trimmed || string(got) != tc.want {
+			t.Errorf("trimResponseBody(%+v) = %s, %v, want %s", tc.rule, got, trimmed, tc.want)
+		}
+	}
+
+	// Untouched bodies keep their formatting
+	if got, trimmed := trimResponseBody(TrimRule{Drop: []string{"$.missing"}, MaxArrayLength: 10}, body); trimmed || string(got) != string(body) {
+		t.Errorf("expected body to be unchanged, got %s", got)
+	}
+}
+
+func TestResponseTrimming_RuleFor(t *testing.T) {
+	trimming := ResponseTrimming{
+		"*":        {Drop: []string{"$._links"}, MaxArrayLength: 50, MaxStringLength: 1000},
+		"getIssue": {Drop: []string{"$.html"}, MaxArrayLength: 10},
+	}
+	rule, ok := trimming.ruleFor("getIssue")
+	if !ok || strings.Join(rule.Drop, ",") != "$._links,$.html" || rule.MaxArrayLength != 10 || rule.MaxStringLength != 1000 {
+		t.Errorf("unexpected combined rule: %+v", rule)
+	}
+	if rule, ok := trimming.ruleFor("listIssues"); !ok || rule.MaxArrayLength != 50 {
+		t.Errorf("expected the \"*\" rule, got %+v", rule)
+	}
+	if _, ok := ResponseTrimming(nil).ruleFor("getIssue"); ok {
+		t.Error("expected no rule without configuration")
+	}
+}
+
+func TestLoadResponseTrimming(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trim.yaml")
+	if err := os.WriteFile(path, []byte("listPets:\n  drop: [\"$.items[*].photo\"]\n  maxArrayLength: 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	trimming, err := LoadResponseTrimming(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rule := trimming["listPets"]; len(rule.Drop) != 1 || rule.MaxArrayLength != 2 {
+		t.Errorf("unexpected rules: %+v", trimming)
+	}
+
+	if err := os.WriteFile(path, []byte("listPets: {maxStringLength: -1}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadResponseTrimming(path); err == nil {
+		t.Error("expected error for negative limit")
+	}
+}
+
+func TestToolHandler_ResponseTrimming(t *testing.T) {
+	op := OpenAPIOperation{OperationID: "listPets", Path: "/pets", Method: "get"}
+	opts := &ToolGenOptions{
+		RequestHandler:   fakeResponse(200, "application/json", `{"items": [{"name": "Rex", "photo": "iVBORw0KGgo="}, {"name": "Tom"}, {"name": "Kit"}]}`),
+		ResponseTrimming: ResponseTrimming{"listPets": {Drop: []string{"$.items[*].photo"}, MaxArrayLength: 2}},
+	}
+	handler := toolHandler("listPets", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+	res, _, err := handler(context.Background(), nil, map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := resultText(t, res)
+	if !strings.Contains(text, `{"items":[{"name":"Rex"},{"name":"Tom"}]}`) || !strings.Contains(text, "RESPONSE TRIMMED") {
+		t.Errorf("expected trimmed response, got: %s", text)
+	}
+
+	// __filter selects from the trimmed response
+	res, _, _ = handler(context.Background(), nil, map[string]any{"__filter": "items[].photo"})
+	if text := resultText(t, res); !strings.Contains(text, "Response:\n[]") {
+		t.Errorf("expected filter to see the trimmed response, got: %s", text)
+	}
+
+	// The notice is added to those of earlier steps, e.g. JSON sent as plain text
+	opts.RequestHandler = fakeResponse(200, "text/plain", `{"items": [{"name": "Rex", "photo": "iVBORw0KGgo="}]}`)
+	handler = toolHandler("listPets", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+	res, _, _ = handler(context.Background(), nil, map[string]any{})
+	if text := resultText(t, res); !strings.Contains(text, "CONTENT TYPE MISMATCH") || !strings.Contains(text, "RESPONSE TRIMMED") {
+		t.Errorf("expected both notices, got: %s", text)
+	}
+}
@@ -0,0 +1,104 @@
+// units.go
+package openapi2mcp
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// moneyWords are words of property names suggesting an amount of money.
+var moneyWords = []string{"amount", "price", "cost", "fee", "fees", "balance", "charge", "payment", "salary", "subtotal"}
+
+// minorUnitWords are words of property names stating that an amount is in minor currency units.
+var minorUnitWords = []string{"cents", "cent", "minor", "pence"}
+
+// unitHint explains the unit and precision of a numeric or currency value, so that agents don't send dollars where
+// cents are expected: the x-unit extension, multipleOf, currency formats, and amounts documented in minor units.
+// name is the property or parameter name; it returns "" if there is nothing to point out.
+func unitHint(s *openapi3.Schema, name string) string {
+	if s == nil {
+		return ""
+	}
+	var hints []string
+	if unit := schemaUnit(s); unit != "" {
+		hints = append(hints, "Unit: "+unit+".")
+	}
+
+	words := nameWords(name)
+	format := strings.ToLower(s.Format)
+	minor := slices.ContainsFunc(words, func(w string) bool { return slices.Contains(minorUnitWords, w) })
+	money := minor || slices.ContainsFunc(words, func(w string) bool { return slices.Contains(moneyWords, w) })
+	switch {
+	case format == "cents" || format == "minor-units" || format == "minor_units" || s.Type.Is("integer") && minor:
+		hints = append(hints, "Amount in minor currency units (e.g. cents): 10.50 is sent as 1050.")
+	case s.Type.Is("integer") && money && s.MultipleOf == nil:
+		hints = append(hints, "Integer amount: money is usually expected in minor currency units (e.g. cents), so 10.50 is sent as 1050.")
+	case format == "currency" || format == "money" || format == "decimal" && money:
+		hints = append(hints, "Amount in major currency units with decimals (e.g. 10.50), not in cents.")
+	case format == "iso4217" || format == "currency-code" || s.Type.Is("string") && slices.Contains(words, "currency") && s.MaxLength != nil && *s.MaxLength == 3:
+		hints = append(hints, "ISO 4217 currency code, e.g. USD or EUR.")
+	}
+
+	if s.MultipleOf != nil && *s.MultipleOf > 0 {
+		step := strconv.FormatFloat(*s.MultipleOf, 'f', -1, 64)
+		if decimals := strings.Index(step, "."); decimals >= 0 && *s.MultipleOf < 1 {
+			hints = append(hints, fmt.Sprintf("Multiple of %s (at most %d decimal places).", step, len(step)-decimals-1))
+		} else {
+			hints = append(hints, fmt.Sprintf("Multiple of %s.", step))
+		}
+	}
+	return strings.Join(hints, " ")
+}
+
+// schemaUnit returns the unit documented with the x-unit (or x-units) extension.
+func schemaUnit(s *openapi3.Schema) string {
+	for _, key := range []string{"x-unit", "x-units"} {
+		if unit, ok := s.Extensions[key].(string); ok && strings.TrimSpace(unit) != "" {
+			return strings.TrimSpace(unit)
+		}
+	}
+	return ""
+}
+
+// nameWords splits a camelCase, snake_case, or kebab-case name into lower-case words, e.g. "totalAmount_cents"
+// into total, amount, and cents.
+func nameWords(name string) []string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	for _, r := range name {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && len(word) > 0 && !unicode.IsUpper(word[len(word)-1]):
+			flush()
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+	return words
+}
+
+// withUnitHint appends the unit hint of the schema to a description.
+func withUnitHint(description string, s *openapi3.Schema, name string) string {
+	hint := unitHint(s, name)
+	if hint == "" || strings.Contains(description, hint) {
+		return description
+	}
+	if description == "" {
+		return hint
+	}
+	return strings.TrimRight(description, " ") + " " + hint
+}
@@ -0,0 +1,57 @@
+package openapi2mcp
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNameWords(t *testing.T) {
+	if got := nameWords("totalAmount_cents-v2"); !slices.Equal(got, []string{"total", "amount", "cents", "v2"}) {
+		t.Errorf("nameWords() = %v", got)
+	}
+}
+
+func TestBuildInputSchema_UnitHints(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /payments:
+    post:
+      operationId: createPayment
+      parameters:
+        - {name: maxFee, in: query, description: Upper bound of the fee., schema: {type: integer}}
+        - {name: totalCount, in: query, schema: {type: integer}}
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              properties:
+                amount_cents: {type: integer}
+                price: {type: number, format: decimal, multipleOf: 0.01}
+                currency: {type: string, maxLength: 3}
+                distance: {type: number, x-unit: km}
+      responses: {"201": {description: created}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	op := ExtractOpenAPIOperations(doc)[0]
+	schema := BuildInputSchema(op.Parameters, op.RequestBody)
+	body := schema.Properties["requestBody"].Properties
+	for desc, want := range map[string]string{
+		schema.Properties["maxFee"].Description: "Upper bound of the fee. Integer amount: money is usually expected in minor currency units (e.g. cents), so 10.50 is sent as 1050.",
+		body["amount_cents"].Description:        "Amount in minor currency units (e.g. cents): 10.50 is sent as 1050.",
+		body["price"].Description:               "Amount in major currency units with decimals (e.g. 10.50), not in cents. Multiple of 0.01 (at most 2 decimal places).",
+		body["currency"].Description:            "ISO 4217 currency code, e.g. USD or EUR.",
+		body["distance"].Description:            "Unit: km.",
+	} {
+		if desc != want {
+			t.Errorf("got description %q, want %q", desc, want)
+		}
+	}
+	if desc := schema.Properties["totalCount"].Description; strings.Contains(desc, "cents") {
+		t.Errorf("expected no money hint for a count, got %q", desc)
+	}
+}
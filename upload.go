@@ -0,0 +1,360 @@
+// upload.go
+package openapi2mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Resumable upload protocols of the x-mcp-upload extension.
+const (
+	uploadContentRange = "content-range" // chunks sent to the operation's URL with Content-Range headers
+	uploadTus          = "tus"           // tus 1.0.0 (https://tus.io): a created upload, sent with PATCH requests
+)
+
+// DefaultUploadChunkBytes is the chunk size of resumable uploads declaring none.
+const DefaultUploadChunkBytes = 8 << 20
+
+// uploadProgressInterval is the minimum time between two progress notifications of an upload.
+const uploadProgressInterval = 250 * time.Millisecond
+
+// uploadConfig is the x-mcp-upload extension of an operation taking a binary request body, e.g.
+//
+//	x-mcp-upload: {protocol: tus, chunkSize: 16777216, endpoint: /uploads}
+type uploadConfig struct {
+	Protocol  string `json:"protocol"`  // uploadContentRange or uploadTus
+	ChunkSize int64  `json:"chunkSize"` // bytes per request; default DefaultUploadChunkBytes
+	Endpoint  string `json:"endpoint"`  // tus only: path below the base URL, or URL, creating uploads; default: the operation's URL
+}
+
+// operationUpload returns the resumable upload protocol declared by the x-mcp-upload extension of the operation, or of
+// the spec if the operation has none, or nil if there is none or it names an unknown protocol.
+func operationUpload(op OpenAPIOperation, doc *openapi3.T) *uploadConfig {
+	if doc == nil {
+		return nil
+	}
+	ext := doc.Extensions["x-mcp-upload"]
+	if doc.Paths != nil {
+		if item := doc.Paths.Value(op.Path); item != nil {
+			if o := item.GetOperation(strings.ToUpper(op.Method)); o != nil && o.Extensions["x-mcp-upload"] != nil {
+				ext = o.Extensions["x-mcp-upload"]
+			}
+		}
+	}
+	if ext == nil {
+		return nil
+	}
+	var cfg uploadConfig
+	if protocol, ok := ext.(string); ok {
+		cfg.Protocol = protocol
+	} else if data, err := json.Marshal(ext); err != nil || json.Unmarshal(data, &cfg) != nil {
+		return nil
+	}
+	cfg.Protocol = strings.ToLower(cfg.Protocol)
+	if cfg.Protocol != uploadContentRange && cfg.Protocol != uploadTus {
+		return nil
+	}
+	if cfg.ChunkSize <= 0 {
+		cfg.ChunkSize = DefaultUploadChunkBytes
+	}
+	return &cfg
+}
+
+// uploadProgress returns a function reporting the bytes of name sent so far as MCP progress notifications, or nil
+// if the client didn't ask for progress with a progress token.
+func uploadProgress(ctx context.Context, req *mcp.CallToolRequest, name string, total int64) func(sent int64) {
+	if req == nil || req.Session == nil || req.Params == nil {
+		return nil
+	}
+	token := req.Params.GetProgressToken()
+	if token == nil {
+		return nil
+	}
+	return progressReporter(name, total, func(params *mcp.ProgressNotificationParams) {
+		params.ProgressToken = token
+		req.Session.NotifyProgress(ctx, params) // the upload goes on if the client is gone
+	})
+}
+
+// progressReporter returns a function passing the bytes of name sent so far to notify, at most every
+// uploadProgressInterval and only when they increased, except for the completed upload.
+func progressReporter(name string, total int64, notify func(*mcp.ProgressNotificationParams)) func(sent int64) {
+	var last time.Time
+	var reported int64
+	return func(sent int64) {
+		if sent <= reported || sent < total && time.Since(last) < uploadProgressInterval {
+			return
+		}
+		last, reported = time.Now(), sent
+		notify(&mcp.ProgressNotificationParams{
+			Progress: float64(sent),
+			Total:    float64(total),
+			Message:  fmt.Sprintf("Uploading %s: %d of %d bytes", name, sent, total),
+		})
+	}
+}
+
+// progressReader reports the bytes read from r, starting at offset, to progress.
+type progressReader struct {
+	r        io.Reader
+	offset   int64
+	progress func(sent int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.offset += int64(n)
+		p.progress(p.offset)
+	}
+	return n, err
+}
+
+// withProgress returns body reporting the bytes read from it, starting at offset, to progress, if it isn't nil.
+func withProgress(body io.ReadCloser, offset int64, progress func(sent int64)) io.ReadCloser {
+	if progress == nil || body == nil || body == http.NoBody {
+		return body
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{&progressReader{r: body, offset: offset, progress: progress}, body}
+}
+
+// resumableUpload sends a local file in chunks with a resumable upload protocol.
+type resumableUpload struct {
+	cfg      *uploadConfig
+	upload   *uploadFile
+	send     func(*http.Request) (*http.Response, error)
+	progress func(sent int64) // nil if progress isn't reported
+	baseURL  string           // resolves relative tus endpoints
+	backoff  time.Duration    // delay before the first resume in a row, doubled for each further one
+
+	chunks   int    // requests sending file content
+	resumes  int    // times the upload continued after a failed chunk
+	location string // tus only: the URL of the created upload
+}
+
+// Do sends the file of the upload, with the method, URL, and headers of req, and returns the response to its last
+// chunk, or the first response that failed permanently. Failed chunks are resumed from the offset the API reports,
+// up to DefaultRetryAttempts times in a row.
+func (u *resumableUpload) Do(req *http.Request) (*http.Response, error) {
+	if u.cfg.Protocol == uploadTus {
+		return u.tus(req)
+	}
+	return u.contentRange(req)
+}
+
+// Note describes how the upload was sent, for the result.
+func (u *resumableUpload) Note() string {
+	note := fmt.Sprintf("Uploaded %s (%d bytes) in %d chunks with %s", u.upload.path, u.upload.size, u.chunks, u.cfg.Protocol)
+	if u.resumes > 0 {
+		note += fmt.Sprintf(", resumed %d times after failures", u.resumes)
+	}
+	if u.location != "" {
+		note += ". Upload URL: " + u.location
+	}
+	return note + "."
+}
+
+// chunk returns a request sending the file from offset, up to the chunk size, and the offset following it.
+func (u *resumableUpload) chunk(req *http.Request, method, target string, offset int64) (*http.Request, int64, error) {
+	end := min(offset+u.cfg.ChunkSize, u.upload.size)
+	chunkReq := req.Clone(req.Context())
+	chunkReq.Method = method
+	var err error
+	if chunkReq.URL, err = url.Parse(target); err != nil {
+		return nil, 0, err
+	}
+	chunkReq.Host = ""
+	chunkReq.GetBody = func() (io.ReadCloser, error) {
+		return withProgress(io.NopCloser(io.NewSectionReader(u.upload.file, offset, end-offset)), offset, u.progress), nil
+	}
+	chunkReq.Body, _ = chunkReq.GetBody()
+	chunkReq.ContentLength = end - offset
+	u.chunks++
+	return chunkReq, end, nil
+}
+
+// chunkFailed reports whether sending a chunk failed transiently, so that the upload may be resumed.
+func chunkFailed(resp *http.Response, err error) bool {
+	return err != nil || isRetriableStatus(resp.StatusCode) || resp.StatusCode >= 500
+}
+
+// resume closes the response to a failed chunk and waits before resuming the upload for the attempt-th time in a
+// row. It fails if the call was cancelled.
+func (u *resumableUpload) resume(ctx context.Context, resp *http.Response, attempt int) error {
+	if resp != nil {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	u.resumes++
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(u.backoff << (attempt - 1)):
+		return nil
+	}
+}
+
+// rangeEnd matches the Range header of a 308 Resume Incomplete response, e.g. "bytes=0-1048575".
+var rangeEnd = regexp.MustCompile(`^bytes=0-(\d+)$`)
+
+// contentRange sends the file in chunks to the URL of req, each with a Content-Range header. Responses to chunks
+// before the last may be 308 Resume Incomplete, with the bytes received in a Range header, or any 2xx. After a
+// failure, the bytes received are queried with an empty request with "Content-Range: bytes */size".
+func (u *resumableUpload) contentRange(req *http.Request) (*http.Response, error) {
+	size := u.upload.size
+	for offset, attempt := int64(0), 0; ; {
+		chunkReq, next, err := u.chunk(req, req.Method, req.URL.String(), offset)
+		if err != nil {
+			return nil, err
+		}
+		chunkReq.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, next-1, size))
+		resp, err := u.send(chunkReq)
+		if chunkFailed(resp, err) {
+			if attempt++; attempt >= DefaultRetryAttempts {
+				return resp, err
+			}
+			if err := u.resume(req.Context(), resp, attempt); err != nil {
+				return nil, err
+			}
+			status := req.Clone(req.Context())
+			status.Body, status.GetBody, status.ContentLength = http.NoBody, nil, 0
+			status.Header.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+			resp, err := u.send(status)
+			if err != nil {
+				continue
+			}
+			if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+				return resp, nil
+			}
+			if resp.StatusCode == http.StatusPermanentRedirect {
+				offset = 0
+				if m := rangeEnd.FindStringSubmatch(resp.Header.Get("Range")); m != nil {
+					end, _ := strconv.ParseInt(m[1], 10, 64)
+					offset = end + 1
+				}
+			}
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			continue
+		}
+		attempt = 0
+		if next >= size || resp.StatusCode != http.StatusPermanentRedirect && (resp.StatusCode < 200 || resp.StatusCode >= 300) {
+			return resp, nil
+		}
+		if m := rangeEnd.FindStringSubmatch(resp.Header.Get("Range")); m != nil {
+			end, _ := strconv.ParseInt(m[1], 10, 64)
+			next = end + 1
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		offset = next
+	}
+}
+
+// tusVersion is the tus protocol version sent in the Tus-Resumable header.
+const tusVersion = "1.0.0"
+
+// tus creates an upload with a POST to the endpoint, and sends the file to the upload URL in its Location header
+// with PATCH requests. After a failure, the bytes received are queried with a HEAD request.
+func (u *resumableUpload) tus(req *http.Request) (*http.Response, error) {
+	endpoint := req.URL.String()
+	if u.cfg.Endpoint != "" {
+		endpoint = u.cfg.Endpoint
+		if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+			var err error
+			if endpoint, err = url.JoinPath(u.baseURL, endpoint); err != nil {
+				return nil, err
+			}
+		}
+	}
+	createURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	create := req.Clone(req.Context())
+	create.Method, create.URL, create.Host = http.MethodPost, createURL, ""
+	create.Body, create.GetBody, create.ContentLength = http.NoBody, nil, 0
+	contentType := create.Header.Get("Content-Type")
+	create.Header.Del("Content-Type")
+	create.Header.Set("Tus-Resumable", tusVersion)
+	create.Header.Set("Upload-Length", strconv.FormatInt(u.upload.size, 10))
+	metadata := "filename " + base64.StdEncoding.EncodeToString([]byte(path.Base(u.upload.path)))
+	if contentType != "" {
+		metadata += ",filetype " + base64.StdEncoding.EncodeToString([]byte(contentType))
+	}
+	create.Header.Set("Upload-Metadata", metadata)
+	resp, err := u.send(create)
+	if err != nil || resp.StatusCode != http.StatusCreated || resp.Header.Get("Location") == "" {
+		return resp, err
+	}
+	location, err := createURL.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return resp, nil
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	u.location = location.String()
+
+	for offset, attempt := int64(0), 0; ; {
+		chunkReq, _, err := u.chunk(req, http.MethodPatch, u.location, offset)
+		if err != nil {
+			return nil, err
+		}
+		chunkReq.Header.Set("Content-Type", "application/offset+octet-stream")
+		chunkReq.Header.Set("Tus-Resumable", tusVersion)
+		chunkReq.Header.Set("Upload-Offset", strconv.FormatInt(offset, 10))
+		resp, err := u.send(chunkReq)
+		// 409 Conflict means the offset didn't match the bytes the API received
+		if chunkFailed(resp, err) || resp.StatusCode == http.StatusConflict {
+			if attempt++; attempt >= DefaultRetryAttempts {
+				return resp, err
+			}
+			if err := u.resume(req.Context(), resp, attempt); err != nil {
+				return nil, err
+			}
+			head := chunkReq.Clone(req.Context())
+			head.Method = http.MethodHead
+			head.Body, head.GetBody, head.ContentLength = http.NoBody, nil, 0
+			head.Header.Del("Content-Type")
+			head.Header.Del("Upload-Offset")
+			if resp, err := u.send(head); err == nil {
+				resp.Body.Close()
+				if received, err := strconv.ParseInt(resp.Header.Get("Upload-Offset"), 10, 64); err == nil && resp.StatusCode < 300 {
+					offset = received
+				}
+			}
+			continue
+		}
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return resp, nil
+		}
+		attempt = 0
+		received, err := strconv.ParseInt(resp.Header.Get("Upload-Offset"), 10, 64)
+		if err != nil || received <= offset {
+			return resp, nil
+		}
+		if received >= u.upload.size {
+			return resp, nil
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		offset = received
+	}
+}
@@ -0,0 +1,263 @@
+package openapi2mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// testUploadFile opens a file with content in a temporary upload root.
+func testUploadFile(t *testing.T, content string) *uploadFile {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "data.bin"), []byte(content), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	upload, err := openUploadFile(root, "data.bin", 1<<20)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { upload.file.Close() })
+	return upload
+}
+
+func TestToolHandler_ContentRangeUpload(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /files:
+    put:
+      operationId: uploadFile
+      x-mcp-upload: {protocol: content-range, chunkSize: 4}
+      requestBody: {required: true, content: {application/octet-stream: {schema: {type: string, format: binary}}}}
+      responses: {"201": {description: stored}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "data.bin"), []byte("0123456789"), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var sent []string
+	var received strings.Builder
+	opts := &ToolGenOptions{UploadRoot: root, RequestHandler: func(req *http.Request) (*http.Response, error) {
+		data, _ := io.ReadAll(req.Body)
+		received.Write(data)
+		sent = append(sent, req.Method+" "+req.URL.Path+" "+req.Header.Get("Content-Range"))
+		status := http.StatusPermanentRedirect
+		if strings.HasSuffix(req.Header.Get("Content-Range"), "9/10") {
+			status = http.StatusCreated
+		}
+		return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+	}}
+	op := OpenAPIOperation{OperationID: "uploadFile", Method: "put", Path: "/files", RequestBody: doc.Paths.Value("/files").Put.RequestBody}
+	handler := toolHandler("uploadFile", op, doc, jsonschema.Schema{}, []string{"https://api.example.com"}, opts, newServerRuntime())
+	result, _, err := handler(context.Background(), nil, map[string]any{requestBodyFileArgument: "data.bin"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{"PUT /files bytes 0-3/10", "PUT /files bytes 4-7/10", "PUT /files bytes 8-9/10"}
+	if strings.Join(sent, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected requests:\n%s\nwant:\n%s", strings.Join(sent, "\n"), strings.Join(want, "\n"))
+	}
+	if received.String() != "0123456789" {
+		t.Errorf("expected the file to be sent once, got %q", received.String())
+	}
+	note := result.Content[len(result.Content)-1].(*mcp.TextContent).Text
+	if !strings.Contains(note, "Uploaded data.bin (10 bytes) in 3 chunks with content-range") {
+		t.Errorf("expected a note on the chunked upload, got %q", note)
+	}
+}
+
+func TestToolHandler_UploadLargerThanRequestBodyLimit(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(`openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /files:
+    put:
+      operationId: uploadFile
+      x-mcp-upload: {protocol: content-range, chunkSize: 4}
+      requestBody: {required: true, content: {application/octet-stream: {schema: {type: string, format: binary}}}}
+      responses: {"201": {description: stored}}
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "data.bin"), []byte("0123456789"), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var received strings.Builder
+	opts := &ToolGenOptions{UploadRoot: root, MaxRequestBodyBytes: 5, RequestHandler: func(req *http.Request) (*http.Response, error) {
+		data, _ := io.ReadAll(req.Body)
+		received.Write(data)
+		status := http.StatusPermanentRedirect
+		if strings.HasSuffix(req.Header.Get("Content-Range"), "9/10") {
+			status = http.StatusCreated
+		}
+		return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+	}}
+	op := OpenAPIOperation{OperationID: "uploadFile", Method: "put", Path: "/files", RequestBody: doc.Paths.Value("/files").Put.RequestBody}
+	handler := toolHandler("uploadFile", op, doc, jsonschema.Schema{}, []string{"https://api.example.com"}, opts, newServerRuntime())
+	result, _, err := handler(context.Background(), nil, map[string]any{requestBodyFileArgument: "data.bin"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError || received.String() != "0123456789" {
+		t.Errorf("expected the file to be uploaded in chunks, got %q: %s", received.String(), resultText(t, result))
+	}
+
+	opts.MaxUploadFileBytes = 8
+	received.Reset()
+	handler = toolHandler("uploadFile", op, doc, jsonschema.Schema{}, []string{"https://api.example.com"}, opts, newServerRuntime())
+	result, _, err = handler(context.Background(), nil, map[string]any{requestBodyFileArgument: "data.bin"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.IsError || !strings.Contains(resultText(t, result), "data.bin is 10 bytes, the limit is 8 bytes") || received.Len() > 0 {
+		t.Errorf("expected the file to be rejected by MaxUploadFileBytes, got: %s", resultText(t, result))
+	}
+}
+
+func TestResumableUpload_ContentRangeResumesFromReceivedBytes(t *testing.T) {
+	upload := testUploadFile(t, "0123456789")
+	failed := false
+	var sent []string
+	u := &resumableUpload{
+		cfg:    &uploadConfig{Protocol: uploadContentRange, ChunkSize: 4},
+		upload: upload,
+		send: func(req *http.Request) (*http.Response, error) {
+			data, _ := io.ReadAll(req.Body)
+			sent = append(sent, req.Header.Get("Content-Range")+" "+string(data))
+			resp := &http.Response{StatusCode: http.StatusPermanentRedirect, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}
+			switch req.Header.Get("Content-Range") {
+			case "bytes 4-7/10":
+				if !failed {
+					failed = true
+					return nil, errors.New("connection reset")
+				}
+			case "bytes */10":
+				resp.Header.Set("Range", "bytes=0-5") // received part of the failed chunk
+			case "bytes 6-9/10":
+				resp.StatusCode = http.StatusCreated
+			}
+			return resp, nil
+		},
+		backoff: 1,
+	}
+	req, _ := http.NewRequest(http.MethodPut, "https://api.example.com/files/data.bin", nil)
+	resp, err := u.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("expected the response to the last chunk, got %d", resp.StatusCode)
+	}
+	want := []string{"bytes 0-3/10 0123", "bytes 4-7/10 4567", "bytes */10 ", "bytes 6-9/10 6789"}
+	if strings.Join(sent, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected requests:\n%s\nwant:\n%s", strings.Join(sent, "\n"), strings.Join(want, "\n"))
+	}
+	if u.resumes != 1 {
+		t.Errorf("expected 1 resume, got %d", u.resumes)
+	}
+}
+
+func TestResumableUpload_Tus(t *testing.T) {
+	upload := testUploadFile(t, "0123456789")
+	received := 0
+	failed := false
+	var sent []string
+	u := &resumableUpload{
+		cfg:     &uploadConfig{Protocol: uploadTus, ChunkSize: 4, Endpoint: "/uploads"},
+		upload:  upload,
+		baseURL: "https://api.example.com/v1",
+		send: func(req *http.Request) (*http.Response, error) {
+			data, _ := io.ReadAll(req.Body)
+			sent = append(sent, fmt.Sprintf("%s %s offset=%s %s", req.Method, req.URL, req.Header.Get("Upload-Offset"), data))
+			resp := &http.Response{StatusCode: http.StatusNoContent, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}
+			switch req.Method {
+			case http.MethodPost:
+				if req.Header.Get("Upload-Length") != "10" || req.Header.Get("Tus-Resumable") != tusVersion {
+					t.Errorf("unexpected creation headers: %v", req.Header)
+				}
+				resp.StatusCode = http.StatusCreated
+				resp.Header.Set("Location", "/v1/uploads/42")
+			case http.MethodPatch:
+				if req.Header.Get("Content-Type") != "application/offset+octet-stream" {
+					t.Errorf("unexpected content type %q", req.Header.Get("Content-Type"))
+				}
+				if received == 4 && !failed {
+					failed = true
+					received += 2 // the connection broke after 2 bytes
+					resp.StatusCode = http.StatusBadGateway
+					return resp, nil
+				}
+				received += len(data)
+				resp.Header.Set("Upload-Offset", fmt.Sprint(received))
+			case http.MethodHead:
+				resp.StatusCode = http.StatusOK
+				resp.Header.Set("Upload-Offset", fmt.Sprint(received))
+			}
+			return resp, nil
+		},
+		backoff: 1,
+	}
+	req, _ := http.NewRequest(http.MethodPost, "https://api.example.com/v1/files", nil)
+	req.Header.Set("Content-Type", "application/octet-stream")
+	resp, err := u.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != http.StatusNoContent || resp.Header.Get("Upload-Offset") != "10" {
+		t.Errorf("expected the completed upload, got %d with offset %s", resp.StatusCode, resp.Header.Get("Upload-Offset"))
+	}
+	want := []string{
+		"POST https://api.example.com/v1/uploads offset= ",
+		"PATCH https://api.example.com/v1/uploads/42 offset=0 0123",
+		"PATCH https://api.example.com/v1/uploads/42 offset=4 4567",
+		"HEAD https://api.example.com/v1/uploads/42 offset= ",
+		"PATCH https://api.example.com/v1/uploads/42 offset=6 6789",
+	}
+	if strings.Join(sent, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected requests:\n%s\nwant:\n%s", strings.Join(sent, "\n"), strings.Join(want, "\n"))
+	}
+	if note := u.Note(); !strings.Contains(note, "resumed 1 times") || !strings.Contains(note, "https://api.example.com/v1/uploads/42") {
+		t.Errorf("expected the note to mention the resume and upload URL, got %q", note)
+	}
+}
+
+func TestProgressReporter(t *testing.T) {
+	var progress []float64
+	report := progressReporter("data.bin", 100, func(p *mcp.ProgressNotificationParams) {
+		if p.Total != 100 {
+			t.Errorf("expected total 100, got %v", p.Total)
+		}
+		progress = append(progress, p.Progress)
+	})
+	body := withProgress(io.NopCloser(strings.NewReader(strings.Repeat("x", 100))), 0, report)
+	buf := make([]byte, 10)
+	for {
+		if _, err := body.Read(buf); err != nil {
+			break
+		}
+	}
+	report(50) // resent bytes don't go back
+
+	// Reads within uploadProgressInterval are reported once, and the completed upload always
+	if fmt.Sprint(progress) != "[10 100]" {
+		t.Errorf("unexpected progress notifications: %v", progress)
+	}
+}
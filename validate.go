@@ -0,0 +1,94 @@
+// validate.go
+package openapi2mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// maxResponseMismatches caps the number of mismatches reported for a single response.
+const maxResponseMismatches = 10
+
+// responseSchemaFor returns the documented JSON schema for the given status code, falling back to the default response.
+// Returns nil if the operation documents no JSON schema for that status.
+func responseSchemaFor(op OpenAPIOperation, statusCode int) *openapi3.Schema {
+	if op.Responses == nil {
+		return nil
+	}
+	respRef := op.Responses.Status(statusCode)
+	if respRef == nil {
+		respRef = op.Responses.Default()
+	}
+	if respRef == nil || respRef.Value == nil {
+		return nil
+	}
+	mt := getContentByType(respRef.Value.Content, "application/json")
+	if mt == nil {
+		mt = getContentByType(respRef.Value.Content, "application/vnd.api+json")
+	}
+	if mt == nil || mt.Schema == nil {
+		return nil
+	}
+	return mt.Schema.Value
+}
+
+// validateResponseBody checks a response body against the operation's documented response schema.
+// Returns a list of human-readable mismatches, or nil if the body conforms or no schema is documented.
+func validateResponseBody(op OpenAPIOperation, statusCode int, body []byte) []string {
+	schema := responseSchemaFor(op, statusCode)
+	if schema == nil || len(body) == 0 {
+		return nil
+	}
+
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return []string{"response body is not valid JSON: " + err.Error()}
+	}
+
+	err := schema.VisitJSON(value, openapi3.MultiErrors(), openapi3.VisitAsResponse())
+	if err == nil {
+		return nil
+	}
+
+	var errs []error
+	var multi openapi3.MultiError
+	if errors.As(err, &multi) {
+		errs = multi
+	} else {
+		errs = []error{err}
+	}
+
+	var mismatches []string
+	for _, e := range errs {
+		if len(mismatches) >= maxResponseMismatches {
+			mismatches = append(mismatches, fmt.Sprintf("... and %d more", len(errs)-maxResponseMismatches))
+			break
+		}
+		var schemaErr *openapi3.SchemaError
+		if errors.As(e, &schemaErr) {
+			path := "$"
+			if ptr := schemaErr.JSONPointer(); len(ptr) > 0 {
+				path += "." + strings.Join(ptr, ".")
+			}
+			mismatches = append(mismatches, fmt.Sprintf("%s: %s", path, schemaErr.Reason))
+		} else {
+			mismatches = append(mismatches, e.Error())
+		}
+	}
+	return mismatches
+}
+
+// formatResponseValidationWarning renders response schema mismatches as a warning section appended to tool results.
+func formatResponseValidationWarning(statusCode int, mismatches []string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\n\nRESPONSE VALIDATION WARNING: The response (HTTP %d) does not match the documented schema.\n", statusCode))
+	for _, m := range mismatches {
+		sb.WriteString("• " + m + "\n")
+	}
+	sb.WriteString("Do not rely on missing or mistyped fields; the API may have changed since the spec was written.")
+	return sb.String()
+}
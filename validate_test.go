@@ -0,0 +1,96 @@
+package openapi2mcp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func petResponses() *openapi3.Responses {
+	schema := &openapi3.Schema{
+		Type:     typesPtr("object"),
+		Required: []string{"id", "name"},
+		Properties: openapi3.Schemas{
+			"id":   &openapi3.SchemaRef{Value: &openapi3.Schema{Type: typesPtr("integer")}},
+			"name": &openapi3.SchemaRef{Value: &openapi3.Schema{Type: typesPtr("string")}},
+		},
+	}
+	return openapi3.NewResponses(openapi3.WithStatus(200, &openapi3.ResponseRef{
+		Value: openapi3.NewResponse().WithJSONSchema(schema),
+	}))
+}
+
+func fakeResponse(status int, contentType, body string) func(*http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: status,
+			Status:     http.StatusText(status),
+			Header:     http.Header{"Content-Type": []string{contentType}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	}
+}
+
+func resultText(t *testing.T, res *mcp.CallToolResult) string {
+	t.Helper()
+	if res == nil || len(res.Content) == 0 {
+		t.Fatalf("expected content in result")
+	}
+	text, ok := res.Content[0].(*mcp.TextContent)
+	if !ok {
+		t.Fatalf("expected text content, got %T", res.Content[0])
+	}
+	return text.Text
+}
+
+func TestValidateResponseBody(t *testing.T) {
+	op := OpenAPIOperation{OperationID: "getPet", Responses: petResponses()}
+
+	if m := validateResponseBody(op, 200, []byte(`{"id": 1, "name": "Rex"}`)); len(m) != 0 {
+		t.Fatalf("expected no mismatches, got %v", m)
+	}
+
+	m := validateResponseBody(op, 200, []byte(`{"id": "one"}`))
+	if len(m) != 2 {
+		t.Fatalf("expected 2 mismatches, got %v", m)
+	}
+	joined := strings.Join(m, "\n")
+	if !strings.Contains(joined, "$.id") || !strings.Contains(joined, "name") {
+		t.Errorf("expected mismatches to mention id and name, got %v", m)
+	}
+
+	// Undocumented status codes are not validated
+	if m := validateResponseBody(op, 201, []byte(`{}`)); m != nil {
+		t.Errorf("expected no validation for undocumented status, got %v", m)
+	}
+}
+
+func TestToolHandler_ValidateResponses(t *testing.T) {
+	op := OpenAPIOperation{OperationID: "getPet", Path: "/pet", Method: "get", Responses: petResponses()}
+	opts := &ToolGenOptions{
+		ValidateResponses: true,
+		RequestHandler:    fakeResponse(200, "application/json", `{"id": 1}`),
+	}
+	handler := toolHandler("getPet", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+	res, _, err := handler(context.Background(), nil, map[string]any{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text := resultText(t, res); !strings.Contains(text, "RESPONSE VALIDATION WARNING") {
+		t.Errorf("expected validation warning, got: %s", text)
+	}
+
+	opts.ValidateResponses = false
+	handler = toolHandler("getPet", op, minimalOpenAPIDoc(), jsonschema.Schema{}, []string{"http://example.com"}, opts, nil)
+	res, _, _ = handler(context.Background(), nil, map[string]any{})
+	if text := resultText(t, res); strings.Contains(text, "RESPONSE VALIDATION WARNING") {
+		t.Errorf("expected no validation warning when disabled, got: %s", text)
+	}
+}
@@ -0,0 +1,145 @@
+// webhook.go
+package openapi2mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// webhooksReceiverPrefix is the path segment under which a CallbackReceiver receives webhooks,
+// in place of the operationId used for callbacks.
+const webhooksReceiverPrefix = "webhooks"
+
+// Webhook describes a request that the API sends to subscribers, as declared in the top-level
+// webhooks object of an OpenAPI 3.1 document, rather than a request the API serves.
+type Webhook struct {
+	Name        string
+	Method      string
+	OperationID string
+	Summary     string
+	Description string
+	RequestBody *openapi3.RequestBodyRef
+	Responses   *openapi3.Responses
+}
+
+// ExtractWebhooks returns the webhooks of an OpenAPI 3.1 document, sorted by name and method.
+// Component references are resolved against doc. Documents without webhooks return nil.
+// Example usage for ExtractWebhooks:
+//
+//	doc, err := openapi2mcp.LoadOpenAPISpec("petstore.yaml")
+//	if err != nil { log.Fatal(err) }
+//	webhooks, err := openapi2mcp.ExtractWebhooks(doc)
+func ExtractWebhooks(doc *openapi3.T) ([]Webhook, error) {
+	raw, ok := doc.Extensions["webhooks"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("webhooks: %w", err)
+	}
+	var items map[string]*openapi3.PathItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("webhooks: %w", err)
+	}
+
+	// kin-openapi doesn't know about webhooks, so resolve them as the paths of a copy of doc
+	paths := openapi3.NewPaths()
+	for name, item := range items {
+		if item == nil {
+			return nil, fmt.Errorf("webhooks: %s: empty path item", name)
+		}
+		paths.Set("/"+name, item)
+	}
+	resolved := *doc
+	resolved.Paths = paths
+	resolved.Extensions = nil
+	if err := openapi3.NewLoader().ResolveRefsIn(&resolved, nil); err != nil {
+		return nil, fmt.Errorf("webhooks: %w", err)
+	}
+
+	var webhooks []Webhook
+	for _, name := range slices.Sorted(maps.Keys(items)) {
+		item := paths.Value("/" + name)
+		for _, method := range slices.Sorted(maps.Keys(item.Operations())) {
+			op := item.Operations()[method]
+			webhooks = append(webhooks, Webhook{
+				Name:        name,
+				Method:      strings.ToUpper(method),
+				OperationID: op.OperationID,
+				Summary:     op.Summary,
+				Description: op.Description,
+				RequestBody: op.RequestBody,
+				Responses:   op.Responses,
+			})
+		}
+	}
+	return webhooks, nil
+}
+
+// webhookDocumentation returns the JSON documentation of a webhook served by its resource,
+// including where receiver expects it if receiver is non-nil.
+func webhookDocumentation(webhook Webhook, receiver *CallbackReceiver) map[string]any {
+	out := map[string]any{
+		"name":   webhook.Name,
+		"method": webhook.Method,
+	}
+	if webhook.OperationID != "" {
+		out["operationId"] = webhook.OperationID
+	}
+	if webhook.Summary != "" {
+		out["summary"] = webhook.Summary
+	}
+	if webhook.Description != "" {
+		out["description"] = webhook.Description
+	}
+	if webhook.RequestBody != nil && webhook.RequestBody.Value != nil {
+		mt := getContentByType(webhook.RequestBody.Value.Content, "application/json")
+		if mt != nil && mt.Schema != nil {
+			if schema := extractProperty(mt.Schema, nil); schema != nil {
+				out["payloadSchema"] = schema
+			}
+		}
+	}
+	if webhook.Responses != nil {
+		out["expectedResponses"] = slices.Sorted(maps.Keys(webhook.Responses.Map()))
+	}
+	if receiver != nil {
+		out["receiverURL"] = receiver.CallbackURL(webhooksReceiverPrefix, webhook.Name)
+		out["howToReceive"] = fmt.Sprintf("Subscribe receiverURL with the API, then use await_callback with {\"operation_id\": %q, \"name\": %q} to wait for the webhook.", webhooksReceiverPrefix, webhook.Name)
+	}
+	return out
+}
+
+// registerWebhookResources adds a webhook://{name} documentation resource per webhook name.
+func registerWebhookResources(server *mcp.Server, webhooks []Webhook, receiver *CallbackReceiver) {
+	byName := make(map[string][]Webhook)
+	for _, webhook := range webhooks {
+		byName[webhook.Name] = append(byName[webhook.Name], webhook)
+	}
+	for name, list := range byName {
+		resource := &mcp.Resource{
+			URI:         "webhook://" + escapePathParameter(name),
+			Name:        "Webhook " + name,
+			Description: fmt.Sprintf("Documentation of the %s webhook the API sends to subscribers", name),
+			MIMEType:    "application/json",
+		}
+		var docs []map[string]any
+		for _, webhook := range list {
+			docs = append(docs, webhookDocumentation(webhook, receiver))
+		}
+		text, _ := json.MarshalIndent(docs, "", "  ")
+		server.AddResource(resource, func(context.Context, *mcp.ServerRequest[*mcp.ReadResourceParams]) (*mcp.ReadResourceResult, error) {
+			return &mcp.ReadResourceResult{
+				Contents: []*mcp.ResourceContents{{URI: resource.URI, MIMEType: "application/json", Text: string(text)}},
+			}, nil
+		})
+	}
+}
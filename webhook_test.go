@@ -0,0 +1,86 @@
+package openapi2mcp
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const webhooksSpec = `openapi: 3.1.0
+info: {title: Pets, version: "1.0.0"}
+paths: {}
+webhooks:
+  newPet:
+    post:
+      summary: A pet was added
+      requestBody:
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/Pet'}
+      responses:
+        "200": {description: Received}
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        name: {type: string}
+`
+
+func TestExtractWebhooks(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(webhooksSpec)
+	if err != nil {
+		t.Fatalf("expected spec with webhooks to load, got: %v", err)
+	}
+	webhooks, err := ExtractWebhooks(doc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(webhooks) != 1 || webhooks[0].Name != "newPet" || webhooks[0].Method != "POST" {
+		t.Fatalf("unexpected webhooks %+v", webhooks)
+	}
+	schema := webhooks[0].RequestBody.Value.Content.Get("application/json").Schema
+	if schema.Value == nil || schema.Value.Properties["name"] == nil {
+		t.Errorf("expected payload schema reference to be resolved, got %+v", schema)
+	}
+
+	if _, err := LoadOpenAPISpecFromString(strings.Replace(webhooksSpec, "#/components/schemas/Pet", "#/components/schemas/Missing", 1)); err == nil {
+		t.Error("expected error for unresolvable webhook reference")
+	}
+}
+
+func TestRegisterOpenAPITools_Webhooks(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(webhooksSpec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	names := RegisterOpenAPITools(server, ExtractOpenAPIOperations(doc), doc, &ToolGenOptions{CallbackReceiver: NewCallbackReceiver("https://hooks.example.com")})
+	if !slices.Contains(names, "await_callback") {
+		t.Errorf("expected await_callback for webhooks, got %v", names)
+	}
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(context.Background(), serverTransport, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil).Connect(context.Background(), clientTransport, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cs.Close()
+
+	res, err := cs.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "webhook://newPet"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := res.Contents[0].Text
+	for _, want := range []string{`"summary": "A pet was added"`, `"payloadSchema"`, `"receiverURL": "https://hooks.example.com/webhooks/newPet"`} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected resource to contain %s, got:\n%s", want, text)
+		}
+	}
+}
@@ -0,0 +1,618 @@
+// workflow.go
+package openapi2mcp
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.yaml.in/yaml/v3"
+)
+
+// Workflow is a sequence of operation calls served as a single tool, e.g. creating a customer, subscribing them to a
+// plan, and sending them an invite. Inputs is the JSON schema of the tool's arguments. Step arguments and outputs are
+// values that may contain runtime expressions: $inputs.<name> for a tool argument, $steps.<id>.outputs.<name> for the
+// output of an earlier step, and, in step outputs, $response.body#/<pointer>, $response.header.<name>, and
+// $statusCode for the step's response. A value that is a single expression keeps the type of what it refers to;
+// expressions in braces within a longer string ("Welcome, {$inputs.name}") are interpolated. Outputs are the tool's
+// result; if there are none, the last step's response body is. SuccessActions and FailureActions apply to the steps
+// that define no actions of their own.
+type Workflow struct {
+	Name           string            `json:"name" yaml:"name"`
+	Description    string            `json:"description,omitempty" yaml:"description,omitempty"`
+	Inputs         map[string]any    `json:"inputs,omitempty" yaml:"inputs,omitempty"`
+	Steps          []WorkflowStep    `json:"steps" yaml:"steps"`
+	Outputs        map[string]string `json:"outputs,omitempty" yaml:"outputs,omitempty"`
+	SuccessActions []WorkflowAction  `json:"successActions,omitempty" yaml:"successActions,omitempty"`
+	FailureActions []WorkflowAction  `json:"failureActions,omitempty" yaml:"failureActions,omitempty"`
+}
+
+// WorkflowStep is a call of the operation with operationId Operation within a Workflow. The step succeeds if all of
+// SuccessCriteria hold or, without criteria, if the call returns a 2xx response. Then the first OnSuccess action whose
+// criteria hold decides how the workflow continues, else the next step runs; after a failure, the first matching
+// OnFailure action does, else the workflow fails.
+type WorkflowStep struct {
+	ID              string              `json:"id" yaml:"id"`
+	Operation       string              `json:"operation" yaml:"operation"`
+	Arguments       map[string]any      `json:"arguments,omitempty" yaml:"arguments,omitempty"`
+	Outputs         map[string]string   `json:"outputs,omitempty" yaml:"outputs,omitempty"`
+	SuccessCriteria []WorkflowCriterion `json:"successCriteria,omitempty" yaml:"successCriteria,omitempty"`
+	OnSuccess       []WorkflowAction    `json:"onSuccess,omitempty" yaml:"onSuccess,omitempty"`
+	OnFailure       []WorkflowAction    `json:"onFailure,omitempty" yaml:"onFailure,omitempty"`
+}
+
+// WorkflowCriterion is a condition on a step's response. Simple conditions compare runtime expressions and literals,
+// e.g. "$statusCode == 200 && $response.body#/status == 'active'"; regex conditions match the value of the Context
+// expression against the regular expression Condition.
+type WorkflowCriterion struct {
+	Condition string `json:"condition" yaml:"condition"`
+	Context   string `json:"context,omitempty" yaml:"context,omitempty"`
+	Type      string `json:"type,omitempty" yaml:"type,omitempty"` // "simple" (default) or "regex"
+}
+
+// WorkflowAction decides how a workflow continues after a step: "end" ends it (failing it after a failed step),
+// "goto" continues with the step StepID, and, after a failed step, "retry" runs StepID or the failed step again after
+// RetryAfter seconds, at most RetryLimit times (default 1) before the workflow fails. The action only applies if all
+// of its Criteria hold.
+type WorkflowAction struct {
+	Type       string              `json:"type" yaml:"type"`
+	StepID     string              `json:"stepId,omitempty" yaml:"stepId,omitempty"`
+	RetryAfter float64             `json:"retryAfter,omitempty" yaml:"retryAfter,omitempty"`
+	RetryLimit int                 `json:"retryLimit,omitempty" yaml:"retryLimit,omitempty"`
+	Criteria   []WorkflowCriterion `json:"criteria,omitempty" yaml:"criteria,omitempty"`
+}
+
+// maxWorkflowSteps caps the steps a workflow runs, so that goto and retry actions can't loop forever.
+const maxWorkflowSteps = 100
+
+// maxWorkflowRetryAfter caps how long a retry action waits.
+const maxWorkflowRetryAfter = time.Minute
+
+// LoadWorkflows reads workflows from a YAML or JSON file:
+//
+//	workflows:
+//	  - name: onboard_customer
+//	    description: Create a customer, subscribe them to a plan, and send them an invite.
+//	    inputs:
+//	      type: object
+//	      properties: {email: {type: string}, plan: {type: string}}
+//	      required: [email, plan]
+//	    steps:
+//	      - id: customer
+//	        operation: createCustomer
+//	        arguments: {requestBody: {email: $inputs.email}}
+//	        outputs: {id: $response.body#/id}
+//	      - id: subscription
+//	        operation: createSubscription
+//	        arguments: {customerId: $steps.customer.outputs.id, requestBody: {plan: $inputs.plan}}
+//	      - id: invite
+//	        operation: sendInvite
+//	        arguments: {customerId: $steps.customer.outputs.id}
+//	    outputs:
+//	      customerId: $steps.customer.outputs.id
+//
+// Example usage for LoadWorkflows:
+//
+//	workflows, err := openapi2mcp.LoadWorkflows("workflows.yaml")
+//	if err != nil { log.Fatal(err) }
+//	opts.Workflows = workflows
+func LoadWorkflows(path string) ([]Workflow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Workflows []Workflow `yaml:"workflows"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid workflows %s: %w", path, err)
+	}
+	return file.Workflows, nil
+}
+
+// validateWorkflow checks that the workflow has a name and steps with unique IDs calling known operations, and that
+// its criteria and actions are well-formed.
+func validateWorkflow(w Workflow, ops *OperationRegistry) error {
+	if w.Name == "" {
+		return errors.New("workflow without a name")
+	}
+	if len(w.Steps) == 0 {
+		return fmt.Errorf("workflow %s has no steps", w.Name)
+	}
+	seen := make(map[string]bool)
+	for i, step := range w.Steps {
+		if step.ID == "" || seen[step.ID] {
+			return fmt.Errorf("workflow %s: step %d needs a unique id", w.Name, i+1)
+		}
+		seen[step.ID] = true
+		if step.Operation == "" {
+			return fmt.Errorf("workflow %s: step %s names no operation", w.Name, step.ID)
+		}
+		if tool, _ := operationTool(ops, step.Operation); tool == "" {
+			return fmt.Errorf("workflow %s: step %s calls unknown operation %q", w.Name, step.ID, step.Operation)
+		}
+	}
+	check := func(where string, criteria []WorkflowCriterion, actions []WorkflowAction, failure bool) error {
+		if err := validateCriteria(criteria); err != nil {
+			return fmt.Errorf("workflow %s: %s: %w", w.Name, where, err)
+		}
+		for _, a := range actions {
+			err := validateCriteria(a.Criteria)
+			switch {
+			case err != nil:
+			case a.Type == "goto" && !seen[a.StepID]:
+				err = fmt.Errorf("goto names unknown step %q", a.StepID)
+			case a.Type == "retry" && failure:
+				if a.StepID != "" && !seen[a.StepID] {
+					err = fmt.Errorf("retry names unknown step %q", a.StepID)
+				}
+			case a.Type != "end" && a.Type != "goto":
+				err = fmt.Errorf("unsupported action type %q", a.Type)
+			}
+			if err != nil {
+				return fmt.Errorf("workflow %s: %s: %w", w.Name, where, err)
+			}
+		}
+		return nil
+	}
+	if err := check("success actions", nil, w.SuccessActions, false); err != nil {
+		return err
+	}
+	if err := check("failure actions", nil, w.FailureActions, true); err != nil {
+		return err
+	}
+	for _, step := range w.Steps {
+		if err := check("step "+step.ID, step.SuccessCriteria, step.OnSuccess, false); err != nil {
+			return err
+		}
+		if err := check("step "+step.ID, nil, step.OnFailure, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateCriteria checks that the criteria are of supported types and their regular expressions compile.
+func validateCriteria(criteria []WorkflowCriterion) error {
+	for _, c := range criteria {
+		switch c.Type {
+		case "", "simple":
+		case "regex":
+			if _, err := regexp.Compile(c.Condition); err != nil {
+				return fmt.Errorf("invalid regex criterion: %v", err)
+			}
+		default:
+			return fmt.Errorf("unsupported criterion type %q", c.Type)
+		}
+	}
+	return nil
+}
+
+// workflowState holds the values runtime expressions of a running workflow refer to.
+type workflowState struct {
+	inputs  map[string]any
+	outputs map[string]map[string]any // by step ID
+}
+
+// embeddedExpression matches the runtime expressions in braces within a longer string.
+var embeddedExpression = regexp.MustCompile(`\{(\$[^{}]+)\}`)
+
+// resolve replaces the runtime expressions in value, recursively, using response for $response and $statusCode.
+func (s *workflowState) resolve(value any, response *linkContext) (any, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		resolved := make(map[string]any, len(v))
+		for key, item := range v {
+			r, err := s.resolve(item, response)
+			if err != nil {
+				return nil, err
+			}
+			resolved[key] = r
+		}
+		return resolved, nil
+	case []any:
+		resolved := make([]any, len(v))
+		for i, item := range v {
+			r, err := s.resolve(item, response)
+			if err != nil {
+				return nil, err
+			}
+			resolved[i] = r
+		}
+		return resolved, nil
+	case string:
+		if strings.HasPrefix(v, "$") {
+			return s.evaluate(v, response)
+		}
+		var err error
+		interpolated := embeddedExpression.ReplaceAllStringFunc(v, func(m string) string {
+			r, e := s.evaluate(m[1:len(m)-1], response)
+			if e != nil {
+				err = e
+				return m
+			}
+			return fmt.Sprint(r)
+		})
+		return interpolated, err
+	}
+	return value, nil
+}
+
+// evaluate returns the value of a single runtime expression.
+func (s *workflowState) evaluate(expr string, response *linkContext) (any, error) {
+	if name, ok := strings.CutPrefix(expr, "$inputs."); ok {
+		v, ok := s.inputs[name]
+		if !ok {
+			return nil, fmt.Errorf("%s is not set", expr)
+		}
+		return v, nil
+	}
+	if rest, ok := strings.CutPrefix(expr, "$steps."); ok {
+		step, name, _ := strings.Cut(rest, ".outputs.")
+		v, ok := s.outputs[step][name]
+		if !ok {
+			return nil, fmt.Errorf("%s is not set", expr)
+		}
+		return v, nil
+	}
+	if response != nil && (strings.HasPrefix(expr, "$response.") || expr == "$statusCode") {
+		v, _, ok := response.evaluate(expr)
+		if !ok {
+			return nil, fmt.Errorf("%s is not in the response", expr)
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("unknown expression %s", expr)
+}
+
+// stepExchangeKey is the context key of the exchange a workflow step's operation call records its response in.
+type stepExchangeKey struct{}
+
+// withStepExchange returns ctx asking operation tools to record their response in exchange.
+func withStepExchange(ctx context.Context, exchange *linkContext) context.Context {
+	return context.WithValue(ctx, stepExchangeKey{}, exchange)
+}
+
+// recordStepExchange records the response in the exchange of ctx, if a workflow step asked for it. JSON bodies are
+// decoded; others are recorded as text.
+func recordStepExchange(ctx context.Context, resp *http.Response, body []byte, isJSON bool) {
+	exchange, _ := ctx.Value(stepExchangeKey{}).(*linkContext)
+	if exchange == nil {
+		return
+	}
+	exchange.statusCode, exchange.header = resp.StatusCode, resp.Header
+	exchange.body = nil
+	if !isJSON || json.Unmarshal(body, &exchange.body) != nil {
+		exchange.body = string(body)
+	}
+}
+
+// workflowStepResult is a step of a workflow run, as listed in the result of its tool.
+type workflowStepResult struct {
+	ID     string `json:"id"`
+	Tool   string `json:"tool"`
+	Status int    `json:"http_status,omitempty"`
+}
+
+// runWorkflow runs the steps of the workflow with the tool arguments args, following their actions, and stops at the
+// first failing step no action recovers from. The steps are called on behalf of req, the workflow tool's request.
+func runWorkflow(ctx context.Context, req *mcp.CallToolRequest, w Workflow, args map[string]any, ops *OperationRegistry) *mcp.CallToolResult {
+	state := &workflowState{inputs: args, outputs: make(map[string]map[string]any)}
+	steps := []workflowStepResult{}
+	retries := make(map[*WorkflowAction]int)
+	stepIndex := func(id string) int {
+		return slices.IndexFunc(w.Steps, func(s WorkflowStep) bool { return s.ID == id })
+	}
+	var last any
+	for i, run := 0, 0; i < len(w.Steps); run++ {
+		step := w.Steps[i]
+		tool, _ := operationTool(ops, step.Operation)
+		failed := func(reason string) *mcp.CallToolResult {
+			text := fmt.Sprintf("Workflow %s failed at step %s (%s): %s", w.Name, step.ID, tool, reason)
+			if len(steps) > 0 {
+				var done []string
+				for _, s := range steps {
+					done = append(done, s.ID)
+				}
+				text += fmt.Sprintf("\nCompleted steps, whose changes remain: %s", strings.Join(done, ", "))
+			}
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}, IsError: true}
+		}
+		if run == maxWorkflowSteps {
+			return failed(fmt.Sprintf("the workflow ran %d steps, the most allowed; check its goto and retry actions", maxWorkflowSteps))
+		}
+
+		stepArgs, err := state.resolve(step.Arguments, nil)
+		if err != nil {
+			return failed(err.Error())
+		}
+		callArgs, _ := stepArgs.(map[string]any)
+		if callArgs == nil {
+			callArgs = map[string]any{}
+		}
+		callArgs["__confirmed"] = true
+
+		exchange := &linkContext{args: callArgs}
+		res, err := ops.callAs(withStepExchange(ctx, exchange), req, tool, callArgs)
+		if err != nil {
+			return failed(err.Error())
+		}
+		succeeded := exchange.statusCode >= 200 && exchange.statusCode < 300 && (res == nil || !res.IsError)
+		if len(step.SuccessCriteria) > 0 {
+			succeeded = exchange.statusCode != 0 && state.holds(step.SuccessCriteria, exchange)
+		}
+
+		if !succeeded {
+			reason := "the call failed"
+			if len(step.SuccessCriteria) > 0 && exchange.statusCode != 0 {
+				reason = "the success criteria don't hold"
+			}
+			if res != nil && len(res.Content) > 0 {
+				if text, ok := res.Content[0].(*mcp.TextContent); ok {
+					reason += "\n" + text.Text
+				}
+			}
+			action := state.action(step.OnFailure, w.FailureActions, exchange)
+			switch {
+			case action == nil || action.Type == "end":
+				return failed(reason)
+			case action.Type == "goto":
+				i = stepIndex(action.StepID)
+			case action.Type == "retry":
+				limit := cmp.Or(action.RetryLimit, 1)
+				if retries[action] >= limit {
+					return failed(fmt.Sprintf("%s\n(gave up after %d retries)", reason, limit))
+				}
+				retries[action]++
+				timer := time.NewTimer(min(time.Duration(action.RetryAfter*float64(time.Second)), maxWorkflowRetryAfter))
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					timer.Stop()
+					return failed(ctx.Err().Error())
+				}
+				if action.StepID != "" {
+					i = stepIndex(action.StepID)
+				}
+			}
+			continue
+		}
+
+		steps = append(steps, workflowStepResult{ID: step.ID, Tool: tool, Status: exchange.statusCode})
+		last = exchange.body
+		outputs := make(map[string]any, len(step.Outputs))
+		for name, expr := range step.Outputs {
+			v, err := state.resolve(expr, exchange)
+			if err != nil {
+				return failed(fmt.Sprintf("output %s: %v", name, err))
+			}
+			outputs[name] = v
+		}
+		state.outputs[step.ID] = outputs
+
+		action := state.action(step.OnSuccess, w.SuccessActions, exchange)
+		switch {
+		case action == nil:
+			i++
+		case action.Type == "end":
+			i = len(w.Steps)
+		case action.Type == "goto":
+			i = stepIndex(action.StepID)
+		}
+	}
+
+	result := map[string]any{"workflow": w.Name, "steps": steps}
+	if len(w.Outputs) > 0 {
+		outputs := make(map[string]any, len(w.Outputs))
+		for _, name := range slices.Sorted(maps.Keys(w.Outputs)) {
+			v, err := state.resolve(w.Outputs[name], nil)
+			if err != nil {
+				v = nil
+			}
+			outputs[name] = v
+		}
+		result["outputs"] = outputs
+	} else {
+		result["outputs"] = last
+	}
+	text, _ := json.MarshalIndent(result, "", "  ")
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: string(text)}}}
+}
+
+// action returns the first of the step's actions, or else of the workflow's defaults, whose criteria hold for the
+// step's response, or nil if none does.
+func (s *workflowState) action(actions, defaults []WorkflowAction, response *linkContext) *WorkflowAction {
+	if len(actions) == 0 {
+		actions = defaults
+	}
+	for i := range actions {
+		if s.holds(actions[i].Criteria, response) {
+			return &actions[i]
+		}
+	}
+	return nil
+}
+
+// holds reports whether all of the criteria hold for the step's response.
+func (s *workflowState) holds(criteria []WorkflowCriterion, response *linkContext) bool {
+	for _, c := range criteria {
+		if c.Type == "regex" {
+			v, err := s.evaluate(c.Context, response)
+			if err != nil {
+				return false
+			}
+			if re, err := regexp.Compile(c.Condition); err != nil || !re.MatchString(fmt.Sprint(v)) {
+				return false
+			}
+			continue
+		}
+		if !s.condition(c.Condition, response) {
+			return false
+		}
+	}
+	return true
+}
+
+// comparisonOperators are the operators of simple conditions, longest first so that "<=" isn't taken for "<".
+var comparisonOperators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// condition evaluates a simple condition: comparisons of runtime expressions and literals ('text', numbers, true,
+// false, null), or single expressions tested for being set and not false, joined by && and ||, which binds weaker.
+func (s *workflowState) condition(cond string, response *linkContext) bool {
+	for _, alternative := range strings.Split(cond, "||") {
+		holds := true
+		for _, term := range strings.Split(alternative, "&&") {
+			if !s.comparison(strings.TrimSpace(term), response) {
+				holds = false
+				break
+			}
+		}
+		if holds {
+			return true
+		}
+	}
+	return false
+}
+
+// comparison evaluates a single comparison of a simple condition.
+func (s *workflowState) comparison(term string, response *linkContext) bool {
+	operand := func(text string) (any, bool) {
+		text = strings.TrimSpace(text)
+		switch {
+		case strings.HasPrefix(text, "$"):
+			v, err := s.evaluate(text, response)
+			return v, err == nil
+		case len(text) >= 2 && text[0] == '\'' && text[len(text)-1] == '\'':
+			return text[1 : len(text)-1], true
+		case text == "true" || text == "false":
+			return text == "true", true
+		case text == "null":
+			return nil, true
+		}
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			return f, true
+		}
+		return text, true
+	}
+	for _, op := range comparisonOperators {
+		left, right, ok := strings.Cut(term, op)
+		if !ok {
+			continue
+		}
+		l, lok := operand(left)
+		r, rok := operand(right)
+		if !lok || !rok {
+			return false
+		}
+		var order int
+		lf, lnum := workflowNumber(l)
+		rf, rnum := workflowNumber(r)
+		if lnum && rnum {
+			order = cmp.Compare(lf, rf)
+		} else {
+			order = strings.Compare(fmt.Sprint(l), fmt.Sprint(r))
+		}
+		switch op {
+		case "==":
+			return order == 0
+		case "!=":
+			return order != 0
+		case "<=":
+			return order <= 0
+		case ">=":
+			return order >= 0
+		case "<":
+			return order < 0
+		}
+		return order > 0
+	}
+	v, ok := operand(term)
+	return ok && v != nil && v != false
+}
+
+// workflowNumber returns v as a number, if it is one.
+func workflowNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// describeWorkflow returns the description of a workflow's tool, listing its steps.
+func describeWorkflow(w Workflow, ops *OperationRegistry) string {
+	var sb strings.Builder
+	sb.WriteString(w.Description)
+	if sb.Len() > 0 {
+		sb.WriteString("\n\n")
+	}
+	sb.WriteString("STEPS: This tool calls these operations in order, stopping at the first failing one:")
+	for i, step := range w.Steps {
+		tool, op := operationTool(ops, step.Operation)
+		fmt.Fprintf(&sb, "\n%d. %s (%s %s)", i+1, tool, strings.ToUpper(op.Method), op.Path)
+	}
+	return sb.String()
+}
+
+// registerWorkflowTools adds a tool running each valid workflow and returns their names. Invalid workflows are
+// skipped with a warning.
+func registerWorkflowTools(server *mcp.Server, workflows []Workflow, ops *OperationRegistry, opts *ToolGenOptions, logger *slog.Logger) []string {
+	var names []string
+	for _, w := range workflows {
+		if err := validateWorkflow(w, ops); err != nil {
+			warnf(logger, "Skipping %v", err)
+			continue
+		}
+		if _, ok := ops.Operation(w.Name); ok {
+			warnf(logger, "Workflow %q replaces the operation tool of the same name", w.Name)
+		}
+
+		schema := &jsonschema.Schema{Type: "object"}
+		if len(w.Inputs) > 0 {
+			data, _ := json.Marshal(w.Inputs)
+			if err := json.Unmarshal(data, schema); err != nil {
+				warnf(logger, "Skipping workflow %s: invalid inputs: %v", w.Name, err)
+				continue
+			}
+		}
+
+		// Workflows with state-changing or costly steps are confirmed as a whole, listing the steps
+		dangerous := slices.ContainsFunc(w.Steps, func(step WorkflowStep) bool {
+			_, op := operationTool(ops, step.Operation)
+			return confirmationRequired(opts, op, ops.Doc())
+		})
+		desc := describeWorkflow(w, ops)
+		var annotations *mcp.ToolAnnotations
+		if opts.Version != "" {
+			annotations = &mcp.ToolAnnotations{Title: "OpenAPI " + opts.Version}
+		}
+		workflow := w
+		mcp.AddTool(server, &mcp.Tool{Name: w.Name, Description: desc, InputSchema: schema, Annotations: annotations},
+			func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+				if _, confirmed := args["__confirmed"]; dangerous && !confirmed {
+					text := fmt.Sprintf("⚠️  CONFIRMATION REQUIRED\n\nAction: %s\n%s\n\nTo confirm, retry the call with {\"__confirmed\": true} added to your arguments.", workflow.Name, describeWorkflow(workflow, ops))
+					return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}, nil, nil
+				}
+				inputs := maps.Clone(args)
+				delete(inputs, "__confirmed")
+				return runWorkflow(ctx, req, workflow, inputs, ops), nil, nil
+			})
+		names = append(names, w.Name)
+	}
+	return names
+}
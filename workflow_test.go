@@ -0,0 +1,182 @@
+package openapi2mcp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const workflowSpec = `openapi: 3.0.0
+info: {title: Test, version: "1.0"}
+paths:
+  /customers:
+    post:
+      operationId: createCustomer
+      requestBody:
+        content:
+          application/json:
+            schema: {type: object, properties: {email: {type: string}}}
+      responses: {"201": {description: created}}
+  /customers/{customerId}/subscriptions:
+    parameters: [{name: customerId, in: path, required: true, schema: {type: string}}]
+    post:
+      operationId: createSubscription
+      requestBody:
+        content:
+          application/json:
+            schema: {type: object, properties: {plan: {type: string}, note: {type: string}}}
+      responses: {"201": {description: created}}
+`
+
+const workflowFile = `workflows:
+  - name: onboard_customer
+    description: Create a customer and subscribe them to a plan.
+    inputs:
+      type: object
+      properties: {email: {type: string}, plan: {type: string}}
+      required: [email, plan]
+    steps:
+      - id: customer
+        operation: createCustomer
+        arguments: {requestBody: {email: $inputs.email}}
+        outputs: {id: $response.body#/id}
+      - id: subscription
+        operation: createSubscription
+        arguments:
+          customerId: $steps.customer.outputs.id
+          requestBody: {plan: $inputs.plan, note: "for {$inputs.email}"}
+        outputs: {status: $statusCode}
+    outputs:
+      customerId: $steps.customer.outputs.id
+      status: $steps.subscription.outputs.status
+  - name: broken
+    steps:
+      - id: missing
+        operation: noSuchOperation
+`
+
+func TestRegisterOpenAPITools_Workflows(t *testing.T) {
+	doc, err := LoadOpenAPISpecFromString(workflowSpec)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "workflows.yaml")
+	if err := os.WriteFile(path, []byte(workflowFile), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	workflows, err := LoadWorkflows(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var mu sync.Mutex
+	var sent []string
+	failSubscription := false
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	toolNames := RegisterOpenAPITools(server, ExtractOpenAPIOperations(doc), doc, &ToolGenOptions{
+		Workflows:               workflows,
+		ConfirmDangerousActions: true,
+		BaseURL:                 "http://example.com",
+		RequestHandler: func(req *http.Request) (*http.Response, error) {
+			body, _ := io.ReadAll(req.Body)
+			mu.Lock()
+			defer mu.Unlock()
+			sent = append(sent, req.Method+" "+req.URL.Path+" "+string(body))
+			if req.URL.Path == "/customers" {
+				return fakeResponse(201, "application/json", `{"id": "c1"}`)(req)
+			}
+			if failSubscription {
+				return fakeResponse(422, "application/json", `{"error": "unknown plan"}`)(req)
+			}
+			return fakeResponse(201, "application/json", `{"plan": "pro"}`)(req)
+		},
+	})
+	if !strings.Contains(strings.Join(toolNames, ","), "onboard_customer") {
+		t.Errorf("expected the workflow tool, got %v", toolNames)
+	}
+	for _, name := range toolNames {
+		if name == "broken" {
+			t.Errorf("expected the workflow with an unknown operation to be skipped")
+		}
+	}
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(context.Background(), serverTransport, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cs, err := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil).Connect(context.Background(), clientTransport, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer cs.Close()
+	call := func(args map[string]any) *mcp.CallToolResult {
+		t.Helper()
+		res, err := cs.CallTool(context.Background(), &mcp.CallToolParams{Name: "onboard_customer", Arguments: args})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return res
+	}
+
+	// The workflow is confirmed as a whole before any step runs
+	text := resultText(t, call(map[string]any{"email": "ann@example.com", "plan": "pro"}))
+	if !strings.Contains(text, "CONFIRMATION REQUIRED") || !strings.Contains(text, "createSubscription (POST /customers/{customerId}/subscriptions)") {
+		t.Errorf("expected a confirmation listing the steps, got: %s", text)
+	}
+	if len(sent) != 0 {
+		t.Fatalf("expected no calls before confirmation, got %v", sent)
+	}
+
+	text = resultText(t, call(map[string]any{"email": "ann@example.com", "plan": "pro", "__confirmed": true}))
+	for _, want := range []string{`"customerId": "c1"`, `"status": 201`} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in the outputs, got: %s", want, text)
+		}
+	}
+	mu.Lock()
+	if len(sent) != 2 || sent[1] != `POST /customers/c1/subscriptions {"note":"for ann@example.com","plan":"pro"}` {
+		t.Errorf("expected the subscription to use the created customer, got %v", sent)
+	}
+	failSubscription = true
+	mu.Unlock()
+
+	res := call(map[string]any{"email": "bob@example.com", "plan": "gold", "__confirmed": true})
+	text = resultText(t, res)
+	if !res.IsError || !strings.Contains(text, "failed at step subscription") || !strings.Contains(text, "Completed steps, whose changes remain: customer") {
+		t.Errorf("expected the failing step and the completed ones, got: %s", text)
+	}
+}
+
+func TestWorkflowState_Resolve(t *testing.T) {
+	state := &workflowState{
+		inputs:  map[string]any{"count": 3.0, "name": "Ann"},
+		outputs: map[string]map[string]any{"first": {"id": 7.0}},
+	}
+	got, err := state.resolve(map[string]any{
+		"count": "$inputs.count",
+		"ids":   []any{"$steps.first.outputs.id"},
+		"text":  "Hi {$inputs.name}, #{$steps.first.outputs.id}",
+		"fixed": true,
+	}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m := got.(map[string]any)
+	if m["count"] != 3.0 || m["ids"].([]any)[0] != 7.0 || m["text"] != "Hi Ann, #7" || m["fixed"] != true {
+		t.Errorf("unexpected resolved values: %v", m)
+	}
+
+	if _, err := state.resolve("$inputs.missing", nil); err == nil {
+		t.Error("expected an error for an unset input")
+	}
+	if _, err := state.resolve("$response.body#/id", nil); err == nil {
+		t.Error("expected an error for a response expression outside step outputs")
+	}
+}